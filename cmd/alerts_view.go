@@ -0,0 +1,343 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/olafkfreund/azure-tui/internal/azure/alerts"
+	"github.com/olafkfreund/azure-tui/internal/azure/usage"
+	"github.com/olafkfreund/azure-tui/internal/theme"
+)
+
+// How often the open alert count of the status bar is refreshed
+const alertPollInterval = 2 * time.Minute
+
+// alertsView lists the fired alerts and the alert rules of a resource, a
+// resource group or the subscription
+type alertsView struct {
+	scope      string // Resource or resource group ID, "" for the subscription
+	scopeName  string
+	alerts     []alerts.Alert
+	rules      []usage.Alarm
+	cursor     int
+	expanded   bool // The selected alert shows its details
+	showClosed bool
+	loading    bool
+	err        string
+}
+
+// visible returns the alerts shown: the open ones, or all of them
+func (v *alertsView) visible() []alerts.Alert {
+	if v.showClosed {
+		return v.alerts
+	}
+	var open []alerts.Alert
+	for _, alert := range v.alerts {
+		if alert.Open() {
+			open = append(open, alert)
+		}
+	}
+	return open
+}
+
+// selected returns the selected alert
+func (v *alertsView) selected() (alerts.Alert, bool) {
+	visible := v.visible()
+	if v.cursor < 0 || v.cursor >= len(visible) {
+		return alerts.Alert{}, false
+	}
+	return visible[v.cursor], true
+}
+
+// Alert messages
+type alertsLoadedMsg struct {
+	scope  string
+	alerts []alerts.Alert
+	rules  []usage.Alarm
+	err    error
+}
+
+type alertStateChangedMsg struct {
+	name  string
+	state string
+	err   error
+}
+
+type alertCountMsg struct {
+	generation int
+	count      int
+	err        error
+}
+
+type alertCountTickMsg struct {
+	generation int
+}
+
+func loadAlertsCmd(scope string) tea.Cmd {
+	return func() tea.Msg {
+		fired, err := alerts.List(scope)
+		rules, rulesErr := usage.ListAlarms(scope)
+		if err == nil && rulesErr != nil {
+			err = fmt.Errorf("failed to list alert rules: %v", rulesErr)
+		}
+		return alertsLoadedMsg{scope: scope, alerts: fired, rules: rules, err: err}
+	}
+}
+
+func changeAlertStateCmd(alert alerts.Alert, state string) tea.Cmd {
+	return func() tea.Msg {
+		return alertStateChangedMsg{name: alert.Name, state: state, err: alerts.ChangeState(alert.ID, state)}
+	}
+}
+
+func loadAlertCountCmd(generation int) tea.Cmd {
+	return func() tea.Msg {
+		count, err := alerts.OpenCount()
+		return alertCountMsg{generation: generation, count: count, err: err}
+	}
+}
+
+// pollAlertCount restarts the polling of the open alert count, e.g. for a
+// new subscription. Polls of an earlier generation stop.
+func (m *model) pollAlertCount() tea.Cmd {
+	m.alertPoll++
+	return loadAlertCountCmd(m.alertPoll)
+}
+
+// openAlerts shows the alerts of the node selected in the tree, or of the
+// resource in the details panel, or of the whole subscription
+func (m *model) openAlerts() tea.Cmd {
+	view := &alertsView{scopeName: "subscription", loading: true}
+	if m.currentSubscription != nil {
+		view.scopeName = m.currentSubscription.Name
+	}
+	scoped := false
+	if m.selectedPanel == 0 && m.treeView != nil {
+		if node := m.treeView.GetSelectedNode(); node != nil {
+			if bookmark, ok := m.bookmarkFor(node); ok {
+				view.scope, view.scopeName, scoped = bookmark.ResourceID, bookmark.Name, true
+			}
+		}
+	}
+	if !scoped && m.selectedResource != nil {
+		view.scope, view.scopeName = m.selectedResource.ID, m.selectedResource.Name
+	}
+
+	m.alertsView = view
+	m.selectedPanel = 1
+	m.rightPanelScrollOffset = 0
+	m.pushView("alerts")
+	return loadAlertsCmd(view.scope)
+}
+
+// updateAlertsView handles keys in the alerts view. Keys it does not use
+// are left to the normal key map.
+func (m model) updateAlertsView(msg tea.KeyMsg) (tea.Model, tea.Cmd, bool) {
+	view := m.alertsView
+	switch key := msg.String(); key {
+	case "j", "down":
+		view.cursor = min(view.cursor+1, max(0, len(view.visible())-1))
+	case "k", "up":
+		view.cursor = max(view.cursor-1, 0)
+	case "enter", " ", "space":
+		view.expanded = !view.expanded
+	case "s":
+		view.showClosed = !view.showClosed
+		view.cursor = 0
+	case "a", "c", "u":
+		alert, ok := view.selected()
+		if !ok {
+			return m, nil, true
+		}
+		state := map[string]string{"a": alerts.StateAcknowledged, "c": alerts.StateClosed, "u": alerts.StateNew}[key]
+		if alert.State == state {
+			m.addToast(fmt.Sprintf("%s is already %s", alert.Name, strings.ToLower(state)), "notice")
+			return m, nil, true
+		}
+		return m, changeAlertStateCmd(alert, state), true
+	case "r":
+		view.loading, view.err = true, ""
+		return m, loadAlertsCmd(view.scope), true
+	default:
+		return m, nil, false
+	}
+	return m, nil, true
+}
+
+// updateAlerts handles the alert messages
+func (m model) updateAlerts(msg tea.Msg) (tea.Model, tea.Cmd) {
+	view := m.alertsView
+	switch msg := msg.(type) {
+	case alertsLoadedMsg:
+		if view == nil || view.scope != msg.scope {
+			return m, nil
+		}
+		view.loading = false
+		view.alerts, view.rules = msg.alerts, msg.rules
+		view.cursor = max(0, min(view.cursor, len(view.visible())-1))
+		view.err = ""
+		if msg.err != nil {
+			view.err = msg.err.Error()
+		}
+
+	case alertStateChangedMsg:
+		if msg.err != nil {
+			m.addToast(msg.err.Error(), "failure")
+			return m, nil
+		}
+		m.addToast(fmt.Sprintf("%s is now %s", msg.name, strings.ToLower(msg.state)), "success")
+		cmds := []tea.Cmd{m.pollAlertCount()}
+		if view != nil {
+			view.loading = true
+			cmds = append(cmds, loadAlertsCmd(view.scope))
+		}
+		return m, tea.Batch(cmds...)
+
+	case alertCountMsg:
+		if msg.generation != m.alertPoll {
+			return m, nil
+		}
+		// A failed count keeps the last one; Alerts Management may not be
+		// available to everyone
+		if msg.err == nil {
+			m.openAlertCount = msg.count
+		}
+		generation := msg.generation
+		return m, tea.Tick(alertPollInterval, func(time.Time) tea.Msg {
+			return alertCountTickMsg{generation: generation}
+		})
+
+	case alertCountTickMsg:
+		if msg.generation == m.alertPoll {
+			return m, loadAlertCountCmd(msg.generation)
+		}
+	}
+	return m, nil
+}
+
+// alertSeverityStyle colors severities from Sev0, the most severe, to Sev4
+func alertSeverityStyle(severity string) lipgloss.Style {
+	switch severity {
+	case "Sev0", "Sev1":
+		return lipgloss.NewStyle().Foreground(colorRed).Bold(true)
+	case "Sev2":
+		return lipgloss.NewStyle().Foreground(colorYellow)
+	case "Sev3":
+		return lipgloss.NewStyle().Foreground(colorAqua)
+	}
+	return lipgloss.NewStyle().Foreground(colorGray)
+}
+
+// renderAlerts draws the fired alerts of the scope, newest first, and the
+// alert rules that watch it
+func (m model) renderAlerts(width, height int) string {
+	view := m.alertsView
+	textWidth := max(40, width-4)
+
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(colorBlue)
+	labelStyle := lipgloss.NewStyle().Foreground(colorGray)
+	faint := lipgloss.NewStyle().Foreground(colorGray).Italic(true)
+	selectedStyle := lipgloss.NewStyle().Foreground(colorYellow).Bold(true)
+
+	var lines []string
+	lines = append(lines, headerStyle.Render("🔔 Alerts: "+view.scopeName))
+
+	states := map[string]int{}
+	for _, alert := range view.alerts {
+		states[alert.State]++
+	}
+	switch {
+	case view.loading:
+		lines = append(lines, "⏳ Loading alerts...")
+	default:
+		lines = append(lines, labelStyle.Render(fmt.Sprintf("Last 30 days: %d new, %d acknowledged, %d closed",
+			states[alerts.StateNew], states[alerts.StateAcknowledged], states[alerts.StateClosed])))
+	}
+	if view.err != "" {
+		lines = append(lines, lipgloss.NewStyle().Foreground(colorRed).Render(truncateText("❌ "+view.err, textWidth)))
+	}
+	lines = append(lines, "")
+
+	// The rules take a few rows below the alerts
+	ruleRows := min(len(view.rules), 5)
+	available := height - 4 - len(lines) - ruleRows - 5
+	if m.renderTabBar() != "" {
+		available -= 2
+	}
+
+	visible := view.visible()
+	title := fmt.Sprintf("Fired alerts (%d open)", states[alerts.StateNew]+states[alerts.StateAcknowledged])
+	if view.showClosed {
+		title = fmt.Sprintf("Fired alerts (%d)", len(view.alerts))
+	}
+	lines = append(lines, headerStyle.Render(title))
+	if len(visible) == 0 && !view.loading {
+		lines = append(lines, faint.Render("No open alerts"))
+	}
+
+	details := []string{}
+	if alert, ok := view.selected(); ok && view.expanded {
+		if alert.Description != "" {
+			details = append(details, "Description: "+alert.Description)
+		}
+		rule := alert.Rule
+		if i := strings.LastIndex(rule, "/"); i >= 0 {
+			rule = rule[i+1:]
+		}
+		details = append(details,
+			"Rule:        "+rule,
+			"Target:      "+alert.TargetID,
+			"Condition:   "+alert.Condition+", monitored by "+alert.MonitorService,
+			"Modified:    "+alert.Modified.Local().Format("Jan 02 15:04"))
+	}
+	rows := max(3, available-len(details))
+	start := max(0, min(view.cursor-rows/2, len(visible)-rows))
+	// The name and target take what the state, condition and time leave
+	nameWidth := max(16, textWidth-46)
+	for i := start; i < min(len(visible), start+rows); i++ {
+		alert := visible[i]
+		marker := "  "
+		if i == view.cursor {
+			marker = theme.Icon("❯ ", "> ")
+		}
+		name := alert.Name
+		if alert.TargetName != "" && !strings.EqualFold(alert.TargetID, view.scope) {
+			name += " · " + alert.TargetName
+		}
+		name = truncateText(name, nameWidth)
+		row := fmt.Sprintf("%s%s  %-12s  %-8s  %s",
+			name, strings.Repeat(" ", nameWidth-ansi.StringWidth(name)), alert.State, alert.Condition, alert.Fired.Local().Format("Jan 02 15:04"))
+		if i == view.cursor {
+			row = selectedStyle.Render(row)
+		}
+		lines = append(lines, truncateText(marker+alertSeverityStyle(alert.Severity).Render(fmt.Sprintf("%-4s", alert.Severity))+"  "+row, textWidth))
+		if i == view.cursor {
+			for _, detail := range details {
+				lines = append(lines, labelStyle.Render(truncateText("      "+detail, textWidth)))
+			}
+		}
+	}
+
+	lines = append(lines, "", headerStyle.Render(fmt.Sprintf("Alert rules watching %s (%d)", view.scopeName, len(view.rules))))
+	if len(view.rules) == 0 && !view.loading {
+		lines = append(lines, faint.Render("No metric alert rules watch this scope"))
+	}
+	for _, rule := range view.rules[:ruleRows] {
+		status := lipgloss.NewStyle().Foreground(colorGreen).Render("● " + rule.Status)
+		if rule.Status != "OK" {
+			status = labelStyle.Render("○ " + rule.Status)
+		}
+		lines = append(lines, truncateText("  "+status+"  "+rule.Name+labelStyle.Render("  "+rule.Details), textWidth))
+	}
+	if hidden := len(view.rules) - ruleRows; hidden > 0 {
+		lines = append(lines, faint.Render(fmt.Sprintf("  … %d more", hidden)))
+	}
+
+	lines = append(lines, "", faint.Render(truncateText("Enter:Details  a:Ack  c:Close  u:Reopen  s:Closed  r:Refresh  Esc:Back", textWidth)))
+	return strings.Join(lines, "\n")
+}
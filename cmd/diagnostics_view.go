@@ -0,0 +1,634 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/olafkfreund/azure-tui/internal/azure/diagnostics"
+	"github.com/olafkfreund/azure-tui/internal/azure/loganalytics"
+	"github.com/olafkfreund/azure-tui/internal/config"
+	"github.com/olafkfreund/azure-tui/internal/jobs"
+	"github.com/olafkfreund/azure-tui/internal/theme"
+)
+
+// diagnosticsView audits the diagnostic settings of the resources of a
+// group, a folder, a resource or the subscription against the central
+// workspace, and applies a settings template to many of them at once
+type diagnosticsView struct {
+	group       string               // Resource group audited, "" for the subscription
+	targets     []diagnostics.Target // Resources audited instead of a group or the subscription
+	scopeName   string
+	template    diagnostics.Template
+	audits      []diagnostics.Audit
+	selected    map[string]bool // Resource IDs the template is applied to
+	onlyFlagged bool
+	cursor      int
+	expanded    bool // The selected resource shows its settings
+	workspaces  []loganalytics.Workspace
+	picking     bool // Choosing the central workspace
+	pickCursor  int
+	changes     []diagnostics.Change // Preview of the template, nil when not previewing
+	planning    bool
+	loading     bool
+	err         string
+}
+
+// visible returns the audits listed
+func (v *diagnosticsView) visible() []diagnostics.Audit {
+	if !v.onlyFlagged {
+		return v.audits
+	}
+	var audits []diagnostics.Audit
+	for _, audit := range v.audits {
+		if audit.Flagged() {
+			audits = append(audits, audit)
+		}
+	}
+	return audits
+}
+
+// chosen returns the resources to apply the template to: the selected
+// ones, or all that are flagged when none is selected
+func (v *diagnosticsView) chosen() []diagnostics.Audit {
+	var selected, flagged []diagnostics.Audit
+	for _, audit := range v.audits {
+		if v.selected[audit.ID] {
+			selected = append(selected, audit)
+		}
+		if audit.Flagged() {
+			flagged = append(flagged, audit)
+		}
+	}
+	if len(selected) > 0 {
+		return selected
+	}
+	return flagged
+}
+
+// workspaceName returns the name of the central workspace
+func (v *diagnosticsView) workspaceName() string {
+	for _, workspace := range v.workspaces {
+		if strings.EqualFold(workspace.ID, v.template.WorkspaceID) {
+			return workspace.Name + " (" + workspace.ResourceGroup + ")"
+		}
+	}
+	return resourceNameOf(v.template.WorkspaceID)
+}
+
+// Diagnostic settings messages
+type diagnosticsAuditMsg struct {
+	scopeName string
+	audits    []diagnostics.Audit
+	err       error
+}
+
+type diagnosticsWorkspacesMsg struct {
+	workspaces []loganalytics.Workspace
+	err        error
+}
+
+type diagnosticsPreviewMsg struct {
+	changes []diagnostics.Change
+}
+
+type diagnosticsSavedMsg struct {
+	err error
+}
+
+type diagnosticsAppliedMsg struct{}
+
+// auditDiagnosticsCmd audits targets, or the resources of a group or of
+// the subscription when targets is nil
+func auditDiagnosticsCmd(scopeName, group string, targets []diagnostics.Target, workspaceID string) tea.Cmd {
+	return func() tea.Msg {
+		if targets == nil {
+			var err error
+			if targets, err = diagnostics.ListTargets(group); err != nil {
+				return diagnosticsAuditMsg{scopeName: scopeName, err: err}
+			}
+		}
+		return diagnosticsAuditMsg{scopeName: scopeName, audits: diagnostics.AuditTargets(targets, workspaceID)}
+	}
+}
+
+func loadDiagnosticsWorkspacesCmd() tea.Cmd {
+	return func() tea.Msg {
+		workspaces, err := loganalytics.ListWorkspaces()
+		return diagnosticsWorkspacesMsg{workspaces: workspaces, err: err}
+	}
+}
+
+func previewDiagnosticsCmd(template diagnostics.Template, audits []diagnostics.Audit) tea.Cmd {
+	return func() tea.Msg {
+		return diagnosticsPreviewMsg{changes: template.Preview(audits)}
+	}
+}
+
+func saveDiagnosticsWorkspaceCmd(workspaceID string) tea.Cmd {
+	return func() tea.Msg {
+		return diagnosticsSavedMsg{err: config.SaveDiagnosticsWorkspace(workspaceID)}
+	}
+}
+
+// diagnosticsTarget returns the audit target of a resource
+func diagnosticsTarget(resource AzureResource) diagnostics.Target {
+	return diagnostics.Target{ID: resource.ID, Name: resource.Name, Type: resource.Type, ResourceGroup: resource.ResourceGroup}
+}
+
+// openDiagnostics audits the resource group, folder or resource selected
+// in the tree, the resource of the details panel, or the subscription
+func (m *model) openDiagnostics() tea.Cmd {
+	settings := config.GetDiagnosticsConfig()
+	view := &diagnosticsView{
+		scopeName: "subscription",
+		template: diagnostics.Template{
+			Name:        settings.SettingName,
+			WorkspaceID: settings.Workspace,
+			Logs:        *settings.Logs,
+			Metrics:     *settings.Metrics,
+		},
+		selected: map[string]bool{},
+		loading:  true,
+	}
+	if m.currentSubscription != nil {
+		view.scopeName = m.currentSubscription.Name
+	}
+
+	scoped := false
+	if m.selectedPanel == 0 && m.treeView != nil {
+		if node := m.treeView.GetSelectedNode(); node != nil {
+			scoped = true
+			switch data := node.ResourceData.(type) {
+			case AzureResource:
+				view.scopeName, view.targets = data.Name, []diagnostics.Target{diagnosticsTarget(data)}
+			case config.Bookmark:
+				if data.Type == "group" {
+					view.scopeName, view.group = data.Name, data.Name
+				} else {
+					view.scopeName = data.Name
+					view.targets = []diagnostics.Target{{ID: data.ResourceID, Name: data.Name, Type: data.Type}}
+				}
+			default:
+				switch node.Type {
+				case "group":
+					view.scopeName, view.group = node.Name, node.Name
+				case "smart-folder", "favorites":
+					// The resources in the folder, not the groups bookmarked
+					view.scopeName, view.targets = node.Name, []diagnostics.Target{}
+					for _, child := range node.Children {
+						switch data := child.ResourceData.(type) {
+						case AzureResource:
+							view.targets = append(view.targets, diagnosticsTarget(data))
+						case config.Bookmark:
+							if data.Type != "group" {
+								view.targets = append(view.targets, diagnostics.Target{ID: data.ResourceID, Name: data.Name, Type: data.Type})
+							}
+						}
+					}
+				default:
+					scoped = false
+				}
+			}
+		}
+	}
+	if !scoped && m.selectedResource != nil {
+		view.scopeName, view.targets = m.selectedResource.Name, []diagnostics.Target{diagnosticsTarget(*m.selectedResource)}
+	}
+
+	m.diagnosticsView = view
+	m.selectedPanel = 1
+	m.rightPanelScrollOffset = 0
+	m.pushView("diagnostics")
+	return tea.Batch(loadDiagnosticsWorkspacesCmd(),
+		auditDiagnosticsCmd(view.scopeName, view.group, view.targets, view.template.WorkspaceID))
+}
+
+// applyDiagnostics applies the template to the previewed resources as a
+// background job, and audits them again when it is done
+func (m *model) applyDiagnostics() tea.Cmd {
+	view := m.diagnosticsView
+	template := view.template
+	var changes []diagnostics.Change
+	for _, change := range view.changes {
+		if change.Action != "skip" {
+			changes = append(changes, change)
+		}
+	}
+	if len(changes) == 0 {
+		m.addToast("Nothing to apply", "notice")
+		return nil
+	}
+
+	run := func(ctx context.Context, out io.Writer) (string, error) {
+		failed := 0
+		for _, change := range changes {
+			if ctx.Err() != nil {
+				return "", ctx.Err()
+			}
+			if err := template.Apply(ctx, change); err != nil {
+				failed++
+				fmt.Fprintf(out, "✗ %s: %v\n", change.Name, err)
+				continue
+			}
+			fmt.Fprintf(out, "✓ %s: %s %s\n", change.Name, change.Action+"d", template.Name)
+		}
+		if failed > 0 {
+			return "", fmt.Errorf("%d of %d resources failed", failed, len(changes))
+		}
+		return fmt.Sprintf("%d resources send to %s", len(changes), resourceNameOf(template.WorkspaceID)), nil
+	}
+	done := func(jobs.Job) tea.Msg {
+		return diagnosticsAppliedMsg{}
+	}
+	view.changes = nil
+	return m.startJob(fmt.Sprintf("Apply diagnostic settings to %d resources", len(changes)), run, done)
+}
+
+// planDiagnostics plans the template again on the previewed resources,
+// e.g. after logs or metrics were switched
+func (v *diagnosticsView) planDiagnostics() {
+	for i, change := range v.changes {
+		if change.Categories != nil {
+			v.changes[i] = v.template.Plan(change.Audit, change.Categories)
+		}
+	}
+}
+
+// updateDiagnosticsView handles keys in the diagnostics view. Keys it does
+// not use are left to the normal key map.
+func (m model) updateDiagnosticsView(msg tea.KeyMsg) (tea.Model, tea.Cmd, bool) {
+	view := m.diagnosticsView
+	key := msg.String()
+
+	if view.picking {
+		switch key {
+		case "j", "down":
+			view.pickCursor = min(view.pickCursor+1, max(0, len(view.workspaces)-1))
+		case "k", "up":
+			view.pickCursor = max(view.pickCursor-1, 0)
+		case "enter":
+			if view.pickCursor >= len(view.workspaces) {
+				return m, nil, true
+			}
+			view.picking = false
+			view.template.WorkspaceID = view.workspaces[view.pickCursor].ID
+			diagnostics.Reclassify(view.audits, view.template.WorkspaceID)
+			view.changes = nil
+			return m, saveDiagnosticsWorkspaceCmd(view.template.WorkspaceID), true
+		case "esc":
+			view.picking = false
+		default:
+			return m, nil, false
+		}
+		return m, nil, true
+	}
+
+	if view.changes != nil || view.planning {
+		switch key {
+		case "y", "enter":
+			if view.planning {
+				return m, nil, true
+			}
+			return m, m.applyDiagnostics(), true
+		case "l":
+			view.template.Logs = !view.template.Logs
+			view.planDiagnostics()
+		case "m":
+			view.template.Metrics = !view.template.Metrics
+			view.planDiagnostics()
+		case "esc", "n":
+			view.changes, view.planning = nil, false
+		default:
+			return m, nil, false
+		}
+		return m, nil, true
+	}
+
+	visible := view.visible()
+	switch key {
+	case "j", "down":
+		view.cursor = min(view.cursor+1, max(0, len(visible)-1))
+	case "k", "up":
+		view.cursor = max(view.cursor-1, 0)
+	case "enter":
+		view.expanded = !view.expanded
+	case " ", "space":
+		if view.cursor < len(visible) {
+			id := visible[view.cursor].ID
+			view.selected[id] = !view.selected[id]
+			view.cursor = min(view.cursor+1, max(0, len(visible)-1))
+		}
+	case "a":
+		// Select the flagged resources, or clear the selection
+		if len(view.selected) > 0 {
+			clear(view.selected)
+		} else {
+			for _, audit := range view.audits {
+				if audit.Flagged() {
+					view.selected[audit.ID] = true
+				}
+			}
+		}
+	case "f":
+		view.onlyFlagged = !view.onlyFlagged
+		view.cursor = 0
+	case "w":
+		if len(view.workspaces) == 0 {
+			m.addToast("No Log Analytics workspaces found", "notice")
+			return m, nil, true
+		}
+		view.picking, view.pickCursor = true, 0
+		for i, workspace := range view.workspaces {
+			if strings.EqualFold(workspace.ID, view.template.WorkspaceID) {
+				view.pickCursor = i
+			}
+		}
+	case "p":
+		if view.template.WorkspaceID == "" {
+			m.addToast("Choose the central workspace first (w)", "notice")
+			return m, nil, true
+		}
+		chosen := view.chosen()
+		if len(chosen) == 0 {
+			m.addToast("No resources to apply diagnostic settings to", "notice")
+			return m, nil, true
+		}
+		view.planning = true
+		return m, previewDiagnosticsCmd(view.template, chosen), true
+	case "r":
+		view.loading, view.err = true, ""
+		return m, auditDiagnosticsCmd(view.scopeName, view.group, view.targets, view.template.WorkspaceID), true
+	default:
+		return m, nil, false
+	}
+	return m, nil, true
+}
+
+// updateDiagnostics handles the diagnostic settings messages
+func (m model) updateDiagnostics(msg tea.Msg) (tea.Model, tea.Cmd) {
+	view := m.diagnosticsView
+	if view == nil {
+		return m, nil
+	}
+	switch msg := msg.(type) {
+	case diagnosticsAuditMsg:
+		if msg.scopeName != view.scopeName {
+			return m, nil
+		}
+		view.loading = false
+		if msg.err != nil {
+			view.err = msg.err.Error()
+			return m, nil
+		}
+		view.audits = msg.audits
+		// The selection keeps the resources that are still listed
+		listed := map[string]bool{}
+		for _, audit := range view.audits {
+			listed[audit.ID] = true
+		}
+		for id := range view.selected {
+			if !listed[id] {
+				delete(view.selected, id)
+			}
+		}
+		view.cursor = max(0, min(view.cursor, len(view.visible())-1))
+
+	case diagnosticsWorkspacesMsg:
+		if msg.err != nil {
+			view.err = msg.err.Error()
+			return m, nil
+		}
+		view.workspaces = msg.workspaces
+		// Ask for the central workspace the first time
+		if view.template.WorkspaceID == "" && len(view.workspaces) > 0 && view.changes == nil {
+			view.picking, view.pickCursor = true, 0
+		}
+
+	case diagnosticsPreviewMsg:
+		if !view.planning {
+			return m, nil
+		}
+		view.planning, view.changes = false, msg.changes
+
+	case diagnosticsSavedMsg:
+		if msg.err != nil {
+			m.addToast("Failed to save the central workspace: "+msg.err.Error(), "failure")
+			return m, tea.Tick(toastDuration, func(time.Time) tea.Msg { return toastExpiredMsg{} })
+		}
+
+	case diagnosticsAppliedMsg:
+		view.loading = true
+		return m, auditDiagnosticsCmd(view.scopeName, view.group, view.targets, view.template.WorkspaceID)
+	}
+	return m, nil
+}
+
+// diagnosticsStatus returns the icon and label of an audit state
+func diagnosticsStatus(status string) (string, lipgloss.Style) {
+	switch status {
+	case diagnostics.StatusOK:
+		return "✅ OK", lipgloss.NewStyle().Foreground(colorGreen)
+	case diagnostics.StatusMissing:
+		return "❌ None", lipgloss.NewStyle().Foreground(colorRed).Bold(true)
+	case diagnostics.StatusWrongWorkspace:
+		return "⚠ Elsewhere", lipgloss.NewStyle().Foreground(colorYellow)
+	case diagnostics.StatusUnsupported:
+		return "– N/A", lipgloss.NewStyle().Foreground(colorGray)
+	}
+	return "❓ Error", lipgloss.NewStyle().Foreground(colorRed)
+}
+
+// renderDiagnostics draws the audit, the workspace picker or the preview
+// of applying the template
+func (m model) renderDiagnostics(width, height int) string {
+	view := m.diagnosticsView
+	textWidth := max(40, width-4)
+
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(colorBlue)
+	labelStyle := lipgloss.NewStyle().Foreground(colorGray)
+	faint := lipgloss.NewStyle().Foreground(colorGray).Italic(true)
+	selectedStyle := lipgloss.NewStyle().Foreground(colorYellow).Bold(true)
+
+	// The header stays, the rows below scroll
+	var header []string
+	header = append(header, headerStyle.Render("📋 Diagnostic settings: "+view.scopeName))
+	if view.template.WorkspaceID == "" {
+		header = append(header, labelStyle.Render("Central workspace: ")+lipgloss.NewStyle().Foreground(colorYellow).Render("not set, press w to choose one"))
+	} else {
+		header = append(header, truncateText(labelStyle.Render("Central workspace: ")+view.workspaceName(), textWidth))
+	}
+	if !view.loading {
+		counts := map[string]int{}
+		for _, audit := range view.audits {
+			counts[audit.Status]++
+		}
+		summary := fmt.Sprintf("%d resources", len(view.audits))
+		for _, count := range []struct{ status, text string }{
+			{diagnostics.StatusMissing, "without settings"},
+			{diagnostics.StatusWrongWorkspace, "sending elsewhere"},
+			{diagnostics.StatusOK, "OK"},
+			{diagnostics.StatusUnsupported, "unsupported"},
+			{diagnostics.StatusError, "failed"},
+		} {
+			if counts[count.status] > 0 {
+				summary += fmt.Sprintf(" · %d %s", counts[count.status], count.text)
+			}
+		}
+		header = append(header, labelStyle.Render(truncateText(summary, textWidth)))
+	}
+	if view.loading {
+		header = append(header, "⏳ Auditing diagnostic settings...")
+	}
+	if view.planning {
+		header = append(header, "⏳ Looking up the categories of the resources...")
+	}
+	if view.err != "" {
+		header = append(header, lipgloss.NewStyle().Foreground(colorRed).Render(truncateText("❌ "+view.err, textWidth)))
+	}
+	header = append(header, "")
+
+	var body []string
+	cursorLine := 0
+	footer := "Space:Select  a:Flagged  f:Filter  w:Workspace  p:Preview  r:Refresh"
+
+	switch {
+	case view.picking:
+		footer = "j/k:Move  Enter:Choose  Esc:Cancel"
+		body = append(body, headerStyle.Render("Choose the central Log Analytics workspace"))
+		for i, workspace := range view.workspaces {
+			prefix, name := "  ", workspace.Name
+			if i == view.pickCursor {
+				cursorLine = len(body)
+				prefix, name = theme.Icon("❯ ", "> "), selectedStyle.Render(name)
+			}
+			body = append(body, truncateText(prefix+name+labelStyle.Render("  "+workspace.ResourceGroup+" · "+workspace.Location), textWidth))
+		}
+
+	case view.changes != nil:
+		footer = "y:Apply  l:Logs  m:Metrics  Esc:Cancel"
+		onOff := map[bool]string{true: "all", false: "off"}
+		actions := map[string]int{}
+		for _, change := range view.changes {
+			actions[change.Action]++
+		}
+		body = append(body,
+			headerStyle.Render(fmt.Sprintf("Preview: setting %q to %s", view.template.Name, resourceNameOf(view.template.WorkspaceID))),
+			labelStyle.Render(fmt.Sprintf("Logs: %s  Metrics: %s  ·  %d to create, %d to update, %d skipped",
+				onOff[view.template.Logs], onOff[view.template.Metrics], actions["create"], actions["update"], actions["skip"])),
+			"")
+		for _, change := range view.changes {
+			var action, text string
+			switch change.Action {
+			case "create":
+				action = lipgloss.NewStyle().Foreground(colorGreen).Render("+ create")
+			case "update":
+				action = lipgloss.NewStyle().Foreground(colorYellow).Render("~ update")
+			default:
+				action = labelStyle.Render("- skip  ")
+			}
+			if change.Action == "skip" {
+				text = labelStyle.Render(change.Reason)
+			} else {
+				var entries []string
+				for _, entry := range append(append([]diagnostics.Entry(nil), change.Logs...), change.Metrics...) {
+					entries = append(entries, entry.String())
+				}
+				text = strings.Join(entries, ", ")
+			}
+			body = append(body, truncateText(fmt.Sprintf("  %s  %-20s  %s", action, change.Name, text), textWidth))
+		}
+
+	default:
+		visible := view.visible()
+		selected := 0
+		for _, audit := range view.audits {
+			if view.selected[audit.ID] {
+				selected++
+			}
+		}
+		title := fmt.Sprintf("Resources (%d)", len(visible))
+		if view.onlyFlagged {
+			title = fmt.Sprintf("Flagged resources (%d)", len(visible))
+		}
+		if selected > 0 {
+			title += fmt.Sprintf(", %d selected", selected)
+		}
+		body = append(body, headerStyle.Render(title))
+		if len(visible) == 0 && !view.loading {
+			body = append(body, faint.Render("  None"))
+		}
+		// Plain mode spells the states out, the column grows to fit them
+		stateWidth := 12
+		for _, audit := range visible {
+			label, _ := diagnosticsStatus(audit.Status)
+			stateWidth = max(stateWidth, lipgloss.Width(theme.Text(label)))
+		}
+		for i, audit := range visible {
+			prefix := "  "
+			if i == view.cursor {
+				cursorLine = len(body)
+				prefix = theme.Icon("❯ ", "> ")
+			}
+			check := "[ ] "
+			if view.selected[audit.ID] {
+				check = "[x] "
+			}
+			label, style := diagnosticsStatus(audit.Status)
+			state := style.Render(padText(label, stateWidth))
+			name := fmt.Sprintf("%-20s", audit.Name)
+			if i == view.cursor {
+				name = selectedStyle.Render(name)
+			}
+			// The workspaces the resource sends to, the rest shows with its
+			// settings
+			var destinations []string
+			for _, setting := range audit.Settings {
+				if setting.WorkspaceID != "" {
+					destinations = append(destinations, "→ "+resourceNameOf(setting.WorkspaceID))
+				} else {
+					destinations = append(destinations, "→ "+setting.Destinations())
+				}
+			}
+			text := labelStyle.Render(fmt.Sprintf("%-16s", resourceNameOf(audit.Type))) + "  " + strings.Join(destinations, ", ")
+			body = append(body, truncateText(prefix+check+state+"  "+name+"  "+text, textWidth))
+
+			if i == view.cursor && view.expanded {
+				if audit.Err != "" {
+					body = append(body, truncateText("      "+lipgloss.NewStyle().Foreground(colorRed).Render(audit.Err), textWidth))
+				}
+				if len(audit.Settings) == 0 && audit.Err == "" {
+					body = append(body, faint.Render("      No diagnostic settings"))
+				}
+				for _, setting := range audit.Settings {
+					enabled := strings.Join(setting.Enabled(), ", ")
+					if enabled == "" {
+						enabled = "nothing enabled"
+					}
+					body = append(body, truncateText("      "+setting.Name+labelStyle.Render(" → "+setting.Destinations()+": ")+enabled, textWidth))
+				}
+			}
+		}
+	}
+
+	// Scroll the rows to keep the selected one and its settings in view
+	available := height - 4 - len(header) - 2
+	if m.renderTabBar() != "" {
+		available -= 2
+	}
+	available = max(5, available)
+	start := 0
+	if len(body) > available {
+		start = max(0, min(cursorLine-available/3, len(body)-available))
+	}
+	end := min(len(body), start+available)
+
+	lines := append(header, body[start:end]...)
+	lines = append(lines, "", faint.Render(truncateText(footer, textWidth)))
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,528 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/olafkfreund/azure-tui/internal/azure/health"
+	"github.com/olafkfreund/azure-tui/internal/config"
+	"github.com/olafkfreund/azure-tui/internal/theme"
+	"github.com/olafkfreund/azure-tui/internal/tui"
+)
+
+// How often the health of the resources in the tree is refreshed
+const healthPollInterval = 5 * time.Minute
+
+// healthView shows the Resource Health of a resource, or the unhealthy
+// resources of the subscription, and the Service Health incidents
+type healthView struct {
+	resourceID      string // "" for the subscription
+	name            string
+	location        string
+	statuses        []health.Status // History of the resource, newest first, or the unhealthy resources
+	events          []health.Event
+	locations       []string // Regions in use, to mark the incidents that reach them
+	cursor          int      // Over the statuses, then the events
+	expanded        bool     // The selected entry shows its details
+	loadingStatuses bool
+	loadingEvents   bool
+	err             string
+}
+
+// entries returns the number of selectable entries
+func (v *healthView) entries() int {
+	return len(v.historyStatuses()) + len(v.events)
+}
+
+// current returns the current status of the resource
+func (v *healthView) current() (health.Status, bool) {
+	if v.resourceID == "" || len(v.statuses) == 0 {
+		return health.Status{}, false
+	}
+	return v.statuses[0], true
+}
+
+// historyStatuses returns the statuses listed as entries: the earlier
+// health events of a resource, or the unhealthy resources
+func (v *healthView) historyStatuses() []health.Status {
+	if v.resourceID != "" && len(v.statuses) > 0 {
+		return v.statuses[1:]
+	}
+	return v.statuses
+}
+
+// Health messages
+type healthHistoryMsg struct {
+	resourceID string
+	statuses   []health.Status
+	err        error
+}
+
+type serviceEventsMsg struct {
+	events []health.Event
+	err    error
+}
+
+type healthStatusesMsg struct {
+	generation int
+	statuses   []health.Status
+	err        error
+}
+
+type healthTickMsg struct {
+	generation int
+}
+
+func loadHealthHistoryCmd(resourceID string) tea.Cmd {
+	return func() tea.Msg {
+		statuses, err := health.History(resourceID)
+		return healthHistoryMsg{resourceID: resourceID, statuses: statuses, err: err}
+	}
+}
+
+func loadServiceEventsCmd(subscriptionIDs []string) tea.Cmd {
+	return func() tea.Msg {
+		events, err := health.ServiceEvents(subscriptionIDs)
+		return serviceEventsMsg{events: events, err: err}
+	}
+}
+
+func loadHealthStatusesCmd(generation int) tea.Cmd {
+	return func() tea.Msg {
+		statuses, err := health.ListStatuses()
+		return healthStatusesMsg{generation: generation, statuses: statuses, err: err}
+	}
+}
+
+// pollHealth restarts the polling of the health of the resources, e.g. for
+// a new subscription. Polls of an earlier generation stop.
+func (m *model) pollHealth() tea.Cmd {
+	m.healthPoll++
+	return loadHealthStatusesCmd(m.healthPoll)
+}
+
+// healthIcon returns the icon of an availability state
+func healthIcon(state string) string {
+	switch state {
+	case health.Available:
+		return "🟢"
+	case health.Degraded:
+		return "🟡"
+	case health.Unavailable:
+		return "🔴"
+	}
+	return "❔"
+}
+
+// healthCause says who caused a health event
+func healthCause(status health.Status) string {
+	switch status.Cause {
+	case health.CausePlatform:
+		return "Azure"
+	case health.CauseUser:
+		return "Your action"
+	}
+	return ""
+}
+
+// healthBadge returns the tree badge of a status: its state, and whether
+// Azure is to blame when the resource is unhealthy
+func healthBadge(status health.Status) string {
+	badge := healthIcon(status.State)
+	if !status.Healthy() && status.Cause == health.CausePlatform {
+		badge += " Azure"
+	}
+	return badge
+}
+
+// unhealthy returns the resources that are not available, the worst first
+func (m model) unhealthy() []health.Status {
+	rank := map[string]int{health.Unavailable: 0, health.Degraded: 1, health.Unknown: 2}
+	var statuses []health.Status
+	for _, status := range m.resourceHealth {
+		if !status.Healthy() {
+			statuses = append(statuses, status)
+		}
+	}
+	sort.SliceStable(statuses, func(i, j int) bool {
+		if rank[statuses[i].State] != rank[statuses[j].State] {
+			return rank[statuses[i].State] < rank[statuses[j].State]
+		}
+		return strings.ToLower(resourceNameOf(statuses[i].ResourceID)) < strings.ToLower(resourceNameOf(statuses[j].ResourceID))
+	})
+	return statuses
+}
+
+// resourceNameOf returns the last segment of a resource ID
+func resourceNameOf(id string) string {
+	return id[strings.LastIndex(id, "/")+1:]
+}
+
+// applyHealthBadges shows the health of the resources next to them in the
+// tree, including the favorites and smart folders
+func (m *model) applyHealthBadges() {
+	if m.treeView == nil {
+		return
+	}
+	var walk func(node *tui.TreeNode)
+	walk = func(node *tui.TreeNode) {
+		id := ""
+		switch data := node.ResourceData.(type) {
+		case AzureResource:
+			id = data.ID
+		case config.Bookmark:
+			id = data.ResourceID
+		}
+		node.Badge = ""
+		if status, ok := m.resourceHealth[strings.ToLower(id)]; ok && id != "" {
+			node.Badge = healthBadge(status)
+		}
+		for _, child := range node.Children {
+			walk(child)
+		}
+	}
+	walk(m.treeView.Root)
+}
+
+// openHealth shows the health of the resource selected in the tree or in
+// the details panel, or the unhealthy resources of the subscription
+func (m *model) openHealth() tea.Cmd {
+	view := &healthView{name: "subscription", locations: m.quotaLocations()}
+	if m.currentSubscription != nil {
+		view.name = m.currentSubscription.Name
+	}
+	resource := m.selectedResource
+	if m.selectedPanel == 0 && m.treeView != nil {
+		resource = nil
+		if node := m.treeView.GetSelectedNode(); node != nil {
+			if data, ok := node.ResourceData.(AzureResource); ok && data.ID != "" {
+				resource = &data
+			}
+		}
+	}
+
+	if resource != nil {
+		view.resourceID, view.name, view.location = resource.ID, resource.Name, resource.Location
+	} else {
+		// The last poll shows until the new one is in
+		view.statuses = m.unhealthy()
+	}
+
+	m.healthView = view
+	m.selectedPanel = 1
+	m.rightPanelScrollOffset = 0
+	m.pushView("health")
+	return m.refreshHealth()
+}
+
+// refreshHealth loads the health shown, and the incidents of all the
+// subscriptions, again
+func (m *model) refreshHealth() tea.Cmd {
+	view := m.healthView
+	view.loadingStatuses, view.loadingEvents, view.err = true, true, ""
+	subscriptionIDs := make([]string, 0, len(m.subscriptions))
+	for _, subscription := range m.subscriptions {
+		subscriptionIDs = append(subscriptionIDs, subscription.ID)
+	}
+	if view.resourceID != "" {
+		return tea.Batch(loadServiceEventsCmd(subscriptionIDs), loadHealthHistoryCmd(view.resourceID))
+	}
+	return tea.Batch(loadServiceEventsCmd(subscriptionIDs), m.pollHealth())
+}
+
+// updateHealthView handles keys in the health view. Keys it does not use
+// are left to the normal key map.
+func (m model) updateHealthView(msg tea.KeyMsg) (tea.Model, tea.Cmd, bool) {
+	view := m.healthView
+	switch msg.String() {
+	case "j", "down":
+		view.cursor = min(view.cursor+1, max(0, view.entries()-1))
+	case "k", "up":
+		view.cursor = max(view.cursor-1, 0)
+	case "enter", " ", "space":
+		view.expanded = !view.expanded
+	case "r":
+		return m, m.refreshHealth(), true
+	default:
+		return m, nil, false
+	}
+	return m, nil, true
+}
+
+// updateHealth handles the health messages
+func (m model) updateHealth(msg tea.Msg) (tea.Model, tea.Cmd) {
+	view := m.healthView
+	switch msg := msg.(type) {
+	case healthHistoryMsg:
+		if view == nil || view.resourceID != msg.resourceID {
+			return m, nil
+		}
+		view.loadingStatuses = false
+		if msg.err != nil {
+			view.err = msg.err.Error()
+			return m, nil
+		}
+		view.statuses = msg.statuses
+		view.cursor = max(0, min(view.cursor, view.entries()-1))
+		// The history has the newest status of the resource, the tree
+		// shows it too
+		if current, ok := view.current(); ok {
+			if m.resourceHealth == nil {
+				m.resourceHealth = map[string]health.Status{}
+			}
+			m.resourceHealth[strings.ToLower(msg.resourceID)] = current
+			m.applyHealthBadges()
+		}
+
+	case serviceEventsMsg:
+		if view == nil {
+			return m, nil
+		}
+		view.loadingEvents = false
+		view.events = msg.events
+		view.cursor = max(0, min(view.cursor, view.entries()-1))
+		if msg.err != nil {
+			view.err = msg.err.Error()
+		}
+
+	case healthStatusesMsg:
+		if msg.generation != m.healthPoll {
+			return m, nil
+		}
+		// A failed poll keeps the last health; Resource Health may not be
+		// available to everyone
+		if msg.err == nil {
+			m.resourceHealth = make(map[string]health.Status, len(msg.statuses))
+			for _, status := range msg.statuses {
+				m.resourceHealth[strings.ToLower(status.ResourceID)] = status
+			}
+			m.applyHealthBadges()
+		}
+		if view != nil && view.resourceID == "" {
+			view.loadingStatuses = false
+			view.statuses = m.unhealthy()
+			view.cursor = max(0, min(view.cursor, view.entries()-1))
+			if msg.err != nil {
+				view.err = msg.err.Error()
+			}
+		}
+		generation := msg.generation
+		return m, tea.Tick(healthPollInterval, func(time.Time) tea.Msg {
+			return healthTickMsg{generation: generation}
+		})
+
+	case healthTickMsg:
+		if msg.generation == m.healthPoll {
+			return m, loadHealthStatusesCmd(msg.generation)
+		}
+	}
+	return m, nil
+}
+
+// healthStateStyle colors availability states
+func healthStateStyle(state string) lipgloss.Style {
+	switch state {
+	case health.Available:
+		return lipgloss.NewStyle().Foreground(colorGreen)
+	case health.Degraded:
+		return lipgloss.NewStyle().Foreground(colorYellow)
+	case health.Unavailable:
+		return lipgloss.NewStyle().Foreground(colorRed).Bold(true)
+	}
+	return lipgloss.NewStyle().Foreground(colorGray)
+}
+
+// renderHealth draws the current health and health events of the
+// resource, or the unhealthy resources, and the Service Health incidents
+func (m model) renderHealth(width, height int) string {
+	view := m.healthView
+	textWidth := max(40, width-4)
+
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(colorBlue)
+	labelStyle := lipgloss.NewStyle().Foreground(colorGray)
+	faint := lipgloss.NewStyle().Foreground(colorGray).Italic(true)
+	selectedStyle := lipgloss.NewStyle().Foreground(colorYellow).Bold(true)
+	timeText := func(t time.Time) string {
+		if t.IsZero() {
+			return "-"
+		}
+		return t.Local().Format("Jan 02 15:04")
+	}
+	detail := func(label, text string) []string {
+		var lines []string
+		for i, line := range strings.Split(ansi.Wrap(text, textWidth-20, ""), "\n") {
+			if i == 6 {
+				lines = append(lines, "                  …")
+				break
+			}
+			prefix := strings.Repeat(" ", 18)
+			if i == 0 {
+				prefix = "    " + labelStyle.Render(fmt.Sprintf("%-14s", label))
+			}
+			lines = append(lines, truncateText(prefix+line, textWidth))
+		}
+		return lines
+	}
+
+	// The header stays, the entries below scroll
+	var header []string
+	header = append(header, headerStyle.Render("🩺 Health: "+view.name))
+
+	activeHere := []string{}
+	for _, event := range view.events {
+		if event.Active && view.location != "" && event.Affects([]string{view.location}) {
+			activeHere = append(activeHere, event.Title)
+		}
+	}
+	if current, ok := view.current(); ok {
+		line := "Current: " + healthStateStyle(current.State).Render(healthIcon(current.State)+" "+current.State)
+		if cause := healthCause(current); cause != "" && !current.Healthy() {
+			line += labelStyle.Render("  caused by: ") + cause
+		}
+		header = append(header, truncateText(line, textWidth))
+		if current.Summary != "" {
+			header = append(header, truncateText("  "+current.Summary, textWidth))
+		}
+		header = append(header, labelStyle.Render(fmt.Sprintf("  Since %s, reported %s", timeText(current.Occurred), timeText(current.Reported))))
+		for _, action := range current.Actions {
+			header = append(header, truncateText(labelStyle.Render("  Recommended: ")+strings.ReplaceAll(action, "\n", " "), textWidth))
+		}
+	} else if view.resourceID == "" && !view.loadingStatuses {
+		states := map[string]int{}
+		for _, status := range m.resourceHealth {
+			states[status.State]++
+		}
+		header = append(header, labelStyle.Render(fmt.Sprintf("Resources: %d available, %d degraded, %d unavailable, %d unknown",
+			states[health.Available], states[health.Degraded], states[health.Unavailable], states[health.Unknown])))
+	}
+	if len(activeHere) > 0 {
+		header = append(header, lipgloss.NewStyle().Foreground(colorRed).Render(truncateText(
+			fmt.Sprintf("⚠ Azure incident in %s: %s", view.location, strings.Join(activeHere, "; ")), textWidth)))
+	}
+	if view.loadingStatuses || view.loadingEvents {
+		header = append(header, "⏳ Loading health...")
+	}
+	if view.err != "" {
+		header = append(header, lipgloss.NewStyle().Foreground(colorRed).Render(truncateText("❌ "+view.err, textWidth)))
+	}
+	header = append(header, "")
+
+	var body []string
+	cursorLine := 0
+	entry := 0
+	marker := func() string {
+		if entry == view.cursor {
+			cursorLine = len(body)
+			return theme.Icon("❯ ", "> ")
+		}
+		return "  "
+	}
+
+	statuses := view.historyStatuses()
+	if view.resourceID != "" {
+		body = append(body, headerStyle.Render(fmt.Sprintf("Health events (%d)", len(statuses))))
+	} else {
+		body = append(body, headerStyle.Render(fmt.Sprintf("Unhealthy resources (%d)", len(statuses))))
+	}
+	if len(statuses) == 0 && !view.loadingStatuses {
+		body = append(body, faint.Render("  None"))
+	}
+	for _, status := range statuses {
+		prefix := marker()
+		row := timeText(status.Occurred)
+		if view.resourceID == "" {
+			row = resourceNameOf(status.ResourceID)
+		}
+		state := healthStateStyle(status.State).Render(padText(healthIcon(status.State)+" "+status.State, 14))
+		text := fmt.Sprintf("%-14s", healthCause(status)) + status.Summary
+		if entry == view.cursor {
+			row, text = selectedStyle.Render(row), selectedStyle.Render(text)
+		}
+		body = append(body, truncateText(prefix+state+"  "+row+"  "+text, textWidth))
+		if entry == view.cursor && view.expanded {
+			if view.resourceID == "" {
+				body = append(body, detail("Resource:", status.ResourceID)...)
+			}
+			if status.Title != "" {
+				body = append(body, detail("Title:", status.Title)...)
+			}
+			if status.Details != "" {
+				body = append(body, detail("Details:", status.Details)...)
+			}
+			if status.Reason != "" {
+				body = append(body, detail("Reason:", status.Reason)...)
+			}
+			if !status.RootCauseTime.IsZero() {
+				body = append(body, detail("Root cause:", "attributed "+timeText(status.RootCauseTime))...)
+			}
+			if !status.Resolved.IsZero() {
+				body = append(body, detail("Resolved:", timeText(status.Resolved)+" "+status.ResolvedSummary)...)
+			}
+		}
+		entry++
+	}
+
+	active := 0
+	for _, event := range view.events {
+		if event.Active {
+			active++
+		}
+	}
+	body = append(body, "", headerStyle.Render(fmt.Sprintf("Service Health incidents, last 7 days (%d active)", active)))
+	if len(view.events) == 0 && !view.loadingEvents {
+		body = append(body, faint.Render("  No incidents affect your subscriptions"))
+	}
+	for _, event := range view.events {
+		prefix := marker()
+		state := lipgloss.NewStyle().Foreground(colorGray).Render("○ Resolved")
+		if event.Active {
+			state = lipgloss.NewStyle().Foreground(colorRed).Bold(true).Render("● Active  ")
+		}
+		text := event.Title
+		if len(event.Services) > 0 {
+			text += " · " + strings.Join(event.Services, ", ")
+		}
+		if event.Affects(view.locations) {
+			text += " · your regions"
+		}
+		if entry == view.cursor {
+			text = selectedStyle.Render(text)
+		}
+		body = append(body, truncateText(prefix+state+"  "+text, textWidth))
+		if entry == view.cursor && view.expanded {
+			body = append(body, detail("Regions:", strings.Join(event.Regions, ", "))...)
+			body = append(body, detail("Impact:", timeText(event.Started)+" to "+map[bool]string{true: "now", false: timeText(event.Mitigated)}[event.Active])...)
+			body = append(body, detail("Updated:", timeText(event.Updated))...)
+			body = append(body, detail("Tracking ID:", event.TrackingID)...)
+			if len(event.Subscriptions) > 0 {
+				body = append(body, detail("Subscriptions:", fmt.Sprintf("%d", len(event.Subscriptions)))...)
+			}
+			if event.Summary != "" {
+				body = append(body, detail("Summary:", event.Summary)...)
+			}
+		}
+		entry++
+	}
+
+	// Scroll the entries to keep the selected one and its details in view
+	available := height - 4 - len(header) - 2
+	if m.renderTabBar() != "" {
+		available -= 2
+	}
+	available = max(5, available)
+	start := 0
+	if len(body) > available {
+		start = max(0, min(cursorLine-available/3, len(body)-available))
+	}
+	end := min(len(body), start+available)
+
+	lines := append(header, body[start:end]...)
+	lines = append(lines, "", faint.Render(truncateText("j/k:Move  Enter:Details  r:Refresh  Esc:Back", textWidth)))
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,662 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/olafkfreund/azure-tui/internal/azure/loganalytics"
+	"github.com/olafkfreund/azure-tui/internal/config"
+	"github.com/olafkfreund/azure-tui/internal/theme"
+	"github.com/olafkfreund/azure-tui/internal/tui"
+)
+
+// kqlEditorRows is the height of the query editor
+const kqlEditorRows = 7
+
+// kqlConsole runs KQL queries on a Log Analytics workspace
+type kqlConsole struct {
+	resourceType string // Resource the starter queries are for, "" for the workspace
+	resourceID   string
+	resourceName string
+	workspaces   []loganalytics.Workspace
+	workspace    int // Index of the queried workspace, -1 while there is none
+	timespan     int // Index in loganalytics.Timespans
+	editor       *tui.Editor
+	focus        string // "editor", "results", "workspaces", "queries" or "save"
+	back         string // Focus to return to from the lists
+	picker       int    // Cursor of the workspace and query lists
+	saveName     string
+	history      []string
+	historyAt    int    // Position while browsing the history, len(history) after it
+	draft        string // Query typed before browsing the history
+	result       *loganalytics.Result
+	unsorted     [][]string // Rows in the order of the query
+	elapsed      time.Duration
+	sortColumn   int // -1 in the order of the query
+	sortDesc     bool
+	row, column  int
+	loading      bool // The workspaces are loading
+	running      bool
+	err          string
+}
+
+// kqlQuery is an entry of the query list: a saved query or a starter
+type kqlQuery struct {
+	name  string
+	query string
+	saved bool
+}
+
+// current returns the queried workspace
+func (v *kqlConsole) current() (loganalytics.Workspace, bool) {
+	if v.workspace < 0 || v.workspace >= len(v.workspaces) {
+		return loganalytics.Workspace{}, false
+	}
+	return v.workspaces[v.workspace], true
+}
+
+// queries returns the saved queries of the workspace, then the starters
+func (v *kqlConsole) queries() []kqlQuery {
+	var queries []kqlQuery
+	if workspace, ok := v.current(); ok {
+		for _, saved := range config.GetSavedQueries(workspace.ID) {
+			queries = append(queries, kqlQuery{name: saved.Name, query: saved.Query, saved: true})
+		}
+	}
+	for _, starter := range loganalytics.Starters(v.resourceType, v.resourceID, v.resourceName) {
+		queries = append(queries, kqlQuery{name: starter.Name, query: starter.Query})
+	}
+	return queries
+}
+
+// KQL console messages
+type kqlWorkspacesMsg struct {
+	workspaces []loganalytics.Workspace
+	err        error
+}
+
+type kqlResultMsg struct {
+	result  *loganalytics.Result
+	elapsed time.Duration
+	err     error
+}
+
+type kqlSavedMsg struct {
+	message string
+	err     error
+}
+
+type kqlExportedMsg struct {
+	path string
+	rows int
+	err  error
+}
+
+func loadKQLWorkspacesCmd() tea.Cmd {
+	return func() tea.Msg {
+		workspaces, err := loganalytics.ListWorkspaces()
+		return kqlWorkspacesMsg{workspaces: workspaces, err: err}
+	}
+}
+
+// runKQLCmd runs a query and adds it to the history
+func runKQLCmd(workspace loganalytics.Workspace, query string, timespan loganalytics.Timespan) tea.Cmd {
+	return func() tea.Msg {
+		// A history that cannot be saved does not stop the query
+		_ = config.AddQueryHistory(query)
+		start := time.Now()
+		result, err := loganalytics.Query(workspace, query, timespan)
+		return kqlResultMsg{result: result, elapsed: time.Since(start), err: err}
+	}
+}
+
+func saveKQLQueryCmd(workspace, name, query string) tea.Cmd {
+	return func() tea.Msg {
+		if err := config.SaveQuery(workspace, name, query); err != nil {
+			return kqlSavedMsg{err: err}
+		}
+		return kqlSavedMsg{message: fmt.Sprintf("Saved query %q", name)}
+	}
+}
+
+func deleteKQLQueryCmd(workspace, name string) tea.Cmd {
+	return func() tea.Msg {
+		if err := config.DeleteSavedQuery(workspace, name); err != nil {
+			return kqlSavedMsg{err: err}
+		}
+		return kqlSavedMsg{message: fmt.Sprintf("Deleted saved query %q", name)}
+	}
+}
+
+// exportKQLCmd writes a result as CSV or JSON to the exports folder of the
+// config directory
+func exportKQLCmd(result *loganalytics.Result, format string) tea.Cmd {
+	return func() tea.Msg {
+		dir := filepath.Join(os.Getenv("HOME"), ".config", "azure-tui", "exports")
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return kqlExportedMsg{err: err}
+		}
+		path := filepath.Join(dir, "query-"+time.Now().Format("20060102-150405")+"."+format)
+
+		var data []byte
+		if format == "csv" {
+			var b bytes.Buffer
+			if err := result.WriteCSV(&b); err != nil {
+				return kqlExportedMsg{err: err}
+			}
+			data = b.Bytes()
+		} else {
+			var err error
+			if data, err = result.JSON(); err != nil {
+				return kqlExportedMsg{err: err}
+			}
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return kqlExportedMsg{err: err}
+		}
+		return kqlExportedMsg{path: path, rows: len(result.Rows)}
+	}
+}
+
+// openKQLConsole opens the console with the starter queries of the
+// selected resource, or of the workspace when there is none
+func (m *model) openKQLConsole() tea.Cmd {
+	view := &kqlConsole{workspace: -1, timespan: 1, focus: "editor", sortColumn: -1, loading: true}
+	if m.selectedResource != nil {
+		view.resourceType, view.resourceID, view.resourceName = m.selectedResource.Type, m.selectedResource.ID, m.selectedResource.Name
+	}
+	view.history = config.GetQueryHistory()
+	view.historyAt = len(view.history)
+	query := ""
+	if starters := loganalytics.Starters(view.resourceType, view.resourceID, view.resourceName); len(starters) > 0 {
+		query = starters[0].Query
+	}
+	view.editor = tui.NewEditor(query)
+
+	m.kqlView = view
+	m.selectedPanel = 1
+	m.rightPanelScrollOffset = 0
+	m.pushView("kql")
+	return loadKQLWorkspacesCmd()
+}
+
+// runKQL runs the query of the editor on the workspace
+func (m *model) runKQL() tea.Cmd {
+	view := m.kqlView
+	query := strings.TrimSpace(view.editor.Value())
+	workspace, ok := view.current()
+	switch {
+	case query == "" || view.running:
+		return nil
+	case !ok:
+		m.addToast("Choose a Log Analytics workspace first", "notice")
+		return nil
+	}
+
+	if len(view.history) == 0 || view.history[len(view.history)-1] != query {
+		view.history = append(slices.DeleteFunc(view.history, func(previous string) bool { return previous == query }), query)
+	}
+	view.historyAt = len(view.history)
+	view.running, view.err = true, ""
+	return runKQLCmd(workspace, query, loganalytics.Timespans[view.timespan])
+}
+
+// openList shows the workspace or query list over the results
+func (v *kqlConsole) openList(focus string) {
+	if v.focus != "workspaces" && v.focus != "queries" {
+		v.back = v.focus
+	}
+	v.focus, v.picker = focus, 0
+	if focus == "workspaces" {
+		v.picker = max(0, v.workspace)
+	}
+}
+
+// updateKQLConsole handles keys in the KQL console. In the editor every
+// key types, apart from the control keys of the console.
+func (m model) updateKQLConsole(msg tea.KeyMsg) (tea.Model, tea.Cmd, bool) {
+	view := m.kqlView
+	key := msg.String()
+
+	switch view.focus {
+	case "save":
+		switch key {
+		case "enter":
+			name := strings.TrimSpace(view.saveName)
+			view.focus, view.saveName = "editor", ""
+			if workspace, ok := view.current(); ok && name != "" {
+				return m, saveKQLQueryCmd(workspace.ID, name, strings.TrimSpace(view.editor.Value())), true
+			}
+		case "esc":
+			view.focus, view.saveName = "editor", ""
+		case "backspace":
+			if runes := []rune(view.saveName); len(runes) > 0 {
+				view.saveName = string(runes[:len(runes)-1])
+			}
+		default:
+			if msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace {
+				view.saveName += string(msg.Runes)
+			}
+		}
+		return m, nil, true
+
+	case "workspaces", "queries":
+		count := len(view.workspaces)
+		if view.focus == "queries" {
+			count = len(view.queries())
+		}
+		switch key {
+		case "j", "down":
+			view.picker = min(view.picker+1, max(0, count-1))
+		case "k", "up":
+			view.picker = max(view.picker-1, 0)
+		case "enter":
+			if view.focus == "workspaces" {
+				if view.picker < count {
+					view.workspace = view.picker
+				}
+				view.focus = view.back
+			} else if queries := view.queries(); view.picker < len(queries) {
+				view.editor.SetValue(queries[view.picker].query)
+				view.focus = "editor"
+			}
+		case "d":
+			queries := view.queries()
+			workspace, ok := view.current()
+			if view.focus == "queries" && ok && view.picker < len(queries) && queries[view.picker].saved {
+				return m, deleteKQLQueryCmd(workspace.ID, queries[view.picker].name), true
+			}
+		case "esc":
+			view.focus = view.back
+		}
+		return m, nil, true
+
+	case "results":
+		switch key {
+		case "j", "down":
+			if view.result != nil {
+				view.row = min(view.row+1, max(0, len(view.result.Rows)-1))
+			}
+		case "k", "up":
+			view.row = max(view.row-1, 0)
+		case "pgdown":
+			if view.result != nil {
+				view.row = min(view.row+10, max(0, len(view.result.Rows)-1))
+			}
+		case "pgup":
+			view.row = max(view.row-10, 0)
+		case "l", "right":
+			if view.result != nil {
+				view.column = min(view.column+1, max(0, len(view.result.Columns)-1))
+			}
+		case "h", "left":
+			view.column = max(view.column-1, 0)
+		case "s":
+			// Sorting cycles ascending, descending and back to the order of
+			// the query
+			if view.result == nil || len(view.result.Columns) == 0 {
+				return m, nil, true
+			}
+			switch {
+			case view.sortColumn != view.column:
+				view.sortColumn, view.sortDesc = view.column, false
+			case !view.sortDesc:
+				view.sortDesc = true
+			default:
+				view.sortColumn = -1
+				view.result.Rows = slices.Clone(view.unsorted)
+			}
+			view.result.Sort(view.sortColumn, view.sortDesc)
+			view.row = 0
+		case "e", "enter":
+			view.focus = "editor"
+		case "x", "X":
+			if view.result == nil || len(view.result.Rows) == 0 {
+				m.addToast("No results to export", "notice")
+				return m, nil, true
+			}
+			format := "csv"
+			if key == "X" {
+				format = "json"
+			}
+			export := *view.result
+			export.Rows = slices.Clone(view.result.Rows)
+			return m, exportKQLCmd(&export, format), true
+		case "y":
+			if view.result != nil && view.row < len(view.result.Rows) && view.column < len(view.result.Columns) {
+				return m, copyCmd(view.result.Columns[view.column].Name+" value", view.result.Rows[view.row][view.column]), true
+			}
+		case "t":
+			view.timespan = (view.timespan + 1) % len(loganalytics.Timespans)
+		case "w":
+			view.openList("workspaces")
+		case "o":
+			view.openList("queries")
+		case "r", "ctrl+r":
+			return m, m.runKQL(), true
+		default:
+			return m, nil, false
+		}
+		return m, nil, true
+	}
+
+	// The editor
+	editor := view.editor
+	switch key {
+	case "ctrl+c":
+		return m, nil, false
+	case "esc":
+		if view.result == nil {
+			return m, nil, false
+		}
+		view.focus = "results"
+	case "ctrl+r":
+		return m, m.runKQL(), true
+	case "ctrl+p", "ctrl+n":
+		if len(view.history) == 0 {
+			return m, nil, true
+		}
+		if view.historyAt == len(view.history) {
+			view.draft = editor.Value()
+		}
+		if key == "ctrl+p" {
+			view.historyAt = max(0, view.historyAt-1)
+		} else {
+			view.historyAt = min(len(view.history), view.historyAt+1)
+		}
+		if view.historyAt == len(view.history) {
+			editor.SetValue(view.draft)
+		} else {
+			editor.SetValue(view.history[view.historyAt])
+		}
+	case "ctrl+s":
+		if _, ok := view.current(); !ok || strings.TrimSpace(editor.Value()) == "" {
+			m.addToast("Nothing to save without a workspace and a query", "notice")
+			return m, nil, true
+		}
+		view.focus, view.saveName = "save", ""
+	case "ctrl+o":
+		view.openList("queries")
+	case "ctrl+w":
+		view.openList("workspaces")
+	case "ctrl+t":
+		view.timespan = (view.timespan + 1) % len(loganalytics.Timespans)
+	case "up":
+		editor.Move(-1, 0)
+	case "down":
+		editor.Move(1, 0)
+	case "left":
+		editor.Move(0, -1)
+	case "right":
+		editor.Move(0, 1)
+	case "home", "ctrl+a":
+		editor.Home()
+	case "end", "ctrl+e":
+		editor.End()
+	case "enter":
+		editor.Newline()
+	case "backspace":
+		editor.Backspace()
+	case "delete", "ctrl+d":
+		editor.Delete()
+	case "tab":
+		editor.Insert("  ")
+	default:
+		if msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace {
+			editor.Insert(string(msg.Runes))
+		}
+	}
+	return m, nil, true
+}
+
+// updateKQL handles the KQL console messages
+func (m model) updateKQL(msg tea.Msg) (tea.Model, tea.Cmd) {
+	view := m.kqlView
+	switch msg := msg.(type) {
+	case kqlWorkspacesMsg:
+		if view == nil {
+			return m, nil
+		}
+		view.loading = false
+		if msg.err != nil {
+			view.err = msg.err.Error()
+			return m, nil
+		}
+		view.workspaces = msg.workspaces
+		view.workspace = loganalytics.DefaultWorkspace(msg.workspaces, view.resourceID)
+		if view.workspace < 0 {
+			view.err = "No Log Analytics workspaces in this subscription"
+		}
+
+	case kqlResultMsg:
+		if view == nil || !view.running {
+			return m, nil
+		}
+		view.running = false
+		if msg.err != nil {
+			view.err = msg.err.Error()
+			return m, nil
+		}
+		view.result, view.elapsed = msg.result, msg.elapsed
+		view.unsorted = slices.Clone(msg.result.Rows)
+		view.row, view.column = 0, 0
+		if view.sortColumn >= len(view.result.Columns) {
+			view.sortColumn = -1
+		}
+		view.result.Sort(view.sortColumn, view.sortDesc)
+		view.focus = "results"
+
+	case kqlSavedMsg:
+		if msg.err != nil {
+			m.addToast("Saving the query failed: "+msg.err.Error(), "failure")
+		} else {
+			m.addToast(msg.message, "success")
+		}
+		if view != nil && view.focus == "queries" {
+			view.picker = max(0, min(view.picker, len(view.queries())-1))
+		}
+		return m, tea.Tick(toastDuration, func(time.Time) tea.Msg { return toastExpiredMsg{} })
+
+	case kqlExportedMsg:
+		if msg.err != nil {
+			m.addToast("Exporting the results failed: "+msg.err.Error(), "failure")
+		} else {
+			m.addToast(fmt.Sprintf("Exported %d rows to %s", msg.rows, msg.path), "success")
+			m.logEntries = append(m.logEntries, "Query results exported: "+msg.path)
+		}
+		return m, tea.Tick(toastDuration, func(time.Time) tea.Msg { return toastExpiredMsg{} })
+	}
+	return m, nil
+}
+
+// kqlCell returns a cell on one line
+func kqlCell(value string) string {
+	return strings.NewReplacer("\r\n", " ", "\n", " ", "\t", " ").Replace(value)
+}
+
+// renderKQLConsole draws the editor and, below it, the results or the
+// open list
+func (m model) renderKQLConsole(width, height int) string {
+	view := m.kqlView
+	textWidth := max(40, width-4)
+
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(colorBlue)
+	labelStyle := lipgloss.NewStyle().Foreground(colorGray)
+	faint := lipgloss.NewStyle().Foreground(colorGray).Italic(true)
+	selectedStyle := lipgloss.NewStyle().Foreground(colorYellow).Bold(true)
+
+	var lines []string
+	title := "🔎 KQL Console"
+	if view.resourceName != "" {
+		title += ": " + view.resourceName
+	}
+	lines = append(lines, headerStyle.Render(title))
+	workspaceText := "no workspace"
+	if view.loading {
+		workspaceText = "loading workspaces..."
+	}
+	if workspace, ok := view.current(); ok {
+		workspaceText = workspace.Name + " (" + workspace.ResourceGroup + ")"
+	}
+	lines = append(lines, truncateText(labelStyle.Render("Workspace: ")+workspaceText+labelStyle.Render("   Time range: ")+"last "+loganalytics.Timespans[view.timespan].Label, textWidth))
+	lines = append(lines, "")
+	lines = append(lines, strings.Split(view.editor.View(textWidth, kqlEditorRows, view.focus == "editor"), "\n")...)
+	lines = append(lines, "")
+
+	switch {
+	case view.running:
+		lines = append(lines, "⏳ Running query...")
+	case view.err != "":
+		for i, line := range strings.Split(view.err, "\n") {
+			if i == 3 {
+				break
+			}
+			lines = append(lines, lipgloss.NewStyle().Foreground(colorRed).Render(truncateText("❌ "+line, textWidth)))
+		}
+	case view.result != nil:
+		lines = append(lines, labelStyle.Render(fmt.Sprintf("%d rows in %.1fs", len(view.result.Rows), view.elapsed.Seconds())))
+	}
+
+	available := height - 4 - len(lines) - 2
+	if m.renderTabBar() != "" {
+		available -= 2
+	}
+	available = max(3, available)
+
+	help := "^R:Run  ^P/^N:History  ^S:Save  ^O:Queries  ^W:Workspace  ^T:Range"
+	switch view.focus {
+	case "save":
+		lines = append(lines, headerStyle.Render("Save query as: ")+view.saveName+"█")
+		help = "Enter:Save  Esc:Cancel"
+
+	case "workspaces", "queries":
+		var items []string
+		if view.focus == "workspaces" {
+			lines = append(lines, headerStyle.Render(fmt.Sprintf("Workspaces (%d)", len(view.workspaces))))
+			for _, workspace := range view.workspaces {
+				items = append(items, workspace.Name+labelStyle.Render("  "+workspace.ResourceGroup+", "+workspace.Location))
+			}
+			help = "j/k:Move  Enter:Use  Esc:Back"
+		} else {
+			lines = append(lines, headerStyle.Render("Saved and starter queries"))
+			for _, query := range view.queries() {
+				icon := theme.Icon("📄 ", "- ")
+				if query.saved {
+					icon = theme.Icon("⭐ ", "* ")
+				}
+				items = append(items, icon+query.name+labelStyle.Render("  "+kqlCell(query.query)))
+			}
+			help = "j/k:Move  Enter:Load  d:Delete saved  Esc:Back"
+		}
+		if len(items) == 0 {
+			lines = append(lines, faint.Render("Nothing here yet"))
+		}
+		start := max(0, min(view.picker-available/2, len(items)-available))
+		for i := start; i < min(len(items), start+available); i++ {
+			marker := "  "
+			item := items[i]
+			if i == view.picker {
+				marker = theme.Icon("❯ ", "> ")
+				item = selectedStyle.Render(item)
+			}
+			lines = append(lines, truncateText(marker+item, textWidth))
+		}
+
+	default:
+		if view.focus == "results" {
+			help = "j/k:Row  h/l:Col  s:Sort  y:Copy  x/X:Export  w:Workspace  o:Queries"
+		}
+		if view.result == nil {
+			break
+		}
+		if len(view.result.Columns) == 0 || len(view.result.Rows) == 0 {
+			lines = append(lines, faint.Render("No rows"))
+			break
+		}
+		lines = append(lines, m.renderKQLTable(textWidth, available)...)
+	}
+
+	lines = append(lines, "", faint.Render(truncateText(help, textWidth)))
+	return strings.Join(lines, "\n")
+}
+
+// renderKQLTable draws the header and rows of the result that fit, scrolled
+// to keep the selected cell in view
+func (m model) renderKQLTable(width, height int) []string {
+	view := m.kqlView
+	result := view.result
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(colorBlue)
+	selectedStyle := lipgloss.NewStyle().Foreground(colorYellow).Bold(true)
+	cellStyle := lipgloss.NewStyle().Reverse(true)
+
+	// Columns are as wide as their widest value, up to a limit
+	widths := make([]int, len(result.Columns))
+	for c, column := range result.Columns {
+		widths[c] = ansi.StringWidth(column.Name) + 2 // Room for the sort arrow
+		for _, row := range result.Rows {
+			widths[c] = max(widths[c], ansi.StringWidth(kqlCell(row[c])))
+		}
+		widths[c] = min(widths[c], 40)
+	}
+	// Columns scroll sideways: the first shown is the leftmost that keeps
+	// the selected one on screen
+	first := view.column
+	for used := widths[first]; first > 0 && used+2+widths[first-1] <= width-2; first-- {
+		used += 2 + widths[first-1]
+	}
+
+	cell := func(c int, value string) string {
+		text := truncateText(kqlCell(value), widths[c])
+		padding := strings.Repeat(" ", widths[c]-ansi.StringWidth(text))
+		if result.Columns[c].Numeric() {
+			return padding + text
+		}
+		return text + padding
+	}
+	line := func(marker string, cells func(c int) string) string {
+		parts := []string{}
+		used := 2
+		for c := first; c < len(result.Columns) && used < width; c++ {
+			parts = append(parts, cells(c))
+			used += widths[c] + 2
+		}
+		return truncateText(marker+strings.Join(parts, "  "), width)
+	}
+
+	lines := []string{line("  ", func(c int) string {
+		name := result.Columns[c].Name
+		if c == view.sortColumn {
+			name += map[bool]string{false: " ↑", true: " ↓"}[view.sortDesc]
+		}
+		return headerStyle.Render(cell(c, name))
+	})}
+
+	rows := max(1, height-1)
+	start := max(0, min(view.row-rows/2, len(result.Rows)-rows))
+	for r := start; r < min(len(result.Rows), start+rows); r++ {
+		marker := "  "
+		if r == view.row {
+			marker = theme.Icon("❯ ", "> ")
+		}
+		lines = append(lines, line(marker, func(c int) string {
+			text := cell(c, result.Rows[r][c])
+			switch {
+			case r == view.row && c == view.column && view.focus == "results":
+				return cellStyle.Render(text)
+			case r == view.row:
+				return selectedStyle.Render(text)
+			}
+			return text
+		}))
+	}
+	return lines
+}
@@ -3,48 +3,78 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"maps"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 
+	"github.com/olafkfreund/azure-tui/internal/azcli"
 	"github.com/olafkfreund/azure-tui/internal/azure/aci"
 	"github.com/olafkfreund/azure-tui/internal/azure/devops"
+	"github.com/olafkfreund/azure-tui/internal/azure/health"
 	"github.com/olafkfreund/azure-tui/internal/azure/keyvault"
 	"github.com/olafkfreund/azure-tui/internal/azure/network"
 	"github.com/olafkfreund/azure-tui/internal/azure/resourceactions"
 	"github.com/olafkfreund/azure-tui/internal/azure/resourcedetails"
 	"github.com/olafkfreund/azure-tui/internal/azure/storage"
 	"github.com/olafkfreund/azure-tui/internal/azure/tfbicep"
+	"github.com/olafkfreund/azure-tui/internal/clipboard"
 	"github.com/olafkfreund/azure-tui/internal/config"
+	"github.com/olafkfreund/azure-tui/internal/jobs"
+	"github.com/olafkfreund/azure-tui/internal/jsonview"
+	"github.com/olafkfreund/azure-tui/internal/keymap"
 	"github.com/olafkfreund/azure-tui/internal/openai"
 	"github.com/olafkfreund/azure-tui/internal/search"
 	"github.com/olafkfreund/azure-tui/internal/terraform"
+	"github.com/olafkfreund/azure-tui/internal/theme"
 	"github.com/olafkfreund/azure-tui/internal/tui"
 )
 
-// Gruvbox colors
+// Colors of the active theme, set by applyTheme
 var (
-	bgDark      = lipgloss.Color("#282828")
-	bgMedium    = lipgloss.Color("#3c3836")
-	bgLight     = lipgloss.Color("#504945")
-	fgLight     = lipgloss.Color("#fbf1c7")
-	fgMedium    = lipgloss.Color("#ebdbb2")
-	colorBlue   = lipgloss.Color("#83a598")
-	colorGreen  = lipgloss.Color("#b8bb26")
-	colorRed    = lipgloss.Color("#fb4934")
-	colorYellow = lipgloss.Color("#fabd2f")
-	colorPurple = lipgloss.Color("#d3869b")
-	colorAqua   = lipgloss.Color("#8ec07c")
-	colorGray   = lipgloss.Color("#a89984")
+	bgDark      = theme.Current().Background
+	bgMedium    = theme.Current().Surface
+	bgLight     = theme.Current().SurfaceHighlight
+	fgLight     = theme.Current().Text
+	fgMedium    = theme.Current().Subtext
+	colorBlue   = theme.Current().Primary
+	colorGreen  = theme.Current().Success
+	colorRed    = theme.Current().Error
+	colorYellow = theme.Current().Warning
+	colorPurple = theme.Current().Accent
+	colorAqua   = theme.Current().Info
+	colorGray   = theme.Current().Muted
 )
 
+// applyTheme makes t the active theme for every view
+func applyTheme(t theme.Theme) {
+	theme.Set(t)
+	bgDark = t.Background
+	bgMedium = t.Surface
+	bgLight = t.SurfaceHighlight
+	fgLight = t.Text
+	fgMedium = t.Subtext
+	colorBlue = t.Primary
+	colorGreen = t.Success
+	colorRed = t.Error
+	colorYellow = t.Warning
+	colorPurple = t.Accent
+	colorAqua = t.Info
+	colorGray = t.Muted
+}
+
 // Global debug file and sync
 var debugFile *os.File
 var debugFileOnce sync.Once
@@ -107,6 +137,19 @@ type resourceActionMsg struct {
 	result   resourceactions.ActionResult
 }
 type errorMsg struct{ error string }
+type layoutSavedMsg struct{ err error }
+type aiChatAnsweredMsg struct {
+	question string
+	answer   string
+	err      error
+}
+
+// aiChatTurn is a question asked in the AI chat and its answer
+type aiChatTurn struct {
+	question string
+	answer   string
+	err      error
+}
 
 // Network dashboard message types
 type networkDashboardMsg struct{ content string }
@@ -205,6 +248,53 @@ type settingsConfigSavedMsg struct {
 	message string
 }
 
+// Saved search message types
+type searchQueryTranslatedMsg struct {
+	question string
+	query    string
+	err      error
+}
+
+type savedSearchesUpdatedMsg struct {
+	searches []config.SavedSearch
+	message  string
+	err      error
+}
+
+// Bookmark message types
+type bookmarksUpdatedMsg struct {
+	bookmarks []config.Bookmark
+	message   string
+	err       error
+}
+
+// bookmarkLoadedMsg carries a bookmarked resource, or the resources of a
+// bookmarked group, looked up in the bookmark's own subscription
+type bookmarkLoadedMsg struct {
+	resourceID string
+	resource   *AzureResource
+	resources  []AzureResource
+	err        error
+}
+
+// Equivalent command message types
+type clipboardCopiedMsg struct {
+	what   string // What was copied, e.g. "az command"
+	target string // Clipboard it went to
+	err    error
+}
+
+type sessionScriptSavedMsg struct {
+	path     string
+	commands int
+	err      error
+}
+
+// Background job message types
+type jobsChangedMsg struct{}
+type jobTickMsg struct{}
+type toastExpiredMsg struct{}
+
 // Storage Account message types
 type storageContainersMsg struct {
 	accountName string
@@ -253,31 +343,32 @@ type subscriptionSelectedMsg struct {
 	message      string
 }
 
-type model struct {
-	treeView               *tui.TreeView
-	statusBar              *tui.StatusBar
-	aiProvider             *openai.AIProvider
-	width, height          int
-	ready                  bool
-	subscriptions          []Subscription
-	resourceGroups         []ResourceGroup
-	allResources           []AzureResource
+// panelState is everything the right panel shows. Each tab keeps its own
+// copy, so scroll position and loading state are independent between tabs.
+type panelState struct {
 	selectedResource       *AzureResource
 	resourceDetails        *resourcedetails.ResourceDetails
 	aiDescription          string
-	loadingState           string
-	selectedPanel          int
 	rightPanelScrollOffset int
-	leftPanelScrollOffset  int // Add independent scrolling for left panel
-	rightPanelMaxLines     int
 	actionInProgress       bool
 	lastActionResult       *resourceactions.ActionResult
 	showDashboard          bool
-	logEntries             []string
-	// New navigation fields
-	activeView            string          // "details", "dashboard", "welcome", "network-dashboard", "vnet-details", "nsg-details", "network-topology", "network-ai"
-	propertyExpandedIndex int             // For navigating expanded properties
-	expandedProperties    map[string]bool // Track which properties are expanded
+	activeView             string          // "details", "dashboard", "welcome", "raw-json", "metrics", "quotas", "alerts", "network-dashboard", "vnet-details", "nsg-details", "network-topology", "network-ai"
+	propertyExpandedIndex  int             // For navigating expanded properties
+	expandedProperties     map[string]bool // Track which properties are expanded
+	navigationStack        []string        // Navigation stack for back navigation
+	needsReload            bool            // Restored from a previous session, loaded on first activation
+
+	// Raw JSON and metrics views
+	jsonExplorer    *jsonview.Explorer
+	jsonInputMode   string // "filter" or "search" while typing in the raw JSON view
+	jsonInput       string
+	metricsView     *metricsExplorer
+	quotasView      *quotaView
+	alertsView      *alertsView
+	kqlView         *kqlConsole
+	healthView      *healthView
+	diagnosticsView *diagnosticsView
 
 	// Network-specific fields
 	networkDashboardContent string
@@ -313,14 +404,73 @@ type model struct {
 	selectedBlob              *storage.Blob
 	currentStorageAccount     string
 	currentContainer          string
+}
+
+// newPanelState returns the state of an empty right panel
+func newPanelState() panelState {
+	return panelState{
+		activeView:            "welcome",
+		propertyExpandedIndex: -1,
+		expandedProperties:    make(map[string]bool),
+		navigationStack:       []string{},
+	}
+}
+
+type model struct {
+	panelState
+
+	treeView              *tui.TreeView
+	statusBar             *tui.StatusBar
+	aiProvider            *openai.AIProvider
+	width, height         int
+	ready                 bool
+	subscriptions         []Subscription
+	resourceGroups        []ResourceGroup
+	allResources          []AzureResource
+	loadingState          string
+	selectedPanel         int
+	leftPanelScrollOffset int // Add independent scrolling for left panel
+	rightPanelMaxLines    int
+	layout                tui.Layout
+	draggingDivider       bool // The panel divider is being dragged with the mouse
+	logEntries            []string
+
+	// Bottom panel with the logs, the output of resource actions and the AI chat
+	bottomView         string
+	bottomScrollOffset int // Lines scrolled back from the end
+	actionOutput       []string
+	aiChat             []aiChatTurn
+	aiChatInput        string
+	aiChatWaiting      bool
+
+	// Long operations run as background jobs, listed in the jobs view of
+	// the bottom panel. Toasts announce the jobs that finish.
+	jobManager   *jobs.Manager
+	jobFollowUps map[int]jobFollowUp // By job ID, until the job has finished
+	jobIndex     int                 // Job selected in the jobs view
+	jobTicking   bool                // Elapsed times are being updated
+	toasts       []toast
+
+	// Tabs in the right panel, each with its own panelState
+	tabManager *tui.TabManager
+	tabStates  map[string]*panelState // keyed by the tab's "id" meta value
+	nextTabID  int
+
+	// Active key bindings, from the keymap section of the config
+	keymap         *keymap.Keymap
+	keymapWarnings []string // Conflicts and errors found loading the keymap
+
+	// Command palette, nil when closed
+	commandPalette *tui.CommandPalette
+
+	// Equivalent commands popup, nil when closed
+	commandsPopup *commandsPopup
+	copyMenu      *copyMenu
 
 	// Help popup state
 	showHelpPopup    bool
 	helpScrollOffset int // For scrolling through help content
 
-	// Navigation stack for back navigation
-	navigationStack []string
-
 	// Search functionality
 	searchEngine      *search.SearchEngine
 	searchMode        bool
@@ -331,6 +481,24 @@ type model struct {
 	showSearchResults bool
 	searchHistory     []string
 	filteredResources []AzureResource
+	searchFacets      []search.FacetGroup
+	facetFocus        bool // navigating the facet sidebar instead of the results
+	facetIndex        int
+
+	// Saved searches shown as smart folders in the tree
+	savedSearches  []config.SavedSearch
+	searchSaveMode bool // naming the current query before saving it
+	searchSaveName string
+
+	// Bookmarked nodes in the Favorites folder, looked up when first shown
+	bookmarks      []config.Bookmark
+	bookmarkLoads  map[string]*bookmarkLoad // By lower-case resource ID
+	bookmarkPicker *tui.CommandPalette
+
+	// Natural-language "? ..." searches translated into the query syntax
+	searchTranslating bool   // waiting for the AI provider
+	searchAIQuery     string // generated query awaiting confirmation
+	searchAIError     string
 
 	// Terraform integration
 	showTerraformPopup    bool
@@ -357,13 +525,15 @@ type model struct {
 	// Settings menu functionality
 	showSettingsPopup     bool
 	settingsMenuIndex     int
-	settingsMode          string // "menu", "config-view", "folder-browser", "edit-setting"
+	settingsMode          string // "menu", "config-view", "folder-browser", "edit-setting", "theme-select"
 	settingsCurrentPath   string
 	settingsFolders       []string
 	settingsConfigContent string
 	settingsEditKey       string
 	settingsEditValue     string
 	settingsCurrentConfig *config.AppConfig
+	settingsThemes        []string    // Themes offered by the theme picker
+	settingsThemeBefore   theme.Theme // Restored when the theme picker is cancelled
 
 	// Subscription selection functionality
 	currentSubscription    *Subscription
@@ -371,6 +541,14 @@ type model struct {
 	subscriptionMenuIndex  int
 	availableSubscriptions []Subscription
 	subscriptionMenuMode   string // "menu" or "loading"
+
+	// Open alerts of the subscription, polled for the status bar
+	openAlertCount int
+	alertPoll      int // Generation of the polling; older polls stop
+
+	// Resource Health of the resources in the tree by lower case ID, polled
+	resourceHealth map[string]health.Status
+	healthPoll     int // Generation of the polling; older polls stop
 }
 
 // Helper functions for search functionality
@@ -389,13 +567,305 @@ func convertAzureResourceToSearchResource(azResource AzureResource) search.Resou
 	}
 }
 
-// updateSearchEngine updates the search engine with current resources
-func (m *model) updateSearchEngine() {
-	searchResources := make([]search.Resource, len(m.allResources))
-	for i, azResource := range m.allResources {
+// updateSearchEngine indexes newly loaded resources in the search engine
+func (m *model) updateSearchEngine(resources []AzureResource) {
+	searchResources := make([]search.Resource, len(resources))
+	for i, azResource := range resources {
 		searchResources[i] = convertAzureResourceToSearchResource(azResource)
 	}
-	m.searchEngine.SetResources(searchResources)
+	m.searchEngine.UpsertResources(searchResources)
+	m.refreshSmartFolders()
+}
+
+// refreshSmartFolders re-runs every saved search against the loaded inventory
+// and updates the smart folders at the top of the tree
+func (m *model) refreshSmartFolders() {
+	if m.treeView == nil {
+		return
+	}
+
+	resourceMap := make(map[string]AzureResource)
+	for _, resource := range m.allResources {
+		resourceMap[resource.ID] = resource
+	}
+
+	folders := make([]tui.SmartFolder, 0, len(m.savedSearches))
+	for _, saved := range m.savedSearches {
+		folder := tui.SmartFolder{Name: saved.Name, Query: saved.Query}
+		results, err := m.searchEngine.Search(saved.Query)
+		if err == nil {
+			seenIDs := make(map[string]bool)
+			for _, result := range results {
+				resource, exists := resourceMap[result.ResourceID]
+				if !exists || seenIDs[result.ResourceID] {
+					continue
+				}
+				seenIDs[result.ResourceID] = true
+				folder.Items = append(folder.Items, tui.SmartFolderItem{Name: resource.Name, Type: resource.Type, Data: resource})
+			}
+		}
+		folders = append(folders, folder)
+	}
+
+	m.treeView.SetSmartFolders(folders)
+	m.applyHealthBadges()
+}
+
+// saveSearchCmd persists the current query as a named saved search
+func saveSearchCmd(name, query string) tea.Cmd {
+	return func() tea.Msg {
+		if err := config.SaveSearch(name, query); err != nil {
+			return savedSearchesUpdatedMsg{err: err}
+		}
+		return savedSearchesUpdatedMsg{
+			searches: config.GetSavedSearches(),
+			message:  fmt.Sprintf("Saved search %q", name),
+		}
+	}
+}
+
+// translateSearchQueryCmd asks the AI provider to turn a natural-language
+// question into a search query. Only the query schema is sent along, the
+// query itself runs locally once confirmed.
+func translateSearchQueryCmd(ai *openai.AIProvider, question string) tea.Cmd {
+	return func() tea.Msg {
+		query, err := ai.TranslateSearchQuery(question, search.QuerySchema())
+		if err == nil {
+			err = search.ValidateQuery(query)
+		}
+		return searchQueryTranslatedMsg{question: question, query: query, err: err}
+	}
+}
+
+// naturalLanguageQuestion returns the question of a "? ..." search query
+func naturalLanguageQuestion(query string) (string, bool) {
+	question, found := strings.CutPrefix(strings.TrimSpace(query), "?")
+	return strings.TrimSpace(question), found
+}
+
+// deleteSavedSearchCmd removes a saved search by name
+func deleteSavedSearchCmd(name string) tea.Cmd {
+	return func() tea.Msg {
+		if err := config.DeleteSavedSearch(name); err != nil {
+			return savedSearchesUpdatedMsg{err: err}
+		}
+		return savedSearchesUpdatedMsg{
+			searches: config.GetSavedSearches(),
+			message:  fmt.Sprintf("Deleted saved search %q", name),
+		}
+	}
+}
+
+// bookmarkLoad is the lookup of a bookmark in its subscription
+type bookmarkLoad struct {
+	loading   bool
+	resource  *AzureResource  // Bookmarked resource
+	resources []AzureResource // Resources of a bookmarked group
+	err       error
+}
+
+// refreshFavorites rebuilds the Favorites folder at the top of the tree from
+// the bookmarks and what has been looked up of them so far
+func (m *model) refreshFavorites() {
+	if m.treeView == nil {
+		return
+	}
+
+	favorites := make([]tui.Favorite, 0, len(m.bookmarks))
+	for _, bookmark := range m.bookmarks {
+		favorite := tui.Favorite{Name: bookmark.Name, Type: bookmark.Type, Data: bookmark}
+		load := m.bookmarkLoads[strings.ToLower(bookmark.ResourceID)]
+		switch {
+		case load == nil || load.loading:
+			if bookmark.Type == "group" {
+				favorite.Items = []tui.SmartFolderItem{{Name: "Loading...", Type: "placeholder"}}
+			}
+		case load.err != nil:
+			favorite.Missing = true
+		case load.resource != nil:
+			favorite.Data, favorite.Resolved = *load.resource, true
+		default:
+			favorite.Items = []tui.SmartFolderItem{}
+			for _, resource := range load.resources {
+				favorite.Items = append(favorite.Items, tui.SmartFolderItem{Name: resource.Name, Type: resource.Type, Data: resource})
+			}
+		}
+		favorites = append(favorites, favorite)
+	}
+
+	m.treeView.SetFavorites(favorites)
+	m.applyHealthBadges()
+}
+
+// bookmarkFor returns the bookmark of a tree node: the node of a bookmark in
+// the Favorites folder, a resource or a resource group
+func (m model) bookmarkFor(node *tui.TreeNode) (config.Bookmark, bool) {
+	switch data := node.ResourceData.(type) {
+	case config.Bookmark:
+		return data, true
+	case AzureResource:
+		return config.Bookmark{
+			Name:           data.Name,
+			Type:           data.Type,
+			SubscriptionID: subscriptionOf(data.ID),
+			ResourceID:     data.ID,
+		}, data.ID != ""
+	}
+
+	if node.Type != "group" || m.currentSubscription == nil {
+		return config.Bookmark{}, false
+	}
+	return config.Bookmark{
+		Name:           node.Name,
+		Type:           "group",
+		SubscriptionID: m.currentSubscription.ID,
+		ResourceID:     fmt.Sprintf("/subscriptions/%s/resourceGroups/%s", m.currentSubscription.ID, node.Name),
+	}, true
+}
+
+// isBookmarked reports whether a resource ID is bookmarked
+func (m model) isBookmarked(resourceID string) bool {
+	for _, bookmark := range m.bookmarks {
+		if strings.EqualFold(bookmark.ResourceID, resourceID) {
+			return true
+		}
+	}
+	return false
+}
+
+// subscriptionOf returns the subscription ID of an Azure resource ID
+func subscriptionOf(resourceID string) string {
+	parts := strings.Split(resourceID, "/")
+	for i := 0; i+1 < len(parts); i++ {
+		if strings.EqualFold(parts[i], "subscriptions") {
+			return parts[i+1]
+		}
+	}
+	return ""
+}
+
+// toggleBookmarkCmd adds the bookmark, or removes it when it exists
+func toggleBookmarkCmd(bookmark config.Bookmark, remove bool) tea.Cmd {
+	return func() tea.Msg {
+		var err error
+		message := fmt.Sprintf("Bookmarked %q", bookmark.Name)
+		if remove {
+			err = config.DeleteBookmark(bookmark.ResourceID)
+			message = fmt.Sprintf("Removed bookmark %q", bookmark.Name)
+		} else {
+			err = config.AddBookmark(bookmark)
+		}
+		if err != nil {
+			return bookmarksUpdatedMsg{err: err}
+		}
+		return bookmarksUpdatedMsg{bookmarks: config.GetBookmarks(), message: message}
+	}
+}
+
+// loadFavoritesCmd looks up the bookmarks that haven't been looked up yet
+func (m model) loadFavoritesCmd() tea.Cmd {
+	var cmds []tea.Cmd
+	for _, bookmark := range m.bookmarks {
+		if m.bookmarkLoads[strings.ToLower(bookmark.ResourceID)] == nil {
+			cmds = append(cmds, m.loadBookmarkCmd(bookmark))
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+// loadBookmarkCmd looks up a bookmark in its subscription
+func (m model) loadBookmarkCmd(bookmark config.Bookmark) tea.Cmd {
+	m.bookmarkLoads[strings.ToLower(bookmark.ResourceID)] = &bookmarkLoad{loading: true}
+	return func() tea.Msg {
+		msg := bookmarkLoadedMsg{resourceID: bookmark.ResourceID}
+		if bookmark.Type == "group" {
+			msg.resources, msg.err = fetchResourcesInSubscriptionGroup(bookmark.SubscriptionID, bookmark.Name)
+		} else {
+			msg.resource, msg.err = fetchResourceByID(bookmark.ResourceID)
+		}
+		return msg
+	}
+}
+
+// openBookmarkPicker lists the bookmarks to jump to
+func (m *model) openBookmarkPicker() {
+	names := make(map[string]string)
+	for _, subscription := range m.subscriptions {
+		names[strings.ToLower(subscription.ID)] = subscription.Name
+	}
+
+	items := make([]tui.PaletteItem, 0, len(m.bookmarks))
+	for _, bookmark := range m.bookmarks {
+		category := names[strings.ToLower(bookmark.SubscriptionID)]
+		if category == "" {
+			category = bookmark.SubscriptionID
+		}
+		items = append(items, tui.PaletteItem{ID: bookmark.ResourceID, Title: bookmark.Name, Category: category})
+	}
+	m.bookmarkPicker = tui.NewCommandPalette(items)
+	m.bookmarkPicker.EmptyText = "No bookmarks, press " + m.keymap.KeyHint(keymap.ActionToggleBookmark) + " on a tree node to add one"
+}
+
+// updateBookmarkPicker handles keys while the bookmark picker is open
+func (m model) updateBookmarkPicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	picker := m.bookmarkPicker
+	switch m.keymap.Action(keymap.ScopeSearch, msg.String()) {
+	case keymap.ActionSearchExit:
+		m.bookmarkPicker = nil
+		return m, nil
+	case keymap.ActionSearchNext:
+		picker.Move(1)
+		return m, nil
+	case keymap.ActionSearchPrev:
+		picker.Move(-1)
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "enter":
+		item, ok := picker.SelectedItem()
+		m.bookmarkPicker = nil
+		if ok {
+			return m.jumpToBookmark(item.ID)
+		}
+	case "backspace":
+		if len(picker.Query) > 0 {
+			picker.SetQuery(picker.Query[:len(picker.Query)-1])
+		}
+	default:
+		if len(msg.String()) == 1 && msg.String() >= " " && msg.String() <= "~" {
+			picker.SetQuery(picker.Query + msg.String())
+		}
+	}
+	return m, nil
+}
+
+// jumpToBookmark selects a bookmark in the Favorites folder and opens it
+func (m model) jumpToBookmark(resourceID string) (tea.Model, tea.Cmd) {
+	folder := m.treeView.Favorites()
+	if folder == nil {
+		return m, nil
+	}
+	var loadFavorites tea.Cmd
+	if !folder.Expanded {
+		m.treeView.SetExpanded(folder, true)
+		loadFavorites = m.loadFavoritesCmd()
+	}
+
+	for _, node := range folder.Children {
+		bookmark, ok := m.bookmarkFor(node)
+		if !ok || !strings.EqualFold(bookmark.ResourceID, resourceID) {
+			continue
+		}
+		m.selectedPanel = 0
+		m.treeView.Select(node)
+		if node.Expanded {
+			return m, loadFavorites // An open group stays open
+		}
+		model, cmd := m.runAction(keymap.ActionSelect)
+		return model, tea.Batch(loadFavorites, cmd)
+	}
+	return m, loadFavorites
 }
 
 // performSearch executes a search and updates results
@@ -404,6 +874,16 @@ func (m *model) performSearch() {
 		m.searchResults = []search.SearchResult{}
 		m.filteredResources = m.allResources
 		m.showSearchResults = false
+		m.resetFacets()
+		return
+	}
+
+	// Questions are translated on Enter, never searched literally
+	m.searchAIError = ""
+	if _, isQuestion := naturalLanguageQuestion(m.searchQuery); isQuestion {
+		m.searchResults = []search.SearchResult{}
+		m.showSearchResults = false
+		m.resetFacets()
 		return
 	}
 
@@ -411,11 +891,17 @@ func (m *model) performSearch() {
 	if err != nil {
 		m.searchResults = []search.SearchResult{}
 		m.filteredResources = []AzureResource{}
+		m.resetFacets()
 		return
 	}
 
 	m.searchResults = results
 	m.searchResultIndex = 0
+	m.searchFacets = search.ComputeFacets(results)
+	m.facetIndex = 0
+	if len(m.searchFacets) == 0 {
+		m.facetFocus = false
+	}
 
 	// Create filtered resources list from search results
 	resourceMap := make(map[string]AzureResource)
@@ -437,6 +923,58 @@ func (m *model) performSearch() {
 	m.showSearchResults = len(results) > 0
 }
 
+// resetFacets clears the facet sidebar and returns focus to the results
+func (m *model) resetFacets() {
+	m.searchFacets = nil
+	m.facetFocus = false
+	m.facetIndex = 0
+}
+
+// maxFacetsPerGroup limits how many values of each facet field the sidebar lists
+const maxFacetsPerGroup = 5
+
+// visibleFacets returns the facets listed in the sidebar, in display order
+func (m *model) visibleFacets() []search.Facet {
+	var facets []search.Facet
+	for _, group := range m.searchFacets {
+		facets = append(facets, group.Facets[:min(maxFacetsPerGroup, len(group.Facets))]...)
+	}
+	return facets
+}
+
+// applyFacet narrows the current query down to the selected facet
+func (m *model) applyFacet() {
+	facets := m.visibleFacets()
+	if m.facetIndex >= len(facets) {
+		return
+	}
+
+	filter := search.FacetFilter(facets[m.facetIndex])
+	if filter == "" || search.HasFilter(m.searchQuery, filter) {
+		return
+	}
+
+	m.searchQuery = strings.TrimSpace(m.searchQuery + " " + filter)
+	m.addToSearchHistory(m.searchQuery)
+	m.performSearch()
+	m.updateSearchSuggestions()
+}
+
+// facetLabel returns the display name of a facet value
+func (m *model) facetLabel(facet search.Facet) string {
+	switch facet.Field {
+	case search.FacetType:
+		return strings.TrimPrefix(facet.Value, "Microsoft.")
+	case search.FacetSubscription:
+		for _, sub := range m.subscriptions {
+			if strings.EqualFold(sub.ID, facet.Value) {
+				return sub.Name
+			}
+		}
+	}
+	return facet.Value
+}
+
 // addToSearchHistory adds a query to search history
 func (m *model) addToSearchHistory(query string) {
 	if query == "" {
@@ -493,6 +1031,10 @@ func (m *model) enterSearchMode() {
 	m.searchResults = []search.SearchResult{}
 	m.searchResultIndex = 0
 	m.showSearchResults = false
+	m.resetFacets()
+	m.searchTranslating = false
+	m.searchAIQuery = ""
+	m.searchAIError = ""
 }
 
 // exitSearchMode deactivates search mode and resets filters
@@ -503,11 +1045,64 @@ func (m *model) exitSearchMode() {
 	m.searchResultIndex = 0
 	m.showSearchResults = false
 	m.filteredResources = m.allResources
+	m.resetFacets()
+	m.searchTranslating = false
+	m.searchAIQuery = ""
+	m.searchAIError = ""
+}
+
+// updateSearchInput edits the search query for keys that aren't bound to a
+// search action
+func (m model) updateSearchInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		// Questions starting with "?" are translated by the AI provider
+		if question, isQuestion := naturalLanguageQuestion(m.searchQuery); isQuestion {
+			if question == "" || m.searchTranslating {
+				return m, nil
+			}
+			if m.aiProvider == nil {
+				m.searchAIError = "AI provider not configured. Set GITHUB_TOKEN or OPENAI_API_KEY environment variable."
+				return m, nil
+			}
+			m.searchTranslating = true
+			m.searchAIError = ""
+			return m, translateSearchQueryCmd(m.aiProvider, question)
+		}
+		// Execute search and add to history
+		if m.searchQuery != "" {
+			m.addToSearchHistory(m.searchQuery)
+			m.performSearch()
+		}
+	case "backspace":
+		// Remove last character from search query
+		if len(m.searchQuery) > 0 {
+			m.searchQuery = m.searchQuery[:len(m.searchQuery)-1]
+			m.performSearch()
+			m.updateSearchSuggestions()
+		}
+	case "tab":
+		// Accept first suggestion if available
+		if len(m.searchSuggestions) > 0 {
+			m.searchQuery = m.searchSuggestions[0]
+			m.performSearch()
+		}
+	default:
+		// Add character to search query
+		if len(msg.String()) == 1 && msg.String() >= " " && msg.String() <= "~" {
+			m.searchQuery += msg.String()
+			m.performSearch()
+			m.updateSearchSuggestions()
+		}
+	}
+	return m, nil
 }
 
 // updateSearchSuggestions updates search suggestions based on current query
 func (m *model) updateSearchSuggestions() {
-	if len(m.searchQuery) >= 2 {
+	if _, isQuestion := naturalLanguageQuestion(m.searchQuery); isQuestion {
+		m.searchSuggestions = []string{}
+	} else if len(m.searchQuery) >= 2 {
 		m.searchSuggestions = m.searchEngine.GetSuggestions(m.searchQuery)
 	} else {
 		m.searchSuggestions = []string{}
@@ -533,6 +1128,24 @@ func (m *model) renderSearchInput(width int) string {
 	}
 
 	content := prompt + m.searchQuery + cursor
+	if m.searchSaveMode {
+		content = fmt.Sprintf("💾 Save %q as: %s█", m.searchQuery, m.searchSaveName)
+		return searchStyle.Render(content)
+	}
+
+	// Natural-language questions show their translation for confirmation
+	if m.searchAIQuery != "" {
+		content = "🤖 Run query: " + lipgloss.NewStyle().Foreground(colorAqua).Bold(true).Render(m.searchAIQuery)
+		content += lipgloss.NewStyle().Faint(true).Render("  (Enter to run, Esc to edit)")
+		return searchStyle.Render(content)
+	}
+	if m.searchTranslating {
+		content += lipgloss.NewStyle().Faint(true).Render("  🤖 Translating...")
+	} else if m.searchAIError != "" {
+		content += "\n" + lipgloss.NewStyle().Foreground(colorRed).Render("❌ "+m.searchAIError)
+	} else if _, isQuestion := naturalLanguageQuestion(m.searchQuery); isQuestion {
+		content += lipgloss.NewStyle().Faint(true).Render("  (Enter to translate with AI)")
+	}
 
 	// Show suggestions if available
 	if len(m.searchSuggestions) > 0 {
@@ -557,21 +1170,35 @@ func (m *model) renderSearchResults(width, height int) string {
 		return ""
 	}
 
+	// Show the facet sidebar next to the results when there is room for both
+	listWidth := width
+	sidebar := ""
+	if len(m.searchFacets) > 0 && width >= 70 {
+		sidebarWidth := min(32, width/3)
+		listWidth = width - sidebarWidth - 2
+		sidebar = m.renderFacetSidebar(sidebarWidth)
+	}
+
 	var content strings.Builder
 	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(colorBlue)
 	content.WriteString(headerStyle.Render(fmt.Sprintf("🔍 Search Results (%d found)", len(m.searchResults))))
 	content.WriteString("\n\n")
 
-	// Group results by resource for better display
+	// Group results by resource for better display, keeping the ranked order
 	resourceResults := make(map[string][]search.SearchResult)
+	var resourceOrder []string
 	for _, result := range m.searchResults {
+		if _, seen := resourceResults[result.ResourceID]; !seen {
+			resourceOrder = append(resourceOrder, result.ResourceID)
+		}
 		resourceResults[result.ResourceID] = append(resourceResults[result.ResourceID], result)
 	}
 
 	maxResults := min(height-5, len(resourceResults))
 	resultCount := 0
 
-	for resourceID, results := range resourceResults {
+	for _, resourceID := range resourceOrder {
+		results := resourceResults[resourceID]
 		if resultCount >= maxResults {
 			break
 		}
@@ -588,17 +1215,19 @@ func (m *model) renderSearchResults(width, height int) string {
 		// Highlight current selection
 		isSelected := resultCount == m.searchResultIndex
 		nameStyle := lipgloss.NewStyle().Foreground(colorGreen)
+		icon := theme.Icon("📦", "  ")
 		if isSelected {
 			nameStyle = nameStyle.Background(bgLight).Bold(true)
+			icon = theme.Icon("📦", "> ")
 		}
 
-		content.WriteString(nameStyle.Render(fmt.Sprintf("📦 %s", resource.Name)))
+		content.WriteString(nameStyle.Render(fmt.Sprintf("%s %s", icon, resource.Name)))
 		content.WriteString(fmt.Sprintf(" (%s)\n", lipgloss.NewStyle().Foreground(colorGray).Render(resource.Type)))
 
 		// Show match details
 		for _, result := range results {
 			matchStyle := lipgloss.NewStyle().Foreground(colorYellow).Faint(true)
-			content.WriteString(fmt.Sprintf("   %s: %s\n", matchStyle.Render(result.MatchType), result.MatchValue))
+			content.WriteString(fmt.Sprintf("   %s: %s\n", matchStyle.Render(result.MatchType), highlightMatchSpans(result.MatchValue, result.MatchSpans)))
 		}
 
 		content.WriteString("\n")
@@ -610,7 +1239,92 @@ func (m *model) renderSearchResults(width, height int) string {
 		content.WriteString(moreStyle.Render(fmt.Sprintf("... and %d more results", len(resourceResults)-maxResults)))
 	}
 
-	return content.String()
+	list := lipgloss.NewStyle().Width(listWidth).Render(content.String())
+	if sidebar == "" {
+		return list
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, list, "  ", sidebar)
+}
+
+// facetFieldTitles are the sidebar headings of the facet fields
+var facetFieldTitles = map[string]string{
+	search.FacetType:          "Type",
+	search.FacetLocation:      "Location",
+	search.FacetResourceGroup: "Resource Group",
+	search.FacetSubscription:  "Subscription",
+	search.FacetTag:           "Tags",
+}
+
+// renderFacetSidebar renders result counts by type, location, resource
+// group, subscription and tag value
+func (m *model) renderFacetSidebar(width int) string {
+	var content strings.Builder
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(colorBlue)
+	groupStyle := lipgloss.NewStyle().Foreground(colorYellow)
+	countStyle := lipgloss.NewStyle().Foreground(colorGray)
+	selectedStyle := lipgloss.NewStyle().Background(bgLight).Foreground(colorGreen).Bold(true)
+	hintStyle := lipgloss.NewStyle().Faint(true).Foreground(colorGray)
+
+	content.WriteString(headerStyle.Render("🏷️ Facets"))
+	content.WriteString("\n")
+	if m.facetFocus {
+		content.WriteString(hintStyle.Render("↑/↓ select • Enter filter • Ctrl+F back"))
+	} else {
+		content.WriteString(hintStyle.Render("Ctrl+F to narrow down"))
+	}
+	content.WriteString("\n")
+
+	index := 0
+	for _, group := range m.searchFacets {
+		content.WriteString("\n")
+		content.WriteString(groupStyle.Render(facetFieldTitles[group.Field]))
+		content.WriteString("\n")
+
+		shown := min(maxFacetsPerGroup, len(group.Facets))
+		for _, facet := range group.Facets[:shown] {
+			count := fmt.Sprintf("%d", facet.Count)
+			labelWidth := max(width-len(count)-3, 4)
+			label := m.facetLabel(facet)
+			if len(label) > labelWidth {
+				label = label[:labelWidth-1] + "…"
+			}
+
+			if m.facetFocus && index == m.facetIndex {
+				content.WriteString(selectedStyle.Render(fmt.Sprintf("▶ %-*s %s", labelWidth, label, count)))
+			} else {
+				content.WriteString(fmt.Sprintf("  %-*s %s", labelWidth, label, countStyle.Render(count)))
+			}
+			content.WriteString("\n")
+			index++
+		}
+		if more := len(group.Facets) - shown; more > 0 {
+			content.WriteString(hintStyle.Render(fmt.Sprintf("  … %d more", more)))
+			content.WriteString("\n")
+		}
+	}
+
+	return lipgloss.NewStyle().Width(width).Render(content.String())
+}
+
+// highlightMatchSpans renders the matched ranges of a search result value in bold
+func highlightMatchSpans(value string, spans []search.MatchSpan) string {
+	if len(spans) == 0 {
+		return value
+	}
+
+	highlightStyle := lipgloss.NewStyle().Foreground(colorAqua).Bold(true).Underline(true)
+	var b strings.Builder
+	pos := 0
+	for _, span := range spans {
+		if span.Start < pos || span.End > len(value) || span.Start >= span.End {
+			continue
+		}
+		b.WriteString(value[pos:span.Start])
+		b.WriteString(highlightStyle.Render(value[span.Start:span.End]))
+		pos = span.End
+	}
+	b.WriteString(value[pos:])
+	return b.String()
 }
 
 // handleTerraformMenuSelection handles the selection from the Terraform menu
@@ -690,13 +1404,16 @@ func (m *model) handleTerraformDeploymentSelection() (tea.Model, tea.Cmd) {
 	switch m.terraformMenuIndex {
 	case 0: // Initialize Terraform (terraform init)
 		m.showTerraformPopup = false
-		return *m, executeTerraformOperationCmd("init", m.terraformFolderPath)
+		cmd := m.startTerraformJob("init", m.terraformFolderPath)
+		return *m, cmd
 	case 1: // Plan Deployment (terraform plan)
 		m.showTerraformPopup = false
-		return *m, executeTerraformOperationCmd("plan", m.terraformFolderPath)
+		cmd := m.startTerraformJob("plan", m.terraformFolderPath)
+		return *m, cmd
 	case 2: // Deploy Infrastructure (terraform apply)
 		m.showTerraformPopup = false
-		return *m, executeTerraformOperationCmd("apply", m.terraformFolderPath)
+		cmd := m.startTerraformJob("apply", m.terraformFolderPath)
+		return *m, cmd
 	case 3: // Edit Template Files
 		m.showTerraformPopup = false
 		return *m, analyzeTerraformCodeCmd(m.terraformFolderPath)
@@ -773,6 +1490,31 @@ func (m *model) handleDevOpsMenuSelection() (tea.Model, tea.Cmd) {
 	return *m, nil
 }
 
+// settingsMenuOptions are the entries of the Settings menu, in the order
+// handleSettingsMenuSelection expects them
+var settingsMenuOptions = []string{
+	"📋 View Configuration",
+	"📁 Edit Terraform Directory",
+	"🖥️  Edit UI Settings",
+	"📝 Edit Editor Settings",
+	"🎨 Change Theme",
+	"💾 Save Configuration",
+}
+
+// previewSettingsTheme applies the theme highlighted in the theme picker
+func (m *model) previewSettingsTheme() {
+	if m.settingsMenuIndex >= len(m.settingsThemes) {
+		return
+	}
+	name := m.settingsThemes[m.settingsMenuIndex]
+	t, err := theme.Load(name)
+	if err != nil {
+		m.logEntries = append(m.logEntries, "Theme Error: "+err.Error())
+		return
+	}
+	applyTheme(t)
+}
+
 // handleSettingsMenuSelection handles the selection from the Settings menu
 func (m *model) handleSettingsMenuSelection() (tea.Model, tea.Cmd) {
 	switch m.settingsMode {
@@ -801,12 +1543,30 @@ func (m *model) handleSettingsMenuSelection() (tea.Model, tea.Cmd) {
 				m.settingsEditValue = m.settingsCurrentConfig.Editor.DefaultEditor
 			}
 			return *m, nil
-		case 4: // Save Configuration
+		case 4: // Change Theme
+			m.settingsMode = "theme-select"
+			m.settingsThemes = theme.Names()
+			m.settingsThemeBefore = *theme.Current()
+			m.settingsMenuIndex = max(0, slices.Index(m.settingsThemes, theme.Current().Name))
+			return *m, nil
+		case 5: // Save Configuration
 			if m.settingsCurrentConfig != nil {
 				m.showSettingsPopup = false
 				return *m, saveSettingsConfigCmd(m.settingsCurrentConfig)
 			}
 		}
+	case "theme-select":
+		// The highlighted theme is already previewed, so only persist it
+		if m.settingsMenuIndex >= len(m.settingsThemes) {
+			return *m, nil
+		}
+		name := m.settingsThemes[m.settingsMenuIndex]
+		if m.settingsCurrentConfig != nil {
+			m.settingsCurrentConfig.UI.ColorScheme = name
+		}
+		m.settingsMode = "menu"
+		m.settingsMenuIndex = 4
+		return *m, saveColorSchemeCmd(name)
 	case "folder-browser":
 		if m.settingsMenuIndex < len(m.settingsFolders) {
 			selectedPath := m.settingsFolders[m.settingsMenuIndex]
@@ -834,7 +1594,7 @@ func fetchSubscriptions() ([]Subscription, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, "az", "account", "list", "--output", "json")
+	cmd := azcli.CommandContext(ctx, "account", "list", "--output", "json")
 	output, err := cmd.Output()
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch subscriptions: %v", err)
@@ -864,7 +1624,7 @@ func fetchResourceGroups() ([]ResourceGroup, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, "az", "group", "list", "--output", "json")
+	cmd := azcli.CommandContext(ctx, "group", "list", "--output", "json")
 	output, err := cmd.Output()
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch resource groups: %v", err)
@@ -887,10 +1647,20 @@ func fetchResourceGroups() ([]ResourceGroup, error) {
 }
 
 func fetchResourcesInGroup(groupName string) ([]AzureResource, error) {
+	return fetchResourcesInSubscriptionGroup("", groupName)
+}
+
+// fetchResourcesInSubscriptionGroup lists the resources of a group in the
+// given subscription, or in the current one when subscriptionID is empty
+func fetchResourcesInSubscriptionGroup(subscriptionID, groupName string) ([]AzureResource, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, "az", "resource", "list", "--resource-group", groupName, "--output", "json")
+	args := []string{"resource", "list", "--resource-group", groupName, "--output", "json"}
+	if subscriptionID != "" {
+		args = append(args, "--subscription", subscriptionID)
+	}
+	cmd := azcli.CommandContext(ctx, args...)
 	output, err := cmd.Output()
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch resources: %v", err)
@@ -915,7 +1685,8 @@ func fetchResourcesInGroup(groupName string) ([]AzureResource, error) {
 			ResourceGroup: groupName, Tags: r.Tags,
 		}
 
-		if r.Type == "Microsoft.Compute/virtualMachines" {
+		// VM status is only looked up in the current subscription
+		if r.Type == "Microsoft.Compute/virtualMachines" && subscriptionID == "" {
 			if status, err := resourceactions.GetVMStatus(r.Name, groupName); err == nil {
 				resource.Status = status
 			}
@@ -925,6 +1696,35 @@ func fetchResourcesInGroup(groupName string) ([]AzureResource, error) {
 	return resources, nil
 }
 
+// fetchResourceByID looks up a single resource in any subscription
+func fetchResourceByID(resourceID string) (*AzureResource, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cmd := azcli.CommandContext(ctx, "resource", "show", "--ids", resourceID, "--output", "json")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch resource: %v", err)
+	}
+
+	var r struct {
+		ID            string            `json:"id"`
+		Name          string            `json:"name"`
+		Type          string            `json:"type"`
+		Location      string            `json:"location"`
+		ResourceGroup string            `json:"resourceGroup"`
+		Tags          map[string]string `json:"tags"`
+	}
+	if err := json.Unmarshal(output, &r); err != nil {
+		return nil, fmt.Errorf("failed to parse resource data: %v", err)
+	}
+
+	return &AzureResource{
+		ID: r.ID, Name: r.Name, Type: r.Type, Location: r.Location,
+		ResourceGroup: r.ResourceGroup, Tags: r.Tags,
+	}, nil
+}
+
 func loadDataCmd() tea.Cmd {
 	return func() tea.Msg {
 		subs, err := fetchSubscriptions()
@@ -1007,48 +1807,49 @@ func loadAIDescriptionCmd(ai *openai.AIProvider, resource AzureResource, details
 	}
 }
 
-func executeResourceActionCmd(action string, resource AzureResource) tea.Cmd {
-	return func() tea.Msg {
-		var result resourceactions.ActionResult
+// jobResourceActions are the resource actions that run as background jobs
+var jobResourceActions = []string{"start", "stop", "restart"}
 
+// runResourceLifecycleAction starts, stops or restarts a VM, AKS cluster or
+// container instance, streaming the command output to out
+func runResourceLifecycleAction(ctx context.Context, out io.Writer, action string, resource AzureResource) resourceactions.ActionResult {
+	switch resource.Type {
+	case "Microsoft.Compute/virtualMachines":
 		switch action {
 		case "start":
-			if resource.Type == "Microsoft.Compute/virtualMachines" {
-				result = resourceactions.StartVM(resource.Name, resource.ResourceGroup)
-			} else if resource.Type == "Microsoft.ContainerService/managedClusters" {
-				result = resourceactions.StartAKSCluster(resource.Name, resource.ResourceGroup)
-			} else if resource.Type == "Microsoft.ContainerInstance/containerGroups" {
-				err := aci.StartContainerInstance(resource.Name, resource.ResourceGroup)
-				if err != nil {
-					result = resourceactions.ActionResult{Success: false, Message: fmt.Sprintf("Failed to start container instance: %v", err)}
-				} else {
-					result = resourceactions.ActionResult{Success: true, Message: fmt.Sprintf("Successfully started container instance %s", resource.Name)}
-				}
-			}
+			return resourceactions.StartVMContext(ctx, out, resource.Name, resource.ResourceGroup)
 		case "stop":
-			if resource.Type == "Microsoft.Compute/virtualMachines" {
-				result = resourceactions.StopVM(resource.Name, resource.ResourceGroup)
-			} else if resource.Type == "Microsoft.ContainerService/managedClusters" {
-				result = resourceactions.StopAKSCluster(resource.Name, resource.ResourceGroup)
-			} else if resource.Type == "Microsoft.ContainerInstance/containerGroups" {
-				err := aci.StopContainerInstance(resource.Name, resource.ResourceGroup)
-				if err != nil {
-					result = resourceactions.ActionResult{Success: false, Message: fmt.Sprintf("Failed to stop container instance: %v", err)}
-				} else {
-					result = resourceactions.ActionResult{Success: true, Message: fmt.Sprintf("Successfully stopped container instance %s", resource.Name)}
-				}
-			}
+			return resourceactions.StopVMContext(ctx, out, resource.Name, resource.ResourceGroup)
 		case "restart":
-			if resource.Type == "Microsoft.Compute/virtualMachines" {
-				result = resourceactions.RestartVM(resource.Name, resource.ResourceGroup)
-			} else if resource.Type == "Microsoft.ContainerInstance/containerGroups" {
-				err := aci.RestartContainerInstance(resource.Name, resource.ResourceGroup)
-				if err != nil {
-					result = resourceactions.ActionResult{Success: false, Message: fmt.Sprintf("Failed to restart container instance: %v", err)}
-				} else {
-					result = resourceactions.ActionResult{Success: true, Message: fmt.Sprintf("Successfully restarted container instance %s", resource.Name)}
-				}
-			}
+			return resourceactions.RestartVMContext(ctx, out, resource.Name, resource.ResourceGroup)
+		}
+	case "Microsoft.ContainerService/managedClusters":
+		switch action {
+		case "start":
+			return resourceactions.StartAKSClusterContext(ctx, out, resource.Name, resource.ResourceGroup)
+		case "stop":
+			return resourceactions.StopAKSClusterContext(ctx, out, resource.Name, resource.ResourceGroup)
+		}
+	case "Microsoft.ContainerInstance/containerGroups":
+		past := map[string]string{"start": "started", "stop": "stopped", "restart": "restarted"}[action]
+		if past == "" {
+			break
+		}
+		if err := aci.ContainerInstanceActionContext(ctx, out, action, resource.Name, resource.ResourceGroup); err != nil {
+			return resourceactions.ActionResult{Success: false, Message: fmt.Sprintf("Failed to %s container instance: %v", action, err)}
+		}
+		return resourceactions.ActionResult{Success: true, Message: fmt.Sprintf("Successfully %s container instance %s", past, resource.Name)}
+	}
+	return resourceactions.ActionResult{Success: false, Message: "Unsupported action"}
+}
+
+func executeResourceActionCmd(action string, resource AzureResource) tea.Cmd {
+	return func() tea.Msg {
+		var result resourceactions.ActionResult
+
+		switch action {
+		case "start", "stop", "restart":
+			result = runResourceLifecycleAction(context.Background(), nil, action, resource)
 		case "ssh":
 			if resource.Type == "Microsoft.Compute/virtualMachines" {
 				result = resourceactions.ExecuteVMSSH(resource.Name, resource.ResourceGroup, "azureuser")
@@ -1073,6 +1874,11 @@ func executeResourceActionCmd(action string, resource AzureResource) tea.Cmd {
 			if resource.Type == "Microsoft.ContainerService/managedClusters" {
 				result = resourceactions.ListAKSServices(resource.Name, resource.ResourceGroup)
 			}
+		case "scale", "connect", "logs":
+			// AKS actions without a key, run from the command palette
+			if resource.Type == "Microsoft.ContainerService/managedClusters" {
+				result = resourceactions.ExecuteResourceAction(action, resource.Type, resource.Name, resource.ResourceGroup, map[string]interface{}{})
+			}
 		default:
 			result = resourceactions.ActionResult{Success: false, Message: "Unsupported action"}
 		}
@@ -1586,28 +2392,33 @@ func deleteStorageContainerCmd(accountName, containerName string) tea.Cmd {
 	}
 }
 
-// uploadBlobCmd uploads a file to a blob container
-func uploadBlobCmd(accountName, containerName, blobName, filePath string) tea.Cmd {
-	return func() tea.Msg {
-		err := storage.UploadBlob(accountName, containerName, blobName, filePath)
-		var result resourceactions.ActionResult
-
+// startUploadBlobJob uploads a file to a blob container as a background job
+func (m *model) startUploadBlobJob(accountName, containerName, blobName, filePath string) tea.Cmd {
+	var result resourceactions.ActionResult
+	upload := func(ctx context.Context, out io.Writer) (string, error) {
+		err := storage.UploadBlobContext(ctx, out, accountName, containerName, blobName, filePath)
 		if err != nil {
 			result = resourceactions.ActionResult{
 				Success: false,
 				Message: fmt.Sprintf("Failed to upload blob '%s': %v", blobName, err),
 				Output:  "",
 			}
-		} else {
-			result = resourceactions.ActionResult{
-				Success: true,
-				Message: fmt.Sprintf("Successfully uploaded blob '%s' to container '%s'", blobName, containerName),
-				Output:  fmt.Sprintf("Blob '%s' is now available in container", blobName),
-			}
+			return "", err
+		}
+		result = resourceactions.ActionResult{
+			Success: true,
+			Message: fmt.Sprintf("Successfully uploaded blob '%s' to container '%s'", blobName, containerName),
+			Output:  fmt.Sprintf("Blob '%s' is now available in container", blobName),
+		}
+		return result.Message, nil
+	}
+	done := func(job jobs.Job) tea.Msg {
+		if job.Status == jobs.Cancelled {
+			result = resourceactions.ActionResult{Success: false, Message: fmt.Sprintf("Upload of blob '%s' cancelled", blobName)}
 		}
-
 		return storageActionMsg{action: "upload-blob", result: result}
 	}
+	return m.startJob(fmt.Sprintf("Upload %s to %s", blobName, containerName), upload, done)
 }
 
 // deleteBlobCmd deletes a blob from a container
@@ -1639,73 +2450,96 @@ func (m model) getContextualShortcuts() string {
 	var shortcuts []string
 
 	// Always available shortcuts
-	baseShortcuts := []string{"Tab:Switch", "?:Help", "q:Quit"}
+	baseShortcuts := []string{
+		m.shortcutHint(keymap.ActionTogglePanel, "Switch"), m.shortcutHint(keymap.ActionCommandPalette, "Commands"),
+		m.shortcutHint(keymap.ActionHelp, "Help"), m.shortcutHint(keymap.ActionQuit, "Quit"),
+	}
 
 	// Context-specific shortcuts based on selected resource
 	if m.selectedResource != nil {
 		switch m.selectedResource.Type {
 		case "Microsoft.Compute/virtualMachines":
 			shortcuts = append(shortcuts, []string{
-				"s:Start", "S:Stop", "r:Restart",
-				"c:SSH", "b:Bastion", "d:Dashboard",
+				m.shortcutHint(keymap.ActionStart, "Start"), m.shortcutHint(keymap.ActionStop, "Stop"), m.shortcutHint(keymap.ActionRestart, "Restart"),
+				m.shortcutHint(keymap.ActionSSH, "SSH"), m.shortcutHint(keymap.ActionBastion, "Bastion"),
 			}...)
 
 		case "Microsoft.ContainerService/managedClusters":
 			shortcuts = append(shortcuts, []string{
-				"s:Start", "S:Stop", "p:Pods",
-				"y:Deployments", "n:Nodes", "v:Services",
+				m.shortcutHint(keymap.ActionStart, "Start"), m.shortcutHint(keymap.ActionStop, "Stop"), m.shortcutHint(keymap.ActionAKSPods, "Pods"),
+				m.shortcutHint(keymap.ActionAKSDeployments, "Deployments"), m.shortcutHint(keymap.ActionAKSNodes, "Nodes"), m.shortcutHint(keymap.ActionAKSServices, "Services"),
 			}...)
 
 		case "Microsoft.ContainerInstance/containerGroups":
 			shortcuts = append(shortcuts, []string{
-				"s:Start", "S:Stop", "r:Restart",
-				"L:Logs", "E:Exec", "a:Attach", "u:Scale", "I:Details",
+				m.shortcutHint(keymap.ActionStart, "Start"), m.shortcutHint(keymap.ActionStop, "Stop"), m.shortcutHint(keymap.ActionRestart, "Restart"),
+				m.shortcutHint(keymap.ActionContainerLogs, "Logs"), m.shortcutHint(keymap.ActionContainerExec, "Exec"), m.shortcutHint(keymap.ActionAnalyze, "Attach"),
+				m.shortcutHint(keymap.ActionContainerScale, "Scale"), m.shortcutHint(keymap.ActionContainerDetails, "Details"),
 			}...)
 
 		case "Microsoft.Network/virtualNetworks":
 			shortcuts = append(shortcuts, []string{
-				"V:VNet Details", "N:Network Dashboard",
-				"Z:Topology", "A:AI Analysis", "C:Create VNet",
+				m.shortcutHint(keymap.ActionVNetDetails, "VNet Details"), m.shortcutHint(keymap.ActionNetworkDashboard, "Network Dashboard"),
+				m.shortcutHint(keymap.ActionNetworkTopology, "Topology"), m.shortcutHint(keymap.ActionNetworkAI, "AI Analysis"), m.shortcutHint(keymap.ActionCreate, "Create VNet"),
 			}...)
 
 		case "Microsoft.Network/networkSecurityGroups":
 			shortcuts = append(shortcuts, []string{
-				"G:NSG Details", "N:Network Dashboard",
-				"Z:Topology", "A:AI Analysis", "Ctrl+N:Create NSG",
+				m.shortcutHint(keymap.ActionNSGDetails, "NSG Details"), m.shortcutHint(keymap.ActionNetworkDashboard, "Network Dashboard"),
+				m.shortcutHint(keymap.ActionNetworkTopology, "Topology"), m.shortcutHint(keymap.ActionNetworkAI, "AI Analysis"), m.shortcutHint(keymap.ActionCreateNSG, "Create NSG"),
 			}...)
 
 		case "Microsoft.Storage/storageAccounts":
 			shortcuts = append(shortcuts, []string{
-				"T:List Containers", "Shift+T:Create Container", "B:List Blobs",
-				"U:Upload Blob", "Ctrl+X:Delete Item", "d:Dashboard", "R:Refresh",
+				m.shortcutHint(keymap.ActionListContainers, "List Containers"), m.shortcutHint(keymap.ActionCreateContainer, "Create Container"), m.shortcutHint(keymap.ActionListBlobs, "List Blobs"),
+				m.shortcutHint(keymap.ActionUploadBlob, "Upload Blob"), m.shortcutHint(keymap.ActionDeleteItem, "Delete Item"), m.shortcutHint(keymap.ActionRefresh, "Refresh"),
 			}...)
 
 		case "Microsoft.KeyVault/vaults":
 			shortcuts = append(shortcuts, []string{
-				"K:List Secrets", "Shift+K:Create Secret", "Ctrl+D:Delete Secret",
-				"d:Dashboard", "R:Refresh",
+				m.shortcutHint(keymap.ActionListSecrets, "List Secrets"), m.shortcutHint(keymap.ActionCreate, "Create Secret"), m.shortcutHint(keymap.ActionDeleteSecret, "Delete Secret"),
+				m.shortcutHint(keymap.ActionRefresh, "Refresh"),
 			}...)
 
 		default:
 			// Generic resource shortcuts
 			shortcuts = append(shortcuts, []string{
-				"d:Dashboard", "R:Refresh",
+				m.shortcutHint(keymap.ActionRefresh, "Refresh"),
 			}...)
 		}
 	} else {
 		// No resource selected - show navigation shortcuts
 		shortcuts = append(shortcuts, []string{
-			"N:Network Dashboard", "Z:Topology", "A:AI Analysis",
-			"Space:Expand", "Enter:Select", "R:Refresh",
+			m.shortcutHint(keymap.ActionNetworkDashboard, "Network Dashboard"), m.shortcutHint(keymap.ActionNetworkTopology, "Topology"), m.shortcutHint(keymap.ActionNetworkAI, "AI Analysis"),
+			m.shortcutHint(keymap.ActionSelect, "Expand/Select"), m.shortcutHint(keymap.ActionRefresh, "Refresh"),
 		}...)
 	}
 
 	// Add base shortcuts
 	shortcuts = append(shortcuts, baseShortcuts...)
 
+	// Drop hints for actions that were unbound in the keymap
+	shortcuts = slices.DeleteFunc(shortcuts, func(hint string) bool { return hint == "" })
+
 	return strings.Join(shortcuts, " ")
 }
 
+// actionKey formats the keys bound to action for the action lists in the
+// details panel, e.g. "[s]"
+func (m model) actionKey(action string) string {
+	return "[" + m.keymap.KeyHint(action) + "]"
+}
+
+// shortcutHint formats a status bar hint such as "s:Start" with the first
+// key bound to action, or returns "" if the action has no key
+func (m model) shortcutHint(action, label string) string {
+	keys := m.keymap.Keys(action)
+	if len(keys) == 0 {
+		return ""
+	}
+	return keymap.Format(keys[0]) + ":" + label
+}
+
 // getTerraformShortcuts returns relevant shortcuts based on the current Terraform mode
 func (m model) getTerraformShortcuts() string {
 	var shortcuts []string
@@ -1792,6 +2626,11 @@ func (m model) getSettingsShortcuts() string {
 			"↑/↓:Navigate", "Enter:Select", "Esc:Back",
 		}...)
 
+	case "theme-select":
+		shortcuts = append(shortcuts, []string{
+			"↑/↓:Preview", "Enter:Apply", "Esc:Cancel",
+		}...)
+
 	case "edit-setting":
 		shortcuts = append(shortcuts, []string{
 			"Esc:Back",
@@ -1805,225 +2644,2429 @@ func (m model) getSettingsShortcuts() string {
 	return strings.Join(shortcuts, " ")
 }
 
-func initModel() model {
-	// Initialize AI provider with auto-detection (GitHub Copilot or OpenAI)
-	ai := openai.NewAIProviderAuto()
+// =============================================================================
+// EQUIVALENT COMMANDS
+// =============================================================================
 
-	return model{
-		treeView:               tui.NewTreeView(),
-		statusBar:              tui.CreatePowerlineStatusBar(80),
-		aiProvider:             ai,
-		loadingState:           "loading",
-		selectedPanel:          0,
-		rightPanelScrollOffset: 0,
-		leftPanelScrollOffset:  0, // Initialize left panel scroll offset
-		rightPanelMaxLines:     50,
-		showDashboard:          false,
-		logEntries:             []string{},
-		activeView:             "welcome",
-		propertyExpandedIndex:  -1,
-		expandedProperties:     make(map[string]bool),
-		showHelpPopup:          false,
-		helpScrollOffset:       0,
-		navigationStack:        []string{}, // Initialize navigation stack
-		// Initialize search functionality
-		searchEngine:      search.NewSearchEngine(),
-		searchMode:        false,
-		searchQuery:       "",
-		searchResults:     []search.SearchResult{},
-		searchResultIndex: 0,
-		searchSuggestions: []string{},
-		showSearchResults: false,
-		searchHistory:     []string{},
-		filteredResources: []AzureResource{},
-		// Initialize Terraform functionality
-		showTerraformPopup:  false,
-		terraformMenuIndex:  0,
-		terraformMode:       "menu",
-		terraformFolderPath: "",
-		terraformMenuOptions: []string{
-			"Browse Folders",
-			"Create from Template",
-			"Analyze Code",
-			"Terraform Operations",
-			"Open External Editor",
-		},
-		terraformFolders:      []string{},
-		terraformAnalysis:     "",
-		terraformMenuAction:   "",
-		terraformScrollOffset: 0,
-		// Initialize DevOps functionality
-		showDevOpsPopup: false,
-		devopsMenuIndex: 0,
-		devopsMode:      "menu",
-		devopsMenuOptions: []string{
-			"Browse Organizations",
-			"List Projects",
-			"View Pipelines",
-			"Pipeline Operations",
-			"DevOps Analytics",
-		},
-		devopsOrganizations: []devops.Organization{},
-		devopsPipelines:     []devops.Pipeline{},
-		devopsScrollOffset:  0,
-		devopsResult:        "",
-		devopsManager:       nil,
-		// Initialize Settings functionality
-		showSettingsPopup:     false,
-		settingsMenuIndex:     0,
-		settingsMode:          "menu",
-		settingsCurrentPath:   "",
-		settingsFolders:       []string{},
-		settingsConfigContent: "",
-		settingsEditKey:       "",
-		settingsEditValue:     "",
-		settingsCurrentConfig: nil,
-		// Initialize Subscription selection functionality
-		currentSubscription:    nil,
-		showSubscriptionPopup:  false,
-		subscriptionMenuIndex:  0,
-		availableSubscriptions: []Subscription{},
-		subscriptionMenuMode:   "menu",
-	}
+// commandsPopup lists az commands with their PowerShell and REST
+// equivalents: the actions of the selected resource and what the session
+// has run, newest first
+type commandsPopup struct {
+	entries []commandEntry
+	index   int
 }
 
-func (m model) Init() tea.Cmd {
-	return tea.Batch(
-		loadDataCmd(),
-		getCurrentSubscriptionCmd(),
-	)
+// commandEntry is a command in the commands popup
+type commandEntry struct {
+	section    string
+	title      string
+	equivalent azcli.Equivalent
 }
 
-func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	switch msg := msg.(type) {
-	case tea.WindowSizeMsg:
-		m.width, m.height, m.ready = msg.Width, msg.Height, true
-		if m.statusBar != nil {
-			m.statusBar.Width = msg.Width
-		}
+// Commands of the session shown in the commands popup
+const maxSessionCommands = 50
 
-	case subscriptionsLoadedMsg:
-		m.subscriptions = msg.subscriptions
+// move changes the selected command
+func (p *commandsPopup) move(delta int) {
+	p.index = max(0, min(p.index+delta, len(p.entries)-1))
+}
 
-	case resourceGroupsLoadedMsg:
-		m.resourceGroups = msg.groups
-		m.loadingState = "ready"
-		if m.treeView != nil {
-			for _, group := range msg.groups {
-				groupNode := m.treeView.AddResourceGroup(group.Name, group.Location)
-				m.treeView.AddResource(groupNode, "Loading...", "placeholder", nil)
-			}
-			m.treeView.EnsureSelection()
-		}
+// openCommandsPopup collects the commands for the popup
+func (m *model) openCommandsPopup() {
+	subscriptionID := ""
+	if m.currentSubscription != nil {
+		subscriptionID = m.currentSubscription.ID
+	}
 
-	case resourcesInGroupMsg:
-		if m.treeView != nil {
-			for _, groupNode := range m.treeView.Root.Children {
-				if groupNode.Name == msg.groupName {
-					groupNode.Children = []*tui.TreeNode{}
-					for _, resource := range msg.resources {
-						m.treeView.AddResource(groupNode, resource.Name, resource.Type, resource)
-					}
-					break
-				}
+	popup := &commandsPopup{}
+	if resource := m.selectedResource; resource != nil {
+		section := "Selected resource: " + resource.Name
+		args := []string{"resource", "show", "--ids", resource.ID, "--output", "json"}
+		popup.entries = append(popup.entries, commandEntry{section, "Show details", azcli.Equivalents(args, subscriptionID)})
+		for _, action := range []string{"start", "stop", "restart", "connect"} {
+			if args := resourceactions.CommandArgs(action, resource.Type, resource.Name, resource.ResourceGroup); args != nil {
+				title := strings.ToUpper(action[:1]) + action[1:]
+				popup.entries = append(popup.entries, commandEntry{section, title, azcli.Equivalents(args, subscriptionID)})
 			}
 		}
-		m.allResources = append(m.allResources, msg.resources...)
-		// Update search engine with new resources
-		m.updateSearchEngine()
+	}
 
-	case resourceDetailsLoadedMsg:
-		m.selectedResource = &msg.resource
-		m.resourceDetails = msg.details
-		// AI analysis is now manual-only by default - users must press 'a' to trigger
-		// Auto-analysis can be enabled by setting AZURE_TUI_AUTO_AI="true"
-		autoAI := os.Getenv("AZURE_TUI_AUTO_AI") == "true" // Default to false - manual trigger only
-		if m.aiProvider != nil && autoAI {
-			m.actionInProgress = true
-			return m, loadAIDescriptionCmd(m.aiProvider, msg.resource, msg.details)
-		}
+	history := azcli.History()
+	for i := len(history) - 1; i >= max(0, len(history)-maxSessionCommands); i-- {
+		entry := history[i]
+		popup.entries = append(popup.entries, commandEntry{"Run this session", entry.Time.Format("15:04:05"), azcli.Equivalents(entry.Args, subscriptionID)})
+	}
+	m.commandsPopup = popup
+}
 
-	case aiDescriptionLoadedMsg:
-		m.actionInProgress = false
-		m.aiDescription = msg.description
+// updateCommandsPopup handles keys while the commands popup is open
+func (m model) updateCommandsPopup(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	popup := m.commandsPopup
+	var selected azcli.Equivalent
+	if len(popup.entries) > 0 {
+		selected = popup.entries[popup.index].equivalent
+	}
 
-	case resourceActionMsg:
-		m.actionInProgress = false
-		m.lastActionResult = &msg.result
-		if msg.result.Success && m.selectedResource != nil {
-			return m, loadResourceDetailsCmd(*m.selectedResource)
+	switch msg.String() {
+	case "esc", "q":
+		m.commandsPopup = nil
+	case "j", "down":
+		popup.move(1)
+	case "k", "up":
+		popup.move(-1)
+	case "enter", "c":
+		if selected.AZ != "" {
+			return m, copyCmd("az command", selected.AZ)
+		}
+	case "p":
+		if selected.PowerShell != "" {
+			return m, copyCmd("PowerShell command", selected.PowerShell)
 		}
+	case "r":
+		if selected.REST != "" {
+			return m, copyCmd("REST request", selected.REST)
+		}
+	case "s":
+		return m, saveSessionScriptCmd()
+	}
+	return m, nil
+}
 
-	case networkDashboardMsg:
-		// Final dashboard loaded - stop progress and show result
-		m.actionInProgress = false
-		m.networkLoadingInProgress = false
-		m.networkDashboardContent = msg.content
-		m.pushView("network-dashboard")
-		// Add debug logging
-		m.logEntries = append(m.logEntries, "DEBUG: Network Dashboard loaded successfully")
+// copyCmd copies text to the clipboard
+func copyCmd(what, text string) tea.Cmd {
+	return func() tea.Msg {
+		target, err := clipboard.Copy(text)
+		return clipboardCopiedMsg{what: what, target: target, err: err}
+	}
+}
 
-	case networkLoadingProgressMsg:
-		// Handle network loading progress updates
-		m.networkDashboardContent = network.RenderNetworkLoadingProgress(msg.progress)
-		m.pushView("network-dashboard")
+// saveSessionScriptCmd saves the az commands of the session as a shell
+// script in the scripts folder of the config directory
+func saveSessionScriptCmd() tea.Cmd {
+	return func() tea.Msg {
+		history := azcli.History()
+		if len(history) == 0 {
+			return sessionScriptSavedMsg{err: errors.New("no az commands have run yet")}
+		}
 
-		// If this is the initial progress message (0%), start the async loading
-		if msg.progress.ProgressPercentage == 0.0 && msg.progress.CompletedOperations == 0 {
-			m.networkLoadingInProgress = true
-			m.networkLoadingStartTime = time.Now()
-			return m, loadNetworkDashboardWithProgressCmd()
+		dir := filepath.Join(os.Getenv("HOME"), ".config", "azure-tui", "scripts")
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return sessionScriptSavedMsg{err: err}
 		}
+		path := filepath.Join(dir, "session-"+time.Now().Format("20060102-150405")+".sh")
+		if err := os.WriteFile(path, []byte(azcli.Script(history)), 0755); err != nil {
+			return sessionScriptSavedMsg{err: err}
+		}
+		return sessionScriptSavedMsg{path: path, commands: len(history)}
+	}
+}
 
-	case progressTickMsg:
-		// Handle progress animation ticks during network loading
-		if m.networkLoadingInProgress {
-			// Create simulated progress update
-			elapsed := time.Since(m.networkLoadingStartTime).Seconds()
-			estimatedTotal := 15.0 // Estimated total time in seconds
+// renderCommandsPopup draws the commands popup, scrolled to keep the
+// selected command in view
+func (m model) renderCommandsPopup() string {
+	popup := m.commandsPopup
+	width := max(40, min(110, m.width-8))
+	textWidth := width - 4 - 14 // Padding and the label column
 
-			// Calculate realistic progress based on elapsed time
-			simulatedProgress := (elapsed / estimatedTotal) * 95.0 // Cap at 95% until real completion
-			if simulatedProgress > 95.0 {
-				simulatedProgress = 95.0
+	labelStyle := lipgloss.NewStyle().Foreground(colorGray).Width(12)
+	sectionStyle := lipgloss.NewStyle().Bold(true).Foreground(colorGreen)
+	faint := lipgloss.NewStyle().Foreground(colorGray).Italic(true)
+
+	var lines []string
+	selectedStart, selectedEnd := 0, 0
+	section := ""
+	for i, entry := range popup.entries {
+		if entry.section != section {
+			section = entry.section
+			if len(lines) > 0 {
+				lines = append(lines, "")
+			}
+			lines = append(lines, sectionStyle.Render(section))
+		}
+
+		titleStyle := lipgloss.NewStyle().Foreground(fgMedium)
+		marker := "  "
+		if i == popup.index {
+			titleStyle = titleStyle.Foreground(colorYellow).Bold(true)
+			marker = focusMarker("▶")
+			selectedStart = len(lines)
+		}
+		lines = append(lines, titleStyle.Render(marker+entry.title))
+		for _, row := range []struct{ label, text string }{
+			{"az", entry.equivalent.AZ},
+			{"PowerShell", entry.equivalent.PowerShell},
+			{"REST", entry.equivalent.REST},
+		} {
+			text := row.text
+			style := lipgloss.NewStyle().Foreground(fgLight)
+			if text == "" {
+				text, style = "no direct equivalent", faint
+			}
+			for j, line := range strings.Split(ansi.Wrap(text, textWidth, "/?&"), "\n") {
+				label := ""
+				if j == 0 {
+					label = row.label
+				}
+				lines = append(lines, "  "+labelStyle.Render(label)+style.Render(line))
 			}
+		}
+		if i == popup.index {
+			selectedEnd = len(lines)
+		}
+	}
+	if len(popup.entries) == 0 {
+		lines = append(lines, faint.Render("Select a resource or load a view to see the commands behind it"))
+	}
 
-			simulatedCompletedOps := int(simulatedProgress / 100.0 * 7.0)
+	// Scroll just far enough to show all of the selected command
+	visible := max(8, m.height-10)
+	start := 0
+	if selectedEnd > visible {
+		start = selectedEnd - visible
+	}
+	start = min(start, selectedStart)
+	end := min(len(lines), start+visible)
 
-			progress := network.NetworkLoadingProgress{
-				CurrentOperation:       fmt.Sprintf("Loading network resources... (%.1fs elapsed)", elapsed),
-				TotalOperations:        7,
-				CompletedOperations:    simulatedCompletedOps,
-				ProgressPercentage:     simulatedProgress,
-				ResourceProgress:       make(map[string]network.ResourceProgress),
-				Errors:                 []string{},
-				StartTime:              m.networkLoadingStartTime,
-				EstimatedTimeRemaining: fmt.Sprintf("%.1fs remaining", estimatedTotal-elapsed),
-			}
+	var content strings.Builder
+	content.WriteString(lipgloss.NewStyle().Bold(true).Foreground(colorBlue).Render("🧾 Equivalent Commands"))
+	content.WriteString("\n\n")
+	content.WriteString(strings.Join(lines[start:end], "\n"))
+	content.WriteString("\n\n")
+	footer := "j/k:Select  Enter/c:Copy az  p:Copy PowerShell  r:Copy REST  s:Save session script  Esc:Close"
+	content.WriteString(faint.Render(footer))
 
-			// Add resource-specific progress simulation
-			resourceTypes := []string{"VirtualNetworks", "NetworkSecurityGroups", "RouteTables", "PublicIPs", "NetworkInterfaces", "LoadBalancers", "Firewalls"}
-			for i, resType := range resourceTypes {
-				var status string
-				if i < simulatedCompletedOps {
-					status = "completed"
-				} else if i == simulatedCompletedOps {
-					status = "loading"
-				} else {
-					status = "pending"
-				}
+	styledPopup := lipgloss.NewStyle().
+		Foreground(fgLight).
+		Padding(1, 2).
+		Width(width).
+		Align(lipgloss.Left, lipgloss.Top).
+		Render(theme.Text(content.String()))
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, styledPopup)
+}
 
-				progress.ResourceProgress[resType] = network.ResourceProgress{
-					ResourceType: resType,
-					Status:       status,
-					StartTime:    m.networkLoadingStartTime.Add(time.Duration(i) * time.Second * 2),
-					Count:        0,
-				}
-			}
+// =============================================================================
+// RAW JSON EXPLORER
+// =============================================================================
 
-			m.networkDashboardContent = network.RenderNetworkLoadingProgress(progress)
+// openJSONExplorer shows the full az resource show payload of the selected
+// resource as a collapsible tree
+func (m *model) openJSONExplorer() {
+	details := m.resourceDetails
+	if m.selectedResource == nil || details == nil || !strings.EqualFold(details.ID, m.selectedResource.ID) {
+		m.addToast("The resource details are still loading", "notice")
+		return
+	}
 
-			// Continue ticking if still in progress
+	explorer, err := newJSONExplorer(details)
+	if err != nil {
+		m.addToast("Cannot show the raw JSON: "+err.Error(), "failure")
+		return
+	}
+	m.jsonExplorer = explorer
+	m.jsonInputMode, m.jsonInput = "", ""
+	m.selectedPanel = 1
+	m.rightPanelScrollOffset = 0
+	m.pushView("raw-json")
+}
+
+// newJSONExplorer returns an explorer of the raw JSON of details
+func newJSONExplorer(details *resourcedetails.ResourceDetails) (*jsonview.Explorer, error) {
+	data := []byte(details.Raw)
+	if len(data) == 0 {
+		// Details that did not come from az resource show
+		var err error
+		if data, err = json.Marshal(details); err != nil {
+			return nil, err
+		}
+	}
+	return jsonview.NewExplorer(data)
+}
+
+// updateJSONExplorer handles keys in the raw JSON view. Keys it does not
+// use are left to the normal key map.
+func (m model) updateJSONExplorer(msg tea.KeyMsg) (tea.Model, tea.Cmd, bool) {
+	explorer := m.jsonExplorer
+
+	// Typing a filter or a search term
+	if m.jsonInputMode != "" {
+		switch msg.Type {
+		case tea.KeyEnter:
+			if m.jsonInputMode == "filter" {
+				if err := explorer.SetFilter(m.jsonInput); err != nil {
+					return m, nil, true // The error is shown under the input
+				}
+			} else {
+				explorer.Search(m.jsonInput)
+			}
+			m.jsonInputMode = ""
+		case tea.KeyEsc:
+			m.jsonInputMode = ""
+		case tea.KeyBackspace:
+			if runes := []rune(m.jsonInput); len(runes) > 0 {
+				m.jsonInput = string(runes[:len(runes)-1])
+			}
+		case tea.KeySpace:
+			m.jsonInput += " "
+		case tea.KeyRunes:
+			m.jsonInput += string(msg.Runes)
+		}
+		return m, nil, true
+	}
+
+	switch msg.String() {
+	case "j", "down":
+		explorer.Move(1)
+	case "k", "up":
+		explorer.Move(-1)
+	case "pgdown", "ctrl+d":
+		explorer.Move(10)
+	case "pgup", "ctrl+u":
+		explorer.Move(-10)
+	case "g", "home":
+		explorer.Home()
+	case "G", "end":
+		explorer.End()
+	case "enter", " ", "space":
+		explorer.Toggle()
+	case "l", "right":
+		explorer.Expand()
+	case "h", "left":
+		explorer.Collapse()
+	case "E":
+		explorer.ExpandAll()
+	case "C":
+		explorer.CollapseAll()
+	case "/":
+		m.jsonInputMode, m.jsonInput = "search", explorer.SearchTerm()
+	case "f", "|":
+		m.jsonInputMode, m.jsonInput = "filter", explorer.Filter()
+		if m.jsonInput == "" {
+			m.jsonInput = "."
+		}
+	case "n":
+		explorer.NextMatch(1)
+	case "N":
+		explorer.NextMatch(-1)
+	case "c", "y":
+		if node := explorer.Selected(); node != nil {
+			return m, copyCmd("value", node.Text()), true
+		}
+	case "p":
+		if path := explorer.Path(explorer.Selected()); path != "" {
+			return m, copyCmd("path", path), true
+		}
+	case "esc":
+		// Clear the filter and the search before leaving the view
+		if explorer.Filter() != "" || explorer.SearchTerm() != "" {
+			_ = explorer.SetFilter("")
+			explorer.Search("")
+			return m, nil, true
+		}
+		return m, nil, false
+	default:
+		return m, nil, false
+	}
+	return m, nil, true
+}
+
+// renderJSONExplorer draws the raw JSON view: the path of the selected
+// node, the filter and search, and the visible part of the tree
+func (m model) renderJSONExplorer(width, height int) string {
+	explorer := m.jsonExplorer
+	textWidth := max(20, width-4)
+
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(colorBlue)
+	labelStyle := lipgloss.NewStyle().Foreground(colorGray)
+	faint := lipgloss.NewStyle().Foreground(colorGray).Italic(true)
+	keyStyle := lipgloss.NewStyle().Foreground(colorBlue)
+	errorStyle := lipgloss.NewStyle().Foreground(colorRed)
+
+	var header []string
+	title := "🧬 Raw JSON"
+	if m.selectedResource != nil {
+		title += ": " + m.selectedResource.Name
+	}
+	header = append(header, headerStyle.Render(title))
+
+	path := explorer.Path(explorer.Selected())
+	if path == "" {
+		path = "(computed by the filter)"
+	}
+	header = append(header, labelStyle.Render("Path:   ")+lipgloss.NewStyle().Foreground(colorYellow).Render(path))
+
+	filter := explorer.Filter()
+	if filter == "" {
+		filter = "none"
+	}
+	search := "none"
+	if term := explorer.SearchTerm(); term != "" {
+		current, total := explorer.Matches()
+		search = fmt.Sprintf("%q (%d/%d)", term, current, total)
+	}
+	header = append(header, labelStyle.Render("Filter: ")+filter+labelStyle.Render("   Search: ")+search)
+
+	switch m.jsonInputMode {
+	case "filter":
+		header = append(header, labelStyle.Render("jq filter> ")+m.jsonInput+"█")
+		if err := explorer.FilterError(); err != nil {
+			header = append(header, errorStyle.Render("❌ "+err.Error()))
+		}
+	case "search":
+		header = append(header, labelStyle.Render("search> ")+m.jsonInput+"█")
+	}
+
+	footer := "j/k:Move  Enter:Toggle  h/l:Collapse/Expand  E/C:All  /:Search  n/N:Match  f:Filter  c:Copy value  p:Copy path  Esc:Back"
+	if m.jsonInputMode != "" {
+		footer = "Enter:Apply  Esc:Cancel"
+	}
+
+	// Only the rows that fit are rendered; tabs take two lines
+	visible := height - 4 - len(header) - 4
+	if m.renderTabBar() != "" {
+		visible -= 2
+	}
+	visible = max(3, visible)
+	rows := explorer.Rows()
+	start := explorer.Window(visible)
+	end := min(len(rows), start+visible)
+
+	var lines []string
+	for i := start; i < end; i++ {
+		row := rows[i]
+		node := row.Node
+
+		marker := "  "
+		if i == explorer.Cursor() {
+			marker = theme.Icon("❯ ", "> ")
+		}
+		expander := "  "
+		if len(node.Children) > 0 {
+			expander = theme.Icon("▶ ", "+ ")
+			if explorer.Expanded(node) {
+				expander = theme.Icon("▼ ", "- ")
+			}
+		}
+
+		var text strings.Builder
+		text.WriteString(strings.Repeat("  ", row.Depth) + expander)
+		if label := node.Label(); label != "" {
+			style := keyStyle
+			if explorer.IsMatch(node) {
+				style = style.Underline(true).Foreground(colorYellow)
+			}
+			text.WriteString(style.Render(label) + ": ")
+		}
+		if len(node.Children) > 0 || node.Kind == jsonview.Object || node.Kind == jsonview.Array {
+			text.WriteString(labelStyle.Render(node.Summary()))
+		} else {
+			style := jsonValueStyle(node.Kind)
+			if explorer.IsMatch(node) {
+				style = style.Underline(true)
+			}
+			text.WriteString(style.Render(node.Value))
+		}
+
+		line := marker + text.String()
+		if i == explorer.Cursor() {
+			line = lipgloss.NewStyle().Bold(true).Render(line)
+		}
+		lines = append(lines, truncateText(line, textWidth))
+	}
+	if len(rows) == 0 {
+		lines = append(lines, faint.Render("The filter has no output"))
+	}
+
+	var content strings.Builder
+	content.WriteString(strings.Join(header, "\n"))
+	content.WriteString("\n\n")
+	content.WriteString(strings.Join(lines, "\n"))
+	content.WriteString("\n\n")
+	content.WriteString(faint.Render(ansi.Wrap(footer, textWidth, "")))
+	return content.String()
+}
+
+// jsonValueStyle colors scalar values by type
+func jsonValueStyle(kind jsonview.Kind) lipgloss.Style {
+	switch kind {
+	case jsonview.String:
+		return lipgloss.NewStyle().Foreground(colorGreen)
+	case jsonview.Number:
+		return lipgloss.NewStyle().Foreground(colorYellow)
+	case jsonview.Bool:
+		return lipgloss.NewStyle().Foreground(colorPurple)
+	}
+	return lipgloss.NewStyle().Foreground(colorGray)
+}
+
+// =============================================================================
+// COPY MENU
+// =============================================================================
+
+// copyMenu lists values of the selected resource and the search results
+// that can be copied to the clipboard
+type copyMenu struct {
+	items []copyItem
+	index int
+}
+
+// copyItem is a value in the copy menu. Values that need a call to Azure,
+// like connection strings, are fetched when they are copied.
+type copyItem struct {
+	section string
+	label   string
+	value   string
+	fetch   func() (string, error)
+}
+
+// Leaf properties listed in the copy menu
+const maxCopyProperties = 100
+
+// move changes the selected item
+func (c *copyMenu) move(delta int) {
+	c.index = max(0, min(c.index+delta, len(c.items)-1))
+}
+
+// portalURL returns the Azure portal link of a resource
+func portalURL(tenantID, resourceID string) string {
+	if tenantID == "" {
+		return "https://portal.azure.com/#resource" + resourceID
+	}
+	return "https://portal.azure.com/#@" + tenantID + "/resource" + resourceID
+}
+
+// openCopyMenu collects what can be copied in the current context
+func (m *model) openCopyMenu() {
+	menu := &copyMenu{}
+	add := func(section, label, value string) {
+		if value != "" {
+			menu.items = append(menu.items, copyItem{section: section, label: label, value: value})
+		}
+	}
+
+	if resource := m.selectedResource; resource != nil {
+		section := "Resource: " + resource.Name
+		tenantID := ""
+		if m.currentSubscription != nil {
+			tenantID = m.currentSubscription.TenantID
+		}
+		add(section, "Resource ID", resource.ID)
+		add(section, "Name", resource.Name)
+		add(section, "Resource group", resource.ResourceGroup)
+		add(section, "Portal URL", portalURL(tenantID, resource.ID))
+		if resource.Type == "Microsoft.Storage/storageAccounts" {
+			name, group := resource.Name, resource.ResourceGroup
+			menu.items = append(menu.items, copyItem{
+				section: section,
+				label:   "Connection string",
+				fetch:   func() (string, error) { return storage.GetConnectionString(name, group) },
+			})
+		}
+
+		if details := m.resourceDetails; details != nil && strings.EqualFold(details.ID, resource.ID) {
+			// Expanded properties are copied whole, as JSON
+			for _, key := range slices.Sorted(maps.Keys(m.expandedProperties)) {
+				if value, ok := details.Properties[key]; ok && m.expandedProperties[key] {
+					add("Expanded properties", key, copyValue(value, "  "))
+				}
+			}
+			properties := flattenProperties("", details.Properties, nil)
+			for _, property := range properties[:min(len(properties), maxCopyProperties)] {
+				add("Properties", property[0], property[1])
+			}
+		}
+	}
+
+	if m.showSearchResults && len(m.searchResults) > 0 {
+		section := fmt.Sprintf("Search results (%d)", len(m.searchResults))
+		if m.searchResultIndex < len(m.searchResults) {
+			add(section, "Selected result ID", m.searchResults[m.searchResultIndex].ResourceID)
+		}
+		var ids, names []string
+		for _, result := range m.searchResults {
+			ids = append(ids, result.ResourceID)
+			names = append(names, result.ResourceName)
+		}
+		add(section, "All resource IDs", strings.Join(ids, "\n"))
+		add(section, "All names", strings.Join(names, "\n"))
+	}
+
+	m.copyMenu = menu
+}
+
+// flattenProperties lists the leaf values of properties as path/value
+// pairs sorted by path, e.g. "ipConfigurations[0].privateIPAddress"
+func flattenProperties(prefix string, value interface{}, pairs [][2]string) [][2]string {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, key := range slices.Sorted(maps.Keys(v)) {
+			path := key
+			if prefix != "" {
+				path = prefix + "." + key
+			}
+			pairs = flattenProperties(path, v[key], pairs)
+		}
+	case []interface{}:
+		for i, item := range v {
+			pairs = flattenProperties(fmt.Sprintf("%s[%d]", prefix, i), item, pairs)
+		}
+	case nil:
+	default:
+		pairs = append(pairs, [2]string{prefix, copyValue(v, "")})
+	}
+	return pairs
+}
+
+// copyValue returns value as it is copied: strings as they are, anything
+// else as JSON
+func copyValue(value interface{}, indent string) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	data, err := json.MarshalIndent(value, "", indent)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+// updateCopyMenu handles keys while the copy menu is open
+func (m model) updateCopyMenu(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	menu := m.copyMenu
+	switch msg.String() {
+	case "esc", "q":
+		m.copyMenu = nil
+	case "j", "down":
+		menu.move(1)
+	case "k", "up":
+		menu.move(-1)
+	case "enter", "c", "y":
+		if len(menu.items) == 0 {
+			return m, nil
+		}
+		item := menu.items[menu.index]
+		m.copyMenu = nil
+		what := strings.ToLower(item.label[:1]) + item.label[1:]
+		if item.fetch != nil {
+			return m, copyFetchedCmd(what, item.fetch)
+		}
+		return m, copyCmd(what, item.value)
+	}
+	return m, nil
+}
+
+// copyFetchedCmd fetches a value and copies it to the clipboard
+func copyFetchedCmd(what string, fetch func() (string, error)) tea.Cmd {
+	return func() tea.Msg {
+		text, err := fetch()
+		if err != nil {
+			return clipboardCopiedMsg{what: what, err: err}
+		}
+		target, err := clipboard.Copy(text)
+		return clipboardCopiedMsg{what: what, target: target, err: err}
+	}
+}
+
+// renderCopyMenu draws the copy menu, scrolled to keep the selected item
+// in view
+func (m model) renderCopyMenu() string {
+	menu := m.copyMenu
+	width := max(40, min(100, m.width-8))
+	labelWidth := 0
+	for _, item := range menu.items {
+		labelWidth = max(labelWidth, ansi.StringWidth(item.label))
+	}
+	labelWidth = min(min(labelWidth, 36), (width-4)/2)
+	valueWidth := width - 4 - 2 - labelWidth - 2
+
+	sectionStyle := lipgloss.NewStyle().Bold(true).Foreground(colorGreen)
+	valueStyle := lipgloss.NewStyle().Foreground(colorGray)
+	faint := lipgloss.NewStyle().Foreground(colorGray).Italic(true)
+
+	var lines []string
+	selectedLine := 0
+	section := ""
+	for i, item := range menu.items {
+		if item.section != section {
+			section = item.section
+			if len(lines) > 0 {
+				lines = append(lines, "")
+			}
+			lines = append(lines, sectionStyle.Render(section))
+		}
+
+		labelStyle := lipgloss.NewStyle().Foreground(fgMedium)
+		marker := "  "
+		if i == menu.index {
+			labelStyle = labelStyle.Foreground(colorYellow).Bold(true)
+			marker = focusMarker("▶")
+			selectedLine = len(lines)
+		}
+		value := item.value
+		if item.fetch != nil {
+			value = "fetched from Azure when copied"
+		}
+		// Multi-line values show their first line
+		if first, _, multiline := strings.Cut(value, "\n"); multiline {
+			value = first + " …"
+		}
+		label := labelStyle.Render(truncateText(marker+item.label, labelWidth+2))
+		lines = append(lines, lipgloss.NewStyle().Width(labelWidth+4).Render(label)+valueStyle.Render(truncateText(value, valueWidth)))
+	}
+	if len(menu.items) == 0 {
+		lines = append(lines, faint.Render("Select a resource or search to get something to copy"))
+	}
+
+	visible := max(8, m.height-10)
+	start := max(0, selectedLine-visible+1)
+	end := min(len(lines), start+visible)
+
+	var content strings.Builder
+	content.WriteString(lipgloss.NewStyle().Bold(true).Foreground(colorBlue).Render("📋 Copy to Clipboard"))
+	content.WriteString("\n\n")
+	content.WriteString(strings.Join(lines[start:end], "\n"))
+	content.WriteString("\n\n")
+	content.WriteString(faint.Render("j/k:Select  Enter/c:Copy  Esc:Close"))
+
+	styledPopup := lipgloss.NewStyle().
+		Foreground(fgLight).
+		Padding(1, 2).
+		Width(width).
+		Align(lipgloss.Left, lipgloss.Top).
+		Render(theme.Text(content.String()))
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, styledPopup)
+}
+
+// =============================================================================
+// BACKGROUND JOBS
+// =============================================================================
+
+// How long a toast stays on screen
+const toastDuration = 5 * time.Second
+
+// jobFollowUp turns a finished job into the message its caller handles,
+// delivered to the tab the job was started from
+type jobFollowUp struct {
+	tabID string
+	done  func(jobs.Job) tea.Msg
+}
+
+// toast is a short notice shown in the bottom right corner
+type toast struct {
+	text  string
+	kind  string // "success", "failure" or "notice"
+	until time.Time
+}
+
+// waitForJobsCmd waits until a job starts, writes output or finishes
+func waitForJobsCmd(manager *jobs.Manager) tea.Cmd {
+	return func() tea.Msg {
+		<-manager.Changed()
+		return jobsChangedMsg{}
+	}
+}
+
+// jobTickCmd updates the elapsed time of running jobs
+func jobTickCmd() tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg { return jobTickMsg{} })
+}
+
+// startJob runs fn as a background job. When it finishes, done turns it
+// into the message the command it replaces used to return, so the job ends
+// the same way. A job isn't started again while it is still running.
+func (m *model) startJob(title string, fn jobs.Func, done func(jobs.Job) tea.Msg) tea.Cmd {
+	for _, job := range m.jobManager.Jobs() {
+		if job.Title == title && !job.Done() {
+			m.addToast(title+" is already running", "notice")
+			return nil
+		}
+	}
+
+	id := m.jobManager.Start(title, fn)
+	m.jobFollowUps[id] = jobFollowUp{tabID: m.activeTabID(), done: done}
+	m.jobIndex = len(m.jobManager.Jobs()) - 1
+	m.logEntries = append(m.logEntries, "Job started: "+title)
+	if m.jobTicking {
+		return nil
+	}
+	m.jobTicking = true
+	return jobTickCmd()
+}
+
+// finishJobs announces the jobs that have finished and returns the
+// follow-ups of the commands that started them
+func (m *model) finishJobs() []tea.Cmd {
+	var cmds []tea.Cmd
+	for _, job := range m.jobManager.TakeFinished() {
+		kind := "failure"
+		if job.Status == jobs.Succeeded {
+			kind = "success"
+		}
+		m.addToast(fmt.Sprintf("%s: %s", job.Title, job.Message), kind)
+		cmds = append(cmds, tea.Tick(toastDuration, func(time.Time) tea.Msg { return toastExpiredMsg{} }))
+
+		followUp, ok := m.jobFollowUps[job.ID]
+		if !ok {
+			continue
+		}
+		delete(m.jobFollowUps, job.ID)
+		msg := followUp.done(job)
+		cmds = append(cmds, tagTabCmd(followUp.tabID, func() tea.Msg { return msg }))
+	}
+	return cmds
+}
+
+// cancelJob cancels the job selected in the jobs view, or else the latest
+// running job
+func (m *model) cancelJob() {
+	jobList := m.jobManager.Jobs()
+	if len(jobList) == 0 {
+		return
+	}
+	if m.layout.ShowBottom && m.bottomView == "jobs" {
+		job := jobList[min(m.jobIndex, len(jobList)-1)]
+		if m.jobManager.Cancel(job.ID) {
+			m.addToast("Cancelling "+job.Title, "notice")
+		}
+		return
+	}
+	for i := len(jobList) - 1; i >= 0; i-- {
+		if m.jobManager.Cancel(jobList[i].ID) {
+			m.addToast("Cancelling "+jobList[i].Title, "notice")
+			return
+		}
+	}
+}
+
+// selectJob moves the selection of the jobs view
+func (m *model) selectJob(delta int) {
+	count := len(m.jobManager.Jobs())
+	m.jobIndex = max(0, min(m.jobIndex+delta, count-1))
+	m.bottomScrollOffset = 0
+}
+
+// addToast shows text in the corner for a few seconds
+func (m *model) addToast(text, kind string) {
+	m.toasts = append(m.toasts, toast{text: text, kind: kind, until: time.Now().Add(toastDuration)})
+}
+
+// startResourceActionJob starts, stops or restarts a resource as a
+// background job
+func (m *model) startResourceActionJob(action string, resource AzureResource) tea.Cmd {
+	var result resourceactions.ActionResult
+	run := func(ctx context.Context, out io.Writer) (string, error) {
+		result = runResourceLifecycleAction(ctx, out, action, resource)
+		if !result.Success {
+			return "", errors.New(result.Message)
+		}
+		return result.Message, nil
+	}
+	done := func(job jobs.Job) tea.Msg {
+		if job.Status == jobs.Cancelled {
+			result = resourceactions.ActionResult{Success: false, Message: fmt.Sprintf("%s of %s cancelled", strings.ToUpper(action[:1])+action[1:], resource.Name)}
+		}
+		return resourceActionMsg{action: action, resource: resource, result: result}
+	}
+	return m.startJob(fmt.Sprintf("%s %s", strings.ToUpper(action[:1])+action[1:], resource.Name), run, done)
+}
+
+// jobListRows is the number of rows the job list takes in a jobs view of
+// the given content height. The rows below it show the selected job.
+func (m model) jobListRows(lines int) int {
+	return max(1, min(len(m.jobManager.Jobs()), lines/2))
+}
+
+// renderJobList draws the job list of the jobs view, scrolled to keep the
+// selected job in view
+func (m model) renderJobList(width, rows int) []string {
+	jobList := m.jobManager.Jobs()
+	faint := lipgloss.NewStyle().Foreground(colorGray).Italic(true)
+	if len(jobList) == 0 {
+		return []string{faint.Render("No jobs yet")}
+	}
+
+	icons := map[jobs.Status]string{jobs.Running: "⏳", jobs.Succeeded: "✅", jobs.Failed: "❌", jobs.Cancelled: "⏹"}
+	selected := min(m.jobIndex, len(jobList)-1)
+	start := max(0, min(selected-rows+1, len(jobList)-rows))
+	now := m.jobManager.Now()
+	var lines []string
+	for i := start; i < min(start+rows, len(jobList)); i++ {
+		job := jobList[i]
+		marker := "  "
+		style := lipgloss.NewStyle().Foreground(fgMedium)
+		if i == selected {
+			marker = focusMarker("▶")
+			style = style.Foreground(fgLight).Bold(true)
+		}
+		elapsed := job.Elapsed(now).Round(time.Second).String()
+		line := fmt.Sprintf("%s%s %s  %s  %s", marker, icons[job.Status], job.Title, job.Status, elapsed)
+		lines = append(lines, style.Render(truncateText(line, width)))
+	}
+	return append(lines, faint.Render(truncateText("Output of "+jobList[selected].Title, width)))
+}
+
+// overlayToasts draws the toasts over the bottom right corner of view,
+// newest at the bottom
+func (m model) overlayToasts(view string) string {
+	if len(m.toasts) == 0 {
+		return view
+	}
+	lines := strings.Split(view, "\n")
+	toasts := m.toasts[max(0, len(m.toasts)-3):]
+	width := max(20, min(60, m.width/2))
+	for i, t := range toasts {
+		row := len(lines) - len(toasts) + i
+		if row < 0 {
+			continue
+		}
+		icon, color := "ℹ️", colorAqua
+		switch t.kind {
+		case "success":
+			icon, color = "✅", colorGreen
+		case "failure":
+			icon, color = "❌", colorRed
+		}
+		text := truncateText(icon+" "+t.text, width-2)
+		rendered := lipgloss.NewStyle().Foreground(color).Background(bgLight).Padding(0, 1).Render(text)
+		left := ansi.Truncate(lines[row], max(0, m.width-lipgloss.Width(rendered)), "")
+		lines[row] = left + strings.Repeat(" ", max(0, m.width-lipgloss.Width(rendered)-lipgloss.Width(left))) + rendered
+	}
+	return strings.Join(lines, "\n")
+}
+
+// =============================================================================
+// TABS
+// =============================================================================
+
+// tabMsg routes the response to a command back to the tab that issued it
+type tabMsg struct {
+	tabID string
+	msg   tea.Msg
+}
+
+type openTabsSavedMsg struct{ err error }
+
+// isTabScoped reports whether msg carries results for the right panel. Those
+// belong to the tab that asked for them, not to whichever tab is active when
+// they arrive.
+func isTabScoped(msg tea.Msg) bool {
+	switch msg.(type) {
+	case resourceDetailsLoadedMsg, aiDescriptionLoadedMsg, resourceActionMsg, errorMsg,
+		networkDashboardMsg, networkLoadingProgressMsg, networkLoadingProgressWithContinuationMsg, progressTickMsg,
+		networkTopologyMsg, networkTopologyLoadingProgressMsg, networkTopologyLoadingProgressWithContinuationMsg,
+		vnetDetailsMsg, nsgDetailsMsg, networkAIAnalysisMsg,
+		containerInstanceDetailsMsg, containerInstanceLogsMsg, containerInstanceActionMsg, containerInstanceScaleMsg,
+		keyVaultSecretsMsg, keyVaultSecretDetailsMsg, keyVaultSecretActionMsg,
+		storageLoadingStartMsg, storageLoadingProgressMsg, storageLoadingCompleteMsg,
+		storageContainersMsg, storageBlobsMsg, storageBlobDetailsMsg, storageActionMsg,
+		metricDefinitionsMsg, metricsLoadedMsg, metricsRefreshMsg, usageMetricsLoadedMsg, quotasLoadedMsg,
+		alertsLoadedMsg, alertStateChangedMsg, kqlWorkspacesMsg, kqlResultMsg,
+		healthHistoryMsg, serviceEventsMsg, diagnosticsAuditMsg, diagnosticsWorkspacesMsg, diagnosticsPreviewMsg,
+		diagnosticsAppliedMsg:
+		return true
+	}
+	return false
+}
+
+// tagTabCmd wraps cmd so that its tab scoped responses are delivered to tabID
+func tagTabCmd(tabID string, cmd tea.Cmd) tea.Cmd {
+	if cmd == nil || tabID == "" {
+		return cmd
+	}
+	return func() tea.Msg {
+		msg := cmd()
+		if batch, ok := msg.(tea.BatchMsg); ok {
+			tagged := make(tea.BatchMsg, len(batch))
+			for i, c := range batch {
+				tagged[i] = tagTabCmd(tabID, c)
+			}
+			return tagged
+		}
+		if isTabScoped(msg) {
+			return tabMsg{tabID: tabID, msg: msg}
+		}
+		return msg
+	}
+}
+
+// updateTab applies a routed response to its tab. A tab in the background is
+// swapped in while the response is handled, and responses for tabs that have
+// been closed in the meantime are dropped.
+func (m model) updateTab(routed tabMsg) (tea.Model, tea.Cmd) {
+	state, exists := m.tabStates[routed.tabID]
+	if !exists {
+		return m, nil
+	}
+	if routed.tabID == m.activeTabID() {
+		updated, cmd := m.update(routed.msg)
+		return updated, tagTabCmd(routed.tabID, cmd)
+	}
+
+	active := m.panelState
+	m.panelState = *state
+	updated, cmd := m.update(routed.msg)
+	if um, ok := updated.(model); ok {
+		*state = um.panelState
+		um.panelState = active
+		updated = um
+	}
+	return updated, tagTabCmd(routed.tabID, cmd)
+}
+
+// restoreTabs creates the overview tab and the tabs that were open in the
+// previous session. Restored tabs are loaded when first shown.
+func (m *model) restoreTabs() {
+	m.tabManager = tui.NewTabManager()
+	m.tabStates = make(map[string]*panelState)
+	m.addTab(tui.Tab{Title: "Overview", Type: "main"}, m.panelState)
+
+	for _, saved := range config.GetOpenTabs() {
+		state := newPanelState()
+		state.needsReload = true
+		m.addTab(tui.Tab{
+			Title:    saved.Title,
+			Type:     saved.Type,
+			Meta:     map[string]string{"view": saved.View, "resourceID": saved.ResourceID},
+			Closable: true,
+		}, state)
+	}
+
+	// Start on the overview
+	m.saveActiveTab()
+	m.tabManager.ActiveIndex = 0
+	m.panelState = *m.tabStates[m.activeTabID()]
+}
+
+// activeTabID returns the id of the tab shown in the right panel
+func (m *model) activeTabID() string {
+	if m.tabManager == nil {
+		return ""
+	}
+	if tab := m.tabManager.ActiveTab(); tab != nil {
+		return tab.Meta["id"]
+	}
+	return ""
+}
+
+// addTab adds a tab showing state and makes it the active one
+func (m *model) addTab(tab tui.Tab, state panelState) {
+	m.saveActiveTab()
+	m.nextTabID++
+	id := fmt.Sprintf("tab-%d", m.nextTabID)
+	if tab.Meta == nil {
+		tab.Meta = map[string]string{}
+	}
+	tab.Meta["id"] = id
+	m.tabStates[id] = &state
+	m.tabManager.AddTab(tab)
+	m.panelState = state
+}
+
+// saveActiveTab stores the live panel state in the active tab
+func (m *model) saveActiveTab() {
+	if state, exists := m.tabStates[m.activeTabID()]; exists {
+		*state = m.panelState
+	}
+}
+
+// openTab opens a new tab of kind ("resource", "monitor", "logs" or "shell")
+// for view, optionally about resource
+func (m *model) openTab(kind, title, view string, resource *AzureResource) tea.Cmd {
+	state := newPanelState()
+	meta := map[string]string{"view": view}
+	if resource != nil {
+		selected := *resource
+		state.selectedResource = &selected
+		meta["resourceID"] = selected.ID
+	}
+	m.addTab(tui.Tab{Title: title, Type: kind, Meta: meta, Closable: true}, state)
+	return saveOpenTabsCmd(m.tabManager.Tabs)
+}
+
+// switchTab activates the tab delta positions away
+func (m *model) switchTab(delta int) tea.Cmd {
+	if m.tabManager == nil || len(m.tabManager.Tabs) < 2 {
+		return nil
+	}
+	m.saveActiveTab()
+	m.tabManager.SwitchTab(delta)
+	return m.showActiveTab()
+}
+
+// activateOverviewTab switches back to the overview tab, which follows the
+// selection in the tree
+func (m *model) activateOverviewTab() {
+	if m.tabManager == nil || m.tabManager.ActiveIndex == 0 {
+		return
+	}
+	m.saveActiveTab()
+	m.tabManager.ActiveIndex = 0
+	m.showActiveTab()
+}
+
+// selectTab makes the tab at index the active one
+func (m *model) selectTab(index int) tea.Cmd {
+	if m.tabManager == nil || index == m.tabManager.ActiveIndex || index < 0 || index >= len(m.tabManager.Tabs) {
+		return nil
+	}
+	m.saveActiveTab()
+	m.tabManager.ActiveIndex = index
+	return m.showActiveTab()
+}
+
+// closeActiveTab closes the active tab. The overview tab can't be closed.
+func (m *model) closeActiveTab() tea.Cmd {
+	tab := m.tabManager.ActiveTab()
+	if tab == nil || !tab.Closable {
+		return nil
+	}
+	delete(m.tabStates, tab.Meta["id"])
+	m.tabManager.CloseTab(m.tabManager.ActiveIndex)
+	return tea.Batch(m.showActiveTab(), saveOpenTabsCmd(m.tabManager.Tabs))
+}
+
+// showActiveTab loads the active tab's state into the panel, fetching the
+// content of a tab restored from the previous session the first time
+func (m *model) showActiveTab() tea.Cmd {
+	state, exists := m.tabStates[m.activeTabID()]
+	if !exists {
+		return nil
+	}
+	m.panelState = *state
+	if !m.needsReload {
+		return nil
+	}
+	m.needsReload = false
+	return m.reloadTabCmd(*m.tabManager.ActiveTab())
+}
+
+// reloadTabCmd fetches the content of a restored tab
+func (m *model) reloadTabCmd(tab tui.Tab) tea.Cmd {
+	var resource *AzureResource
+	if id := tab.Meta["resourceID"]; id != "" {
+		found := resourceFromID(id)
+		for _, r := range m.allResources {
+			if r.ID == id {
+				found = r
+				break
+			}
+		}
+		resource = &found
+		m.selectedResource = resource
+	}
+
+	switch tab.Meta["view"] {
+	case "network-dashboard":
+		m.actionInProgress = true
+		return showNetworkDashboardCmd()
+	case "network-topology":
+		m.actionInProgress = true
+		return showNetworkTopologyCmd()
+	case "network-ai":
+		m.actionInProgress = true
+		return showNetworkAIAnalysisCmd()
+	case "container-logs":
+		if resource != nil {
+			m.actionInProgress = true
+			return getContainerLogsCmd(resource.Name, resource.ResourceGroup, "", 100)
+		}
+	}
+
+	if resource != nil {
+		return loadResourceDetailsCmd(*resource)
+	}
+	return nil
+}
+
+// tabViewContent returns the content of the view a dashboard or log tab was
+// opened for, or "" for tabs that show resource details
+func (m model) tabViewContent() string {
+	if m.tabManager == nil {
+		return ""
+	}
+	tab := m.tabManager.ActiveTab()
+	if tab == nil {
+		return ""
+	}
+
+	var content string
+	switch tab.Meta["view"] {
+	case "network-dashboard":
+		content = m.networkDashboardContent
+	case "network-topology":
+		content = m.networkTopologyContent
+	case "network-ai":
+		content = m.networkAIContent
+	case "container-logs":
+		content = m.containerInstanceLogsContent
+	default:
+		return ""
+	}
+
+	if content == "" {
+		return fmt.Sprintf("⏳ Loading %s...", tab.Title)
+	}
+	return content
+}
+
+// resourceViewContent returns the content of the view opened from the
+// selected resource, e.g. its storage containers, or "" for its details
+func (m model) resourceViewContent() string {
+	switch m.activeView {
+	case "vnet-details":
+		return m.vnetDetailsContent
+	case "nsg-details":
+		return m.nsgDetailsContent
+	case "container-details":
+		return m.containerInstanceDetailsContent
+	case "keyvault-secrets":
+		if m.selectedResource != nil {
+			return keyvault.RenderKeyVaultSecretsView(m.selectedResource.Name, m.keyVaultSecrets)
+		}
+		return m.keyVaultSecretsContent
+	case "keyvault-secret-details":
+		return m.keyVaultSecretDetailsContent
+	case "storage-containers":
+		return m.storageContainersContent
+	case "storage-blobs":
+		return m.storageBlobsContent
+	case "storage-blob-details":
+		return m.storageBlobDetailsContent
+	}
+	return ""
+}
+
+// renderTabBar renders the open tabs, marking the ones still loading
+func (m model) renderTabBar() string {
+	if m.tabManager == nil || len(m.tabManager.Tabs) < 2 {
+		return ""
+	}
+	return tui.RenderTabsWithActive(m.displayedTabs(), m.tabManager.ActiveIndex)
+}
+
+// displayedTabs returns the tabs as shown in the tab bar, marking the ones
+// that are loading
+func (m model) displayedTabs() []tui.Tab {
+	tabs := make([]tui.Tab, len(m.tabManager.Tabs))
+	for i, tab := range m.tabManager.Tabs {
+		loading := m.actionInProgress
+		if i != m.tabManager.ActiveIndex {
+			if state, exists := m.tabStates[tab.Meta["id"]]; exists {
+				loading = state.actionInProgress
+			}
+		}
+		if loading {
+			tab.Title += " ⏳"
+		}
+		tabs[i] = tab
+	}
+	return tabs
+}
+
+// saveOpenTabsCmd persists the open tabs, leaving out the overview
+func saveOpenTabsCmd(tabs []tui.Tab) tea.Cmd {
+	openTabs := []config.OpenTab{}
+	for _, tab := range tabs {
+		if !tab.Closable {
+			continue
+		}
+		openTabs = append(openTabs, config.OpenTab{
+			Type:       tab.Type,
+			Title:      tab.Title,
+			View:       tab.Meta["view"],
+			ResourceID: tab.Meta["resourceID"],
+		})
+	}
+	return func() tea.Msg {
+		return openTabsSavedMsg{err: config.SaveOpenTabs(openTabs)}
+	}
+}
+
+// resourceFromID rebuilds the basic fields of a resource from its Azure ID
+func resourceFromID(id string) AzureResource {
+	resource := AzureResource{ID: id}
+	parts := strings.Split(strings.Trim(id, "/"), "/")
+	for i := 0; i+1 < len(parts); i++ {
+		switch strings.ToLower(parts[i]) {
+		case "resourcegroups":
+			resource.ResourceGroup = parts[i+1]
+		case "providers":
+			rest := parts[i+1:]
+			resource.Type = rest[0]
+			for j := 1; j+1 < len(rest); j += 2 {
+				resource.Type += "/" + rest[j]
+				resource.Name = rest[j+1]
+			}
+			return resource
+		}
+	}
+	return resource
+}
+
+// =============================================================================
+// COMMAND PALETTE
+// =============================================================================
+
+// resourcePaletteActions are the keymap actions offered in the command
+// palette per resource type, on top of those from GetResourceActions
+var resourcePaletteActions = map[string][]string{
+	"Microsoft.ContainerInstance/containerGroups": {
+		keymap.ActionStart, keymap.ActionStop, keymap.ActionRestart, keymap.ActionContainerLogs,
+		keymap.ActionContainerExec, keymap.ActionContainerScale, keymap.ActionContainerDetails,
+	},
+	"Microsoft.KeyVault/vaults": {keymap.ActionListSecrets, keymap.ActionCreate, keymap.ActionDeleteSecret},
+	"Microsoft.Storage/storageAccounts": {
+		keymap.ActionListContainers, keymap.ActionCreateContainer, keymap.ActionListBlobs,
+		keymap.ActionUploadBlob, keymap.ActionDeleteItem,
+	},
+	"Microsoft.Network/virtualNetworks":       {keymap.ActionVNetDetails},
+	"Microsoft.Network/networkSecurityGroups": {keymap.ActionNSGDetails},
+}
+
+// resourceActionKeymap maps actions from resourceactions.GetResourceActions
+// to the keymap actions that run them
+var resourceActionKeymap = map[string]string{
+	"start":       keymap.ActionStart,
+	"stop":        keymap.ActionStop,
+	"restart":     keymap.ActionRestart,
+	"ssh":         keymap.ActionSSH,
+	"bastion":     keymap.ActionBastion,
+	"pods":        keymap.ActionAKSPods,
+	"deployments": keymap.ActionAKSDeployments,
+	"services":    keymap.ActionAKSServices,
+	"nodes":       keymap.ActionAKSNodes,
+}
+
+// paletteResourceAction returns the palette item ID for an action from
+// GetResourceActions. Actions that need parameters, or that nothing
+// implements yet, aren't offered.
+func paletteResourceAction(name, resourceType string) (string, bool) {
+	if action, ok := resourceActionKeymap[name]; ok {
+		return action, true
+	}
+	switch {
+	case name == "create" && resourceType == "Microsoft.Network/virtualNetworks":
+		return keymap.ActionCreate, true
+	case name == "create" && resourceType == "Microsoft.Network/networkSecurityGroups":
+		return keymap.ActionCreateNSG, true
+	case resourceType == "Microsoft.ContainerService/managedClusters" && (name == "scale" || name == "connect" || name == "logs"):
+		return "resource:" + name, true
+	}
+	return "", false
+}
+
+// paletteItems lists every action available in the current context: actions
+// for the selected resource, views, Terraform, DevOps and settings
+func (m model) paletteItems() []tui.PaletteItem {
+	var items []tui.PaletteItem
+	seen := map[string]bool{}
+	add := func(id, title, category string) {
+		if seen[id] {
+			return
+		}
+		seen[id] = true
+		items = append(items, tui.PaletteItem{ID: id, Title: title, Category: category, Key: m.keymap.KeyHint(id)})
+	}
+	addAction := func(id, category string) {
+		add(id, m.keymap.Description(id), category)
+	}
+
+	if resource := m.selectedResource; resource != nil {
+		category := "Resource (" + resource.Name + ")"
+		var ids []string
+		for _, name := range resourceactions.GetResourceActions(resource.Type) {
+			if id, ok := paletteResourceAction(name, resource.Type); ok {
+				ids = append(ids, id)
+			}
+		}
+		ids = append(ids, resourcePaletteActions[resource.Type]...)
+		for _, id := range ids {
+			switch {
+			case strings.HasPrefix(id, "resource:"):
+				name := strings.TrimPrefix(id, "resource:")
+				add(id, strings.ToUpper(name[:1])+name[1:], category)
+			case id == keymap.ActionCreate && resource.Type == "Microsoft.KeyVault/vaults":
+				add(id, "Create Secret", category)
+			case id == keymap.ActionCreate:
+				add(id, "Create VNet", category)
+			default:
+				addAction(id, category)
+			}
+		}
+		if m.aiProvider != nil {
+			add(keymap.ActionAnalyze, "AI analysis", category)
+		}
+		addAction(keymap.ActionMetrics, category)
+		addAction(keymap.ActionAlerts, category)
+		addAction(keymap.ActionHealth, category)
+		addAction(keymap.ActionDiagnostics, category)
+		addAction(keymap.ActionKQL, category)
+		addAction(keymap.ActionRawJSON, category)
+		addAction(keymap.ActionOpenTab, category)
+	}
+
+	if m.selectedPanel == 0 && m.treeView != nil {
+		if node := m.treeView.GetSelectedNode(); node != nil {
+			if bookmark, ok := m.bookmarkFor(node); ok {
+				title := "Bookmark " + bookmark.Name
+				if m.isBookmarked(bookmark.ResourceID) {
+					title = "Remove bookmark " + bookmark.Name
+				}
+				add(keymap.ActionToggleBookmark, title, "View")
+			}
+		}
+	}
+	for _, id := range []string{
+		keymap.ActionSearch, keymap.ActionRefresh, keymap.ActionJumpBookmark,
+		keymap.ActionNetworkDashboard, keymap.ActionNetworkTopology, keymap.ActionNetworkAI,
+	} {
+		addAction(id, "View")
+	}
+	addAction(keymap.ActionJobs, "Jobs")
+	if running := m.jobManager.Running(); running > 0 {
+		addAction(keymap.ActionCancelJob, "Jobs")
+	}
+	if len(m.jobManager.Jobs()) > m.jobManager.Running() {
+		add("jobs:clear", "Clear finished jobs", "Jobs")
+	}
+	if m.tabManager != nil && len(m.tabManager.Tabs) > 1 {
+		for _, id := range []string{keymap.ActionNextTab, keymap.ActionPrevTab, keymap.ActionCloseTab} {
+			addAction(id, "View")
+		}
+	}
+	for _, id := range []string{
+		keymap.ActionToggleBottom, keymap.ActionBottomView, keymap.ActionZoom,
+		keymap.ActionGrowTree, keymap.ActionShrinkTree, keymap.ActionGrowBottom, keymap.ActionShrinkBottom,
+		keymap.ActionResetLayout,
+	} {
+		addAction(id, "Layout")
+	}
+	for _, id := range []string{keymap.ActionCreateNSG, keymap.ActionCreateSubnet, keymap.ActionCreatePublicIP, keymap.ActionCreateLoadBalancer} {
+		addAction(id, "Network")
+	}
+	if m.selectedResource == nil || m.selectedResource.Type != "Microsoft.KeyVault/vaults" {
+		add(keymap.ActionCreate, "Create VNet", "Network")
+	}
+
+	addAction(keymap.ActionTerraformMenu, "Terraform")
+	for i, option := range m.terraformMenuOptions {
+		add(fmt.Sprintf("terraform:%d", i), option, "Terraform")
+	}
+	addAction(keymap.ActionDevOpsMenu, "DevOps")
+	for i, option := range m.devopsMenuOptions {
+		add(fmt.Sprintf("devops:%d", i), option, "DevOps")
+	}
+	addAction(keymap.ActionSettingsMenu, "Settings")
+	add("settings:view", "View Configuration", "Settings")
+	add("settings:terraform-dir", "Edit Terraform Directory", "Settings")
+	add("settings:theme", "Change Theme", "Settings")
+	addAction(keymap.ActionSubscriptionMenu, "Settings")
+	addAction(keymap.ActionQuotas, "Settings")
+
+	addAction(keymap.ActionCommands, "Interface")
+	addAction(keymap.ActionCopy, "Interface")
+	add("commands:script", "Save session commands as a shell script", "Interface")
+	addAction(keymap.ActionHelp, "Interface")
+	addAction(keymap.ActionQuit, "Interface")
+	return items
+}
+
+// updateCommandPalette handles keys while the command palette is open
+func (m model) updateCommandPalette(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	palette := m.commandPalette
+	switch m.keymap.Action(keymap.ScopeSearch, msg.String()) {
+	case keymap.ActionSearchExit:
+		m.commandPalette = nil
+		return m, nil
+	case keymap.ActionSearchNext:
+		palette.Move(1)
+		return m, nil
+	case keymap.ActionSearchPrev:
+		palette.Move(-1)
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "enter":
+		item, ok := palette.SelectedItem()
+		m.commandPalette = nil
+		if ok {
+			return m.runPaletteItem(item.ID)
+		}
+	case "backspace":
+		if len(palette.Query) > 0 {
+			palette.SetQuery(palette.Query[:len(palette.Query)-1])
+		}
+	default:
+		if len(msg.String()) == 1 && msg.String() >= " " && msg.String() <= "~" {
+			palette.SetQuery(palette.Query + msg.String())
+		}
+	}
+	return m, nil
+}
+
+// runPaletteItem runs the command palette item with the given ID. Terraform,
+// DevOps and settings items open their popup at that menu option.
+func (m model) runPaletteItem(id string) (tea.Model, tea.Cmd) {
+	kind, value, _ := strings.Cut(id, ":")
+	switch kind {
+	case "terraform":
+		index, err := strconv.Atoi(value)
+		if err != nil {
+			return m, nil
+		}
+		m.terraformMenuIndex = index
+		m.showTerraformPopup = true
+		m.terraformMode = "menu"
+		return m.handleTerraformMenuSelection()
+	case "devops":
+		index, err := strconv.Atoi(value)
+		if err != nil {
+			return m, nil
+		}
+		m.devopsMenuIndex = index
+		m.showDevOpsPopup = true
+		m.devopsMode = "menu"
+		return m.handleDevOpsMenuSelection()
+	case "settings":
+		m.showSettingsPopup = true
+		m.settingsMode = "menu"
+		m.settingsMenuIndex = 0
+		switch value {
+		case "terraform-dir":
+			m.settingsMenuIndex = 1
+			return m.handleSettingsMenuSelection()
+		case "theme":
+			m.settingsMenuIndex = 4
+			return m.handleSettingsMenuSelection()
+		}
+		// The configuration is shown once it has loaded
+		return m, loadSettingsConfigCmd()
+	case "resource":
+		if m.selectedResource != nil && slices.Contains(jobResourceActions, value) {
+			cmd := m.startResourceActionJob(value, *m.selectedResource)
+			return m, cmd
+		}
+		if m.selectedResource != nil && !m.actionInProgress {
+			m.actionInProgress = true
+			return m, executeResourceActionCmd(value, *m.selectedResource)
+		}
+		return m, nil
+	case "commands":
+		if value == "script" {
+			return m, saveSessionScriptCmd()
+		}
+		return m, nil
+	case "jobs":
+		if value == "clear" {
+			m.jobManager.ClearFinished()
+			m.jobIndex = 0
+		}
+		return m, nil
+	}
+	return m.runAction(id)
+}
+
+// Lines scrolled by one step of the mouse wheel over a panel
+const wheelScrollLines = 3
+
+// Views of the bottom panel, in the order they are switched through
+var bottomPanelViews = []string{"logs", "output", "jobs", "ai"}
+
+var bottomPanelTitles = map[string]string{
+	"logs":   "📜 Logs",
+	"output": "⚡ Action Output",
+	"jobs":   "⏳ Jobs",
+	"ai":     "🤖 AI Chat",
+}
+
+// panelRects splits the screen between the panels. Heights count from the
+// top of the screen, like the height of the window.
+func (m model) panelRects() (tree, details, bottom tui.Rect) {
+	return m.layout.Split(m.width, m.height, tui.Panel(m.selectedPanel))
+}
+
+// mainPanelTop is the screen row the panels start at, below the status bar
+// and the search input
+func (m model) mainPanelTop() int {
+	top := 1
+	if m.statusBar != nil {
+		top = lipgloss.Height(m.statusBar.RenderStatusBar())
+	}
+	if m.searchMode {
+		top += lipgloss.Height(m.renderSearchInput(m.width))
+	}
+	return top
+}
+
+// scrollPanel scrolls the tree (0), the details panel (1) or the bottom
+// panel (2) by delta lines
+func (m *model) scrollPanel(panel, delta int) {
+	tree, details, bottom := m.panelRects()
+	switch panel {
+	case 0:
+		if m.treeView == nil || tree.Empty() {
+			return
+		}
+		treeContent := m.treeView.RenderTreeView(tree.Width-4, tree.Height-2)
+		maxLines := max(0, strings.Count(treeContent, "\n")-(tree.Height-6))
+		m.leftPanelScrollOffset = max(0, min(m.leftPanelScrollOffset+delta, maxLines))
+	case 1:
+		if details.Empty() {
+			return
+		}
+		if m.activeView == "raw-json" && m.jsonExplorer != nil {
+			m.jsonExplorer.Move(delta)
+			return
+		}
+		rightContent := m.renderResourcePanel(details.Width-4, details.Height-2)
+		maxLines := max(0, strings.Count(rightContent, "\n")-(details.Height-6))
+		m.rightPanelScrollOffset = max(0, min(m.rightPanelScrollOffset+delta, maxLines))
+	case 2:
+		if bottom.Empty() {
+			return
+		}
+		// The bottom panel follows new lines, so its offset counts from the end
+		maxLines := max(0, len(m.bottomPanelLines(bottom.Width))-m.bottomContentHeight(bottom.Height))
+		m.bottomScrollOffset = max(0, min(m.bottomScrollOffset-delta, maxLines))
+	}
+}
+
+// popupOpen reports whether a popup covers the panels
+func (m model) popupOpen() bool {
+	return m.commandPalette != nil || m.bookmarkPicker != nil || m.commandsPopup != nil || m.copyMenu != nil || m.showHelpPopup || m.showTerraformPopup ||
+		m.showSettingsPopup || m.showSubscriptionPopup || m.showDevOpsPopup
+}
+
+// treeNodeAt returns the tree node drawn on screen row y
+func (m model) treeNodeAt(y int) *tui.TreeNode {
+	tree, _, _ := m.panelRects()
+	if m.treeView == nil || tree.Empty() {
+		return nil
+	}
+	treeContent := m.treeView.RenderTreeView(tree.Width-4, tree.Height-2)
+	totalLines := strings.Count(treeContent, "\n") + 1
+	maxHeight := tree.Height - 6
+
+	// Undo the panel padding and scrolling applied when rendering
+	row := y - m.mainPanelTop() - 1
+	start := max(0, min(m.leftPanelScrollOffset, totalLines-1))
+	if totalLines > maxHeight && start > 0 {
+		row-- // "More above" indicator
+	}
+	if row < 0 || row >= maxHeight {
+		return nil
+	}
+	return m.treeView.NodeAtLine(start + row)
+}
+
+// tabAt returns the tab drawn at the screen position and whether the
+// position is on its close button
+func (m model) tabAt(x, y int) (int, bool) {
+	_, details, _ := m.panelRects()
+	if details.Empty() || m.renderTabBar() == "" || y != m.mainPanelTop()+1 {
+		return -1, false
+	}
+	x -= details.X + 2
+	if m.selectedPanel == 1 {
+		x -= lipgloss.Width(focusMarker("📊"))
+	}
+	return tui.TabAt(m.displayedTabs(), m.tabManager.ActiveIndex, x)
+}
+
+// updateMouse handles clicks, the wheel and dragging the panel divider
+func (m model) updateMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	wheel := 0
+	switch msg.Button {
+	case tea.MouseButtonWheelDown:
+		wheel = 1
+	case tea.MouseButtonWheelUp:
+		wheel = -1
+	}
+
+	// Popups only scroll, clicks outside of them are ignored
+	if m.popupOpen() {
+		if wheel == 0 || msg.Action != tea.MouseActionPress {
+			return m, nil
+		}
+		return m.scrollPopup(wheel)
+	}
+
+	if m.draggingDivider {
+		switch msg.Action {
+		case tea.MouseActionMotion:
+			m.layout.SetTreeWidth(msg.X, m.width)
+		case tea.MouseActionRelease:
+			m.draggingDivider = false
+			return m, saveLayoutCmd(m.layoutConfig())
+		}
+		return m, nil
+	}
+
+	if msg.Action != tea.MouseActionPress {
+		return m, nil
+	}
+
+	tree, details, bottom := m.panelRects()
+	panel := -1
+	switch {
+	case msg.Y < m.mainPanelTop():
+	case bottom.Contains(msg.X, msg.Y):
+		panel = 2
+	case tree.Contains(msg.X, msg.Y):
+		panel = 0
+	case details.Contains(msg.X, msg.Y):
+		panel = 1
+	}
+
+	if wheel != 0 {
+		if panel == 0 && m.treeView != nil {
+			m.treeView.Scroll(wheel * wheelScrollLines)
+		} else if panel > 0 {
+			m.scrollPanel(panel, wheel*wheelScrollLines)
+		}
+		return m, nil
+	}
+	if msg.Button != tea.MouseButtonLeft {
+		return m, nil
+	}
+
+	switch {
+	case msg.Y < m.mainPanelTop():
+		// Status bar segments run their action
+		if m.statusBar != nil && msg.Y == 0 {
+			if segment, ok := m.statusBar.SegmentAt(msg.X); ok && segment.Action != "" {
+				return m.runAction(segment.Action)
+			}
+		}
+	case !tree.Empty() && !details.Empty() && panel != 2 && (msg.X == details.X-1 || msg.X == details.X):
+		// The padding between the panels is the divider
+		m.draggingDivider = true
+	case panel == 0:
+		m.selectedPanel = 0
+		if node := m.treeNodeAt(msg.Y); node != nil {
+			m.treeView.Select(node)
+			return m.runAction(keymap.ActionSelect)
+		}
+	case panel == 1:
+		m.selectedPanel = 1
+		if index, closeClicked := m.tabAt(msg.X, msg.Y); index >= 0 {
+			cmd := m.selectTab(index)
+			if closeClicked {
+				return m, tea.Batch(cmd, m.closeActiveTab())
+			}
+			return m, cmd
+		}
+	case panel == 2:
+		m.selectedPanel = 2
+		// The header lists the views, a click switches to the one under it
+		// A zoomed bottom panel starts below the status bar
+		if msg.Y == max(bottom.Y, m.mainPanelTop())+1 {
+			x := 1 // Panel padding
+			for _, view := range bottomPanelViews {
+				width := lipgloss.Width(theme.Text(bottomPanelTitles[view])) + 2
+				if msg.X >= x && msg.X < x+width {
+					m.bottomView = view
+					m.bottomScrollOffset = 0
+					return m, saveLayoutCmd(m.layoutConfig())
+				}
+				x += width
+			}
+		}
+	}
+	return m, nil
+}
+
+// scrollPopup moves through the open popup with the mouse wheel
+func (m model) scrollPopup(delta int) (tea.Model, tea.Cmd) {
+	switch {
+	case m.commandPalette != nil:
+		m.commandPalette.Move(delta)
+		return m, nil
+	case m.bookmarkPicker != nil:
+		m.bookmarkPicker.Move(delta)
+		return m, nil
+	case m.commandsPopup != nil:
+		m.commandsPopup.move(delta)
+	case m.copyMenu != nil:
+		m.copyMenu.move(delta)
+		return m, nil
+	case m.showHelpPopup:
+		m.helpScrollOffset = max(0, m.helpScrollOffset+delta)
+		return m, nil
+	}
+
+	// The other popups move their selection with the arrow keys
+	key := tea.KeyMsg{Type: tea.KeyDown}
+	if delta < 0 {
+		key = tea.KeyMsg{Type: tea.KeyUp}
+	}
+	return m.update(key)
+}
+
+// loadLayout restores the layout saved in the config file
+func (m *model) loadLayout() {
+	saved := config.GetUIConfig().Layout
+	m.layout = tui.NewLayout()
+	if saved.TreeRatio > 0 {
+		m.layout.TreeRatio = saved.TreeRatio
+	}
+	if saved.BottomHeight > 0 {
+		m.layout.BottomHeight = saved.BottomHeight
+	}
+	m.layout.ShowBottom = saved.BottomPanel
+	m.bottomView = bottomPanelViews[0]
+	if slices.Contains(bottomPanelViews, saved.BottomView) {
+		m.bottomView = saved.BottomView
+	}
+}
+
+// layoutConfig is the layout as saved in the config file. Zooming is left
+// out, it only lasts for the session.
+func (m model) layoutConfig() config.LayoutConfig {
+	return config.LayoutConfig{
+		TreeRatio:    m.layout.TreeRatio,
+		BottomPanel:  m.layout.ShowBottom,
+		BottomHeight: m.layout.BottomHeight,
+		BottomView:   m.bottomView,
+	}
+}
+
+// saveLayoutCmd persists the layout
+func saveLayoutCmd(layout config.LayoutConfig) tea.Cmd {
+	return func() tea.Msg {
+		return layoutSavedMsg{err: config.SaveLayout(layout)}
+	}
+}
+
+// runLayoutAction resizes, shows or zooms panels
+func (m model) runLayoutAction(action string) (tea.Model, tea.Cmd) {
+	// Columns and rows changed by one resize step
+	const resizeStep = 4
+
+	switch action {
+	case keymap.ActionGrowTree:
+		m.layout.SetTreeWidth(m.layout.TreeWidth(m.width)+resizeStep, m.width)
+	case keymap.ActionShrinkTree:
+		m.layout.SetTreeWidth(m.layout.TreeWidth(m.width)-resizeStep, m.width)
+	case keymap.ActionToggleBottom:
+		m.layout.ShowBottom = !m.layout.ShowBottom
+		if m.layout.ShowBottom {
+			m.selectedPanel = 2
+		} else if m.selectedPanel == 2 {
+			m.selectedPanel = 0
+		}
+	case keymap.ActionBottomView:
+		index := slices.Index(bottomPanelViews, m.bottomView)
+		m.bottomView = bottomPanelViews[(index+1)%len(bottomPanelViews)]
+		m.bottomScrollOffset = 0
+		m.layout.ShowBottom = true
+	case keymap.ActionGrowBottom:
+		m.layout.ShowBottom = true
+		m.layout.ResizeBottom(resizeStep/2, m.height)
+	case keymap.ActionShrinkBottom:
+		m.layout.ResizeBottom(-resizeStep/2, m.height)
+	case keymap.ActionZoom:
+		// Zooming isn't saved
+		m.layout.Zoomed = !m.layout.Zoomed
+		return m, nil
+	case keymap.ActionResetLayout:
+		m.layout = tui.NewLayout()
+		if m.selectedPanel == 2 {
+			m.selectedPanel = 0
+		}
+	}
+	return m, saveLayoutCmd(m.layoutConfig())
+}
+
+// bottomContentHeight is the number of content lines of a bottom panel of
+// the given height, below the separator and the header
+func (m model) bottomContentHeight(height int) int {
+	lines := height - 2
+	switch m.bottomView {
+	case "ai":
+		lines-- // Input line
+	case "jobs":
+		lines -= m.jobListRows(lines) + 1 // Job list and the output heading
+	}
+	return max(1, lines)
+}
+
+// bottomPanelLines returns the lines of the bottom panel's current view,
+// wrapped to its width
+func (m model) bottomPanelLines(width int) []string {
+	textWidth := max(10, width-2)
+	wrap := func(text string, style lipgloss.Style) []string {
+		var lines []string
+		for _, line := range strings.Split(strings.TrimRight(wrapText(theme.Text(text), textWidth), "\n"), "\n") {
+			lines = append(lines, style.Render(line))
+		}
+		return lines
+	}
+	plain := lipgloss.NewStyle().Foreground(fgMedium)
+	faint := lipgloss.NewStyle().Foreground(colorGray).Italic(true)
+
+	var lines []string
+	switch m.bottomView {
+	case "logs":
+		for _, entry := range m.logEntries {
+			style := plain
+			if strings.Contains(entry, "Error") {
+				style = lipgloss.NewStyle().Foreground(colorRed)
+			}
+			lines = append(lines, wrap(entry, style)...)
+		}
+		if len(lines) == 0 {
+			lines = wrap("No log entries yet", faint)
+		}
+	case "output":
+		for _, entry := range m.actionOutput {
+			lines = append(lines, wrap(entry, plain)...)
+		}
+		if len(lines) == 0 {
+			lines = wrap("Output of resource actions (start, stop, secrets, containers...) is collected here", faint)
+		}
+	case "jobs":
+		jobList := m.jobManager.Jobs()
+		if len(jobList) == 0 {
+			return wrap("Long operations (start, stop, uploads, Terraform) run here in the background", faint)
+		}
+		job := jobList[min(m.jobIndex, len(jobList)-1)]
+		for _, line := range job.Output {
+			lines = append(lines, wrap(line, plain)...)
+		}
+		switch job.Status {
+		case jobs.Running:
+			if len(lines) == 0 {
+				lines = wrap("Waiting for output...", faint)
+			}
+		case jobs.Succeeded:
+			lines = append(lines, wrap(job.Message, lipgloss.NewStyle().Foreground(colorGreen))...)
+		default:
+			lines = append(lines, wrap(job.Message, lipgloss.NewStyle().Foreground(colorRed))...)
+		}
+	case "ai":
+		if m.aiProvider == nil {
+			return wrap("AI is not configured. Set OPENAI_API_KEY or sign in to GitHub Copilot to chat about your resources.", faint)
+		}
+		questionStyle := lipgloss.NewStyle().Foreground(colorBlue).Bold(true)
+		for _, turn := range m.aiChat {
+			lines = append(lines, wrap("You: "+turn.question, questionStyle)...)
+			switch {
+			case turn.err != nil:
+				lines = append(lines, wrap("AI: "+turn.err.Error(), lipgloss.NewStyle().Foreground(colorRed))...)
+			case turn.answer == "":
+				lines = append(lines, wrap("AI: thinking...", faint)...)
+			default:
+				lines = append(lines, wrap("AI: "+turn.answer, plain)...)
+			}
+			lines = append(lines, "")
+		}
+		if len(lines) == 0 {
+			lines = wrap("Ask about the selected resource, e.g. \"why can't I reach this VM on port 22?\"", faint)
+		}
+	}
+	return lines
+}
+
+// renderBottomPanel draws the logs, the action output or the AI chat below
+// the tree and the details panel
+func (m model) renderBottomPanel(width, height int) string {
+	focused := m.selectedPanel == 2
+	separatorColor := colorGray
+	if focused {
+		separatorColor = colorBlue
+	}
+
+	var header []string
+	for _, view := range bottomPanelViews {
+		title := theme.Text(bottomPanelTitles[view])
+		style := lipgloss.NewStyle().Foreground(colorGray).Padding(0, 1)
+		if view == m.bottomView {
+			style = style.Foreground(fgLight).Background(bgMedium).Bold(true)
+			if theme.Plain() {
+				// Brackets instead of the padding mark the view without color
+				style = style.UnsetPadding()
+				title = "[" + title + "]"
+			}
+		}
+		header = append(header, style.Render(title))
+	}
+	if hint := m.shortcutHint(keymap.ActionBottomView, "Switch"); hint != "" {
+		header = append(header, lipgloss.NewStyle().Foreground(colorGray).Render("  "+hint))
+	}
+
+	// Show the end of the content, scrolled back by bottomScrollOffset
+	lines := m.bottomPanelLines(width)
+	visible := m.bottomContentHeight(height)
+	end := max(0, len(lines)-m.bottomScrollOffset)
+	start := max(0, end-visible)
+	content := lines[start:end]
+	for len(content) < visible {
+		content = append(content, "")
+	}
+
+	rows := []string{
+		lipgloss.NewStyle().Foreground(separatorColor).Render(strings.Repeat("─", max(0, width-2))),
+		strings.Join(header, ""),
+	}
+	if m.bottomView == "jobs" {
+		rows = append(rows, m.renderJobList(width-2, m.jobListRows(height-2))...)
+	}
+	rows = append(rows, content...)
+	if m.bottomView == "ai" && m.aiProvider != nil {
+		if focused {
+			rows = append(rows, lipgloss.NewStyle().Foreground(colorYellow).Render("> "+m.aiChatInput+"█"))
+		} else {
+			rows = append(rows, lipgloss.NewStyle().Foreground(colorGray).Italic(true).Render("> Focus this panel to ask a question"))
+		}
+	}
+
+	return lipgloss.NewStyle().
+		Width(width).
+		MaxWidth(width).
+		Height(height).
+		MaxHeight(height).
+		Padding(0, 1).
+		Render(strings.Join(rows, "\n"))
+}
+
+// updateAIChatInput handles typing a question in the AI chat. Keys that
+// aren't text fall through to the normal bindings.
+func (m model) updateAIChatInput(msg tea.KeyMsg) (tea.Model, tea.Cmd, bool) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		question := strings.TrimSpace(m.aiChatInput)
+		if question == "" || m.aiChatWaiting {
+			return m, nil, true
+		}
+		context := m.aiChatContext()
+		m.aiChat = append(m.aiChat, aiChatTurn{question: question})
+		m.aiChatInput = ""
+		m.aiChatWaiting = true
+		m.bottomScrollOffset = 0
+		return m, askAIChatCmd(m.aiProvider, question, context), true
+	case tea.KeyBackspace:
+		if runes := []rune(m.aiChatInput); len(runes) > 0 {
+			m.aiChatInput = string(runes[:len(runes)-1])
+		}
+		return m, nil, true
+	case tea.KeyEsc:
+		m.selectedPanel = 0
+		return m, nil, true
+	case tea.KeyRunes, tea.KeySpace:
+		if msg.Type == tea.KeySpace {
+			m.aiChatInput += " "
+		} else {
+			m.aiChatInput += string(msg.Runes)
+		}
+		return m, nil, true
+	}
+	return m, nil, false
+}
+
+// aiChatContext describes the selected resource and the recent conversation
+// for the AI
+func (m model) aiChatContext() string {
+	var b strings.Builder
+	b.WriteString("You are helping a user of a terminal UI for Azure. Answer briefly.\n")
+	if r := m.selectedResource; r != nil {
+		b.WriteString(fmt.Sprintf("Selected resource: %s (%s) in resource group %s, location %s", r.Name, r.Type, r.ResourceGroup, r.Location))
+		if r.Status != "" {
+			b.WriteString(", status " + r.Status)
+		}
+		b.WriteString("\n")
+		if len(r.Properties) > 0 {
+			if properties, err := json.Marshal(r.Properties); err == nil {
+				b.WriteString("Properties: " + truncateRunes(string(properties), 4000) + "\n")
+			}
+		}
+	}
+	// The last turns give follow-up questions their context
+	for _, turn := range m.aiChat[max(0, len(m.aiChat)-3):] {
+		if turn.answer != "" {
+			b.WriteString(fmt.Sprintf("Earlier question: %s\nEarlier answer: %s\n", turn.question, truncateRunes(turn.answer, 1000)))
+		}
+	}
+	return b.String()
+}
+
+// truncateText converts text for plain mode and cuts it to width cells, so
+// that labels and the ellipsis are measured the way they are shown
+func truncateText(text string, width int) string {
+	return ansi.Truncate(theme.Text(text), width, theme.Text("…"))
+}
+
+// padText converts text for plain mode and pads it to width cells, so that
+// the columns after it line up whatever its icons turn into
+func padText(text string, width int) string {
+	text = theme.Text(text)
+	return text + strings.Repeat(" ", max(0, width-lipgloss.Width(text)))
+}
+
+// truncateRunes shortens text to at most n runes
+func truncateRunes(text string, n int) string {
+	if runes := []rune(text); len(runes) > n {
+		return string(runes[:n]) + "…"
+	}
+	return text
+}
+
+// askAIChatCmd sends a chat question to the AI
+func askAIChatCmd(ai *openai.AIProvider, question, context string) tea.Cmd {
+	return func() tea.Msg {
+		answer, err := ai.Ask(question, context)
+		return aiChatAnsweredMsg{question: question, answer: strings.TrimSpace(answer), err: err}
+	}
+}
+
+// recordActionResult shows the result of a resource action and adds it to
+// the action output of the bottom panel
+func (m *model) recordActionResult(result resourceactions.ActionResult) {
+	m.lastActionResult = &result
+
+	status := "✅"
+	if !result.Success {
+		status = "❌"
+	}
+	entry := fmt.Sprintf("[%s] %s %s", time.Now().Format("15:04:05"), status, result.Message)
+	if m.selectedResource != nil {
+		entry = fmt.Sprintf("[%s] %s %s: %s", time.Now().Format("15:04:05"), status, m.selectedResource.Name, result.Message)
+	}
+	if output := strings.TrimSpace(result.Output); output != "" {
+		entry += "\n" + output
+	}
+	m.actionOutput = append(m.actionOutput, entry)
+}
+
+func initModel() model {
+	// Initialize AI provider with auto-detection (GitHub Copilot or OpenAI)
+	ai := openai.NewAIProviderAuto()
+
+	m := model{
+		panelState:            newPanelState(),
+		jobManager:            jobs.NewManager(),
+		jobFollowUps:          make(map[int]jobFollowUp),
+		treeView:              tui.NewTreeView(),
+		statusBar:             tui.CreatePowerlineStatusBar(80),
+		aiProvider:            ai,
+		loadingState:          "loading",
+		selectedPanel:         0,
+		leftPanelScrollOffset: 0, // Initialize left panel scroll offset
+		rightPanelMaxLines:    50,
+		logEntries:            []string{},
+		showHelpPopup:         false,
+		helpScrollOffset:      0,
+		// Initialize search functionality
+		searchEngine:      search.NewSearchEngine(),
+		searchMode:        false,
+		searchQuery:       "",
+		searchResults:     []search.SearchResult{},
+		searchResultIndex: 0,
+		searchSuggestions: []string{},
+		showSearchResults: false,
+		searchHistory:     []string{},
+		filteredResources: []AzureResource{},
+		savedSearches:     config.GetSavedSearches(),
+		bookmarks:         config.GetBookmarks(),
+		bookmarkLoads:     make(map[string]*bookmarkLoad),
+		// Initialize Terraform functionality
+		showTerraformPopup:  false,
+		terraformMenuIndex:  0,
+		terraformMode:       "menu",
+		terraformFolderPath: "",
+		terraformMenuOptions: []string{
+			"Browse Folders",
+			"Create from Template",
+			"Analyze Code",
+			"Terraform Operations",
+			"Open External Editor",
+		},
+		terraformFolders:      []string{},
+		terraformAnalysis:     "",
+		terraformMenuAction:   "",
+		terraformScrollOffset: 0,
+		// Initialize DevOps functionality
+		showDevOpsPopup: false,
+		devopsMenuIndex: 0,
+		devopsMode:      "menu",
+		devopsMenuOptions: []string{
+			"Browse Organizations",
+			"List Projects",
+			"View Pipelines",
+			"Pipeline Operations",
+			"DevOps Analytics",
+		},
+		devopsOrganizations: []devops.Organization{},
+		devopsPipelines:     []devops.Pipeline{},
+		devopsScrollOffset:  0,
+		devopsResult:        "",
+		devopsManager:       nil,
+		// Initialize Settings functionality
+		showSettingsPopup:     false,
+		settingsMenuIndex:     0,
+		settingsMode:          "menu",
+		settingsCurrentPath:   "",
+		settingsFolders:       []string{},
+		settingsConfigContent: "",
+		settingsEditKey:       "",
+		settingsEditValue:     "",
+		settingsCurrentConfig: nil,
+		// Initialize Subscription selection functionality
+		currentSubscription:    nil,
+		showSubscriptionPopup:  false,
+		subscriptionMenuIndex:  0,
+		availableSubscriptions: []Subscription{},
+		subscriptionMenuMode:   "menu",
+	}
+	m.loadTheme()
+	m.loadKeymap()
+	m.loadLayout()
+	m.restoreTabs()
+
+	return m
+}
+
+// loadTheme applies plain mode and the configured color scheme, falling back to the
+// default theme when it can't be loaded
+func (m *model) loadTheme() {
+	theme.SetPlain(config.GetUIConfig().PlainMode || theme.PlainFromEnv())
+
+	t, err := theme.Load(config.GetUIConfig().ColorScheme)
+	if err != nil {
+		m.logEntries = append(m.logEntries, "Theme Error: "+err.Error())
+		t, _ = theme.Builtin(theme.Default)
+	}
+	applyTheme(t)
+}
+
+// loadKeymap applies the configured keymap preset and bindings. Conflicts
+// and invalid entries are shown in the help popup, the keymap stays usable
+// either way.
+func (m *model) loadKeymap() {
+	keymapConfig := config.GetKeymapConfig()
+	km, conflicts, err := keymap.Load(keymapConfig.Preset, keymapConfig.Bindings)
+	for _, conflict := range conflicts {
+		m.keymapWarnings = append(m.keymapWarnings, conflict.String())
+	}
+	if err != nil {
+		m.keymapWarnings = append(m.keymapWarnings, err.Error())
+	}
+	for _, warning := range m.keymapWarnings {
+		m.logEntries = append(m.logEntries, "Keymap: "+warning)
+	}
+	m.keymap = km
+}
+
+func (m model) Init() tea.Cmd {
+	return tea.Batch(
+		loadDataCmd(),
+		getCurrentSubscriptionCmd(),
+		waitForJobsCmd(m.jobManager),
+	)
+}
+
+// Update routes responses to the tab that asked for them and tags new
+// commands with the tab they were issued from
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if routed, ok := msg.(tabMsg); ok {
+		return m.updateTab(routed)
+	}
+
+	updated, cmd := m.update(msg)
+	if um, ok := updated.(model); ok {
+		return um, tagTabCmd(um.activeTabID(), cmd)
+	}
+	return updated, cmd
+}
+
+func (m model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height, m.ready = msg.Width, msg.Height, true
+		if m.statusBar != nil {
+			m.statusBar.Width = msg.Width
+		}
+
+	case subscriptionsLoadedMsg:
+		m.subscriptions = msg.subscriptions
+
+	case resourceGroupsLoadedMsg:
+		m.resourceGroups = msg.groups
+		m.loadingState = "ready"
+		if m.treeView != nil {
+			for _, group := range msg.groups {
+				groupNode := m.treeView.AddResourceGroup(group.Name, group.Location)
+				m.treeView.AddResource(groupNode, "Loading...", "placeholder", nil)
+			}
+			m.refreshSmartFolders()
+			m.refreshFavorites()
+			m.treeView.EnsureSelection()
+		}
+
+	case resourcesInGroupMsg:
+		if m.treeView != nil {
+			for _, groupNode := range m.treeView.Root.Children {
+				if groupNode.Type == "group" && groupNode.Name == msg.groupName {
+					m.treeView.ClearChildren(groupNode)
+					for _, resource := range msg.resources {
+						m.treeView.AddResource(groupNode, resource.Name, resource.Type, resource)
+					}
+					m.applyHealthBadges()
+					break
+				}
+			}
+		}
+		m.allResources = append(m.allResources, msg.resources...)
+		// Update search engine with new resources
+		m.updateSearchEngine(msg.resources)
+
+	case resourceDetailsLoadedMsg:
+		m.selectedResource = &msg.resource
+		m.resourceDetails = msg.details
+		m.searchEngine.RecordAccess(msg.resource.ID)
+		if m.activeView == "raw-json" && msg.details != nil {
+			// The raw JSON view follows the selected resource
+			if explorer, err := newJSONExplorer(msg.details); err == nil {
+				m.jsonExplorer = explorer
+				m.jsonInputMode, m.jsonInput = "", ""
+			}
+		}
+		// AI analysis is now manual-only by default - users must press 'a' to trigger
+		// Auto-analysis can be enabled by setting AZURE_TUI_AUTO_AI="true"
+		autoAI := os.Getenv("AZURE_TUI_AUTO_AI") == "true" // Default to false - manual trigger only
+		if m.aiProvider != nil && autoAI {
+			m.actionInProgress = true
+			return m, loadAIDescriptionCmd(m.aiProvider, msg.resource, msg.details)
+		}
+
+	case quotasLoadedMsg:
+		return m.updateQuotas(msg)
+	case alertsLoadedMsg, alertStateChangedMsg, alertCountMsg, alertCountTickMsg:
+		return m.updateAlerts(msg)
+	case kqlWorkspacesMsg, kqlResultMsg, kqlSavedMsg, kqlExportedMsg:
+		return m.updateKQL(msg)
+	case healthHistoryMsg, serviceEventsMsg, healthStatusesMsg, healthTickMsg:
+		return m.updateHealth(msg)
+	case diagnosticsAuditMsg, diagnosticsWorkspacesMsg, diagnosticsPreviewMsg, diagnosticsSavedMsg, diagnosticsAppliedMsg:
+		return m.updateDiagnostics(msg)
+	case metricDefinitionsMsg, metricsLoadedMsg, metricsRefreshMsg, usageMetricsLoadedMsg:
+		return m.updateMetrics(msg)
+
+	case aiDescriptionLoadedMsg:
+		m.actionInProgress = false
+		m.aiDescription = msg.description
+
+	case jobsChangedMsg:
+		// Output and status changes are picked up by the next render
+		cmds := m.finishJobs()
+		cmds = append(cmds, waitForJobsCmd(m.jobManager))
+		return m, tea.Batch(cmds...)
+
+	case jobTickMsg:
+		if m.jobManager.Running() == 0 {
+			m.jobTicking = false
+			return m, nil
+		}
+		return m, jobTickCmd()
+
+	case clipboardCopiedMsg:
+		if msg.err != nil {
+			m.addToast(fmt.Sprintf("Copying the %s failed: %v", msg.what, msg.err), "failure")
+		} else {
+			m.addToast(fmt.Sprintf("Copied the %s to the %s", msg.what, msg.target), "success")
+		}
+		return m, tea.Tick(toastDuration, func(time.Time) tea.Msg { return toastExpiredMsg{} })
+
+	case sessionScriptSavedMsg:
+		if msg.err != nil {
+			m.addToast("Saving the session script failed: "+msg.err.Error(), "failure")
+		} else {
+			m.addToast(fmt.Sprintf("Saved %d commands to %s", msg.commands, msg.path), "success")
+			m.logEntries = append(m.logEntries, "Session script saved: "+msg.path)
+		}
+		return m, tea.Tick(toastDuration, func(time.Time) tea.Msg { return toastExpiredMsg{} })
+
+	case toastExpiredMsg:
+		now := time.Now()
+		m.toasts = slices.DeleteFunc(m.toasts, func(t toast) bool { return !now.Before(t.until) })
+
+	case resourceActionMsg:
+		m.actionInProgress = false
+		m.recordActionResult(msg.result)
+		if msg.result.Success && m.selectedResource != nil {
+			return m, loadResourceDetailsCmd(*m.selectedResource)
+		}
+
+	case networkDashboardMsg:
+		// Final dashboard loaded - stop progress and show result
+		m.actionInProgress = false
+		m.networkLoadingInProgress = false
+		m.networkDashboardContent = msg.content
+		m.pushView("network-dashboard")
+		// Add debug logging
+		m.logEntries = append(m.logEntries, "DEBUG: Network Dashboard loaded successfully")
+
+	case networkLoadingProgressMsg:
+		// Handle network loading progress updates
+		m.networkDashboardContent = network.RenderNetworkLoadingProgress(msg.progress)
+		m.pushView("network-dashboard")
+
+		// If this is the initial progress message (0%), start the async loading
+		if msg.progress.ProgressPercentage == 0.0 && msg.progress.CompletedOperations == 0 {
+			m.networkLoadingInProgress = true
+			m.networkLoadingStartTime = time.Now()
+			return m, loadNetworkDashboardWithProgressCmd()
+		}
+
+	case progressTickMsg:
+		// Handle progress animation ticks during network loading
+		if m.networkLoadingInProgress {
+			// Create simulated progress update
+			elapsed := time.Since(m.networkLoadingStartTime).Seconds()
+			estimatedTotal := 15.0 // Estimated total time in seconds
+
+			// Calculate realistic progress based on elapsed time
+			simulatedProgress := (elapsed / estimatedTotal) * 95.0 // Cap at 95% until real completion
+			if simulatedProgress > 95.0 {
+				simulatedProgress = 95.0
+			}
+
+			simulatedCompletedOps := int(simulatedProgress / 100.0 * 7.0)
+
+			progress := network.NetworkLoadingProgress{
+				CurrentOperation:       fmt.Sprintf("Loading network resources... (%.1fs elapsed)", elapsed),
+				TotalOperations:        7,
+				CompletedOperations:    simulatedCompletedOps,
+				ProgressPercentage:     simulatedProgress,
+				ResourceProgress:       make(map[string]network.ResourceProgress),
+				Errors:                 []string{},
+				StartTime:              m.networkLoadingStartTime,
+				EstimatedTimeRemaining: fmt.Sprintf("%.1fs remaining", estimatedTotal-elapsed),
+			}
+
+			// Add resource-specific progress simulation
+			resourceTypes := []string{"VirtualNetworks", "NetworkSecurityGroups", "RouteTables", "PublicIPs", "NetworkInterfaces", "LoadBalancers", "Firewalls"}
+			for i, resType := range resourceTypes {
+				var status string
+				if i < simulatedCompletedOps {
+					status = "completed"
+				} else if i == simulatedCompletedOps {
+					status = "loading"
+				} else {
+					status = "pending"
+				}
+
+				progress.ResourceProgress[resType] = network.ResourceProgress{
+					ResourceType: resType,
+					Status:       status,
+					StartTime:    m.networkLoadingStartTime.Add(time.Duration(i) * time.Second * 2),
+					Count:        0,
+				}
+			}
+
+			m.networkDashboardContent = network.RenderNetworkLoadingProgress(progress)
+
+			// Continue ticking if still in progress
 			if m.networkLoadingInProgress {
 				return m, tea.Tick(time.Millisecond*500, func(t time.Time) tea.Msg {
 					return progressTickMsg{}
@@ -2201,19 +5244,20 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case containerInstanceActionMsg:
 		m.actionInProgress = false
-		m.lastActionResult = &msg.result
+		m.recordActionResult(msg.result)
 		if msg.result.Success && m.selectedResource != nil {
 			return m, loadResourceDetailsCmd(*m.selectedResource)
 		}
 
 	case containerInstanceScaleMsg:
 		m.actionInProgress = false
-		m.lastActionResult = &msg.result
+		m.recordActionResult(msg.result)
 		if msg.result.Success && m.selectedResource != nil {
 			return m, loadResourceDetailsCmd(*m.selectedResource)
 		}
 
 	case keyVaultSecretsMsg:
+		m.actionInProgress = false
 		m.keyVaultSecrets = msg.secrets
 		m.keyVaultSecretsContent = fmt.Sprintf("Secrets in Vault '%s':\n", msg.vaultName)
 		for _, secret := range msg.secrets {
@@ -2228,7 +5272,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case keyVaultSecretActionMsg:
 		m.actionInProgress = false
-		m.lastActionResult = &msg.result
+		m.recordActionResult(msg.result)
 		if msg.result.Success {
 			return m, listKeyVaultSecretsCmd(m.selectedResource.Name)
 		}
@@ -2316,7 +5360,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case storageActionMsg:
 		m.actionInProgress = false
-		m.lastActionResult = &msg.result
+		m.recordActionResult(msg.result)
 		if msg.result.Success {
 			// Refresh the appropriate view based on the action
 			switch msg.action {
@@ -2379,17 +5423,93 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if !msg.success {
 			status = "Failed"
 		}
-		m.logEntries = append(m.logEntries, fmt.Sprintf("DevOps %s: %s - %s", msg.operation, status, msg.result[:min(50, len(msg.result))]))
+		m.logEntries = append(m.logEntries, fmt.Sprintf("DevOps %s: %s - %s", msg.operation, status, msg.result[:min(50, len(msg.result))]))
+
+	// Settings message handlers
+	case settingsFoldersLoadedMsg:
+		m.settingsFolders = msg.folders
+
+	case settingsConfigLoadedMsg:
+		m.settingsCurrentConfig = msg.config
+		m.settingsConfigContent = msg.content
+		if msg.config != nil {
+			m.settingsMode = "config-view"
+		}
+
+	case searchQueryTranslatedMsg:
+		m.searchTranslating = false
+		// Ignore answers to a question that is no longer being asked
+		if question, _ := naturalLanguageQuestion(m.searchQuery); !m.searchMode || question != msg.question {
+			return m, nil
+		}
+		if msg.err != nil {
+			m.searchAIError = fmt.Sprintf("Could not translate question: %v", msg.err)
+			m.logEntries = append(m.logEntries, "AI Search Error: "+m.searchAIError)
+			return m, nil
+		}
+		m.searchAIQuery = msg.query
+		m.logEntries = append(m.logEntries, fmt.Sprintf("AI Search: %q → %s", msg.question, msg.query))
+
+	case layoutSavedMsg:
+		if msg.err != nil {
+			m.logEntries = append(m.logEntries, fmt.Sprintf("Layout Error: could not save layout: %v", msg.err))
+		}
+
+	case aiChatAnsweredMsg:
+		m.aiChatWaiting = false
+		for i := len(m.aiChat) - 1; i >= 0; i-- {
+			if m.aiChat[i].question == msg.question && m.aiChat[i].answer == "" && m.aiChat[i].err == nil {
+				m.aiChat[i].answer = msg.answer
+				m.aiChat[i].err = msg.err
+				break
+			}
+		}
+		m.bottomScrollOffset = 0
+
+	case openTabsSavedMsg:
+		if msg.err != nil {
+			m.logEntries = append(m.logEntries, fmt.Sprintf("Tabs Error: could not save open tabs: %v", msg.err))
+		}
+
+	case savedSearchesUpdatedMsg:
+		if msg.err != nil {
+			m.logEntries = append(m.logEntries, fmt.Sprintf("Saved Search Error: %v", msg.err))
+		} else {
+			m.savedSearches = msg.searches
+			m.refreshSmartFolders()
+			m.treeView.EnsureSelection()
+			m.logEntries = append(m.logEntries, "Saved Search: "+msg.message)
+		}
+
+	case bookmarksUpdatedMsg:
+		if msg.err != nil {
+			m.logEntries = append(m.logEntries, fmt.Sprintf("Bookmark Error: %v", msg.err))
+			return m, nil
+		}
+		m.bookmarks = msg.bookmarks
+		m.refreshFavorites()
+		m.treeView.EnsureSelection()
+		m.logEntries = append(m.logEntries, "Bookmarks: "+msg.message)
+		if folder := m.treeView.Favorites(); folder != nil && folder.Expanded {
+			cmd := m.loadFavoritesCmd()
+			m.refreshFavorites()
+			return m, cmd
+		}
 
-	// Settings message handlers
-	case settingsFoldersLoadedMsg:
-		m.settingsFolders = msg.folders
+	case bookmarkLoadedMsg:
+		m.bookmarkLoads[strings.ToLower(msg.resourceID)] = &bookmarkLoad{resource: msg.resource, resources: msg.resources, err: msg.err}
+		if msg.err != nil {
+			m.logEntries = append(m.logEntries, fmt.Sprintf("Bookmark Error: %s: %v", msg.resourceID, msg.err))
+		}
+		m.refreshFavorites()
+		m.treeView.EnsureSelection()
 
-	case settingsConfigLoadedMsg:
-		m.settingsCurrentConfig = msg.config
-		m.settingsConfigContent = msg.content
-		if msg.config != nil {
-			m.settingsMode = "config-view"
+		// Open the resource if its bookmark is still selected
+		if selected := m.treeView.GetSelectedNode(); selected != nil && msg.resource != nil {
+			if resource, ok := selected.ResourceData.(AzureResource); ok && strings.EqualFold(resource.ID, msg.resourceID) {
+				m.activateOverviewTab()
+				return m, loadResourceDetailsCmd(resource)
+			}
 		}
 
 	case settingsConfigSavedMsg:
@@ -2402,6 +5522,9 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case currentSubscriptionMsg:
 		m.currentSubscription = msg.subscription
+		if msg.subscription != nil {
+			return m, tea.Batch(m.pollAlertCount(), m.pollHealth())
+		}
 
 	case subscriptionMenuMsg:
 		m.availableSubscriptions = msg.subscriptions
@@ -2412,8 +5535,10 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if msg.success {
 			m.currentSubscription = &msg.subscription
 			m.logEntries = append(m.logEntries, "Subscription: "+msg.message)
-			// Reload resource groups for the new subscription
-			return m, loadDataCmd()
+			// Reload resource groups, the alert count and the health for the
+			// new subscription
+			m.resourceHealth = nil
+			return m, tea.Batch(loadDataCmd(), m.pollAlertCount(), m.pollHealth())
 		} else {
 			m.logEntries = append(m.logEntries, "Subscription Error: "+msg.message)
 		}
@@ -2429,7 +5554,24 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.leftPanelScrollOffset = 0
 		return m, nil
 
+	case tea.MouseMsg:
+		return m.updateMouse(msg)
+
 	case tea.KeyMsg:
+		// The command palette is opened on top of everything else
+		if m.commandPalette != nil {
+			return m.updateCommandPalette(msg)
+		}
+		if m.bookmarkPicker != nil {
+			return m.updateBookmarkPicker(msg)
+		}
+		if m.commandsPopup != nil {
+			return m.updateCommandsPopup(msg)
+		}
+		if m.copyMenu != nil {
+			return m.updateCopyMenu(msg)
+		}
+
 		// Handle popups first (they should take priority over search mode)
 
 		// Handle Terraform popup navigation
@@ -2488,8 +5630,13 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Handle Settings popup navigation
 		if m.showSettingsPopup {
 			switch msg.String() {
-			case "escape":
-				if m.settingsMode == "config-view" || m.settingsMode == "folder-browser" {
+			case "esc", "escape":
+				if m.settingsMode == "theme-select" {
+					// Cancelling the picker undoes the preview
+					applyTheme(m.settingsThemeBefore)
+					m.settingsMode = "menu"
+					m.settingsMenuIndex = 4
+				} else if m.settingsMode == "config-view" || m.settingsMode == "folder-browser" {
 					// Go back to main settings menu
 					m.settingsMode = "menu"
 					m.settingsMenuIndex = 0
@@ -2506,17 +5653,21 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			case "j", "down":
 				if m.settingsMode == "menu" {
-					settingsMenuOptions := []string{"View Config", "Edit Config", "Browse Folders", "Reset to Defaults"}
 					m.settingsMenuIndex = (m.settingsMenuIndex + 1) % len(settingsMenuOptions)
 				} else if m.settingsMode == "folder-browser" {
 					m.settingsMenuIndex = (m.settingsMenuIndex + 1) % len(m.settingsFolders)
+				} else if m.settingsMode == "theme-select" && len(m.settingsThemes) > 0 {
+					m.settingsMenuIndex = (m.settingsMenuIndex + 1) % len(m.settingsThemes)
+					m.previewSettingsTheme()
 				}
 			case "k", "up":
 				if m.settingsMode == "menu" {
-					settingsMenuOptions := []string{"View Config", "Edit Config", "Browse Folders", "Reset to Defaults"}
 					m.settingsMenuIndex = (m.settingsMenuIndex - 1 + len(settingsMenuOptions)) % len(settingsMenuOptions)
 				} else if m.settingsMode == "folder-browser" {
 					m.settingsMenuIndex = (m.settingsMenuIndex - 1 + len(m.settingsFolders)) % len(m.settingsFolders)
+				} else if m.settingsMode == "theme-select" && len(m.settingsThemes) > 0 {
+					m.settingsMenuIndex = (m.settingsMenuIndex - 1 + len(m.settingsThemes)) % len(m.settingsThemes)
+					m.previewSettingsTheme()
 				}
 			}
 			return m, nil
@@ -2600,14 +5751,14 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		// Handle Help popup navigation
 		if m.showHelpPopup {
-			switch msg.String() {
-			case "escape", "?":
+			switch m.keymap.Action(keymap.ScopeNormal, msg.String()) {
+			case keymap.ActionBack, keymap.ActionHelp:
 				m.showHelpPopup = false
 				m.helpScrollOffset = 0 // Reset scroll when closing
-			case "j", "down":
+			case keymap.ActionMoveDown:
 				// Scroll down in help content
 				m.helpScrollOffset += 1
-			case "k", "up":
+			case keymap.ActionMoveUp:
 				// Scroll up in help content (prevent negative scroll)
 				if m.helpScrollOffset > 0 {
 					m.helpScrollOffset -= 1
@@ -2616,472 +5767,696 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 
-		// Handle search mode input after popups
-		if m.searchMode {
+		// Handle naming a saved search
+		if m.searchSaveMode {
 			switch msg.String() {
-			case "escape":
-				m.exitSearchMode()
+			case "esc", "escape":
+				m.searchSaveMode = false
+				m.searchSaveName = ""
 			case "enter":
-				// Execute search and add to history
-				if m.searchQuery != "" {
-					m.addToSearchHistory(m.searchQuery)
-					m.performSearch()
+				name := strings.TrimSpace(m.searchSaveName)
+				m.searchSaveMode = false
+				m.searchSaveName = ""
+				if name != "" {
+					return m, saveSearchCmd(name, m.searchQuery)
 				}
 			case "backspace":
-				// Remove last character from search query
-				if len(m.searchQuery) > 0 {
-					m.searchQuery = m.searchQuery[:len(m.searchQuery)-1]
-					m.performSearch()
-					m.updateSearchSuggestions()
+				if len(m.searchSaveName) > 0 {
+					m.searchSaveName = m.searchSaveName[:len(m.searchSaveName)-1]
+				}
+			default:
+				if len(msg.String()) == 1 && msg.String() >= " " && msg.String() <= "~" {
+					m.searchSaveName += msg.String()
 				}
-			case "tab":
-				// Accept first suggestion if available
-				if len(m.searchSuggestions) > 0 {
-					m.searchQuery = m.searchSuggestions[0]
-					m.performSearch()
+			}
+			return m, nil
+		}
+
+		// Confirm or reject a query translated from a "? ..." question
+		if m.searchMode && m.searchAIQuery != "" {
+			switch msg.String() {
+			case "enter":
+				m.searchQuery = m.searchAIQuery
+				m.searchAIQuery = ""
+				m.addToSearchHistory(m.searchQuery)
+				m.performSearch()
+				m.updateSearchSuggestions()
+			case "esc", "escape":
+				// Back to editing the question
+				m.searchAIQuery = ""
+			}
+			return m, nil
+		}
+
+		// Handle search mode input after popups
+		if m.searchMode {
+			// Facet sidebar navigation; other keys keep editing the query
+			if m.facetFocus {
+				switch m.keymap.Action(keymap.ScopeSearch, msg.String()) {
+				case keymap.ActionSearchPrev:
+					if m.facetIndex > 0 {
+						m.facetIndex--
+					}
+					return m, nil
+				case keymap.ActionSearchNext:
+					if m.facetIndex < len(m.visibleFacets())-1 {
+						m.facetIndex++
+					}
+					return m, nil
+				case keymap.ActionSearchFacets, keymap.ActionSearchExit:
+					m.facetFocus = false
+					return m, nil
+				}
+				if msg.String() == "enter" {
+					m.applyFacet()
+					return m, nil
+				}
+			}
+
+			switch m.keymap.Action(keymap.ScopeSearch, msg.String()) {
+			case keymap.ActionSearchFacets:
+				// Move focus to the facet sidebar
+				if len(m.searchFacets) > 0 {
+					m.facetFocus = true
+					m.facetIndex = 0
 				}
-			case "down", "ctrl+j":
+			case keymap.ActionSearchSave:
+				// Save the current query as a smart folder
+				if strings.TrimSpace(m.searchQuery) != "" {
+					m.searchSaveMode = true
+					m.searchSaveName = ""
+				}
+			case keymap.ActionSearchExit:
+				m.exitSearchMode()
+			case keymap.ActionSearchCopy:
+				m.openCopyMenu()
+			case keymap.ActionSearchNext:
 				// Navigate to next search result
 				if m.showSearchResults {
+					m.activateOverviewTab()
 					m.navigateSearchResults(1)
 					if m.selectedResource != nil {
 						return m, loadResourceDetailsCmd(*m.selectedResource)
 					}
 				}
-			case "up", "ctrl+k":
+			case keymap.ActionSearchPrev:
 				// Navigate to previous search result
 				if m.showSearchResults {
+					m.activateOverviewTab()
 					m.navigateSearchResults(-1)
 					if m.selectedResource != nil {
 						return m, loadResourceDetailsCmd(*m.selectedResource)
 					}
 				}
 			default:
-				// Add character to search query
-				if len(msg.String()) == 1 && msg.String() >= " " && msg.String() <= "~" {
-					m.searchQuery += msg.String()
-					m.performSearch()
-					m.updateSearchSuggestions()
-				}
+				return m.updateSearchInput(msg)
 			}
 			return m, nil
 		}
 
-		// Regular key handling when not in search mode
-		switch msg.String() {
-		case "q", "ctrl+c":
-			return m, tea.Quit
-		case "tab":
-			m.selectedPanel = (m.selectedPanel + 1) % 2
-
-		// Terraform Integration - Primary Access Key
-		case "ctrl+t":
-			if !m.showTerraformPopup {
-				m.showTerraformPopup = true
-				m.terraformMenuIndex = 0
-				return m, loadTerraformFoldersCmd()
-			} else {
-				m.showTerraformPopup = false
+		// Keys of the raw JSON view in the focused details panel
+		if m.selectedPanel == 1 && m.activeView == "raw-json" && m.jsonExplorer != nil {
+			if model, cmd, handled := m.updateJSONExplorer(msg); handled {
+				return model, cmd
 			}
+		}
 
-		// DevOps Integration - Primary Access Key
-		case "ctrl+o":
-			if !m.showDevOpsPopup {
-				m.showDevOpsPopup = true
-				m.devopsMenuIndex = 0
-				m.devopsMode = "menu"
-				return m, loadDevOpsOrganizationsCmd()
-			} else {
-				m.showDevOpsPopup = false
+		// Keys of the metrics view in the focused details panel
+		if m.selectedPanel == 1 && m.activeView == "metrics" && m.metricsView != nil {
+			if model, cmd, handled := m.updateMetricsExplorer(msg); handled {
+				return model, cmd
 			}
+		}
 
-		// Settings Menu - Primary Access Key
-		case "ctrl+,":
-			if !m.showSettingsPopup {
-				m.showSettingsPopup = true
-				m.settingsMode = "menu"
-				m.settingsMenuIndex = 0
-				return m, loadSettingsConfigCmd()
-			} else {
-				m.showSettingsPopup = false
+		// Keys of the quotas view in the focused details panel
+		if m.selectedPanel == 1 && m.activeView == "quotas" && m.quotasView != nil {
+			if model, cmd, handled := m.updateQuotasView(msg); handled {
+				return model, cmd
 			}
+		}
 
-		// Subscription Selection Menu - Primary Access Key
-		case "ctrl+a":
-			if !m.showSubscriptionPopup {
-				m.showSubscriptionPopup = true
-				m.subscriptionMenuMode = "loading"
-				m.subscriptionMenuIndex = 0
-				return m, loadSubscriptionMenuCmd()
-			} else {
-				m.showSubscriptionPopup = false
+		// Keys of the alerts view in the focused details panel
+		if m.selectedPanel == 1 && m.activeView == "alerts" && m.alertsView != nil {
+			if model, cmd, handled := m.updateAlertsView(msg); handled {
+				return model, cmd
 			}
+		}
 
-		case "left", "h":
-			// Left navigation - switch to tree panel or previous section
-			if m.selectedPanel == 1 {
-				m.selectedPanel = 0
-				// Don't reset scroll when switching to maintain position
-			}
-		case "right", "l":
-			// Right navigation - switch to details panel
-			if m.selectedPanel == 0 {
-				m.selectedPanel = 1
-				// Don't reset scroll when switching to maintain position
-			}
-		case "j", "down":
-			if m.selectedPanel == 0 && m.treeView != nil {
-				// Try to navigate first
-				m.treeView.SelectNext()
-				m.treeView.EnsureSelection()
-				if selectedNode := m.treeView.GetSelectedNode(); selectedNode != nil && selectedNode.Type == "resource" {
-					if resource, ok := selectedNode.ResourceData.(AzureResource); ok {
-						return m, loadResourceDetailsCmd(resource)
-					}
-				}
-			} else if m.selectedPanel == 1 {
-				// Right panel scrolling down
-				rightContent := m.renderResourcePanel(m.width/3, m.height-2)
-				totalLines := strings.Count(rightContent, "\n")
-				maxLines := max(0, totalLines-(m.height-6))
-				if m.rightPanelScrollOffset < maxLines {
-					m.rightPanelScrollOffset++
-				}
+		// Keys of the health view in the focused details panel
+		if m.selectedPanel == 1 && m.activeView == "health" && m.healthView != nil {
+			if model, cmd, handled := m.updateHealthView(msg); handled {
+				return model, cmd
 			}
-		case "k", "up":
-			if m.selectedPanel == 0 && m.treeView != nil {
-				// Navigate normally
-				m.treeView.SelectPrevious()
-				m.treeView.EnsureSelection()
-				if selectedNode := m.treeView.GetSelectedNode(); selectedNode != nil && selectedNode.Type == "resource" {
-					if resource, ok := selectedNode.ResourceData.(AzureResource); ok {
-						return m, loadResourceDetailsCmd(resource)
-					}
-				}
-			} else if m.selectedPanel == 1 {
-				// Right panel scrolling up
-				if m.rightPanelScrollOffset > 0 {
-					m.rightPanelScrollOffset--
-				}
+		}
+
+		// Keys of the diagnostics view in the focused details panel
+		if m.selectedPanel == 1 && m.activeView == "diagnostics" && m.diagnosticsView != nil {
+			if model, cmd, handled := m.updateDiagnosticsView(msg); handled {
+				return model, cmd
 			}
-		case "ctrl+j", "ctrl+down":
-			// Dedicated scrolling down for current panel
-			if m.selectedPanel == 0 && m.treeView != nil {
-				// Left panel scrolling
-				treeContent := m.treeView.RenderTreeView(m.width/3-4, m.height-2)
-				totalLines := strings.Count(treeContent, "\n")
-				maxLines := max(0, totalLines-(m.height-6))
-				if m.leftPanelScrollOffset < maxLines {
-					m.leftPanelScrollOffset++
-				}
-			} else if m.selectedPanel == 1 {
-				// Right panel scrolling
-				rightContent := m.renderResourcePanel(m.width/3, m.height-2)
-				totalLines := strings.Count(rightContent, "\n")
-				maxLines := max(0, totalLines-(m.height-6))
-				if m.rightPanelScrollOffset < maxLines {
-					m.rightPanelScrollOffset++
-				}
+		}
+
+		// Keys of the KQL console in the focused details panel
+		if m.selectedPanel == 1 && m.activeView == "kql" && m.kqlView != nil {
+			if model, cmd, handled := m.updateKQLConsole(msg); handled {
+				return model, cmd
+			}
+		}
+
+		// Typing in the AI chat of the focused bottom panel
+		if m.selectedPanel == 2 && m.bottomView == "ai" && m.aiProvider != nil {
+			if model, cmd, handled := m.updateAIChatInput(msg); handled {
+				return model, cmd
 			}
-		case "ctrl+k", "ctrl+up":
-			// Dedicated scrolling up for current panel
-			switch m.selectedPanel {
-			case 0:
-				// Left panel scrolling up
-				if m.leftPanelScrollOffset > 0 {
-					m.leftPanelScrollOffset--
+		}
+
+		// Regular key handling when not in search mode
+		return m.runAction(m.keymap.Action(keymap.ScopeNormal, msg.String()))
+	}
+	return m, nil
+}
+
+// runAction performs a keymap action in normal mode. It is shared by key
+// presses and the command palette.
+func (m model) runAction(action string) (tea.Model, tea.Cmd) {
+	switch action {
+	case keymap.ActionQuit:
+		return m, tea.Quit
+	case keymap.ActionTogglePanel:
+		panels := 2
+		if m.layout.ShowBottom {
+			panels = 3
+		}
+		m.selectedPanel = (m.selectedPanel + 1) % panels
+
+	case keymap.ActionGrowTree, keymap.ActionShrinkTree, keymap.ActionToggleBottom, keymap.ActionBottomView,
+		keymap.ActionGrowBottom, keymap.ActionShrinkBottom, keymap.ActionZoom, keymap.ActionResetLayout:
+		return m.runLayoutAction(action)
+
+	// Terraform Integration - Primary Access Key
+	case keymap.ActionTerraformMenu:
+		if !m.showTerraformPopup {
+			m.showTerraformPopup = true
+			m.terraformMenuIndex = 0
+			return m, loadTerraformFoldersCmd()
+		} else {
+			m.showTerraformPopup = false
+		}
+
+	// DevOps Integration - Primary Access Key
+	case keymap.ActionDevOpsMenu:
+		if !m.showDevOpsPopup {
+			m.showDevOpsPopup = true
+			m.devopsMenuIndex = 0
+			m.devopsMode = "menu"
+			return m, loadDevOpsOrganizationsCmd()
+		} else {
+			m.showDevOpsPopup = false
+		}
+
+	// Settings Menu - Primary Access Key
+	case keymap.ActionSettingsMenu:
+		if !m.showSettingsPopup {
+			m.showSettingsPopup = true
+			m.settingsMode = "menu"
+			m.settingsMenuIndex = 0
+			return m, loadSettingsConfigCmd()
+		} else {
+			m.showSettingsPopup = false
+		}
+
+	// Subscription Selection Menu - Primary Access Key
+	case keymap.ActionSubscriptionMenu:
+		if !m.showSubscriptionPopup {
+			m.showSubscriptionPopup = true
+			m.subscriptionMenuMode = "loading"
+			m.subscriptionMenuIndex = 0
+			return m, loadSubscriptionMenuCmd()
+		} else {
+			m.showSubscriptionPopup = false
+		}
+
+	case keymap.ActionPanelLeft:
+		// Left navigation - switch to tree panel or previous section
+		if m.selectedPanel != 0 {
+			m.selectedPanel = 0
+			// Don't reset scroll when switching to maintain position
+		}
+	case keymap.ActionPanelRight:
+		// Right navigation - switch to details panel
+		if m.selectedPanel == 0 {
+			m.selectedPanel = 1
+			// Don't reset scroll when switching to maintain position
+		}
+	case keymap.ActionMoveDown:
+		if m.selectedPanel == 0 && m.treeView != nil {
+			// Try to navigate first
+			m.treeView.SelectNext()
+			m.treeView.EnsureSelection()
+			if selectedNode := m.treeView.GetSelectedNode(); selectedNode != nil && selectedNode.Type == "resource" {
+				if resource, ok := selectedNode.ResourceData.(AzureResource); ok {
+					m.activateOverviewTab()
+					return m, loadResourceDetailsCmd(resource)
 				}
-			case 1:
-				// Right panel scrolling up
-				if m.rightPanelScrollOffset > 0 {
-					m.rightPanelScrollOffset--
+			}
+		} else if m.selectedPanel == 2 && m.bottomView == "jobs" {
+			m.selectJob(1)
+		} else if m.selectedPanel > 0 {
+			m.scrollPanel(m.selectedPanel, 1)
+		}
+	case keymap.ActionMoveUp:
+		if m.selectedPanel == 0 && m.treeView != nil {
+			// Navigate normally
+			m.treeView.SelectPrevious()
+			m.treeView.EnsureSelection()
+			if selectedNode := m.treeView.GetSelectedNode(); selectedNode != nil && selectedNode.Type == "resource" {
+				if resource, ok := selectedNode.ResourceData.(AzureResource); ok {
+					m.activateOverviewTab()
+					return m, loadResourceDetailsCmd(resource)
 				}
 			}
-		case " ", "enter":
-			if m.selectedPanel == 0 && m.treeView != nil {
-				selectedNode := m.treeView.GetSelectedNode()
-				if selectedNode != nil {
-					switch selectedNode.Type {
-					case "group":
-						selectedNode.Expanded = !selectedNode.Expanded
-						if selectedNode.Expanded {
-							return m, loadResourcesInGroupCmd(selectedNode.Name)
-						}
-					case "resource":
-						if resource, ok := selectedNode.ResourceData.(AzureResource); ok {
-							return m, loadResourceDetailsCmd(resource)
+		} else if m.selectedPanel == 2 && m.bottomView == "jobs" {
+			m.selectJob(-1)
+		} else if m.selectedPanel > 0 {
+			m.scrollPanel(m.selectedPanel, -1)
+		}
+	case keymap.ActionScrollDown:
+		// Dedicated scrolling down for current panel
+		m.scrollPanel(m.selectedPanel, 1)
+	case keymap.ActionScrollUp:
+		// Dedicated scrolling up for current panel
+		m.scrollPanel(m.selectedPanel, -1)
+	case keymap.ActionSelect:
+		if m.selectedPanel == 0 && m.treeView != nil {
+			selectedNode := m.treeView.GetSelectedNode()
+			if selectedNode != nil {
+				switch selectedNode.Type {
+				case "group":
+					if _, expanded := m.treeView.ToggleExpansion(); expanded {
+						return m, loadResourcesInGroupCmd(selectedNode.Name)
+					}
+				case "smart-folder":
+					m.treeView.ToggleExpansion()
+				case "favorites":
+					if _, expanded := m.treeView.ToggleExpansion(); expanded {
+						cmd := m.loadFavoritesCmd()
+						m.refreshFavorites()
+						return m, cmd
+					}
+				case "bookmark":
+					bookmark, ok := selectedNode.ResourceData.(config.Bookmark)
+					if !ok {
+						break
+					}
+					load := m.bookmarkLoads[strings.ToLower(bookmark.ResourceID)]
+					if bookmark.Type == "group" {
+						if _, expanded := m.treeView.ToggleExpansion(); !expanded || (load != nil && load.err == nil) {
+							break
 						}
+					} else if load != nil && load.loading {
+						break
+					}
+					// Not looked up yet, or looked up without result: try again
+					cmd := m.loadBookmarkCmd(bookmark)
+					m.refreshFavorites()
+					return m, cmd
+				case "resource":
+					if resource, ok := selectedNode.ResourceData.(AzureResource); ok {
+						m.activateOverviewTab()
+						return m, loadResourceDetailsCmd(resource)
 					}
 				}
 			}
-		case "e":
-			// Toggle property expansion in details panel
-			if m.selectedPanel == 1 && m.selectedResource != nil {
-				// Toggle expansion for complex properties
-				if m.selectedResource.Type == "Microsoft.ContainerService/managedClusters" {
-					key := "agentPoolProfiles"
-					m.expandedProperties[key] = !m.expandedProperties[key]
-				}
-			}
-		case "/":
-			// Enter search mode
-			if !m.searchMode {
-				m.enterSearchMode()
-			}
-		case "s":
-			if m.selectedResource != nil && !m.actionInProgress {
-				m.actionInProgress = true
-				return m, executeResourceActionCmd("start", *m.selectedResource)
-			}
-		case "S":
-			if m.selectedResource != nil && !m.actionInProgress {
-				m.actionInProgress = true
-				return m, executeResourceActionCmd("stop", *m.selectedResource)
-			}
-		case "r":
-			if m.selectedResource != nil && !m.actionInProgress {
-				m.actionInProgress = true
-				return m, executeResourceActionCmd("restart", *m.selectedResource)
-			} else {
-				return m, loadDataCmd()
-			}
-		case "c":
-			if m.selectedResource != nil && !m.actionInProgress && m.selectedResource.Type == "Microsoft.Compute/virtualMachines" {
-				m.actionInProgress = true
-				return m, executeResourceActionCmd("ssh", *m.selectedResource)
-			}
-		case "b":
-			if m.selectedResource != nil && !m.actionInProgress && m.selectedResource.Type == "Microsoft.Compute/virtualMachines" {
-				m.actionInProgress = true
-				return m, executeResourceActionCmd("bastion", *m.selectedResource)
-			}
-		case "p":
-			if m.selectedResource != nil && !m.actionInProgress && m.selectedResource.Type == "Microsoft.ContainerService/managedClusters" {
-				m.actionInProgress = true
-				return m, executeResourceActionCmd("pods", *m.selectedResource)
-			}
-		case "n":
-			if m.selectedResource != nil && !m.actionInProgress && m.selectedResource.Type == "Microsoft.ContainerService/managedClusters" {
-				m.actionInProgress = true
-				return m, executeResourceActionCmd("nodes", *m.selectedResource)
-			}
-		case "v":
-			if m.selectedResource != nil && !m.actionInProgress && m.selectedResource.Type == "Microsoft.ContainerService/managedClusters" {
-				m.actionInProgress = true
-				return m, executeResourceActionCmd("services", *m.selectedResource)
-			}
-		case "y":
-			// AKS deployments (moved from 'D' to avoid conflict with enhanced dashboard)
-			if m.selectedResource != nil && !m.actionInProgress && m.selectedResource.Type == "Microsoft.ContainerService/managedClusters" {
-				m.actionInProgress = true
-				return m, executeResourceActionCmd("deployments", *m.selectedResource)
+		}
+	case keymap.ActionExpandProperty:
+		// Toggle property expansion in details panel
+		if m.selectedPanel == 1 && m.selectedResource != nil {
+			// Toggle expansion for complex properties
+			if m.selectedResource.Type == "Microsoft.ContainerService/managedClusters" {
+				key := "agentPoolProfiles"
+				m.expandedProperties[key] = !m.expandedProperties[key]
 			}
-		case "N":
-			// Show comprehensive network dashboard
-			if !m.actionInProgress {
-				m.actionInProgress = true
-				// Add debug logging
-				m.logEntries = append(m.logEntries, "DEBUG: Network Dashboard command triggered")
-				return m, showNetworkDashboardCmd()
-			}
-		case "V":
-			// Show VNet details for selected network resource
-			if m.selectedResource != nil && !m.actionInProgress && strings.Contains(m.selectedResource.Type, "Network") {
-				if strings.Contains(m.selectedResource.Type, "virtualNetworks") {
-					m.actionInProgress = true
-					return m, showVNetDetailsCmd(m.selectedResource.Name, m.selectedResource.ResourceGroup)
+		}
+	case keymap.ActionMetrics:
+		// Toggle the metrics view of the selected resource
+		if m.activeView == "metrics" {
+			m.popView()
+		} else if cmd := m.openMetricsExplorer(); cmd != nil {
+			return m, cmd
+		}
+	case keymap.ActionAlerts:
+		// Toggle the alerts view of the selected node
+		if m.activeView == "alerts" {
+			m.popView()
+		} else {
+			return m, m.openAlerts()
+		}
+	case keymap.ActionHealth:
+		// Toggle the health view of the selected resource
+		if m.activeView == "health" {
+			m.popView()
+		} else {
+			return m, m.openHealth()
+		}
+	case keymap.ActionDiagnostics:
+		// Toggle the diagnostic settings audit of the selected node
+		if m.activeView == "diagnostics" {
+			m.popView()
+		} else {
+			return m, m.openDiagnostics()
+		}
+	case keymap.ActionKQL:
+		// Toggle the KQL console
+		if m.activeView == "kql" {
+			m.popView()
+		} else {
+			return m, m.openKQLConsole()
+		}
+	case keymap.ActionQuotas:
+		// Toggle the quotas view of the subscription
+		if m.activeView == "quotas" {
+			m.popView()
+		} else if cmd := m.openQuotas(); cmd != nil {
+			return m, cmd
+		}
+	case keymap.ActionRawJSON:
+		// Toggle the raw JSON view of the selected resource
+		if m.activeView == "raw-json" {
+			m.popView()
+		} else {
+			m.openJSONExplorer()
+		}
+	case keymap.ActionSearch:
+		// Enter search mode
+		if !m.searchMode {
+			m.enterSearchMode()
+		}
+	case keymap.ActionOpenTab:
+		// Open the selected resource in a new tab
+		resource := m.selectedResource
+		if m.selectedPanel == 0 && m.treeView != nil {
+			if selectedNode := m.treeView.GetSelectedNode(); selectedNode != nil && selectedNode.Type == "resource" {
+				if nodeResource, ok := selectedNode.ResourceData.(AzureResource); ok {
+					resource = &nodeResource
 				}
 			}
-		case "G":
-			// Show NSG details for selected network security group
-			if m.selectedResource != nil && !m.actionInProgress && strings.Contains(m.selectedResource.Type, "networkSecurityGroups") {
-				m.actionInProgress = true
-				return m, showNSGDetailsCmd(m.selectedResource.Name, m.selectedResource.ResourceGroup)
-			}
-		case "Z":
-			// Show network topology view
-			if !m.actionInProgress {
-				m.actionInProgress = true
-				return m, showNetworkTopologyCmd()
-			}
-		case "A":
-			// Show AI-powered network analysis
-			if !m.actionInProgress {
-				m.actionInProgress = true
-				return m, showNetworkAIAnalysisCmd()
-			}
-		case "C":
-			// Key Vault: Create secret (with demo values for now)
-			if m.selectedResource != nil && !m.actionInProgress && m.selectedResource.Type == "Microsoft.KeyVault/vaults" {
-				m.actionInProgress = true
-				// For demo purposes, create a secret with sample name and value
-				// In a real implementation, this would open a form dialog
-				return m, createKeyVaultSecretCmd(m.selectedResource.Name, "demo-secret", "demo-value", map[string]string{"created-by": "azure-tui"})
-			}
-			// Create VNet action for network resources
-			if !m.actionInProgress {
-				m.actionInProgress = true
-				return m, createNetworkResourceCmd("vnet")
-			}
-		case "ctrl+n":
-			// Create NSG action
-			if !m.actionInProgress {
-				m.actionInProgress = true
-				return m, createNetworkResourceCmd("nsg")
-			}
-		case "ctrl+s":
-			// Create subnet action
-			if !m.actionInProgress {
-				m.actionInProgress = true
-				return m, createNetworkResourceCmd("subnet")
-			}
-		case "ctrl+p":
-			// Create public IP action
-			if !m.actionInProgress {
-				m.actionInProgress = true
-				return m, createNetworkResourceCmd("publicip")
-			}
-		case "ctrl+l":
-			// Create load balancer action
-			if !m.actionInProgress {
-				m.actionInProgress = true
-				return m, createNetworkResourceCmd("loadbalancer")
-			}
-
-		// Container Instance Management Actions
-		case "L":
-			// Get container logs
-			if m.selectedResource != nil && !m.actionInProgress && m.selectedResource.Type == "Microsoft.ContainerInstance/containerGroups" {
-				m.actionInProgress = true
-				return m, getContainerLogsCmd(m.selectedResource.Name, m.selectedResource.ResourceGroup, "", 100)
-			}
-		case "E":
-			// Exec into container
-			if m.selectedResource != nil && !m.actionInProgress && m.selectedResource.Type == "Microsoft.ContainerInstance/containerGroups" {
-				m.actionInProgress = true
-				return m, execIntoContainerCmd(m.selectedResource.Name, m.selectedResource.ResourceGroup, "", "/bin/bash")
-			}
-		case "a":
-			// AI Analysis for selected resource (general case)
-			if m.selectedResource != nil && !m.actionInProgress && m.aiProvider != nil {
-				m.actionInProgress = true
-				return m, loadAIDescriptionCmd(m.aiProvider, *m.selectedResource, m.resourceDetails)
-			}
-			// Attach to container (only for container instances) - fallback if no AI provider
-			if m.selectedResource != nil && !m.actionInProgress && m.selectedResource.Type == "Microsoft.ContainerInstance/containerGroups" {
-				m.actionInProgress = true
-				return m, attachToContainerCmd(m.selectedResource.Name, m.selectedResource.ResourceGroup, "")
-			}
-		case "u":
-			// Update/scale container instance
-			if m.selectedResource != nil && !m.actionInProgress && m.selectedResource.Type == "Microsoft.ContainerInstance/containerGroups" {
-				m.actionInProgress = true
-				// Scale up CPU and memory (this could be made interactive in future)
-				return m, scaleContainerInstanceCmd(m.selectedResource.Name, m.selectedResource.ResourceGroup, 2.0, 4.0)
+		}
+		if resource != nil {
+			opened := *resource
+			return m, tea.Batch(m.openTab("resource", opened.Name, "details", &opened), loadResourceDetailsCmd(opened))
+		}
+	case keymap.ActionNextTab:
+		return m, m.switchTab(1)
+	case keymap.ActionPrevTab:
+		return m, m.switchTab(-1)
+	case keymap.ActionCloseTab:
+		return m, m.closeActiveTab()
+	case keymap.ActionToggleBookmark:
+		if m.selectedPanel == 0 && m.treeView != nil {
+			if selectedNode := m.treeView.GetSelectedNode(); selectedNode != nil {
+				if bookmark, ok := m.bookmarkFor(selectedNode); ok {
+					return m, toggleBookmarkCmd(bookmark, m.isBookmarked(bookmark.ResourceID))
+				}
 			}
-		case "I":
-			// Show detailed container instance information
-			if m.selectedResource != nil && !m.actionInProgress && m.selectedResource.Type == "Microsoft.ContainerInstance/containerGroups" {
+		}
+	case keymap.ActionJumpBookmark:
+		if m.treeView != nil {
+			m.openBookmarkPicker()
+		}
+	case keymap.ActionCommands:
+		m.openCommandsPopup()
+	case keymap.ActionCopy:
+		m.openCopyMenu()
+	case keymap.ActionDeleteSavedSearch:
+		// Remove the selected smart folder's saved search
+		if m.selectedPanel == 0 && m.treeView != nil {
+			if selectedNode := m.treeView.GetSelectedNode(); selectedNode != nil && selectedNode.Type == "smart-folder" {
+				return m, deleteSavedSearchCmd(selectedNode.Name)
+			}
+		}
+	case keymap.ActionStart:
+		if m.selectedResource != nil {
+			cmd := m.startResourceActionJob("start", *m.selectedResource)
+			return m, cmd
+		}
+	case keymap.ActionStop:
+		if m.selectedResource != nil {
+			cmd := m.startResourceActionJob("stop", *m.selectedResource)
+			return m, cmd
+		}
+	case keymap.ActionRestart:
+		if m.selectedResource != nil {
+			cmd := m.startResourceActionJob("restart", *m.selectedResource)
+			return m, cmd
+		} else {
+			return m, loadDataCmd()
+		}
+	case keymap.ActionJobs:
+		m.layout.ShowBottom = true
+		m.layout.Zoomed = false
+		m.selectedPanel = 2
+		m.bottomView = "jobs"
+		m.bottomScrollOffset = 0
+		return m, saveLayoutCmd(m.layoutConfig())
+	case keymap.ActionCancelJob:
+		m.cancelJob()
+	case keymap.ActionSSH:
+		if m.selectedResource != nil && !m.actionInProgress && m.selectedResource.Type == "Microsoft.Compute/virtualMachines" {
+			resource := *m.selectedResource
+			openCmd := m.openTab("shell", "ssh "+resource.Name, "ssh", &resource)
+			m.actionInProgress = true
+			return m, tea.Batch(openCmd, executeResourceActionCmd("ssh", resource))
+		}
+	case keymap.ActionBastion:
+		if m.selectedResource != nil && !m.actionInProgress && m.selectedResource.Type == "Microsoft.Compute/virtualMachines" {
+			m.actionInProgress = true
+			return m, executeResourceActionCmd("bastion", *m.selectedResource)
+		}
+	case keymap.ActionAKSPods:
+		if m.selectedResource != nil && !m.actionInProgress && m.selectedResource.Type == "Microsoft.ContainerService/managedClusters" {
+			m.actionInProgress = true
+			return m, executeResourceActionCmd("pods", *m.selectedResource)
+		}
+	case keymap.ActionAKSNodes:
+		if m.selectedResource != nil && !m.actionInProgress && m.selectedResource.Type == "Microsoft.ContainerService/managedClusters" {
+			m.actionInProgress = true
+			return m, executeResourceActionCmd("nodes", *m.selectedResource)
+		}
+	case keymap.ActionAKSServices:
+		if m.selectedResource != nil && !m.actionInProgress && m.selectedResource.Type == "Microsoft.ContainerService/managedClusters" {
+			m.actionInProgress = true
+			return m, executeResourceActionCmd("services", *m.selectedResource)
+		}
+	case keymap.ActionAKSDeployments:
+		// AKS deployments (moved from 'D' to avoid conflict with enhanced dashboard)
+		if m.selectedResource != nil && !m.actionInProgress && m.selectedResource.Type == "Microsoft.ContainerService/managedClusters" {
+			m.actionInProgress = true
+			return m, executeResourceActionCmd("deployments", *m.selectedResource)
+		}
+	case keymap.ActionNetworkDashboard:
+		// Show comprehensive network dashboard
+		if !m.actionInProgress {
+			openCmd := m.openTab("monitor", "Network", "network-dashboard", nil)
+			m.actionInProgress = true
+			// Add debug logging
+			m.logEntries = append(m.logEntries, "DEBUG: Network Dashboard command triggered")
+			return m, tea.Batch(openCmd, showNetworkDashboardCmd())
+		}
+	case keymap.ActionVNetDetails:
+		// Show VNet details for selected network resource
+		if m.selectedResource != nil && !m.actionInProgress && strings.Contains(m.selectedResource.Type, "Network") {
+			if strings.Contains(m.selectedResource.Type, "virtualNetworks") {
 				m.actionInProgress = true
-				return m, showContainerInstanceDetailsCmd(m.selectedResource.Name, m.selectedResource.ResourceGroup)
+				return m, showVNetDetailsCmd(m.selectedResource.Name, m.selectedResource.ResourceGroup)
 			}
+		}
+	case keymap.ActionNSGDetails:
+		// Show NSG details for selected network security group
+		if m.selectedResource != nil && !m.actionInProgress && strings.Contains(m.selectedResource.Type, "networkSecurityGroups") {
+			m.actionInProgress = true
+			return m, showNSGDetailsCmd(m.selectedResource.Name, m.selectedResource.ResourceGroup)
+		}
+	case keymap.ActionNetworkTopology:
+		// Show network topology view
+		if !m.actionInProgress {
+			openCmd := m.openTab("monitor", "Topology", "network-topology", nil)
+			m.actionInProgress = true
+			return m, tea.Batch(openCmd, showNetworkTopologyCmd())
+		}
+	case keymap.ActionNetworkAI:
+		// Show AI-powered network analysis
+		if !m.actionInProgress {
+			openCmd := m.openTab("monitor", "Network AI", "network-ai", nil)
+			m.actionInProgress = true
+			return m, tea.Batch(openCmd, showNetworkAIAnalysisCmd())
+		}
+	case keymap.ActionCreate:
+		// Key Vault: Create secret (with demo values for now)
+		if m.selectedResource != nil && !m.actionInProgress && m.selectedResource.Type == "Microsoft.KeyVault/vaults" {
+			m.actionInProgress = true
+			// For demo purposes, create a secret with sample name and value
+			// In a real implementation, this would open a form dialog
+			return m, createKeyVaultSecretCmd(m.selectedResource.Name, "demo-secret", "demo-value", map[string]string{"created-by": "azure-tui"})
+		}
+		// Create VNet action for network resources
+		if !m.actionInProgress {
+			m.actionInProgress = true
+			return m, createNetworkResourceCmd("vnet")
+		}
+	case keymap.ActionCreateNSG:
+		// Create NSG action
+		if !m.actionInProgress {
+			m.actionInProgress = true
+			return m, createNetworkResourceCmd("nsg")
+		}
+	case keymap.ActionCreateSubnet:
+		// Create subnet action
+		if !m.actionInProgress {
+			m.actionInProgress = true
+			return m, createNetworkResourceCmd("subnet")
+		}
+	case keymap.ActionCreatePublicIP:
+		// Create public IP action
+		if !m.actionInProgress {
+			m.actionInProgress = true
+			return m, createNetworkResourceCmd("publicip")
+		}
+	case keymap.ActionCreateLoadBalancer:
+		// Create load balancer action
+		if !m.actionInProgress {
+			m.actionInProgress = true
+			return m, createNetworkResourceCmd("loadbalancer")
+		}
 
-		// Key Vault Management Actions
-		case "K":
-			// List Key Vault secrets
-			if m.selectedResource != nil && !m.actionInProgress && m.selectedResource.Type == "Microsoft.KeyVault/vaults" {
-				m.actionInProgress = true
-				return m, listKeyVaultSecretsCmd(m.selectedResource.Name)
-			}
-		case "shift+k":
-			// Create Key Vault secret
-			if m.selectedResource != nil && !m.actionInProgress && m.selectedResource.Type == "Microsoft.KeyVault/vaults" {
-				m.actionInProgress = true
-				// For demo purposes, create a secret with sample name and value
-				// In a real implementation, this would open a form dialog
-				return m, createKeyVaultSecretCmd(m.selectedResource.Name, "demo-secret", "demo-value", map[string]string{"created-by": "azure-tui"})
-			}
-		case "ctrl+d":
-			// Delete Key Vault secret (demo - would need secret selection in real implementation)
-			if m.selectedResource != nil && !m.actionInProgress && m.selectedResource.Type == "Microsoft.KeyVault/vaults" {
-				m.actionInProgress = true
-				// For demo purposes, delete a known secret name
-				// In a real implementation, this would show a list to select from
-				return m, deleteKeyVaultSecretCmd(m.selectedResource.Name, "demo-secret")
-			}
+	// Container Instance Management Actions
+	case keymap.ActionContainerLogs:
+		// Get container logs
+		if m.selectedResource != nil && !m.actionInProgress && m.selectedResource.Type == "Microsoft.ContainerInstance/containerGroups" {
+			resource := *m.selectedResource
+			openCmd := m.openTab("logs", resource.Name+" logs", "container-logs", &resource)
+			m.actionInProgress = true
+			return m, tea.Batch(openCmd, getContainerLogsCmd(resource.Name, resource.ResourceGroup, "", 100))
+		}
+	case keymap.ActionContainerExec:
+		// Exec into container
+		if m.selectedResource != nil && !m.actionInProgress && m.selectedResource.Type == "Microsoft.ContainerInstance/containerGroups" {
+			m.actionInProgress = true
+			return m, execIntoContainerCmd(m.selectedResource.Name, m.selectedResource.ResourceGroup, "", "/bin/bash")
+		}
+	case keymap.ActionAnalyze:
+		// AI Analysis for selected resource (general case)
+		if m.selectedResource != nil && !m.actionInProgress && m.aiProvider != nil {
+			m.actionInProgress = true
+			return m, loadAIDescriptionCmd(m.aiProvider, *m.selectedResource, m.resourceDetails)
+		}
+		// Attach to container (only for container instances) - fallback if no AI provider
+		if m.selectedResource != nil && !m.actionInProgress && m.selectedResource.Type == "Microsoft.ContainerInstance/containerGroups" {
+			m.actionInProgress = true
+			return m, attachToContainerCmd(m.selectedResource.Name, m.selectedResource.ResourceGroup, "")
+		}
+	case keymap.ActionContainerScale:
+		// Update/scale container instance
+		if m.selectedResource != nil && !m.actionInProgress && m.selectedResource.Type == "Microsoft.ContainerInstance/containerGroups" {
+			m.actionInProgress = true
+			// Scale up CPU and memory (this could be made interactive in future)
+			return m, scaleContainerInstanceCmd(m.selectedResource.Name, m.selectedResource.ResourceGroup, 2.0, 4.0)
+		}
+	case keymap.ActionContainerDetails:
+		// Show detailed container instance information
+		if m.selectedResource != nil && !m.actionInProgress && m.selectedResource.Type == "Microsoft.ContainerInstance/containerGroups" {
+			m.actionInProgress = true
+			return m, showContainerInstanceDetailsCmd(m.selectedResource.Name, m.selectedResource.ResourceGroup)
+		}
 
-		// Storage Account Management Actions
-		case "T":
-			// List Storage Containers (using T for sTroage containers)
-			if m.selectedResource != nil && !m.actionInProgress && m.selectedResource.Type == "Microsoft.Storage/storageAccounts" {
-				m.actionInProgress = true
-				return m, listStorageContainersCmd(m.selectedResource.Name)
-			}
-		case "shift+t":
-			// Create Storage Container
-			if m.selectedResource != nil && !m.actionInProgress && m.selectedResource.Type == "Microsoft.Storage/storageAccounts" {
-				m.actionInProgress = true
-				// For demo purposes, create a container with a sample name
-				// In a real implementation, this would open a form dialog
-				return m, createStorageContainerCmd(m.selectedResource.Name, "demo-container")
-			}
-		case "B":
-			// List Blobs in Container (only available when viewing containers)
-			if m.selectedResource != nil && !m.actionInProgress &&
-				m.selectedResource.Type == "Microsoft.Storage/storageAccounts" &&
-				m.activeView == "storage-containers" && len(m.storageContainers) > 0 {
-				m.actionInProgress = true
-				// For demo purposes, use the first container
-				// In a real implementation, this would allow container selection
-				containerName := m.storageContainers[0].Name
-				return m, listStorageBlobsCmd(m.selectedResource.Name, containerName)
-			}
-		case "U":
-			// Upload Blob (only available when viewing blobs)
-			if m.selectedResource != nil && !m.actionInProgress &&
-				m.selectedResource.Type == "Microsoft.Storage/storageAccounts" &&
-				m.activeView == "storage-blobs" && m.currentContainer != "" {
-				m.actionInProgress = true
-				// For demo purposes, simulate uploading a file
-				// In a real implementation, this would open a file dialog
-				return m, uploadBlobCmd(m.selectedResource.Name, m.currentContainer, "demo-blob.txt", "/tmp/demo-file.txt")
-			}
-		case "ctrl+x":
-			// Delete Storage Item (Container or Blob depending on current view)
-			if m.selectedResource != nil && !m.actionInProgress && m.selectedResource.Type == "Microsoft.Storage/storageAccounts" {
-				m.actionInProgress = true
-				if m.activeView == "storage-containers" && len(m.storageContainers) > 0 {
-					// Delete first container for demo
-					return m, deleteStorageContainerCmd(m.selectedResource.Name, m.storageContainers[0].Name)
-				} else if m.activeView == "storage-blobs" && len(m.storageBlobs) > 0 && m.currentContainer != "" {
-					// Delete first blob for demo
-					return m, deleteBlobCmd(m.selectedResource.Name, m.currentContainer, m.storageBlobs[0].Name)
-				}
-			}
+	// Key Vault Management Actions
+	case keymap.ActionListSecrets:
+		// List Key Vault secrets
+		if m.selectedResource != nil && !m.actionInProgress && m.selectedResource.Type == "Microsoft.KeyVault/vaults" {
+			m.actionInProgress = true
+			return m, listKeyVaultSecretsCmd(m.selectedResource.Name)
+		}
+	case keymap.ActionDeleteSecret:
+		// Delete Key Vault secret (demo - would need secret selection in real implementation)
+		if m.selectedResource != nil && !m.actionInProgress && m.selectedResource.Type == "Microsoft.KeyVault/vaults" {
+			m.actionInProgress = true
+			// For demo purposes, delete a known secret name
+			// In a real implementation, this would show a list to select from
+			return m, deleteKeyVaultSecretCmd(m.selectedResource.Name, "demo-secret")
+		}
 
-		case "R":
-			return m, loadDataCmd()
-		case "?":
-			// Toggle help popup
-			m.showHelpPopup = !m.showHelpPopup
-		case "escape":
-			// Handle escape key for search mode, help popup, or navigation
-			if m.searchMode {
-				m.exitSearchMode()
-			} else if m.showHelpPopup {
-				m.showHelpPopup = false
-				m.helpScrollOffset = 0 // Reset scroll when closing
-			} else {
-				// Try to go back to previous view
-				if !m.popView() {
-					// If no previous view, try to reset to welcome view
-					if m.activeView != "welcome" {
-						m.activeView = "welcome"
-						m.showDashboard = false
-						m.selectedResource = nil
-						m.rightPanelScrollOffset = 0
-						m.leftPanelScrollOffset = 0
-					}
+	// Storage Account Management Actions
+	case keymap.ActionListContainers:
+		// List Storage Containers (using T for sTroage containers)
+		if m.selectedResource != nil && !m.actionInProgress && m.selectedResource.Type == "Microsoft.Storage/storageAccounts" {
+			m.actionInProgress = true
+			return m, listStorageContainersCmd(m.selectedResource.Name)
+		}
+	case keymap.ActionCreateContainer:
+		// Create Storage Container
+		if m.selectedResource != nil && !m.actionInProgress && m.selectedResource.Type == "Microsoft.Storage/storageAccounts" {
+			m.actionInProgress = true
+			// For demo purposes, create a container with a sample name
+			// In a real implementation, this would open a form dialog
+			return m, createStorageContainerCmd(m.selectedResource.Name, "demo-container")
+		}
+	case keymap.ActionListBlobs:
+		// List Blobs in Container (only available when viewing containers)
+		if m.selectedResource != nil && !m.actionInProgress &&
+			m.selectedResource.Type == "Microsoft.Storage/storageAccounts" &&
+			m.activeView == "storage-containers" && len(m.storageContainers) > 0 {
+			m.actionInProgress = true
+			// For demo purposes, use the first container
+			// In a real implementation, this would allow container selection
+			containerName := m.storageContainers[0].Name
+			return m, listStorageBlobsCmd(m.selectedResource.Name, containerName)
+		}
+	case keymap.ActionUploadBlob:
+		// Upload Blob (only available when viewing blobs)
+		if m.selectedResource != nil &&
+			m.selectedResource.Type == "Microsoft.Storage/storageAccounts" &&
+			m.activeView == "storage-blobs" && m.currentContainer != "" {
+			// For demo purposes, simulate uploading a file
+			// In a real implementation, this would open a file dialog
+			cmd := m.startUploadBlobJob(m.selectedResource.Name, m.currentContainer, "demo-blob.txt", "/tmp/demo-file.txt")
+			return m, cmd
+		}
+	case keymap.ActionDeleteItem:
+		// Delete Storage Item (Container or Blob depending on current view)
+		if m.selectedResource != nil && !m.actionInProgress && m.selectedResource.Type == "Microsoft.Storage/storageAccounts" {
+			m.actionInProgress = true
+			if m.activeView == "storage-containers" && len(m.storageContainers) > 0 {
+				// Delete first container for demo
+				return m, deleteStorageContainerCmd(m.selectedResource.Name, m.storageContainers[0].Name)
+			} else if m.activeView == "storage-blobs" && len(m.storageBlobs) > 0 && m.currentContainer != "" {
+				// Delete first blob for demo
+				return m, deleteBlobCmd(m.selectedResource.Name, m.currentContainer, m.storageBlobs[0].Name)
+			}
+		}
+
+	case keymap.ActionRefresh:
+		return m, loadDataCmd()
+	case keymap.ActionCommandPalette:
+		m.commandPalette = tui.NewCommandPalette(m.paletteItems())
+	case keymap.ActionHelp:
+		// Toggle help popup
+		m.showHelpPopup = !m.showHelpPopup
+	case keymap.ActionBack:
+		// Handle escape key for search mode, help popup, or navigation
+		if m.searchMode {
+			m.exitSearchMode()
+		} else if m.showHelpPopup {
+			m.showHelpPopup = false
+			m.helpScrollOffset = 0 // Reset scroll when closing
+		} else {
+			// Try to go back to previous view
+			if !m.popView() {
+				// If no previous view, try to reset to welcome view
+				if m.activeView != "welcome" {
+					m.activeView = "welcome"
+					m.showDashboard = false
+					m.selectedResource = nil
+					m.rightPanelScrollOffset = 0
+					m.leftPanelScrollOffset = 0
 				}
 			}
 		}
@@ -3090,6 +6465,13 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m model) View() string {
+	// Whatever wasn't converted before layout, e.g. borders, is converted
+	// here without moving anything.
+	// Toasts are drawn over popups too.
+	return theme.Screen(m.overlayToasts(m.view()))
+}
+
+func (m model) view() string {
 	if !m.ready {
 		return lipgloss.NewStyle().
 			Background(bgDark).
@@ -3103,9 +6485,9 @@ func (m model) View() string {
 
 		// Show current subscription info instead of generic "Azure Dashboard"
 		if m.currentSubscription != nil {
-			m.statusBar.AddSegment(fmt.Sprintf("☁️ %s", m.currentSubscription.Name), colorBlue, bgDark)
+			m.statusBar.AddActionSegment(fmt.Sprintf("☁️ %s", m.currentSubscription.Name), keymap.ActionSubscriptionMenu, colorBlue, bgDark)
 		} else {
-			m.statusBar.AddSegment("☁️ Azure Dashboard", colorBlue, bgDark)
+			m.statusBar.AddActionSegment("☁️ Azure Dashboard", keymap.ActionSubscriptionMenu, colorBlue, bgDark)
 		}
 
 		switch m.loadingState {
@@ -3117,13 +6499,57 @@ func (m model) View() string {
 				m.statusBar.AddSegment(fmt.Sprintf("Selected: %s", m.selectedResource.Name), colorPurple, bgMedium)
 			}
 		case "error":
-			m.statusBar.AddSegment("Error", colorRed, bgMedium)
+			m.statusBar.AddActionSegment("Error", keymap.ActionRefresh, colorRed, bgMedium)
+		}
+		if running := m.jobManager.Running(); running > 0 {
+			m.statusBar.AddActionSegment(fmt.Sprintf("⏳ %d running", running), keymap.ActionJobs, colorYellow, bgMedium)
+		}
+		switch {
+		case m.openAlertCount == 1:
+			m.statusBar.AddActionSegment("🔔 1 alert", keymap.ActionAlerts, colorRed, bgMedium)
+		case m.openAlertCount > 1:
+			m.statusBar.AddActionSegment(fmt.Sprintf("🔔 %d alerts", m.openAlertCount), keymap.ActionAlerts, colorRed, bgMedium)
 		}
 
 		panelName := "Tree"
 		panelHelp := ""
 		navigationHelp := ""
-		if m.selectedPanel == 1 {
+		if m.selectedPanel == 2 {
+			panelName = "Bottom"
+			panelHelp = " (j/k:scroll)"
+			if m.bottomView == "jobs" {
+				panelHelp = " (j/k:select job)"
+			}
+			navigationHelp = "h/←:Tree Tab:Next"
+		} else if m.selectedPanel == 1 && m.activeView == "metrics" {
+			panelName = "Metrics"
+			panelHelp = " (j/k:metric space:chart t:range)"
+			navigationHelp = "Tab:Tree Esc:Back"
+		} else if m.selectedPanel == 1 && m.activeView == "alerts" {
+			panelName = "Alerts"
+			panelHelp = " (a:acknowledge c:close s:show closed)"
+			navigationHelp = "Tab:Tree Esc:Back"
+		} else if m.selectedPanel == 1 && m.activeView == "health" {
+			panelName = "Health"
+			panelHelp = " (j/k:move enter:details r:refresh)"
+			navigationHelp = "Tab:Tree Esc:Back"
+		} else if m.selectedPanel == 1 && m.activeView == "diagnostics" {
+			panelName = "Diagnostics"
+			panelHelp = " (space:select w:workspace p:preview)"
+			navigationHelp = "Tab:Tree Esc:Back"
+		} else if m.selectedPanel == 1 && m.activeView == "kql" {
+			panelName = "KQL"
+			panelHelp = " (ctrl+r:run ctrl+o:queries x:export)"
+			navigationHelp = "Tab:Tree Esc:Back"
+		} else if m.selectedPanel == 1 && m.activeView == "quotas" {
+			panelName = "Quotas"
+			panelHelp = " (a:all n/p:region L:type region)"
+			navigationHelp = "Tab:Tree Esc:Back"
+		} else if m.selectedPanel == 1 && m.activeView == "raw-json" {
+			panelName = "Raw JSON"
+			panelHelp = " (j/k:move /:search f:filter)"
+			navigationHelp = "Tab:Tree Esc:Back"
+		} else if m.selectedPanel == 1 {
 			panelName = "Details"
 			if m.rightPanelScrollOffset > 0 {
 				panelHelp = " (j/k:scroll)"
@@ -3139,17 +6565,17 @@ func (m model) View() string {
 			}
 			navigationHelp = "l/→:Details"
 		}
-		m.statusBar.AddSegment(fmt.Sprintf("▶ %s%s", panelName, panelHelp), colorAqua, bgMedium)
-		m.statusBar.AddSegment(navigationHelp, colorPurple, bgMedium)
+		m.statusBar.AddActionSegment(fmt.Sprintf("▶ %s%s", panelName, panelHelp), keymap.ActionTogglePanel, colorAqua, bgMedium)
+		m.statusBar.AddActionSegment(navigationHelp, keymap.ActionTogglePanel, colorPurple, bgMedium)
 
 		// Add expansion hint for AKS resources
 		if m.selectedResource != nil && m.selectedResource.Type == "Microsoft.ContainerService/managedClusters" && m.selectedPanel == 1 {
-			m.statusBar.AddSegment("e:Expand AKS Properties", colorYellow, bgMedium)
+			m.statusBar.AddActionSegment("e:Expand AKS Properties", keymap.ActionExpandProperty, colorYellow, bgMedium)
 		}
 
 		// Add navigation indicator if there's history
 		if len(m.navigationStack) > 0 {
-			m.statusBar.AddSegment(fmt.Sprintf("Esc:Back(%d)", len(m.navigationStack)), colorAqua, bgMedium)
+			m.statusBar.AddActionSegment(fmt.Sprintf("Esc:Back(%d)", len(m.navigationStack)), keymap.ActionBack, colorAqua, bgMedium)
 		}
 
 		// Add search indicators
@@ -3163,82 +6589,15 @@ func (m model) View() string {
 				m.statusBar.AddSegment("Enter:Select", colorGray, bgLight)
 			}
 		} else {
-			m.statusBar.AddSegment("/:Search", colorGray, bgLight)
+			m.statusBar.AddActionSegment("/:Search", keymap.ActionSearch, colorGray, bgLight)
 		}
 
 		// Add contextual shortcuts
-		m.statusBar.AddSegment(m.getContextualShortcuts(), colorGray, bgLight)
-	}
-
-	// Two-panel layout - Fixed width constraints to prevent layout breaking
-	leftWidth := m.width / 3
-	rightWidth := m.width - leftWidth
-
-	// Ensure minimum widths to prevent layout collapse
-	if leftWidth < 20 {
-		leftWidth = 20
-	}
-	if rightWidth < 30 {
-		rightWidth = 30
-	}
-
-	// Tree panel with strict width enforcement
-	treeContent := ""
-	if m.treeView != nil {
-		treeContentRaw := m.treeView.RenderTreeView(leftWidth-4, m.height-2)
-		// ALWAYS apply left panel scroll offset to maintain independent position
-		treeContent = m.renderScrollableContentWithOffset(treeContentRaw, m.height-6, m.leftPanelScrollOffset)
-	}
-
-	// Style left panel with STRICT width constraints
-	leftPanelStyle := lipgloss.NewStyle().
-		Width(leftWidth).
-		MaxWidth(leftWidth). // Enforce maximum width
-		Foreground(fgMedium).
-		Padding(1, 2)
-
-	// Add visual indicator for active panel
-	if m.selectedPanel == 0 {
-		leftPanelStyle = leftPanelStyle.
-			Foreground(fgLight).
-			Bold(true)
-		// Add enhanced active panel indicator
-		treeContent = "🔍 " + strings.ReplaceAll(treeContent, "\n", "\n   ")
-	}
-
-	leftPanel := leftPanelStyle.Render(treeContent)
-
-	// Details panel with scrolling support and STRICT width constraints
-	rightContentRaw := ""
-	if m.searchMode && m.showSearchResults {
-		// Show search results in right panel when in search mode
-		rightContentRaw = m.renderSearchResults(rightWidth-4, m.height-2)
-	} else {
-		rightContentRaw = m.renderResourcePanel(rightWidth-4, m.height-2)
-	}
-
-	// Ensure content is properly wrapped to prevent layout breaking
-	rightContentWrapped := wrapText(rightContentRaw, rightWidth-8)
-
-	// ALWAYS apply right panel scroll offset to maintain independent position
-	rightContent := m.renderScrollableContentWithOffset(rightContentWrapped, m.height-6, m.rightPanelScrollOffset)
-
-	// Style right panel with STRICT width constraints
-	rightPanelStyle := lipgloss.NewStyle().
-		Width(rightWidth).
-		MaxWidth(rightWidth). // Enforce maximum width
-		Foreground(fgMedium).
-		Padding(1, 2)
-
-	if m.selectedPanel == 1 {
-		rightPanelStyle = rightPanelStyle.
-			Foreground(fgLight).
-			Bold(true)
-		// Add enhanced active panel marker
-		rightContent = "📊 " + strings.ReplaceAll(rightContent, "\n", "\n   ")
+		m.statusBar.AddActionSegment(m.getContextualShortcuts(), keymap.ActionHelp, colorGray, bgLight)
 	}
 
-	rightPanel := rightPanelStyle.Render(rightContent)
+	// Panels are sized by the layout, zoomed out panels aren't drawn
+	tree, details, bottom := m.panelRects()
 
 	// Join everything
 	statusBarContent := ""
@@ -3252,14 +6611,42 @@ func (m model) View() string {
 		searchInput = m.renderSearchInput(m.width)
 	}
 
-	mainContent := lipgloss.JoinHorizontal(lipgloss.Top, leftPanel, rightPanel)
-
-	// Combine status bar, search input, and main content
-	var fullView string
+	// Combine status bar, search input, the panels and the bottom panel
+	sections := []string{statusBarContent}
 	if searchInput != "" {
-		fullView = lipgloss.JoinVertical(lipgloss.Left, statusBarContent, searchInput, mainContent)
-	} else {
-		fullView = lipgloss.JoinVertical(lipgloss.Left, statusBarContent, mainContent)
+		sections = append(sections, searchInput)
+	}
+	top := lipgloss.Height(strings.Join(sections, "\n"))
+
+	var panels []string
+	if !tree.Empty() {
+		panels = append(panels, m.renderTreePanel(tree.Width, tree.Height))
+	}
+	if !details.Empty() {
+		panels = append(panels, m.renderDetailsPanel(details.Width, details.Height))
+	}
+	if mainHeight := m.height - top - bottom.Height; len(panels) > 0 && mainHeight > 0 {
+		// A fixed height keeps the bottom panel at the bottom of the screen
+		mainContent := lipgloss.JoinHorizontal(lipgloss.Top, panels...)
+		sections = append(sections, lipgloss.NewStyle().Height(mainHeight).MaxHeight(mainHeight).Render(mainContent))
+	}
+	if !bottom.Empty() {
+		sections = append(sections, m.renderBottomPanel(bottom.Width, min(bottom.Height, m.height-top)))
+	}
+	fullView := lipgloss.JoinVertical(lipgloss.Left, sections...)
+
+	// Render the command palette above everything else
+	if m.commandPalette != nil {
+		return m.renderCommandPalette(fullView)
+	}
+	if m.bookmarkPicker != nil {
+		return m.renderBookmarkPicker(fullView)
+	}
+	if m.commandsPopup != nil {
+		return m.renderCommandsPopup()
+	}
+	if m.copyMenu != nil {
+		return m.renderCopyMenu()
 	}
 
 	// Render help popup if active
@@ -3272,117 +6659,30 @@ func (m model) View() string {
 		// Create structured table data for better formatting
 		var allSections []string
 
-		// Navigation section
-		allSections = append(allSections, lipgloss.NewStyle().Bold(true).Foreground(colorGreen).Render("🧭 Navigation:"))
-		allSections = append(allSections, "")
-		allSections = append(allSections, renderShortcutRow("j/k ↑/↓", "Navigate up/down in tree"))
-		allSections = append(allSections, renderShortcutRow("h/l ←/→", "Switch between panels"))
-		allSections = append(allSections, renderShortcutRow("Space", "Expand/collapse resource groups"))
-		allSections = append(allSections, renderShortcutRow("Enter", "Open resource in details panel"))
-		allSections = append(allSections, renderShortcutRow("Tab", "Switch between panels"))
-		allSections = append(allSections, renderShortcutRow("e", "Expand/collapse complex properties"))
-		allSections = append(allSections, renderShortcutRow("Ctrl+j/k", "Scroll up/down in current panel"))
-		allSections = append(allSections, "")
-
-		// Search section
-		allSections = append(allSections, lipgloss.NewStyle().Bold(true).Foreground(colorYellow).Render("🔍 Search:"))
-		allSections = append(allSections, "")
-		allSections = append(allSections, renderShortcutRow("/", "Enter search mode"))
-		allSections = append(allSections, renderShortcutRow("Enter", "Execute search / Accept suggestion"))
-		allSections = append(allSections, renderShortcutRow("Tab", "Accept first suggestion"))
-		allSections = append(allSections, renderShortcutRow("↑/↓", "Navigate search results"))
-		allSections = append(allSections, renderShortcutRow("Escape", "Exit search mode"))
-		allSections = append(allSections, renderShortcutRow("Advanced", "type:vm location:eastus tag:env=prod"))
-		allSections = append(allSections, "")
-
-		// Resource Actions section
-		allSections = append(allSections, lipgloss.NewStyle().Bold(true).Foreground(colorAqua).Render("⚡ Resource Actions:"))
-		allSections = append(allSections, "")
-		allSections = append(allSections, renderShortcutRow("s", "Start resource (VMs, Containers)"))
-		allSections = append(allSections, renderShortcutRow("S", "Stop resource (VMs, Containers)"))
-		allSections = append(allSections, renderShortcutRow("r", "Restart resource (VMs, Containers)"))
-		allSections = append(allSections, renderShortcutRow("Shift+D", "Enhanced dashboard with real data"))
-		allSections = append(allSections, renderShortcutRow("R", "Refresh all data"))
-		allSections = append(allSections, "")
-
-		// Network Management section
-		allSections = append(allSections, lipgloss.NewStyle().Bold(true).Foreground(colorBlue).Render("🌐 Network Management:"))
-		allSections = append(allSections, "")
-		allSections = append(allSections, renderShortcutRow("N", "Network Dashboard"))
-		allSections = append(allSections, renderShortcutRow("V", "VNet Details (for VNets)"))
-		allSections = append(allSections, renderShortcutRow("G", "NSG Details (for NSGs)"))
-		allSections = append(allSections, renderShortcutRow("Z", "Network Topology"))
-		allSections = append(allSections, renderShortcutRow("A", "AI Network Analysis"))
-		allSections = append(allSections, renderShortcutRow("C", "Create VNet"))
-		allSections = append(allSections, renderShortcutRow("Ctrl+N", "Create NSG"))
-		allSections = append(allSections, "")
-
-		// Terraform Management section
-		allSections = append(allSections, lipgloss.NewStyle().Bold(true).Foreground(colorAqua).Render("🏗️  Terraform Management:"))
-		allSections = append(allSections, "")
-		allSections = append(allSections, renderShortcutRow("Ctrl+T", "Open Terraform Manager"))
-		allSections = append(allSections, renderShortcutRow("", "• Browse Terraform projects"))
-		allSections = append(allSections, renderShortcutRow("", "• Analyze code"))
-		allSections = append(allSections, renderShortcutRow("", "• Execute operations"))
-		allSections = append(allSections, renderShortcutRow("", "• Create from templates"))
-		allSections = append(allSections, "")
-
-		// DevOps Management section
-		allSections = append(allSections, lipgloss.NewStyle().Bold(true).Foreground(colorBlue).Render("⚒️ DevOps Management:"))
-		allSections = append(allSections, "")
-		allSections = append(allSections, renderShortcutRow("Ctrl+O", "Open Azure DevOps Manager"))
-		allSections = append(allSections, renderShortcutRow("", "• Browse organizations"))
-		allSections = append(allSections, renderShortcutRow("", "• Manage projects"))
-		allSections = append(allSections, renderShortcutRow("", "• View pipelines"))
-		allSections = append(allSections, renderShortcutRow("", "• Execute operations"))
-		allSections = append(allSections, "")
-
-		// Container Management section
-		allSections = append(allSections, lipgloss.NewStyle().Bold(true).Foreground(colorPurple).Render("🐳 Container Management:"))
-		allSections = append(allSections, "")
-		allSections = append(allSections, renderShortcutRow("L", "Get Container Logs"))
-		allSections = append(allSections, renderShortcutRow("E", "Exec into Container"))
-		allSections = append(allSections, renderShortcutRow("a", "Attach to Container"))
-		allSections = append(allSections, renderShortcutRow("u", "Scale Container Resources"))
-		allSections = append(allSections, renderShortcutRow("I", "Container Instance Details"))
-		allSections = append(allSections, "")
-
-		// SSH & AKS section
-		allSections = append(allSections, lipgloss.NewStyle().Bold(true).Foreground(colorYellow).Render("🔐 SSH & AKS:"))
-		allSections = append(allSections, "")
-		allSections = append(allSections, renderShortcutRow("c", "SSH Connect (VMs)"))
-		allSections = append(allSections, renderShortcutRow("b", "Bastion Connect (VMs)"))
-		allSections = append(allSections, renderShortcutRow("p", "List Pods (AKS)"))
-		allSections = append(allSections, renderShortcutRow("y", "List Deployments (AKS)"))
-		allSections = append(allSections, renderShortcutRow("n", "List Nodes (AKS)"))
-		allSections = append(allSections, renderShortcutRow("v", "List Services (AKS)"))
-		allSections = append(allSections, "")
-
-		// Key Vault Management section
-		allSections = append(allSections, lipgloss.NewStyle().Bold(true).Foreground(colorGray).Render("🔑 Key Vault Management:"))
-		allSections = append(allSections, "")
-		allSections = append(allSections, renderShortcutRow("K", "List Secrets"))
-		allSections = append(allSections, renderShortcutRow("Shift+K", "Create Secret"))
-		allSections = append(allSections, renderShortcutRow("Ctrl+D", "Delete Secret"))
-		allSections = append(allSections, "")
-
-		// Subscription Management section
-		allSections = append(allSections, lipgloss.NewStyle().Bold(true).Foreground(colorAqua).Render("☁️ Subscription Management:"))
-		allSections = append(allSections, "")
-		allSections = append(allSections, renderShortcutRow("Ctrl+A", "Open Subscription Manager"))
-		allSections = append(allSections, renderShortcutRow("", "• Switch Azure subscriptions"))
-		allSections = append(allSections, renderShortcutRow("", "• View tenant information"))
-		allSections = append(allSections, renderShortcutRow("", "• Change active context"))
-		allSections = append(allSections, "")
+		// Problems with the configured keymap come first
+		if len(m.keymapWarnings) > 0 {
+			allSections = append(allSections, lipgloss.NewStyle().Bold(true).Foreground(colorRed).Render("⚠️  Keymap Problems:"))
+			allSections = append(allSections, "")
+			for _, warning := range m.keymapWarnings {
+				allSections = append(allSections, lipgloss.NewStyle().Foreground(colorRed).Render("• "+warning))
+			}
+			allSections = append(allSections, "")
+		}
 
-		// Interface section
-		allSections = append(allSections, lipgloss.NewStyle().Bold(true).Foreground(colorGray).Render("🎮 Interface:"))
-		allSections = append(allSections, "")
-		allSections = append(allSections, renderShortcutRow("?", "Show/hide this help"))
-		allSections = append(allSections, renderShortcutRow("Ctrl+,", "Open Settings Manager"))
-		allSections = append(allSections, renderShortcutRow("Esc", "Navigate back / Close dialogs"))
-		allSections = append(allSections, renderShortcutRow("q", "Quit application"))
-		allSections = append(allSections, "")
+		// Sections follow the active keymap
+		for _, section := range createShortcutsMap(m.keymap) {
+			heading := helpSectionHeadings[section.Category]
+			headingColor := fgLight
+			if heading.color != nil {
+				headingColor = *heading.color
+			}
+			allSections = append(allSections, lipgloss.NewStyle().Bold(true).Foreground(headingColor).Render(heading.title))
+			allSections = append(allSections, "")
+			for _, row := range section.Rows {
+				allSections = append(allSections, renderShortcutRow(row.Keys, row.Description))
+			}
+			allSections = append(allSections, "")
+		}
 
 		// Add scroll navigation instructions
 		allSections = append(allSections, lipgloss.NewStyle().Bold(true).Foreground(colorGreen).Render("📜 Help Navigation:"))
@@ -3406,7 +6706,7 @@ func (m model) View() string {
 			Width(78). // Slightly wider for better table formatting
 			Align(lipgloss.Left, lipgloss.Top)
 
-		styledPopup := popupStyle.Render(helpContent.String())
+		styledPopup := popupStyle.Render(theme.Text(helpContent.String()))
 
 		// Create a simple centered layout
 		return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, styledPopup)
@@ -3435,6 +6735,134 @@ func (m model) View() string {
 	return lipgloss.NewStyle().Background(bgDark).Render(fullView)
 }
 
+// focusMarker marks the focused panel. It is as wide as the indentation of
+// the panel's other lines.
+func focusMarker(icon string) string {
+	return theme.Icon(icon+" ", ">> ")
+}
+
+// renderTreePanel draws the resource tree. Heights count from the top of the
+// screen, like the height of the window.
+func (m model) renderTreePanel(width, height int) string {
+	// Tree panel with strict width enforcement
+	treeContent := ""
+	if m.treeView != nil {
+		treeContentRaw := m.treeView.RenderTreeView(width-4, height-2)
+		// ALWAYS apply left panel scroll offset to maintain independent position
+		treeContent = m.renderScrollableContentWithOffset(treeContentRaw, height-6, m.leftPanelScrollOffset)
+	}
+
+	// Style left panel with STRICT width constraints
+	leftPanelStyle := lipgloss.NewStyle().
+		Width(width).
+		MaxWidth(width). // Enforce maximum width
+		Foreground(fgMedium).
+		Padding(1, 2)
+
+	// Add visual indicator for active panel
+	if m.selectedPanel == 0 {
+		leftPanelStyle = leftPanelStyle.
+			Foreground(fgLight).
+			Bold(true)
+		// Add enhanced active panel indicator
+		treeContent = focusMarker("🔍") + strings.ReplaceAll(treeContent, "\n", "\n   ")
+	}
+	// Clip instead of wrapping so every tree row stays where clicks expect it
+	treeContent = lipgloss.NewStyle().MaxWidth(width - 4).Render(treeContent)
+
+	return leftPanelStyle.Render(treeContent)
+}
+
+// renderDetailsPanel draws the tab bar and the content of the active tab
+func (m model) renderDetailsPanel(width, height int) string {
+	// Details panel with scrolling support and STRICT width constraints
+	rightContentWrapped := ""
+	if m.searchMode && m.showSearchResults {
+		// Show search results in right panel when in search mode. They lay
+		// out their own columns, so they are sized to fit instead of wrapped.
+		rightContentWrapped = theme.Text(m.renderSearchResults(width-8, height-2))
+	} else if (m.activeView == "raw-json" && m.jsonExplorer != nil) || (m.activeView == "metrics" && m.metricsView != nil) ||
+		(m.activeView == "quotas" && m.quotasView != nil) || (m.activeView == "alerts" && m.alertsView != nil) ||
+		(m.activeView == "kql" && m.kqlView != nil) || (m.activeView == "health" && m.healthView != nil) ||
+		(m.activeView == "diagnostics" && m.diagnosticsView != nil) {
+		// The raw JSON, metrics, quotas, alerts, KQL, health and diagnostics
+		// views fit their own rows, so indentation, charts, gauges and
+		// tables survive
+		rightContentWrapped = theme.Text(m.renderResourcePanel(width-4, height-2))
+	} else {
+		// Ensure content is properly wrapped to prevent layout breaking
+		rightContentWrapped = wrapText(theme.Text(m.renderResourcePanel(width-4, height-2)), width-8)
+	}
+
+	// ALWAYS apply right panel scroll offset to maintain independent position
+	rightContent := ""
+	if tabBar := m.renderTabBar(); tabBar != "" {
+		// Open tabs are listed above the content of the active one
+		tabBar = lipgloss.NewStyle().MaxWidth(width - 4).Render(tabBar)
+		rightContent = tabBar + "\n\n" + m.renderScrollableContentWithOffset(rightContentWrapped, height-8, m.rightPanelScrollOffset)
+	} else {
+		rightContent = m.renderScrollableContentWithOffset(rightContentWrapped, height-6, m.rightPanelScrollOffset)
+	}
+
+	// Style right panel with STRICT width constraints
+	rightPanelStyle := lipgloss.NewStyle().
+		Width(width).
+		MaxWidth(width). // Enforce maximum width
+		Foreground(fgMedium).
+		Padding(1, 2)
+
+	if m.selectedPanel == 1 {
+		rightPanelStyle = rightPanelStyle.
+			Foreground(fgLight).
+			Bold(true)
+		// Add enhanced active panel marker
+		rightContent = focusMarker("📊") + strings.ReplaceAll(rightContent, "\n", "\n   ")
+	}
+
+	return rightPanelStyle.Render(rightContent)
+}
+
+func (m model) renderCommandPalette(background string) string {
+	return m.renderPalettePopup("🔎 Command Palette", m.commandPalette, "Enter:Run")
+}
+
+// renderBookmarkPicker draws the list of bookmarks to jump to
+func (m model) renderBookmarkPicker(background string) string {
+	return m.renderPalettePopup("⭐ Jump to Bookmark", m.bookmarkPicker, "Enter:Jump")
+}
+
+// renderPalettePopup draws a fuzzy-filtered list in a centered popup
+func (m model) renderPalettePopup(heading string, palette *tui.CommandPalette, enterHint string) string {
+	var content strings.Builder
+
+	// Title
+	title := lipgloss.NewStyle().Bold(true).Foreground(colorBlue).Render(heading)
+	content.WriteString(title)
+	content.WriteString("\n\n")
+
+	visibleLines := max(5, min(15, m.height-12))
+	content.WriteString(palette.Render(66, visibleLines))
+	content.WriteString("\n\n")
+
+	footer := strings.Join([]string{
+		"Type to filter", m.shortcutHint(keymap.ActionSearchPrev, "Up"), m.shortcutHint(keymap.ActionSearchNext, "Down"),
+		enterHint, m.shortcutHint(keymap.ActionSearchExit, "Close"),
+	}, "  ")
+	content.WriteString(lipgloss.NewStyle().Italic(true).Foreground(colorGray).Render(footer))
+
+	// Create popup style - clean, no borders or backgrounds
+	popupStyle := lipgloss.NewStyle().
+		Foreground(fgLight).
+		Padding(1, 2).
+		Width(72).
+		Align(lipgloss.Left, lipgloss.Top)
+
+	styledPopup := popupStyle.Render(theme.Text(content.String()))
+
+	// Overlay on background
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, styledPopup)
+}
+
 func (m model) renderTerraformPopup(background string) string {
 	var content strings.Builder
 
@@ -3566,8 +6994,8 @@ func (m model) renderTerraformPopup(background string) string {
 	// Add statusbar with contextual shortcuts
 	shortcuts := m.getTerraformShortcuts()
 	statusbarStyle := lipgloss.NewStyle().
-		Background(lipgloss.Color("4")).
-		Foreground(lipgloss.Color("15")).
+		Background(colorBlue).
+		Foreground(bgDark).
 		Bold(true).
 		Padding(0, 1).
 		Width(58)
@@ -3590,7 +7018,7 @@ func (m model) renderTerraformPopup(background string) string {
 		Width(60).
 		Align(lipgloss.Center, lipgloss.Top)
 
-	styledPopup := popupStyle.Render(content.String())
+	styledPopup := popupStyle.Render(theme.Text(content.String()))
 
 	// Overlay on background
 	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, styledPopup)
@@ -3609,15 +7037,7 @@ func (m model) renderSettingsPopup(background string) string {
 		content.WriteString(lipgloss.NewStyle().Bold(true).Foreground(colorGreen).Render("Select an option:"))
 		content.WriteString("\n\n")
 
-		menuOptions := []string{
-			"📋 View Configuration",
-			"📁 Edit Terraform Directory",
-			"🎨 Edit UI Settings",
-			"📝 Edit Editor Settings",
-			"💾 Save Configuration",
-		}
-
-		for i, option := range menuOptions {
+		for i, option := range settingsMenuOptions {
 			style := lipgloss.NewStyle().Foreground(fgMedium)
 			if i == m.settingsMenuIndex {
 				style = style.Foreground(fgLight).Bold(true)
@@ -3652,6 +7072,24 @@ func (m model) renderSettingsPopup(background string) string {
 			}
 		}
 
+	case "theme-select":
+		content.WriteString(lipgloss.NewStyle().Bold(true).Foreground(colorGreen).Render("Select Theme:"))
+		content.WriteString("\n\n")
+
+		for i, name := range m.settingsThemes {
+			style := lipgloss.NewStyle().Foreground(fgMedium)
+			prefix := "  "
+			if i == m.settingsMenuIndex {
+				style = style.Foreground(fgLight).Bold(true)
+				prefix = "▶ "
+			}
+			if name == m.settingsThemeBefore.Name {
+				name += " (current)"
+			}
+			content.WriteString(style.Render(prefix + name))
+			content.WriteString("\n")
+		}
+
 	case "edit-setting":
 		content.WriteString(lipgloss.NewStyle().Bold(true).Foreground(colorGreen).Render(fmt.Sprintf("Editing: %s", m.settingsEditKey)))
 		content.WriteString("\n\n")
@@ -3666,8 +7104,8 @@ func (m model) renderSettingsPopup(background string) string {
 	// Add status bar with contextual shortcuts
 	shortcuts := m.getSettingsShortcuts()
 	statusbarStyle := lipgloss.NewStyle().
-		Background(lipgloss.Color("4")).
-		Foreground(lipgloss.Color("15")).
+		Background(colorBlue).
+		Foreground(bgDark).
 		Bold(true).
 		Padding(0, 1).
 		Width(58)
@@ -3692,7 +7130,7 @@ func (m model) renderSettingsPopup(background string) string {
 		Width(60).
 		Align(lipgloss.Center, lipgloss.Top)
 
-	styledPopup := popupStyle.Render(content.String())
+	styledPopup := popupStyle.Render(theme.Text(content.String()))
 
 	// Overlay on background
 	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, styledPopup)
@@ -3765,8 +7203,8 @@ func (m model) renderSubscriptionPopup(background string) string {
 	// Add status bar with contextual shortcuts
 	shortcuts := "Navigate: ↑/↓  Select: Enter  Back: Esc"
 	statusbarStyle := lipgloss.NewStyle().
-		Background(lipgloss.Color("4")).
-		Foreground(lipgloss.Color("15")).
+		Background(colorBlue).
+		Foreground(bgDark).
 		Bold(true).
 		Padding(0, 1).
 		Width(58)
@@ -3789,7 +7227,7 @@ func (m model) renderSubscriptionPopup(background string) string {
 		Width(70).
 		Align(lipgloss.Center, lipgloss.Top)
 
-	styledPopup := popupStyle.Render(content.String())
+	styledPopup := popupStyle.Render(theme.Text(content.String()))
 
 	// Overlay on background
 	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, styledPopup)
@@ -3926,8 +7364,8 @@ func (m model) renderDevOpsPopup(background string) string {
 	// Add status bar with contextual shortcuts
 	shortcuts := m.getDevOpsShortcuts()
 	statusbarStyle := lipgloss.NewStyle().
-		Background(lipgloss.Color("4")).
-		Foreground(lipgloss.Color("15")).
+		Background(colorBlue).
+		Foreground(bgDark).
 		Bold(true).
 		Padding(0, 1).
 		Width(58)
@@ -3950,17 +7388,50 @@ func (m model) renderDevOpsPopup(background string) string {
 		Width(70).
 		Align(lipgloss.Center, lipgloss.Top)
 
-	styledPopup := popupStyle.Render(content.String())
+	styledPopup := popupStyle.Render(theme.Text(content.String()))
 
 	// Overlay on background
 	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, styledPopup)
 }
 
 func (m model) renderResourcePanel(width, height int) string {
+	// Dashboard and log tabs show the view they were opened for
+	if content := m.tabViewContent(); content != "" {
+		return content
+	}
+
+	// Quotas belong to the subscription, alerts and diagnostics may be of a
+	// group, queries of a workspace and health of the subscription, not of
+	// a resource
+	if m.activeView == "quotas" && m.quotasView != nil {
+		return m.renderQuotas(width, height)
+	}
+	if m.activeView == "alerts" && m.alertsView != nil {
+		return m.renderAlerts(width, height)
+	}
+	if m.activeView == "kql" && m.kqlView != nil {
+		return m.renderKQLConsole(width, height)
+	}
+	if m.activeView == "health" && m.healthView != nil {
+		return m.renderHealth(width, height)
+	}
+	if m.activeView == "diagnostics" && m.diagnosticsView != nil {
+		return m.renderDiagnostics(width, height)
+	}
+
 	// Handle regular resource views
 	if m.selectedResource == nil {
 		return m.renderWelcomePanel(width, height)
 	}
+	if m.activeView == "raw-json" && m.jsonExplorer != nil {
+		return m.renderJSONExplorer(width, height)
+	}
+	if m.activeView == "metrics" && m.metricsView != nil {
+		return m.renderMetricsExplorer(width, height)
+	}
+	if content := m.resourceViewContent(); content != "" {
+		return content
+	}
 
 	// Remove dashboard logic: skip dashboard loading/progress/data
 	// Only show original resource details or welcome panel
@@ -4080,11 +7551,11 @@ func (m model) renderEnhancedResourceDetails(width, height int) string {
 		content.WriteString("\n")
 
 		actionStyle := lipgloss.NewStyle().Foreground(colorBlue)
-		content.WriteString(fmt.Sprintf("%s Start VM\n", actionStyle.Render("[s]")))
-		content.WriteString(fmt.Sprintf("%s Stop VM\n", actionStyle.Render("[S]")))
-		content.WriteString(fmt.Sprintf("%s Restart VM\n", actionStyle.Render("[r]")))
-		content.WriteString(fmt.Sprintf("%s SSH Connect\n", actionStyle.Render("[c]")))
-		content.WriteString(fmt.Sprintf("%s Bastion Connect\n", actionStyle.Render("[b]")))
+		content.WriteString(fmt.Sprintf("%s Start VM\n", actionStyle.Render(m.actionKey(keymap.ActionStart))))
+		content.WriteString(fmt.Sprintf("%s Stop VM\n", actionStyle.Render(m.actionKey(keymap.ActionStop))))
+		content.WriteString(fmt.Sprintf("%s Restart VM\n", actionStyle.Render(m.actionKey(keymap.ActionRestart))))
+		content.WriteString(fmt.Sprintf("%s SSH Connect\n", actionStyle.Render(m.actionKey(keymap.ActionSSH))))
+		content.WriteString(fmt.Sprintf("%s Bastion Connect\n", actionStyle.Render(m.actionKey(keymap.ActionBastion))))
 
 		if m.actionInProgress {
 			progressStyle := lipgloss.NewStyle().Foreground(colorYellow)
@@ -4113,12 +7584,12 @@ func (m model) renderEnhancedResourceDetails(width, height int) string {
 		content.WriteString("\n")
 
 		actionStyle := lipgloss.NewStyle().Foreground(colorBlue)
-		content.WriteString(fmt.Sprintf("%s Start Cluster\n", actionStyle.Render("[s]")))
-		content.WriteString(fmt.Sprintf("%s Stop Cluster\n", actionStyle.Render("[S]")))
-		content.WriteString(fmt.Sprintf("%s List Pods\n", actionStyle.Render("[p]")))
-		content.WriteString(fmt.Sprintf("%s List Deployments\n", actionStyle.Render("[D]")))
-		content.WriteString(fmt.Sprintf("%s List Nodes\n", actionStyle.Render("[n]")))
-		content.WriteString(fmt.Sprintf("%s List Services\n", actionStyle.Render("[v]")))
+		content.WriteString(fmt.Sprintf("%s Start Cluster\n", actionStyle.Render(m.actionKey(keymap.ActionStart))))
+		content.WriteString(fmt.Sprintf("%s Stop Cluster\n", actionStyle.Render(m.actionKey(keymap.ActionStop))))
+		content.WriteString(fmt.Sprintf("%s List Pods\n", actionStyle.Render(m.actionKey(keymap.ActionAKSPods))))
+		content.WriteString(fmt.Sprintf("%s List Deployments\n", actionStyle.Render(m.actionKey(keymap.ActionAKSDeployments))))
+		content.WriteString(fmt.Sprintf("%s List Nodes\n", actionStyle.Render(m.actionKey(keymap.ActionAKSNodes))))
+		content.WriteString(fmt.Sprintf("%s List Services\n", actionStyle.Render(m.actionKey(keymap.ActionAKSServices))))
 
 		if m.actionInProgress {
 			progressStyle := lipgloss.NewStyle().Foreground(colorYellow)
@@ -4147,14 +7618,14 @@ func (m model) renderEnhancedResourceDetails(width, height int) string {
 		content.WriteString("\n")
 
 		actionStyle := lipgloss.NewStyle().Foreground(colorBlue)
-		content.WriteString(fmt.Sprintf("%s Start Container Instance\n", actionStyle.Render("[s]")))
-		content.WriteString(fmt.Sprintf("%s Stop Container Instance\n", actionStyle.Render("[S]")))
-		content.WriteString(fmt.Sprintf("%s Restart Container Instance\n", actionStyle.Render("[r]")))
-		content.WriteString(fmt.Sprintf("%s Get Container Logs\n", actionStyle.Render("[L]")))
-		content.WriteString(fmt.Sprintf("%s Exec into Container\n", actionStyle.Render("[E]")))
-		content.WriteString(fmt.Sprintf("%s Attach to Container\n", actionStyle.Render("[a]")))
-		content.WriteString(fmt.Sprintf("%s Scale Container Resources\n", actionStyle.Render("[u]")))
-		content.WriteString(fmt.Sprintf("%s Show Detailed Info\n", actionStyle.Render("[I]")))
+		content.WriteString(fmt.Sprintf("%s Start Container Instance\n", actionStyle.Render(m.actionKey(keymap.ActionStart))))
+		content.WriteString(fmt.Sprintf("%s Stop Container Instance\n", actionStyle.Render(m.actionKey(keymap.ActionStop))))
+		content.WriteString(fmt.Sprintf("%s Restart Container Instance\n", actionStyle.Render(m.actionKey(keymap.ActionRestart))))
+		content.WriteString(fmt.Sprintf("%s Get Container Logs\n", actionStyle.Render(m.actionKey(keymap.ActionContainerLogs))))
+		content.WriteString(fmt.Sprintf("%s Exec into Container\n", actionStyle.Render(m.actionKey(keymap.ActionContainerExec))))
+		content.WriteString(fmt.Sprintf("%s Attach to Container\n", actionStyle.Render(m.actionKey(keymap.ActionAnalyze))))
+		content.WriteString(fmt.Sprintf("%s Scale Container Resources\n", actionStyle.Render(m.actionKey(keymap.ActionContainerScale))))
+		content.WriteString(fmt.Sprintf("%s Show Detailed Info\n", actionStyle.Render(m.actionKey(keymap.ActionContainerDetails))))
 
 		if m.actionInProgress {
 			progressStyle := lipgloss.NewStyle().Foreground(colorYellow)
@@ -4183,9 +7654,9 @@ func (m model) renderEnhancedResourceDetails(width, height int) string {
 		content.WriteString("\n")
 
 		actionStyle := lipgloss.NewStyle().Foreground(colorBlue)
-		content.WriteString(fmt.Sprintf("%s List Secrets\n", actionStyle.Render("[K]")))
-		content.WriteString(fmt.Sprintf("%s Create Secret\n", actionStyle.Render("[Shift+K]")))
-		content.WriteString(fmt.Sprintf("%s Delete Secret\n", actionStyle.Render("[Ctrl+D]")))
+		content.WriteString(fmt.Sprintf("%s List Secrets\n", actionStyle.Render(m.actionKey(keymap.ActionListSecrets))))
+		content.WriteString(fmt.Sprintf("%s Create Secret\n", actionStyle.Render(m.actionKey(keymap.ActionCreate))))
+		content.WriteString(fmt.Sprintf("%s Delete Secret\n", actionStyle.Render(m.actionKey(keymap.ActionDeleteSecret))))
 
 		if m.actionInProgress {
 			progressStyle := lipgloss.NewStyle().Foreground(colorYellow)
@@ -4214,11 +7685,11 @@ func (m model) renderEnhancedResourceDetails(width, height int) string {
 		content.WriteString("\n")
 
 		actionStyle := lipgloss.NewStyle().Foreground(colorBlue)
-		content.WriteString(fmt.Sprintf("%s List Containers\n", actionStyle.Render("[T]")))
-		content.WriteString(fmt.Sprintf("%s Create Container\n", actionStyle.Render("[Shift+T]")))
-		content.WriteString(fmt.Sprintf("%s List Blobs\n", actionStyle.Render("[B]")))
-		content.WriteString(fmt.Sprintf("%s Upload Blob\n", actionStyle.Render("[U]")))
-		content.WriteString(fmt.Sprintf("%s Delete Storage Item\n", actionStyle.Render("[Ctrl+X]")))
+		content.WriteString(fmt.Sprintf("%s List Containers\n", actionStyle.Render(m.actionKey(keymap.ActionListContainers))))
+		content.WriteString(fmt.Sprintf("%s Create Container\n", actionStyle.Render(m.actionKey(keymap.ActionCreateContainer))))
+		content.WriteString(fmt.Sprintf("%s List Blobs\n", actionStyle.Render(m.actionKey(keymap.ActionListBlobs))))
+		content.WriteString(fmt.Sprintf("%s Upload Blob\n", actionStyle.Render(m.actionKey(keymap.ActionUploadBlob))))
+		content.WriteString(fmt.Sprintf("%s Delete Storage Item\n", actionStyle.Render(m.actionKey(keymap.ActionDeleteItem))))
 
 		if m.actionInProgress {
 			progressStyle := lipgloss.NewStyle().Foreground(colorYellow)
@@ -4253,6 +7724,8 @@ func (m model) renderEnhancedResourceDetails(width, height int) string {
 		helpStyle := lipgloss.NewStyle().Faint(true).Foreground(colorGray)
 		content.WriteString(helpStyle.Render("💡 Tip: Press 'e' to expand complex properties like Agent Pools"))
 		content.WriteString("\n")
+		content.WriteString(helpStyle.Render("💡 Press " + m.actionKey(keymap.ActionRawJSON) + " to explore the full JSON of the resource"))
+		content.WriteString("\n")
 	}
 
 	// Footer with help text
@@ -4632,66 +8105,66 @@ func (m model) renderScrollableContentWithOffset(content string, maxHeight int,
 	return result
 }
 
-// createShortcutsMap creates a comprehensive keyboard shortcuts map for the help popup
-func createShortcutsMap() map[string]string {
-	return map[string]string{
-		// Navigation
-		"j/k ↑/↓": "Navigate up/down in tree",
-		"h/l ←/→": "Switch between panels",
-		"Space":   "Expand/collapse resource groups",
-		"Enter":   "Open resource in details panel",
-		"Tab":     "Switch between panels",
-		"e":       "Expand/collapse complex properties",
-
-		// Search
-		"/":      "Enter search mode",
-		"Escape": "Exit search mode",
-		"↑/↓":    "Navigate search results (in search mode)",
-
-		// Resource Actions
-		"s":       "Start resource (VMs, Containers)",
-		"S":       "Stop resource (VMs, Containers)",
-		"r":       "Restart resource (VMs, Containers)",
-		"shift+d": "Enhanced dashboard with real data",
-		"R":       "Refresh all data",
-
-		// Network Management
-		"N":      "Network Dashboard",
-		"V":      "VNet Details (for VNets)",
-		"G":      "NSG Details (for NSGs)",
-		"Z":      "Network Topology",
-		"A":      "AI Network Analysis",
-		"C":      "Create VNet",
-		"Ctrl+N": "Create NSG",
-		"Ctrl+S": "Create Subnet",
-		"Ctrl+P": "Create Public IP",
-		"Ctrl+L": "Create Load Balancer",
-
-		// Container Instance Management
-		"L": "Get Container Logs",
-		"E": "Exec into Container",
-		"a": "Attach to Container",
-		"u": "Scale Container Resources",
-		"I": "Container Instance Details",
-
-		// SSH & AKS Management
-		"c": "SSH Connect (VMs)",
-		"b": "Bastion Connect (VMs)",
-		"p": "List Pods (AKS)",
-		"y": "List Deployments (AKS)",
-		"n": "List Nodes (AKS)",
-		"v": "List Services (AKS)",
-
-		// Key Vault Management
-		"K":       "List Secrets (Key Vault)",
-		"shift+k": "Create Secret (Key Vault)",
-		"ctrl+d":  "Delete Secret (Key Vault)",
-
-		// Interface
-		"?":   "Show/hide this help",
-		"Esc": "Navigate back / Close dialogs",
-		"q":   "Quit application",
-	}
+// helpSectionHeadings are the titles and colors of the help popup sections
+var helpSectionHeadings = map[string]struct {
+	title string
+	color *lipgloss.Color // Points at a theme color so headings follow theme changes
+}{
+	keymap.CategoryNavigation:   {"🧭 Navigation:", &colorGreen},
+	keymap.CategoryLayout:       {"🪟 Layout:", &colorBlue},
+	keymap.CategoryJobs:         {"⏳ Background Jobs:", &colorYellow},
+	keymap.CategorySearch:       {"🔍 Search:", &colorYellow},
+	keymap.CategoryResource:     {"⚡ Resource Actions:", &colorAqua},
+	keymap.CategoryNetwork:      {"🌐 Network Management:", &colorBlue},
+	keymap.CategoryTerraform:    {"🏗️  Terraform Management:", &colorAqua},
+	keymap.CategoryDevOps:       {"⚒️ DevOps Management:", &colorBlue},
+	keymap.CategoryContainer:    {"🐳 Container Management:", &colorPurple},
+	keymap.CategorySSH:          {"🔐 SSH & AKS:", &colorYellow},
+	keymap.CategoryKeyVault:     {"🔑 Key Vault Management:", &colorGray},
+	keymap.CategoryStorage:      {"🗄️  Storage Management:", &colorGreen},
+	keymap.CategorySubscription: {"☁️ Subscription Management:", &colorAqua},
+	keymap.CategoryInterface:    {"🎮 Interface:", &colorGray},
+}
+
+// helpNotes are shown below the bindings of a help section. They cover keys
+// that can't be rebound and things that aren't keys at all.
+var helpNotes = map[string][]keymap.HelpRow{
+	keymap.CategorySearch: {
+		{Keys: "Enter", Description: "Execute search / Accept suggestion"},
+		{Keys: "Tab", Description: "Accept first suggestion"},
+		{Keys: "Advanced", Description: "type:vm location:eastus tag:env=prod"},
+		{Description: "tag:none matches untagged resources"},
+		{Description: "sub:<id> and *-api* wildcards narrow further"},
+		{Description: "NOT type:... / NOT tag:... exclude resources"},
+		{Keys: "? text", Description: "Ask in plain language, AI writes the query"},
+	},
+	keymap.CategoryTerraform: {
+		{Description: "• Browse Terraform projects"},
+		{Description: "• Analyze code"},
+		{Description: "• Execute operations"},
+		{Description: "• Create from templates"},
+	},
+	keymap.CategoryDevOps: {
+		{Description: "• Browse organizations"},
+		{Description: "• Manage projects"},
+		{Description: "• View pipelines"},
+		{Description: "• Execute operations"},
+	},
+	keymap.CategorySubscription: {
+		{Description: "• Switch Azure subscriptions"},
+		{Description: "• View tenant information"},
+		{Description: "• Change active context"},
+	},
+}
+
+// createShortcutsMap builds the help popup sections from the active keymap,
+// so rebound keys and presets are reflected in the help
+func createShortcutsMap(km *keymap.Keymap) []keymap.HelpSection {
+	sections := km.Help()
+	for i := range sections {
+		sections[i].Rows = append(sections[i].Rows, helpNotes[sections[i].Category]...)
+	}
+	return sections
 }
 
 // Terraform Commands
@@ -4889,72 +8362,97 @@ Provide specific, actionable recommendations focused on Azure cloud best practic
 
 func executeTerraformOperationCmd(operation string, workspacePath string) tea.Cmd {
 	return func() tea.Msg {
-		var result string
-		var err error
+		return runTerraformOperation(context.Background(), nil, operation, workspacePath)
+	}
+}
 
-		// Ensure we're in the correct directory
-		if _, statErr := os.Stat(workspacePath); os.IsNotExist(statErr) {
-			return terraformOperationMsg{
-				operation: operation,
-				result:    fmt.Sprintf("Directory not found: %s", workspacePath),
-				success:   false,
-			}
+// startTerraformJob runs a Terraform operation as a background job
+func (m *model) startTerraformJob(operation string, workspacePath string) tea.Cmd {
+	var result terraformOperationMsg
+	run := func(ctx context.Context, out io.Writer) (string, error) {
+		result = runTerraformOperation(ctx, out, operation, workspacePath)
+		if !result.success {
+			return "", errors.New(result.result)
 		}
-
-		switch operation {
-		case "init":
-			// Use the terraform package function
-			err = terraform.InitWorkspace(workspacePath)
-			if err == nil {
-				result = "✅ Terraform initialized successfully"
-			}
-		case "plan":
-			// Use the terraform package function
-			result, err = terraform.PlanWorkspace(workspacePath, "")
-			if err == nil && result != "" {
-				result = "✅ Terraform plan completed successfully:\n" + result
-			}
-		case "apply":
-			// Use the terraform package function
-			result, err = terraform.ApplyWorkspace(workspacePath, "", true)
-			if err == nil && result != "" {
-				result = "✅ Terraform apply completed successfully:\n" + result
-			}
-		case "destroy":
-			// Use the terraform package function
-			result, err = terraform.DestroyWorkspace(workspacePath, "", true)
-			if err == nil && result != "" {
-			}
-		case "show":
-			// Show current state or plan
-			op, showErr := tfbicep.TerraformShow(workspacePath)
-			if showErr != nil {
-				err = showErr
-			} else {
-				result = "📋 Terraform show output:\n" + op.Output
-			}
-		case "state":
-			// Show state list
-			op, stateErr := tfbicep.TerraformState(workspacePath, "list", []string{})
-			if stateErr != nil {
-				err = stateErr
-			} else {
-				result = "📊 Terraform state resources:\n" + op.Output
-			}
-		default:
-			err = fmt.Errorf("unknown operation: %s", operation)
+		return fmt.Sprintf("Terraform %s completed", operation), nil
+	}
+	done := func(job jobs.Job) tea.Msg {
+		if job.Status == jobs.Cancelled {
+			result = terraformOperationMsg{operation: operation, result: fmt.Sprintf("❌ %s cancelled", operation), success: false}
 		}
+		return result
+	}
+	return m.startJob(fmt.Sprintf("terraform %s %s", operation, filepath.Base(workspacePath)), run, done)
+}
 
-		success := err == nil
-		if err != nil {
-			result = fmt.Sprintf("❌ %s failed: %v", operation, err)
-		}
+// runTerraformOperation runs a Terraform operation in workspacePath. The
+// operations that run as jobs stream their output to out.
+func runTerraformOperation(ctx context.Context, out io.Writer, operation string, workspacePath string) terraformOperationMsg {
+	var result string
+	var err error
 
+	// Ensure we're in the correct directory
+	if _, statErr := os.Stat(workspacePath); os.IsNotExist(statErr) {
 		return terraformOperationMsg{
 			operation: operation,
-			result:    result,
-			success:   success,
+			result:    fmt.Sprintf("Directory not found: %s", workspacePath),
+			success:   false,
+		}
+	}
+
+	switch operation {
+	case "init":
+		// Use the terraform package function
+		err = terraform.InitWorkspaceContext(ctx, out, workspacePath)
+		if err == nil {
+			result = "✅ Terraform initialized successfully"
+		}
+	case "plan":
+		// Use the terraform package function
+		result, err = terraform.PlanWorkspaceContext(ctx, out, workspacePath, "")
+		if err == nil && result != "" {
+			result = "✅ Terraform plan completed successfully:\n" + result
+		}
+	case "apply":
+		// Use the terraform package function
+		result, err = terraform.ApplyWorkspaceContext(ctx, out, workspacePath, "", true)
+		if err == nil && result != "" {
+			result = "✅ Terraform apply completed successfully:\n" + result
+		}
+	case "destroy":
+		// Use the terraform package function
+		result, err = terraform.DestroyWorkspaceContext(ctx, out, workspacePath, "", true)
+		if err == nil && result != "" {
+		}
+	case "show":
+		// Show current state or plan
+		op, showErr := tfbicep.TerraformShow(workspacePath)
+		if showErr != nil {
+			err = showErr
+		} else {
+			result = "📋 Terraform show output:\n" + op.Output
+		}
+	case "state":
+		// Show state list
+		op, stateErr := tfbicep.TerraformState(workspacePath, "list", []string{})
+		if stateErr != nil {
+			err = stateErr
+		} else {
+			result = "📊 Terraform state resources:\n" + op.Output
 		}
+	default:
+		err = fmt.Errorf("unknown operation: %s", operation)
+	}
+
+	success := err == nil
+	if err != nil {
+		result = fmt.Sprintf("❌ %s failed: %v", operation, err)
+	}
+
+	return terraformOperationMsg{
+		operation: operation,
+		result:    result,
+		success:   success,
 	}
 }
 
@@ -5405,13 +8903,15 @@ func loadSettingsConfigCmd() tea.Cmd {
 		content += fmt.Sprintf("  Show Terraform Menu: %t\n", cfg.UI.ShowTerraformMenu)
 		content += fmt.Sprintf("  Popup Width: %d\n", cfg.UI.PopupWidth)
 		content += fmt.Sprintf("  Popup Height: %d\n", cfg.UI.PopupHeight)
-		content += fmt.Sprintf("  Enable Mouse Support: %t\n", cfg.UI.EnableMouseSupport)
+		content += fmt.Sprintf("  Enable Mouse Support: %t\n", *config.GetUIConfig().EnableMouseSupport)
 		content += fmt.Sprintf("\n📝 Editor:\n")
 		content += fmt.Sprintf("  Default Editor: %s\n", cfg.Editor.DefaultEditor)
 		content += fmt.Sprintf("  Temp Directory: %s\n", cfg.Editor.TempDir)
 
+		// The settings edit a copy, the loaded config is shared
+		edited := *cfg
 		return settingsConfigLoadedMsg{
-			config:  cfg,
+			config:  &edited,
 			content: content,
 		}
 	}
@@ -5444,10 +8944,30 @@ func loadSettingsFoldersCmd() tea.Cmd {
 	}
 }
 
-// saveSettingsConfigCmd saves the current configuration
+// saveColorSchemeCmd persists the chosen theme
+func saveColorSchemeCmd(name string) tea.Cmd {
+	return func() tea.Msg {
+		if err := config.SaveColorScheme(name); err != nil {
+			return settingsConfigSavedMsg{
+				success: false,
+				message: fmt.Sprintf("Failed to save theme: %v", err),
+			}
+		}
+
+		return settingsConfigSavedMsg{
+			success: true,
+			message: fmt.Sprintf("Theme set to %s", name),
+		}
+	}
+}
+
+// saveSettingsConfigCmd saves the settings changed in the settings popup.
+// The theme is saved when it is chosen; the rest of the configuration is
+// left as it is on disk, so that tabs or bookmarks saved since the popup
+// opened are kept.
 func saveSettingsConfigCmd(cfg *config.AppConfig) tea.Cmd {
 	return func() tea.Msg {
-		err := config.SaveConfig(cfg)
+		err := config.SaveTerraformWorkspace(cfg.Terraform.WorkspacePath)
 		if err != nil {
 			return settingsConfigSavedMsg{
 				success: false,
@@ -5466,7 +8986,7 @@ func getCurrentSubscription() (*Subscription, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, "az", "account", "show", "--output", "json")
+	cmd := azcli.CommandContext(ctx, "account", "show", "--output", "json")
 	output, err := cmd.Output()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get current subscription: %v", err)
@@ -5495,7 +9015,7 @@ func setCurrentSubscription(subscriptionID string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, "az", "account", "set", "--subscription", subscriptionID)
+	cmd := azcli.CommandContext(ctx, "account", "set", "--subscription", subscriptionID)
 	err := cmd.Run()
 	if err != nil {
 		return fmt.Errorf("failed to set current subscription: %v", err)
@@ -5560,7 +9080,11 @@ func main() {
 	}()
 
 	m := initModel()
-	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithMouseCellMotion())
+	options := []tea.ProgramOption{tea.WithAltScreen()}
+	if *config.GetUIConfig().EnableMouseSupport {
+		options = append(options, tea.WithMouseCellMotion())
+	}
+	p := tea.NewProgram(m, options...)
 	if _, err := p.Run(); err != nil {
 		fmt.Printf("Error starting Azure Dashboard: %v\n", err)
 	}
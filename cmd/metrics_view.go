@@ -0,0 +1,442 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/olafkfreund/azure-tui/internal/azure/metrics"
+	"github.com/olafkfreund/azure-tui/internal/azure/usage"
+	"github.com/olafkfreund/azure-tui/internal/theme"
+	"github.com/olafkfreund/azure-tui/internal/tui"
+)
+
+// How often an open metrics view reloads its charts
+const metricsRefreshInterval = time.Minute
+
+// Metrics charted at once
+const maxChartedMetrics = 4
+
+// metricsExplorer charts the Azure Monitor metrics of a resource
+type metricsExplorer struct {
+	resource    AzureResource
+	definitions []metrics.Definition
+	selected    []string // Metric names in the order they were picked
+	cursor      int      // In definitions
+	aggregation string
+	rangeIndex  int // In metrics.Ranges
+	series      []metrics.Series
+	end         time.Time // End of the charted range
+	loading     bool
+	err         string
+	generation  int // Results and refreshes of older queries are dropped
+
+	latest       map[string]usage.UsageMetric // Latest value of each metric, by name
+	latestErr    string
+	latestLoaded bool
+}
+
+// metricsRange returns the selected time range
+func (e *metricsExplorer) metricsRange() metrics.TimeRange {
+	return metrics.Ranges[e.rangeIndex]
+}
+
+// isSelected reports whether the metric is charted
+func (e *metricsExplorer) isSelected(name string) bool {
+	return slices.Contains(e.selected, name)
+}
+
+// definition returns the definition of a metric
+func (e *metricsExplorer) definition(name string) (metrics.Definition, bool) {
+	for _, definition := range e.definitions {
+		if definition.Name == name {
+			return definition, true
+		}
+	}
+	return metrics.Definition{}, false
+}
+
+// aggregations returns the aggregations every charted metric supports
+func (e *metricsExplorer) aggregations() []string {
+	var supported []string
+	for _, aggregation := range metrics.Aggregations {
+		all := true
+		for _, name := range e.selected {
+			if definition, ok := e.definition(name); ok && !definition.Supports(aggregation) {
+				all = false
+			}
+		}
+		if all {
+			supported = append(supported, aggregation)
+		}
+	}
+	return supported
+}
+
+// Metric messages
+type metricDefinitionsMsg struct {
+	resourceID  string
+	definitions []metrics.Definition
+	err         error
+}
+
+type metricsLoadedMsg struct {
+	resourceID string
+	generation int
+	series     []metrics.Series
+	end        time.Time
+	err        error
+}
+
+type metricsRefreshMsg struct {
+	resourceID string
+	generation int
+}
+
+type usageMetricsLoadedMsg struct {
+	resourceID string
+	metrics    []usage.UsageMetric
+	err        error
+}
+
+func loadMetricDefinitionsCmd(resourceID string) tea.Cmd {
+	return func() tea.Msg {
+		definitions, err := metrics.ListDefinitions(resourceID)
+		return metricDefinitionsMsg{resourceID: resourceID, definitions: definitions, err: err}
+	}
+}
+
+// loadUsageMetricsCmd reads the latest value of each metric of the list
+func loadUsageMetricsCmd(resourceID string, definitions []metrics.Definition) tea.Cmd {
+	return func() tea.Msg {
+		usageMetrics, err := usage.ListUsageMetrics(resourceID, definitions)
+		return usageMetricsLoadedMsg{resourceID: resourceID, metrics: usageMetrics, err: err}
+	}
+}
+
+// loadMetricsCmd charts metrics with query, metrics.Query or metrics.Refresh
+func loadMetricsCmd(query func(string, []string, string, metrics.TimeRange, time.Time) ([]metrics.Series, error),
+	resourceID string, names []string, aggregation string, timeRange metrics.TimeRange, generation int) tea.Cmd {
+	return func() tea.Msg {
+		end := time.Now()
+		series, err := query(resourceID, names, aggregation, timeRange, end)
+		return metricsLoadedMsg{resourceID: resourceID, generation: generation, series: series, end: end, err: err}
+	}
+}
+
+// openMetricsExplorer shows the metrics view of the selected resource and
+// starts discovering its metrics
+func (m *model) openMetricsExplorer() tea.Cmd {
+	resource := m.selectedResource
+	if resource == nil {
+		return nil
+	}
+	m.metricsView = &metricsExplorer{resource: *resource, aggregation: "Average", rangeIndex: 1, loading: true}
+	m.selectedPanel = 1
+	m.rightPanelScrollOffset = 0
+	m.pushView("metrics")
+	return loadMetricDefinitionsCmd(resource.ID)
+}
+
+// queryMetrics reloads the charts after the selection changed
+func (m *model) queryMetrics() tea.Cmd {
+	return m.loadCharts(metrics.Query)
+}
+
+// refreshMetrics reloads the charts when their refresh interval is up
+func (m *model) refreshMetrics() tea.Cmd {
+	return m.loadCharts(metrics.Refresh)
+}
+
+// loadCharts queries the charted metrics with query
+func (m *model) loadCharts(query func(string, []string, string, metrics.TimeRange, time.Time) ([]metrics.Series, error)) tea.Cmd {
+	view := m.metricsView
+	view.generation++
+	if len(view.selected) == 0 {
+		view.series, view.loading = nil, false
+		return nil
+	}
+	view.loading = true
+	return loadMetricsCmd(query, view.resource.ID, view.selected, view.aggregation, view.metricsRange(), view.generation)
+}
+
+// defaultMetric picks the metric charted first: CPU where there is one,
+// otherwise the first metric
+func defaultMetric(definitions []metrics.Definition) string {
+	for _, definition := range definitions {
+		if strings.Contains(strings.ToLower(definition.Name), "cpu") {
+			return definition.Name
+		}
+	}
+	if len(definitions) > 0 {
+		return definitions[0].Name
+	}
+	return ""
+}
+
+// updateMetricsExplorer handles keys in the metrics view. Keys it does not
+// use are left to the normal key map.
+func (m model) updateMetricsExplorer(msg tea.KeyMsg) (tea.Model, tea.Cmd, bool) {
+	view := m.metricsView
+	switch key := msg.String(); key {
+	case "j", "down":
+		view.cursor = min(view.cursor+1, max(0, len(view.definitions)-1))
+	case "k", "up":
+		view.cursor = max(view.cursor-1, 0)
+	case "enter", " ", "space":
+		if len(view.definitions) == 0 {
+			return m, nil, true
+		}
+		name := view.definitions[view.cursor].Name
+		if i := slices.Index(view.selected, name); i >= 0 {
+			view.selected = slices.Delete(view.selected, i, i+1)
+		} else if len(view.selected) < maxChartedMetrics {
+			view.selected = append(view.selected, name)
+		} else {
+			m.addToast(fmt.Sprintf("Up to %d metrics can be charted at once", maxChartedMetrics), "notice")
+			return m, nil, true
+		}
+		if !slices.Contains(view.aggregations(), view.aggregation) {
+			if definition, ok := view.definition(name); ok && definition.Supports(definition.PrimaryAggregation) {
+				view.aggregation = definition.PrimaryAggregation
+			}
+		}
+		return m, m.queryMetrics(), true
+	case "a", "A":
+		aggregations := view.aggregations()
+		if len(aggregations) == 0 {
+			return m, nil, true
+		}
+		delta := 1
+		if key == "A" {
+			delta = len(aggregations) - 1
+		}
+		i := slices.Index(aggregations, view.aggregation)
+		view.aggregation = aggregations[(i+delta+len(aggregations))%len(aggregations)]
+		return m, m.queryMetrics(), true
+	case "t", "T":
+		delta := 1
+		if key == "T" {
+			delta = len(metrics.Ranges) - 1
+		}
+		view.rangeIndex = (view.rangeIndex + delta) % len(metrics.Ranges)
+		return m, m.queryMetrics(), true
+	case "1", "2", "3", "4":
+		view.rangeIndex = int(key[0]-'1') % len(metrics.Ranges)
+		return m, m.queryMetrics(), true
+	case "r":
+		view.latestLoaded = false
+		return m, tea.Batch(m.queryMetrics(), loadUsageMetricsCmd(view.resource.ID, view.definitions)), true
+	default:
+		return m, nil, false
+	}
+	return m, nil, true
+}
+
+// updateMetrics handles the metric messages
+func (m model) updateMetrics(msg tea.Msg) (tea.Model, tea.Cmd) {
+	view := m.metricsView
+	switch msg := msg.(type) {
+	case metricDefinitionsMsg:
+		if view == nil || view.resource.ID != msg.resourceID {
+			return m, nil
+		}
+		view.loading = false
+		if msg.err != nil {
+			view.err = msg.err.Error()
+			return m, nil
+		}
+		view.definitions, view.err = msg.definitions, ""
+		if name := defaultMetric(msg.definitions); name != "" {
+			view.selected = []string{name}
+			view.cursor = slices.IndexFunc(msg.definitions, func(d metrics.Definition) bool { return d.Name == name })
+			if definition, ok := view.definition(name); ok {
+				view.aggregation = definition.PrimaryAggregation
+			}
+		}
+		return m, tea.Batch(m.queryMetrics(), loadUsageMetricsCmd(msg.resourceID, msg.definitions))
+
+	case usageMetricsLoadedMsg:
+		if view == nil || view.resource.ID != msg.resourceID {
+			return m, nil
+		}
+		// Metrics whose request failed are marked, the others still shown
+		view.latest, view.latestLoaded, view.latestErr = map[string]usage.UsageMetric{}, true, ""
+		for _, metric := range msg.metrics {
+			view.latest[metric.Metric] = metric
+		}
+		if msg.err != nil {
+			view.latestErr = msg.err.Error()
+		}
+		return m, nil
+
+	case metricsLoadedMsg:
+		if view == nil || view.resource.ID != msg.resourceID || view.generation != msg.generation {
+			return m, nil
+		}
+		view.loading = false
+		if msg.err != nil {
+			view.err = msg.err.Error()
+		} else {
+			view.series, view.end, view.err = msg.series, msg.end, ""
+		}
+		generation := view.generation
+		return m, tea.Tick(metricsRefreshInterval, func(time.Time) tea.Msg {
+			return metricsRefreshMsg{resourceID: msg.resourceID, generation: generation}
+		})
+
+	case metricsRefreshMsg:
+		// Only an open view keeps refreshing
+		if view == nil || m.activeView != "metrics" || view.resource.ID != msg.resourceID || view.generation != msg.generation {
+			return m, nil
+		}
+		return m, m.refreshMetrics()
+	}
+	return m, nil
+}
+
+// renderMetricsExplorer draws the charts of the selected metrics, grouped
+// by unit, and the list of the resource's metrics
+func (m model) renderMetricsExplorer(width, height int) string {
+	view := m.metricsView
+	textWidth := max(30, width-4)
+
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(colorBlue)
+	labelStyle := lipgloss.NewStyle().Foreground(colorGray)
+	faint := lipgloss.NewStyle().Foreground(colorGray).Italic(true)
+	selectedStyle := lipgloss.NewStyle().Foreground(colorYellow).Bold(true)
+
+	var lines []string
+	lines = append(lines, headerStyle.Render("📈 Metrics: "+view.resource.Name))
+
+	var ranges []string
+	for i, r := range metrics.Ranges {
+		if i == view.rangeIndex {
+			ranges = append(ranges, selectedStyle.Render("["+r.Label+"]"))
+		} else {
+			ranges = append(ranges, labelStyle.Render(r.Label))
+		}
+	}
+	status := ""
+	switch {
+	case view.loading:
+		status = "⏳ Loading..."
+	case !view.end.IsZero():
+		status = labelStyle.Render("Updated " + view.end.Local().Format("15:04:05"))
+	}
+	lines = append(lines, truncateText(labelStyle.Render("Range: ")+strings.Join(ranges, " ")+labelStyle.Render("   Aggregation: ")+view.aggregation+"   "+status, textWidth))
+	if view.err != "" {
+		lines = append(lines, lipgloss.NewStyle().Foreground(colorRed).Render(truncateText("❌ "+view.err, textWidth)))
+	}
+	lines = append(lines, "")
+
+	// The metric list takes what the charts leave, at least five rows
+	available := height - 4
+	if m.renderTabBar() != "" {
+		available -= 2
+	}
+	listRows := min(len(view.definitions), max(5, available/3))
+	chartSpace := available - len(lines) - listRows - 4
+	if view.latestErr != "" {
+		chartSpace--
+	}
+
+	// One chart per unit, so that bytes and percentages get their own axis
+	var units []string
+	byUnit := map[string][]metrics.Series{}
+	for _, series := range view.series {
+		if _, ok := byUnit[series.Unit]; !ok {
+			units = append(units, series.Unit)
+		}
+		byUnit[series.Unit] = append(byUnit[series.Unit], series)
+	}
+	colors := []lipgloss.TerminalColor{colorAqua, colorYellow, colorPurple, colorGreen, colorRed, colorBlue}
+	timeLayout := "15:04"
+	if view.metricsRange().Duration > 24*time.Hour {
+		timeLayout = "Jan 02"
+	}
+	colorIndex := 0
+	for _, unit := range units {
+		group := byUnit[unit]
+		legendRows := len(group)
+		chartRows := max(3, (chartSpace/max(1, len(units)))-legendRows-3)
+
+		chart := tui.LineChart{
+			Start:      view.end.Add(-view.metricsRange().Duration),
+			End:        view.end,
+			Width:      textWidth,
+			Height:     chartRows,
+			FormatY:    func(v float64) string { return metrics.FormatValue(v, unit) },
+			TimeLayout: timeLayout,
+		}
+		var legend []string
+		for i, series := range group {
+			color := colors[colorIndex%len(colors)]
+			colorIndex++
+			chartSeries := tui.ChartSeries{Name: series.DisplayName, Color: color}
+			for _, point := range series.Points {
+				chartSeries.Points = append(chartSeries.Points, tui.ChartPoint{Time: point.Time, Value: point.Value, Valid: point.Valid})
+			}
+			chart.Series = append(chart.Series, chartSeries)
+
+			last := "no data"
+			if value, ok := series.Last(); ok {
+				last = metrics.FormatValue(value, series.Unit)
+			}
+			marker := theme.Icon("━━", string([]rune("*+ox#%")[i%6]))
+			legend = append(legend, lipgloss.NewStyle().Foreground(color).Render(marker)+" "+
+				truncateText(fmt.Sprintf("%s (%s)  last %s", series.DisplayName, series.Aggregation, last), textWidth-4))
+		}
+		lines = append(lines, strings.Split(chart.Render(), "\n")...)
+		lines = append(lines, legend...)
+		lines = append(lines, "")
+	}
+	if len(view.series) == 0 && !view.loading && view.err == "" {
+		if len(view.selected) == 0 {
+			lines = append(lines, faint.Render("Pick a metric below to chart it"), "")
+		} else {
+			lines = append(lines, faint.Render("No data points in this time range"), "")
+		}
+	}
+
+	// Metric list, scrolled to keep the cursor in view
+	header := headerStyle.Render(fmt.Sprintf("Metrics (%d)", len(view.definitions)))
+	if len(view.definitions) > 0 && !view.latestLoaded {
+		header += labelStyle.Render("  ⏳ Reading latest values...")
+	}
+	lines = append(lines, header)
+	if view.latestErr != "" {
+		lines = append(lines, lipgloss.NewStyle().Foreground(colorRed).Render(truncateText("❌ "+view.latestErr, textWidth)))
+	}
+	start := max(0, min(view.cursor-listRows/2, len(view.definitions)-listRows))
+	for i := start; i < min(len(view.definitions), start+listRows); i++ {
+		definition := view.definitions[i]
+		check := "[ ]"
+		if view.isSelected(definition.Name) {
+			check = "[x]"
+		}
+		marker := "  "
+		style := lipgloss.NewStyle().Foreground(fgMedium)
+		if i == view.cursor {
+			marker = theme.Icon("❯ ", "> ")
+			style = selectedStyle
+		}
+		text := fmt.Sprintf("%s%s %s", marker, check, definition.DisplayName)
+		if latest, ok := view.latest[definition.Name]; ok {
+			text += labelStyle.Render("  latest " + latest.Value)
+		} else if definition.Unit != "" {
+			text += labelStyle.Render("  " + definition.Unit)
+		}
+		lines = append(lines, style.Render(truncateText(text, textWidth)))
+	}
+	if len(view.definitions) == 0 && !view.loading {
+		lines = append(lines, faint.Render("This resource has no metrics"))
+	}
+
+	lines = append(lines, "", faint.Render(truncateText("j/k:Metric  Space:Chart  a:Aggregation  t/1-4:Range  r:Refresh  Esc:Back", textWidth)))
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,311 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/olafkfreund/azure-tui/internal/azure/usage"
+)
+
+// quotaView shows how much of the subscription's quotas a region uses
+type quotaView struct {
+	location  string
+	quotas    []usage.Quota
+	showAll   bool // Also quotas nothing uses yet
+	offset    int  // First row shown
+	loading   bool
+	err       string
+	inputMode bool // Typing the name of a region
+	input     string
+}
+
+// rows returns the quotas shown: those in use and the regional totals,
+// or all of them
+func (v *quotaView) rows() []usage.Quota {
+	if v.showAll {
+		return v.quotas
+	}
+	var rows []usage.Quota
+	for _, quota := range v.quotas {
+		if quota.Current > 0 || quota.Headline() {
+			rows = append(rows, quota)
+		}
+	}
+	return rows
+}
+
+type quotasLoadedMsg struct {
+	location string
+	quotas   []usage.Quota
+	err      error
+}
+
+func loadQuotasCmd(location string) tea.Cmd {
+	return func() tea.Msg {
+		quotas, err := usage.ListQuotas(location)
+		return quotasLoadedMsg{location: location, quotas: quotas, err: err}
+	}
+}
+
+// quotaLocations returns the regions the loaded resource groups and
+// resources are in
+func (m *model) quotaLocations() []string {
+	seen := map[string]bool{}
+	add := func(location string) {
+		location = strings.ToLower(strings.ReplaceAll(location, " ", ""))
+		if location != "" && location != "global" {
+			seen[location] = true
+		}
+	}
+	for _, group := range m.resourceGroups {
+		add(group.Location)
+	}
+	for _, resource := range m.allResources {
+		add(resource.Location)
+	}
+	return slices.Sorted(maps.Keys(seen))
+}
+
+// openQuotas shows the quotas of the selected resource's region, or of
+// the first region in use. Without one the region is asked for.
+func (m *model) openQuotas() tea.Cmd {
+	location := ""
+	if m.selectedResource != nil {
+		location = strings.ToLower(m.selectedResource.Location)
+	}
+	if location == "" || location == "global" {
+		if locations := m.quotaLocations(); len(locations) > 0 {
+			location = locations[0]
+		}
+	}
+
+	m.quotasView = &quotaView{location: location, inputMode: location == ""}
+	m.selectedPanel = 1
+	m.rightPanelScrollOffset = 0
+	m.pushView("quotas")
+	return m.loadQuotas(location)
+}
+
+// loadQuotas switches the view to a region and loads its quotas
+func (m *model) loadQuotas(location string) tea.Cmd {
+	view := m.quotasView
+	if location == "" {
+		return nil
+	}
+	view.location, view.quotas, view.offset = location, nil, 0
+	view.loading, view.err = true, ""
+	return loadQuotasCmd(location)
+}
+
+// updateQuotasView handles keys in the quotas view. Keys it does not use
+// are left to the normal key map.
+func (m model) updateQuotasView(msg tea.KeyMsg) (tea.Model, tea.Cmd, bool) {
+	view := m.quotasView
+	key := msg.String()
+
+	if view.inputMode {
+		switch key {
+		case "enter":
+			view.inputMode = false
+			location := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(view.input), " ", ""))
+			view.input = ""
+			return m, m.loadQuotas(location), true
+		case "esc":
+			view.inputMode, view.input = false, ""
+		case "backspace":
+			if runes := []rune(view.input); len(runes) > 0 {
+				view.input = string(runes[:len(runes)-1])
+			}
+		default:
+			if msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace {
+				view.input += string(msg.Runes)
+			}
+		}
+		return m, nil, true
+	}
+
+	rows := len(view.rows())
+	switch key {
+	case "j", "down":
+		view.offset = min(view.offset+1, max(0, rows-1))
+	case "k", "up":
+		view.offset = max(view.offset-1, 0)
+	case "pgdown":
+		view.offset = min(view.offset+10, max(0, rows-1))
+	case "pgup":
+		view.offset = max(view.offset-10, 0)
+	case "a":
+		view.showAll = !view.showAll
+		view.offset = 0
+	case "n", "p":
+		locations := m.quotaLocations()
+		if len(locations) == 0 {
+			return m, nil, true
+		}
+		delta := 1
+		if key == "p" {
+			delta = len(locations) - 1
+		}
+		i := slices.Index(locations, view.location)
+		if i < 0 {
+			i = len(locations) - delta
+		}
+		return m, m.loadQuotas(locations[(i+delta)%len(locations)]), true
+	case "L":
+		view.inputMode, view.input = true, ""
+	case "r":
+		return m, m.loadQuotas(view.location), true
+	default:
+		return m, nil, false
+	}
+	return m, nil, true
+}
+
+// updateQuotas handles the loaded quotas and warns about the ones close
+// to their limit
+func (m model) updateQuotas(msg quotasLoadedMsg) (tea.Model, tea.Cmd) {
+	view := m.quotasView
+	if view == nil || view.location != msg.location {
+		return m, nil
+	}
+	view.loading = false
+	view.quotas = msg.quotas
+	if msg.err != nil {
+		view.err = msg.err.Error()
+	}
+
+	critical := 0
+	for _, quota := range msg.quotas {
+		if quota.Level() == "critical" {
+			critical++
+		}
+	}
+	switch {
+	case critical == 1:
+		m.addToast(fmt.Sprintf("1 quota in %s is at %d%% or more of its limit", msg.location, usage.CriticalPercent), "failure")
+	case critical > 1:
+		m.addToast(fmt.Sprintf("%d quotas in %s are at %d%% or more of their limit", critical, msg.location, usage.CriticalPercent), "failure")
+	}
+	return m, nil
+}
+
+// renderQuotas draws the quotas of the region as gauges, the closest to
+// their limit first
+func (m model) renderQuotas(width, height int) string {
+	view := m.quotasView
+	textWidth := max(40, width-4)
+
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(colorBlue)
+	labelStyle := lipgloss.NewStyle().Foreground(colorGray)
+	faint := lipgloss.NewStyle().Foreground(colorGray).Italic(true)
+	levelStyles := map[string]lipgloss.Style{
+		"ok":       lipgloss.NewStyle().Foreground(colorGreen),
+		"warning":  lipgloss.NewStyle().Foreground(colorYellow),
+		"critical": lipgloss.NewStyle().Foreground(colorRed).Bold(true),
+	}
+
+	var lines []string
+	title := "📏 Quotas"
+	if view.location != "" {
+		title += ": " + view.location
+	}
+	if m.currentSubscription != nil {
+		title += labelStyle.Render("  (" + m.currentSubscription.Name + ")")
+	}
+	lines = append(lines, headerStyle.Render(title))
+
+	if view.inputMode {
+		lines = append(lines, labelStyle.Render("region> ")+view.input+"█")
+	} else {
+		var regions []string
+		for _, location := range m.quotaLocations() {
+			if location == view.location {
+				regions = append(regions, lipgloss.NewStyle().Foreground(colorYellow).Bold(true).Render("["+location+"]"))
+			} else {
+				regions = append(regions, labelStyle.Render(location))
+			}
+		}
+		if len(regions) > 0 {
+			lines = append(lines, truncateText(labelStyle.Render("Regions in use: ")+strings.Join(regions, " "), textWidth))
+		}
+	}
+
+	rows := view.rows()
+	counts := map[string]int{}
+	for _, quota := range view.quotas {
+		counts[quota.Level()]++
+	}
+	switch {
+	case view.loading:
+		lines = append(lines, "⏳ Loading quotas...")
+	case len(view.quotas) > 0:
+		summary := levelStyles["critical"].Render(fmt.Sprintf("%d critical (≥%d%%)", counts["critical"], usage.CriticalPercent)) + "  " +
+			levelStyles["warning"].Render(fmt.Sprintf("%d warning (≥%d%%)", counts["warning"], usage.WarningPercent)) + "  " +
+			labelStyle.Render(fmt.Sprintf("%d of %d quotas shown", len(rows), len(view.quotas)))
+		lines = append(lines, summary)
+	}
+	if view.err != "" {
+		lines = append(lines, lipgloss.NewStyle().Foreground(colorRed).Render(truncateText("❌ "+view.err, textWidth)))
+	}
+	lines = append(lines, "")
+
+	if len(rows) == 0 && !view.loading && !view.inputMode {
+		switch {
+		case view.location == "":
+			lines = append(lines, faint.Render("Press L to pick a region"))
+		case len(view.quotas) > 0:
+			lines = append(lines, faint.Render("Nothing uses a quota in this region yet, press a to show all"))
+		case view.err == "":
+			lines = append(lines, faint.Render("No quotas found for this region"))
+		}
+	}
+
+	// Name, gauge and usage columns; the name takes what is left
+	gaugeWidth := 12
+	usageWidth := 20
+	nameWidth := max(16, textWidth-10-gaugeWidth-usageWidth-2)
+	if len(rows) > 0 {
+		lines = append(lines, labelStyle.Render(fmt.Sprintf("%-9s %-*s %-*s %s", "Provider", nameWidth, "Quota", gaugeWidth, "Usage", "Used / Limit")))
+	}
+
+	// Rows that fit below the header and above the footer; tabs take two lines
+	available := height - 4 - len(lines) - 3
+	if m.renderTabBar() != "" {
+		available -= 2
+	}
+	available = max(3, available)
+	view.offset = max(0, min(view.offset, len(rows)-available))
+	for _, quota := range rows[view.offset:min(len(rows), view.offset+available)] {
+		percent := quota.Percent()
+		filled := int(math.Round(math.Min(percent, 100) / 100 * float64(gaugeWidth)))
+		style := levelStyles[quota.Level()]
+		gauge := style.Render(strings.Repeat("█", filled)) + labelStyle.Render(strings.Repeat("░", gaugeWidth-filled))
+		usageText := fmt.Sprintf("%3.0f%%  %s / %s", percent, formatQuotaValue(quota.Current), formatQuotaValue(quota.Limit))
+		name := truncateText(quota.Name, nameWidth)
+		lines = append(lines, fmt.Sprintf("%-9s %s%s %s %s",
+			quota.Provider, name, strings.Repeat(" ", nameWidth-ansi.StringWidth(name)), gauge, style.Render(usageText)))
+	}
+	if hidden := len(rows) - view.offset - available; hidden > 0 {
+		lines = append(lines, faint.Render(fmt.Sprintf("↓ %d more", hidden)))
+	}
+
+	footer := "j/k:Scroll  a:Show all  n/p:Region  L:Type region  r:Refresh  Esc:Back"
+	if view.inputMode {
+		footer = "Enter:Load  Esc:Cancel   e.g. westeurope"
+	}
+	lines = append(lines, "", faint.Render(truncateText(footer, textWidth)))
+	return strings.Join(lines, "\n")
+}
+
+// formatQuotaValue prints quota counts without decimals when they have none
+func formatQuotaValue(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
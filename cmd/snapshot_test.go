@@ -0,0 +1,724 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"github.com/olafkfreund/azure-tui/internal/azure/alerts"
+	"github.com/olafkfreund/azure-tui/internal/azure/diagnostics"
+	"github.com/olafkfreund/azure-tui/internal/azure/health"
+	"github.com/olafkfreund/azure-tui/internal/azure/keyvault"
+	"github.com/olafkfreund/azure-tui/internal/azure/loganalytics"
+	"github.com/olafkfreund/azure-tui/internal/azure/metrics"
+	"github.com/olafkfreund/azure-tui/internal/azure/network"
+	"github.com/olafkfreund/azure-tui/internal/azure/resourcedetails"
+	"github.com/olafkfreund/azure-tui/internal/azure/storage"
+	"github.com/olafkfreund/azure-tui/internal/azure/usage"
+	"github.com/olafkfreund/azure-tui/internal/config"
+	"github.com/olafkfreund/azure-tui/internal/jobs"
+	"github.com/olafkfreund/azure-tui/internal/theme"
+)
+
+// Run `go test ./cmd -run TestSnapshots -update` after an intended change
+// to the UI and review the diff of testdata/snapshots
+var update = flag.Bool("update", false, "rewrite the golden files in testdata/snapshots")
+
+// snapshotCase drives the model through steps and compares the screen with
+// testdata/snapshots/<name>.golden. Commands returned by the model are not
+// run: whatever the backend would answer is scripted as a message instead.
+type snapshotCase struct {
+	name          string
+	width, height int
+	plain         bool
+	steps         []any // Key names such as "j" or "ctrl+t", messages from the backend, or funcs that set up the model
+}
+
+func TestSnapshots(t *testing.T) {
+	backend := newFakeBackend()
+	vm, storageAccount, vault := backend.resources[0], backend.resources[1], backend.resources[2]
+
+	// Steps that load the resource groups and open the first one
+	openGroup := []any{
+		backend.groupsLoaded(),
+		"j", "j", "enter",
+		backend.resourcesLoaded("rg-prod"),
+	}
+
+	// Steps that bookmark a resource, a group in another subscription and a
+	// resource that is gone, then open the Favorites folder
+	openFavorites := []any{
+		backend.groupsLoaded(),
+		bookmarksUpdatedMsg{bookmarks: backend.bookmarks(), message: "loaded"},
+		"k", "enter",
+	}
+
+	cases := []snapshotCase{
+		{name: "welcome", width: 120, height: 40, steps: []any{backend.groupsLoaded()}},
+		{name: "tree", width: 120, height: 40, steps: openGroup},
+		{name: "tree-narrow", width: 80, height: 24, steps: openGroup},
+		{name: "details", width: 120, height: 40, steps: append(openGroup,
+			"j", backend.detailsLoaded(vm),
+		)},
+		{name: "details-focused", width: 120, height: 40, steps: append(openGroup,
+			"j", backend.detailsLoaded(vm), "tab",
+		)},
+		{name: "network-dashboard", width: 120, height: 40, steps: append(openGroup,
+			"N", backend.networkDashboardLoaded(),
+		)},
+		{name: "storage-containers", width: 120, height: 40, steps: append(openGroup,
+			"j", "j", backend.detailsLoaded(storageAccount), "T", backend.containersLoaded(),
+		)},
+		{name: "storage-blobs", width: 120, height: 40, steps: append(openGroup,
+			"j", "j", backend.detailsLoaded(storageAccount), "T", backend.containersLoaded(), backend.blobsLoaded(),
+		)},
+		{name: "keyvault-secrets", width: 120, height: 40, steps: append(openGroup,
+			"j", "j", "j", backend.detailsLoaded(vault), "K", backend.secretsLoaded(),
+		)},
+		{name: "popup-help", width: 120, height: 40, steps: []any{backend.groupsLoaded(), "?"}},
+		{name: "popup-command-palette", width: 120, height: 40, steps: append(openGroup,
+			"j", backend.detailsLoaded(vm), ":", "r", "e", "s", "t",
+		)},
+		{name: "popup-commands", width: 120, height: 40, steps: append(openGroup,
+			"j", backend.detailsLoaded(vm), "W", "j",
+		)},
+		{name: "popup-copy", width: 120, height: 40, steps: append(openGroup,
+			"j", backend.detailsLoaded(vm), "Y", "j",
+		)},
+		{name: "details-raw-json", width: 120, height: 40, steps: append(openGroup,
+			"j", backend.detailsLoaded(vm), "i", "j", "j", "j", "j", "l", "/", "n", "i", "c", "enter",
+		)},
+		{name: "details-raw-json-filter", width: 120, height: 40, steps: append(openGroup,
+			"j", backend.detailsLoaded(vm), "i", "f", "backspace", ".", "p", "r", "o", "p", "e", "r", "t", "i", "e", "s", ".", "n", "e", "t", "w", "o", "r", "k", "P", "r", "o", "f", "i", "l", "e", ".", "n", "e", "t", "w", "o", "r", "k", "I", "n", "t", "e", "r", "f", "a", "c", "e", "s", "[", "]", "enter", "j",
+		)},
+		{name: "metrics", width: 120, height: 40, steps: append(openGroup,
+			"j", backend.detailsLoaded(vm), localTimeUTC, "M", backend.metricDefinitionsLoaded(vm),
+			backend.metricsLoaded(vm, 1, "Percentage CPU"), "j", " ",
+			backend.metricsLoaded(vm, 2, "Percentage CPU", "Network In Total"), backend.usageMetricsLoaded(vm),
+		)},
+		{name: "quotas", width: 120, height: 40, steps: append(openGroup,
+			"j", backend.detailsLoaded(vm), "Q", backend.quotasLoaded(vm.Location),
+		)},
+		{name: "alerts", width: 120, height: 40, steps: append(openGroup,
+			"j", backend.detailsLoaded(vm), alertCountMsg{generation: 0, count: 2}, localTimeUTC, "!", backend.alertsLoaded(vm.ID), "enter",
+		)},
+		{name: "health", width: 120, height: 40, steps: append(openGroup,
+			"j", backend.detailsLoaded(vm), backend.healthLoaded(), localTimeUTC, "H", backend.healthHistoryLoaded(vm.ID), backend.serviceEventsLoaded(),
+			"j", "enter",
+		)},
+		{name: "diagnostics", width: 120, height: 40, steps: append(openGroup,
+			"P", backend.diagnosticsWorkspacesLoaded(), "j", "enter", backend.diagnosticsAudited("rg-prod"), "enter",
+		)},
+		{name: "diagnostics-preview", width: 120, height: 40, steps: append(openGroup,
+			"P", backend.diagnosticsWorkspacesLoaded(), "j", "enter", backend.diagnosticsAudited("rg-prod"), "p", backend.diagnosticsPreviewed(), "m",
+		)},
+		{name: "kql", width: 120, height: 40, steps: append(openGroup,
+			"j", backend.detailsLoaded(vm), "O", backend.kqlWorkspacesLoaded(), "ctrl+r", backend.kqlResult(), "l", "l", "s", "s", "j",
+		)},
+		{name: "popup-settings", width: 120, height: 40, steps: []any{backend.groupsLoaded(), ":", "s", "e", "t", "t", "i", "n", "g", "s", " ", "m", "e", "n", "u", "enter"}},
+		{name: "popup-subscriptions", width: 120, height: 40, steps: []any{backend.groupsLoaded(), "ctrl+a", backend.subscriptionsLoaded()}},
+		{name: "popup-terraform", width: 120, height: 40, steps: []any{backend.groupsLoaded(), "ctrl+t", terraformFoldersLoadedMsg{folders: []string{"infra/network", "infra/aks"}}}},
+		{name: "popup-devops", width: 120, height: 40, steps: []any{backend.groupsLoaded(), "ctrl+o"}},
+		{name: "bottom-panel", width: 120, height: 40, steps: append(openGroup, "`")},
+		{name: "favorites", width: 120, height: 40, steps: append(openFavorites,
+			backend.bookmarkLoaded(0), backend.bookmarkLoaded(1), backend.bookmarkLoaded(2), "j", "j", "enter",
+		)},
+		{name: "popup-bookmarks", width: 120, height: 40, steps: append(openFavorites,
+			subscriptionsLoadedMsg{subscriptions: backend.subscriptions()}, "'", "s", "h",
+		)},
+		{name: "bottom-jobs", width: 120, height: 40, steps: append(openGroup,
+			startJobs, jobsChangedMsg{}, "J",
+		)},
+		{name: "plain-tree", width: 120, height: 40, plain: true, steps: append(openGroup,
+			"j", backend.detailsLoaded(vm),
+		)},
+		{name: "plain-diagnostics", width: 120, height: 40, plain: true, steps: plainDiagnostics(backend, openGroup)},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := runSnapshot(t, c)
+			assertGolden(t, filepath.Join("testdata", "snapshots", c.name+".golden"), got)
+		})
+	}
+}
+
+// plainDiagnostics opens the diagnostic settings audit, whose states turn
+// into labels of different widths in plain mode
+func plainDiagnostics(backend *fakeBackend, openGroup []any) []any {
+	return append(openGroup,
+		"P", backend.diagnosticsWorkspacesLoaded(), "j", "enter", backend.diagnosticsAudited("rg-prod"),
+	)
+}
+
+// The columns after a state stay aligned when plain mode spells it out
+func TestPlainColumnsAlign(t *testing.T) {
+	backend := newFakeBackend()
+	screen := runSnapshot(t, snapshotCase{
+		width: 120, height: 40, plain: true,
+		steps: plainDiagnostics(backend, []any{
+			backend.groupsLoaded(),
+			"j", "j", "enter",
+			backend.resourcesLoaded("rg-prod"),
+		}),
+	})
+
+	columns := map[int]bool{}
+	for _, line := range strings.Split(screen, "\n") {
+		for _, state := range []string{"[ERROR] None", "[WARN] Elsewhere", "[OK] OK"} {
+			if i := strings.Index(line, state); i >= 0 {
+				rest := line[i+len(state):]
+				columns[i+len(state)+len(rest)-len(strings.TrimLeft(rest, " "))] = true
+			}
+		}
+	}
+	if len(columns) != 1 {
+		t.Errorf("Expected the resource names to start in one column, got columns %v in\n%s", columns, screen)
+	}
+}
+
+// runSnapshot plays the steps of c and returns the screen they leave
+func runSnapshot(t *testing.T, c snapshotCase) string {
+	t.Helper()
+
+	// Nothing from the machine running the tests may end up on the screen
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("TERM", "xterm-256color")
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("OPENAI_API_KEY", "")
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("USE_GITHUB_COPILOT", "false")
+	if c.plain {
+		t.Setenv("NO_COLOR", "1")
+	}
+	t.Cleanup(func() { theme.SetPlain(false) })
+
+	// Golden files hold the layout, not the escape sequences of the styles
+	lipgloss.SetColorProfile(termenv.Ascii)
+
+	var m tea.Model = initModel()
+	m, _ = m.Update(tea.WindowSizeMsg{Width: c.width, Height: c.height})
+	for i, step := range c.steps {
+		switch step := step.(type) {
+		case string:
+			m, _ = m.Update(keyPress(step))
+		case func(*testing.T, *model):
+			mm := m.(model)
+			step(t, &mm)
+			m = mm
+		case tea.Msg:
+			m, _ = m.Update(step)
+		default:
+			t.Fatalf("step %d: unsupported step %v", i, step)
+		}
+	}
+
+	// Trailing spaces depend on padding only and make diffs hard to read
+	lines := strings.Split(m.View(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// startJobs starts a job that succeeds after 42 seconds and one that is
+// still running, on a clock that only moves when the test moves it
+func startJobs(t *testing.T, m *model) {
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	now := start
+	m.jobManager.Now = func() time.Time { return now }
+	result := func(job jobs.Job) tea.Msg { return resourceActionMsg{action: "start"} }
+
+	release := make(chan struct{})
+	m.startJob("Start vm-web-01", func(ctx context.Context, out io.Writer) (string, error) {
+		fmt.Fprintln(out, "Starting vm-web-01...")
+		<-release
+		return "VM 'vm-web-01' started successfully", nil
+	}, result)
+
+	now = start.Add(30 * time.Second)
+	m.startJob("Stop vm-web-02", func(ctx context.Context, out io.Writer) (string, error) {
+		fmt.Fprintln(out, "Deallocating vm-web-02...")
+		<-ctx.Done()
+		return "", ctx.Err()
+	}, result)
+	running := m.jobManager.Jobs()[1].ID
+	t.Cleanup(func() { m.jobManager.Cancel(running) })
+
+	now = start.Add(42 * time.Second)
+	close(release)
+	for !m.jobManager.Jobs()[0].Done() {
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// localTimeUTC shows times in UTC, so that goldens do not depend on the
+// time zone of the machine running the tests
+func localTimeUTC(t *testing.T, m *model) {
+	local := time.Local
+	time.Local = time.UTC
+	t.Cleanup(func() { time.Local = local })
+}
+
+// assertGolden compares got with the golden file at path, or rewrites the
+// file with -update
+func assertGolden(t *testing.T, path, got string) {
+	t.Helper()
+
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run with -update to create it)", err)
+	}
+	if got == string(want) {
+		return
+	}
+
+	gotLines, wantLines := strings.Split(got, "\n"), strings.Split(string(want), "\n")
+	for i := 0; i < max(len(gotLines), len(wantLines)); i++ {
+		var g, w string
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if g != w {
+			t.Errorf("%s differs from line %d:\nwant: %q\n got: %q\n\nfull output:\n%s\n(run with -update if the change is intended)", path, i+1, w, g, got)
+			return
+		}
+	}
+}
+
+// keyPress builds the message bubbletea sends for a key name as used in the
+// keymap
+func keyPress(key string) tea.KeyMsg {
+	keys := map[string]tea.KeyType{
+		"enter":     tea.KeyEnter,
+		"esc":       tea.KeyEsc,
+		"tab":       tea.KeyTab,
+		"backspace": tea.KeyBackspace,
+		"up":        tea.KeyUp,
+		"down":      tea.KeyDown,
+		"left":      tea.KeyLeft,
+		"right":     tea.KeyRight,
+		"ctrl+a":    tea.KeyCtrlA,
+		"ctrl+o":    tea.KeyCtrlO,
+		"ctrl+r":    tea.KeyCtrlR,
+		"ctrl+t":    tea.KeyCtrlT,
+		"ctrl+w":    tea.KeyCtrlW,
+	}
+	if keyType, ok := keys[key]; ok {
+		return tea.KeyMsg{Type: keyType}
+	}
+	if key == " " {
+		return tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+}
+
+// fakeBackend answers for Azure with fixed data
+type fakeBackend struct {
+	resources []AzureResource
+}
+
+func newFakeBackend() *fakeBackend {
+	return &fakeBackend{
+		resources: []AzureResource{
+			{
+				ID:            "/subscriptions/0000/resourceGroups/rg-prod/providers/Microsoft.Compute/virtualMachines/vm-web-01",
+				Name:          "vm-web-01",
+				Type:          "Microsoft.Compute/virtualMachines",
+				Location:      "westeurope",
+				ResourceGroup: "rg-prod",
+				Status:        "Running",
+				Tags:          map[string]string{"env": "prod"},
+			},
+			{
+				ID:            "/subscriptions/0000/resourceGroups/rg-prod/providers/Microsoft.Storage/storageAccounts/stprodlogs",
+				Name:          "stprodlogs",
+				Type:          "Microsoft.Storage/storageAccounts",
+				Location:      "westeurope",
+				ResourceGroup: "rg-prod",
+			},
+			{
+				ID:            "/subscriptions/0000/resourceGroups/rg-prod/providers/Microsoft.KeyVault/vaults/kv-prod",
+				Name:          "kv-prod",
+				Type:          "Microsoft.KeyVault/vaults",
+				Location:      "westeurope",
+				ResourceGroup: "rg-prod",
+			},
+		},
+	}
+}
+
+func (b *fakeBackend) groupsLoaded() tea.Msg {
+	return resourceGroupsLoadedMsg{groups: []ResourceGroup{
+		{Name: "rg-prod", Location: "westeurope"},
+		{Name: "rg-dev", Location: "northeurope"},
+	}}
+}
+
+func (b *fakeBackend) resourcesLoaded(group string) tea.Msg {
+	var resources []AzureResource
+	for _, r := range b.resources {
+		if r.ResourceGroup == group {
+			resources = append(resources, r)
+		}
+	}
+	return resourcesInGroupMsg{groupName: group, resources: resources}
+}
+
+func (b *fakeBackend) detailsLoaded(r AzureResource) tea.Msg {
+	raw := fmt.Sprintf(`{
+  "id": %q,
+  "name": %q,
+  "type": %q,
+  "location": %q,
+  "resourceGroup": %q,
+  "tags": {"env": "prod", "cost-center": "42"},
+  "properties": {
+    "provisioningState": "Succeeded",
+    "hardwareProfile": {"vmSize": "Standard_D2s_v3"},
+    "networkProfile": {
+      "networkInterfaces": [
+        {"id": "/subscriptions/0000/resourceGroups/rg-prod/providers/Microsoft.Network/networkInterfaces/nic-1", "primary": true}
+      ]
+    },
+    "diskSizeGB": 128
+  }
+}`, r.ID, r.Name, r.Type, r.Location, r.ResourceGroup)
+	return resourceDetailsLoadedMsg{
+		resource: r,
+		details: &resourcedetails.ResourceDetails{
+			ID:            r.ID,
+			Name:          r.Name,
+			Type:          r.Type,
+			Location:      r.Location,
+			ResourceGroup: r.ResourceGroup,
+			Status:        r.Status,
+			Tags:          r.Tags,
+			CreatedTime:   "2024-01-15T09:30:00Z",
+			Raw:           []byte(raw),
+			Properties: map[string]interface{}{
+				"provisioningState": "Succeeded",
+			},
+		},
+	}
+}
+
+func (b *fakeBackend) metricDefinitionsLoaded(r AzureResource) tea.Msg {
+	return metricDefinitionsMsg{resourceID: r.ID, definitions: []metrics.Definition{
+		{Name: "Percentage CPU", DisplayName: "Percentage CPU", Unit: "Percent", PrimaryAggregation: "Average", Aggregations: []string{"Average", "Maximum", "Minimum"}},
+		{Name: "Network In Total", DisplayName: "Network In Total", Unit: "Bytes", PrimaryAggregation: "Total", Aggregations: []string{"Average", "Maximum", "Minimum", "Total"}},
+		{Name: "Disk Read Bytes", DisplayName: "Disk Read Bytes", Unit: "Bytes", PrimaryAggregation: "Total"},
+		{Name: "Available Memory Bytes", DisplayName: "Available Memory Bytes", Unit: "Bytes", PrimaryAggregation: "Average"},
+	}}
+}
+
+// usageMetricsLoaded answers the latest values of the metrics, one of
+// whose requests failed
+func (b *fakeBackend) usageMetricsLoaded(r AzureResource) tea.Msg {
+	return usageMetricsLoadedMsg{resourceID: r.ID, metrics: []usage.UsageMetric{
+		{Metric: "Percentage CPU", Name: "Percentage CPU", Value: "12.5%", Unit: "Percent", Latest: 12.5, Found: true},
+		{Metric: "Network In Total", Name: "Network In Total", Value: "2.8 MB", Unit: "Bytes", Latest: 2.8e6, Found: true},
+		{Metric: "Disk Read Bytes", Name: "Disk Read Bytes", Value: "unavailable", Unit: "Bytes"},
+		{Metric: "Available Memory Bytes", Name: "Available Memory Bytes", Value: "no data", Unit: "Bytes"},
+	}, err: fmt.Errorf("1 of 2 metric requests failed: Disk Read Bytes: failed to query metrics: exit status 1")}
+}
+
+// metricsLoaded answers a metrics query over the 24 hours up to a fixed
+// time with a daily curve per metric
+func (b *fakeBackend) metricsLoaded(r AzureResource, generation int, names ...string) tea.Msg {
+	end := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	msg := metricsLoadedMsg{resourceID: r.ID, generation: generation, end: end}
+	for i, name := range names {
+		series := metrics.Series{Metric: name, DisplayName: name, Unit: "Percent", Aggregation: "Average"}
+		scale := 40.0
+		if strings.Contains(name, "Network") {
+			series.Unit, scale = "Bytes", 3<<20
+		}
+		for p := 0; p <= 96; p++ {
+			value := scale * (1.2 + math.Sin(float64(p)/96*2*math.Pi+float64(i))) / 2.2
+			// An hour without data
+			valid := p < 40 || p > 44
+			series.Points = append(series.Points, metrics.Point{Time: end.Add(-24*time.Hour + time.Duration(p)*15*time.Minute), Value: value, Valid: valid})
+		}
+		msg.series = append(msg.series, series)
+	}
+	return msg
+}
+
+// alertsLoaded answers the alerts of a scope: two open, one closed, and a
+// rule on it
+func (b *fakeBackend) alertsLoaded(scope string) tea.Msg {
+	fired := time.Date(2025, 6, 1, 9, 30, 0, 0, time.UTC)
+	alert := func(name, severity, state, condition string, age time.Duration) alerts.Alert {
+		return alerts.Alert{
+			ID:             "/subscriptions/sub-1/providers/Microsoft.AlertsManagement/alerts/" + name,
+			Name:           name,
+			Severity:       severity,
+			State:          state,
+			Condition:      condition,
+			TargetID:       scope,
+			TargetName:     scope[strings.LastIndex(scope, "/")+1:],
+			MonitorService: "Platform",
+			Rule:           "/subscriptions/sub-1/resourceGroups/rg-prod/providers/microsoft.insights/metricAlerts/" + name,
+			Fired:          fired.Add(-age),
+			Modified:       fired.Add(-age / 2),
+		}
+	}
+	return alertsLoadedMsg{
+		scope: scope,
+		alerts: []alerts.Alert{
+			alert("cpu-high", "Sev1", alerts.StateNew, "Fired", 0),
+			alert("disk-latency", "Sev3", alerts.StateAcknowledged, "Resolved", 5*time.Hour),
+			alert("memory-low", "Sev2", alerts.StateClosed, "Resolved", 48*time.Hour),
+		},
+		rules: []usage.Alarm{
+			{Name: "cpu-high", Status: "OK", Details: "Severity: 1, Window: PT5M, Metric: Percentage CPU"},
+			{Name: "disk-latency", Status: "Disabled", Details: "Severity: 3, Window: PT15M, Metric: OS Disk Latency"},
+		},
+	}
+}
+
+// healthLoaded answers the health of the resources: the VM down because of
+// Azure, the vault degraded by a change and the storage account fine
+func (b *fakeBackend) healthLoaded() tea.Msg {
+	return healthStatusesMsg{statuses: []health.Status{
+		{ResourceID: b.resources[0].ID, State: health.Unavailable, Cause: health.CausePlatform},
+		{ResourceID: b.resources[1].ID, State: health.Available},
+		{ResourceID: b.resources[2].ID, State: health.Degraded, Cause: health.CauseUser},
+	}}
+}
+
+// healthHistoryLoaded answers the current health of a resource and two
+// earlier events
+func (b *fakeBackend) healthHistoryLoaded(id string) tea.Msg {
+	at := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	return healthHistoryMsg{resourceID: id, statuses: []health.Status{
+		{ResourceID: id, State: health.Unavailable, Cause: health.CausePlatform, Reason: "Unplanned",
+			Summary:  "We're sorry, your virtual machine isn't available because of a host failure.",
+			Actions:  []string{"Redeploy the virtual machine to a new host"},
+			Occurred: at, Reported: at.Add(5 * time.Minute)},
+		{ResourceID: id, State: health.Available, Summary: "There aren't any known Azure platform problems affecting this virtual machine.",
+			Occurred: at.Add(-48 * time.Hour)},
+		{ResourceID: id, State: health.Unavailable, Cause: health.CauseUser, Reason: "UserInitiated",
+			Title: "Stopped and deallocated", Summary: "The virtual machine was stopped by a user.",
+			RootCauseTime: at.Add(-71 * time.Hour), Resolved: at.Add(-48 * time.Hour),
+			Occurred: at.Add(-72 * time.Hour)},
+	}}
+}
+
+// serviceEventsLoaded answers an active incident in the region of the VM
+// and a resolved one elsewhere
+func (b *fakeBackend) serviceEventsLoaded() tea.Msg {
+	at := time.Date(2025, 6, 1, 8, 45, 0, 0, time.UTC)
+	return serviceEventsMsg{events: []health.Event{
+		{TrackingID: "VN4K-1T8", Type: "ServiceIssue", Title: "Virtual Machines - West Europe - Connectivity issues", Active: true,
+			Services: []string{"Virtual Machines"}, Regions: []string{"West Europe"}, Started: at, Updated: at.Add(30 * time.Minute)},
+		{TrackingID: "ZT2P-9Q0", Type: "ServiceIssue", Title: "Storage - East US - Elevated latency",
+			Services: []string{"Storage"}, Regions: []string{"East US"}, Started: at.Add(-96 * time.Hour), Mitigated: at.Add(-90 * time.Hour),
+			Updated: at.Add(-90 * time.Hour), Summary: "Between 08:45 and 14:45 UTC a subset of customers saw elevated latency."},
+	}}
+}
+
+// kqlWorkspacesLoaded answers two workspaces, the second in the group of
+// the VM
+func (b *fakeBackend) kqlWorkspacesLoaded() tea.Msg {
+	return kqlWorkspacesMsg{workspaces: []loganalytics.Workspace{
+		{ID: "/subscriptions/sub-1/resourceGroups/rg-shared/providers/Microsoft.OperationalInsights/workspaces/log-shared", Name: "log-shared", ResourceGroup: "rg-shared", Location: "westeurope"},
+		{ID: "/subscriptions/sub-1/resourceGroups/rg-prod/providers/Microsoft.OperationalInsights/workspaces/log-prod", Name: "log-prod", ResourceGroup: "rg-prod", Location: "westeurope"},
+	}}
+}
+
+// diagnosticsWorkspacesLoaded answers the workspaces of the KQL console to
+// the diagnostics view
+func (b *fakeBackend) diagnosticsWorkspacesLoaded() tea.Msg {
+	return diagnosticsWorkspacesMsg{workspaces: b.kqlWorkspacesLoaded().(kqlWorkspacesMsg).workspaces}
+}
+
+// logProd is the central workspace of the diagnostics snapshots
+const logProd = "/subscriptions/sub-1/resourceGroups/rg-prod/providers/Microsoft.OperationalInsights/workspaces/log-prod"
+
+// diagnosticsAudited answers the audit of a scope: the VM without
+// settings, the storage account sending to another workspace and the
+// vault sending to log-prod
+func (b *fakeBackend) diagnosticsAudited(scopeName string) tea.Msg {
+	settings := [][]diagnostics.Setting{
+		nil,
+		{{Name: "to-shared", WorkspaceID: strings.Replace(logProd, "log-prod", "log-shared", 1),
+			StorageAccount: b.resources[1].ID, Metrics: []diagnostics.Entry{{Category: "Transaction", Enabled: true}}}},
+		{{Name: "central-workspace", WorkspaceID: logProd, Logs: []diagnostics.Entry{{CategoryGroup: "allLogs", Enabled: true}}}},
+	}
+	var audits []diagnostics.Audit
+	for i, r := range b.resources {
+		audits = append(audits, diagnostics.Audit{
+			Target:   diagnosticsTarget(r),
+			Settings: settings[i],
+			Status:   diagnostics.Classify(settings[i], logProd),
+		})
+	}
+	diagnostics.SortAudits(audits)
+	return diagnosticsAuditMsg{scopeName: scopeName, audits: audits}
+}
+
+// diagnosticsPreviewed answers the categories of the flagged resources:
+// the VM has metrics only, the storage account logs with the allLogs group
+func (b *fakeBackend) diagnosticsPreviewed() tea.Msg {
+	audits := b.diagnosticsAudited("").(diagnosticsAuditMsg).audits
+	template := diagnostics.Template{Name: "central-workspace", WorkspaceID: logProd, Logs: true, Metrics: true}
+	return diagnosticsPreviewMsg{changes: []diagnostics.Change{
+		template.Plan(audits[0], []diagnostics.Category{{Name: "AllMetrics", Type: "Metrics"}}),
+		template.Plan(audits[1], []diagnostics.Category{
+			{Name: "StorageRead", Type: "Logs", Groups: []string{"allLogs", "audit"}},
+			{Name: "Transaction", Type: "Metrics"},
+		}),
+	}}
+}
+
+// kqlResult answers a query with a time, a text and a number column
+func (b *fakeBackend) kqlResult() tea.Msg {
+	result := &loganalytics.Result{
+		Columns: []loganalytics.Column{{Name: "TimeGenerated", Type: "datetime"}, {Name: "Computer", Type: "string"}, {Name: "avg_CounterValue", Type: "real"}},
+	}
+	for i, value := range []string{"12.5", "87.25", "4", "", "33.1"} {
+		result.Rows = append(result.Rows, []string{fmt.Sprintf("2025-06-01T09:%02d:00Z", 30-5*i), "vm-web-01", value})
+	}
+	return kqlResultMsg{result: result, elapsed: 1200 * time.Millisecond}
+}
+
+// quotasLoaded answers the quotas of a region, one of them near its limit
+func (b *fakeBackend) quotasLoaded(location string) tea.Msg {
+	quotas := []usage.Quota{
+		{Provider: "Compute", ID: "cores", Name: "Total Regional vCPUs", Current: 92, Limit: 100},
+		{Provider: "Compute", ID: "standardDSv3Family", Name: "Standard DSv3 Family vCPUs", Current: 8, Limit: 8},
+		{Provider: "Compute", ID: "virtualMachines", Name: "Virtual Machines", Current: 12, Limit: 25000},
+		{Provider: "Compute", ID: "standardNCFamily", Name: "Standard NC Family vCPUs", Current: 0, Limit: 24},
+		{Provider: "Network", ID: "PublicIPAddresses", Name: "Public IP Addresses", Current: 41, Limit: 50},
+		{Provider: "Network", ID: "VirtualNetworks", Name: "Virtual Networks", Current: 3, Limit: 1000},
+		{Provider: "Storage", ID: "StorageAccounts", Name: "Storage Accounts", Current: 5, Limit: 250},
+	}
+	for i := range quotas {
+		quotas[i].Location, quotas[i].Unit = location, "Count"
+	}
+	usage.SortQuotas(quotas)
+	return quotasLoadedMsg{location: location, quotas: quotas}
+}
+
+func (b *fakeBackend) networkDashboardLoaded() tea.Msg {
+	dashboard := &network.NetworkDashboard{
+		VirtualNetworks: []network.VirtualNetwork{{
+			Name:          "vnet-prod",
+			Location:      "westeurope",
+			ResourceGroup: "rg-prod",
+			AddressSpace:  network.AddressSpace{AddressPrefixes: []string{"10.0.0.0/16"}},
+			Subnets: []network.Subnet{
+				{Name: "snet-web", AddressPrefix: "10.0.1.0/24", NSGName: "nsg-web"},
+				{Name: "snet-data", AddressPrefix: "10.0.2.0/24"},
+			},
+		}},
+		NetworkSecurityGroups: []network.NetworkSecurityGroup{{
+			Name:          "nsg-web",
+			Location:      "westeurope",
+			ResourceGroup: "rg-prod",
+			SecurityRules: []network.SecurityRule{{
+				Name: "allow-https", Priority: 100, Direction: "Inbound", Access: "Allow",
+				Protocol: "Tcp", DestinationPortRange: "443", SourceAddressPrefix: "*",
+			}},
+		}},
+		PublicIPs: []network.PublicIP{{
+			Name: "pip-web", Location: "westeurope", ResourceGroup: "rg-prod",
+			IPAddress: "20.0.0.10", AllocationMethod: "Static",
+		}},
+		Summary: network.NetworkSummary{TotalVNets: 1, TotalSubnets: 2, TotalNSGs: 1, TotalPublicIPs: 1},
+	}
+	return networkDashboardMsg{content: network.RenderLoadedNetworkDashboard(dashboard, nil)}
+}
+
+func (b *fakeBackend) containersLoaded() tea.Msg {
+	return storageContainersMsg{accountName: "stprodlogs", containers: []storage.Container{
+		{Name: "logs", LastModified: "2024-03-01T10:00:00Z", PublicAccess: "None"},
+		{Name: "backups", LastModified: "2024-02-11T08:15:00Z"},
+	}}
+}
+
+func (b *fakeBackend) blobsLoaded() tea.Msg {
+	return storageBlobsMsg{accountName: "stprodlogs", containerName: "logs", blobs: []storage.Blob{
+		{Name: "app/2024-03-01.log", Size: 48213, BlobType: "BlockBlob", ContentType: "text/plain", LastModified: "2024-03-01T23:59:00Z", AccessTier: "Hot"},
+		{Name: "audit.log", Size: 3 * 1024 * 1024, BlobType: "AppendBlob", LastModified: "2024-03-02T06:00:00Z"},
+	}}
+}
+
+func (b *fakeBackend) secretsLoaded() tea.Msg {
+	return keyVaultSecretsMsg{vaultName: "kv-prod", secrets: []keyvault.Secret{
+		{Name: "db-password", ID: "https://kv-prod.vault.azure.net/secrets/db-password", Enabled: true, ContentType: "text/plain"},
+		{Name: "old-api-key", ID: "https://kv-prod.vault.azure.net/secrets/old-api-key", Enabled: false},
+	}}
+}
+
+func (b *fakeBackend) subscriptions() []Subscription {
+	return []Subscription{
+		{ID: "00000000-0000-0000-0000-000000000001", Name: "Production", TenantID: "tenant", IsDefault: true},
+		{ID: "00000000-0000-0000-0000-000000000002", Name: "Development", TenantID: "tenant"},
+	}
+}
+
+func (b *fakeBackend) subscriptionsLoaded() tea.Msg {
+	return subscriptionMenuMsg{subscriptions: b.subscriptions()}
+}
+
+func (b *fakeBackend) bookmarks() []config.Bookmark {
+	vm := b.resources[0]
+	return []config.Bookmark{
+		{Name: vm.Name, Type: vm.Type, SubscriptionID: "00000000-0000-0000-0000-000000000001", ResourceID: vm.ID},
+		{
+			Name: "rg-shared", Type: "group", SubscriptionID: "00000000-0000-0000-0000-000000000002",
+			ResourceID: "/subscriptions/00000000-0000-0000-0000-000000000002/resourceGroups/rg-shared",
+		},
+		{
+			Name: "kv-retired", Type: "Microsoft.KeyVault/vaults", SubscriptionID: "00000000-0000-0000-0000-000000000002",
+			ResourceID: "/subscriptions/00000000-0000-0000-0000-000000000002/resourceGroups/rg-shared/providers/Microsoft.KeyVault/vaults/kv-retired",
+		},
+	}
+}
+
+// bookmarkLoaded answers the lookup of the i-th bookmark
+func (b *fakeBackend) bookmarkLoaded(i int) tea.Msg {
+	bookmark := b.bookmarks()[i]
+	msg := bookmarkLoadedMsg{resourceID: bookmark.ResourceID}
+	switch i {
+	case 0:
+		msg.resource = &b.resources[0]
+	case 1:
+		msg.resources = []AzureResource{{
+			ID:            bookmark.ResourceID + "/providers/Microsoft.Network/virtualNetworks/vnet-hub",
+			Name:          "vnet-hub",
+			Type:          "Microsoft.Network/virtualNetworks",
+			Location:      "westeurope",
+			ResourceGroup: "rg-shared",
+		}}
+	default:
+		msg.err = fmt.Errorf("resource not found")
+	}
+	return msg
+}
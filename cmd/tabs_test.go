@@ -0,0 +1,86 @@
+package main
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/olafkfreund/azure-tui/internal/azure/resourcedetails"
+	"github.com/olafkfreund/azure-tui/internal/config"
+)
+
+const tabsVMID = "/subscriptions/sub-1/resourceGroups/rg-prod/providers/Microsoft.Compute/virtualMachines/vm-web"
+
+// tabsModel starts the model in a temporary home whose config holds tabs
+func tabsModel(t *testing.T, tabs []config.OpenTab) model {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("OPENAI_API_KEY", "")
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("USE_GITHUB_COPILOT", "false")
+	if err := config.SaveConfig(&config.AppConfig{OpenTabs: tabs}); err != nil {
+		t.Fatal(err)
+	}
+	return initModel()
+}
+
+func TestRestoreTabs(t *testing.T) {
+	m := tabsModel(t, []config.OpenTab{
+		{Type: "resource", Title: "vm-web", View: "details", ResourceID: tabsVMID},
+		{Type: "monitor", Title: "Network", View: "network-dashboard"},
+	})
+
+	tabs := m.tabManager.Tabs
+	if len(tabs) != 3 || tabs[0].Title != "Overview" || tabs[0].Closable || m.tabManager.ActiveIndex != 0 {
+		t.Fatalf("restored tabs = %+v, active %d, want the overview first and active", tabs, m.tabManager.ActiveIndex)
+	}
+	if tabs[1].Title != "vm-web" || tabs[1].Meta["resourceID"] != tabsVMID || tabs[2].Meta["view"] != "network-dashboard" {
+		t.Errorf("restored tabs = %+v", tabs[1:])
+	}
+	for _, tab := range tabs[1:] {
+		if state := m.tabStates[tab.Meta["id"]]; state == nil || !state.needsReload {
+			t.Errorf("tab %q should be loaded when first shown", tab.Title)
+		}
+	}
+
+	// A restored tab loads its resource the first time it is shown only
+	if cmd := m.switchTab(1); cmd == nil || m.selectedResource == nil || m.selectedResource.ID != tabsVMID {
+		t.Fatalf("showing the restored tab selected %+v, want it loaded", m.selectedResource)
+	}
+	m.switchTab(-1)
+	if cmd := m.switchTab(1); cmd != nil {
+		t.Error("showing the tab again should not load it again")
+	}
+}
+
+func TestTabScopedResponses(t *testing.T) {
+	m := tabsModel(t, nil)
+	vm := AzureResource{ID: tabsVMID, Name: "vm-web", Type: "Microsoft.Compute/virtualMachines", ResourceGroup: "rg-prod"}
+	m.openTab("resource", vm.Name, "details", &vm)
+	background := m.activeTabID()
+	m.activateOverviewTab()
+
+	// The details arrive after the user went back to the overview
+	details := &resourcedetails.ResourceDetails{ID: vm.ID, Name: vm.Name}
+	loaded := func() tea.Msg { return resourceDetailsLoadedMsg{resource: vm, details: details} }
+	msg := tagTabCmd(background, loaded)()
+	if routed, ok := msg.(tabMsg); !ok || routed.tabID != background {
+		t.Fatalf("tagged response = %#v, want it routed to %s", msg, background)
+	}
+	updated, _ := m.Update(msg)
+	m = updated.(model)
+	if m.resourceDetails != nil || m.selectedResource != nil {
+		t.Errorf("the overview shows %+v, want only the background tab updated", m.selectedResource)
+	}
+	if state := m.tabStates[background]; state.resourceDetails != details {
+		t.Errorf("background tab details = %+v, want the response", state.resourceDetails)
+	}
+
+	// Responses for closed tabs are dropped and others are not routed
+	updated, _ = m.Update(tabMsg{tabID: "tab-closed", msg: loaded()})
+	if updated.(model).resourceDetails != nil {
+		t.Error("a response for a closed tab reached the active one")
+	}
+	if _, ok := tagTabCmd(background, func() tea.Msg { return tea.WindowSizeMsg{} })().(tabMsg); ok {
+		t.Error("a window resize should not be routed to a tab")
+	}
+}
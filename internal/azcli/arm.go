@@ -0,0 +1,80 @@
+package azcli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// armTimeout bounds each request to Azure Resource Manager
+const armTimeout = 30 * time.Second
+
+// ARMGet sends a GET request to Azure Resource Manager with az rest. The
+// URL may hold {subscriptionId}, which az rest fills in with the current
+// subscription.
+func ARMGet(requestURL string) ([]byte, error) {
+	return arm(CommandContext, "get", requestURL)
+}
+
+// ARMGetQuiet is ARMGet for polls, which are not recorded in the session
+// history
+func ARMGetQuiet(requestURL string) ([]byte, error) {
+	return arm(CommandContextQuiet, "get", requestURL)
+}
+
+// ARMPost sends a POST request without a body to Azure Resource Manager
+// with az rest
+func ARMPost(requestURL string) ([]byte, error) {
+	return arm(CommandContext, "post", requestURL)
+}
+
+// ARMList sends GET requests for a list and the pages after it, up to
+// maxPages of them. page parses each response and returns its nextLink,
+// "" on the last page.
+func ARMList(requestURL string, maxPages int, page func(data []byte) (string, error)) error {
+	return followPages(requestURL, maxPages, ARMGet, page)
+}
+
+// ARMListQuiet is ARMList for polls, which are not recorded in the session
+// history
+func ARMListQuiet(requestURL string, maxPages int, page func(data []byte) (string, error)) error {
+	return followPages(requestURL, maxPages, ARMGetQuiet, page)
+}
+
+// followPages is ARMList with the request sender passed in
+func followPages(next string, maxPages int, get func(string) ([]byte, error), page func([]byte) (string, error)) error {
+	for pages := 0; next != "" && pages < maxPages; pages++ {
+		data, err := get(next)
+		if err != nil {
+			return err
+		}
+		if next, err = page(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// arm runs az rest with command and returns its output. The error carries
+// what az printed, which holds the error Azure answered with.
+func arm(command func(context.Context, ...string) *exec.Cmd, method, requestURL string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), armTimeout)
+	defer cancel()
+	output, err := command(ctx, "rest", "--method", method, "--url", requestURL, "--output", "json").Output()
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("no answer from Azure after %s", armTimeout)
+		}
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			if message := strings.TrimSpace(string(exitErr.Stderr)); message != "" {
+				return nil, errors.New(message)
+			}
+		}
+		return nil, err
+	}
+	return output, nil
+}
@@ -0,0 +1,127 @@
+// Package azcli starts Azure CLI commands. Every command it starts is kept in
+// the session history, so that what was done in the TUI can be shown as
+// equivalent commands or saved as a script.
+package azcli
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxHistory is the number of commands kept in the session history
+const maxHistory = 1000
+
+// Entry is a command started during the session
+type Entry struct {
+	Time time.Time
+	Args []string // Arguments after "az"
+}
+
+var (
+	mu      sync.Mutex
+	history []Entry
+)
+
+// executable is the Azure CLI, replaced in tests
+var executable = "az"
+
+// Command returns the command running az with args and records it in the
+// session history
+func Command(args ...string) *exec.Cmd {
+	Record(args...)
+	return exec.Command(executable, args...)
+}
+
+// CommandContext is Command with a context that kills the command
+func CommandContext(ctx context.Context, args ...string) *exec.Cmd {
+	Record(args...)
+	return exec.CommandContext(ctx, executable, args...)
+}
+
+// CommandContextQuiet is CommandContext without recording the command, for
+// polls and refreshes the user did not ask for. Recording those would push
+// the user's own commands out of the history and repeat them in scripts.
+func CommandContextQuiet(ctx context.Context, args ...string) *exec.Cmd {
+	return exec.CommandContext(ctx, executable, args...)
+}
+
+// secretOptions are options whose values are kept out of the history
+var secretOptions = map[string]bool{
+	"--value": true, "--password": true, "--admin-password": true, "--secret": true,
+	"--account-key": true, "--sas-token": true, "--connection-string": true,
+}
+
+// Redacted is recorded in place of secret option values
+const Redacted = "<redacted>"
+
+// Record adds a command to the session history. The values of options
+// such as --password are replaced by Redacted.
+func Record(args ...string) {
+	recorded := append([]string(nil), args...)
+	for i, arg := range recorded {
+		if name, _, ok := strings.Cut(arg, "="); ok && secretOptions[name] {
+			recorded[i] = name + "=" + Redacted
+		} else if i > 0 && secretOptions[recorded[i-1]] {
+			recorded[i] = Redacted
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	history = append(history, Entry{Time: time.Now(), Args: recorded})
+	if len(history) > maxHistory {
+		history = history[len(history)-maxHistory:]
+	}
+}
+
+// History returns the commands started during the session, oldest first
+func History() []Entry {
+	mu.Lock()
+	defer mu.Unlock()
+	return append([]Entry(nil), history...)
+}
+
+// Line returns the az command line for args, quoted for a POSIX shell
+func Line(args []string) string {
+	words := []string{"az"}
+	for _, arg := range args {
+		words = append(words, shellQuote(arg))
+	}
+	return strings.Join(words, " ")
+}
+
+// Script returns a shell script that runs the commands of entries in order.
+// A command repeated right after itself, such as a refreshed list, is kept
+// once.
+func Script(entries []Entry) string {
+	var b strings.Builder
+	b.WriteString("#!/usr/bin/env bash\n")
+	b.WriteString("# Azure CLI commands run by azure-tui")
+	if len(entries) > 0 {
+		fmt.Fprintf(&b, " between %s and %s", entries[0].Time.Format(time.DateTime), entries[len(entries)-1].Time.Format(time.DateTime))
+	}
+	b.WriteString("\nset -euo pipefail\n\n")
+
+	previous := ""
+	for _, entry := range entries {
+		line := Line(entry.Args)
+		if line == previous {
+			continue
+		}
+		previous = line
+		fmt.Fprintf(&b, "# %s\n%s\n", entry.Time.Format(time.TimeOnly), line)
+	}
+	return b.String()
+}
+
+// shellQuote quotes s for a POSIX shell when it isn't a plain word
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./:=@%+,") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
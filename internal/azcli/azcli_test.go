@@ -0,0 +1,140 @@
+package azcli
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLine(t *testing.T) {
+	got := Line([]string{"vm", "start", "--name", "vm web", "--query", "[0].id", "--resource-group", "it's"})
+	want := `az vm start --name 'vm web' --query '[0].id' --resource-group 'it'\''s'`
+	if got != want {
+		t.Errorf("Line() = %s, want %s", got, want)
+	}
+}
+
+func TestRecordRedactsSecrets(t *testing.T) {
+	Record("keyvault", "secret", "set", "--vault-name", "kv", "--name", "db", "--value", "hunter2")
+	Record("vm", "create", "--admin-password=hunter2")
+
+	history := History()
+	for _, entry := range history[len(history)-2:] {
+		if line := Line(entry.Args); strings.Contains(line, "hunter2") {
+			t.Errorf("secret recorded in %s", line)
+		}
+	}
+}
+
+func TestScript(t *testing.T) {
+	at := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	script := Script([]Entry{
+		{Time: at, Args: []string{"group", "list"}},
+		{Time: at.Add(time.Second), Args: []string{"group", "list"}},
+		{Time: at.Add(2 * time.Second), Args: []string{"vm", "start", "--name", "web"}},
+		{Time: at.Add(3 * time.Second), Args: []string{"group", "list"}},
+	})
+
+	if !strings.HasPrefix(script, "#!/usr/bin/env bash\n") || !strings.Contains(script, "set -euo pipefail") {
+		t.Errorf("script has no bash header:\n%s", script)
+	}
+	if got := strings.Count(script, "\naz group list\n"); got != 2 {
+		t.Errorf("az group list appears %d times, want 2 (repeats right after each other are dropped):\n%s", got, script)
+	}
+	if !strings.Contains(script, "# 12:00:02\naz vm start --name web\n") {
+		t.Errorf("script misses the vm start command:\n%s", script)
+	}
+}
+
+func TestEquivalents(t *testing.T) {
+	tests := []struct {
+		args       []string
+		powerShell string
+		rest       string
+	}{
+		{
+			args:       []string{"vm", "deallocate", "--name", "web", "--resource-group", "rg-prod"},
+			powerShell: "Stop-AzVM -Name web -ResourceGroupName rg-prod -Force",
+			rest:       "POST https://management.azure.com/subscriptions/sub-1/resourceGroups/rg-prod/providers/Microsoft.Compute/virtualMachines/web/deallocate?api-version=2024-07-01",
+		},
+		{
+			args:       []string{"group", "list", "--output", "json", "--subscription", "sub-2"},
+			powerShell: "Get-AzResourceGroup",
+			rest:       "GET https://management.azure.com/subscriptions/sub-2/resourcegroups?api-version=2021-04-01",
+		},
+		{
+			args:       []string{"storage", "blob", "upload", "--account-name", "st", "--container-name", "logs", "--name", "a b.txt", "--overwrite"},
+			powerShell: "Set-AzStorageBlobContent -Context (New-AzStorageContext -StorageAccountName st -UseConnectedAccount) -Container logs -Blob 'a b.txt' -Force",
+		},
+		{
+			// The REST path needs the resource group
+			args:       []string{"resource", "list"},
+			powerShell: "Get-AzResource",
+		},
+		{
+			args: []string{"monitor", "metrics", "list", "--resource", "/x"},
+		},
+	}
+
+	for _, tt := range tests {
+		got := Equivalents(tt.args, "sub-1")
+		if got.AZ != Line(tt.args) {
+			t.Errorf("%v: AZ = %s", tt.args, got.AZ)
+		}
+		if got.PowerShell != tt.powerShell {
+			t.Errorf("%v: PowerShell = %q, want %q", tt.args, got.PowerShell, tt.powerShell)
+		}
+		if got.REST != tt.rest {
+			t.Errorf("%v: REST = %q, want %q", tt.args, got.REST, tt.rest)
+		}
+	}
+}
+
+func TestFollowPages(t *testing.T) {
+	pages := map[string]string{"first": "second", "second": "third", "third": ""}
+	var requested []string
+	get := func(url string) ([]byte, error) {
+		requested = append(requested, url)
+		return []byte(url), nil
+	}
+	page := func(data []byte) (string, error) { return pages[string(data)], nil }
+
+	if err := followPages("first", 10, get, page); err != nil || strings.Join(requested, ",") != "first,second,third" {
+		t.Errorf("followPages() requested %q, %v, want every page", requested, err)
+	}
+
+	requested = nil
+	if err := followPages("first", 2, get, page); err != nil || strings.Join(requested, ",") != "first,second" {
+		t.Errorf("followPages(2 pages) requested %q, %v", requested, err)
+	}
+
+	requested = nil
+	failing := func([]byte) (string, error) { return "second", errors.New("bad page") }
+	if err := followPages("first", 10, get, failing); err == nil || len(requested) != 1 {
+		t.Errorf("followPages() with a bad page = %v after %q, want the error at once", err, requested)
+	}
+}
+
+func TestPollsAreNotRecorded(t *testing.T) {
+	executable = "/nonexistent/az"
+	t.Cleanup(func() { executable = "az" })
+	const summary = "https://management.azure.com/subscriptions/{subscriptionId}/providers/Microsoft.AlertsManagement/alertsSummary"
+
+	before := len(History())
+	if _, err := ARMGetQuiet(summary); err == nil {
+		t.Fatal("ARMGetQuiet() without az should fail")
+	}
+	ARMListQuiet(summary, 1, func([]byte) (string, error) { return "", nil })
+	CommandContextQuiet(context.Background(), "monitor", "metrics", "list")
+	if got := History(); len(got) != before {
+		t.Errorf("polls recorded %q", Line(got[len(got)-1].Args))
+	}
+
+	// The same request asked for by the user is recorded
+	ARMGet(summary)
+	if got := History(); len(got) != before+1 || !strings.Contains(Line(got[len(got)-1].Args), "alertsSummary") {
+		t.Errorf("History() = %d entries after a recorded request, want %d", len(got), before+1)
+	}
+}
@@ -0,0 +1,183 @@
+package azcli
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Equivalent is an az command written for other tools
+type Equivalent struct {
+	AZ         string // az command line
+	PowerShell string // Az PowerShell module cmdlet, empty when there is none
+	REST       string // Azure Resource Manager request, empty when there is none
+}
+
+// translation maps an az command to Az PowerShell and the REST API
+type translation struct {
+	cmdlet string
+	params map[string]string // az option to cmdlet parameter; %s in the parameter is replaced by the value
+	extra  string            // Appended to the cmdlet, e.g. -Force
+
+	method     string
+	path       string // {subscription} and {--option} are replaced by values
+	apiVersion string
+}
+
+// Options shared by most translations
+var (
+	nameAndGroup = map[string]string{"--name": "Name", "--resource-group": "ResourceGroupName"}
+	groupOnly    = map[string]string{"--resource-group": "ResourceGroupName"}
+	storageAuth  = "Context (New-AzStorageContext -StorageAccountName %s -UseConnectedAccount)"
+)
+
+// ARM paths of resources named by --name in --resource-group
+const (
+	vmPath        = "/subscriptions/{subscription}/resourceGroups/{--resource-group}/providers/Microsoft.Compute/virtualMachines/{--name}"
+	aksPath       = "/subscriptions/{subscription}/resourceGroups/{--resource-group}/providers/Microsoft.ContainerService/managedClusters/{--name}"
+	containerPath = "/subscriptions/{subscription}/resourceGroups/{--resource-group}/providers/Microsoft.ContainerInstance/containerGroups/{--name}"
+	vnetPath      = "/subscriptions/{subscription}/resourceGroups/{--resource-group}/providers/Microsoft.Network/virtualNetworks/{--name}"
+	nsgPath       = "/subscriptions/{subscription}/resourceGroups/{--resource-group}/providers/Microsoft.Network/networkSecurityGroups/{--name}"
+)
+
+// translations by the az command words before the first option
+var translations = map[string]translation{
+	"account list": {cmdlet: "Get-AzSubscription", method: "GET", path: "/subscriptions", apiVersion: "2022-12-01"},
+	"account show": {cmdlet: "Get-AzContext"},
+	"account set":  {cmdlet: "Set-AzContext", params: map[string]string{"--subscription": "Subscription"}},
+
+	"group list": {cmdlet: "Get-AzResourceGroup", method: "GET", path: "/subscriptions/{subscription}/resourcegroups", apiVersion: "2021-04-01"},
+	"group show": {cmdlet: "Get-AzResourceGroup", params: nameAndGroup, method: "GET", path: "/subscriptions/{subscription}/resourcegroups/{--name}", apiVersion: "2021-04-01"},
+
+	"resource list": {cmdlet: "Get-AzResource", params: groupOnly, method: "GET", path: "/subscriptions/{subscription}/resourceGroups/{--resource-group}/resources", apiVersion: "2021-04-01"},
+	"resource show": {cmdlet: "Get-AzResource", params: map[string]string{"--ids": "ResourceId"}},
+
+	"vm list":       {cmdlet: "Get-AzVM", params: groupOnly},
+	"vm show":       {cmdlet: "Get-AzVM", params: nameAndGroup, method: "GET", path: vmPath, apiVersion: "2024-07-01"},
+	"vm start":      {cmdlet: "Start-AzVM", params: nameAndGroup, method: "POST", path: vmPath + "/start", apiVersion: "2024-07-01"},
+	"vm deallocate": {cmdlet: "Stop-AzVM", params: nameAndGroup, extra: "-Force", method: "POST", path: vmPath + "/deallocate", apiVersion: "2024-07-01"},
+	"vm restart":    {cmdlet: "Restart-AzVM", params: nameAndGroup, method: "POST", path: vmPath + "/restart", apiVersion: "2024-07-01"},
+
+	"aks list":  {cmdlet: "Get-AzAksCluster", params: groupOnly},
+	"aks show":  {cmdlet: "Get-AzAksCluster", params: nameAndGroup, method: "GET", path: aksPath, apiVersion: "2024-09-01"},
+	"aks start": {cmdlet: "Start-AzAksCluster", params: nameAndGroup, method: "POST", path: aksPath + "/start", apiVersion: "2024-09-01"},
+	"aks stop":  {cmdlet: "Stop-AzAksCluster", params: nameAndGroup, method: "POST", path: aksPath + "/stop", apiVersion: "2024-09-01"},
+
+	"container show":    {cmdlet: "Get-AzContainerGroup", params: nameAndGroup, method: "GET", path: containerPath, apiVersion: "2023-05-01"},
+	"container start":   {cmdlet: "Start-AzContainerGroup", params: nameAndGroup, method: "POST", path: containerPath + "/start", apiVersion: "2023-05-01"},
+	"container stop":    {cmdlet: "Stop-AzContainerGroup", params: nameAndGroup, method: "POST", path: containerPath + "/stop", apiVersion: "2023-05-01"},
+	"container restart": {cmdlet: "Restart-AzContainerGroup", params: nameAndGroup, method: "POST", path: containerPath + "/restart", apiVersion: "2023-05-01"},
+	"container logs":    {cmdlet: "Get-AzContainerInstanceLog", params: map[string]string{"--name": "ContainerGroupName", "--resource-group": "ResourceGroupName", "--container-name": "ContainerName", "--tail": "Tail"}},
+
+	"network vnet list": {cmdlet: "Get-AzVirtualNetwork", params: groupOnly, method: "GET", path: "/subscriptions/{subscription}/providers/Microsoft.Network/virtualNetworks", apiVersion: "2024-05-01"},
+	"network vnet show": {cmdlet: "Get-AzVirtualNetwork", params: nameAndGroup, method: "GET", path: vnetPath, apiVersion: "2024-05-01"},
+	"network nsg list":  {cmdlet: "Get-AzNetworkSecurityGroup", params: groupOnly, method: "GET", path: "/subscriptions/{subscription}/providers/Microsoft.Network/networkSecurityGroups", apiVersion: "2024-05-01"},
+	"network nsg show":  {cmdlet: "Get-AzNetworkSecurityGroup", params: nameAndGroup, method: "GET", path: nsgPath, apiVersion: "2024-05-01"},
+
+	"keyvault secret list": {cmdlet: "Get-AzKeyVaultSecret", params: map[string]string{"--vault-name": "VaultName"}},
+	"keyvault secret show": {cmdlet: "Get-AzKeyVaultSecret", params: map[string]string{"--vault-name": "VaultName", "--name": "Name"}, extra: "-AsPlainText"},
+	"keyvault secret set": {cmdlet: "Set-AzKeyVaultSecret", params: map[string]string{
+		"--vault-name": "VaultName", "--name": "Name", "--value": "SecretValue (ConvertTo-SecureString %s -AsPlainText -Force)",
+	}},
+	"keyvault secret delete": {cmdlet: "Remove-AzKeyVaultSecret", params: map[string]string{"--vault-name": "VaultName", "--name": "Name"}, extra: "-Force"},
+
+	"storage container list":   {cmdlet: "Get-AzStorageContainer", params: map[string]string{"--account-name": storageAuth}},
+	"storage container create": {cmdlet: "New-AzStorageContainer", params: map[string]string{"--account-name": storageAuth, "--name": "Name"}},
+	"storage container delete": {cmdlet: "Remove-AzStorageContainer", params: map[string]string{"--account-name": storageAuth, "--name": "Name"}, extra: "-Force"},
+	"storage blob list":        {cmdlet: "Get-AzStorageBlob", params: map[string]string{"--account-name": storageAuth, "--container-name": "Container"}},
+	"storage blob upload": {cmdlet: "Set-AzStorageBlobContent", params: map[string]string{
+		"--account-name": storageAuth, "--container-name": "Container", "--name": "Blob", "--file": "File", "--overwrite": "Force",
+	}},
+	"storage blob delete": {cmdlet: "Remove-AzStorageBlob", params: map[string]string{"--account-name": storageAuth, "--container-name": "Container", "--name": "Blob"}},
+}
+
+// Equivalents returns the az command for args and its Az PowerShell and
+// REST equivalents where there are some. subscriptionID fills in the
+// subscription of REST paths unless args name one.
+func Equivalents(args []string, subscriptionID string) Equivalent {
+	equivalent := Equivalent{AZ: Line(args)}
+
+	words, options := parseArgs(args)
+	t, ok := translations[strings.Join(words, " ")]
+	if !ok {
+		return equivalent
+	}
+
+	if t.cmdlet != "" {
+		parts := []string{t.cmdlet}
+		for _, option := range options {
+			param, ok := t.params[option.name]
+			switch {
+			case !ok:
+				// Output format and queries have no cmdlet parameter
+			case strings.Contains(param, "%s"):
+				parts = append(parts, "-"+fmt.Sprintf(param, powerShellQuote(option.value)))
+			case option.value == "":
+				parts = append(parts, "-"+param)
+			default:
+				parts = append(parts, "-"+param, powerShellQuote(option.value))
+			}
+		}
+		if t.extra != "" {
+			parts = append(parts, t.extra)
+		}
+		equivalent.PowerShell = strings.Join(parts, " ")
+	}
+
+	if t.method != "" {
+		values := map[string]string{"{subscription}": subscriptionID}
+		for _, option := range options {
+			values["{"+option.name+"}"] = option.value
+		}
+		if values["{--subscription}"] != "" {
+			values["{subscription}"] = values["{--subscription}"]
+		}
+		if values["{subscription}"] == "" {
+			values["{subscription}"] = "{subscriptionId}"
+		}
+
+		path := t.path
+		for placeholder, value := range values {
+			path = strings.ReplaceAll(path, placeholder, value)
+		}
+		// An option the path needs is missing
+		if !strings.Contains(path, "{--") {
+			equivalent.REST = fmt.Sprintf("%s https://management.azure.com%s?api-version=%s", t.method, path, t.apiVersion)
+		}
+	}
+	return equivalent
+}
+
+// option is an option of an az command with its value, empty for a flag
+type option struct {
+	name, value string
+}
+
+// parseArgs splits az arguments into the command words and the options
+func parseArgs(args []string) ([]string, []option) {
+	var words []string
+	var options []option
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") {
+			if len(options) == 0 {
+				words = append(words, arg)
+			}
+			continue
+		}
+		name, value, hasValue := strings.Cut(arg, "=")
+		if !hasValue && i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+			value = args[i+1]
+			i++
+		}
+		options = append(options, option{name: name, value: value})
+	}
+	return words, options
+}
+
+// powerShellQuote quotes s for PowerShell when it isn't a plain word
+func powerShellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./:") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -1,12 +1,15 @@
 package aci
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"os/exec"
+	"io"
 	"strings"
 	"time"
 
+	"github.com/olafkfreund/azure-tui/internal/azcli"
+	"github.com/olafkfreund/azure-tui/internal/jobs"
 	"github.com/olafkfreund/azure-tui/internal/tui"
 )
 
@@ -199,7 +202,7 @@ type EncryptionProperties struct {
 // =============================================================================
 
 func ListContainerInstances() ([]ContainerInstance, error) {
-	cmd := exec.Command("az", "container", "list", "--output", "json")
+	cmd := azcli.Command("container", "list", "--output", "json")
 	out, err := cmd.Output()
 	if err != nil {
 		return nil, err
@@ -212,7 +215,7 @@ func ListContainerInstances() ([]ContainerInstance, error) {
 }
 
 func GetContainerInstanceDetails(name, resourceGroup string) (*ContainerInstance, error) {
-	cmd := exec.Command("az", "container", "show", "--name", name, "--resource-group", resourceGroup, "--output", "json")
+	cmd := azcli.Command("container", "show", "--name", name, "--resource-group", resourceGroup, "--output", "json")
 	out, err := cmd.Output()
 	if err != nil {
 		return nil, err
@@ -225,23 +228,30 @@ func GetContainerInstanceDetails(name, resourceGroup string) (*ContainerInstance
 }
 
 func CreateContainerInstance(name, group, location, image string) error {
-	return exec.Command("az", "container", "create", "--name", name, "--resource-group", group, "--location", location, "--image", image).Run()
+	return azcli.Command("container", "create", "--name", name, "--resource-group", group, "--location", location, "--image", image).Run()
 }
 
 func DeleteContainerInstance(name, group string) error {
-	return exec.Command("az", "container", "delete", "--name", name, "--resource-group", group, "--yes").Run()
+	return azcli.Command("container", "delete", "--name", name, "--resource-group", group, "--yes").Run()
 }
 
 func StartContainerInstance(name, resourceGroup string) error {
-	return exec.Command("az", "container", "start", "--name", name, "--resource-group", resourceGroup).Run()
+	return ContainerInstanceActionContext(context.Background(), nil, "start", name, resourceGroup)
 }
 
 func StopContainerInstance(name, resourceGroup string) error {
-	return exec.Command("az", "container", "stop", "--name", name, "--resource-group", resourceGroup).Run()
+	return ContainerInstanceActionContext(context.Background(), nil, "stop", name, resourceGroup)
 }
 
 func RestartContainerInstance(name, resourceGroup string) error {
-	return exec.Command("az", "container", "restart", "--name", name, "--resource-group", resourceGroup).Run()
+	return ContainerInstanceActionContext(context.Background(), nil, "restart", name, resourceGroup)
+}
+
+// ContainerInstanceActionContext starts, stops or restarts a container
+// group, streaming the command output to out
+func ContainerInstanceActionContext(ctx context.Context, out io.Writer, action, name, resourceGroup string) error {
+	_, err := jobs.Run(ctx, out, azcli.CommandContext(ctx, "container", action, "--name", name, "--resource-group", resourceGroup))
+	return err
 }
 
 func GetContainerLogs(name, resourceGroup string, containerName string, tail int) (string, error) {
@@ -253,7 +263,7 @@ func GetContainerLogs(name, resourceGroup string, containerName string, tail int
 		args = append(args, "--tail", fmt.Sprintf("%d", tail))
 	}
 
-	cmd := exec.Command("az", args...)
+	cmd := azcli.Command(args...)
 	out, err := cmd.Output()
 	if err != nil {
 		return "", err
@@ -267,7 +277,7 @@ func ExecIntoContainer(name, resourceGroup, containerName, command string) error
 		args = append(args, "--container-name", containerName)
 	}
 
-	cmd := exec.Command("az", args...)
+	cmd := azcli.Command(args...)
 	cmd.Stdin = nil
 	cmd.Stdout = nil
 	cmd.Stderr = nil
@@ -280,7 +290,7 @@ func AttachToContainer(name, resourceGroup, containerName string) error {
 		args = append(args, "--container-name", containerName)
 	}
 
-	cmd := exec.Command("az", args...)
+	cmd := azcli.Command(args...)
 	return cmd.Run()
 }
 
@@ -293,7 +303,7 @@ func UpdateContainerInstance(name, resourceGroup string, cpu float64, memory flo
 		args = append(args, "--memory", fmt.Sprintf("%.1f", memory))
 	}
 
-	return exec.Command("az", args...).Run()
+	return azcli.Command(args...).Run()
 }
 
 // =============================================================================
@@ -2,7 +2,8 @@ package acr
 
 import (
 	"encoding/json"
-	"os/exec"
+
+	"github.com/olafkfreund/azure-tui/internal/azcli"
 )
 
 type ContainerRegistry struct {
@@ -12,7 +13,7 @@ type ContainerRegistry struct {
 }
 
 func ListContainerRegistries() ([]ContainerRegistry, error) {
-	cmd := exec.Command("az", "acr", "list", "--output", "json")
+	cmd := azcli.Command("acr", "list", "--output", "json")
 	out, err := cmd.Output()
 	if err != nil {
 		return nil, err
@@ -25,9 +26,9 @@ func ListContainerRegistries() ([]ContainerRegistry, error) {
 }
 
 func CreateContainerRegistry(name, group, location string) error {
-	return exec.Command("az", "acr", "create", "--name", name, "--resource-group", group, "--location", location, "--sku", "Basic").Run()
+	return azcli.Command("acr", "create", "--name", name, "--resource-group", group, "--location", location, "--sku", "Basic").Run()
 }
 
 func DeleteContainerRegistry(name, group string) error {
-	return exec.Command("az", "acr", "delete", "--name", name, "--resource-group", group, "--yes").Run()
+	return azcli.Command("acr", "delete", "--name", name, "--resource-group", group, "--yes").Run()
 }
@@ -2,7 +2,8 @@ package aks
 
 import (
 	"encoding/json"
-	"os/exec"
+
+	"github.com/olafkfreund/azure-tui/internal/azcli"
 )
 
 type AKSCluster struct {
@@ -12,7 +13,7 @@ type AKSCluster struct {
 }
 
 func ListAKSClusters() ([]AKSCluster, error) {
-	cmd := exec.Command("az", "aks", "list", "--output", "json")
+	cmd := azcli.Command("aks", "list", "--output", "json")
 	out, err := cmd.Output()
 	if err != nil {
 		return nil, err
@@ -25,13 +26,13 @@ func ListAKSClusters() ([]AKSCluster, error) {
 }
 
 func CreateAKSCluster(name, group, location string) error {
-	return exec.Command("az", "aks", "create", "--name", name, "--resource-group", group, "--location", location, "--node-count", "1", "--generate-ssh-keys").Run()
+	return azcli.Command("aks", "create", "--name", name, "--resource-group", group, "--location", location, "--node-count", "1", "--generate-ssh-keys").Run()
 }
 
 func DeleteAKSCluster(name, group string) error {
-	return exec.Command("az", "aks", "delete", "--name", name, "--resource-group", group, "--yes", "--no-wait").Run()
+	return azcli.Command("aks", "delete", "--name", name, "--resource-group", group, "--yes", "--no-wait").Run()
 }
 
 func AKSGetCredentials(name, group string) error {
-	return exec.Command("az", "aks", "get-credentials", "--name", name, "--resource-group", group, "--overwrite-existing").Run()
+	return azcli.Command("aks", "get-credentials", "--name", name, "--resource-group", group, "--overwrite-existing").Run()
 }
@@ -0,0 +1,201 @@
+// Package alerts reads fired Azure Monitor alerts and changes their state
+// through the Alerts Management API
+package alerts
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/olafkfreund/azure-tui/internal/azcli"
+)
+
+// apiVersion of the Alerts Management API
+const apiVersion = "2019-05-05-preview"
+
+// maxPages bounds how many pages of alerts List follows
+const maxPages = 10
+
+// Alert states
+const (
+	StateNew          = "New"
+	StateAcknowledged = "Acknowledged"
+	StateClosed       = "Closed"
+)
+
+// Alert is an alert fired by an alert rule
+type Alert struct {
+	ID             string
+	Name           string
+	Severity       string // "Sev0", the most severe, to "Sev4"
+	State          string // New, Acknowledged or Closed
+	Condition      string // Fired, or Resolved once the condition cleared
+	TargetID       string
+	TargetName     string
+	TargetGroup    string
+	TargetType     string
+	MonitorService string // e.g. "Platform", "Log Analytics"
+	Rule           string // ID of the alert rule
+	Description    string
+	Fired          time.Time
+	Modified       time.Time
+}
+
+// Open reports whether the alert still needs attention
+func (a Alert) Open() bool {
+	return a.State != StateClosed
+}
+
+// subscriptionScope returns the /subscriptions/<id> part of a resource ID,
+// or the placeholder az rest fills in with the current subscription
+func subscriptionScope(scope string) string {
+	parts := strings.Split(strings.Trim(scope, "/"), "/")
+	if len(parts) >= 2 && strings.EqualFold(parts[0], "subscriptions") {
+		return "/subscriptions/" + parts[1]
+	}
+	return "/subscriptions/{subscriptionId}"
+}
+
+// ListURL returns the request for the alerts of the last 30 days that
+// target scope: a resource ID, a resource group ID, or "" for the whole
+// subscription
+func ListURL(scope string) string {
+	query := url.Values{}
+	query.Set("api-version", apiVersion)
+	query.Set("timeRange", "30d")
+	query.Set("sortBy", "startDateTime")
+	query.Set("sortOrder", "desc")
+
+	parts := strings.Split(strings.Trim(scope, "/"), "/")
+	switch {
+	case strings.Contains(strings.ToLower(scope), "/providers/"):
+		query.Set("targetResource", scope)
+	case len(parts) == 4 && strings.EqualFold(parts[2], "resourceGroups"):
+		query.Set("targetResourceGroup", parts[3])
+	}
+	return "https://management.azure.com" + subscriptionScope(scope) + "/providers/Microsoft.AlertsManagement/alerts?" + query.Encode()
+}
+
+// List returns the alerts of the last 30 days that target scope, newest
+// first. See ListURL for the scopes.
+func List(scope string) ([]Alert, error) {
+	var all []Alert
+	err := azcli.ARMList(ListURL(scope), maxPages, func(data []byte) (string, error) {
+		alerts, nextLink, err := ParseAlerts(data)
+		all = append(all, alerts...)
+		return nextLink, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list alerts: %v", err)
+	}
+	sort.SliceStable(all, func(i, j int) bool { return all[i].Fired.After(all[j].Fired) })
+	return all, nil
+}
+
+// ParseAlerts parses a page of the alerts API and returns the link to the
+// next page, if any
+func ParseAlerts(data []byte) ([]Alert, string, error) {
+	var response struct {
+		Value []struct {
+			ID         string `json:"id"`
+			Name       string `json:"name"`
+			Properties struct {
+				Essentials struct {
+					Severity             string `json:"severity"`
+					AlertState           string `json:"alertState"`
+					MonitorCondition     string `json:"monitorCondition"`
+					TargetResource       string `json:"targetResource"`
+					TargetResourceName   string `json:"targetResourceName"`
+					TargetResourceGroup  string `json:"targetResourceGroup"`
+					TargetResourceType   string `json:"targetResourceType"`
+					MonitorService       string `json:"monitorService"`
+					AlertRule            string `json:"alertRule"`
+					Description          string `json:"description"`
+					StartDateTime        string `json:"startDateTime"`
+					LastModifiedDateTime string `json:"lastModifiedDateTime"`
+				} `json:"essentials"`
+			} `json:"properties"`
+		} `json:"value"`
+		NextLink string `json:"nextLink"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, "", fmt.Errorf("failed to parse alerts: %v", err)
+	}
+
+	alerts := make([]Alert, 0, len(response.Value))
+	for _, item := range response.Value {
+		essentials := item.Properties.Essentials
+		alert := Alert{
+			ID:             item.ID,
+			Name:           item.Name,
+			Severity:       essentials.Severity,
+			State:          essentials.AlertState,
+			Condition:      essentials.MonitorCondition,
+			TargetID:       essentials.TargetResource,
+			TargetName:     essentials.TargetResourceName,
+			TargetGroup:    essentials.TargetResourceGroup,
+			TargetType:     essentials.TargetResourceType,
+			MonitorService: essentials.MonitorService,
+			Rule:           essentials.AlertRule,
+			Description:    essentials.Description,
+		}
+		alert.Fired, _ = time.Parse(time.RFC3339, essentials.StartDateTime)
+		alert.Modified, _ = time.Parse(time.RFC3339, essentials.LastModifiedDateTime)
+		alerts = append(alerts, alert)
+	}
+	return alerts, response.NextLink, nil
+}
+
+// changeStateURL returns the request that moves an alert to state
+func changeStateURL(alertID, state string) string {
+	return "https://management.azure.com" + alertID + "/changestate?api-version=" + apiVersion + "&newState=" + url.QueryEscape(state)
+}
+
+// ChangeState acknowledges or closes an alert
+func ChangeState(alertID, state string) error {
+	if _, err := azcli.ARMPost(changeStateURL(alertID, state)); err != nil {
+		return fmt.Errorf("failed to change the alert to %s: %v", state, err)
+	}
+	return nil
+}
+
+// OpenCount returns the number of fired alerts in the subscription that
+// are not closed yet. The status bar polls it, so the request is not
+// recorded in the session history.
+func OpenCount() (int, error) {
+	query := url.Values{}
+	query.Set("api-version", apiVersion)
+	query.Set("groupby", "alertState")
+	query.Set("monitorCondition", "Fired")
+	output, err := azcli.ARMGetQuiet("https://management.azure.com/subscriptions/{subscriptionId}/providers/Microsoft.AlertsManagement/alertsSummary?" + query.Encode())
+	if err != nil {
+		return 0, fmt.Errorf("failed to count alerts: %v", err)
+	}
+	return ParseOpenCount(output)
+}
+
+// ParseOpenCount parses an alerts summary grouped by state and returns
+// the number of alerts that are not closed
+func ParseOpenCount(data []byte) (int, error) {
+	var response struct {
+		Properties struct {
+			Values []struct {
+				Name  string `json:"name"`
+				Count int    `json:"count"`
+			} `json:"values"`
+		} `json:"properties"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return 0, fmt.Errorf("failed to parse the alerts summary: %v", err)
+	}
+	count := 0
+	for _, value := range response.Properties.Values {
+		if !strings.EqualFold(value.Name, StateClosed) {
+			count += value.Count
+		}
+	}
+	return count, nil
+}
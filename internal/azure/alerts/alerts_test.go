@@ -0,0 +1,110 @@
+package alerts
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestListURL(t *testing.T) {
+	vm := "/subscriptions/sub-1/resourceGroups/rg-prod/providers/Microsoft.Compute/virtualMachines/vm-web-01"
+	tests := []struct {
+		scope  string
+		prefix string
+		filter string
+		value  string
+	}{
+		{vm, "https://management.azure.com/subscriptions/sub-1/providers/Microsoft.AlertsManagement/alerts?", "targetResource", vm},
+		{"/subscriptions/sub-1/resourceGroups/rg-prod", "https://management.azure.com/subscriptions/sub-1/providers/", "targetResourceGroup", "rg-prod"},
+		{"", "https://management.azure.com/subscriptions/{subscriptionId}/providers/", "", ""},
+	}
+	for _, tt := range tests {
+		got := ListURL(tt.scope)
+		if !strings.HasPrefix(got, tt.prefix) {
+			t.Errorf("ListURL(%q) = %q, want prefix %q", tt.scope, got, tt.prefix)
+			continue
+		}
+		query, err := url.ParseQuery(got[strings.Index(got, "?")+1:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if query.Get("api-version") == "" || query.Get("timeRange") != "30d" {
+			t.Errorf("ListURL(%q) is missing the API version or time range: %q", tt.scope, got)
+		}
+		if tt.filter != "" && query.Get(tt.filter) != tt.value {
+			t.Errorf("ListURL(%q) filters %s by %q, want %q", tt.scope, tt.filter, query.Get(tt.filter), tt.value)
+		}
+		if tt.filter == "" && (query.Has("targetResource") || query.Has("targetResourceGroup")) {
+			t.Errorf("ListURL(%q) should not filter: %q", tt.scope, got)
+		}
+	}
+}
+
+func TestParseAlerts(t *testing.T) {
+	data := []byte(`{
+		"value": [{
+			"id": "/subscriptions/sub-1/providers/Microsoft.AlertsManagement/alerts/1234",
+			"name": "cpu-high",
+			"properties": {"essentials": {
+				"severity": "Sev1",
+				"alertState": "New",
+				"monitorCondition": "Fired",
+				"targetResource": "/subscriptions/sub-1/resourceGroups/rg-prod/providers/Microsoft.Compute/virtualMachines/vm-web-01",
+				"targetResourceName": "vm-web-01",
+				"targetResourceGroup": "rg-prod",
+				"monitorService": "Platform",
+				"alertRule": "/subscriptions/sub-1/resourceGroups/rg-prod/providers/microsoft.insights/metricAlerts/cpu-high",
+				"startDateTime": "2025-06-01T09:30:00Z",
+				"lastModifiedDateTime": "2025-06-01T09:35:00Z"
+			}}
+		}],
+		"nextLink": "https://management.azure.com/next"
+	}`)
+	alerts, next, err := ParseAlerts(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next != "https://management.azure.com/next" {
+		t.Errorf("unexpected next link %q", next)
+	}
+	if len(alerts) != 1 {
+		t.Fatalf("expected 1 alert, got %d", len(alerts))
+	}
+	alert := alerts[0]
+	if alert.Name != "cpu-high" || alert.Severity != "Sev1" || alert.State != StateNew || alert.Condition != "Fired" ||
+		alert.TargetName != "vm-web-01" || alert.TargetGroup != "rg-prod" {
+		t.Errorf("unexpected alert %+v", alert)
+	}
+	if !alert.Fired.Equal(time.Date(2025, 6, 1, 9, 30, 0, 0, time.UTC)) {
+		t.Errorf("unexpected fired time %v", alert.Fired)
+	}
+	if !alert.Open() {
+		t.Error("expected a new alert to be open")
+	}
+	alert.State = StateClosed
+	if alert.Open() {
+		t.Error("expected a closed alert not to be open")
+	}
+}
+
+func TestParseOpenCount(t *testing.T) {
+	data := []byte(`{"properties": {"groupedby": "alertState", "total": 9, "values": [
+		{"name": "New", "count": 3}, {"name": "Acknowledged", "count": 2}, {"name": "Closed", "count": 4}
+	]}}`)
+	count, err := ParseOpenCount(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 5 {
+		t.Errorf("expected 5 open alerts, got %d", count)
+	}
+}
+
+func TestChangeStateURL(t *testing.T) {
+	got := changeStateURL("/subscriptions/sub-1/providers/Microsoft.AlertsManagement/alerts/1234", StateAcknowledged)
+	want := "https://management.azure.com/subscriptions/sub-1/providers/Microsoft.AlertsManagement/alerts/1234/changestate?api-version=" + apiVersion + "&newState=Acknowledged"
+	if got != want {
+		t.Errorf("changeStateURL() = %q, want %q", got, want)
+	}
+}
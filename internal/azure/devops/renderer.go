@@ -5,19 +5,7 @@ import (
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
-)
-
-// Color definitions for borderless design
-var (
-	colorOrgHeader    = lipgloss.Color("39")  // Bright blue
-	colorProjectName  = lipgloss.Color("35")  // Bright green
-	colorPipelineName = lipgloss.Color("37")  // Bright cyan
-	colorStatusGood   = lipgloss.Color("32")  // Green
-	colorStatusBad    = lipgloss.Color("31")  // Red
-	colorStatusWarn   = lipgloss.Color("33")  // Yellow
-	colorSelected     = lipgloss.Color("226") // Bright yellow
-	colorMuted        = lipgloss.Color("240") // Gray
-	colorRunning      = lipgloss.Color("39")  // Blue
+	"github.com/olafkfreund/azure-tui/internal/theme"
 )
 
 // NewTreeRenderer creates a new tree renderer for borderless UI
@@ -61,7 +49,7 @@ func (tr *TreeRenderer) renderHeader() string {
 	var header strings.Builder
 
 	titleStyle := lipgloss.NewStyle().
-		Foreground(colorOrgHeader).
+		Foreground(theme.Current().Primary).
 		Bold(true)
 
 	header.WriteString(titleStyle.Render("Azure DevOps Manager"))
@@ -70,11 +58,11 @@ func (tr *TreeRenderer) renderHeader() string {
 	// Organization and project info
 	if len(tr.nodes) > 0 {
 		if orgNode := tr.findNodeByType("organization"); orgNode != nil {
-			orgStyle := lipgloss.NewStyle().Foreground(colorOrgHeader)
+			orgStyle := lipgloss.NewStyle().Foreground(theme.Current().Primary)
 			header.WriteString(fmt.Sprintf("Organization: %s", orgStyle.Render(orgNode.Name)))
 
 			if projNode := tr.findSelectedProject(); projNode != nil {
-				projStyle := lipgloss.NewStyle().Foreground(colorProjectName)
+				projStyle := lipgloss.NewStyle().Foreground(theme.Current().Success)
 				header.WriteString(fmt.Sprintf("          Project: %s", projStyle.Render(projNode.Name)))
 			}
 		}
@@ -110,7 +98,7 @@ func (tr *TreeRenderer) renderTreeNode(node *DevOpsTreeNode, selected bool) stri
 	name := node.Name
 	if selected {
 		selectedStyle := lipgloss.NewStyle().
-			Foreground(colorSelected).
+			Foreground(theme.Current().Highlight).
 			Bold(true)
 		name = selectedStyle.Render("► " + name)
 	} else {
@@ -124,7 +112,7 @@ func (tr *TreeRenderer) renderTreeNode(node *DevOpsTreeNode, selected bool) stri
 		line.WriteString("  " + status)
 
 		if node.LastRun != "" {
-			lastRunStyle := lipgloss.NewStyle().Foreground(colorMuted)
+			lastRunStyle := lipgloss.NewStyle().Foreground(theme.Current().Muted)
 			line.WriteString("   " + lastRunStyle.Render("Last: "+node.LastRun))
 		}
 	}
@@ -158,22 +146,22 @@ func (tr *TreeRenderer) getNodeIcon(node *DevOpsTreeNode) string {
 func (tr *TreeRenderer) getStatusIndicator(status string) string {
 	switch strings.ToLower(status) {
 	case "running", "inprogress":
-		style := lipgloss.NewStyle().Foreground(colorRunning)
+		style := lipgloss.NewStyle().Foreground(theme.Current().Primary)
 		return style.Render("Running")
 	case "succeeded", "success":
-		style := lipgloss.NewStyle().Foreground(colorStatusGood)
+		style := lipgloss.NewStyle().Foreground(theme.Current().Success)
 		return style.Render("Success")
 	case "failed", "error":
-		style := lipgloss.NewStyle().Foreground(colorStatusBad)
+		style := lipgloss.NewStyle().Foreground(theme.Current().Error)
 		return style.Render("Failed")
 	case "canceled", "cancelled":
-		style := lipgloss.NewStyle().Foreground(colorStatusWarn)
+		style := lipgloss.NewStyle().Foreground(theme.Current().Warning)
 		return style.Render("Canceled")
 	case "queued", "pending":
-		style := lipgloss.NewStyle().Foreground(colorStatusWarn)
+		style := lipgloss.NewStyle().Foreground(theme.Current().Warning)
 		return style.Render("Queued")
 	default:
-		style := lipgloss.NewStyle().Foreground(colorMuted)
+		style := lipgloss.NewStyle().Foreground(theme.Current().Muted)
 		return style.Render("Unknown")
 	}
 }
@@ -184,17 +172,17 @@ func (tr *TreeRenderer) applyNodeStyle(text string, node *DevOpsTreeNode) string
 
 	switch node.Type {
 	case "organization":
-		style = lipgloss.NewStyle().Foreground(colorOrgHeader).Bold(true)
+		style = lipgloss.NewStyle().Foreground(theme.Current().Primary).Bold(true)
 	case "project":
-		style = lipgloss.NewStyle().Foreground(colorProjectName)
+		style = lipgloss.NewStyle().Foreground(theme.Current().Success)
 	case "build-pipelines", "release-pipelines":
-		style = lipgloss.NewStyle().Foreground(colorPipelineName).Bold(true)
+		style = lipgloss.NewStyle().Foreground(theme.Current().Info).Bold(true)
 	case "pipeline":
-		style = lipgloss.NewStyle().Foreground(colorPipelineName)
+		style = lipgloss.NewStyle().Foreground(theme.Current().Info)
 	case "recent-activity":
-		style = lipgloss.NewStyle().Foreground(colorMuted).Italic(true)
+		style = lipgloss.NewStyle().Foreground(theme.Current().Muted).Italic(true)
 	default:
-		style = lipgloss.NewStyle().Foreground(colorMuted)
+		style = lipgloss.NewStyle().Foreground(theme.Current().Muted)
 	}
 
 	return style.Render(text)
@@ -205,8 +193,8 @@ func (tr *TreeRenderer) renderStatusBar() string {
 	shortcuts := "Navigate: j/k  Expand: Space  Select: Enter  Back: Esc"
 
 	statusStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("15")).
-		Background(lipgloss.Color("240")).
+		Foreground(theme.Current().Text).
+		Background(theme.Current().Muted).
 		Padding(0, 1).
 		Width(tr.width - 2)
 
@@ -0,0 +1,455 @@
+// Package diagnostics audits the diagnostic settings of resources against
+// a central Log Analytics workspace and applies a settings template to
+// many resources at once
+package diagnostics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/olafkfreund/azure-tui/internal/azcli"
+)
+
+// workers bounds how many resources are looked up at once
+const workers = 8
+
+// maxSettings is how many diagnostic settings Azure allows per resource
+const maxSettings = 5
+
+// Audit states of a resource
+const (
+	StatusOK             = "ok"              // Sends to the workspace
+	StatusMissing        = "missing"         // Has no diagnostic settings
+	StatusWrongWorkspace = "wrong-workspace" // Sends elsewhere only
+	StatusUnsupported    = "unsupported"     // Has no diagnostic settings to make
+	StatusError          = "error"
+)
+
+// Target is a resource to audit
+type Target struct {
+	ID            string
+	Name          string
+	Type          string
+	ResourceGroup string
+}
+
+// ListTargets returns the resources of a resource group, or of the current
+// subscription when group is empty
+func ListTargets(group string) ([]Target, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	args := []string{"resource", "list", "--output", "json"}
+	if group != "" {
+		args = append(args, "--resource-group", group)
+	}
+	output, err := azcli.CommandContext(ctx, args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list resources: %v", err)
+	}
+	var response []struct {
+		ID            string `json:"id"`
+		Name          string `json:"name"`
+		Type          string `json:"type"`
+		ResourceGroup string `json:"resourceGroup"`
+	}
+	if err := json.Unmarshal(output, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse resources: %v", err)
+	}
+	targets := make([]Target, 0, len(response))
+	for _, item := range response {
+		targets = append(targets, Target(item))
+	}
+	return targets, nil
+}
+
+// Entry is a log or metric category of a setting, or a category group
+// such as "allLogs"
+type Entry struct {
+	Category      string `json:"category,omitempty"`
+	CategoryGroup string `json:"categoryGroup,omitempty"`
+	Enabled       bool   `json:"enabled"`
+}
+
+// String returns the category or group
+func (e Entry) String() string {
+	if e.CategoryGroup != "" {
+		return e.CategoryGroup
+	}
+	return e.Category
+}
+
+// Setting is a diagnostic setting of a resource
+type Setting struct {
+	Name           string
+	WorkspaceID    string
+	StorageAccount string
+	EventHub       string
+	Logs           []Entry
+	Metrics        []Entry
+}
+
+// Destinations describes where the setting sends to
+func (s Setting) Destinations() string {
+	var destinations []string
+	if s.WorkspaceID != "" {
+		destinations = append(destinations, "workspace "+lastSegment(s.WorkspaceID))
+	}
+	if s.StorageAccount != "" {
+		destinations = append(destinations, "storage "+lastSegment(s.StorageAccount))
+	}
+	if s.EventHub != "" {
+		destinations = append(destinations, "event hub "+lastSegment(s.EventHub))
+	}
+	if len(destinations) == 0 {
+		return "nowhere"
+	}
+	return strings.Join(destinations, ", ")
+}
+
+// Enabled returns the enabled log and metric entries
+func (s Setting) Enabled() []string {
+	var enabled []string
+	for _, entry := range append(append([]Entry(nil), s.Logs...), s.Metrics...) {
+		if entry.Enabled {
+			enabled = append(enabled, entry.String())
+		}
+	}
+	return enabled
+}
+
+// List returns the diagnostic settings of a resource
+func List(resourceID string) ([]Setting, error) {
+	output, err := run("monitor", "diagnostic-settings", "list", "--resource", resourceID, "--output", "json")
+	if err != nil {
+		return nil, err
+	}
+	return ParseSettings(output)
+}
+
+// ParseSettings parses az monitor diagnostic-settings list, a list in
+// recent versions of the CLI and an object with a value list before
+func ParseSettings(data []byte) ([]Setting, error) {
+	var response []struct {
+		Name                        string  `json:"name"`
+		WorkspaceID                 string  `json:"workspaceId"`
+		StorageAccountID            string  `json:"storageAccountId"`
+		EventHubAuthorizationRuleID string  `json:"eventHubAuthorizationRuleId"`
+		Logs                        []Entry `json:"logs"`
+		Metrics                     []Entry `json:"metrics"`
+	}
+	if err := unmarshalList(data, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse diagnostic settings: %v", err)
+	}
+	settings := make([]Setting, 0, len(response))
+	for _, item := range response {
+		settings = append(settings, Setting{
+			Name:           item.Name,
+			WorkspaceID:    item.WorkspaceID,
+			StorageAccount: item.StorageAccountID,
+			EventHub:       item.EventHubAuthorizationRuleID,
+			Logs:           item.Logs,
+			Metrics:        item.Metrics,
+		})
+	}
+	return settings, nil
+}
+
+// Audit is what a resource does with its logs
+type Audit struct {
+	Target
+	Settings []Setting
+	Status   string
+	Err      string
+}
+
+// Flagged reports whether the resource should be fixed
+func (a Audit) Flagged() bool {
+	return a.Status == StatusMissing || a.Status == StatusWrongWorkspace
+}
+
+// Classify returns the audit state of settings against a workspace
+func Classify(settings []Setting, workspaceID string) string {
+	if len(settings) == 0 {
+		return StatusMissing
+	}
+	for _, setting := range settings {
+		if workspaceID != "" && strings.EqualFold(setting.WorkspaceID, workspaceID) {
+			return StatusOK
+		}
+	}
+	return StatusWrongWorkspace
+}
+
+// AuditTargets looks up the diagnostic settings of the targets and checks
+// them against the workspace. Lookups that fail are audits with an error.
+func AuditTargets(targets []Target, workspaceID string) []Audit {
+	audits := make([]Audit, len(targets))
+	forEach(len(targets), func(i int) {
+		audit := Audit{Target: targets[i]}
+		settings, err := List(targets[i].ID)
+		switch {
+		case err != nil && unsupported(err):
+			audit.Status = StatusUnsupported
+		case err != nil:
+			audit.Status, audit.Err = StatusError, err.Error()
+		default:
+			audit.Settings = settings
+			audit.Status = Classify(settings, workspaceID)
+		}
+		audits[i] = audit
+	})
+	SortAudits(audits)
+	return audits
+}
+
+// Reclassify checks audits against another workspace without looking the
+// settings up again
+func Reclassify(audits []Audit, workspaceID string) {
+	for i := range audits {
+		if audits[i].Status != StatusUnsupported && audits[i].Status != StatusError {
+			audits[i].Status = Classify(audits[i].Settings, workspaceID)
+		}
+	}
+	SortAudits(audits)
+}
+
+// SortAudits orders audits by what needs attention: missing settings,
+// the wrong workspace, errors, then the rest, by name within each
+func SortAudits(audits []Audit) {
+	rank := map[string]int{StatusMissing: 0, StatusWrongWorkspace: 1, StatusError: 2, StatusOK: 3, StatusUnsupported: 4}
+	sort.SliceStable(audits, func(i, j int) bool {
+		if rank[audits[i].Status] != rank[audits[j].Status] {
+			return rank[audits[i].Status] < rank[audits[j].Status]
+		}
+		return strings.ToLower(audits[i].Name) < strings.ToLower(audits[j].Name)
+	})
+}
+
+// Category is a log or metric category a resource offers
+type Category struct {
+	Name   string
+	Type   string   // "Logs" or "Metrics"
+	Groups []string // e.g. "allLogs", "audit"
+}
+
+// Categories returns the diagnostic categories a resource offers
+func Categories(resourceID string) ([]Category, error) {
+	output, err := run("monitor", "diagnostic-settings", "categories", "list", "--resource", resourceID, "--output", "json")
+	if err != nil {
+		return nil, err
+	}
+	return ParseCategories(output)
+}
+
+// ParseCategories parses az monitor diagnostic-settings categories list
+func ParseCategories(data []byte) ([]Category, error) {
+	var response []struct {
+		Name           string   `json:"name"`
+		CategoryType   string   `json:"categoryType"`
+		CategoryGroups []string `json:"categoryGroups"`
+	}
+	if err := unmarshalList(data, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse diagnostic categories: %v", err)
+	}
+	categories := make([]Category, 0, len(response))
+	for _, item := range response {
+		categories = append(categories, Category{Name: item.Name, Type: item.CategoryType, Groups: item.CategoryGroups})
+	}
+	return categories, nil
+}
+
+// Template is the diagnostic setting applied to resources
+type Template struct {
+	Name        string // Name of the setting
+	WorkspaceID string
+	Logs        bool // Send every log category
+	Metrics     bool // Send every metric category
+}
+
+// Entries returns the logs and metrics the template enables on a resource
+// offering categories. Logs use the allLogs group where the resource has
+// it, so that categories added later are sent too.
+func (t Template) Entries(categories []Category) (logs, metrics []Entry) {
+	allLogs := false
+	for _, category := range categories {
+		for _, group := range category.Groups {
+			allLogs = allLogs || strings.EqualFold(group, "allLogs")
+		}
+	}
+	if t.Logs && allLogs {
+		logs = []Entry{{CategoryGroup: "allLogs", Enabled: true}}
+	}
+	for _, category := range categories {
+		switch {
+		case strings.EqualFold(category.Type, "Logs") && t.Logs && !allLogs:
+			logs = append(logs, Entry{Category: category.Name, Enabled: true})
+		case strings.EqualFold(category.Type, "Metrics") && t.Metrics:
+			metrics = append(metrics, Entry{Category: category.Name, Enabled: true})
+		}
+	}
+	return logs, metrics
+}
+
+// Change is what applying a template does to a resource
+type Change struct {
+	Audit
+	Action     string // "create", "update" or "skip"
+	Reason     string // Why a resource is skipped
+	Logs       []Entry
+	Metrics    []Entry
+	Categories []Category // Offered by the resource, to plan it again
+}
+
+// Plan works out the change of a template on an audited resource offering
+// categories
+func (t Template) Plan(audit Audit, categories []Category) Change {
+	change := Change{Audit: audit, Action: "create", Categories: categories}
+	change.Logs, change.Metrics = t.Entries(categories)
+
+	existing := false
+	for _, setting := range audit.Settings {
+		existing = existing || strings.EqualFold(setting.Name, t.Name)
+	}
+	switch {
+	case audit.Status == StatusUnsupported || len(categories) == 0:
+		change.Action, change.Reason = "skip", "no diagnostic categories"
+	case audit.Status == StatusError:
+		change.Action, change.Reason = "skip", "settings could not be read"
+	case audit.Status == StatusOK && !existing:
+		change.Action, change.Reason = "skip", "already sends to the workspace"
+	case len(change.Logs) == 0 && len(change.Metrics) == 0:
+		change.Action, change.Reason = "skip", "nothing to send"
+	case existing:
+		change.Action = "update"
+	case len(audit.Settings) >= maxSettings:
+		change.Action, change.Reason = "skip", fmt.Sprintf("already has %d settings, the most Azure allows", maxSettings)
+	}
+	return change
+}
+
+// Preview plans a template on audited resources, looking up the categories
+// each offers
+func (t Template) Preview(audits []Audit) []Change {
+	changes := make([]Change, len(audits))
+	forEach(len(audits), func(i int) {
+		if audits[i].Status == StatusUnsupported || audits[i].Status == StatusError {
+			changes[i] = t.Plan(audits[i], nil)
+			return
+		}
+		categories, err := Categories(audits[i].ID)
+		if err != nil {
+			changes[i] = Change{Audit: audits[i], Action: "skip", Reason: err.Error()}
+			return
+		}
+		changes[i] = t.Plan(audits[i], categories)
+	})
+	return changes
+}
+
+// Args returns the az command that makes the change
+func (t Template) Args(change Change) []string {
+	logs, _ := json.Marshal(change.Logs)
+	metrics, _ := json.Marshal(change.Metrics)
+	args := []string{"monitor", "diagnostic-settings", "create",
+		"--name", t.Name, "--resource", change.ID, "--workspace", t.WorkspaceID}
+	if len(change.Logs) > 0 {
+		args = append(args, "--logs", string(logs))
+	}
+	if len(change.Metrics) > 0 {
+		args = append(args, "--metrics", string(metrics))
+	}
+	return append(args, "--output", "none")
+}
+
+// Apply makes a change. Creating a setting that exists updates it.
+func (t Template) Apply(ctx context.Context, change Change) error {
+	if change.Action == "skip" {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+	cmd := azcli.CommandContext(ctx, t.Args(change)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return commandError(err, stderr.String())
+	}
+	return nil
+}
+
+// forEach calls fn for 0 to n-1 on a few goroutines
+func forEach(n int, fn func(i int)) {
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(workers, n); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				fn(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+}
+
+// run runs az and returns its output, or the message it printed on error
+func run(args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	cmd := azcli.CommandContext(ctx, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, commandError(err, stderr.String())
+	}
+	return output, nil
+}
+
+func commandError(err error, stderr string) error {
+	if message := strings.TrimSpace(stderr); message != "" {
+		return fmt.Errorf("%s", strings.TrimPrefix(message, "ERROR: "))
+	}
+	return err
+}
+
+// unsupported reports whether an error says the resource has no
+// diagnostic settings
+func unsupported(err error) bool {
+	message := strings.ToLower(err.Error())
+	return strings.Contains(message, "resourcetypenotsupported") || strings.Contains(message, "does not support diagnostic settings")
+}
+
+// unmarshalList decodes a JSON list, or the value list of an object
+func unmarshalList(data []byte, v any) error {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		var wrapped struct {
+			Value json.RawMessage `json:"value"`
+		}
+		if err := json.Unmarshal(trimmed, &wrapped); err != nil {
+			return err
+		}
+		if len(wrapped.Value) == 0 {
+			data = []byte("[]")
+		} else {
+			data = wrapped.Value
+		}
+	}
+	return json.Unmarshal(data, v)
+}
+
+func lastSegment(id string) string {
+	return id[strings.LastIndex(id, "/")+1:]
+}
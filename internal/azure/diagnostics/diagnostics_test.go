@@ -0,0 +1,185 @@
+package diagnostics
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const workspace = "/subscriptions/sub-1/resourceGroups/rg-shared/providers/Microsoft.OperationalInsights/workspaces/log-central"
+
+func TestParseSettings(t *testing.T) {
+	setting := `{
+		"name": "to-central",
+		"workspaceId": "` + workspace + `",
+		"storageAccountId": "/subscriptions/sub-1/resourceGroups/rg/providers/Microsoft.Storage/storageAccounts/starchive",
+		"logs": [{"categoryGroup": "allLogs", "enabled": true}, {"category": "AuditEvent", "enabled": false}],
+		"metrics": [{"category": "AllMetrics", "enabled": true}]
+	}`
+	// Recent versions of the CLI print a list, older ones an object
+	for _, data := range []string{"[" + setting + "]", `{"value": [` + setting + `]}`} {
+		settings, err := ParseSettings([]byte(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(settings) != 1 || settings[0].Name != "to-central" || settings[0].WorkspaceID != workspace {
+			t.Fatalf("ParseSettings(%.20s) = %+v", data, settings)
+		}
+		if got := settings[0].Enabled(); !reflect.DeepEqual(got, []string{"allLogs", "AllMetrics"}) {
+			t.Errorf("Enabled() = %q, want the enabled group and metric", got)
+		}
+		if got := settings[0].Destinations(); got != "workspace log-central, storage starchive" {
+			t.Errorf("Destinations() = %q", got)
+		}
+	}
+
+	if settings, err := ParseSettings([]byte(`{"value": null}`)); err != nil || len(settings) != 0 {
+		t.Errorf("ParseSettings(no value) = %+v, %v", settings, err)
+	}
+	if _, err := ParseSettings([]byte("not json")); err == nil {
+		t.Error("ParseSettings(not json) should fail")
+	}
+}
+
+func TestClassify(t *testing.T) {
+	elsewhere := Setting{Name: "other", WorkspaceID: strings.Replace(workspace, "log-central", "log-team", 1)}
+	central := Setting{Name: "central", WorkspaceID: strings.ToUpper(workspace)}
+
+	tests := []struct {
+		name      string
+		settings  []Setting
+		workspace string
+		want      string
+	}{
+		{"no settings", nil, workspace, StatusMissing},
+		{"other workspace", []Setting{elsewhere}, workspace, StatusWrongWorkspace},
+		{"storage only", []Setting{{Name: "archive", StorageAccount: "st"}}, workspace, StatusWrongWorkspace},
+		{"central among others", []Setting{elsewhere, central}, workspace, StatusOK},
+		{"no central workspace", []Setting{central}, "", StatusWrongWorkspace},
+	}
+	for _, tt := range tests {
+		if got := Classify(tt.settings, tt.workspace); got != tt.want {
+			t.Errorf("Classify(%s) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSortAudits(t *testing.T) {
+	audits := []Audit{
+		{Target: Target{Name: "vm-b"}, Status: StatusOK},
+		{Target: Target{Name: "nic"}, Status: StatusUnsupported},
+		{Target: Target{Name: "kv"}, Status: StatusWrongWorkspace},
+		{Target: Target{Name: "VM-a"}, Status: StatusOK},
+		{Target: Target{Name: "st"}, Status: StatusMissing},
+		{Target: Target{Name: "sql"}, Status: StatusError},
+	}
+	SortAudits(audits)
+
+	var order []string
+	for _, audit := range audits {
+		order = append(order, audit.Name)
+	}
+	if want := []string{"st", "kv", "sql", "VM-a", "vm-b", "nic"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("SortAudits() order = %q, want %q", order, want)
+	}
+}
+
+func TestParseCategories(t *testing.T) {
+	data := []byte(`{"value": [
+		{"name": "AuditEvent", "categoryType": "Logs", "categoryGroups": ["audit", "allLogs"]},
+		{"name": "AllMetrics", "categoryType": "Metrics"}
+	]}`)
+	categories, err := ParseCategories(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Category{
+		{Name: "AuditEvent", Type: "Logs", Groups: []string{"audit", "allLogs"}},
+		{Name: "AllMetrics", Type: "Metrics"},
+	}
+	if !reflect.DeepEqual(categories, want) {
+		t.Errorf("ParseCategories() = %+v, want %+v", categories, want)
+	}
+}
+
+func TestTemplateEntries(t *testing.T) {
+	template := Template{Name: "central", WorkspaceID: workspace, Logs: true, Metrics: true}
+	grouped := []Category{
+		{Name: "AuditEvent", Type: "Logs", Groups: []string{"audit", "allLogs"}},
+		{Name: "AzurePolicyEvaluationDetails", Type: "Logs", Groups: []string{"allLogs"}},
+		{Name: "AllMetrics", Type: "Metrics"},
+	}
+	logs, metrics := template.Entries(grouped)
+	if want := []Entry{{CategoryGroup: "allLogs", Enabled: true}}; !reflect.DeepEqual(logs, want) {
+		t.Errorf("Entries(allLogs) logs = %+v, want the group", logs)
+	}
+	if want := []Entry{{Category: "AllMetrics", Enabled: true}}; !reflect.DeepEqual(metrics, want) {
+		t.Errorf("Entries(allLogs) metrics = %+v", metrics)
+	}
+
+	// Without the group every log category is listed
+	logs, _ = template.Entries([]Category{{Name: "A", Type: "Logs"}, {Name: "B", Type: "Logs"}})
+	if want := []Entry{{Category: "A", Enabled: true}, {Category: "B", Enabled: true}}; !reflect.DeepEqual(logs, want) {
+		t.Errorf("Entries(no groups) logs = %+v, want each category", logs)
+	}
+
+	template.Logs = false
+	if logs, metrics := template.Entries(grouped); len(logs) != 0 || len(metrics) != 1 {
+		t.Errorf("Entries(metrics only) = %+v, %+v", logs, metrics)
+	}
+}
+
+func TestTemplatePlan(t *testing.T) {
+	template := Template{Name: "central", WorkspaceID: workspace, Logs: true, Metrics: true}
+	categories := []Category{{Name: "AuditEvent", Type: "Logs"}, {Name: "AllMetrics", Type: "Metrics"}}
+	others := func(n int) []Setting {
+		settings := make([]Setting, n)
+		for i := range settings {
+			settings[i] = Setting{Name: strings.Repeat("x", i+1), StorageAccount: "st"}
+		}
+		return settings
+	}
+
+	tests := []struct {
+		name       string
+		audit      Audit
+		categories []Category
+		action     string
+		reason     string
+	}{
+		{"missing", Audit{Status: StatusMissing}, categories, "create", ""},
+		{"elsewhere", Audit{Status: StatusWrongWorkspace, Settings: others(1)}, categories, "create", ""},
+		{"same name elsewhere", Audit{Status: StatusWrongWorkspace, Settings: []Setting{{Name: "Central", StorageAccount: "st"}}}, categories, "update", ""},
+		{"already sending", Audit{Status: StatusOK, Settings: []Setting{{Name: "team", WorkspaceID: workspace}}}, categories, "skip", "already sends"},
+		{"setting limit", Audit{Status: StatusWrongWorkspace, Settings: others(maxSettings)}, categories, "skip", "the most Azure allows"},
+		{"no categories", Audit{Status: StatusMissing}, nil, "skip", "no diagnostic categories"},
+		{"unsupported", Audit{Status: StatusUnsupported}, nil, "skip", "no diagnostic categories"},
+		{"unreadable", Audit{Status: StatusError}, categories, "skip", "could not be read"},
+	}
+	for _, tt := range tests {
+		change := template.Plan(tt.audit, tt.categories)
+		if change.Action != tt.action || !strings.Contains(change.Reason, tt.reason) {
+			t.Errorf("Plan(%s) = %s %q, want %s %q", tt.name, change.Action, change.Reason, tt.action, tt.reason)
+		}
+	}
+
+	template.Metrics = false
+	if change := template.Plan(Audit{Status: StatusMissing}, []Category{{Name: "AllMetrics", Type: "Metrics"}}); change.Action != "skip" || change.Reason != "nothing to send" {
+		t.Errorf("Plan(logs only, metrics offered) = %s %q, want nothing to send", change.Action, change.Reason)
+	}
+}
+
+func TestTemplateArgs(t *testing.T) {
+	template := Template{Name: "central", WorkspaceID: workspace, Logs: true}
+	vm := "/subscriptions/sub-1/resourceGroups/rg/providers/Microsoft.Compute/virtualMachines/vm-1"
+	change := template.Plan(Audit{Target: Target{ID: vm}, Status: StatusMissing}, []Category{
+		{Name: "AuditEvent", Type: "Logs", Groups: []string{"allLogs"}},
+		{Name: "AllMetrics", Type: "Metrics"},
+	})
+
+	want := []string{"monitor", "diagnostic-settings", "create", "--name", "central", "--resource", vm, "--workspace", workspace,
+		"--logs", `[{"categoryGroup":"allLogs","enabled":true}]`, "--output", "none"}
+	if got := template.Args(change); !reflect.DeepEqual(got, want) {
+		t.Errorf("Args() = %q\nwant %q", got, want)
+	}
+}
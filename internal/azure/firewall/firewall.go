@@ -2,7 +2,8 @@ package firewall
 
 import (
 	"encoding/json"
-	"os/exec"
+
+	"github.com/olafkfreund/azure-tui/internal/azcli"
 )
 
 type Firewall struct {
@@ -12,7 +13,7 @@ type Firewall struct {
 }
 
 func ListFirewalls() ([]Firewall, error) {
-	cmd := exec.Command("az", "network", "firewall", "list", "--output", "json")
+	cmd := azcli.Command("network", "firewall", "list", "--output", "json")
 	out, err := cmd.Output()
 	if err != nil {
 		return nil, err
@@ -25,9 +26,9 @@ func ListFirewalls() ([]Firewall, error) {
 }
 
 func CreateFirewall(name, group, location string) error {
-	return exec.Command("az", "network", "firewall", "create", "--name", name, "--resource-group", group, "--location", location).Run()
+	return azcli.Command("network", "firewall", "create", "--name", name, "--resource-group", group, "--location", location).Run()
 }
 
 func DeleteFirewall(name, group string) error {
-	return exec.Command("az", "network", "firewall", "delete", "--name", name, "--resource-group", group).Run()
+	return azcli.Command("network", "firewall", "delete", "--name", name, "--resource-group", group).Run()
 }
@@ -0,0 +1,399 @@
+// Package health reads Azure Resource Health availability of resources and
+// the Service Health incidents that affect subscriptions
+package health
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/olafkfreund/azure-tui/internal/azcli"
+)
+
+// apiVersion of the Resource Health API
+const apiVersion = "2022-10-01"
+
+// maxPages bounds how many pages of a list are followed
+const maxPages = 20
+
+// Availability states
+const (
+	Available   = "Available"
+	Degraded    = "Degraded"
+	Unavailable = "Unavailable"
+	Unknown     = "Unknown"
+)
+
+// Causes of a health event
+const (
+	CausePlatform = "platform" // Azure
+	CauseUser     = "user"     // Something done to the resource
+)
+
+// statusProvider separates the resource ID from the status in the IDs of
+// availability statuses
+const statusProvider = "/providers/microsoft.resourcehealth/availabilitystatuses"
+
+// Status is the availability of a resource at a point in time
+type Status struct {
+	ResourceID      string
+	State           string // Available, Degraded, Unavailable or Unknown
+	Title           string
+	Summary         string
+	Details         string
+	Reason          string // e.g. "Unplanned", "Planned", "UserInitiated"
+	Cause           string // CausePlatform, CauseUser or "" when unknown
+	RootCauseTime   time.Time
+	Occurred        time.Time
+	Reported        time.Time
+	Resolved        time.Time // Of the recently resolved event, if any
+	ResolvedSummary string
+	Actions         []string // Recommended actions
+}
+
+// Healthy reports whether the resource is available
+func (s Status) Healthy() bool {
+	return s.State == Available
+}
+
+// ListStatuses returns the current availability of every resource in the
+// current subscription. The tree polls it, so the requests are not recorded
+// in the session history.
+func ListStatuses() ([]Status, error) {
+	query := url.Values{}
+	query.Set("api-version", apiVersion)
+	statuses, err := list(azcli.ARMListQuiet, "https://management.azure.com/subscriptions/{subscriptionId}/providers/Microsoft.ResourceHealth/availabilityStatuses?"+query.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list resource health: %v", err)
+	}
+	return statuses, nil
+}
+
+// History returns the current availability of a resource and its health
+// events of the last weeks, newest first
+func History(resourceID string) ([]Status, error) {
+	query := url.Values{}
+	query.Set("api-version", apiVersion)
+	query.Set("$expand", "recommendedactions")
+	statuses, err := list(azcli.ARMList, "https://management.azure.com"+resourceID+"/providers/Microsoft.ResourceHealth/availabilityStatuses?"+query.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the health of the resource: %v", err)
+	}
+	sort.SliceStable(statuses, func(i, j int) bool { return statuses[i].Occurred.After(statuses[j].Occurred) })
+	return statuses, nil
+}
+
+// list follows the pages of an availability status list with armList
+func list(armList func(string, int, func([]byte) (string, error)) error, requestURL string) ([]Status, error) {
+	var all []Status
+	err := armList(requestURL, maxPages, func(data []byte) (string, error) {
+		statuses, nextLink, err := ParseStatuses(data)
+		all = append(all, statuses...)
+		return nextLink, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+// ParseStatuses parses a page of availability statuses and returns the
+// link to the next page, if any
+func ParseStatuses(data []byte) ([]Status, string, error) {
+	var response struct {
+		Value []struct {
+			ID         string `json:"id"`
+			Properties struct {
+				AvailabilityState        string `json:"availabilityState"`
+				Title                    string `json:"title"`
+				Summary                  string `json:"summary"`
+				DetailedStatus           string `json:"detailedStatus"`
+				ReasonType               string `json:"reasonType"`
+				Context                  string `json:"context"`
+				HealthEventCause         string `json:"healthEventCause"`
+				RootCauseAttributionTime string `json:"rootCauseAttributionTime"`
+				OccuredTime              string `json:"occuredTime"` // Sic
+				ReportedTime             string `json:"reportedTime"`
+				RecentlyResolved         *struct {
+					ResolvedTime       string `json:"resolvedTime"`
+					UnavailableSummary string `json:"unavailableSummary"`
+				} `json:"recentlyResolved"`
+				RecommendedActions []struct {
+					Action string `json:"action"`
+				} `json:"recommendedActions"`
+			} `json:"properties"`
+		} `json:"value"`
+		NextLink string `json:"nextLink"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, "", fmt.Errorf("failed to parse resource health: %v", err)
+	}
+
+	statuses := make([]Status, 0, len(response.Value))
+	for _, item := range response.Value {
+		properties := item.Properties
+		status := Status{
+			ResourceID: ResourceOf(item.ID),
+			State:      properties.AvailabilityState,
+			Title:      properties.Title,
+			Summary:    properties.Summary,
+			Details:    properties.DetailedStatus,
+			Reason:     properties.ReasonType,
+			Cause:      cause(properties.HealthEventCause, properties.Context, properties.ReasonType),
+		}
+		if status.State == "" {
+			status.State = Unknown
+		}
+		status.RootCauseTime, _ = time.Parse(time.RFC3339, properties.RootCauseAttributionTime)
+		status.Occurred, _ = time.Parse(time.RFC3339, properties.OccuredTime)
+		status.Reported, _ = time.Parse(time.RFC3339, properties.ReportedTime)
+		if resolved := properties.RecentlyResolved; resolved != nil {
+			status.Resolved, _ = time.Parse(time.RFC3339, resolved.ResolvedTime)
+			status.ResolvedSummary = resolved.UnavailableSummary
+		}
+		for _, action := range properties.RecommendedActions {
+			if text := PlainText(action.Action); text != "" {
+				status.Actions = append(status.Actions, text)
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, response.NextLink, nil
+}
+
+// ResourceOf returns the ID of the resource an availability status is of
+func ResourceOf(statusID string) string {
+	if i := strings.Index(strings.ToLower(statusID), statusProvider); i >= 0 {
+		return statusID[:i]
+	}
+	return statusID
+}
+
+// cause tells whether Azure or the user caused a health event, from the
+// fields the API versions fill in
+func cause(healthEventCause, context, reasonType string) string {
+	for _, value := range []string{healthEventCause, context} {
+		switch strings.ToLower(strings.ReplaceAll(value, " ", "")) {
+		case "platforminitiated":
+			return CausePlatform
+		case "userinitiated", "customerinitiated":
+			return CauseUser
+		}
+	}
+	if strings.EqualFold(reasonType, "UserInitiated") {
+		return CauseUser
+	}
+	return ""
+}
+
+// Event is a Service Health incident or maintenance
+type Event struct {
+	TrackingID    string
+	Type          string // e.g. "ServiceIssue", "PlannedMaintenance"
+	Title         string
+	Summary       string
+	Level         string // e.g. "Error", "Warning", "Informational"
+	Active        bool
+	Services      []string
+	Regions       []string
+	Subscriptions []string // IDs of the subscriptions it affects
+	Started       time.Time
+	Mitigated     time.Time
+	Updated       time.Time
+}
+
+// Affects reports whether the event impacts one of locations, e.g.
+// "westeurope". Global events affect every region.
+func (e Event) Affects(locations []string) bool {
+	for _, region := range e.Regions {
+		region = NormalizeRegion(region)
+		if region == "global" {
+			return true
+		}
+		for _, location := range locations {
+			if region == NormalizeRegion(location) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// NormalizeRegion turns "West Europe" into "westeurope"
+func NormalizeRegion(region string) string {
+	return strings.ToLower(strings.ReplaceAll(region, " ", ""))
+}
+
+// serviceEventsLookback is how far back resolved incidents are listed
+const serviceEventsLookback = 7 * 24 * time.Hour
+
+// ServiceEvents returns the service incidents of the last week that affect
+// the subscriptions, active ones first. Without subscriptions it looks at
+// the current one. Failed subscriptions are reported in the error next to
+// the events of the others.
+func ServiceEvents(subscriptionIDs []string) ([]Event, error) {
+	if len(subscriptionIDs) == 0 {
+		subscriptionIDs = []string{"{subscriptionId}"}
+	}
+	query := url.Values{}
+	query.Set("api-version", apiVersion)
+	query.Set("queryStartTime", time.Now().Add(-serviceEventsLookback).UTC().Format("01/02/2006"))
+	query.Set("$filter", "properties/eventType eq 'ServiceIssue'")
+
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		events []Event
+		errs   []string
+	)
+	for _, subscriptionID := range subscriptionIDs {
+		wg.Add(1)
+		go func(subscriptionID string) {
+			defer wg.Done()
+			var found []Event
+			eventsURL := "https://management.azure.com/subscriptions/" + subscriptionID + "/providers/Microsoft.ResourceHealth/events?" + query.Encode()
+			err := azcli.ARMList(eventsURL, maxPages, func(data []byte) (string, error) {
+				pageEvents, nextLink, err := ParseEvents(data, subscriptionID)
+				found = append(found, pageEvents...)
+				return nextLink, err
+			})
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %v", subscriptionID, err))
+				return
+			}
+			events = append(events, found...)
+		}(subscriptionID)
+	}
+	wg.Wait()
+
+	merged := MergeEvents(events)
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		return merged, fmt.Errorf("failed to read service health of %s", strings.Join(errs, "; "))
+	}
+	return merged, nil
+}
+
+// ParseEvents parses a page of Service Health events of a subscription and
+// returns the link to the next page, if any
+func ParseEvents(data []byte, subscriptionID string) ([]Event, string, error) {
+	var response struct {
+		Value []struct {
+			Name       string `json:"name"`
+			Properties struct {
+				EventType            string `json:"eventType"`
+				Status               string `json:"status"`
+				Title                string `json:"title"`
+				Summary              string `json:"summary"`
+				Level                string `json:"level"`
+				ImpactStartTime      string `json:"impactStartTime"`
+				ImpactMitigationTime string `json:"impactMitigationTime"`
+				LastUpdateTime       string `json:"lastUpdateTime"`
+				Impact               []struct {
+					ImpactedService string `json:"impactedService"`
+					ImpactedRegions []struct {
+						ImpactedRegion string `json:"impactedRegion"`
+					} `json:"impactedRegions"`
+				} `json:"impact"`
+			} `json:"properties"`
+		} `json:"value"`
+		NextLink string `json:"nextLink"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, "", fmt.Errorf("failed to parse service health events: %v", err)
+	}
+
+	events := make([]Event, 0, len(response.Value))
+	for _, item := range response.Value {
+		properties := item.Properties
+		event := Event{
+			TrackingID: item.Name,
+			Type:       properties.EventType,
+			Title:      properties.Title,
+			Summary:    PlainText(properties.Summary),
+			Level:      properties.Level,
+			Active:     strings.EqualFold(properties.Status, "Active"),
+		}
+		if subscriptionID != "" && subscriptionID != "{subscriptionId}" {
+			event.Subscriptions = []string{subscriptionID}
+		}
+		for _, impact := range properties.Impact {
+			event.Services = appendUnique(event.Services, impact.ImpactedService)
+			for _, region := range impact.ImpactedRegions {
+				event.Regions = appendUnique(event.Regions, region.ImpactedRegion)
+			}
+		}
+		event.Started, _ = time.Parse(time.RFC3339, properties.ImpactStartTime)
+		event.Mitigated, _ = time.Parse(time.RFC3339, properties.ImpactMitigationTime)
+		event.Updated, _ = time.Parse(time.RFC3339, properties.LastUpdateTime)
+		events = append(events, event)
+	}
+	return events, response.NextLink, nil
+}
+
+// MergeEvents joins the copies of an event seen in several subscriptions
+// and sorts them: active first, then the latest update first
+func MergeEvents(events []Event) []Event {
+	var merged []Event
+	index := map[string]int{}
+	for _, event := range events {
+		i, seen := index[event.TrackingID]
+		if !seen || event.TrackingID == "" {
+			index[event.TrackingID] = len(merged)
+			merged = append(merged, event)
+			continue
+		}
+		for _, subscription := range event.Subscriptions {
+			merged[i].Subscriptions = appendUnique(merged[i].Subscriptions, subscription)
+		}
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		if merged[i].Active != merged[j].Active {
+			return merged[i].Active
+		}
+		return merged[i].Updated.After(merged[j].Updated)
+	})
+	return merged
+}
+
+func appendUnique(values []string, value string) []string {
+	if value == "" {
+		return values
+	}
+	for _, existing := range values {
+		if strings.EqualFold(existing, value) {
+			return values
+		}
+	}
+	return append(values, value)
+}
+
+var (
+	paragraphs = regexp.MustCompile(`(?i)<\s*/p\s*>`)
+	breakTags  = regexp.MustCompile(`(?i)<\s*(br|/li|/div)\s*/?>`)
+	htmlTags   = regexp.MustCompile(`<[^>]*>`)
+	blank      = regexp.MustCompile(`[ \t]+`)
+	blanks     = regexp.MustCompile(`\n\s*\n+`)
+)
+
+// PlainText turns the HTML of summaries and actions into plain text
+func PlainText(text string) string {
+	text = paragraphs.ReplaceAllString(text, "\n\n")
+	text = breakTags.ReplaceAllString(text, "\n")
+	text = html.UnescapeString(htmlTags.ReplaceAllString(text, ""))
+	text = strings.ReplaceAll(text, "\u00a0", " ")
+	lines := strings.Split(blank.ReplaceAllString(text, " "), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	return strings.TrimSpace(blanks.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
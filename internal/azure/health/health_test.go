@@ -0,0 +1,125 @@
+package health
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseStatuses(t *testing.T) {
+	vm := "/subscriptions/sub-1/resourceGroups/rg-prod/providers/Microsoft.Compute/virtualMachines/vm-web-01"
+	data := []byte(`{
+		"value": [{
+			"id": "` + vm + `/providers/Microsoft.ResourceHealth/availabilityStatuses/current",
+			"properties": {
+				"availabilityState": "Unavailable",
+				"summary": "Your virtual machine isn't available because of a host failure.",
+				"reasonType": "Unplanned",
+				"context": "Platform Initiated",
+				"occuredTime": "2025-06-01T09:00:00Z",
+				"reportedTime": "2025-06-01T09:05:00Z",
+				"recommendedActions": [{"action": "<a href='#'>Redeploy</a> the virtual machine"}]
+			}
+		}, {
+			"id": "` + vm + `/providers/microsoft.resourcehealth/availabilityStatuses/8a2d",
+			"properties": {
+				"availabilityState": "Unavailable",
+				"reasonType": "UserInitiated",
+				"occuredTime": "2025-05-29T09:00:00Z",
+				"recentlyResolved": {"resolvedTime": "2025-05-30T09:00:00Z", "unavailableSummary": "Stopped"}
+			}
+		}, {
+			"id": "/subscriptions/sub-1/resourceGroups/rg-prod/providers/Microsoft.KeyVault/vaults/kv/providers/Microsoft.ResourceHealth/availabilityStatuses/current",
+			"properties": {}
+		}],
+		"nextLink": "https://management.azure.com/next"
+	}`)
+	statuses, next, err := ParseStatuses(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next != "https://management.azure.com/next" || len(statuses) != 3 {
+		t.Fatalf("ParseStatuses() = %d statuses, next %q", len(statuses), next)
+	}
+
+	current := statuses[0]
+	if current.ResourceID != vm || current.State != Unavailable || current.Cause != CausePlatform || current.Healthy() {
+		t.Errorf("current status = %+v", current)
+	}
+	if !current.Occurred.Equal(time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("Occurred = %v, want the misspelled occuredTime", current.Occurred)
+	}
+	if !reflect.DeepEqual(current.Actions, []string{"Redeploy the virtual machine"}) {
+		t.Errorf("Actions = %q, want the text of the HTML", current.Actions)
+	}
+
+	if event := statuses[1]; event.ResourceID != vm || event.Cause != CauseUser || event.ResolvedSummary != "Stopped" || event.Resolved.IsZero() {
+		t.Errorf("earlier event = %+v", event)
+	}
+	if unknown := statuses[2]; unknown.State != Unknown || unknown.Cause != "" {
+		t.Errorf("status without properties = %+v, want Unknown", unknown)
+	}
+}
+
+func TestParseEvents(t *testing.T) {
+	data := []byte(`{"value": [{
+		"name": "VN4K-1T8",
+		"properties": {
+			"eventType": "ServiceIssue",
+			"status": "Active",
+			"title": "Virtual Machines - West Europe",
+			"summary": "<p>Starting at 08:45 UTC&nbsp;customers may see   errors.</p><p>Next update in 60 minutes.</p>",
+			"impactStartTime": "2025-06-01T08:45:00Z",
+			"lastUpdateTime": "2025-06-01T09:15:00Z",
+			"impact": [
+				{"impactedService": "Virtual Machines", "impactedRegions": [{"impactedRegion": "West Europe"}, {"impactedRegion": "North Europe"}]},
+				{"impactedService": "Virtual Machines", "impactedRegions": [{"impactedRegion": "West Europe"}]}
+			]
+		}
+	}]}`)
+	events, next, err := ParseEvents(data, "sub-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next != "" || len(events) != 1 {
+		t.Fatalf("ParseEvents() = %d events, next %q", len(events), next)
+	}
+	event := events[0]
+	if !event.Active || event.TrackingID != "VN4K-1T8" || !reflect.DeepEqual(event.Subscriptions, []string{"sub-1"}) {
+		t.Errorf("event = %+v", event)
+	}
+	if !reflect.DeepEqual(event.Services, []string{"Virtual Machines"}) || !reflect.DeepEqual(event.Regions, []string{"West Europe", "North Europe"}) {
+		t.Errorf("impact = %q in %q, want each once", event.Services, event.Regions)
+	}
+	if want := "Starting at 08:45 UTC customers may see errors.\n\nNext update in 60 minutes."; event.Summary != want {
+		t.Errorf("Summary = %q, want %q", event.Summary, want)
+	}
+
+	if !event.Affects([]string{"eastus", "westeurope"}) || event.Affects([]string{"eastus"}) {
+		t.Error("Affects() should match regions by their normalized name")
+	}
+	if !(Event{Regions: []string{"Global"}}).Affects([]string{"eastus"}) {
+		t.Error("global events should affect every region")
+	}
+}
+
+func TestMergeEvents(t *testing.T) {
+	at := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	events := MergeEvents([]Event{
+		{TrackingID: "OLD", Updated: at.Add(-time.Hour), Subscriptions: []string{"sub-1"}},
+		{TrackingID: "NEW", Updated: at, Subscriptions: []string{"sub-1"}},
+		{TrackingID: "ACTIVE", Active: true, Updated: at.Add(-48 * time.Hour), Subscriptions: []string{"sub-1"}},
+		{TrackingID: "NEW", Updated: at, Subscriptions: []string{"sub-2"}},
+	})
+
+	var order []string
+	for _, event := range events {
+		order = append(order, event.TrackingID)
+	}
+	if want := []string{"ACTIVE", "NEW", "OLD"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("MergeEvents() order = %q, want %q", order, want)
+	}
+	if !reflect.DeepEqual(events[1].Subscriptions, []string{"sub-1", "sub-2"}) {
+		t.Errorf("merged subscriptions = %q, want both", events[1].Subscriptions)
+	}
+}
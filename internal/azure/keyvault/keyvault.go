@@ -4,9 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os/exec"
 	"strings"
 	"time"
+
+	"github.com/olafkfreund/azure-tui/internal/azcli"
 )
 
 type KeyVault struct {
@@ -35,7 +36,7 @@ type SecretAttributes struct {
 }
 
 func ListKeyVaults() ([]KeyVault, error) {
-	cmd := exec.Command("az", "keyvault", "list", "--output", "json")
+	cmd := azcli.Command("keyvault", "list", "--output", "json")
 	out, err := cmd.Output()
 	if err != nil {
 		return nil, err
@@ -48,11 +49,11 @@ func ListKeyVaults() ([]KeyVault, error) {
 }
 
 func CreateKeyVault(name, group, location string) error {
-	return exec.Command("az", "keyvault", "create", "--name", name, "--resource-group", group, "--location", location).Run()
+	return azcli.Command("keyvault", "create", "--name", name, "--resource-group", group, "--location", location).Run()
 }
 
 func DeleteKeyVault(name, group string) error {
-	return exec.Command("az", "keyvault", "delete", "--name", name, "--resource-group", group).Run()
+	return azcli.Command("keyvault", "delete", "--name", name, "--resource-group", group).Run()
 }
 
 // =============================================================================
@@ -64,7 +65,7 @@ func ListSecrets(vaultName string) ([]Secret, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, "az", "keyvault", "secret", "list",
+	cmd := azcli.CommandContext(ctx, "keyvault", "secret", "list",
 		"--vault-name", vaultName, "--output", "json")
 
 	output, err := cmd.Output()
@@ -99,7 +100,7 @@ func CreateSecret(vaultName, secretName, secretValue string, tags map[string]str
 		args = append(args, "--tags", strings.Join(tagStrings, " "))
 	}
 
-	cmd := exec.CommandContext(ctx, "az", args...)
+	cmd := azcli.CommandContext(ctx, args...)
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to create secret: %v", err)
 	}
@@ -112,7 +113,7 @@ func DeleteSecret(vaultName, secretName string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, "az", "keyvault", "secret", "delete",
+	cmd := azcli.CommandContext(ctx, "keyvault", "secret", "delete",
 		"--vault-name", vaultName,
 		"--name", secretName)
 
@@ -128,7 +129,7 @@ func GetSecretMetadata(vaultName, secretName string) (*Secret, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, "az", "keyvault", "secret", "show",
+	cmd := azcli.CommandContext(ctx, "keyvault", "secret", "show",
 		"--vault-name", vaultName,
 		"--name", secretName,
 		"--output", "json")
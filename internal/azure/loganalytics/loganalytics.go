@@ -0,0 +1,288 @@
+// Package loganalytics runs KQL queries against Log Analytics workspaces
+// and exports their results
+package loganalytics
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/olafkfreund/azure-tui/internal/azcli"
+)
+
+// Workspace is a Log Analytics workspace
+type Workspace struct {
+	ID            string // ARM resource ID
+	Name          string
+	ResourceGroup string
+	Location      string
+	CustomerID    string // Workspace ID the query API takes
+}
+
+// Timespan is how far back a query looks
+type Timespan struct {
+	Label    string
+	Duration string // ISO 8601 duration
+}
+
+// Timespans are the time ranges of the console
+var Timespans = []Timespan{
+	{"1h", "PT1H"},
+	{"24h", "P1D"},
+	{"7d", "P7D"},
+	{"30d", "P30D"},
+}
+
+// ListWorkspaces returns the workspaces of the subscription
+func ListWorkspaces() ([]Workspace, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	output, err := azcli.CommandContext(ctx, "monitor", "log-analytics", "workspace", "list", "--output", "json").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Log Analytics workspaces: %v", err)
+	}
+	return ParseWorkspaces(output)
+}
+
+// ParseWorkspaces parses the output of az monitor log-analytics workspace
+// list, sorted by name
+func ParseWorkspaces(data []byte) ([]Workspace, error) {
+	var response []struct {
+		ID            string `json:"id"`
+		Name          string `json:"name"`
+		ResourceGroup string `json:"resourceGroup"`
+		Location      string `json:"location"`
+		CustomerID    string `json:"customerId"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse Log Analytics workspaces: %v", err)
+	}
+
+	workspaces := make([]Workspace, 0, len(response))
+	for _, item := range response {
+		workspaces = append(workspaces, Workspace(item))
+	}
+	sort.SliceStable(workspaces, func(i, j int) bool {
+		return strings.ToLower(workspaces[i].Name) < strings.ToLower(workspaces[j].Name)
+	})
+	return workspaces, nil
+}
+
+// DefaultWorkspace returns the index of the workspace to query for a
+// resource: one in its resource group, or else the first. It returns -1
+// when there are no workspaces.
+func DefaultWorkspace(workspaces []Workspace, resourceID string) int {
+	if len(workspaces) == 0 {
+		return -1
+	}
+	parts := strings.Split(resourceID, "/")
+	for i, part := range parts {
+		if strings.EqualFold(part, "resourceGroups") && i+1 < len(parts) {
+			for w, workspace := range workspaces {
+				if strings.EqualFold(workspace.ResourceGroup, parts[i+1]) {
+					return w
+				}
+			}
+		}
+	}
+	return 0
+}
+
+// Column is a column of a result
+type Column struct {
+	Name string
+	Type string // KQL type, e.g. "string", "long", "real", "datetime"
+}
+
+// Numeric reports whether the column holds numbers
+func (c Column) Numeric() bool {
+	switch c.Type {
+	case "int", "long", "real", "decimal":
+		return true
+	}
+	return false
+}
+
+// Result is the primary table of a query
+type Result struct {
+	Columns []Column
+	Rows    [][]string // Values as text, "" for null
+}
+
+// Query runs a KQL query on a workspace over the timespan up to now
+func Query(workspace Workspace, query string, timespan Timespan) (*Result, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	body, err := json.Marshal(map[string]string{"query": query, "timespan": timespan.Duration})
+	if err != nil {
+		return nil, err
+	}
+	cmd := azcli.CommandContext(ctx, "rest", "--method", "post",
+		"--url", "https://api.loganalytics.io/v1/workspaces/"+workspace.CustomerID+"/query",
+		"--resource", "https://api.loganalytics.io",
+		"--body", string(body),
+		"--output", "json")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		// The query API explains syntax errors on stderr
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("query failed: %s", message)
+		}
+		return nil, fmt.Errorf("query failed: %v", err)
+	}
+	return ParseResult(output)
+}
+
+// ParseResult parses the response of the query API, keeping its first
+// table
+func ParseResult(data []byte) (*Result, error) {
+	var response struct {
+		Tables []struct {
+			Columns []struct {
+				Name string `json:"name"`
+				Type string `json:"type"`
+			} `json:"columns"`
+			Rows [][]json.RawMessage `json:"rows"`
+		} `json:"tables"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse query results: %v", err)
+	}
+
+	result := &Result{}
+	if len(response.Tables) == 0 {
+		return result, nil
+	}
+	table := response.Tables[0]
+	for _, column := range table.Columns {
+		result.Columns = append(result.Columns, Column{Name: column.Name, Type: column.Type})
+	}
+	for _, row := range table.Rows {
+		values := make([]string, len(row))
+		for i, raw := range row {
+			values[i] = cellText(raw)
+		}
+		result.Rows = append(result.Rows, values)
+	}
+	return result, nil
+}
+
+// cellText returns a value as text: strings unquoted, null empty and
+// dynamic values as compact JSON
+func cellText(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	if string(raw) == "null" {
+		return ""
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, raw); err == nil {
+		return compact.String()
+	}
+	return string(raw)
+}
+
+// Sort orders the rows by a column, numbers by value and everything else
+// as text. Timestamps are ISO 8601 and sort as text.
+func (r *Result) Sort(column int, descending bool) {
+	if column < 0 || column >= len(r.Columns) {
+		return
+	}
+	numeric := r.Columns[column].Numeric()
+	less := func(a, b string) bool {
+		if numeric {
+			x, errX := strconv.ParseFloat(a, 64)
+			y, errY := strconv.ParseFloat(b, 64)
+			if errX == nil && errY == nil {
+				return x < y
+			}
+			// Nulls sort first
+			return errX != nil && errY == nil
+		}
+		return a < b
+	}
+	sort.SliceStable(r.Rows, func(i, j int) bool {
+		a, b := r.Rows[i][column], r.Rows[j][column]
+		if descending {
+			return less(b, a)
+		}
+		return less(a, b)
+	})
+}
+
+// WriteCSV writes the result as CSV with a header row
+func (r *Result) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	header := make([]string, len(r.Columns))
+	for i, column := range r.Columns {
+		header[i] = column.Name
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	if err := writer.WriteAll(r.Rows); err != nil {
+		return err
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// JSON returns the rows as an array of objects with the columns in order.
+// Numbers, booleans and dynamic values keep their JSON type.
+func (r *Result) JSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString("[")
+	for i, row := range r.Rows {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		b.WriteString("\n  {")
+		for c, column := range r.Columns {
+			if c > 0 {
+				b.WriteString(", ")
+			}
+			key, _ := json.Marshal(column.Name)
+			b.Write(key)
+			b.WriteString(": ")
+			b.Write(jsonValue(column, row[c]))
+		}
+		b.WriteString("}")
+	}
+	if len(r.Rows) > 0 {
+		b.WriteString("\n")
+	}
+	b.WriteString("]\n")
+	return b.Bytes(), nil
+}
+
+// jsonValue encodes a cell by the type of its column
+func jsonValue(column Column, value string) []byte {
+	if value == "" && column.Type != "string" {
+		return []byte("null")
+	}
+	switch {
+	case column.Numeric() || column.Type == "bool":
+		if json.Valid([]byte(value)) {
+			return []byte(value)
+		}
+	case column.Type == "dynamic":
+		if json.Valid([]byte(value)) {
+			return []byte(value)
+		}
+	}
+	quoted, _ := json.Marshal(value)
+	return quoted
+}
@@ -0,0 +1,153 @@
+package loganalytics
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseWorkspaces(t *testing.T) {
+	data := []byte(`[
+		{"id": "/subscriptions/sub-1/resourceGroups/rg-shared/providers/Microsoft.OperationalInsights/workspaces/log-shared", "name": "log-shared", "resourceGroup": "rg-shared", "location": "westeurope", "customerId": "1111"},
+		{"id": "/subscriptions/sub-1/resourceGroups/rg-prod/providers/Microsoft.OperationalInsights/workspaces/Log-prod", "name": "Log-prod", "resourceGroup": "rg-prod", "location": "westeurope", "customerId": "2222"}
+	]`)
+	workspaces, err := ParseWorkspaces(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(workspaces) != 2 || workspaces[0].Name != "Log-prod" || workspaces[0].CustomerID != "2222" {
+		t.Fatalf("ParseWorkspaces() = %+v, want Log-prod first", workspaces)
+	}
+
+	vm := "/subscriptions/sub-1/resourceGroups/RG-SHARED/providers/Microsoft.Compute/virtualMachines/vm-1"
+	if got := DefaultWorkspace(workspaces, vm); got != 1 {
+		t.Errorf("DefaultWorkspace(resource in rg-shared) = %d, want 1", got)
+	}
+	if got := DefaultWorkspace(workspaces, ""); got != 0 {
+		t.Errorf("DefaultWorkspace(no resource) = %d, want 0", got)
+	}
+	if got := DefaultWorkspace(nil, vm); got != -1 {
+		t.Errorf("DefaultWorkspace(no workspaces) = %d, want -1", got)
+	}
+}
+
+// testResult parses a result with a text, a number and a dynamic column
+func testResult(t *testing.T) *Result {
+	t.Helper()
+	data := []byte(`{"tables": [{
+		"name": "PrimaryResult",
+		"columns": [{"name": "Computer", "type": "string"}, {"name": "Count", "type": "long"}, {"name": "Tags", "type": "dynamic"}],
+		"rows": [
+			["vm-b", 10, {"env": "prod"}],
+			["vm-a", 9, null],
+			["vm, \"c\"", null, [1, 2]]
+		]
+	}]}`)
+	result, err := ParseResult(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func TestParseResult(t *testing.T) {
+	result := testResult(t)
+	want := [][]string{
+		{"vm-b", "10", `{"env":"prod"}`},
+		{"vm-a", "9", ""},
+		{`vm, "c"`, "", "[1,2]"},
+	}
+	if !reflect.DeepEqual(result.Rows, want) {
+		t.Errorf("Rows = %q, want %q", result.Rows, want)
+	}
+	if !result.Columns[1].Numeric() || result.Columns[0].Numeric() {
+		t.Errorf("Numeric() is wrong for %+v", result.Columns)
+	}
+
+	empty, err := ParseResult([]byte(`{"tables": []}`))
+	if err != nil || len(empty.Rows) != 0 {
+		t.Errorf("ParseResult(no tables) = %+v, %v", empty, err)
+	}
+	if _, err := ParseResult([]byte("not json")); err == nil {
+		t.Error("ParseResult(not json) should fail")
+	}
+}
+
+func TestResultSort(t *testing.T) {
+	result := testResult(t)
+	column := func() []string {
+		var values []string
+		for _, row := range result.Rows {
+			values = append(values, row[0])
+		}
+		return values
+	}
+
+	// Numbers sort by value, not as text, and nulls first
+	result.Sort(1, false)
+	if got, want := column(), []string{`vm, "c"`, "vm-a", "vm-b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Sort(Count) = %q, want %q", got, want)
+	}
+	result.Sort(0, true)
+	if got, want := column(), []string{"vm-b", "vm-a", `vm, "c"`}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Sort(Computer, descending) = %q, want %q", got, want)
+	}
+}
+
+func TestResultExport(t *testing.T) {
+	result := testResult(t)
+
+	var csv bytes.Buffer
+	if err := result.WriteCSV(&csv); err != nil {
+		t.Fatal(err)
+	}
+	want := "Computer,Count,Tags\nvm-b,10,\"{\"\"env\"\":\"\"prod\"\"}\"\nvm-a,9,\n\"vm, \"\"c\"\"\",,\"[1,2]\"\n"
+	if csv.String() != want {
+		t.Errorf("WriteCSV() = %q, want %q", csv.String(), want)
+	}
+
+	data, err := result.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rows []map[string]any
+	if err := json.Unmarshal(data, &rows); err != nil {
+		t.Fatalf("JSON() is not valid JSON: %v\n%s", err, data)
+	}
+	if len(rows) != 3 || rows[0]["Count"] != float64(10) || rows[1]["Tags"] != nil || rows[2]["Computer"] != `vm, "c"` {
+		t.Errorf("JSON() = %v", rows)
+	}
+	if tags, ok := rows[0]["Tags"].(map[string]any); !ok || tags["env"] != "prod" {
+		t.Errorf("JSON() should keep dynamic values as objects, got %v", rows[0]["Tags"])
+	}
+	// Columns keep the order of the query
+	if !strings.HasPrefix(strings.TrimSpace(string(data)), `[
+  {"Computer": "vm-b", "Count": 10, "Tags": {"env":"prod"}}`) {
+		t.Errorf("JSON() does not keep the column order:\n%s", data)
+	}
+}
+
+func TestStarters(t *testing.T) {
+	id := "/subscriptions/sub-1/resourceGroups/RG-Prod/providers/Microsoft.Compute/virtualMachines/vm-1"
+	starters := Starters("Microsoft.Compute/virtualMachines", id, "vm-1")
+	if len(starters) < len(generalStarters)+1 || starters[0].Name != "CPU over time" {
+		t.Fatalf("Starters(VM) = %+v, want the VM queries first", starters)
+	}
+	for _, starter := range starters {
+		if strings.Contains(starter.Query, "{id}") || strings.Contains(starter.Query, "{name}") {
+			t.Errorf("starter %q has a placeholder left: %s", starter.Name, starter.Query)
+		}
+	}
+	if !strings.Contains(starters[0].Query, strings.ToLower(id)) {
+		t.Errorf("starter %q does not filter by the resource: %s", starters[0].Name, starters[0].Query)
+	}
+
+	if got := Starters("Microsoft.Unknown/things", id, "x"); len(got) != len(generalStarters) {
+		t.Errorf("Starters(unknown type) = %d queries, want the %d general ones", len(got), len(generalStarters))
+	}
+	if got := Starters("", "", ""); len(got) != len(workspaceStarters) {
+		t.Errorf("Starters(no resource) = %d queries, want the %d workspace ones", len(got), len(workspaceStarters))
+	}
+}
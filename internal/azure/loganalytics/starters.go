@@ -0,0 +1,73 @@
+package loganalytics
+
+import (
+	"strings"
+)
+
+// Starter is a ready-made query for a kind of resource
+type Starter struct {
+	Name  string
+	Query string
+}
+
+// starters by resource type, lower case. {id} is replaced by the resource
+// ID and {name} by its name.
+var starters = map[string][]Starter{
+	"microsoft.compute/virtualmachines": {
+		{"CPU over time", "Perf\n| where _ResourceId =~ \"{id}\"\n| where ObjectName == \"Processor\" and CounterName == \"% Processor Time\"\n| summarize avg(CounterValue) by bin(TimeGenerated, 5m)\n| order by TimeGenerated desc"},
+		{"Heartbeats", "Heartbeat\n| where _ResourceId =~ \"{id}\"\n| summarize LastHeartbeat = max(TimeGenerated) by Computer, OSType, Version"},
+		{"Free disk space", "Perf\n| where _ResourceId =~ \"{id}\"\n| where CounterName == \"% Free Space\"\n| summarize arg_max(TimeGenerated, CounterValue) by InstanceName"},
+	},
+	"microsoft.containerservice/managedclusters": {
+		{"Container errors", "ContainerLogV2\n| where _ResourceId =~ \"{id}\"\n| where LogLevel in (\"error\", \"critical\")\n| project TimeGenerated, PodNamespace, PodName, ContainerName, LogMessage\n| order by TimeGenerated desc\n| take 100"},
+		{"Pods not running", "KubePodInventory\n| where _ResourceId =~ \"{id}\"\n| where PodStatus != \"Running\"\n| summarize arg_max(TimeGenerated, PodStatus) by Namespace, Name"},
+		{"Node CPU", "Perf\n| where _ResourceId =~ \"{id}\"\n| where ObjectName == \"K8SNode\" and CounterName == \"cpuUsageNanoCores\"\n| summarize avg(CounterValue) by bin(TimeGenerated, 5m), Computer"},
+	},
+	"microsoft.web/sites": {
+		{"Failed requests", "AppServiceHTTPLogs\n| where _ResourceId =~ \"{id}\"\n| where ScStatus >= 500\n| project TimeGenerated, CsMethod, CsUriStem, ScStatus, TimeTaken\n| order by TimeGenerated desc\n| take 100"},
+		{"Slowest pages", "AppServiceHTTPLogs\n| where _ResourceId =~ \"{id}\"\n| summarize avg(TimeTaken), count() by CsUriStem\n| top 20 by avg_TimeTaken"},
+	},
+	"microsoft.sql/servers/databases": {
+		{"Errors", "AzureDiagnostics\n| where ResourceId =~ \"{id}\"\n| where Category == \"Errors\"\n| project TimeGenerated, error_number_d, Message\n| order by TimeGenerated desc"},
+		{"Timeouts", "AzureDiagnostics\n| where ResourceId =~ \"{id}\"\n| where Category == \"Timeouts\"\n| order by TimeGenerated desc"},
+	},
+	"microsoft.keyvault/vaults": {
+		{"Secret access", "AzureDiagnostics\n| where ResourceId =~ \"{id}\"\n| where OperationName startswith \"Secret\"\n| project TimeGenerated, OperationName, CallerIPAddress, identity_claim_upn_s, ResultSignature\n| order by TimeGenerated desc"},
+		{"Denied requests", "AzureDiagnostics\n| where ResourceId =~ \"{id}\"\n| where ResultSignature == \"Forbidden\"\n| order by TimeGenerated desc"},
+	},
+	"microsoft.storage/storageaccounts": {
+		{"Failed blob requests", "StorageBlobLogs\n| where _ResourceId startswith \"{id}\"\n| where StatusCode >= 400\n| project TimeGenerated, OperationName, StatusCode, StatusText, Uri\n| order by TimeGenerated desc\n| take 100"},
+		{"Requests by operation", "StorageBlobLogs\n| where _ResourceId startswith \"{id}\"\n| summarize count() by OperationName\n| order by count_ desc"},
+	},
+	"microsoft.network/networksecuritygroups": {
+		{"Rule hits", "AzureDiagnostics\n| where ResourceId =~ \"{id}\"\n| where Category == \"NetworkSecurityGroupRuleCounter\"\n| summarize sum(matchedConnections_d) by ruleName_s, direction_s"},
+	},
+}
+
+// generalStarters apply to every resource
+var generalStarters = []Starter{
+	{"Activity log", "AzureActivity\n| where _ResourceId =~ \"{id}\"\n| project TimeGenerated, OperationNameValue, ActivityStatusValue, Caller\n| order by TimeGenerated desc\n| take 100"},
+	{"Diagnostic logs", "AzureDiagnostics\n| where ResourceId =~ \"{id}\"\n| order by TimeGenerated desc\n| take 100"},
+}
+
+// workspaceStarters apply when no resource is selected
+var workspaceStarters = []Starter{
+	{"Tables with data", "search *\n| summarize count() by $table\n| order by count_ desc"},
+	{"Activity log", "AzureActivity\n| project TimeGenerated, ResourceGroup, OperationNameValue, ActivityStatusValue, Caller\n| order by TimeGenerated desc\n| take 100"},
+	{"Failed operations", "AzureActivity\n| where ActivityStatusValue == \"Failure\"\n| summarize count() by OperationNameValue, ResourceGroup\n| order by count_ desc"},
+}
+
+// Starters returns the starter queries for a resource, those of its type
+// first. Without a resource ID they are queries over the whole workspace.
+func Starters(resourceType, resourceID, name string) []Starter {
+	if resourceID == "" {
+		return append([]Starter(nil), workspaceStarters...)
+	}
+
+	replacer := strings.NewReplacer("{id}", strings.ToLower(resourceID), "{name}", name)
+	var result []Starter
+	for _, starter := range append(append([]Starter(nil), starters[strings.ToLower(resourceType)]...), generalStarters...) {
+		result = append(result, Starter{Name: starter.Name, Query: replacer.Replace(starter.Query)})
+	}
+	return result
+}
@@ -0,0 +1,270 @@
+// Package metrics reads Azure Monitor metrics: which metrics a resource
+// has, and their time series for a time range.
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/olafkfreund/azure-tui/internal/azcli"
+)
+
+// Definition describes a metric a resource has
+type Definition struct {
+	Name               string   // e.g. "Percentage CPU"
+	DisplayName        string   // Localized name
+	Unit               string   // e.g. "Percent", "Bytes", "Count"
+	Namespace          string   // e.g. "Microsoft.Compute/virtualMachines"
+	PrimaryAggregation string   // e.g. "Average"
+	Aggregations       []string // Aggregations the metric supports
+	TimeGrains         []string // Intervals Azure keeps, e.g. "PT1M", "PT1H"
+}
+
+// TimeRange is a period to chart and the interval of its data points
+type TimeRange struct {
+	Label    string
+	Duration time.Duration
+	Interval string // ISO 8601 interval, e.g. "PT5M"
+}
+
+// Ranges are the time ranges of the metrics explorer, each with about a
+// hundred data points
+var Ranges = []TimeRange{
+	{"1h", time.Hour, "PT1M"},
+	{"24h", 24 * time.Hour, "PT15M"},
+	{"7d", 7 * 24 * time.Hour, "PT1H"},
+	{"30d", 30 * 24 * time.Hour, "PT6H"},
+}
+
+// Aggregations are the aggregation types of Azure Monitor
+var Aggregations = []string{"Average", "Maximum", "Minimum", "Total", "Count"}
+
+// Point is a data point. Valid is false where Azure has no data.
+type Point struct {
+	Time  time.Time
+	Value float64
+	Valid bool
+}
+
+// Series is the time series of a metric
+type Series struct {
+	Metric      string
+	DisplayName string
+	Unit        string
+	Aggregation string
+	Points      []Point
+}
+
+// Last returns the newest valid value
+func (s Series) Last() (float64, bool) {
+	for i := len(s.Points) - 1; i >= 0; i-- {
+		if s.Points[i].Valid {
+			return s.Points[i].Value, true
+		}
+	}
+	return 0, false
+}
+
+// ListDefinitions returns the metrics of a resource, sorted as Azure lists them
+func ListDefinitions(resourceID string) ([]Definition, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	cmd := azcli.CommandContext(ctx, "monitor", "metrics", "list-definitions",
+		"--resource", resourceID,
+		"--output", "json")
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list metric definitions: %v", err)
+	}
+	return ParseDefinitions(output)
+}
+
+// ParseDefinitions parses the output of az monitor metrics list-definitions
+func ParseDefinitions(data []byte) ([]Definition, error) {
+	var response []struct {
+		Name struct {
+			Value          string `json:"value"`
+			LocalizedValue string `json:"localizedValue"`
+		} `json:"name"`
+		Namespace                 string   `json:"namespace"`
+		Unit                      string   `json:"unit"`
+		PrimaryAggregationType    string   `json:"primaryAggregationType"`
+		SupportedAggregationTypes []string `json:"supportedAggregationTypes"`
+		MetricAvailabilities      []struct {
+			TimeGrain string `json:"timeGrain"`
+		} `json:"metricAvailabilities"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse metric definitions: %v", err)
+	}
+
+	definitions := make([]Definition, 0, len(response))
+	for _, item := range response {
+		definition := Definition{
+			Name:               item.Name.Value,
+			DisplayName:        item.Name.LocalizedValue,
+			Unit:               item.Unit,
+			Namespace:          item.Namespace,
+			PrimaryAggregation: item.PrimaryAggregationType,
+			Aggregations:       item.SupportedAggregationTypes,
+		}
+		for _, availability := range item.MetricAvailabilities {
+			if availability.TimeGrain != "" {
+				definition.TimeGrains = append(definition.TimeGrains, availability.TimeGrain)
+			}
+		}
+		if definition.DisplayName == "" {
+			definition.DisplayName = definition.Name
+		}
+		if definition.PrimaryAggregation == "" {
+			definition.PrimaryAggregation = "Average"
+		}
+		definitions = append(definitions, definition)
+	}
+	return definitions, nil
+}
+
+// Supports reports whether the metric can be aggregated with aggregation.
+// Metrics that do not list their aggregations are assumed to support all.
+func (d Definition) Supports(aggregation string) bool {
+	if len(d.Aggregations) == 0 {
+		return true
+	}
+	for _, supported := range d.Aggregations {
+		if strings.EqualFold(supported, aggregation) {
+			return true
+		}
+	}
+	return false
+}
+
+// Query returns the time series of metrics over the time range up to end
+func Query(resourceID string, metricNames []string, aggregation string, timeRange TimeRange, end time.Time) ([]Series, error) {
+	return query(azcli.CommandContext, resourceID, metricNames, aggregation, timeRange, end)
+}
+
+// Refresh is Query for the periodic refresh of a chart, which is not
+// recorded in the session history
+func Refresh(resourceID string, metricNames []string, aggregation string, timeRange TimeRange, end time.Time) ([]Series, error) {
+	return query(azcli.CommandContextQuiet, resourceID, metricNames, aggregation, timeRange, end)
+}
+
+// query runs az monitor metrics list with command
+func query(command func(context.Context, ...string) *exec.Cmd, resourceID string, metricNames []string, aggregation string, timeRange TimeRange, end time.Time) ([]Series, error) {
+	if len(metricNames) == 0 {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	args := []string{"monitor", "metrics", "list",
+		"--resource", resourceID,
+		"--metrics"}
+	args = append(args, metricNames...)
+	args = append(args,
+		"--aggregation", aggregation,
+		"--interval", timeRange.Interval,
+		"--start-time", end.Add(-timeRange.Duration).UTC().Format(time.RFC3339),
+		"--end-time", end.UTC().Format(time.RFC3339),
+		"--output", "json")
+
+	output, err := command(ctx, args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query metrics: %v", err)
+	}
+	return ParseSeries(output, aggregation)
+}
+
+// ParseSeries parses the output of az monitor metrics list, reading the
+// values of aggregation, Average when it is empty. Metrics split by
+// dimension give one series per time series.
+func ParseSeries(data []byte, aggregation string) ([]Series, error) {
+	var response struct {
+		Value []struct {
+			Name struct {
+				Value          string `json:"value"`
+				LocalizedValue string `json:"localizedValue"`
+			} `json:"name"`
+			Unit       string `json:"unit"`
+			Timeseries []struct {
+				Data []map[string]interface{} `json:"data"`
+			} `json:"timeseries"`
+		} `json:"value"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse metrics: %v", err)
+	}
+
+	// Azure Monitor averages when no aggregation is asked for
+	if aggregation == "" {
+		aggregation = "Average"
+	}
+	// Data points name the aggregation in lower camel case, e.g. "average"
+	field := strings.ToLower(aggregation[:1]) + aggregation[1:]
+
+	var series []Series
+	for _, metric := range response.Value {
+		for _, timeseries := range metric.Timeseries {
+			s := Series{
+				Metric:      metric.Name.Value,
+				DisplayName: metric.Name.LocalizedValue,
+				Unit:        metric.Unit,
+				Aggregation: aggregation,
+			}
+			if s.DisplayName == "" {
+				s.DisplayName = s.Metric
+			}
+			for _, point := range timeseries.Data {
+				stamp, _ := point["timeStamp"].(string)
+				t, err := time.Parse(time.RFC3339, stamp)
+				if err != nil {
+					continue
+				}
+				value, ok := point[field].(float64)
+				s.Points = append(s.Points, Point{Time: t, Value: value, Valid: ok})
+			}
+			series = append(series, s)
+		}
+	}
+	return series, nil
+}
+
+// FormatValue formats a value with its unit, e.g. "12.5%" or "3.2 MB"
+func FormatValue(value float64, unit string) string {
+	switch unit {
+	case "Percent":
+		return fmt.Sprintf("%.1f%%", value)
+	case "Bytes":
+		return formatScaled(value, 1024, []string{" B", " KB", " MB", " GB", " TB"})
+	case "BytesPerSecond":
+		return formatScaled(value, 1024, []string{" B/s", " KB/s", " MB/s", " GB/s", " TB/s"})
+	case "MilliSeconds":
+		if value >= 1000 {
+			return fmt.Sprintf("%.1f s", value/1000)
+		}
+		return fmt.Sprintf("%.0f ms", value)
+	case "Seconds":
+		return fmt.Sprintf("%.1f s", value)
+	}
+	return formatScaled(value, 1000, []string{"", "k", "M", "G", "T"})
+}
+
+// formatScaled divides value by base until it is below base
+func formatScaled(value, base float64, suffixes []string) string {
+	i := 0
+	for (value >= base || value <= -base) && i < len(suffixes)-1 {
+		value /= base
+		i++
+	}
+	if value == float64(int64(value)) {
+		return fmt.Sprintf("%.0f%s", value, suffixes[i])
+	}
+	return fmt.Sprintf("%.1f%s", value, suffixes[i])
+}
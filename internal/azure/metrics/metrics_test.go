@@ -0,0 +1,113 @@
+package metrics
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseDefinitions(t *testing.T) {
+	data := []byte(`[
+		{"name": {"value": "Percentage CPU", "localizedValue": "Percentage CPU"}, "unit": "Percent",
+		 "namespace": "Microsoft.Compute/virtualMachines", "primaryAggregationType": "Average",
+		 "supportedAggregationTypes": ["None", "Average", "Minimum", "Maximum"],
+		 "metricAvailabilities": [{"retention": "P93D", "timeGrain": "PT1M"}, {"retention": "P93D", "timeGrain": "PT1H"}]},
+		{"name": {"value": "Network In Total"}, "unit": "Bytes"}
+	]`)
+	definitions, err := ParseDefinitions(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(definitions) != 2 {
+		t.Fatalf("expected 2 definitions, got %d", len(definitions))
+	}
+	cpu := definitions[0]
+	if cpu.Name != "Percentage CPU" || cpu.Unit != "Percent" || cpu.PrimaryAggregation != "Average" {
+		t.Errorf("unexpected definition %+v", cpu)
+	}
+	if !reflect.DeepEqual(cpu.TimeGrains, []string{"PT1M", "PT1H"}) {
+		t.Errorf("TimeGrains = %q", cpu.TimeGrains)
+	}
+	if !cpu.Supports("maximum") || cpu.Supports("Total") {
+		t.Errorf("expected Maximum to be supported and Total not, got %v", cpu.Aggregations)
+	}
+
+	network := definitions[1]
+	if network.DisplayName != "Network In Total" || network.PrimaryAggregation != "Average" {
+		t.Errorf("expected the name and Average as defaults, got %+v", network)
+	}
+	if !network.Supports("Total") {
+		t.Error("expected a metric without aggregations to support all")
+	}
+
+	if _, err := ParseDefinitions([]byte("not json")); err == nil {
+		t.Error("expected an error for invalid output")
+	}
+}
+
+func TestParseSeries(t *testing.T) {
+	data := []byte(`{"value": [{
+		"name": {"value": "Percentage CPU", "localizedValue": "CPU"},
+		"unit": "Percent",
+		"timeseries": [{"data": [
+			{"timeStamp": "2025-01-01T00:00:00Z", "average": 12.5},
+			{"timeStamp": "2025-01-01T00:01:00Z"},
+			{"timeStamp": "2025-01-01T00:02:00Z", "average": 30},
+			{"timeStamp": "bad", "average": 99}
+		]}]
+	}]}`)
+	series, err := ParseSeries(data, "Average")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(series) != 1 {
+		t.Fatalf("expected 1 series, got %d", len(series))
+	}
+	s := series[0]
+	if s.Metric != "Percentage CPU" || s.DisplayName != "CPU" || s.Unit != "Percent" || s.Aggregation != "Average" {
+		t.Errorf("unexpected series %+v", s)
+	}
+	if len(s.Points) != 3 {
+		t.Fatalf("expected 3 points, got %d", len(s.Points))
+	}
+	if !s.Points[0].Valid || s.Points[0].Value != 12.5 || !s.Points[0].Time.Equal(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected first point %+v", s.Points[0])
+	}
+	if s.Points[1].Valid {
+		t.Error("expected a point without a value to be a gap")
+	}
+	if last, ok := s.Last(); !ok || last != 30 {
+		t.Errorf("expected the last value 30, got %v %v", last, ok)
+	}
+
+	// Without an aggregation the averages are read
+	series, err = ParseSeries(data, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last, ok := series[0].Last(); !ok || last != 30 || series[0].Aggregation != "Average" {
+		t.Errorf("expected the averages without an aggregation, got %v %v %q", last, ok, series[0].Aggregation)
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	tests := []struct {
+		value float64
+		unit  string
+		want  string
+	}{
+		{12.34, "Percent", "12.3%"},
+		{512, "Bytes", "512 B"},
+		{3 * 1024 * 1024, "Bytes", "3 MB"},
+		{1536, "BytesPerSecond", "1.5 KB/s"},
+		{250, "MilliSeconds", "250 ms"},
+		{2500, "MilliSeconds", "2.5 s"},
+		{1200, "Count", "1.2k"},
+		{42, "Count", "42"},
+	}
+	for _, tt := range tests {
+		if got := FormatValue(tt.value, tt.unit); got != tt.want {
+			t.Errorf("FormatValue(%v, %q) = %q, want %q", tt.value, tt.unit, got, tt.want)
+		}
+	}
+}
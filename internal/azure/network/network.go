@@ -10,7 +10,9 @@ import (
 	"time"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/olafkfreund/azure-tui/internal/azcli"
 	ai "github.com/olafkfreund/azure-tui/internal/openai"
+	"github.com/olafkfreund/azure-tui/internal/theme"
 	"github.com/olafkfreund/azure-tui/internal/tui"
 )
 
@@ -558,7 +560,7 @@ func GetNetworkDashboardWithProgress(resourceGroup string, progressCallback Prog
 // =============================================================================
 
 func ListVirtualNetworks() ([]VirtualNetwork, error) {
-	cmd := exec.Command("az", "network", "vnet", "list", "--output", "json")
+	cmd := azcli.Command("network", "vnet", "list", "--output", "json")
 	// Set a reasonable timeout
 	cmd.WaitDelay = 30 * time.Second
 
@@ -583,7 +585,7 @@ func ListVirtualNetworks() ([]VirtualNetwork, error) {
 }
 
 func GetVirtualNetworkDetails(name, resourceGroup string) (*VirtualNetwork, error) {
-	cmd := exec.Command("az", "network", "vnet", "show", "--name", name, "--resource-group", resourceGroup, "--output", "json")
+	cmd := azcli.Command("network", "vnet", "show", "--name", name, "--resource-group", resourceGroup, "--output", "json")
 	out, err := cmd.Output()
 	if err != nil {
 		return nil, err
@@ -596,7 +598,7 @@ func GetVirtualNetworkDetails(name, resourceGroup string) (*VirtualNetwork, erro
 }
 
 func CreateVirtualNetwork(name, group, location string) error {
-	return exec.Command("az", "network", "vnet", "create", "--name", name, "--resource-group", group, "--location", location, "--address-prefix", "10.0.0.0/16").Run()
+	return azcli.Command("network", "vnet", "create", "--name", name, "--resource-group", group, "--location", location, "--address-prefix", "10.0.0.0/16").Run()
 }
 
 func CreateVirtualNetworkAdvanced(name, group, location string, addressPrefixes []string, dnsServers []string) error {
@@ -614,11 +616,11 @@ func CreateVirtualNetworkAdvanced(name, group, location string, addressPrefixes
 		args = append(args, dnsServers...)
 	}
 
-	return exec.Command("az", args...).Run()
+	return azcli.Command(args...).Run()
 }
 
 func DeleteVirtualNetwork(name, group string) error {
-	return exec.Command("az", "network", "vnet", "delete", "--name", name, "--resource-group", group, "--yes").Run()
+	return azcli.Command("network", "vnet", "delete", "--name", name, "--resource-group", group, "--yes").Run()
 }
 
 // =============================================================================
@@ -626,7 +628,7 @@ func DeleteVirtualNetwork(name, group string) error {
 // =============================================================================
 
 func ListSubnets(vnetName, resourceGroup string) ([]Subnet, error) {
-	cmd := exec.Command("az", "network", "vnet", "subnet", "list", "--vnet-name", vnetName, "--resource-group", resourceGroup, "--output", "json")
+	cmd := azcli.Command("network", "vnet", "subnet", "list", "--vnet-name", vnetName, "--resource-group", resourceGroup, "--output", "json")
 	out, err := cmd.Output()
 	if err != nil {
 		return nil, err
@@ -639,7 +641,7 @@ func ListSubnets(vnetName, resourceGroup string) ([]Subnet, error) {
 }
 
 func CreateSubnet(name, vnetName, resourceGroup, addressPrefix string) error {
-	return exec.Command("az", "network", "vnet", "subnet", "create",
+	return azcli.Command("network", "vnet", "subnet", "create",
 		"--name", name,
 		"--vnet-name", vnetName,
 		"--resource-group", resourceGroup,
@@ -648,7 +650,7 @@ func CreateSubnet(name, vnetName, resourceGroup, addressPrefix string) error {
 
 func AssociateSubnetWithNSG(subnetName, vnetName, resourceGroup, nsgName string) error {
 	nsgID := fmt.Sprintf("/subscriptions/$(az account show --query id -o tsv)/resourceGroups/%s/providers/Microsoft.Network/networkSecurityGroups/%s", resourceGroup, nsgName)
-	return exec.Command("az", "network", "vnet", "subnet", "update",
+	return azcli.Command("network", "vnet", "subnet", "update",
 		"--name", subnetName,
 		"--vnet-name", vnetName,
 		"--resource-group", resourceGroup,
@@ -657,7 +659,7 @@ func AssociateSubnetWithNSG(subnetName, vnetName, resourceGroup, nsgName string)
 
 func AssociateSubnetWithRouteTable(subnetName, vnetName, resourceGroup, routeTableName string) error {
 	routeTableID := fmt.Sprintf("/subscriptions/$(az account show --query id -o tsv)/resourceGroups/%s/providers/Microsoft.Network/routeTables/%s", resourceGroup, routeTableName)
-	return exec.Command("az", "network", "vnet", "subnet", "update",
+	return azcli.Command("network", "vnet", "subnet", "update",
 		"--name", subnetName,
 		"--vnet-name", vnetName,
 		"--resource-group", resourceGroup,
@@ -665,7 +667,7 @@ func AssociateSubnetWithRouteTable(subnetName, vnetName, resourceGroup, routeTab
 }
 
 func DeleteSubnet(name, vnetName, resourceGroup string) error {
-	return exec.Command("az", "network", "vnet", "subnet", "delete",
+	return azcli.Command("network", "vnet", "subnet", "delete",
 		"--name", name,
 		"--vnet-name", vnetName,
 		"--resource-group", resourceGroup).Run()
@@ -676,7 +678,7 @@ func DeleteSubnet(name, vnetName, resourceGroup string) error {
 // =============================================================================
 
 func ListNetworkSecurityGroups() ([]NetworkSecurityGroup, error) {
-	cmd := exec.Command("az", "network", "nsg", "list", "--output", "json")
+	cmd := azcli.Command("network", "nsg", "list", "--output", "json")
 	cmd.WaitDelay = 30 * time.Second
 
 	out, err := cmd.Output()
@@ -699,7 +701,7 @@ func ListNetworkSecurityGroups() ([]NetworkSecurityGroup, error) {
 }
 
 func GetNetworkSecurityGroupDetails(name, resourceGroup string) (*NetworkSecurityGroup, error) {
-	cmd := exec.Command("az", "network", "nsg", "show", "--name", name, "--resource-group", resourceGroup, "--output", "json")
+	cmd := azcli.Command("network", "nsg", "show", "--name", name, "--resource-group", resourceGroup, "--output", "json")
 	out, err := cmd.Output()
 	if err != nil {
 		return nil, err
@@ -712,11 +714,11 @@ func GetNetworkSecurityGroupDetails(name, resourceGroup string) (*NetworkSecurit
 }
 
 func CreateNetworkSecurityGroup(name, resourceGroup, location string) error {
-	return exec.Command("az", "network", "nsg", "create", "--name", name, "--resource-group", resourceGroup, "--location", location).Run()
+	return azcli.Command("network", "nsg", "create", "--name", name, "--resource-group", resourceGroup, "--location", location).Run()
 }
 
 func CreateSecurityRule(nsgName, resourceGroup, ruleName string, priority int, direction, access, protocol, sourcePort, destPort, sourceAddress, destAddress string) error {
-	return exec.Command("az", "network", "nsg", "rule", "create",
+	return azcli.Command("network", "nsg", "rule", "create",
 		"--nsg-name", nsgName,
 		"--resource-group", resourceGroup,
 		"--name", ruleName,
@@ -731,14 +733,14 @@ func CreateSecurityRule(nsgName, resourceGroup, ruleName string, priority int, d
 }
 
 func DeleteSecurityRule(nsgName, resourceGroup, ruleName string) error {
-	return exec.Command("az", "network", "nsg", "rule", "delete",
+	return azcli.Command("network", "nsg", "rule", "delete",
 		"--nsg-name", nsgName,
 		"--resource-group", resourceGroup,
 		"--name", ruleName).Run()
 }
 
 func DeleteNetworkSecurityGroup(name, resourceGroup string) error {
-	return exec.Command("az", "network", "nsg", "delete", "--name", name, "--resource-group", resourceGroup).Run()
+	return azcli.Command("network", "nsg", "delete", "--name", name, "--resource-group", resourceGroup).Run()
 }
 
 // =============================================================================
@@ -746,7 +748,7 @@ func DeleteNetworkSecurityGroup(name, resourceGroup string) error {
 // =============================================================================
 
 func ListRouteTables() ([]RouteTable, error) {
-	cmd := exec.Command("az", "network", "route-table", "list", "--output", "json")
+	cmd := azcli.Command("network", "route-table", "list", "--output", "json")
 	cmd.WaitDelay = 30 * time.Second
 
 	out, err := cmd.Output()
@@ -769,7 +771,7 @@ func ListRouteTables() ([]RouteTable, error) {
 }
 
 func CreateRouteTable(name, resourceGroup, location string) error {
-	return exec.Command("az", "network", "route-table", "create", "--name", name, "--resource-group", resourceGroup, "--location", location).Run()
+	return azcli.Command("network", "route-table", "create", "--name", name, "--resource-group", resourceGroup, "--location", location).Run()
 }
 
 func CreateRoute(routeTableName, resourceGroup, routeName, addressPrefix, nextHopType, nextHopAddress string) error {
@@ -784,18 +786,18 @@ func CreateRoute(routeTableName, resourceGroup, routeName, addressPrefix, nextHo
 		args = append(args, "--next-hop-ip-address", nextHopAddress)
 	}
 
-	return exec.Command("az", args...).Run()
+	return azcli.Command(args...).Run()
 }
 
 func DeleteRoute(routeTableName, resourceGroup, routeName string) error {
-	return exec.Command("az", "network", "route-table", "route", "delete",
+	return azcli.Command("network", "route-table", "route", "delete",
 		"--route-table-name", routeTableName,
 		"--resource-group", resourceGroup,
 		"--name", routeName).Run()
 }
 
 func DeleteRouteTable(name, resourceGroup string) error {
-	return exec.Command("az", "network", "route-table", "delete", "--name", name, "--resource-group", resourceGroup).Run()
+	return azcli.Command("network", "route-table", "delete", "--name", name, "--resource-group", resourceGroup).Run()
 }
 
 // =============================================================================
@@ -803,7 +805,7 @@ func DeleteRouteTable(name, resourceGroup string) error {
 // =============================================================================
 
 func ListPublicIPs() ([]PublicIP, error) {
-	cmd := exec.Command("az", "network", "public-ip", "list", "--output", "json")
+	cmd := azcli.Command("network", "public-ip", "list", "--output", "json")
 	out, err := cmd.Output()
 	if err != nil {
 		return nil, err
@@ -816,7 +818,7 @@ func ListPublicIPs() ([]PublicIP, error) {
 }
 
 func CreatePublicIP(name, resourceGroup, location, allocationMethod, sku string) error {
-	return exec.Command("az", "network", "public-ip", "create",
+	return azcli.Command("network", "public-ip", "create",
 		"--name", name,
 		"--resource-group", resourceGroup,
 		"--location", location,
@@ -825,7 +827,7 @@ func CreatePublicIP(name, resourceGroup, location, allocationMethod, sku string)
 }
 
 func DeletePublicIP(name, resourceGroup string) error {
-	return exec.Command("az", "network", "public-ip", "delete", "--name", name, "--resource-group", resourceGroup).Run()
+	return azcli.Command("network", "public-ip", "delete", "--name", name, "--resource-group", resourceGroup).Run()
 }
 
 // =============================================================================
@@ -833,7 +835,7 @@ func DeletePublicIP(name, resourceGroup string) error {
 // =============================================================================
 
 func ListNetworkInterfaces() ([]NetworkInterface, error) {
-	cmd := exec.Command("az", "network", "nic", "list", "--output", "json")
+	cmd := azcli.Command("network", "nic", "list", "--output", "json")
 	out, err := cmd.Output()
 	if err != nil {
 		return nil, err
@@ -860,11 +862,11 @@ func CreateNetworkInterface(name, resourceGroup, location, subnetID, publicIPNam
 		args = append(args, "--network-security-group", nsgName)
 	}
 
-	return exec.Command("az", args...).Run()
+	return azcli.Command(args...).Run()
 }
 
 func DeleteNetworkInterface(name, resourceGroup string) error {
-	return exec.Command("az", "network", "nic", "delete", "--name", name, "--resource-group", resourceGroup).Run()
+	return azcli.Command("network", "nic", "delete", "--name", name, "--resource-group", resourceGroup).Run()
 }
 
 // =============================================================================
@@ -872,7 +874,7 @@ func DeleteNetworkInterface(name, resourceGroup string) error {
 // =============================================================================
 
 func ListLoadBalancers() ([]LoadBalancer, error) {
-	cmd := exec.Command("az", "network", "lb", "list", "--output", "json")
+	cmd := azcli.Command("network", "lb", "list", "--output", "json")
 	out, err := cmd.Output()
 	if err != nil {
 		return nil, err
@@ -895,11 +897,11 @@ func CreateLoadBalancer(name, resourceGroup, location, sku, publicIPName string)
 		args = append(args, "--public-ip-address", publicIPName)
 	}
 
-	return exec.Command("az", args...).Run()
+	return azcli.Command(args...).Run()
 }
 
 func DeleteLoadBalancer(name, resourceGroup string) error {
-	return exec.Command("az", "network", "lb", "delete", "--name", name, "--resource-group", resourceGroup).Run()
+	return azcli.Command("network", "lb", "delete", "--name", name, "--resource-group", resourceGroup).Run()
 }
 
 // =============================================================================
@@ -907,7 +909,7 @@ func DeleteLoadBalancer(name, resourceGroup string) error {
 // =============================================================================
 
 func ListFirewalls() ([]Firewall, error) {
-	cmd := exec.Command("az", "network", "firewall", "list", "--output", "json")
+	cmd := azcli.Command("network", "firewall", "list", "--output", "json")
 	out, err := cmd.Output()
 	if err != nil {
 		// Azure Firewall extension may not be installed - return empty list instead of error
@@ -921,11 +923,11 @@ func ListFirewalls() ([]Firewall, error) {
 }
 
 func CreateFirewall(name, group, location string) error {
-	return exec.Command("az", "network", "firewall", "create", "--name", name, "--resource-group", group, "--location", location).Run()
+	return azcli.Command("network", "firewall", "create", "--name", name, "--resource-group", group, "--location", location).Run()
 }
 
 func DeleteFirewall(name, group string) error {
-	return exec.Command("az", "network", "firewall", "delete", "--name", name, "--resource-group", group).Run()
+	return azcli.Command("network", "firewall", "delete", "--name", name, "--resource-group", group).Run()
 }
 
 // =============================================================================
@@ -980,7 +982,7 @@ func getNetworkTopology(dashboard *NetworkDashboard) NetworkTopology {
 }
 
 func getVNetPeerings(vnetName, resourceGroup string) ([]PeeringStatus, error) {
-	cmd := exec.Command("az", "network", "vnet", "peering", "list",
+	cmd := azcli.Command("network", "vnet", "peering", "list",
 		"--vnet-name", vnetName,
 		"--resource-group", resourceGroup,
 		"--output", "json")
@@ -1076,7 +1078,12 @@ output vnetId string = virtualNetwork.id
 // RenderNetworkDashboard renders a comprehensive network resource dashboard
 func RenderNetworkDashboard() string {
 	dashboard, err := GetNetworkDashboard("")
+	return RenderLoadedNetworkDashboard(dashboard, err)
+}
 
+// RenderLoadedNetworkDashboard renders a dashboard returned by
+// GetNetworkDashboard, along with the error it returned
+func RenderLoadedNetworkDashboard(dashboard *NetworkDashboard, err error) string {
 	// Handle complete failures
 	if err != nil && dashboard == nil {
 		return tui.RenderPopup(tui.PopupMsg{
@@ -1121,16 +1128,16 @@ func renderEnhancedNetworkDashboard(dashboard *NetworkDashboard) string {
 	var content strings.Builder
 
 	// Enhanced dashboard header with summary statistics
-	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("39")).Padding(0, 1)
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Current().Primary).Padding(0, 1)
 	content.WriteString(headerStyle.Render("🌐 Azure Network Infrastructure Dashboard"))
 	content.WriteString("\n\n")
 
 	// Network summary section with color-coded metrics
-	summaryStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("10"))
+	summaryStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Current().Success)
 	content.WriteString(summaryStyle.Render("📊 Network Summary"))
 	content.WriteString("\n")
 
-	metricStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("14"))
+	metricStyle := lipgloss.NewStyle().Foreground(theme.Current().Info)
 	content.WriteString(fmt.Sprintf("Virtual Networks: %s  •  Security Groups: %s  •  Subnets: %s\n",
 		metricStyle.Render(fmt.Sprintf("%d", dashboard.Summary.TotalVNets)),
 		metricStyle.Render(fmt.Sprintf("%d", dashboard.Summary.TotalNSGs)),
@@ -1144,15 +1151,15 @@ func renderEnhancedNetworkDashboard(dashboard *NetworkDashboard) string {
 
 	// Virtual Networks section with hierarchical display
 	if len(dashboard.VirtualNetworks) > 0 {
-		sectionStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("12"))
+		sectionStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Current().Primary)
 		content.WriteString(sectionStyle.Render("🌐 Virtual Networks"))
 		content.WriteString("\n")
 		content.WriteString(strings.Repeat("─", 80) + "\n")
 
 		for _, vnet := range dashboard.VirtualNetworks {
 			// VNet header with location and resource group
-			vnetStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("11"))
-			locationStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+			vnetStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Current().Warning)
+			locationStyle := lipgloss.NewStyle().Foreground(theme.Current().Muted)
 
 			content.WriteString(fmt.Sprintf("%s %s %s\n",
 				vnetStyle.Render(vnet.Name),
@@ -1161,14 +1168,14 @@ func renderEnhancedNetworkDashboard(dashboard *NetworkDashboard) string {
 
 			// Address space
 			if len(vnet.AddressSpace.AddressPrefixes) > 0 {
-				addrStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("6"))
+				addrStyle := lipgloss.NewStyle().Foreground(theme.Current().Info)
 				content.WriteString(fmt.Sprintf("  📍 Address Space: %s\n",
 					addrStyle.Render(strings.Join(vnet.AddressSpace.AddressPrefixes, ", "))))
 			}
 
 			// DNS servers
 			if len(vnet.DnsServers) > 0 {
-				dnsStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("13"))
+				dnsStyle := lipgloss.NewStyle().Foreground(theme.Current().Accent)
 				content.WriteString(fmt.Sprintf("  🌐 DNS Servers: %s\n",
 					dnsStyle.Render(strings.Join(vnet.DnsServers, ", "))))
 			}
@@ -1177,15 +1184,15 @@ func renderEnhancedNetworkDashboard(dashboard *NetworkDashboard) string {
 			if len(vnet.Subnets) > 0 {
 				content.WriteString("  🏠 Subnets:\n")
 				for _, subnet := range vnet.Subnets {
-					subnetStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("7"))
-					protectionStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+					subnetStyle := lipgloss.NewStyle().Foreground(theme.Current().Subtext)
+					protectionStyle := lipgloss.NewStyle().Foreground(theme.Current().Error)
 
 					protectionInfo := ""
 					if subnet.NSGName != "" {
-						protectionInfo += fmt.Sprintf(" 🔒 %s", subnet.NSGName)
+						protectionInfo += fmt.Sprintf(" %s %s", theme.Icon("🔒", "NSG:"), subnet.NSGName)
 					}
 					if subnet.RouteTableName != "" {
-						protectionInfo += fmt.Sprintf(" 🗺️ %s", subnet.RouteTableName)
+						protectionInfo += fmt.Sprintf(" %s %s", theme.Icon("🗺️", "Routes:"), subnet.RouteTableName)
 					}
 
 					content.WriteString(fmt.Sprintf("    ┣━ %s %s%s\n",
@@ -1200,14 +1207,14 @@ func renderEnhancedNetworkDashboard(dashboard *NetworkDashboard) string {
 
 	// Network Security Groups section
 	if len(dashboard.NetworkSecurityGroups) > 0 {
-		sectionStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("9"))
+		sectionStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Current().Error)
 		content.WriteString(sectionStyle.Render("🔒 Network Security Groups"))
 		content.WriteString("\n")
 		content.WriteString(strings.Repeat("─", 80) + "\n")
 
 		for _, nsg := range dashboard.NetworkSecurityGroups {
-			nsgStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("11"))
-			locationStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+			nsgStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Current().Warning)
+			locationStyle := lipgloss.NewStyle().Foreground(theme.Current().Muted)
 
 			content.WriteString(fmt.Sprintf("%s %s %s\n",
 				nsgStyle.Render(nsg.Name),
@@ -1215,18 +1222,18 @@ func renderEnhancedNetworkDashboard(dashboard *NetworkDashboard) string {
 				locationStyle.Render(fmt.Sprintf("[%s]", nsg.ResourceGroup))))
 
 			// Rule count with color coding
-			ruleCountStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("14"))
+			ruleCountStyle := lipgloss.NewStyle().Foreground(theme.Current().Info)
 			if len(nsg.SecurityRules) > 20 {
-				ruleCountStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+				ruleCountStyle = lipgloss.NewStyle().Foreground(theme.Current().Error)
 			} else if len(nsg.SecurityRules) > 10 {
-				ruleCountStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("11"))
+				ruleCountStyle = lipgloss.NewStyle().Foreground(theme.Current().Warning)
 			}
 			content.WriteString(fmt.Sprintf("  📜 Security Rules: %s\n",
 				ruleCountStyle.Render(fmt.Sprintf("%d", len(nsg.SecurityRules)))))
 
 			// Associated resources
 			if len(nsg.Subnets) > 0 || len(nsg.NetworkInterfaces) > 0 {
-				assocStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("6"))
+				assocStyle := lipgloss.NewStyle().Foreground(theme.Current().Info)
 				content.WriteString(fmt.Sprintf("  🔗 Protecting: %s subnets, %s NICs\n",
 					assocStyle.Render(fmt.Sprintf("%d", len(nsg.Subnets))),
 					assocStyle.Render(fmt.Sprintf("%d", len(nsg.NetworkInterfaces)))))
@@ -1237,28 +1244,28 @@ func renderEnhancedNetworkDashboard(dashboard *NetworkDashboard) string {
 
 	// Connectivity section (Public IPs, Load Balancers, etc.)
 	if len(dashboard.PublicIPs) > 0 || len(dashboard.LoadBalancers) > 0 || len(dashboard.Firewalls) > 0 {
-		sectionStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("13"))
+		sectionStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Current().Accent)
 		content.WriteString(sectionStyle.Render("🌍 Connectivity & Security"))
 		content.WriteString("\n")
 		content.WriteString(strings.Repeat("─", 80) + "\n")
 
 		// Public IPs
 		if len(dashboard.PublicIPs) > 0 {
-			subSectionStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("12"))
+			subSectionStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Current().Primary)
 			content.WriteString(subSectionStyle.Render("Public IP Addresses:"))
 			content.WriteString("\n")
 
 			for _, pip := range dashboard.PublicIPs {
-				ipStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("11"))
-				statusStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
+				ipStyle := lipgloss.NewStyle().Foreground(theme.Current().Warning)
+				statusStyle := lipgloss.NewStyle().Foreground(theme.Current().Success)
 				if pip.AllocationMethod == "Dynamic" {
-					statusStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("11"))
+					statusStyle = lipgloss.NewStyle().Foreground(theme.Current().Warning)
 				}
 
 				ipDisplay := pip.IPAddress
 				if ipDisplay == "" {
 					ipDisplay = "Not Assigned"
-					statusStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+					statusStyle = lipgloss.NewStyle().Foreground(theme.Current().Muted)
 				}
 
 				content.WriteString(fmt.Sprintf("  %s %s %s",
@@ -1267,7 +1274,7 @@ func renderEnhancedNetworkDashboard(dashboard *NetworkDashboard) string {
 					statusStyle.Render(ipDisplay)))
 
 				if pip.AssociatedResource != "" {
-					assocStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("6"))
+					assocStyle := lipgloss.NewStyle().Foreground(theme.Current().Info)
 					content.WriteString(fmt.Sprintf(" → %s", assocStyle.Render(pip.AssociatedResource)))
 				}
 				content.WriteString("\n")
@@ -1277,13 +1284,13 @@ func renderEnhancedNetworkDashboard(dashboard *NetworkDashboard) string {
 
 		// Load Balancers
 		if len(dashboard.LoadBalancers) > 0 {
-			subSectionStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("12"))
+			subSectionStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Current().Primary)
 			content.WriteString(subSectionStyle.Render("Load Balancers:"))
 			content.WriteString("\n")
 
 			for _, lb := range dashboard.LoadBalancers {
-				lbStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("11"))
-				skuStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("14"))
+				lbStyle := lipgloss.NewStyle().Foreground(theme.Current().Warning)
+				skuStyle := lipgloss.NewStyle().Foreground(theme.Current().Info)
 
 				content.WriteString(fmt.Sprintf("  %s %s (%d frontends, %d backends)\n",
 					lbStyle.Render(lb.Name),
@@ -1296,13 +1303,13 @@ func renderEnhancedNetworkDashboard(dashboard *NetworkDashboard) string {
 
 		// Azure Firewalls
 		if len(dashboard.Firewalls) > 0 {
-			subSectionStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("12"))
+			subSectionStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Current().Primary)
 			content.WriteString(subSectionStyle.Render("Azure Firewalls:"))
 			content.WriteString("\n")
 
 			for _, fw := range dashboard.Firewalls {
-				fwStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
-				locationStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+				fwStyle := lipgloss.NewStyle().Foreground(theme.Current().Error)
+				locationStyle := lipgloss.NewStyle().Foreground(theme.Current().Muted)
 
 				content.WriteString(fmt.Sprintf("  %s %s\n",
 					fwStyle.Render(fw.Name),
@@ -1314,21 +1321,21 @@ func renderEnhancedNetworkDashboard(dashboard *NetworkDashboard) string {
 
 	// Network topology quick view
 	if len(dashboard.Topology.PeeringStatus) > 0 || len(dashboard.Topology.GatewayStatus) > 0 {
-		sectionStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("5"))
+		sectionStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Current().Accent)
 		content.WriteString(sectionStyle.Render("🗺️ Network Topology"))
 		content.WriteString("\n")
 		content.WriteString(strings.Repeat("─", 80) + "\n")
 
 		// VNet Peerings
 		if len(dashboard.Topology.PeeringStatus) > 0 {
-			peeringStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("6"))
+			peeringStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Current().Info)
 			content.WriteString(peeringStyle.Render("VNet Peerings:"))
 			content.WriteString("\n")
 
 			for _, peering := range dashboard.Topology.PeeringStatus {
-				statusStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
+				statusStyle := lipgloss.NewStyle().Foreground(theme.Current().Success)
 				if peering.PeeringState != "Connected" {
-					statusStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+					statusStyle = lipgloss.NewStyle().Foreground(theme.Current().Error)
 				}
 
 				content.WriteString(fmt.Sprintf("  %s ↔ %s %s\n",
@@ -1341,14 +1348,14 @@ func renderEnhancedNetworkDashboard(dashboard *NetworkDashboard) string {
 
 		// Gateway connections
 		if len(dashboard.Topology.GatewayStatus) > 0 {
-			gatewayStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("6"))
+			gatewayStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Current().Info)
 			content.WriteString(gatewayStyle.Render("Gateway Connections:"))
 			content.WriteString("\n")
 
 			for _, gateway := range dashboard.Topology.GatewayStatus {
-				statusStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
+				statusStyle := lipgloss.NewStyle().Foreground(theme.Current().Success)
 				if gateway.Status != "Connected" {
-					statusStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+					statusStyle = lipgloss.NewStyle().Foreground(theme.Current().Error)
 				}
 
 				content.WriteString(fmt.Sprintf("  %s %s %s %s\n",
@@ -1363,7 +1370,7 @@ func renderEnhancedNetworkDashboard(dashboard *NetworkDashboard) string {
 
 	// Error reporting section
 	if len(dashboard.Errors) > 0 {
-		errorStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("9"))
+		errorStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Current().Error)
 		content.WriteString(errorStyle.Render("⚠️ Issues Detected"))
 		content.WriteString("\n")
 		content.WriteString(strings.Repeat("─", 80) + "\n")
@@ -1373,14 +1380,14 @@ func renderEnhancedNetworkDashboard(dashboard *NetworkDashboard) string {
 				content.WriteString(fmt.Sprintf("  ... and %d more errors\n", len(dashboard.Errors)-5))
 				break
 			}
-			errorMsgStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+			errorMsgStyle := lipgloss.NewStyle().Foreground(theme.Current().Error)
 			content.WriteString(fmt.Sprintf("  • %s\n", errorMsgStyle.Render(err)))
 		}
 		content.WriteString("\n")
 	}
 
 	// Footer with helpful information
-	footerStyle := lipgloss.NewStyle().Faint(true).Foreground(lipgloss.Color("8"))
+	footerStyle := lipgloss.NewStyle().Faint(true).Foreground(theme.Current().Muted)
 	content.WriteString(footerStyle.Render("💡 Use 'V' for VNet details, 'G' for NSG rules, 'Z' for topology view, 'A' for AI analysis"))
 
 	return content.String()
@@ -1391,12 +1398,12 @@ func RenderNetworkLoadingProgress(progress NetworkLoadingProgress) string {
 	var content strings.Builder
 
 	// Header
-	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("39")).Padding(0, 1)
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Current().Primary).Padding(0, 1)
 	content.WriteString(headerStyle.Render("🌐 Loading Network Dashboard"))
 	content.WriteString("\n\n")
 
 	// Current operation
-	operationStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("12"))
+	operationStyle := lipgloss.NewStyle().Foreground(theme.Current().Primary)
 	content.WriteString(operationStyle.Render(fmt.Sprintf("📋 %s", progress.CurrentOperation)))
 	content.WriteString("\n\n")
 
@@ -1406,7 +1413,7 @@ func RenderNetworkLoadingProgress(progress NetworkLoadingProgress) string {
 	emptyWidth := progressBarWidth - filledWidth
 
 	progressBar := strings.Repeat("█", filledWidth) + strings.Repeat("░", emptyWidth)
-	progressStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
+	progressStyle := lipgloss.NewStyle().Foreground(theme.Current().Success)
 
 	content.WriteString(fmt.Sprintf("Progress: [%s] %.1f%% (%d/%d)",
 		progressStyle.Render(progressBar),
@@ -1417,7 +1424,7 @@ func RenderNetworkLoadingProgress(progress NetworkLoadingProgress) string {
 
 	// Time information
 	elapsed := time.Since(progress.StartTime)
-	timeStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+	timeStyle := lipgloss.NewStyle().Foreground(theme.Current().Muted)
 	content.WriteString(timeStyle.Render(fmt.Sprintf("⏱️  Elapsed: %.1fs | %s", elapsed.Seconds(), progress.EstimatedTimeRemaining)))
 	content.WriteString("\n\n")
 
@@ -1430,27 +1437,28 @@ func RenderNetworkLoadingProgress(progress NetworkLoadingProgress) string {
 
 	for _, resType := range resourceTypes {
 		if resProgress, exists := progress.ResourceProgress[resType]; exists {
-			var statusIcon, statusColor string
+			var statusIcon string
+			var statusColor lipgloss.Color
 
 			switch resProgress.Status {
 			case "pending":
 				statusIcon = "⏳"
-				statusColor = "8" // Gray
+				statusColor = theme.Current().Muted
 			case "loading":
 				statusIcon = "🔄"
-				statusColor = "11" // Yellow
+				statusColor = theme.Current().Warning
 			case "completed":
 				statusIcon = "✅"
-				statusColor = "10" // Green
+				statusColor = theme.Current().Success
 			case "failed":
 				statusIcon = "❌"
-				statusColor = "9" // Red
+				statusColor = theme.Current().Error
 			default:
 				statusIcon = "❔"
-				statusColor = "8"
+				statusColor = theme.Current().Muted
 			}
 
-			statusStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(statusColor))
+			statusStyle := lipgloss.NewStyle().Foreground(statusColor)
 			resourceName := formatResourceTypeName(resType)
 
 			line := fmt.Sprintf("%s %s", statusIcon, resourceName)
@@ -1473,7 +1481,7 @@ func RenderNetworkLoadingProgress(progress NetworkLoadingProgress) string {
 	// Error summary if there are errors
 	if len(progress.Errors) > 0 {
 		content.WriteString("\n")
-		errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+		errorStyle := lipgloss.NewStyle().Foreground(theme.Current().Error)
 		content.WriteString(errorStyle.Render("⚠️  Errors encountered:"))
 		content.WriteString("\n")
 
@@ -1488,7 +1496,7 @@ func RenderNetworkLoadingProgress(progress NetworkLoadingProgress) string {
 
 	// Footer with helpful information
 	content.WriteString("\n")
-	helpStyle := lipgloss.NewStyle().Faint(true).Foreground(lipgloss.Color("8"))
+	helpStyle := lipgloss.NewStyle().Faint(true).Foreground(theme.Current().Muted)
 	content.WriteString(helpStyle.Render("💡 This may take a few moments depending on your Azure subscription size"))
 
 	return content.String()
@@ -1499,12 +1507,12 @@ func RenderNetworkTopologyLoadingProgress(progress NetworkLoadingProgress) strin
 	var content strings.Builder
 
 	// Header - customized for topology
-	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("39")).Padding(0, 1)
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Current().Primary).Padding(0, 1)
 	content.WriteString(headerStyle.Render("🗺️ Loading Network Topology"))
 	content.WriteString("\n\n")
 
 	// Current operation
-	operationStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("12"))
+	operationStyle := lipgloss.NewStyle().Foreground(theme.Current().Primary)
 	content.WriteString(operationStyle.Render(fmt.Sprintf("📋 %s", progress.CurrentOperation)))
 	content.WriteString("\n\n")
 
@@ -1514,7 +1522,7 @@ func RenderNetworkTopologyLoadingProgress(progress NetworkLoadingProgress) strin
 	emptyWidth := progressBarWidth - filledWidth
 
 	progressBar := strings.Repeat("█", filledWidth) + strings.Repeat("░", emptyWidth)
-	progressStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
+	progressStyle := lipgloss.NewStyle().Foreground(theme.Current().Success)
 
 	content.WriteString(fmt.Sprintf("Progress: [%s] %.1f%% (%d/%d)",
 		progressStyle.Render(progressBar),
@@ -1525,7 +1533,7 @@ func RenderNetworkTopologyLoadingProgress(progress NetworkLoadingProgress) strin
 
 	// Time information
 	elapsed := time.Since(progress.StartTime)
-	timeStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+	timeStyle := lipgloss.NewStyle().Foreground(theme.Current().Muted)
 	content.WriteString(timeStyle.Render(fmt.Sprintf("⏱️  Elapsed: %.1fs | %s", elapsed.Seconds(), progress.EstimatedTimeRemaining)))
 	content.WriteString("\n\n")
 
@@ -1538,27 +1546,28 @@ func RenderNetworkTopologyLoadingProgress(progress NetworkLoadingProgress) strin
 
 	for _, resType := range resourceTypes {
 		if resProgress, exists := progress.ResourceProgress[resType]; exists {
-			var statusIcon, statusColor string
+			var statusIcon string
+			var statusColor lipgloss.Color
 
 			switch resProgress.Status {
 			case "pending":
 				statusIcon = "⏳"
-				statusColor = "8" // Gray
+				statusColor = theme.Current().Muted
 			case "loading":
 				statusIcon = "🔄"
-				statusColor = "11" // Yellow
+				statusColor = theme.Current().Warning
 			case "completed":
 				statusIcon = "✅"
-				statusColor = "10" // Green
+				statusColor = theme.Current().Success
 			case "failed":
 				statusIcon = "❌"
-				statusColor = "9" // Red
+				statusColor = theme.Current().Error
 			default:
 				statusIcon = "❔"
-				statusColor = "8"
+				statusColor = theme.Current().Muted
 			}
 
-			statusStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(statusColor))
+			statusStyle := lipgloss.NewStyle().Foreground(statusColor)
 			resourceName := formatResourceTypeName(resType)
 
 			line := fmt.Sprintf("%s %s", statusIcon, resourceName)
@@ -1581,7 +1590,7 @@ func RenderNetworkTopologyLoadingProgress(progress NetworkLoadingProgress) strin
 	// Error summary if there are errors
 	if len(progress.Errors) > 0 {
 		content.WriteString("\n")
-		errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+		errorStyle := lipgloss.NewStyle().Foreground(theme.Current().Error)
 		content.WriteString(errorStyle.Render("⚠️  Errors encountered:"))
 		content.WriteString("\n")
 
@@ -1596,7 +1605,7 @@ func RenderNetworkTopologyLoadingProgress(progress NetworkLoadingProgress) strin
 
 	// Footer with helpful information
 	content.WriteString("\n")
-	helpStyle := lipgloss.NewStyle().Faint(true).Foreground(lipgloss.Color("8"))
+	helpStyle := lipgloss.NewStyle().Faint(true).Foreground(theme.Current().Muted)
 	content.WriteString(helpStyle.Render("💡 Analyzing network connections and topology relationships..."))
 
 	return content.String()
@@ -1740,7 +1749,7 @@ func RenderNSGDetails(nsgName, resourceGroup string) string {
 	var result strings.Builder
 
 	// Header
-	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("39"))
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Current().Primary)
 	result.WriteString(headerStyle.Render(fmt.Sprintf("🔒 Network Security Group: %s", nsgName)))
 	result.WriteString("\n\n")
 
@@ -1919,13 +1928,13 @@ func generateOpenPortsTable(openPorts []OpenPortInfo) string {
 	// Render each source group
 	for source, ports := range sourceGroups {
 		// Source header
-		sourceStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("33"))
+		sourceStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Current().Primary)
 		if source == "*" || source == "0.0.0.0/0" || source == "Internet" {
-			sourceStyle = sourceStyle.Foreground(lipgloss.Color("9")) // Red for public access
+			sourceStyle = sourceStyle.Foreground(theme.Current().Error) // Public access
 		}
 
 		for _, port := range ports {
-			portColor := lipgloss.Color("10") // Green by default
+			portColor := theme.Current().Success // Default
 
 			// Highlight potentially risky ports
 			riskyPorts := map[int]bool{
@@ -1933,9 +1942,9 @@ func generateOpenPortsTable(openPorts []OpenPortInfo) string {
 			}
 
 			if riskyPorts[port.Port] && (source == "*" || source == "0.0.0.0/0") {
-				portColor = lipgloss.Color("9") // Red for risky public ports
+				portColor = theme.Current().Error // Risky public ports
 			} else if port.Port < 1024 {
-				portColor = lipgloss.Color("11") // Yellow for privileged ports
+				portColor = theme.Current().Warning // Privileged ports
 			}
 
 			portStyle := lipgloss.NewStyle().Foreground(portColor)
@@ -1971,14 +1980,14 @@ func generateSecurityRulesTable(rules []SecurityRule) string {
 
 	for _, rule := range sortedRules {
 		// Color coding based on rule properties
-		accessColor := lipgloss.Color("10") // Green for Allow
+		accessColor := theme.Current().Success // Allow
 		if rule.Access == "Deny" {
-			accessColor = lipgloss.Color("9") // Red for Deny
+			accessColor = theme.Current().Error // Deny
 		}
 
-		directionColor := lipgloss.Color("12") // Blue for Outbound
+		directionColor := theme.Current().Primary // Outbound
 		if rule.Direction == "Inbound" {
-			directionColor = lipgloss.Color("13") // Magenta for Inbound
+			directionColor = theme.Current().Accent // Inbound
 		}
 
 		accessStyle := lipgloss.NewStyle().Foreground(accessColor)
@@ -2057,7 +2066,7 @@ func analyzeNSGSecurity(rules []SecurityRule) string {
 	analysis.WriteString("\n🔍 Security Recommendations:\n")
 
 	if len(riskyRules) > 0 {
-		warningStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+		warningStyle := lipgloss.NewStyle().Foreground(theme.Current().Error)
 		analysis.WriteString(warningStyle.Render("⚠️  HIGH RISK: "))
 		analysis.WriteString("The following rules allow public access to sensitive ports:\n")
 		for _, rule := range riskyRules {
@@ -2067,20 +2076,20 @@ func analyzeNSGSecurity(rules []SecurityRule) string {
 	}
 
 	if len(publicInboundPorts) > 5 {
-		cautionStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("11"))
+		cautionStyle := lipgloss.NewStyle().Foreground(theme.Current().Warning)
 		analysis.WriteString(cautionStyle.Render("⚠️  MEDIUM RISK: "))
 		analysis.WriteString(fmt.Sprintf("Many ports (%d) are open to the internet\n", len(publicInboundPorts)))
 		analysis.WriteString("   → Recommendation: Review necessity of each public port\n\n")
 	}
 
 	if inboundDeny == 0 {
-		infoStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("12"))
+		infoStyle := lipgloss.NewStyle().Foreground(theme.Current().Primary)
 		analysis.WriteString(infoStyle.Render("ℹ️  INFO: "))
 		analysis.WriteString("No explicit deny rules found (relying on default deny)\n")
 		analysis.WriteString("   → Recommendation: Consider explicit deny rules for clarity\n\n")
 	}
 
-	successStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
+	successStyle := lipgloss.NewStyle().Foreground(theme.Current().Success)
 	analysis.WriteString(successStyle.Render("✅ GOOD: "))
 	analysis.WriteString("NSG is configured and active\n")
 
@@ -3,12 +3,15 @@ package resourceactions
 import (
 	"context"
 	"fmt"
+	"io"
 	"os/exec"
 	"strings"
 	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/olafkfreund/azure-tui/internal/azcli"
 	"github.com/olafkfreund/azure-tui/internal/bicep"
+	"github.com/olafkfreund/azure-tui/internal/jobs"
 	"github.com/olafkfreund/azure-tui/internal/ssh"
 )
 
@@ -21,67 +24,79 @@ type ActionResult struct {
 
 // StartVM starts a virtual machine
 func StartVM(vmName, resourceGroup string) ActionResult {
-	cmd := exec.Command("az", "vm", "start", "--name", vmName, "--resource-group", resourceGroup)
-	output, err := cmd.CombinedOutput()
+	return StartVMContext(context.Background(), nil, vmName, resourceGroup)
+}
+
+// StartVMContext starts a virtual machine, streaming the command output to out
+func StartVMContext(ctx context.Context, out io.Writer, vmName, resourceGroup string) ActionResult {
+	output, err := jobs.Run(ctx, out, azcli.CommandContext(ctx, CommandArgs("start", "Microsoft.Compute/virtualMachines", vmName, resourceGroup)...))
 
 	if err != nil {
 		return ActionResult{
 			Success: false,
 			Message: fmt.Sprintf("Failed to start VM: %v", err),
-			Output:  string(output),
+			Output:  output,
 		}
 	}
 
 	return ActionResult{
 		Success: true,
 		Message: fmt.Sprintf("VM '%s' started successfully", vmName),
-		Output:  string(output),
+		Output:  output,
 	}
 }
 
 // StopVM stops a virtual machine
 func StopVM(vmName, resourceGroup string) ActionResult {
-	cmd := exec.Command("az", "vm", "deallocate", "--name", vmName, "--resource-group", resourceGroup)
-	output, err := cmd.CombinedOutput()
+	return StopVMContext(context.Background(), nil, vmName, resourceGroup)
+}
+
+// StopVMContext stops a virtual machine, streaming the command output to out
+func StopVMContext(ctx context.Context, out io.Writer, vmName, resourceGroup string) ActionResult {
+	output, err := jobs.Run(ctx, out, azcli.CommandContext(ctx, CommandArgs("stop", "Microsoft.Compute/virtualMachines", vmName, resourceGroup)...))
 
 	if err != nil {
 		return ActionResult{
 			Success: false,
 			Message: fmt.Sprintf("Failed to stop VM: %v", err),
-			Output:  string(output),
+			Output:  output,
 		}
 	}
 
 	return ActionResult{
 		Success: true,
 		Message: fmt.Sprintf("VM '%s' stopped successfully", vmName),
-		Output:  string(output),
+		Output:  output,
 	}
 }
 
 // RestartVM restarts a virtual machine
 func RestartVM(vmName, resourceGroup string) ActionResult {
-	cmd := exec.Command("az", "vm", "restart", "--name", vmName, "--resource-group", resourceGroup)
-	output, err := cmd.CombinedOutput()
+	return RestartVMContext(context.Background(), nil, vmName, resourceGroup)
+}
+
+// RestartVMContext restarts a virtual machine, streaming the command output to out
+func RestartVMContext(ctx context.Context, out io.Writer, vmName, resourceGroup string) ActionResult {
+	output, err := jobs.Run(ctx, out, azcli.CommandContext(ctx, CommandArgs("restart", "Microsoft.Compute/virtualMachines", vmName, resourceGroup)...))
 
 	if err != nil {
 		return ActionResult{
 			Success: false,
 			Message: fmt.Sprintf("Failed to restart VM: %v", err),
-			Output:  string(output),
+			Output:  output,
 		}
 	}
 
 	return ActionResult{
 		Success: true,
 		Message: fmt.Sprintf("VM '%s' restarted successfully", vmName),
-		Output:  string(output),
+		Output:  output,
 	}
 }
 
 // GetVMStatus gets the current status of a virtual machine
 func GetVMStatus(vmName, resourceGroup string) (string, error) {
-	cmd := exec.Command("az", "vm", "get-instance-view",
+	cmd := azcli.Command("vm", "get-instance-view",
 		"--name", vmName,
 		"--resource-group", resourceGroup,
 		"--query", "instanceView.statuses[1].displayStatus",
@@ -98,7 +113,7 @@ func GetVMStatus(vmName, resourceGroup string) (string, error) {
 // ConnectVMSSH attempts to connect to a VM via SSH
 func ConnectVMSSH(vmName, resourceGroup, username string) ActionResult {
 	// First get the VM's public IP
-	cmd := exec.Command("az", "vm", "list-ip-addresses",
+	cmd := azcli.Command("vm", "list-ip-addresses",
 		"--name", vmName,
 		"--resource-group", resourceGroup,
 		"--query", "[0].virtualMachine.network.publicIpAddresses[0].ipAddress",
@@ -135,7 +150,7 @@ func ConnectVMSSH(vmName, resourceGroup, username string) ActionResult {
 // ExecuteVMSSH executes SSH connection to a VM
 func ExecuteVMSSH(vmName, resourceGroup, username string) ActionResult {
 	// First get the VM's public IP
-	cmd := exec.Command("az", "vm", "list-ip-addresses",
+	cmd := azcli.Command("vm", "list-ip-addresses",
 		"--name", vmName,
 		"--resource-group", resourceGroup,
 		"--query", "[0].virtualMachine.network.publicIpAddresses[0].ipAddress",
@@ -161,7 +176,7 @@ func ExecuteVMSSH(vmName, resourceGroup, username string) ActionResult {
 
 	// Check if SSH key authentication is available
 	var keyAuthEnabled bool
-	sshKeyCmd := exec.Command("az", "vm", "show",
+	sshKeyCmd := azcli.Command("vm", "show",
 		"--name", vmName,
 		"--resource-group", resourceGroup,
 		"--query", "osProfile.linuxConfiguration.disablePasswordAuthentication",
@@ -200,7 +215,7 @@ func ExecuteVMSSH(vmName, resourceGroup, username string) ActionResult {
 // ConnectVMBastion connects to a VM via Azure Bastion
 func ConnectVMBastion(vmName, resourceGroup string) ActionResult {
 	// Check if VM has Bastion available
-	cmd := exec.Command("az", "network", "bastion", "list",
+	cmd := azcli.Command("network", "bastion", "list",
 		"--resource-group", resourceGroup,
 		"--output", "json")
 
@@ -225,7 +240,7 @@ func ConnectVMBastion(vmName, resourceGroup string) ActionResult {
 
 // StartWebApp starts an Azure Web App
 func StartWebApp(appName, resourceGroup string) ActionResult {
-	cmd := exec.Command("az", "webapp", "start", "--name", appName, "--resource-group", resourceGroup)
+	cmd := azcli.Command(CommandArgs("start", "Microsoft.Web/sites", appName, resourceGroup)...)
 	output, err := cmd.CombinedOutput()
 
 	if err != nil {
@@ -245,7 +260,7 @@ func StartWebApp(appName, resourceGroup string) ActionResult {
 
 // StopWebApp stops an Azure Web App
 func StopWebApp(appName, resourceGroup string) ActionResult {
-	cmd := exec.Command("az", "webapp", "stop", "--name", appName, "--resource-group", resourceGroup)
+	cmd := azcli.Command(CommandArgs("stop", "Microsoft.Web/sites", appName, resourceGroup)...)
 	output, err := cmd.CombinedOutput()
 
 	if err != nil {
@@ -265,7 +280,7 @@ func StopWebApp(appName, resourceGroup string) ActionResult {
 
 // RestartWebApp restarts an Azure Web App
 func RestartWebApp(appName, resourceGroup string) ActionResult {
-	cmd := exec.Command("az", "webapp", "restart", "--name", appName, "--resource-group", resourceGroup)
+	cmd := azcli.Command(CommandArgs("restart", "Microsoft.Web/sites", appName, resourceGroup)...)
 	output, err := cmd.CombinedOutput()
 
 	if err != nil {
@@ -285,47 +300,55 @@ func RestartWebApp(appName, resourceGroup string) ActionResult {
 
 // StartAKSCluster starts an AKS cluster
 func StartAKSCluster(clusterName, resourceGroup string) ActionResult {
-	cmd := exec.Command("az", "aks", "start", "--name", clusterName, "--resource-group", resourceGroup)
-	output, err := cmd.CombinedOutput()
+	return StartAKSClusterContext(context.Background(), nil, clusterName, resourceGroup)
+}
+
+// StartAKSClusterContext starts an AKS cluster, streaming the command output to out
+func StartAKSClusterContext(ctx context.Context, out io.Writer, clusterName, resourceGroup string) ActionResult {
+	output, err := jobs.Run(ctx, out, azcli.CommandContext(ctx, CommandArgs("start", "Microsoft.ContainerService/managedClusters", clusterName, resourceGroup)...))
 
 	if err != nil {
 		return ActionResult{
 			Success: false,
 			Message: fmt.Sprintf("Failed to start AKS cluster: %v", err),
-			Output:  string(output),
+			Output:  output,
 		}
 	}
 
 	return ActionResult{
 		Success: true,
 		Message: fmt.Sprintf("AKS cluster '%s' started successfully", clusterName),
-		Output:  string(output),
+		Output:  output,
 	}
 }
 
 // StopAKSCluster stops an AKS cluster
 func StopAKSCluster(clusterName, resourceGroup string) ActionResult {
-	cmd := exec.Command("az", "aks", "stop", "--name", clusterName, "--resource-group", resourceGroup)
-	output, err := cmd.CombinedOutput()
+	return StopAKSClusterContext(context.Background(), nil, clusterName, resourceGroup)
+}
+
+// StopAKSClusterContext stops an AKS cluster, streaming the command output to out
+func StopAKSClusterContext(ctx context.Context, out io.Writer, clusterName, resourceGroup string) ActionResult {
+	output, err := jobs.Run(ctx, out, azcli.CommandContext(ctx, CommandArgs("stop", "Microsoft.ContainerService/managedClusters", clusterName, resourceGroup)...))
 
 	if err != nil {
 		return ActionResult{
 			Success: false,
 			Message: fmt.Sprintf("Failed to stop AKS cluster: %v", err),
-			Output:  string(output),
+			Output:  output,
 		}
 	}
 
 	return ActionResult{
 		Success: true,
 		Message: fmt.Sprintf("AKS cluster '%s' stopped successfully", clusterName),
-		Output:  string(output),
+		Output:  output,
 	}
 }
 
 // ScaleAKSCluster scales an AKS cluster node pool
 func ScaleAKSCluster(clusterName, resourceGroup string, nodeCount int) ActionResult {
-	cmd := exec.Command("az", "aks", "scale",
+	cmd := azcli.Command("aks", "scale",
 		"--name", clusterName,
 		"--resource-group", resourceGroup,
 		"--node-count", fmt.Sprintf("%d", nodeCount))
@@ -351,10 +374,7 @@ func ScaleAKSCluster(clusterName, resourceGroup string, nodeCount int) ActionRes
 
 // ConnectAKSCluster gets credentials and connects to AKS cluster
 func ConnectAKSCluster(clusterName, resourceGroup string) ActionResult {
-	cmd := exec.Command("az", "aks", "get-credentials",
-		"--name", clusterName,
-		"--resource-group", resourceGroup,
-		"--overwrite-existing")
+	cmd := azcli.Command(CommandArgs("connect", "Microsoft.ContainerService/managedClusters", clusterName, resourceGroup)...)
 
 	output, err := cmd.CombinedOutput()
 	if err != nil {
@@ -543,6 +563,47 @@ func GetResourceActions(resourceType string) []string {
 	return actions
 }
 
+// CommandArgs returns the arguments of the az command that runs action on a
+// resource of resourceType, or nil when the action isn't a single az command
+func CommandArgs(action, resourceType, resourceName, resourceGroup string) []string {
+	var command []string
+	switch {
+	case strings.Contains(resourceType, "Microsoft.Compute/virtualMachines"):
+		command = map[string][]string{
+			"start":   {"vm", "start"},
+			"stop":    {"vm", "deallocate"},
+			"restart": {"vm", "restart"},
+		}[action]
+	case strings.Contains(resourceType, "Microsoft.Web/sites"):
+		command = map[string][]string{
+			"start":   {"webapp", "start"},
+			"stop":    {"webapp", "stop"},
+			"restart": {"webapp", "restart"},
+		}[action]
+	case strings.Contains(resourceType, "Microsoft.ContainerService/managedClusters"):
+		command = map[string][]string{
+			"start":   {"aks", "start"},
+			"stop":    {"aks", "stop"},
+			"connect": {"aks", "get-credentials"},
+		}[action]
+	case strings.Contains(resourceType, "Microsoft.ContainerInstance/containerGroups"):
+		command = map[string][]string{
+			"start":   {"container", "start"},
+			"stop":    {"container", "stop"},
+			"restart": {"container", "restart"},
+		}[action]
+	}
+	if command == nil {
+		return nil
+	}
+
+	args := append(command, "--name", resourceName, "--resource-group", resourceGroup)
+	if action == "connect" {
+		args = append(args, "--overwrite-existing")
+	}
+	return args
+}
+
 // ExecuteResourceAction executes a specific action on a resource
 func ExecuteResourceAction(action, resourceType, resourceName, resourceGroup string, params map[string]interface{}) ActionResult {
 	switch action {
@@ -723,7 +784,7 @@ func CreateVirtualNetworkAction(name, resourceGroup, location string, addressPre
 		args = append(args, "--address-prefix", "10.0.0.0/16")
 	}
 
-	cmd := exec.Command("az", args...)
+	cmd := azcli.Command(args...)
 	output, err := cmd.CombinedOutput()
 
 	if err != nil {
@@ -743,7 +804,7 @@ func CreateVirtualNetworkAction(name, resourceGroup, location string, addressPre
 
 // DeleteVirtualNetworkAction deletes a virtual network
 func DeleteVirtualNetworkAction(name, resourceGroup string) ActionResult {
-	cmd := exec.Command("az", "network", "vnet", "delete", "--name", name, "--resource-group", resourceGroup, "--yes")
+	cmd := azcli.Command("network", "vnet", "delete", "--name", name, "--resource-group", resourceGroup, "--yes")
 	output, err := cmd.CombinedOutput()
 
 	if err != nil {
@@ -763,7 +824,7 @@ func DeleteVirtualNetworkAction(name, resourceGroup string) ActionResult {
 
 // CreateSubnetAction creates a new subnet in a virtual network
 func CreateSubnetAction(name, vnetName, resourceGroup, addressPrefix string) ActionResult {
-	cmd := exec.Command("az", "network", "vnet", "subnet", "create",
+	cmd := azcli.Command("network", "vnet", "subnet", "create",
 		"--name", name,
 		"--vnet-name", vnetName,
 		"--resource-group", resourceGroup,
@@ -787,7 +848,7 @@ func CreateSubnetAction(name, vnetName, resourceGroup, addressPrefix string) Act
 
 // CreateNetworkSecurityGroupAction creates a new network security group
 func CreateNetworkSecurityGroupAction(name, resourceGroup, location string) ActionResult {
-	cmd := exec.Command("az", "network", "nsg", "create", "--name", name, "--resource-group", resourceGroup, "--location", location)
+	cmd := azcli.Command("network", "nsg", "create", "--name", name, "--resource-group", resourceGroup, "--location", location)
 	output, err := cmd.CombinedOutput()
 
 	if err != nil {
@@ -807,7 +868,7 @@ func CreateNetworkSecurityGroupAction(name, resourceGroup, location string) Acti
 
 // AddSecurityRuleAction adds a new security rule to an NSG
 func AddSecurityRuleAction(nsgName, resourceGroup, ruleName string, priority int, direction, access, protocol, sourcePort, destPort, sourceAddress, destAddress string) ActionResult {
-	cmd := exec.Command("az", "network", "nsg", "rule", "create",
+	cmd := azcli.Command("network", "nsg", "rule", "create",
 		"--nsg-name", nsgName,
 		"--resource-group", resourceGroup,
 		"--name", ruleName,
@@ -839,7 +900,7 @@ func AddSecurityRuleAction(nsgName, resourceGroup, ruleName string, priority int
 // AssociateNSGWithSubnetAction associates an NSG with a subnet
 func AssociateNSGWithSubnetAction(subnetName, vnetName, resourceGroup, nsgName string) ActionResult {
 	nsgID := fmt.Sprintf("/subscriptions/$(az account show --query id -o tsv)/resourceGroups/%s/providers/Microsoft.Network/networkSecurityGroups/%s", resourceGroup, nsgName)
-	cmd := exec.Command("az", "network", "vnet", "subnet", "update",
+	cmd := azcli.Command("network", "vnet", "subnet", "update",
 		"--name", subnetName,
 		"--vnet-name", vnetName,
 		"--resource-group", resourceGroup,
@@ -863,7 +924,7 @@ func AssociateNSGWithSubnetAction(subnetName, vnetName, resourceGroup, nsgName s
 
 // CreateRouteTableAction creates a new route table
 func CreateRouteTableAction(name, resourceGroup, location string) ActionResult {
-	cmd := exec.Command("az", "network", "route-table", "create", "--name", name, "--resource-group", resourceGroup, "--location", location)
+	cmd := azcli.Command("network", "route-table", "create", "--name", name, "--resource-group", resourceGroup, "--location", location)
 	output, err := cmd.CombinedOutput()
 
 	if err != nil {
@@ -894,7 +955,7 @@ func AddRouteAction(routeTableName, resourceGroup, routeName, addressPrefix, nex
 		args = append(args, "--next-hop-ip-address", nextHopAddress)
 	}
 
-	cmd := exec.Command("az", args...)
+	cmd := azcli.Command(args...)
 	output, err := cmd.CombinedOutput()
 
 	if err != nil {
@@ -914,7 +975,7 @@ func AddRouteAction(routeTableName, resourceGroup, routeName, addressPrefix, nex
 
 // CreatePublicIPAction creates a new public IP address
 func CreatePublicIPAction(name, resourceGroup, location, allocationMethod, sku string) ActionResult {
-	cmd := exec.Command("az", "network", "public-ip", "create",
+	cmd := azcli.Command("network", "public-ip", "create",
 		"--name", name,
 		"--resource-group", resourceGroup,
 		"--location", location,
@@ -949,7 +1010,7 @@ func CreateLoadBalancerAction(name, resourceGroup, location, sku, publicIPName s
 		args = append(args, "--public-ip-address", publicIPName)
 	}
 
-	cmd := exec.Command("az", args...)
+	cmd := azcli.Command(args...)
 	output, err := cmd.CombinedOutput()
 
 	if err != nil {
@@ -983,7 +1044,7 @@ func CreateNetworkInterfaceAction(name, resourceGroup, location, subnetID, publi
 		args = append(args, "--network-security-group", nsgName)
 	}
 
-	cmd := exec.Command("az", args...)
+	cmd := azcli.Command(args...)
 	output, err := cmd.CombinedOutput()
 
 	if err != nil {
@@ -1003,7 +1064,7 @@ func CreateNetworkInterfaceAction(name, resourceGroup, location, subnetID, publi
 
 // EnableNetworkWatcherAction enables Network Watcher for monitoring
 func EnableNetworkWatcherAction(resourceGroup, location string) ActionResult {
-	cmd := exec.Command("az", "network", "watcher", "configure", "--resource-group", resourceGroup, "--locations", location, "--enabled", "true")
+	cmd := azcli.Command("network", "watcher", "configure", "--resource-group", resourceGroup, "--locations", location, "--enabled", "true")
 	output, err := cmd.CombinedOutput()
 
 	if err != nil {
@@ -1023,7 +1084,7 @@ func EnableNetworkWatcherAction(resourceGroup, location string) ActionResult {
 
 // TestNetworkConnectivityAction tests connectivity between network resources
 func TestNetworkConnectivityAction(sourceResourceID, destResourceID string) ActionResult {
-	cmd := exec.Command("az", "network", "watcher", "test-connectivity",
+	cmd := azcli.Command("network", "watcher", "test-connectivity",
 		"--source-resource", sourceResourceID,
 		"--dest-resource", destResourceID)
 	output, err := cmd.CombinedOutput()
@@ -1047,7 +1108,7 @@ func TestNetworkConnectivityAction(sourceResourceID, destResourceID string) Acti
 func CreateVNetPeeringAction(localVNet, localResourceGroup, remoteVNet, remoteResourceGroup string) ActionResult {
 	remoteVNetID := fmt.Sprintf("/subscriptions/$(az account show --query id -o tsv)/resourceGroups/%s/providers/Microsoft.Network/virtualNetworks/%s", remoteResourceGroup, remoteVNet)
 
-	cmd := exec.Command("az", "network", "vnet", "peering", "create",
+	cmd := azcli.Command("network", "vnet", "peering", "create",
 		"--name", fmt.Sprintf("%s-to-%s", localVNet, remoteVNet),
 		"--vnet-name", localVNet,
 		"--resource-group", localResourceGroup,
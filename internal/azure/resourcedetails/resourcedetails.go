@@ -10,6 +10,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/olafkfreund/azure-tui/internal/azcli"
 	"github.com/olafkfreund/azure-tui/internal/azure/usage"
 )
 
@@ -45,6 +46,7 @@ type ResourceDetails struct {
 	Properties    map[string]interface{} `json:"properties"`
 	SKU           map[string]interface{} `json:"sku"`
 	ResourceGroup string                 `json:"resourceGroup"`
+	Raw           json.RawMessage        `json:"-"` // Full az resource show payload
 }
 
 // ResourceMetrics represents real-time metrics for a resource
@@ -176,7 +178,7 @@ type KubernetesService struct {
 func GetResourceDetails(resourceID string) (*ResourceDetails, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
-	cmd := exec.CommandContext(ctx, "az", "resource", "show", "--ids", resourceID, "--output", "json")
+	cmd := azcli.CommandContext(ctx, "resource", "show", "--ids", resourceID, "--output", "json")
 	out, err := cmd.Output()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get resource details: %w", err)
@@ -188,6 +190,7 @@ func GetResourceDetails(resourceID string) (*ResourceDetails, error) {
 	}
 
 	details := &ResourceDetails{
+		Raw:      out,
 		ID:       getStringValue(rawResource, "id"),
 		Name:     getStringValue(rawResource, "name"),
 		Type:     getStringValue(rawResource, "type"),
@@ -301,7 +304,7 @@ func getResourceLogs(resourceID string) ([]LogEntry, error) {
 	}
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
-	cmd := exec.CommandContext(ctx, "az", "monitor", "log-analytics", "query",
+	cmd := azcli.CommandContext(ctx, "monitor", "log-analytics", "query",
 		"--workspace", workspace,
 		"--analytics-query", fmt.Sprintf(`
 			AzureActivity
@@ -465,7 +468,7 @@ func getLogAnalyticsWorkspaceForResource(resourceID string) string {
 	}
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
-	cmd := exec.CommandContext(ctx, "az", "monitor", "log-analytics", "workspace", "list", "--resource-group", resourceGroup, "--query", "[0].customerId", "--output", "tsv")
+	cmd := azcli.CommandContext(ctx, "monitor", "log-analytics", "workspace", "list", "--resource-group", resourceGroup, "--query", "[0].customerId", "--output", "tsv")
 	out, err := cmd.Output()
 	if err != nil || strings.TrimSpace(string(out)) == "" {
 		// No workspace found, log and skip
@@ -618,7 +621,7 @@ func getStringValue(data map[string]interface{}, key string) string {
 func getMetricValue(resourceID, metricName string) (float64, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
-	cmd := exec.CommandContext(ctx, "az", "monitor", "metrics", "list",
+	cmd := azcli.CommandContext(ctx, "monitor", "metrics", "list",
 		"--resource", resourceID,
 		"--metric", metricName,
 		"--aggregation", "Average",
@@ -664,7 +667,7 @@ func getMetricTrends(resourceID string) (map[string][]float64, error) {
 	for _, metric := range metrics {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
-		cmd := exec.CommandContext(ctx, "az", "monitor", "metrics", "list",
+		cmd := azcli.CommandContext(ctx, "monitor", "metrics", "list",
 			"--resource", resourceID,
 			"--metric", metric,
 			"--aggregation", "Average",
@@ -690,7 +693,7 @@ func getMetricTrends(resourceID string) (map[string][]float64, error) {
 func getAKSCredentials(clusterName, resourceGroup string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
-	cmd := exec.CommandContext(ctx, "az", "aks", "get-credentials",
+	cmd := azcli.CommandContext(ctx, "aks", "get-credentials",
 		"--name", clusterName,
 		"--resource-group", resourceGroup,
 		"--overwrite-existing")
@@ -700,7 +703,7 @@ func getAKSCredentials(clusterName, resourceGroup string) error {
 func getAKSNodePools(clusterName, resourceGroup string) ([]AKSNodePool, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
-	cmd := exec.CommandContext(ctx, "az", "aks", "nodepool", "list",
+	cmd := azcli.CommandContext(ctx, "aks", "nodepool", "list",
 		"--cluster-name", clusterName,
 		"--resource-group", resourceGroup,
 		"--output", "json")
@@ -2,7 +2,8 @@ package sql
 
 import (
 	"encoding/json"
-	"os/exec"
+
+	"github.com/olafkfreund/azure-tui/internal/azcli"
 )
 
 type SQLServer struct {
@@ -17,7 +18,7 @@ type SQLDatabase struct {
 }
 
 func ListSQLServers() ([]SQLServer, error) {
-	cmd := exec.Command("az", "sql", "server", "list", "--output", "json")
+	cmd := azcli.Command("sql", "server", "list", "--output", "json")
 	out, err := cmd.Output()
 	if err != nil {
 		return nil, err
@@ -30,7 +31,7 @@ func ListSQLServers() ([]SQLServer, error) {
 }
 
 func ListSQLDatabases(server, group string) ([]SQLDatabase, error) {
-	cmd := exec.Command("az", "sql", "db", "list", "--server", server, "--resource-group", group, "--output", "json")
+	cmd := azcli.Command("sql", "db", "list", "--server", server, "--resource-group", group, "--output", "json")
 	out, err := cmd.Output()
 	if err != nil {
 		return nil, err
@@ -43,9 +44,9 @@ func ListSQLDatabases(server, group string) ([]SQLDatabase, error) {
 }
 
 func CreateSQLServer(name, group, location, adminUser, adminPass string) error {
-	return exec.Command("az", "sql", "server", "create", "--name", name, "--resource-group", group, "--location", location, "--admin-user", adminUser, "--admin-password", adminPass).Run()
+	return azcli.Command("sql", "server", "create", "--name", name, "--resource-group", group, "--location", location, "--admin-user", adminUser, "--admin-password", adminPass).Run()
 }
 
 func DeleteSQLServer(name, group string) error {
-	return exec.Command("az", "sql", "server", "delete", "--name", name, "--resource-group", group, "--yes").Run()
+	return azcli.Command("sql", "server", "delete", "--name", name, "--resource-group", group, "--yes").Run()
 }
@@ -4,11 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os/exec"
+	"io"
 	"strings"
 	"time"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/olafkfreund/azure-tui/internal/azcli"
+	"github.com/olafkfreund/azure-tui/internal/jobs"
+	"github.com/olafkfreund/azure-tui/internal/theme"
 )
 
 // Container represents a blob container in a storage account
@@ -80,12 +83,12 @@ func RenderStorageLoadingProgress(progress StorageLoadingProgress) string {
 	var content strings.Builder
 
 	// Header
-	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("39")).Padding(0, 1)
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Current().Primary).Padding(0, 1)
 	content.WriteString(headerStyle.Render("💾 Loading Storage Data"))
 	content.WriteString("\n\n")
 
 	// Current operation
-	operationStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("12"))
+	operationStyle := lipgloss.NewStyle().Foreground(theme.Current().Primary)
 	content.WriteString(operationStyle.Render(fmt.Sprintf("📋 %s", progress.CurrentOperation)))
 	content.WriteString("\n\n")
 
@@ -95,7 +98,7 @@ func RenderStorageLoadingProgress(progress StorageLoadingProgress) string {
 	emptyWidth := progressBarWidth - filledWidth
 
 	progressBar := strings.Repeat("█", filledWidth) + strings.Repeat("░", emptyWidth)
-	progressStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
+	progressStyle := lipgloss.NewStyle().Foreground(theme.Current().Success)
 	content.WriteString(fmt.Sprintf("Progress: [%s] %.1f%% (%d/%d)",
 		progressStyle.Render(progressBar),
 		progress.ProgressPercentage,
@@ -105,7 +108,7 @@ func RenderStorageLoadingProgress(progress StorageLoadingProgress) string {
 
 	// Time information
 	elapsed := time.Since(progress.StartTime)
-	timeStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+	timeStyle := lipgloss.NewStyle().Foreground(theme.Current().Muted)
 	content.WriteString(timeStyle.Render(fmt.Sprintf("⏱️  Elapsed: %.1fs | %s", elapsed.Seconds(), progress.EstimatedTimeRemaining)))
 	content.WriteString("\n\n")
 
@@ -118,27 +121,28 @@ func RenderStorageLoadingProgress(progress StorageLoadingProgress) string {
 
 	for _, opType := range operationTypes {
 		if opProgress, exists := progress.StorageProgress[opType]; exists {
-			var statusIcon, statusColor string
+			var statusIcon string
+			var statusColor lipgloss.Color
 
 			switch opProgress.Status {
 			case "pending":
 				statusIcon = "⏳"
-				statusColor = "8" // Gray
+				statusColor = theme.Current().Muted
 			case "loading":
 				statusIcon = "🔄"
-				statusColor = "11" // Yellow
+				statusColor = theme.Current().Warning
 			case "completed":
 				statusIcon = "✅"
-				statusColor = "10" // Green
+				statusColor = theme.Current().Success
 			case "failed":
 				statusIcon = "❌"
-				statusColor = "9" // Red
+				statusColor = theme.Current().Error
 			default:
 				statusIcon = "❔"
-				statusColor = "8"
+				statusColor = theme.Current().Muted
 			}
 
-			statusStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(statusColor))
+			statusStyle := lipgloss.NewStyle().Foreground(statusColor)
 			operationName := formatStorageOperationName(opType)
 
 			line := fmt.Sprintf("%s %s", statusIcon, operationName)
@@ -161,7 +165,7 @@ func RenderStorageLoadingProgress(progress StorageLoadingProgress) string {
 	// Error summary if there are errors
 	if len(progress.Errors) > 0 {
 		content.WriteString("\n")
-		errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+		errorStyle := lipgloss.NewStyle().Foreground(theme.Current().Error)
 		content.WriteString(errorStyle.Render("⚠️  Errors encountered:"))
 		content.WriteString("\n")
 
@@ -176,7 +180,7 @@ func RenderStorageLoadingProgress(progress StorageLoadingProgress) string {
 
 	// Footer with helpful information
 	content.WriteString("\n")
-	helpStyle := lipgloss.NewStyle().Faint(true).Foreground(lipgloss.Color("8"))
+	helpStyle := lipgloss.NewStyle().Faint(true).Foreground(theme.Current().Muted)
 	content.WriteString(helpStyle.Render("💡 Storage operations may take a few moments depending on account size"))
 
 	return content.String()
@@ -213,7 +217,7 @@ func ListContainers(accountName string) ([]Container, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, "az", "storage", "container", "list",
+	cmd := azcli.CommandContext(ctx, "storage", "container", "list",
 		"--account-name", accountName,
 		"--output", "json")
 
@@ -300,7 +304,7 @@ func ListBlobs(accountName, containerName string) ([]Blob, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, "az", "storage", "blob", "list",
+	cmd := azcli.CommandContext(ctx, "storage", "blob", "list",
 		"--account-name", accountName,
 		"--container-name", containerName,
 		"--output", "json")
@@ -393,7 +397,7 @@ func CreateContainer(accountName, containerName string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, "az", "storage", "container", "create",
+	cmd := azcli.CommandContext(ctx, "storage", "container", "create",
 		"--account-name", accountName,
 		"--name", containerName)
 
@@ -409,7 +413,7 @@ func DeleteContainer(accountName, containerName string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, "az", "storage", "container", "delete",
+	cmd := azcli.CommandContext(ctx, "storage", "container", "delete",
 		"--account-name", accountName,
 		"--name", containerName)
 
@@ -422,17 +426,22 @@ func DeleteContainer(accountName, containerName string) error {
 
 // UploadBlob uploads a file to a blob container
 func UploadBlob(accountName, containerName, blobName, filePath string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	return UploadBlobContext(context.Background(), nil, accountName, containerName, blobName, filePath)
+}
+
+// UploadBlobContext uploads a file to a container, streaming the command
+// output to out
+func UploadBlobContext(ctx context.Context, out io.Writer, accountName, containerName, blobName, filePath string) error {
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, "az", "storage", "blob", "upload",
+	_, err := jobs.Run(ctx, out, azcli.CommandContext(ctx, "storage", "blob", "upload",
 		"--account-name", accountName,
 		"--container-name", containerName,
 		"--name", blobName,
 		"--file", filePath,
-		"--overwrite")
-
-	if err := cmd.Run(); err != nil {
+		"--overwrite"))
+	if err != nil {
 		return fmt.Errorf("failed to upload blob %s: %v", blobName, err)
 	}
 
@@ -444,7 +453,7 @@ func DeleteBlob(accountName, containerName, blobName string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, "az", "storage", "blob", "delete",
+	cmd := azcli.CommandContext(ctx, "storage", "blob", "delete",
 		"--account-name", accountName,
 		"--container-name", containerName,
 		"--name", blobName)
@@ -461,7 +470,7 @@ func GetBlobProperties(accountName, containerName, blobName string) (*Blob, erro
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, "az", "storage", "blob", "show",
+	cmd := azcli.CommandContext(ctx, "storage", "blob", "show",
 		"--account-name", accountName,
 		"--container-name", containerName,
 		"--name", blobName,
@@ -496,12 +505,12 @@ func RenderStorageContainersView(accountName string, containers []Container) str
 		content.WriteString("   • Access permissions may be limited\n")
 		content.WriteString("   • Container names may not match filters\n\n")
 		content.WriteString("🔧 What you can do:\n")
-		content.WriteString("   • Press 'Shift+T' to create a new container\n")
+		content.WriteString("   • Press 't' to create a new container\n")
 		content.WriteString("   • Check Azure portal for container visibility\n")
 		content.WriteString("   • Verify storage account permissions\n")
 		content.WriteString("   • Refresh the view with 'R'\n\n")
 		content.WriteString("Available Actions:\n")
-		content.WriteString("• Press 'Shift+T' to create a new container\n")
+		content.WriteString("• Press 't' to create a new container\n")
 		content.WriteString("• Press 'R' to refresh the container list\n")
 		content.WriteString("• Press 'Esc' to go back\n")
 		return content.String()
@@ -584,14 +593,14 @@ func RenderStorageBlobsView(accountName, containerName string, blobs []Blob) str
 		}
 
 		// Blob type icon
-		typeIcon := "📄"
+		typeIcon := theme.Icon("📄", "[BLOB]")
 		switch blob.BlobType {
 		case "BlockBlob":
-			typeIcon = "🧱"
+			typeIcon = theme.Icon("🧱", "[BLOCK]")
 		case "PageBlob":
-			typeIcon = "📄"
+			typeIcon = theme.Icon("📄", "[PAGE]")
 		case "AppendBlob":
-			typeIcon = "📝"
+			typeIcon = theme.Icon("📝", "[APPEND]")
 		}
 
 		content.WriteString(fmt.Sprintf("%s %s (%s)\n", typeIcon, blob.Name, sizeStr))
@@ -690,7 +699,7 @@ func ListStorageAccounts() ([]StorageAccount, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, "az", "storage", "account", "list", "--output", "json")
+	cmd := azcli.CommandContext(ctx, "storage", "account", "list", "--output", "json")
 	output, err := cmd.Output()
 	if err != nil {
 		return nil, fmt.Errorf("failed to list storage accounts: %v", err)
@@ -709,7 +718,7 @@ func CreateStorageAccount(name, group, location string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, "az", "storage", "account", "create",
+	cmd := azcli.CommandContext(ctx, "storage", "account", "create",
 		"--name", name,
 		"--resource-group", group,
 		"--location", location,
@@ -727,7 +736,7 @@ func DeleteStorageAccount(name, group string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, "az", "storage", "account", "delete",
+	cmd := azcli.CommandContext(ctx, "storage", "account", "delete",
 		"--name", name,
 		"--resource-group", group,
 		"--yes")
@@ -738,3 +747,22 @@ func DeleteStorageAccount(name, group string) error {
 
 	return nil
 }
+
+// GetConnectionString returns the connection string of a storage account
+func GetConnectionString(name, group string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	cmd := azcli.CommandContext(ctx, "storage", "account", "show-connection-string",
+		"--name", name,
+		"--resource-group", group,
+		"--query", "connectionString",
+		"--output", "tsv")
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get connection string for storage account %s: %v", name, err)
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
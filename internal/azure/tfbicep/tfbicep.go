@@ -10,6 +10,8 @@ import (
 	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/olafkfreund/azure-tui/internal/azcli"
 )
 
 // Enhanced Terraform Operations with better error handling and output capture
@@ -201,7 +203,7 @@ func BicepBuild(file string) error {
 }
 
 func BicepDeploy(file, group string) error {
-	return exec.Command("az", "deployment", "group", "create", "--resource-group", group, "--template-file", file).Run()
+	return azcli.Command("deployment", "group", "create", "--resource-group", group, "--template-file", file).Run()
 }
 
 // =============================================================================
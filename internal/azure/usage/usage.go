@@ -1,15 +1,29 @@
 package usage
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"os/exec"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/olafkfreund/azure-tui/internal/azcli"
+	"github.com/olafkfreund/azure-tui/internal/azure/metrics"
 )
 
+// UsageMetric is the latest value of a metric of a resource. Value is
+// formatted for display.
 type UsageMetric struct {
-	Name  string `json:"name"`
-	Value string `json:"currentValue"`
-	Limit string `json:"limit"`
+	Metric string  `json:"metric"`
+	Name   string  `json:"name"`
+	Value  string  `json:"currentValue"`
+	Unit   string  `json:"unit"`
+	Latest float64 `json:"-"`
+	Found  bool    `json:"-"` // False when Azure has no recent data point
 }
 
 type Alarm struct {
@@ -18,76 +32,369 @@ type Alarm struct {
 	Details string `json:"details"`
 }
 
-func ListUsageMetrics(resourceID string) ([]UsageMetric, error) {
-	cmd := exec.Command("az", "monitor", "metrics", "list", "--resource", resourceID, "--output", "json")
-	out, err := cmd.Output()
+// usageGrain is the interval the latest values are read at, when the
+// metric keeps it
+const usageGrain = "PT5M"
+
+// usageWindows are how far back the latest value is looked for at each
+// time grain, a few intervals so that one late data point is not missed
+var usageWindows = map[string]time.Duration{
+	"PT1M":  time.Hour,
+	"PT5M":  time.Hour,
+	"PT15M": time.Hour,
+	"PT30M": 2 * time.Hour,
+	"PT1H":  3 * time.Hour,
+	"PT6H":  24 * time.Hour,
+	"PT12H": 36 * time.Hour,
+	"P1D":   3 * 24 * time.Hour,
+}
+
+// maxMetricsPerQuery is how many metrics Azure Monitor returns per request
+const maxMetricsPerQuery = 20
+
+// usageQuery is a batch of metrics read together: Azure Monitor takes one
+// aggregation and interval per request
+type usageQuery struct {
+	Aggregation string
+	Window      metrics.TimeRange
+	Names       []string
+}
+
+// usageTimeGrain returns the interval a metric is read at: usageGrain, or
+// the finest one the metric keeps when it does not keep that, as storage
+// capacity metrics that are only kept hourly
+func usageTimeGrain(definition metrics.Definition) string {
+	if len(definition.TimeGrains) == 0 || slices.Contains(definition.TimeGrains, usageGrain) {
+		return usageGrain
+	}
+	grain, window := "", time.Duration(0)
+	for _, candidate := range definition.TimeGrains {
+		if duration, ok := usageWindows[candidate]; ok && (grain == "" || duration < window) {
+			grain, window = candidate, duration
+		}
+	}
+	if grain == "" {
+		return usageGrain
+	}
+	return grain
+}
+
+// usageQueries groups the metrics into the requests that read their latest
+// values, in the order of the definitions
+func usageQueries(definitions []metrics.Definition) []usageQuery {
+	var queries []usageQuery
+	open := map[[2]string]int{} // Aggregation and grain to the batch being filled
+	for _, definition := range definitions {
+		grain := usageTimeGrain(definition)
+		key := [2]string{definition.PrimaryAggregation, grain}
+		i, ok := open[key]
+		if !ok || len(queries[i].Names) == maxMetricsPerQuery {
+			i = len(queries)
+			open[key] = i
+			queries = append(queries, usageQuery{
+				Aggregation: definition.PrimaryAggregation,
+				Window:      metrics.TimeRange{Label: grain, Duration: usageWindows[grain], Interval: grain},
+			})
+		}
+		queries[i].Names = append(queries[i].Names, definition.Name)
+	}
+	return queries
+}
+
+// latestUsage returns the latest value of each metric in the series read
+// for them, "no data" for metrics without one and "unavailable" for the
+// metrics in failed
+func latestUsage(definitions []metrics.Definition, series []metrics.Series, failed map[string]bool) []UsageMetric {
+	latest := map[string]metrics.Series{}
+	for _, s := range series {
+		// Metrics split by dimension keep their first series
+		if _, ok := latest[s.Metric]; !ok {
+			latest[s.Metric] = s
+		}
+	}
+
+	usageMetrics := make([]UsageMetric, 0, len(definitions))
+	for _, definition := range definitions {
+		metric := UsageMetric{Metric: definition.Name, Name: definition.DisplayName, Value: "no data", Unit: definition.Unit}
+		if value, ok := latest[definition.Name].Last(); ok {
+			metric.Latest, metric.Found = value, true
+			metric.Value = metrics.FormatValue(value, definition.Unit)
+		} else if failed[definition.Name] {
+			metric.Value = "unavailable"
+		}
+		usageMetrics = append(usageMetrics, metric)
+	}
+	return usageMetrics
+}
+
+// ListUsageMetrics returns the latest value of every metric of a resource,
+// each read with its primary aggregation. Metrics whose request fails are
+// "unavailable" and the others are still returned, with the error.
+func ListUsageMetrics(resourceID string, definitions []metrics.Definition) ([]UsageMetric, error) {
+	queries := usageQueries(definitions)
+	results := make([][]metrics.Series, len(queries))
+	errs := make([]error, len(queries))
+	end := time.Now()
+	var wg sync.WaitGroup
+	for i, query := range queries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = metrics.Query(resourceID, query.Names, query.Aggregation, query.Window, end)
+		}()
+	}
+	wg.Wait()
+
+	var series []metrics.Series
+	failed := map[string]bool{}
+	var failures []string
+	for i, query := range queries {
+		series = append(series, results[i]...)
+		if errs[i] != nil {
+			for _, name := range query.Names {
+				failed[name] = true
+			}
+			failures = append(failures, fmt.Sprintf("%s: %v", strings.Join(query.Names, ", "), errs[i]))
+		}
+	}
+
+	usageMetrics := latestUsage(definitions, series, failed)
+	if len(failures) > 0 {
+		return usageMetrics, fmt.Errorf("%d of %d metric requests failed: %s", len(failures), len(queries), strings.Join(failures, "; "))
+	}
+	return usageMetrics, nil
+}
+
+// Quota thresholds, as a percentage of the limit
+const (
+	WarningPercent  = 80
+	CriticalPercent = 95
+)
+
+// Quota is the usage of a subscription limit in a region
+type Quota struct {
+	Provider string // "Compute", "Network" or "Storage"
+	ID       string // e.g. "standardDSv3Family"
+	Name     string // e.g. "Standard DSv3 Family vCPUs"
+	Location string
+	Current  float64
+	Limit    float64
+	Unit     string
+}
+
+// Percent returns the usage as a percentage of the limit
+func (q Quota) Percent() float64 {
+	if q.Limit <= 0 {
+		if q.Current > 0 {
+			return 100
+		}
+		return 0
+	}
+	return q.Current / q.Limit * 100
+}
+
+// Level returns "critical", "warning" or "ok" for the usage of the quota
+func (q Quota) Level() string {
+	switch percent := q.Percent(); {
+	case percent >= CriticalPercent:
+		return "critical"
+	case percent >= WarningPercent:
+		return "warning"
+	}
+	return "ok"
+}
+
+// Headline reports whether the quota is one of the regional totals that
+// are worth showing even when nothing uses them yet
+func (q Quota) Headline() bool {
+	switch strings.ToLower(q.ID) {
+	case "cores", "virtualmachines", "publicipaddresses", "standardskupublicipaddresses", "storageaccounts":
+		return true
+	}
+	return false
+}
+
+// quotaSources are the commands that list the quotas of a region, each
+// with the provider it reports on
+var quotaSources = []struct {
+	provider string
+	args     []string
+}{
+	{"Compute", []string{"vm", "list-usage"}},
+	{"Network", []string{"network", "list-usages"}},
+	{"Storage", []string{"storage", "account", "show-usage"}},
+}
+
+// ListQuotas returns the compute, network and storage quotas of the
+// subscription in a region, the closest to their limit first. When a
+// provider fails the quotas of the others are still returned, with the
+// error.
+func ListQuotas(location string) ([]Quota, error) {
+	results := make([][]Quota, len(quotaSources))
+	errs := make([]error, len(quotaSources))
+	var wg sync.WaitGroup
+	for i, source := range quotaSources {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			args := append(append([]string{}, source.args...), "--location", location, "--output", "json")
+			out, err := azcli.CommandContext(ctx, args...).Output()
+			if err != nil {
+				errs[i] = fmt.Errorf("failed to list %s quotas: %v", strings.ToLower(source.provider), err)
+				return
+			}
+			results[i], errs[i] = ParseQuotas(out, source.provider, location)
+		}()
+	}
+	wg.Wait()
+
+	var quotas []Quota
+	for _, result := range results {
+		quotas = append(quotas, result...)
+	}
+	SortQuotas(quotas)
+
+	var failures []string
+	for _, err := range errs {
+		if err != nil {
+			failures = append(failures, err.Error())
+		}
+	}
+	if len(failures) > 0 {
+		return quotas, fmt.Errorf("%s", strings.Join(failures, "; "))
+	}
+	return quotas, nil
+}
+
+// quotaNumber reads the numbers of the usage commands, which some of them
+// print as strings
+type quotaNumber float64
+
+func (n *quotaNumber) UnmarshalJSON(data []byte) error {
+	text := strings.Trim(string(data), `"`)
+	if text == "" || text == "null" {
+		*n = 0
+		return nil
+	}
+	value, err := strconv.ParseFloat(text, 64)
 	if err != nil {
-		return nil, err
+		return fmt.Errorf("invalid quota value %s", data)
 	}
+	*n = quotaNumber(value)
+	return nil
+}
 
-	// Azure CLI returns an object with a "value" field containing the array
-	var response struct {
-		Value []struct {
-			DisplayDescription string `json:"displayDescription"`
-			ErrorCode          string `json:"errorCode"`
-			ID                 string `json:"id"`
-			Name               struct {
-				Value          string `json:"value"`
-				LocalizedValue string `json:"localizedValue"`
-			} `json:"name"`
-			Type string `json:"type"`
-			Unit string `json:"unit"`
-		} `json:"value"`
+// ParseQuotas parses the output of az vm list-usage, az network
+// list-usages or az storage account show-usage, which is a single object
+func ParseQuotas(data []byte, provider, location string) ([]Quota, error) {
+	type item struct {
+		CurrentValue quotaNumber `json:"currentValue"`
+		Limit        quotaNumber `json:"limit"`
+		LocalName    string      `json:"localName"`
+		Name         struct {
+			Value          string `json:"value"`
+			LocalizedValue string `json:"localizedValue"`
+		} `json:"name"`
+		Unit string `json:"unit"`
 	}
 
-	if err := json.Unmarshal(out, &response); err != nil {
-		return nil, fmt.Errorf("failed to parse metrics response: %v", err)
+	var items []item
+	if trimmed := strings.TrimSpace(string(data)); strings.HasPrefix(trimmed, "{") {
+		var single item
+		if err := json.Unmarshal(data, &single); err != nil {
+			return nil, fmt.Errorf("failed to parse %s quotas: %v", strings.ToLower(provider), err)
+		}
+		items = append(items, single)
+	} else if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("failed to parse %s quotas: %v", strings.ToLower(provider), err)
 	}
 
-	// Convert to our UsageMetric format
-	var metrics []UsageMetric
-	for _, item := range response.Value {
-		metric := UsageMetric{
-			Name:  item.Name.LocalizedValue,
-			Value: "N/A", // Metrics list doesn't provide current values, just metadata
-			Limit: "N/A", // Metrics list doesn't provide limits
+	quotas := make([]Quota, 0, len(items))
+	for _, item := range items {
+		name := item.LocalName
+		if name == "" {
+			name = item.Name.LocalizedValue
+		}
+		if name == "" {
+			name = item.Name.Value
 		}
-		metrics = append(metrics, metric)
+		unit := item.Unit
+		if unit == "" {
+			unit = "Count"
+		}
+		quotas = append(quotas, Quota{
+			Provider: provider,
+			ID:       item.Name.Value,
+			Name:     name,
+			Location: location,
+			Current:  float64(item.CurrentValue),
+			Limit:    float64(item.Limit),
+			Unit:     unit,
+		})
 	}
+	return quotas, nil
+}
 
-	return metrics, nil
+// SortQuotas orders quotas by how close they are to their limit, then by
+// provider and name
+func SortQuotas(quotas []Quota) {
+	sort.SliceStable(quotas, func(i, j int) bool {
+		a, b := quotas[i], quotas[j]
+		if a.Percent() != b.Percent() {
+			return a.Percent() > b.Percent()
+		}
+		if a.Provider != b.Provider {
+			return a.Provider < b.Provider
+		}
+		return a.Name < b.Name
+	})
 }
 
-func ListAlarms(resourceID string) ([]Alarm, error) {
-	// Use the correct Azure CLI command for metric alerts
-	cmd := exec.Command("az", "monitor", "metrics", "alert", "list", "--output", "json")
-	out, err := cmd.Output()
+// ListAlarms returns the metric alert rules that watch scope: rules on the
+// resource itself, on resources below it, or on a group or subscription
+// that contains it. An empty scope lists the rules of the subscription.
+func ListAlarms(scope string) ([]Alarm, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	out, err := azcli.CommandContext(ctx, "monitor", "metrics", "alert", "list", "--output", "json").Output()
 	if err != nil {
 		return nil, err
 	}
+	return ParseAlarms(out, scope)
+}
 
+// ParseAlarms parses the output of az monitor metrics alert list and keeps
+// the rules that watch scope
+func ParseAlarms(data []byte, scope string) ([]Alarm, error) {
 	// Azure CLI returns an array of alert rules
 	var alertRules []struct {
-		Name      string `json:"name"`
-		Enabled   bool   `json:"enabled"`
-		Severity  int    `json:"severity"`
+		Name      string   `json:"name"`
+		Enabled   bool     `json:"enabled"`
+		Severity  int      `json:"severity"`
+		Scopes    []string `json:"scopes"`
 		Condition struct {
 			AllOf []struct {
-				MetricName string `json:"metricName"`
-				Operator   string `json:"operator"`
-				Threshold  string `json:"threshold"`
+				MetricName string  `json:"metricName"`
+				Operator   string  `json:"operator"`
+				Threshold  float64 `json:"threshold"`
 			} `json:"allOf"`
-		} `json:"condition"`
+		} `json:"criteria"`
 		WindowSize string `json:"windowSize"`
 	}
 
-	if err := json.Unmarshal(out, &alertRules); err != nil {
+	if err := json.Unmarshal(data, &alertRules); err != nil {
 		return nil, err
 	}
 
 	// Convert to our Alarm format
 	var alarms []Alarm
 	for _, rule := range alertRules {
+		if scope != "" && !slices.ContainsFunc(rule.Scopes, func(ruleScope string) bool { return Overlaps(ruleScope, scope) }) {
+			continue
+		}
+
 		status := "OK"
 		if !rule.Enabled {
 			status = "Disabled"
@@ -108,3 +415,10 @@ func ListAlarms(resourceID string) ([]Alarm, error) {
 
 	return alarms, nil
 }
+
+// Overlaps reports whether one Azure scope contains the other, e.g. a
+// resource group and a resource in it, ignoring case
+func Overlaps(a, b string) bool {
+	a, b = strings.ToLower(strings.TrimRight(a, "/")), strings.ToLower(strings.TrimRight(b, "/"))
+	return a == b || strings.HasPrefix(a, b+"/") || strings.HasPrefix(b, a+"/")
+}
@@ -0,0 +1,193 @@
+package usage
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/olafkfreund/azure-tui/internal/azure/metrics"
+)
+
+func TestParseQuotas(t *testing.T) {
+	// az vm list-usage prints its numbers as strings
+	compute := []byte(`[
+		{"currentValue": "92", "limit": "100", "localName": "Total Regional vCPUs",
+		 "name": {"localizedValue": "Total Regional vCPUs", "value": "cores"}},
+		{"currentValue": "0", "limit": "24", "localName": "Standard NC Family vCPUs",
+		 "name": {"localizedValue": "Standard NC Family vCPUs", "value": "standardNCFamily"}}
+	]`)
+	quotas, err := ParseQuotas(compute, "Compute", "westeurope")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(quotas) != 2 {
+		t.Fatalf("expected 2 quotas, got %d", len(quotas))
+	}
+	cores := quotas[0]
+	if cores.ID != "cores" || cores.Name != "Total Regional vCPUs" || cores.Current != 92 || cores.Limit != 100 ||
+		cores.Location != "westeurope" || cores.Unit != "Count" {
+		t.Errorf("unexpected quota %+v", cores)
+	}
+	if !cores.Headline() || quotas[1].Headline() {
+		t.Error("expected the regional vCPUs to be a headline quota and a VM family not")
+	}
+
+	// az storage account show-usage prints a single object with numbers
+	storage := []byte(`{"currentValue": 5, "limit": 250, "name": {"localizedValue": "Storage Accounts", "value": "StorageAccounts"}, "unit": "Count"}`)
+	quotas, err = ParseQuotas(storage, "Storage", "westeurope")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(quotas) != 1 || quotas[0].Name != "Storage Accounts" || quotas[0].Current != 5 || quotas[0].Limit != 250 {
+		t.Errorf("unexpected storage quotas %+v", quotas)
+	}
+
+	if _, err := ParseQuotas([]byte(`[{"currentValue": "many"}]`), "Network", "westeurope"); err == nil {
+		t.Error("expected an error for a value that is not a number")
+	}
+}
+
+func TestQuotaLevels(t *testing.T) {
+	tests := []struct {
+		current, limit float64
+		percent        float64
+		level          string
+	}{
+		{5, 250, 2, "ok"},
+		{41, 50, 82, "warning"},
+		{8, 8, 100, "critical"},
+		{0, 0, 0, "ok"},
+		{1, 0, 100, "critical"},
+	}
+	for _, tt := range tests {
+		q := Quota{Current: tt.current, Limit: tt.limit}
+		if got := q.Percent(); got != tt.percent {
+			t.Errorf("Percent() of %v/%v = %v, want %v", tt.current, tt.limit, got, tt.percent)
+		}
+		if got := q.Level(); got != tt.level {
+			t.Errorf("Level() of %v/%v = %q, want %q", tt.current, tt.limit, got, tt.level)
+		}
+	}
+}
+
+func TestSortQuotas(t *testing.T) {
+	quotas := []Quota{
+		{Provider: "Network", Name: "Virtual Networks", Current: 3, Limit: 1000},
+		{Provider: "Compute", Name: "Total Regional vCPUs", Current: 92, Limit: 100},
+		{Provider: "Storage", Name: "Storage Accounts", Current: 0, Limit: 250},
+		{Provider: "Compute", Name: "Availability Sets", Current: 0, Limit: 2500},
+	}
+	SortQuotas(quotas)
+	want := []string{"Total Regional vCPUs", "Virtual Networks", "Availability Sets", "Storage Accounts"}
+	for i, name := range want {
+		if quotas[i].Name != name {
+			t.Errorf("quota %d is %q, want %q", i, quotas[i].Name, name)
+		}
+	}
+}
+
+func TestUsageMetrics(t *testing.T) {
+	// Storage capacity metrics are only kept hourly
+	definitions, err := metrics.ParseDefinitions([]byte(`[
+		{"name": {"value": "Transactions"}, "unit": "Count", "primaryAggregationType": "Total",
+		 "metricAvailabilities": [{"timeGrain": "PT1M"}, {"timeGrain": "PT5M"}, {"timeGrain": "PT1H"}]},
+		{"name": {"value": "UsedCapacity", "localizedValue": "Used capacity"}, "unit": "Bytes", "primaryAggregationType": "Average",
+		 "metricAvailabilities": [{"timeGrain": "PT1H"}]},
+		{"name": {"value": "Availability"}, "unit": "Percent", "primaryAggregationType": "Average"},
+		{"name": {"value": "BlobCount"}, "unit": "Count", "primaryAggregationType": "Average",
+		 "metricAvailabilities": [{"timeGrain": "P1D"}, {"timeGrain": "PT1H"}]}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var batches []string
+	for _, query := range usageQueries(definitions) {
+		batches = append(batches, fmt.Sprintf("%s %s %s %s", query.Aggregation, query.Window.Interval, query.Window.Duration, strings.Join(query.Names, ",")))
+	}
+	want := []string{"Total PT5M 1h0m0s Transactions", "Average PT1H 3h0m0s UsedCapacity,BlobCount", "Average PT5M 1h0m0s Availability"}
+	if !slices.Equal(batches, want) {
+		t.Errorf("usageQueries() = %q, want %q", batches, want)
+	}
+
+	series, err := metrics.ParseSeries([]byte(`{"value": [
+		{"name": {"value": "UsedCapacity"}, "unit": "Bytes", "timeseries": [{"data": [
+			{"timeStamp": "2025-01-01T00:00:00Z", "average": 1024},
+			{"timeStamp": "2025-01-01T01:00:00Z", "average": 2048},
+			{"timeStamp": "2025-01-01T02:00:00Z"}
+		]}]},
+		{"name": {"value": "BlobCount"}, "unit": "Count", "timeseries": []}
+	]}`), "Average")
+	if err != nil {
+		t.Fatal(err)
+	}
+	usageMetrics := latestUsage(definitions, series, map[string]bool{"Transactions": true})
+	var values []string
+	for _, metric := range usageMetrics {
+		values = append(values, metric.Name+"="+metric.Value)
+	}
+	want = []string{"Transactions=unavailable", "Used capacity=" + metrics.FormatValue(2048, "Bytes"), "Availability=no data", "BlobCount=no data"}
+	if !slices.Equal(values, want) {
+		t.Errorf("latestUsage() = %q, want %q", values, want)
+	}
+	if used := usageMetrics[1]; used.Metric != "UsedCapacity" || !used.Found || used.Latest != 2048 {
+		t.Errorf("unexpected used capacity %+v", used)
+	}
+}
+
+func TestUsageQueriesBatchSize(t *testing.T) {
+	definitions := make([]metrics.Definition, maxMetricsPerQuery+1)
+	for i := range definitions {
+		definitions[i] = metrics.Definition{Name: fmt.Sprint(i), PrimaryAggregation: "Average"}
+	}
+	if queries := usageQueries(definitions); len(queries) != 2 || len(queries[0].Names) != maxMetricsPerQuery || queries[1].Names[0] != "20" {
+		t.Errorf("usageQueries(%d metrics) = %+v", len(definitions), queries)
+	}
+}
+
+func TestParseAlarmsScoped(t *testing.T) {
+	data := []byte(`[
+		{"name": "vm-cpu", "enabled": true, "severity": 2, "windowSize": "PT5M",
+		 "scopes": ["/subscriptions/sub-1/resourceGroups/rg-prod/providers/Microsoft.Compute/virtualMachines/vm-web-01"],
+		 "criteria": {"allOf": [{"metricName": "Percentage CPU", "operator": "GreaterThan", "threshold": 80}]}},
+		{"name": "group-wide", "enabled": false, "severity": 3, "windowSize": "PT15M",
+		 "scopes": ["/subscriptions/sub-1/resourceGroups/RG-PROD"]},
+		{"name": "other-group", "enabled": true, "severity": 1, "windowSize": "PT5M",
+		 "scopes": ["/subscriptions/sub-1/resourceGroups/rg-dev"]}
+	]`)
+
+	names := func(alarms []Alarm) []string {
+		var names []string
+		for _, alarm := range alarms {
+			names = append(names, alarm.Name)
+		}
+		return names
+	}
+	tests := []struct {
+		scope string
+		want  []string
+	}{
+		{"/subscriptions/sub-1/resourceGroups/rg-prod/providers/Microsoft.Compute/virtualMachines/vm-web-01", []string{"vm-cpu", "group-wide"}},
+		{"/subscriptions/sub-1/resourceGroups/rg-prod", []string{"vm-cpu", "group-wide"}},
+		{"/subscriptions/sub-1/resourceGroups/rg-prod-2", nil},
+		{"", []string{"vm-cpu", "group-wide", "other-group"}},
+	}
+	for _, tt := range tests {
+		alarms, err := ParseAlarms(data, tt.scope)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := names(alarms); !slices.Equal(got, tt.want) {
+			t.Errorf("ParseAlarms(%q) = %v, want %v", tt.scope, got, tt.want)
+		}
+	}
+
+	alarms, _ := ParseAlarms(data, "")
+	if alarms[0].Status != "OK" || alarms[1].Status != "Disabled" {
+		t.Errorf("unexpected statuses %q and %q", alarms[0].Status, alarms[1].Status)
+	}
+	if !strings.Contains(alarms[0].Details, "Metric: Percentage CPU") {
+		t.Errorf("expected the metric in the details, got %q", alarms[0].Details)
+	}
+}
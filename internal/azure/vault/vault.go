@@ -2,7 +2,8 @@ package vault
 
 import (
 	"encoding/json"
-	"os/exec"
+
+	"github.com/olafkfreund/azure-tui/internal/azcli"
 )
 
 type KeyVault struct {
@@ -12,7 +13,7 @@ type KeyVault struct {
 }
 
 func ListKeyVaults() ([]KeyVault, error) {
-	cmd := exec.Command("az", "keyvault", "list", "--output", "json")
+	cmd := azcli.Command("keyvault", "list", "--output", "json")
 	out, err := cmd.Output()
 	if err != nil {
 		return nil, err
@@ -25,9 +26,9 @@ func ListKeyVaults() ([]KeyVault, error) {
 }
 
 func CreateKeyVault(name, group, location string) error {
-	return exec.Command("az", "keyvault", "create", "--name", name, "--resource-group", group, "--location", location).Run()
+	return azcli.Command("keyvault", "create", "--name", name, "--resource-group", group, "--location", location).Run()
 }
 
 func DeleteKeyVault(name, group string) error {
-	return exec.Command("az", "keyvault", "delete", "--name", name, "--resource-group", group).Run()
+	return azcli.Command("keyvault", "delete", "--name", name, "--resource-group", group).Run()
 }
@@ -10,6 +10,8 @@ import (
 	"regexp"
 	"strings"
 	"time"
+
+	"github.com/olafkfreund/azure-tui/internal/azcli"
 )
 
 // BicepTemplate represents a Bicep template structure
@@ -294,7 +296,7 @@ func (bm *BicepManager) CompileBicep(ctx context.Context, bicepFilePath string)
 
 	var cmd *exec.Cmd
 	if strings.Contains(bm.bicepPath, "az bicep") {
-		cmd = exec.CommandContext(ctx, "az", "bicep", "build", "--file", bicepFilePath, "--outfile", outputPath)
+		cmd = azcli.CommandContext(ctx, "bicep", "build", "--file", bicepFilePath, "--outfile", outputPath)
 	} else {
 		cmd = exec.CommandContext(ctx, bm.bicepPath, "build", bicepFilePath, "--outfile", outputPath)
 	}
@@ -311,7 +313,7 @@ func (bm *BicepManager) CompileBicep(ctx context.Context, bicepFilePath string)
 func (bm *BicepManager) ValidateBicep(ctx context.Context, bicepFilePath string) (*ValidationResult, error) {
 	var cmd *exec.Cmd
 	if strings.Contains(bm.bicepPath, "az bicep") {
-		cmd = exec.CommandContext(ctx, "az", "bicep", "build", "--file", bicepFilePath, "--stdout")
+		cmd = azcli.CommandContext(ctx, "bicep", "build", "--file", bicepFilePath, "--stdout")
 	} else {
 		cmd = exec.CommandContext(ctx, bm.bicepPath, "build", bicepFilePath, "--stdout")
 	}
@@ -359,7 +361,7 @@ func (bm *BicepManager) DeployBicep(ctx context.Context, resourceGroup, deployme
 		args = append(args, "--parameters", paramStr)
 	}
 
-	cmd := exec.CommandContext(ctx, "az", args...)
+	cmd := azcli.CommandContext(ctx, args...)
 	output, err := cmd.CombinedOutput()
 
 	result.Duration = time.Since(startTime)
@@ -394,7 +396,7 @@ func (bm *BicepManager) DeployBicep(ctx context.Context, resourceGroup, deployme
 // GenerateBicepFromResource generates a Bicep template from an existing Azure resource
 func (bm *BicepManager) GenerateBicepFromResource(ctx context.Context, resourceID string) (*BicepTemplate, error) {
 	// Export the resource as ARM template first
-	cmd := exec.CommandContext(ctx, "az", "resource", "show", "--ids", resourceID)
+	cmd := azcli.CommandContext(ctx, "resource", "show", "--ids", resourceID)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get resource: %v", err)
@@ -577,7 +579,7 @@ func (bm *BicepManager) ListBicepFiles(directory string) ([]string, error) {
 func (bm *BicepManager) GetBicepVersion(ctx context.Context) (string, error) {
 	var cmd *exec.Cmd
 	if strings.Contains(bm.bicepPath, "az bicep") {
-		cmd = exec.CommandContext(ctx, "az", "bicep", "version")
+		cmd = azcli.CommandContext(ctx, "bicep", "version")
 	} else {
 		cmd = exec.CommandContext(ctx, bm.bicepPath, "--version")
 	}
@@ -0,0 +1,67 @@
+// Package clipboard copies text to the system clipboard, or to the
+// terminal's clipboard with an OSC52 escape sequence when there is no
+// system clipboard, e.g. over SSH.
+package clipboard
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	"github.com/aymanbagabas/go-osc52/v2"
+)
+
+// Targets reported by Copy
+const (
+	System   = "clipboard"
+	Terminal = "terminal clipboard"
+)
+
+// Seams for tests
+var (
+	getenv               = os.Getenv
+	output     io.Writer = os.Stderr
+	systemCopy           = func(text string) error {
+		if clipboard.Unsupported {
+			return errUnsupported
+		}
+		return clipboard.WriteAll(text)
+	}
+)
+
+var errUnsupported = errors.New("no system clipboard")
+
+// Copy puts text on the clipboard and returns which clipboard it went to.
+// Over SSH the system clipboard belongs to the remote host, so the text
+// goes to the local terminal with OSC52 instead.
+func Copy(text string) (string, error) {
+	if !Remote() {
+		if err := systemCopy(text); err == nil {
+			return System, nil
+		}
+	}
+	if _, err := Sequence(text).WriteTo(output); err != nil {
+		return "", err
+	}
+	return Terminal, nil
+}
+
+// Remote reports whether the TUI runs in an SSH session
+func Remote() bool {
+	return getenv("SSH_TTY") != "" || getenv("SSH_CONNECTION") != "" || getenv("SSH_CLIENT") != ""
+}
+
+// Sequence returns the OSC52 sequence for text, wrapped for tmux or
+// screen so that the multiplexer passes it on to the terminal
+func Sequence(text string) osc52.Sequence {
+	seq := osc52.New(text)
+	switch {
+	case getenv("TMUX") != "":
+		return seq.Tmux()
+	case strings.HasPrefix(getenv("TERM"), "screen"):
+		return seq.Screen()
+	}
+	return seq
+}
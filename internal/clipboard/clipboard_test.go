@@ -0,0 +1,93 @@
+package clipboard
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// fake swaps the environment, the system clipboard and the terminal for
+// the duration of a test
+func fake(t *testing.T, env map[string]string, systemErr error) (copied *string, terminal *bytes.Buffer) {
+	t.Helper()
+	oldGetenv, oldOutput, oldSystemCopy := getenv, output, systemCopy
+	t.Cleanup(func() { getenv, output, systemCopy = oldGetenv, oldOutput, oldSystemCopy })
+
+	copied = new(string)
+	terminal = &bytes.Buffer{}
+	getenv = func(key string) string { return env[key] }
+	output = terminal
+	systemCopy = func(text string) error {
+		if systemErr != nil {
+			return systemErr
+		}
+		*copied = text
+		return nil
+	}
+	return copied, terminal
+}
+
+func TestCopyUsesSystemClipboard(t *testing.T) {
+	copied, terminal := fake(t, nil, nil)
+
+	target, err := Copy("/subscriptions/123")
+	if err != nil || target != System {
+		t.Fatalf("Copy() = %q, %v, want %q", target, err, System)
+	}
+	if *copied != "/subscriptions/123" {
+		t.Errorf("system clipboard got %q", *copied)
+	}
+	if terminal.Len() != 0 {
+		t.Errorf("nothing should be written to the terminal, got %q", terminal.String())
+	}
+}
+
+func TestCopyFallsBackToOSC52(t *testing.T) {
+	_, terminal := fake(t, nil, errors.New("xclip not found"))
+
+	target, err := Copy("10.0.0.4")
+	if err != nil || target != Terminal {
+		t.Fatalf("Copy() = %q, %v, want %q", target, err, Terminal)
+	}
+	want := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte("10.0.0.4")) + "\x07"
+	if terminal.String() != want {
+		t.Errorf("terminal got %q, want %q", terminal.String(), want)
+	}
+}
+
+func TestCopyOverSSHSkipsSystemClipboard(t *testing.T) {
+	copied, terminal := fake(t, map[string]string{"SSH_CONNECTION": "10.0.0.1 50000 10.0.0.2 22"}, nil)
+
+	target, err := Copy("secret-name")
+	if err != nil || target != Terminal {
+		t.Fatalf("Copy() = %q, %v, want %q", target, err, Terminal)
+	}
+	if *copied != "" {
+		t.Errorf("the remote host's clipboard should not be used, got %q", *copied)
+	}
+	if !strings.Contains(terminal.String(), base64.StdEncoding.EncodeToString([]byte("secret-name"))) {
+		t.Errorf("terminal got %q", terminal.String())
+	}
+}
+
+func TestSequenceMultiplexerPassthrough(t *testing.T) {
+	tests := []struct {
+		name   string
+		env    map[string]string
+		prefix string
+	}{
+		{"plain terminal", map[string]string{"TERM": "xterm-256color"}, "\x1b]52;"},
+		{"tmux", map[string]string{"TMUX": "/tmp/tmux-1000/default,1,0", "TERM": "screen-256color"}, "\x1bPtmux;"},
+		{"screen", map[string]string{"TERM": "screen"}, "\x1bP\x1b]52;"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake(t, tt.env, nil)
+			if got := Sequence("x").String(); !strings.HasPrefix(got, tt.prefix) {
+				t.Errorf("Sequence() = %q, want prefix %q", got, tt.prefix)
+			}
+		})
+	}
+}
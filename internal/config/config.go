@@ -1,9 +1,14 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
 
 	"gopkg.in/yaml.v3"
 )
@@ -48,27 +53,112 @@ type UIConfig struct {
 	TerraformShortcuts map[string]string `yaml:"terraform_shortcuts"`
 	PopupWidth         int               `yaml:"popup_width"`
 	PopupHeight        int               `yaml:"popup_height"`
-	EnableMouseSupport bool              `yaml:"enable_mouse_support"`
+	EnableMouseSupport *bool             `yaml:"enable_mouse_support,omitempty"` // On by default
 	ColorScheme        string            `yaml:"color_scheme"`
+	PlainMode          bool              `yaml:"plain_mode"` // No colors, emoji or box drawing, also set by TERM=dumb or NO_COLOR
+	Layout             LayoutConfig      `yaml:"layout,omitempty"`
+}
+
+// LayoutConfig is the panel layout, saved whenever it is changed
+type LayoutConfig struct {
+	TreeRatio    float64 `yaml:"tree_ratio,omitempty"` // Share of the width given to the tree
+	BottomPanel  bool    `yaml:"bottom_panel,omitempty"`
+	BottomHeight int     `yaml:"bottom_height,omitempty"`
+	BottomView   string  `yaml:"bottom_view,omitempty"` // "logs", "output" or "ai"
+}
+
+// SavedSearch is a named search query shown as a smart folder in the resource tree
+type SavedSearch struct {
+	Name  string `yaml:"name"`
+	Query string `yaml:"query"`
+}
+
+// Bookmark is a tree node pinned to the Favorites folder. The subscription
+// is kept so that bookmarks can be opened from any subscription.
+type Bookmark struct {
+	Name           string `yaml:"name"`
+	Type           string `yaml:"type"` // Resource type, or "group" for resource groups
+	SubscriptionID string `yaml:"subscription_id"`
+	ResourceID     string `yaml:"resource_id"`
+}
+
+// SavedQuery is a named KQL query of a Log Analytics workspace
+type SavedQuery struct {
+	Name      string `yaml:"name"`
+	Workspace string `yaml:"workspace"` // Resource ID of the workspace
+	Query     string `yaml:"query"`
+}
+
+// OpenTab is a tab that was open in the right panel, restored on the next start
+type OpenTab struct {
+	Type       string `yaml:"type"`
+	Title      string `yaml:"title"`
+	View       string `yaml:"view,omitempty"`
+	ResourceID string `yaml:"resource_id,omitempty"`
+}
+
+// DiagnosticsConfig is the central Log Analytics workspace every resource
+// should send its logs to, checked by the diagnostic settings audit
+type DiagnosticsConfig struct {
+	Workspace   string `yaml:"workspace,omitempty"`    // Resource ID of the workspace
+	SettingName string `yaml:"setting_name,omitempty"` // Name of the diagnostic settings created
+	Logs        *bool  `yaml:"logs,omitempty"`         // Send all logs, on by default
+	Metrics     *bool  `yaml:"metrics,omitempty"`      // Send all metrics, on by default
+}
+
+// KeymapConfig selects a keymap preset ("default", "vim" or "emacs") and
+// rebinds individual actions, e.g. {"start": ["x"], "help": ["?", "f1"]}
+type KeymapConfig struct {
+	Preset   string              `yaml:"preset,omitempty"`
+	Bindings map[string][]string `yaml:"bindings,omitempty"`
 }
 
 type AppConfig struct {
-	Naming    NamingConfig    `yaml:"naming"`
-	Env       string          `yaml:"env"`
-	AI        AIConfig        `yaml:"ai"`
-	Terraform TerraformConfig `yaml:"terraform"`
-	Editor    EditorConfig    `yaml:"editor"`
-	UI        UIConfig        `yaml:"ui"`
+	Naming        NamingConfig      `yaml:"naming"`
+	Env           string            `yaml:"env"`
+	AI            AIConfig          `yaml:"ai"`
+	Terraform     TerraformConfig   `yaml:"terraform"`
+	Editor        EditorConfig      `yaml:"editor"`
+	UI            UIConfig          `yaml:"ui"`
+	Keymap        KeymapConfig      `yaml:"keymap,omitempty"`
+	SavedSearches *[]SavedSearch    `yaml:"saved_searches,omitempty"` // nil until configured, then possibly empty
+	Bookmarks     []Bookmark        `yaml:"bookmarks,omitempty"`
+	OpenTabs      []OpenTab         `yaml:"open_tabs,omitempty"`
+	SavedQueries  []SavedQuery      `yaml:"saved_queries,omitempty"`
+	QueryHistory  []string          `yaml:"query_history,omitempty"` // Newest last
+	Diagnostics   DiagnosticsConfig `yaml:"diagnostics,omitempty"`
 }
 
 var loadedConfig *AppConfig
 
+// configMu guards loadedConfig and serializes the updates of the config
+// file. The config loaded is shared and never changed; updates read the
+// file again and replace it.
+var configMu sync.Mutex
+
 func LoadConfig() (*AppConfig, error) {
+	configMu.Lock()
+	defer configMu.Unlock()
+
 	if loadedConfig != nil {
 		return loadedConfig, nil
 	}
-	path := filepath.Join(os.Getenv("HOME"), ".config", "azure-tui", "config.yaml")
-	f, err := os.Open(path)
+	cfg, err := readConfig()
+	if err != nil {
+		return nil, err
+	}
+	loadedConfig = cfg
+	return cfg, nil
+}
+
+// configPath returns the path of the config file
+func configPath() string {
+	return filepath.Join(os.Getenv("HOME"), ".config", "azure-tui", "config.yaml")
+}
+
+// readConfig reads the config file into a new configuration
+func readConfig() (*AppConfig, error) {
+	f, err := os.Open(configPath())
 	if err != nil {
 		return nil, err
 	}
@@ -79,10 +169,10 @@ func LoadConfig() (*AppConfig, error) {
 		}
 	}()
 	var cfg AppConfig
-	if err := yaml.NewDecoder(f).Decode(&cfg); err != nil {
+	// An empty file is an empty configuration
+	if err := yaml.NewDecoder(f).Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
 		return nil, err
 	}
-	loadedConfig = &cfg
 	return &cfg, nil
 }
 
@@ -119,23 +209,24 @@ func GetTerraformConfig() TerraformConfig {
 	}
 
 	// Apply defaults for empty values
-	if cfg.Terraform.WorkspacePath == "" {
-		cfg.Terraform.WorkspacePath = filepath.Join(os.Getenv("HOME"), ".config", "azure-tui", "terraform", "workspaces")
+	terraform := cfg.Terraform
+	if terraform.WorkspacePath == "" {
+		terraform.WorkspacePath = filepath.Join(os.Getenv("HOME"), ".config", "azure-tui", "terraform", "workspaces")
 	}
-	if cfg.Terraform.TemplatesPath == "" {
-		cfg.Terraform.TemplatesPath = "./terraform/templates"
+	if terraform.TemplatesPath == "" {
+		terraform.TemplatesPath = "./terraform/templates"
 	}
-	if cfg.Terraform.StatePath == "" {
-		cfg.Terraform.StatePath = filepath.Join(os.Getenv("HOME"), ".config", "azure-tui", "terraform", "state")
+	if terraform.StatePath == "" {
+		terraform.StatePath = filepath.Join(os.Getenv("HOME"), ".config", "azure-tui", "terraform", "state")
 	}
-	if cfg.Terraform.DefaultEditor == "" {
-		cfg.Terraform.DefaultEditor = getDefaultEditor()
+	if terraform.DefaultEditor == "" {
+		terraform.DefaultEditor = getDefaultEditor()
 	}
-	if cfg.Terraform.BackendType == "" {
-		cfg.Terraform.BackendType = "local"
+	if terraform.BackendType == "" {
+		terraform.BackendType = "local"
 	}
 
-	return cfg.Terraform
+	return terraform
 }
 
 // GetEditorConfig returns the editor configuration with defaults
@@ -145,21 +236,22 @@ func GetEditorConfig() EditorConfig {
 		return getDefaultEditorConfig()
 	}
 
-	if cfg.Editor.DefaultEditor == "" {
-		cfg.Editor.DefaultEditor = getDefaultEditor()
+	editor := cfg.Editor
+	if editor.DefaultEditor == "" {
+		editor.DefaultEditor = getDefaultEditor()
 	}
-	if cfg.Editor.TempDir == "" {
-		cfg.Editor.TempDir = os.TempDir()
+	if editor.TempDir == "" {
+		editor.TempDir = os.TempDir()
 	}
-	if len(cfg.Editor.FileExtensions) == 0 {
-		cfg.Editor.FileExtensions = map[string]string{
+	if len(editor.FileExtensions) == 0 {
+		editor.FileExtensions = map[string]string{
 			"terraform": ".tf",
 			"variables": ".tfvars",
 			"output":    ".tf",
 		}
 	}
 
-	return cfg.Editor
+	return editor
 }
 
 // GetUIConfig returns the UI configuration with defaults
@@ -169,20 +261,309 @@ func GetUIConfig() UIConfig {
 		return getDefaultUIConfig()
 	}
 
-	if cfg.UI.PopupWidth == 0 {
-		cfg.UI.PopupWidth = 80
+	ui := cfg.UI
+	if ui.PopupWidth == 0 {
+		ui.PopupWidth = 80
+	}
+	if ui.PopupHeight == 0 {
+		ui.PopupHeight = 24
 	}
-	if cfg.UI.PopupHeight == 0 {
-		cfg.UI.PopupHeight = 24
+	if len(ui.TerraformShortcuts) == 0 {
+		ui.TerraformShortcuts = getDefaultTerraformShortcuts()
 	}
-	if len(cfg.UI.TerraformShortcuts) == 0 {
-		cfg.UI.TerraformShortcuts = getDefaultTerraformShortcuts()
+	if ui.ColorScheme == "" {
+		ui.ColorScheme = "azure"
 	}
-	if cfg.UI.ColorScheme == "" {
-		cfg.UI.ColorScheme = "azure"
+	if ui.EnableMouseSupport == nil {
+		enabled := true
+		ui.EnableMouseSupport = &enabled
 	}
 
-	return cfg.UI
+	return ui
+}
+
+// GetKeymapConfig returns the keymap preset and custom bindings
+func GetKeymapConfig() KeymapConfig {
+	cfg, err := LoadConfig()
+	if err != nil {
+		return KeymapConfig{}
+	}
+
+	return cfg.Keymap
+}
+
+// GetDiagnosticsConfig returns the central workspace settings with defaults
+func GetDiagnosticsConfig() DiagnosticsConfig {
+	diagnostics := DiagnosticsConfig{}
+	if cfg, err := LoadConfig(); err == nil {
+		diagnostics = cfg.Diagnostics
+	}
+
+	enabled := true
+	if diagnostics.SettingName == "" {
+		diagnostics.SettingName = "central-workspace"
+	}
+	if diagnostics.Logs == nil {
+		diagnostics.Logs = &enabled
+	}
+	if diagnostics.Metrics == nil {
+		diagnostics.Metrics = &enabled
+	}
+	return diagnostics
+}
+
+// SaveDiagnosticsWorkspace persists the central workspace
+func SaveDiagnosticsWorkspace(workspace string) error {
+	return updateConfig(func(cfg *AppConfig) {
+		cfg.Diagnostics.Workspace = workspace
+	})
+}
+
+// GetSavedSearches returns the saved searches, falling back to the built-in
+// examples when saved searches have never been configured
+func GetSavedSearches() []SavedSearch {
+	cfg, err := LoadConfig()
+	if err != nil {
+		return getDefaultSavedSearches()
+	}
+
+	return savedSearches(cfg)
+}
+
+// savedSearches returns the saved searches of cfg, or the built-in examples
+// when it has none configured. Deleting every saved search leaves none.
+func savedSearches(cfg *AppConfig) []SavedSearch {
+	if cfg.SavedSearches == nil {
+		return getDefaultSavedSearches()
+	}
+	return slices.Clone(*cfg.SavedSearches)
+}
+
+// SaveSearch adds or replaces a saved search by name and persists it
+func SaveSearch(name, query string) error {
+	if name == "" || query == "" {
+		return fmt.Errorf("saved search needs both a name and a query")
+	}
+
+	return updateConfig(func(cfg *AppConfig) {
+		searches := savedSearches(cfg)
+
+		replaced := false
+		for i, saved := range searches {
+			if saved.Name == name {
+				searches[i].Query = query
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			searches = append(searches, SavedSearch{Name: name, Query: query})
+		}
+		cfg.SavedSearches = &searches
+	})
+}
+
+// DeleteSavedSearch removes a saved search by name and persists the change
+func DeleteSavedSearch(name string) error {
+	return updateConfig(func(cfg *AppConfig) {
+		remaining := []SavedSearch{}
+		for _, saved := range savedSearches(cfg) {
+			if saved.Name != name {
+				remaining = append(remaining, saved)
+			}
+		}
+		cfg.SavedSearches = &remaining
+	})
+}
+
+// GetBookmarks returns the bookmarked tree nodes
+func GetBookmarks() []Bookmark {
+	cfg, err := LoadConfig()
+	if err != nil {
+		return nil
+	}
+
+	return cfg.Bookmarks
+}
+
+// AddBookmark adds a bookmark, replacing one with the same resource ID, and
+// persists it
+func AddBookmark(bookmark Bookmark) error {
+	if bookmark.ResourceID == "" || bookmark.SubscriptionID == "" {
+		return fmt.Errorf("bookmark needs both a resource ID and a subscription ID")
+	}
+
+	return updateConfig(func(cfg *AppConfig) {
+		bookmarks := []Bookmark{}
+		for _, existing := range cfg.Bookmarks {
+			if !strings.EqualFold(existing.ResourceID, bookmark.ResourceID) {
+				bookmarks = append(bookmarks, existing)
+			}
+		}
+		cfg.Bookmarks = append(bookmarks, bookmark)
+	})
+}
+
+// DeleteBookmark removes the bookmark of a resource ID and persists the change
+func DeleteBookmark(resourceID string) error {
+	return updateConfig(func(cfg *AppConfig) {
+		remaining := []Bookmark{}
+		for _, bookmark := range cfg.Bookmarks {
+			if !strings.EqualFold(bookmark.ResourceID, resourceID) {
+				remaining = append(remaining, bookmark)
+			}
+		}
+		cfg.Bookmarks = remaining
+	})
+}
+
+// maxQueryHistory is the number of KQL queries kept in the history
+const maxQueryHistory = 50
+
+// GetSavedQueries returns the saved queries of a workspace
+func GetSavedQueries(workspace string) []SavedQuery {
+	cfg, err := LoadConfig()
+	if err != nil {
+		return nil
+	}
+
+	var queries []SavedQuery
+	for _, saved := range cfg.SavedQueries {
+		if strings.EqualFold(saved.Workspace, workspace) {
+			queries = append(queries, saved)
+		}
+	}
+	return queries
+}
+
+// SaveQuery adds or replaces a saved query of a workspace by name and
+// persists it
+func SaveQuery(workspace, name, query string) error {
+	if workspace == "" || name == "" || strings.TrimSpace(query) == "" {
+		return fmt.Errorf("saved query needs a workspace, a name and a query")
+	}
+
+	return updateConfig(func(cfg *AppConfig) {
+		cfg.SavedQueries = append(otherQueries(cfg.SavedQueries, workspace, name), SavedQuery{Name: name, Workspace: workspace, Query: query})
+	})
+}
+
+// DeleteSavedQuery removes a saved query of a workspace and persists the
+// change
+func DeleteSavedQuery(workspace, name string) error {
+	return updateConfig(func(cfg *AppConfig) {
+		cfg.SavedQueries = otherQueries(cfg.SavedQueries, workspace, name)
+	})
+}
+
+// otherQueries returns the saved queries other than the one named name in
+// workspace
+func otherQueries(queries []SavedQuery, workspace, name string) []SavedQuery {
+	others := []SavedQuery{}
+	for _, saved := range queries {
+		if !strings.EqualFold(saved.Workspace, workspace) || saved.Name != name {
+			others = append(others, saved)
+		}
+	}
+	return others
+}
+
+// GetQueryHistory returns the KQL queries that were run, newest last
+func GetQueryHistory() []string {
+	cfg, err := LoadConfig()
+	if err != nil {
+		return nil
+	}
+
+	return cfg.QueryHistory
+}
+
+// AddQueryHistory appends a query to the history, moving it to the end
+// when it was run before, and persists it
+func AddQueryHistory(query string) error {
+	if strings.TrimSpace(query) == "" {
+		return nil
+	}
+
+	return updateConfig(func(cfg *AppConfig) {
+		history := []string{}
+		for _, previous := range cfg.QueryHistory {
+			if previous != query {
+				history = append(history, previous)
+			}
+		}
+		history = append(history, query)
+		if len(history) > maxQueryHistory {
+			history = history[len(history)-maxQueryHistory:]
+		}
+		cfg.QueryHistory = history
+	})
+}
+
+// GetOpenTabs returns the tabs that were open when the app was last closed
+func GetOpenTabs() []OpenTab {
+	cfg, err := LoadConfig()
+	if err != nil {
+		return nil
+	}
+
+	return cfg.OpenTabs
+}
+
+// SaveOpenTabs persists the open tabs so they can be restored on restart
+func SaveOpenTabs(tabs []OpenTab) error {
+	return updateConfig(func(cfg *AppConfig) {
+		cfg.OpenTabs = tabs
+	})
+}
+
+// SaveColorScheme persists the theme chosen in the settings
+func SaveColorScheme(name string) error {
+	return updateConfig(func(cfg *AppConfig) {
+		cfg.UI.ColorScheme = name
+	})
+}
+
+// SaveTerraformWorkspace persists the Terraform workspace folder
+func SaveTerraformWorkspace(path string) error {
+	return updateConfig(func(cfg *AppConfig) {
+		cfg.Terraform.WorkspacePath = path
+	})
+}
+
+// SaveLayout persists the panel layout
+func SaveLayout(layout LayoutConfig) error {
+	return updateConfig(func(cfg *AppConfig) {
+		cfg.UI.Layout = layout
+	})
+}
+
+// updateConfig changes the configuration read from the config file, or one
+// with the default UI settings when no config file exists yet, and saves
+// it. Reading, changing and saving happen under the config lock, so
+// updates from different goroutines do not lose each other's changes. A
+// config file that cannot be read is an error, so that saving does not
+// replace it with defaults.
+func updateConfig(change func(cfg *AppConfig)) error {
+	configMu.Lock()
+	defer configMu.Unlock()
+
+	cfg, err := readConfig()
+	if os.IsNotExist(err) {
+		cfg, err = &AppConfig{UI: getDefaultUIConfig()}, nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+	change(cfg)
+	return writeConfig(cfg)
+}
+
+func getDefaultSavedSearches() []SavedSearch {
+	return []SavedSearch{
+		{Name: "All prod VMs", Query: "type:vm tag:env=prod"},
+		{Name: "Untagged storage", Query: "type:storage tag:none"},
+	}
 }
 
 func getDefaultTerraformConfig() TerraformConfig {
@@ -215,12 +596,13 @@ func getDefaultEditorConfig() EditorConfig {
 }
 
 func getDefaultUIConfig() UIConfig {
+	enabled := true
 	return UIConfig{
 		ShowTerraformMenu:  true,
 		TerraformShortcuts: getDefaultTerraformShortcuts(),
 		PopupWidth:         80,
 		PopupHeight:        24,
-		EnableMouseSupport: true,
+		EnableMouseSupport: &enabled,
 		ColorScheme:        "azure",
 	}
 }
@@ -265,22 +647,47 @@ func getDefaultEditor() string {
 
 // SaveConfig saves the current configuration to file
 func SaveConfig(cfg *AppConfig) error {
-	configDir := filepath.Join(os.Getenv("HOME"), ".config", "azure-tui")
+	configMu.Lock()
+	defer configMu.Unlock()
+
+	return writeConfig(cfg)
+}
+
+// writeConfig writes the config file with the config lock held. It writes a
+// temporary file and renames it over the config file, so that the file is
+// never seen half written.
+func writeConfig(cfg *AppConfig) error {
+	path := configPath()
+	configDir := filepath.Dir(path)
 	if err := os.MkdirAll(configDir, 0755); err != nil {
 		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 
-	configPath := filepath.Join(configDir, "config.yaml")
-	f, err := os.Create(configPath)
+	f, err := os.CreateTemp(configDir, ".config-*.yaml")
 	if err != nil {
 		return fmt.Errorf("failed to create config file: %w", err)
 	}
-	defer f.Close()
+	defer os.Remove(f.Name()) // Fails once renamed
 
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
 	encoder := yaml.NewEncoder(f)
 	if err := encoder.Encode(cfg); err != nil {
+		f.Close()
 		return fmt.Errorf("failed to encode config: %w", err)
 	}
+	if err := f.Chmod(mode); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace config file: %w", err)
+	}
 
 	// Reset loaded config to force reload
 	loadedConfig = nil
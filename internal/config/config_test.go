@@ -0,0 +1,285 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// useConfig points HOME at a temporary directory holding content as the
+// config file, or no config file when content is empty, and returns the
+// path of the config file
+func useConfig(t *testing.T, content string) string {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	loadedConfig = nil
+	t.Cleanup(func() { loadedConfig = nil })
+
+	path := configPath()
+	if content != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return path
+}
+
+func TestSavedSearches(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		change func() error
+		want   []string // Names, in order
+	}{
+		{"defaults without a config", "", func() error { return nil }, []string{"All prod VMs", "Untagged storage"}},
+		{"add to the defaults", "", func() error { return SaveSearch("Web", "rg:rg-web") }, []string{"All prod VMs", "Untagged storage", "Web"}},
+		{"replace by name", "saved_searches: [{name: Web, query: old}]\n", func() error { return SaveSearch("Web", "rg:rg-web") }, []string{"Web"}},
+		{"delete one", "", func() error { return DeleteSavedSearch("All prod VMs") }, []string{"Untagged storage"}},
+		{"delete the last one", "saved_searches: [{name: Web, query: rg:rg-web}]\n", func() error { return DeleteSavedSearch("Web") }, nil},
+		{"configured empty", "saved_searches: []\n", func() error { return nil }, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useConfig(t, tt.config)
+			if err := tt.change(); err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, saved := range GetSavedSearches() {
+				names = append(names, saved.Name)
+			}
+			if !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("GetSavedSearches() = %q, want %q", names, tt.want)
+			}
+		})
+	}
+
+	useConfig(t, "saved_searches: [{name: Web, query: old}]\n")
+	if err := SaveSearch("Web", "rg:rg-web"); err != nil {
+		t.Fatal(err)
+	}
+	if got := GetSavedSearches()[0].Query; got != "rg:rg-web" {
+		t.Errorf("replaced query = %q", got)
+	}
+	if err := SaveSearch("", "q"); err == nil {
+		t.Error("SaveSearch() without a name should fail")
+	}
+}
+
+func TestOpenTabs(t *testing.T) {
+	useConfig(t, "")
+	if got := GetOpenTabs(); len(got) != 0 {
+		t.Errorf("GetOpenTabs() without a config = %+v", got)
+	}
+
+	tabs := []OpenTab{
+		{Type: "resource", Title: "vm", View: "details", ResourceID: "/subscriptions/sub-1/resourceGroups/rg/providers/Microsoft.Compute/virtualMachines/vm"},
+		{Type: "monitor", Title: "Network", View: "network-dashboard"},
+	}
+	if err := SaveOpenTabs(tabs); err != nil {
+		t.Fatal(err)
+	}
+	if got := GetOpenTabs(); !reflect.DeepEqual(got, tabs) {
+		t.Errorf("GetOpenTabs() = %+v, want %+v", got, tabs)
+	}
+	if err := SaveOpenTabs(nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := GetOpenTabs(); len(got) != 0 {
+		t.Errorf("GetOpenTabs() after closing every tab = %+v", got)
+	}
+}
+
+func TestLayout(t *testing.T) {
+	useConfig(t, "ui:\n  color_scheme: nord\n")
+	layout := LayoutConfig{TreeRatio: 0.4, BottomPanel: true, BottomHeight: 8, BottomView: "logs"}
+	if err := SaveLayout(layout); err != nil {
+		t.Fatal(err)
+	}
+	ui := GetUIConfig()
+	if ui.Layout != layout {
+		t.Errorf("layout = %+v, want %+v", ui.Layout, layout)
+	}
+	if ui.ColorScheme != "nord" {
+		t.Errorf("saving the layout changed the color scheme to %q", ui.ColorScheme)
+	}
+}
+
+func TestMouseSupportDefault(t *testing.T) {
+	for config, want := range map[string]bool{
+		"":                                     true,
+		"ui:\n  color_scheme: nord\n":          true,
+		"ui:\n  enable_mouse_support: false\n": false,
+		"ui:\n  enable_mouse_support: true\n":  true,
+	} {
+		useConfig(t, config)
+		if ui := GetUIConfig(); ui.EnableMouseSupport == nil || *ui.EnableMouseSupport != want {
+			t.Errorf("mouse support with config %q = %v, want %v", config, ui.EnableMouseSupport, want)
+		}
+	}
+}
+
+func TestBookmarks(t *testing.T) {
+	useConfig(t, "")
+	vm := Bookmark{Name: "vm", SubscriptionID: "sub-1", ResourceID: "/subscriptions/sub-1/resourceGroups/rg/providers/Microsoft.Compute/virtualMachines/vm"}
+	group := Bookmark{Name: "rg", Type: "group", SubscriptionID: "sub-1", ResourceID: "/subscriptions/sub-1/resourceGroups/rg"}
+
+	for _, bookmark := range []Bookmark{vm, group} {
+		if err := AddBookmark(bookmark); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Bookmarking again replaces it, matching the ID in any case
+	renamed := vm
+	renamed.Name, renamed.ResourceID = "vm-renamed", "/SUBSCRIPTIONS/sub-1/resourceGroups/rg/providers/Microsoft.Compute/virtualMachines/vm"
+	if err := AddBookmark(renamed); err != nil {
+		t.Fatal(err)
+	}
+	if got := GetBookmarks(); !reflect.DeepEqual(got, []Bookmark{group, renamed}) {
+		t.Errorf("GetBookmarks() = %+v", got)
+	}
+
+	if err := DeleteBookmark(group.ResourceID); err != nil {
+		t.Fatal(err)
+	}
+	if got := GetBookmarks(); !reflect.DeepEqual(got, []Bookmark{renamed}) {
+		t.Errorf("GetBookmarks() after delete = %+v", got)
+	}
+	if err := AddBookmark(Bookmark{Name: "no id"}); err == nil {
+		t.Error("AddBookmark() without IDs should fail")
+	}
+}
+
+func TestSavedQueriesAndHistory(t *testing.T) {
+	useConfig(t, "")
+	for _, query := range []struct{ workspace, name, query string }{
+		{"ws-1", "errors", "AppTraces | take 1"},
+		{"ws-2", "errors", "AppTraces"},
+		{"WS-1", "errors", "AppExceptions"}, // Replaces the first
+	} {
+		if err := SaveQuery(query.workspace, query.name, query.query); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := GetSavedQueries("ws-1"); len(got) != 1 || got[0].Query != "AppExceptions" {
+		t.Errorf("GetSavedQueries(ws-1) = %+v, want the replaced query", got)
+	}
+	if err := DeleteSavedQuery("ws-2", "errors"); err != nil {
+		t.Fatal(err)
+	}
+	if got := GetSavedQueries("ws-2"); len(got) != 0 {
+		t.Errorf("GetSavedQueries(ws-2) after delete = %+v", got)
+	}
+
+	for i := 0; i < maxQueryHistory+5; i++ {
+		if err := AddQueryHistory(fmt.Sprintf("query %d", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := AddQueryHistory("query 10"); err != nil {
+		t.Fatal(err)
+	}
+	history := GetQueryHistory()
+	if len(history) != maxQueryHistory || history[0] != "query 5" || history[len(history)-1] != "query 10" {
+		t.Errorf("GetQueryHistory() = %d queries from %q to %q, want the last %d with the rerun query moved to the end",
+			len(history), history[0], history[len(history)-1], maxQueryHistory)
+	}
+}
+
+func TestDiagnosticsConfig(t *testing.T) {
+	useConfig(t, "")
+	diagnostics := GetDiagnosticsConfig()
+	if diagnostics.SettingName != "central-workspace" || !*diagnostics.Logs || !*diagnostics.Metrics || diagnostics.Workspace != "" {
+		t.Errorf("GetDiagnosticsConfig() defaults = %+v", diagnostics)
+	}
+
+	if err := SaveDiagnosticsWorkspace("/subscriptions/sub-1/workspaces/log"); err != nil {
+		t.Fatal(err)
+	}
+	if got := GetDiagnosticsConfig().Workspace; got != "/subscriptions/sub-1/workspaces/log" {
+		t.Errorf("diagnostics workspace = %q", got)
+	}
+}
+
+func TestUpdateKeepsOtherSettings(t *testing.T) {
+	path := useConfig(t, "ai:\n  provider: openai\n  model: gpt-4\nui:\n  enable_mouse_support: false\n  color_scheme: nord\nkeymap:\n  preset: vim\n")
+	if err := SaveOpenTabs([]OpenTab{{View: "details"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := readConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.AI.Model != "gpt-4" || cfg.Keymap.Preset != "vim" || cfg.UI.ColorScheme != "nord" || *cfg.UI.EnableMouseSupport {
+		t.Errorf("saving tabs changed other settings: %+v", cfg)
+	}
+	if cfg.SavedSearches != nil {
+		t.Error("saving tabs should not configure saved searches")
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("config file mode = %v, %v, want it kept", info.Mode().Perm(), err)
+	}
+}
+
+func TestCorruptConfigIsNotOverwritten(t *testing.T) {
+	const corrupt = "ai:\n  provider: openai\n  model: [unterminated\n"
+	path := useConfig(t, corrupt)
+
+	for name, save := range map[string]func() error{
+		"SaveSearch":               func() error { return SaveSearch("Web", "rg:web") },
+		"DeleteSavedSearch":        func() error { return DeleteSavedSearch("Web") },
+		"AddBookmark":              func() error { return AddBookmark(Bookmark{SubscriptionID: "s", ResourceID: "/subscriptions/s"}) },
+		"SaveQuery":                func() error { return SaveQuery("ws", "q", "T") },
+		"AddQueryHistory":          func() error { return AddQueryHistory("T") },
+		"SaveOpenTabs":             func() error { return SaveOpenTabs(nil) },
+		"SaveLayout":               func() error { return SaveLayout(LayoutConfig{}) },
+		"SaveColorScheme":          func() error { return SaveColorScheme("nord") },
+		"SaveDiagnosticsWorkspace": func() error { return SaveDiagnosticsWorkspace("ws") },
+	} {
+		if err := save(); err == nil {
+			t.Errorf("%s() with a corrupt config should fail", name)
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != corrupt {
+		t.Errorf("corrupt config was overwritten with %q (%v)", data, err)
+	}
+
+	// An empty file is an empty configuration, not an error
+	path = useConfig(t, "")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveOpenTabs([]OpenTab{{View: "details"}}); err != nil {
+		t.Errorf("SaveOpenTabs() with an empty config = %v", err)
+	}
+}
+
+func TestConcurrentUpdates(t *testing.T) {
+	useConfig(t, "")
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := SaveQuery("ws", fmt.Sprintf("query %d", i), "T"); err != nil {
+				t.Error(err)
+			}
+			GetSavedSearches()
+		}(i)
+	}
+	wg.Wait()
+	if got := GetSavedQueries("ws"); len(got) != 20 {
+		t.Errorf("GetSavedQueries() = %d queries after 20 concurrent saves", len(got))
+	}
+}
@@ -0,0 +1,258 @@
+// Package jobs runs long operations in the background. Each job has a
+// status, the output it has written so far and its elapsed time, and can be
+// cancelled while it runs.
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// Status is the state of a job
+type Status string
+
+const (
+	Running   Status = "running"
+	Succeeded Status = "succeeded"
+	Failed    Status = "failed"
+	Cancelled Status = "cancelled"
+)
+
+// maxOutputLines is the number of output lines kept per job
+const maxOutputLines = 500
+
+// Func is the work of a job. It writes its output to out as it goes, stops
+// when ctx is cancelled and returns a one-line summary.
+type Func func(ctx context.Context, out io.Writer) (string, error)
+
+// Job is a snapshot of a job
+type Job struct {
+	ID       int
+	Title    string
+	Status   Status
+	Message  string // Summary, or the error of a failed job
+	Output   []string
+	Started  time.Time
+	Finished time.Time // Zero while running
+}
+
+// Elapsed is how long the job ran, or has been running at now
+func (j Job) Elapsed(now time.Time) time.Duration {
+	if j.Finished.IsZero() {
+		return now.Sub(j.Started)
+	}
+	return j.Finished.Sub(j.Started)
+}
+
+// Done reports whether the job has finished
+func (j Job) Done() bool {
+	return j.Status != Running
+}
+
+// job is a job and what is needed to update it while it runs
+type job struct {
+	Job
+	cancel  context.CancelFunc
+	partial []byte // Output after the last newline
+}
+
+// Manager starts jobs and keeps track of them
+type Manager struct {
+	Now func() time.Time // Clock, replaced in tests
+
+	mu       sync.Mutex
+	jobs     []*job
+	nextID   int
+	finished []Job // Finished since the last TakeFinished
+	changed  chan struct{}
+}
+
+// NewManager creates a manager without jobs
+func NewManager() *Manager {
+	return &Manager{Now: time.Now, nextID: 1, changed: make(chan struct{}, 1)}
+}
+
+// Start runs fn in the background as a job and returns its ID
+func (m *Manager) Start(title string, fn Func) int {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	m.mu.Lock()
+	j := &job{Job: Job{ID: m.nextID, Title: title, Status: Running, Started: m.Now()}, cancel: cancel}
+	m.nextID++
+	m.jobs = append(m.jobs, j)
+	m.mu.Unlock()
+	m.notify()
+
+	go func() {
+		message, err := fn(ctx, writer{m, j})
+		m.finish(j, ctx, message, err)
+		cancel()
+	}()
+	return j.ID
+}
+
+// finish records the result of a job
+func (m *Manager) finish(j *job, ctx context.Context, message string, err error) {
+	m.mu.Lock()
+	if len(j.partial) > 0 {
+		j.appendLine(string(j.partial))
+		j.partial = nil
+	}
+	j.Finished = m.Now()
+	switch {
+	case err != nil && (ctx.Err() != nil || errors.Is(err, context.Canceled)):
+		// Work that returned before noticing the cancellation keeps its result
+		j.Status = Cancelled
+		j.Message = "Cancelled"
+	case err != nil:
+		j.Status = Failed
+		j.Message = err.Error()
+	default:
+		j.Status = Succeeded
+		j.Message = message
+	}
+	m.finished = append(m.finished, j.snapshot())
+	m.mu.Unlock()
+	m.notify()
+}
+
+// Cancel stops a running job. The job is marked cancelled once its work
+// has returned with an error; work that completes anyway succeeds.
+func (m *Manager) Cancel(id int) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, j := range m.jobs {
+		if j.ID == id && j.Status == Running {
+			j.cancel()
+			return true
+		}
+	}
+	return false
+}
+
+// Jobs returns all jobs in the order they were started
+func (m *Manager) Jobs() []Job {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	jobs := make([]Job, len(m.jobs))
+	for i, j := range m.jobs {
+		jobs[i] = j.snapshot()
+	}
+	return jobs
+}
+
+// Running returns the number of running jobs
+func (m *Manager) Running() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	running := 0
+	for _, j := range m.jobs {
+		if j.Status == Running {
+			running++
+		}
+	}
+	return running
+}
+
+// ClearFinished forgets the jobs that have finished
+func (m *Manager) ClearFinished() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	running := m.jobs[:0]
+	for _, j := range m.jobs {
+		if j.Status == Running {
+			running = append(running, j)
+		}
+	}
+	m.jobs = running
+}
+
+// Changed returns a channel that receives when a job starts, writes output
+// or finishes. Changes that come in quick succession are merged.
+func (m *Manager) Changed() <-chan struct{} {
+	return m.changed
+}
+
+// TakeFinished returns the jobs that finished since the last call
+func (m *Manager) TakeFinished() []Job {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	finished := m.finished
+	m.finished = nil
+	return finished
+}
+
+func (m *Manager) notify() {
+	select {
+	case m.changed <- struct{}{}:
+	default: // A change is already pending
+	}
+}
+
+func (j *job) snapshot() Job {
+	snapshot := j.Job
+	snapshot.Output = append([]string(nil), j.Output...)
+	return snapshot
+}
+
+func (j *job) appendLine(line string) {
+	j.Output = append(j.Output, line)
+	if len(j.Output) > maxOutputLines {
+		j.Output = j.Output[len(j.Output)-maxOutputLines:]
+	}
+}
+
+// writer splits the output of a job into lines
+type writer struct {
+	m *Manager
+	j *job
+}
+
+func (w writer) Write(p []byte) (int, error) {
+	w.m.mu.Lock()
+	data := append(w.j.partial, p...)
+	for {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			break
+		}
+		w.j.appendLine(string(bytes.TrimRight(data[:i], "\r")))
+		data = data[i+1:]
+	}
+	w.j.partial = append([]byte(nil), data...)
+	w.m.mu.Unlock()
+	w.m.notify()
+	return len(p), nil
+}
+
+// RunCommand runs a command, copying its output to out while it runs when
+// out isn't nil, and returns the complete output. The command is killed
+// when ctx is cancelled.
+func RunCommand(ctx context.Context, out io.Writer, dir, name string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = dir
+	return Run(ctx, out, cmd)
+}
+
+// Run is RunCommand for a command that has been set up already. cmd should
+// have been created with ctx.
+func Run(ctx context.Context, out io.Writer, cmd *exec.Cmd) (string, error) {
+	var buf bytes.Buffer
+	var w io.Writer = &buf
+	if out != nil {
+		w = io.MultiWriter(&buf, out)
+	}
+
+	cmd.Stdout = w
+	cmd.Stderr = w
+	err := cmd.Run()
+	if ctx.Err() != nil {
+		return buf.String(), ctx.Err()
+	}
+	return buf.String(), err
+}
@@ -0,0 +1,129 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+)
+
+// waitFinished waits for the next job to finish
+func waitFinished(t *testing.T, m *Manager) Job {
+	t.Helper()
+	deadline := time.After(5 * time.Second)
+	for {
+		if finished := m.TakeFinished(); len(finished) > 0 {
+			return finished[0]
+		}
+		select {
+		case <-m.Changed():
+		case <-deadline:
+			t.Fatal("Timed out waiting for the job to finish")
+		}
+	}
+}
+
+func TestJobOutputAndResult(t *testing.T) {
+	m := NewManager()
+	id := m.Start("deploy", func(ctx context.Context, out io.Writer) (string, error) {
+		fmt.Fprint(out, "step 1\r\nstep")
+		fmt.Fprint(out, " 2\nlast line without newline")
+		return "deployed", nil
+	})
+
+	job := waitFinished(t, m)
+	if job.ID != id || job.Status != Succeeded || job.Message != "deployed" {
+		t.Errorf("Expected job %d to succeed with its summary, got %+v", id, job)
+	}
+	want := []string{"step 1", "step 2", "last line without newline"}
+	if fmt.Sprint(job.Output) != fmt.Sprint(want) {
+		t.Errorf("Expected output %q, got %q", want, job.Output)
+	}
+	if m.Running() != 0 || len(m.TakeFinished()) != 0 {
+		t.Error("Expected no running jobs and the finished job to be taken once")
+	}
+}
+
+func TestJobFailure(t *testing.T) {
+	m := NewManager()
+	m.Start("apply", func(ctx context.Context, out io.Writer) (string, error) {
+		return "", errors.New("quota exceeded")
+	})
+
+	if job := waitFinished(t, m); job.Status != Failed || job.Message != "quota exceeded" {
+		t.Errorf("Expected the job to fail with its error, got %+v", job)
+	}
+}
+
+func TestJobCancel(t *testing.T) {
+	m := NewManager()
+	started := make(chan struct{})
+	id := m.Start("upload", func(ctx context.Context, out io.Writer) (string, error) {
+		close(started)
+		<-ctx.Done()
+		return "", ctx.Err()
+	})
+
+	<-started
+	if m.Running() != 1 || m.Jobs()[0].Done() {
+		t.Fatal("Expected the job to be running")
+	}
+	if !m.Cancel(id) {
+		t.Fatal("Expected a running job to be cancelled")
+	}
+	if job := waitFinished(t, m); job.Status != Cancelled {
+		t.Errorf("Expected the job to be cancelled, got %+v", job)
+	}
+	if m.Cancel(id) {
+		t.Error("Expected a finished job not to be cancelled again")
+	}
+
+	m.ClearFinished()
+	if len(m.Jobs()) != 0 {
+		t.Error("Expected finished jobs to be cleared")
+	}
+}
+
+func TestJobCancelAfterWorkReturned(t *testing.T) {
+	m := NewManager()
+	started, release := make(chan struct{}), make(chan struct{})
+	id := m.Start("restart", func(ctx context.Context, out io.Writer) (string, error) {
+		close(started)
+		<-release // Done before it looks at ctx
+		return "restarted", nil
+	})
+
+	<-started
+	m.Cancel(id)
+	close(release)
+	if job := waitFinished(t, m); job.Status != Succeeded || job.Message != "restarted" {
+		t.Errorf("Expected work that completed to succeed despite the cancel, got %+v", job)
+	}
+}
+
+func TestJobElapsed(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	job := Job{Started: start}
+	if got := job.Elapsed(start.Add(3 * time.Second)); got != 3*time.Second {
+		t.Errorf("Expected a running job to count up to now, got %v", got)
+	}
+	job.Finished = start.Add(5 * time.Second)
+	if got := job.Elapsed(start.Add(time.Hour)); got != 5*time.Second {
+		t.Errorf("Expected a finished job to keep its duration, got %v", got)
+	}
+}
+
+func TestRunCommand(t *testing.T) {
+	m := NewManager()
+	m.Start("echo", func(ctx context.Context, out io.Writer) (string, error) {
+		output, err := RunCommand(ctx, out, "", "sh", "-c", "echo out; echo err >&2")
+		return output, err
+	})
+
+	job := waitFinished(t, m)
+	if job.Status != Succeeded || len(job.Output) != 2 {
+		t.Errorf("Expected both output streams in the job, got %+v", job)
+	}
+}
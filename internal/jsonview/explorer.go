@@ -0,0 +1,317 @@
+package jsonview
+
+import (
+	"strings"
+)
+
+// Explorer is the state of a collapsible view of a JSON document: which
+// nodes are expanded, the selected row, the filter and the search.
+type Explorer struct {
+	doc      *Node
+	roots    []*Node // The document, or the outputs of the filter
+	expanded map[*Node]bool
+	rows     []Row
+	cursor   int
+	offset   int // First row shown
+
+	filter    string
+	filterErr error
+
+	search  string
+	matches []*Node // In document order
+	matched map[*Node]bool
+	match   int
+}
+
+// Row is a visible line of the tree
+type Row struct {
+	Node  *Node
+	Depth int
+}
+
+// NewExplorer parses data and shows it with the top level expanded
+func NewExplorer(data []byte) (*Explorer, error) {
+	doc, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	e := &Explorer{doc: doc, roots: []*Node{doc}, expanded: map[*Node]bool{doc: true}}
+	e.rebuild()
+	return e, nil
+}
+
+// rebuild flattens the expanded part of the tree into rows
+func (e *Explorer) rebuild() {
+	e.rows = e.rows[:0]
+	var walk func(n *Node, depth int)
+	walk = func(n *Node, depth int) {
+		e.rows = append(e.rows, Row{Node: n, Depth: depth})
+		if e.expanded[n] {
+			for _, child := range n.Children {
+				walk(child, depth+1)
+			}
+		}
+	}
+	for _, root := range e.roots {
+		walk(root, 0)
+	}
+	e.cursor = max(0, min(e.cursor, len(e.rows)-1))
+}
+
+// Rows returns the visible rows
+func (e *Explorer) Rows() []Row {
+	return e.rows
+}
+
+// Cursor returns the index of the selected row
+func (e *Explorer) Cursor() int {
+	return e.cursor
+}
+
+// Selected returns the node of the selected row
+func (e *Explorer) Selected() *Node {
+	if len(e.rows) == 0 {
+		return nil
+	}
+	return e.rows[e.cursor].Node
+}
+
+// Expanded reports whether node shows its children
+func (e *Explorer) Expanded(node *Node) bool {
+	return e.expanded[node]
+}
+
+// Path returns the path of node in the document, or "" for values a
+// filter computed, like the output of keys
+func (e *Explorer) Path(node *Node) string {
+	if node == nil || !e.doc.contains(node) {
+		return ""
+	}
+	return node.Path()
+}
+
+// Move moves the selection by delta rows
+func (e *Explorer) Move(delta int) {
+	e.cursor = max(0, min(e.cursor+delta, len(e.rows)-1))
+}
+
+// Home and End select the first and the last row
+func (e *Explorer) Home() { e.cursor = 0 }
+func (e *Explorer) End()  { e.cursor = max(0, len(e.rows)-1) }
+
+// Window returns the first row to show so that the selection is visible
+// in height rows, scrolling as little as possible
+func (e *Explorer) Window(height int) int {
+	height = max(1, height)
+	if e.cursor < e.offset {
+		e.offset = e.cursor
+	} else if e.cursor >= e.offset+height {
+		e.offset = e.cursor - height + 1
+	}
+	e.offset = max(0, min(e.offset, len(e.rows)-height))
+	return e.offset
+}
+
+// Toggle expands or collapses the selected node
+func (e *Explorer) Toggle() {
+	if node := e.Selected(); node != nil && len(node.Children) > 0 {
+		e.expanded[node] = !e.expanded[node]
+		e.rebuild()
+	}
+}
+
+// Expand expands the selected node, or moves into it when it already is
+func (e *Explorer) Expand() {
+	node := e.Selected()
+	switch {
+	case node == nil || len(node.Children) == 0:
+	case !e.expanded[node]:
+		e.expanded[node] = true
+		e.rebuild()
+	default:
+		e.Move(1)
+	}
+}
+
+// Collapse collapses the selected node, or selects its parent when it has
+// nothing to collapse
+func (e *Explorer) Collapse() {
+	node := e.Selected()
+	if node == nil {
+		return
+	}
+	if e.expanded[node] && len(node.Children) > 0 {
+		e.expanded[node] = false
+		e.rebuild()
+		return
+	}
+	for i := e.cursor - 1; i >= 0; i-- {
+		if e.rows[i].Node == node.Parent {
+			e.cursor = i
+			return
+		}
+	}
+}
+
+// ExpandAll expands every node below the roots
+func (e *Explorer) ExpandAll() {
+	var walk func(n *Node)
+	walk = func(n *Node) {
+		if len(n.Children) > 0 {
+			e.expanded[n] = true
+		}
+		for _, child := range n.Children {
+			walk(child)
+		}
+	}
+	for _, root := range e.roots {
+		walk(root)
+	}
+	e.rebuild()
+}
+
+// CollapseAll collapses everything but the roots
+func (e *Explorer) CollapseAll() {
+	selected := e.Selected()
+	e.expanded = map[*Node]bool{}
+	for _, root := range e.roots {
+		e.expanded[root] = true
+	}
+	e.rebuild()
+	e.selectVisible(selected)
+}
+
+// selectVisible selects node, or its closest visible ancestor
+func (e *Explorer) selectVisible(node *Node) {
+	for ; node != nil; node = node.Parent {
+		for i, row := range e.rows {
+			if row.Node == node {
+				e.cursor = i
+				return
+			}
+		}
+	}
+	e.cursor = 0
+}
+
+// Filter returns the current filter, "" when the whole document is shown
+func (e *Explorer) Filter() string {
+	return e.filter
+}
+
+// FilterError returns why the last filter failed, or nil
+func (e *Explorer) FilterError() error {
+	return e.filterErr
+}
+
+// SetFilter shows the outputs of a jq-style filter instead of the whole
+// document. An empty filter shows the document again. A filter that fails
+// leaves the view as it was.
+func (e *Explorer) SetFilter(filter string) error {
+	filter = strings.TrimSpace(filter)
+	roots := []*Node{e.doc}
+	if filter != "" && filter != "." {
+		results, err := Query(e.doc, filter)
+		if err != nil {
+			e.filterErr = err
+			return err
+		}
+		roots = results
+	}
+
+	e.filter, e.filterErr = filter, nil
+	e.roots = roots
+	for _, root := range roots {
+		e.expanded[root] = true
+	}
+	e.cursor, e.offset = 0, 0
+	e.rebuild()
+	if e.search != "" {
+		e.Search(e.search)
+	}
+	return nil
+}
+
+// Search finds the nodes whose key or value contains term, ignoring case,
+// expands their parents and selects the first one at or after the
+// selection. It returns the number of matches.
+func (e *Explorer) Search(term string) int {
+	e.search = term
+	e.matches = nil
+	e.matched = map[*Node]bool{}
+	e.match = 0
+	if term == "" {
+		return 0
+	}
+
+	term = strings.ToLower(term)
+	selected := e.Selected()
+	first := -1
+	passedSelection := false
+	var walk func(n *Node)
+	walk = func(n *Node) {
+		if n == selected {
+			passedSelection = true
+		}
+		if strings.Contains(strings.ToLower(n.Key), term) ||
+			(len(n.Children) == 0 && strings.Contains(strings.ToLower(n.Value), term)) {
+			if passedSelection && first < 0 {
+				first = len(e.matches)
+			}
+			e.matches = append(e.matches, n)
+			e.matched[n] = true
+		}
+		for _, child := range n.Children {
+			walk(child)
+		}
+	}
+	for _, root := range e.roots {
+		walk(root)
+	}
+	if len(e.matches) == 0 {
+		return 0
+	}
+
+	e.match = max(0, first)
+	e.showMatch()
+	return len(e.matches)
+}
+
+// NextMatch selects the next match, or the previous one for a negative
+// delta, wrapping around
+func (e *Explorer) NextMatch(delta int) {
+	if len(e.matches) == 0 {
+		return
+	}
+	e.match = (e.match + delta%len(e.matches) + len(e.matches)) % len(e.matches)
+	e.showMatch()
+}
+
+// showMatch expands the parents of the current match and selects it
+func (e *Explorer) showMatch() {
+	target := e.matches[e.match]
+	for node := target.Parent; node != nil; node = node.Parent {
+		e.expanded[node] = true
+	}
+	e.rebuild()
+	e.selectVisible(target)
+}
+
+// SearchTerm returns the current search term
+func (e *Explorer) SearchTerm() string {
+	return e.search
+}
+
+// Matches returns the number of matches and the 1-based index of the
+// current one, 0 when there are none
+func (e *Explorer) Matches() (current, total int) {
+	if len(e.matches) == 0 {
+		return 0, 0
+	}
+	return e.match + 1, len(e.matches)
+}
+
+// IsMatch reports whether node matches the search
+func (e *Explorer) IsMatch(node *Node) bool {
+	return e.matched[node]
+}
@@ -0,0 +1,202 @@
+package jsonview
+
+import (
+	"strings"
+	"testing"
+)
+
+const vm = `{
+  "name": "vm-web-01",
+  "location": "westeurope",
+  "tags": {"cost-center": "42", "env": "prod"},
+  "properties": {
+    "provisioningState": "Succeeded",
+    "hardwareProfile": {"vmSize": "Standard_D2s_v3"},
+    "networkProfile": {
+      "networkInterfaces": [
+        {"id": "/nic-1", "primary": true},
+        {"id": "/nic-2", "primary": false}
+      ]
+    },
+    "diskSizeGB": 128,
+    "extended": null
+  }
+}`
+
+func parse(t *testing.T) *Node {
+	t.Helper()
+	root, err := Parse([]byte(vm))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	return root
+}
+
+func TestParseKeepsDocumentOrder(t *testing.T) {
+	root := parse(t)
+	var keys []string
+	for _, child := range root.Children {
+		keys = append(keys, child.Key)
+	}
+	if got := strings.Join(keys, ","); got != "name,location,tags,properties" {
+		t.Errorf("keys = %s", got)
+	}
+	if got := root.JSON(""); !strings.HasPrefix(got, `{"name":"vm-web-01","location":"westeurope","tags":{"cost-center":"42"`) {
+		t.Errorf("JSON() = %s", got)
+	}
+	if _, err := Parse([]byte(`{"a": 1} {}`)); err == nil {
+		t.Error("Parse() should reject trailing data")
+	}
+}
+
+func TestPath(t *testing.T) {
+	root := parse(t)
+	tests := []struct {
+		filter string
+		path   string
+	}{
+		{".", "."},
+		{".properties.networkProfile.networkInterfaces[1].id", ".properties.networkProfile.networkInterfaces[1].id"},
+		{`.tags["cost-center"]`, `.tags["cost-center"]`},
+		{`.tags."cost-center"`, `.tags["cost-center"]`},
+		{".properties | .hardwareProfile | .vmSize", ".properties.hardwareProfile.vmSize"},
+	}
+	for _, tt := range tests {
+		nodes, err := Query(root, tt.filter)
+		if err != nil || len(nodes) != 1 {
+			t.Fatalf("Query(%q) = %v, %v", tt.filter, nodes, err)
+		}
+		if got := nodes[0].Path(); got != tt.path {
+			t.Errorf("Query(%q).Path() = %q, want %q", tt.filter, got, tt.path)
+		}
+		// Every path is a filter that finds its node again
+		again, err := Query(root, nodes[0].Path())
+		if err != nil || len(again) != 1 || again[0] != nodes[0] {
+			t.Errorf("Query(%q) did not find the node again: %v", nodes[0].Path(), err)
+		}
+	}
+}
+
+func TestQuery(t *testing.T) {
+	root := parse(t)
+	tests := []struct {
+		filter string
+		want   []string
+	}{
+		{".properties.networkProfile.networkInterfaces[].id", []string{"/nic-1", "/nic-2"}},
+		{".properties.networkProfile.networkInterfaces[-1].primary", []string{"false"}},
+		{".tags | keys", []string{`[` + "\n" + `  "cost-center",` + "\n" + `  "env"` + "\n" + `]`}},
+		{".properties | length", []string{"5"}},
+		{".name | length", []string{"9"}},
+		{".missing.deeper", []string{"null"}},
+		{".properties.extended", []string{"null"}},
+		{".properties.diskSizeGB", []string{"128"}},
+	}
+	for _, tt := range tests {
+		nodes, err := Query(root, tt.filter)
+		if err != nil {
+			t.Fatalf("Query(%q) error = %v", tt.filter, err)
+		}
+		var got []string
+		for _, node := range nodes {
+			got = append(got, node.Text())
+		}
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("Query(%q) = %q, want %q", tt.filter, got, tt.want)
+		}
+	}
+
+	for _, filter := range []string{"name", ".name.first", ".name[]", ".tags[0]", `.["open`, ".properties |", "."} {
+		if _, err := Query(root, filter); (err == nil) != (filter == ".") {
+			t.Errorf("Query(%q) error = %v", filter, err)
+		}
+	}
+}
+
+func TestExplorerExpandCollapse(t *testing.T) {
+	e, err := NewExplorer([]byte(vm))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(e.Rows()); got != 5 {
+		t.Fatalf("rows = %d, want the root and its 4 keys", got)
+	}
+
+	e.Move(4) // properties
+	e.Expand()
+	if got := len(e.Rows()); got != 10 {
+		t.Errorf("rows after expanding properties = %d, want 10", got)
+	}
+	e.Move(1) // provisioningState
+	e.Collapse()
+	if got := e.Selected().Key; got != "properties" {
+		t.Errorf("Collapse() on a leaf should select the parent, got %q", got)
+	}
+	e.Collapse()
+	if got := len(e.Rows()); got != 5 {
+		t.Errorf("rows after collapsing = %d, want 5", got)
+	}
+
+	e.ExpandAll()
+	if got := len(e.Rows()); got != 20 {
+		t.Errorf("rows after ExpandAll = %d, want 20", got)
+	}
+	e.CollapseAll()
+	if got := len(e.Rows()); got != 5 {
+		t.Errorf("rows after CollapseAll = %d, want 5", got)
+	}
+}
+
+func TestExplorerSearch(t *testing.T) {
+	e, _ := NewExplorer([]byte(vm))
+	if n := e.Search("NIC"); n != 2 {
+		t.Fatalf("Search() = %d matches, want 2", n)
+	}
+	if got := e.Path(e.Selected()); got != ".properties.networkProfile.networkInterfaces[0].id" {
+		t.Errorf("selected %q", got)
+	}
+	e.NextMatch(1)
+	if got := e.Path(e.Selected()); got != ".properties.networkProfile.networkInterfaces[1].id" {
+		t.Errorf("selected %q after NextMatch", got)
+	}
+	e.NextMatch(1)
+	if current, total := e.Matches(); current != 1 || total != 2 {
+		t.Errorf("Matches() = %d/%d, want to wrap around to 1/2", current, total)
+	}
+	if n := e.Search("nothing like this"); n != 0 {
+		t.Errorf("Search() = %d matches, want 0", n)
+	}
+}
+
+func TestExplorerFilter(t *testing.T) {
+	e, _ := NewExplorer([]byte(vm))
+	if err := e.SetFilter(".properties.networkProfile.networkInterfaces[]"); err != nil {
+		t.Fatal(err)
+	}
+	rows := e.Rows()
+	if len(rows) != 6 {
+		t.Fatalf("rows = %d, want 2 expanded interfaces", len(rows))
+	}
+	if got := e.Path(rows[0].Node); got != ".properties.networkProfile.networkInterfaces[0]" {
+		t.Errorf("results keep their path, got %q", got)
+	}
+
+	if err := e.SetFilter(".name[0]"); err == nil {
+		t.Error("SetFilter() should fail on a string index")
+	}
+	if e.Filter() != ".properties.networkProfile.networkInterfaces[]" || len(e.Rows()) != 6 {
+		t.Error("a failed filter should leave the view as it was")
+	}
+
+	if err := e.SetFilter(".tags | keys"); err != nil {
+		t.Fatal(err)
+	}
+	if got := e.Path(e.Selected()); got != "" {
+		t.Errorf("computed values have no path, got %q", got)
+	}
+
+	_ = e.SetFilter("")
+	if len(e.Rows()) != 5 {
+		t.Errorf("an empty filter shows the document again, rows = %d", len(e.Rows()))
+	}
+}
@@ -0,0 +1,250 @@
+// Package jsonview explores a JSON document as a collapsible tree. Keys
+// keep the order of the document, every node knows its jq-style path, and
+// the tree can be narrowed with a jq-style filter and searched.
+package jsonview
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Kind is the JSON type of a node
+type Kind int
+
+const (
+	Object Kind = iota
+	Array
+	String
+	Number
+	Bool
+	Null
+)
+
+// Node is a value in a JSON document
+type Node struct {
+	Key      string // Key in the parent object
+	Index    int    // Index in the parent array, -1 otherwise
+	Kind     Kind
+	Value    string // Scalars as they appear in JSON, e.g. "\"eastus\"" or "42"
+	Children []*Node
+	Parent   *Node
+}
+
+// Parse reads a JSON document into a tree
+func Parse(data []byte) (*Node, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	root, err := parseValue(dec, nil, "", -1)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("unexpected data after the JSON document")
+	}
+	return root, nil
+}
+
+func parseValue(dec *json.Decoder, parent *Node, key string, index int) (*Node, error) {
+	token, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	node := &Node{Key: key, Index: index, Parent: parent}
+
+	switch t := token.(type) {
+	case json.Delim:
+		switch t {
+		case '{':
+			node.Kind = Object
+			for dec.More() {
+				keyToken, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				child, err := parseValue(dec, node, keyToken.(string), -1)
+				if err != nil {
+					return nil, err
+				}
+				node.Children = append(node.Children, child)
+			}
+		case '[':
+			node.Kind = Array
+			for i := 0; dec.More(); i++ {
+				child, err := parseValue(dec, node, "", i)
+				if err != nil {
+					return nil, err
+				}
+				node.Children = append(node.Children, child)
+			}
+		default:
+			return nil, fmt.Errorf("unexpected %q", t)
+		}
+		// Closing delimiter
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+	case string:
+		node.Kind = String
+		quoted, _ := json.Marshal(t)
+		node.Value = string(quoted)
+	case json.Number:
+		node.Kind = Number
+		node.Value = t.String()
+	case bool:
+		node.Kind = Bool
+		node.Value = strconv.FormatBool(t)
+	case nil:
+		node.Kind = Null
+		node.Value = "null"
+	}
+	return node, nil
+}
+
+// scalar returns a detached scalar node, used for the results of filters
+func scalar(kind Kind, value string) *Node {
+	return &Node{Index: -1, Kind: kind, Value: value}
+}
+
+// identifier matches keys that need no quoting in a path
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Path returns the jq-style path of the node, e.g.
+// .properties.ipConfigurations[0].name. The root is ".".
+func (n *Node) Path() string {
+	var parts []string
+	for node := n; node.Parent != nil; node = node.Parent {
+		switch {
+		case node.Parent.Kind == Array:
+			parts = append(parts, fmt.Sprintf("[%d]", node.Index))
+		case identifier.MatchString(node.Key):
+			parts = append(parts, "."+node.Key)
+		default:
+			quoted, _ := json.Marshal(node.Key)
+			parts = append(parts, "["+string(quoted)+"]")
+		}
+	}
+	if len(parts) == 0 {
+		return "."
+	}
+
+	var b strings.Builder
+	for i := len(parts) - 1; i >= 0; i-- {
+		if i == len(parts)-1 && strings.HasPrefix(parts[i], "[") {
+			b.WriteByte('.')
+		}
+		b.WriteString(parts[i])
+	}
+	return b.String()
+}
+
+// Label is how the node is named in its parent: its key, or its index
+// in brackets
+func (n *Node) Label() string {
+	if n.Parent != nil && n.Parent.Kind == Array {
+		return fmt.Sprintf("[%d]", n.Index)
+	}
+	return n.Key
+}
+
+// Text returns the value as it is copied: strings without quotes,
+// everything else as indented JSON
+func (n *Node) Text() string {
+	if n.Kind == String {
+		var s string
+		if err := json.Unmarshal([]byte(n.Value), &s); err == nil {
+			return s
+		}
+	}
+	return n.JSON("  ")
+}
+
+// Summary describes a collapsed object or array, e.g. "{3 keys}"
+func (n *Node) Summary() string {
+	switch n.Kind {
+	case Object:
+		if len(n.Children) == 1 {
+			return "{1 key}"
+		}
+		return fmt.Sprintf("{%d keys}", len(n.Children))
+	case Array:
+		if len(n.Children) == 1 {
+			return "[1 item]"
+		}
+		return fmt.Sprintf("[%d items]", len(n.Children))
+	}
+	return n.Value
+}
+
+// JSON encodes the node in document order. An empty indent gives compact
+// JSON.
+func (n *Node) JSON(indent string) string {
+	var b strings.Builder
+	n.encode(&b, indent, "")
+	return b.String()
+}
+
+func (n *Node) encode(b *strings.Builder, indent, prefix string) {
+	if n.Kind != Object && n.Kind != Array {
+		b.WriteString(n.Value)
+		return
+	}
+
+	open, close := "{", "}"
+	if n.Kind == Array {
+		open, close = "[", "]"
+	}
+	b.WriteString(open)
+	if len(n.Children) == 0 {
+		b.WriteString(close)
+		return
+	}
+
+	inner := prefix + indent
+	for i, child := range n.Children {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		if indent != "" {
+			b.WriteString("\n" + inner)
+		}
+		if n.Kind == Object {
+			key, _ := json.Marshal(child.Key)
+			b.Write(key)
+			b.WriteByte(':')
+			if indent != "" {
+				b.WriteByte(' ')
+			}
+		}
+		child.encode(b, indent, inner)
+	}
+	if indent != "" {
+		b.WriteString("\n" + prefix)
+	}
+	b.WriteString(close)
+}
+
+// child returns the child with key, or nil
+func (n *Node) child(key string) *Node {
+	for _, child := range n.Children {
+		if child.Key == key {
+			return child
+		}
+	}
+	return nil
+}
+
+// contains reports whether n is node or one of its ancestors
+func (n *Node) contains(node *Node) bool {
+	for ; node != nil; node = node.Parent {
+		if node == n {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,251 @@
+package jsonview
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Query runs a jq-style filter on root and returns its outputs. The
+// supported subset is paths (.a.b, .["a-b"], .[0], .[-1]), iteration
+// (.[] and .a[]), pipes (|) and the keys and length functions, e.g.
+//
+//	.properties.ipConfigurations[] | .properties.privateIPAddress
+//
+// Results that are part of the document keep their place in it, so their
+// Path is still valid. Missing keys give null, as in jq.
+func Query(root *Node, filter string) ([]*Node, error) {
+	stages, err := parseFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := []*Node{root}
+	for _, stage := range stages {
+		var next []*Node
+		for _, node := range nodes {
+			out, err := stage(node)
+			if err != nil {
+				return nil, err
+			}
+			next = append(next, out...)
+		}
+		nodes = next
+	}
+	return nodes, nil
+}
+
+// step turns one input into its outputs
+type step func(*Node) ([]*Node, error)
+
+// parseFilter splits the filter into steps
+func parseFilter(filter string) ([]step, error) {
+	var steps []step
+	for _, stage := range splitPipes(filter) {
+		stage = strings.TrimSpace(stage)
+		switch stage {
+		case "":
+			return nil, fmt.Errorf("empty filter in %q", filter)
+		case "keys":
+			steps = append(steps, keysOf)
+		case "length":
+			steps = append(steps, lengthOf)
+		default:
+			pathSteps, err := parsePath(stage)
+			if err != nil {
+				return nil, err
+			}
+			steps = append(steps, pathSteps...)
+		}
+	}
+	return steps, nil
+}
+
+// splitPipes splits the filter at the pipes outside of strings
+func splitPipes(filter string) []string {
+	var parts []string
+	start, quoted, escaped := 0, false, false
+	for i, r := range filter {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\' && quoted:
+			escaped = true
+		case r == '"':
+			quoted = !quoted
+		case r == '|' && !quoted:
+			parts = append(parts, filter[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, filter[start:])
+}
+
+// parsePath parses a path such as .a["b-c"][0][] into steps
+func parsePath(path string) ([]step, error) {
+	if !strings.HasPrefix(path, ".") {
+		return nil, fmt.Errorf("%q: expected a path starting with '.', keys or length", path)
+	}
+
+	var steps []step
+	rest := path
+	for rest != "" {
+		switch {
+		case rest == ".":
+			rest = ""
+		case strings.HasPrefix(rest, ".["):
+			rest = rest[1:]
+		case strings.HasPrefix(rest, `."`):
+			key, n, err := readString(rest[1:])
+			if err != nil {
+				return nil, fmt.Errorf("%q: %v", path, err)
+			}
+			steps = append(steps, field(key))
+			rest = rest[1+n:]
+		case strings.HasPrefix(rest, "."):
+			end := 1
+			for end < len(rest) && (rest[end] == '_' || isAlnum(rest[end])) {
+				end++
+			}
+			if end == 1 {
+				return nil, fmt.Errorf("%q: expected a key after '.'", path)
+			}
+			steps = append(steps, field(rest[1:end]))
+			rest = rest[end:]
+		case strings.HasPrefix(rest, "[]"):
+			steps = append(steps, iterate)
+			rest = rest[2:]
+		case strings.HasPrefix(rest, `["`):
+			key, n, err := readString(rest[1:])
+			if err != nil {
+				return nil, fmt.Errorf("%q: %v", path, err)
+			}
+			if !strings.HasPrefix(rest[1+n:], "]") {
+				return nil, fmt.Errorf("%q: expected ']'", path)
+			}
+			steps = append(steps, field(key))
+			rest = rest[2+n:]
+		case strings.HasPrefix(rest, "["):
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("%q: expected ']'", path)
+			}
+			i, err := strconv.Atoi(strings.TrimSpace(rest[1:end]))
+			if err != nil {
+				return nil, fmt.Errorf("%q: %q is not an index", path, rest[1:end])
+			}
+			steps = append(steps, index(i))
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("%q: unexpected %q", path, rest)
+		}
+	}
+	return steps, nil
+}
+
+// readString reads the JSON string at the start of s and returns it with
+// the number of bytes it took
+func readString(s string) (string, int, error) {
+	escaped := false
+	for i := 1; i < len(s); i++ {
+		switch {
+		case escaped:
+			escaped = false
+		case s[i] == '\\':
+			escaped = true
+		case s[i] == '"':
+			var key string
+			if err := json.Unmarshal([]byte(s[:i+1]), &key); err != nil {
+				return "", 0, err
+			}
+			return key, i + 1, nil
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated string")
+}
+
+func isAlnum(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// kindName names kinds in errors, as jq does
+func kindName(kind Kind) string {
+	return [...]string{"object", "array", "string", "number", "boolean", "null"}[kind]
+}
+
+func field(key string) step {
+	return func(n *Node) ([]*Node, error) {
+		switch n.Kind {
+		case Object:
+			if child := n.child(key); child != nil {
+				return []*Node{child}, nil
+			}
+			return []*Node{scalar(Null, "null")}, nil
+		case Null:
+			return []*Node{n}, nil
+		}
+		return nil, fmt.Errorf("cannot index %s with %q", kindName(n.Kind), key)
+	}
+}
+
+func index(i int) step {
+	return func(n *Node) ([]*Node, error) {
+		switch n.Kind {
+		case Array:
+			at := i
+			if at < 0 {
+				at += len(n.Children)
+			}
+			if at >= 0 && at < len(n.Children) {
+				return []*Node{n.Children[at]}, nil
+			}
+			return []*Node{scalar(Null, "null")}, nil
+		case Null:
+			return []*Node{n}, nil
+		}
+		return nil, fmt.Errorf("cannot index %s with number", kindName(n.Kind))
+	}
+}
+
+func iterate(n *Node) ([]*Node, error) {
+	if n.Kind != Object && n.Kind != Array {
+		return nil, fmt.Errorf("cannot iterate over %s", kindName(n.Kind))
+	}
+	return n.Children, nil
+}
+
+func keysOf(n *Node) ([]*Node, error) {
+	if n.Kind != Object && n.Kind != Array {
+		return nil, fmt.Errorf("%s has no keys", kindName(n.Kind))
+	}
+	keys := &Node{Index: -1, Kind: Array}
+	for i, child := range n.Children {
+		key := scalar(Number, strconv.Itoa(i))
+		if n.Kind == Object {
+			quoted, _ := json.Marshal(child.Key)
+			key = scalar(String, string(quoted))
+		}
+		key.Index, key.Parent = i, keys
+		keys.Children = append(keys.Children, key)
+	}
+	return []*Node{keys}, nil
+}
+
+func lengthOf(n *Node) ([]*Node, error) {
+	var length int
+	switch n.Kind {
+	case Object, Array:
+		length = len(n.Children)
+	case String:
+		var s string
+		_ = json.Unmarshal([]byte(n.Value), &s)
+		length = utf8.RuneCountInString(s)
+	case Null:
+		length = 0
+	default:
+		return nil, fmt.Errorf("%s has no length", kindName(n.Kind))
+	}
+	return []*Node{scalar(Number, strconv.Itoa(length))}, nil
+}
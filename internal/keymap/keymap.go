@@ -0,0 +1,568 @@
+package keymap
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/olafkfreund/azure-tui/internal/theme"
+)
+
+// Scope is the input mode a binding applies to. Keys only conflict with
+// other keys of the same scope.
+type Scope string
+
+const (
+	ScopeNormal Scope = "normal"
+	ScopeSearch Scope = "search"
+)
+
+// Action IDs, as used in the keymap section of the config file
+const (
+	// Navigation
+	ActionMoveDown       = "move_down"
+	ActionMoveUp         = "move_up"
+	ActionPanelLeft      = "panel_left"
+	ActionPanelRight     = "panel_right"
+	ActionTogglePanel    = "toggle_panel"
+	ActionSelect         = "select"
+	ActionExpandProperty = "expand_property"
+	ActionRawJSON        = "raw_json"
+	ActionMetrics        = "metrics"
+	ActionAlerts         = "alerts"
+	ActionHealth         = "health"
+	ActionDiagnostics    = "diagnostics"
+	ActionKQL            = "kql_console"
+	ActionScrollDown     = "scroll_down"
+	ActionScrollUp       = "scroll_up"
+	ActionOpenTab        = "open_tab"
+	ActionNextTab        = "next_tab"
+	ActionPrevTab        = "prev_tab"
+	ActionCloseTab       = "close_tab"
+	ActionToggleBookmark = "toggle_bookmark"
+	ActionJumpBookmark   = "jump_bookmark"
+
+	// Layout
+	ActionGrowTree     = "grow_tree"
+	ActionShrinkTree   = "shrink_tree"
+	ActionToggleBottom = "toggle_bottom_panel"
+	ActionBottomView   = "bottom_panel_view"
+	ActionGrowBottom   = "grow_bottom_panel"
+	ActionShrinkBottom = "shrink_bottom_panel"
+	ActionZoom         = "zoom"
+	ActionResetLayout  = "reset_layout"
+
+	// Background jobs
+	ActionJobs      = "jobs"
+	ActionCancelJob = "cancel_job"
+
+	// Search
+	ActionSearch            = "search"
+	ActionDeleteSavedSearch = "delete_saved_search"
+	ActionSearchExit        = "search_exit"
+	ActionSearchNext        = "search_next"
+	ActionSearchPrev        = "search_prev"
+	ActionSearchFacets      = "search_facets"
+	ActionSearchSave        = "search_save"
+	ActionSearchCopy        = "search_copy"
+
+	// Resource actions
+	ActionStart   = "start"
+	ActionStop    = "stop"
+	ActionRestart = "restart"
+	ActionRefresh = "refresh"
+	ActionAnalyze = "analyze"
+
+	// Network management
+	ActionNetworkDashboard   = "network_dashboard"
+	ActionVNetDetails        = "vnet_details"
+	ActionNSGDetails         = "nsg_details"
+	ActionNetworkTopology    = "network_topology"
+	ActionNetworkAI          = "network_ai"
+	ActionCreate             = "create"
+	ActionCreateNSG          = "create_nsg"
+	ActionCreateSubnet       = "create_subnet"
+	ActionCreatePublicIP     = "create_public_ip"
+	ActionCreateLoadBalancer = "create_load_balancer"
+
+	// Integrations
+	ActionTerraformMenu    = "terraform_menu"
+	ActionDevOpsMenu       = "devops_menu"
+	ActionSubscriptionMenu = "subscription_menu"
+	ActionQuotas           = "quotas"
+	ActionSettingsMenu     = "settings_menu"
+
+	// Container instances
+	ActionContainerLogs    = "container_logs"
+	ActionContainerExec    = "container_exec"
+	ActionContainerScale   = "container_scale"
+	ActionContainerDetails = "container_details"
+
+	// SSH & AKS
+	ActionSSH            = "ssh"
+	ActionBastion        = "bastion"
+	ActionAKSPods        = "aks_pods"
+	ActionAKSDeployments = "aks_deployments"
+	ActionAKSNodes       = "aks_nodes"
+	ActionAKSServices    = "aks_services"
+
+	// Key Vault
+	ActionListSecrets  = "keyvault_list_secrets"
+	ActionDeleteSecret = "keyvault_delete_secret"
+
+	// Storage accounts
+	ActionListContainers  = "storage_list_containers"
+	ActionCreateContainer = "storage_create_container"
+	ActionListBlobs       = "storage_list_blobs"
+	ActionUploadBlob      = "storage_upload_blob"
+	ActionDeleteItem      = "storage_delete_item"
+
+	// Interface
+	ActionCommandPalette = "command_palette"
+	ActionCommands       = "equivalent_commands"
+	ActionCopy           = "copy"
+	ActionHelp           = "help"
+	ActionQuit           = "quit"
+	ActionBack           = "back"
+)
+
+// Help categories, in the order they are shown
+const (
+	CategoryNavigation   = "Navigation"
+	CategoryLayout       = "Layout"
+	CategoryJobs         = "Background Jobs"
+	CategorySearch       = "Search"
+	CategoryResource     = "Resource Actions"
+	CategoryNetwork      = "Network Management"
+	CategoryTerraform    = "Terraform Management"
+	CategoryDevOps       = "DevOps Management"
+	CategoryContainer    = "Container Management"
+	CategorySSH          = "SSH & AKS"
+	CategoryKeyVault     = "Key Vault Management"
+	CategoryStorage      = "Storage Management"
+	CategorySubscription = "Subscription Management"
+	CategoryInterface    = "Interface"
+)
+
+// Action is something a key can be bound to
+type Action struct {
+	ID          string
+	Description string
+	Category    string
+	Scope       Scope
+	Keys        []string // Default keys
+}
+
+// registry lists every bindable action. Order matters: it is the order of
+// the help popup, and when two default bindings claim the same key the
+// earlier action keeps it.
+var registry = []Action{
+	{ActionMoveDown, "Navigate down in tree / scroll details", CategoryNavigation, ScopeNormal, []string{"j", "down"}},
+	{ActionMoveUp, "Navigate up in tree / scroll details", CategoryNavigation, ScopeNormal, []string{"k", "up"}},
+	{ActionPanelLeft, "Switch to tree panel", CategoryNavigation, ScopeNormal, []string{"h", "left"}},
+	{ActionPanelRight, "Switch to details panel", CategoryNavigation, ScopeNormal, []string{"l", "right"}},
+	{ActionTogglePanel, "Switch between panels", CategoryNavigation, ScopeNormal, []string{"tab"}},
+	{ActionSelect, "Expand group / open resource in details panel", CategoryNavigation, ScopeNormal, []string{"space", "enter"}},
+	{ActionExpandProperty, "Expand/collapse complex properties", CategoryNavigation, ScopeNormal, []string{"e"}},
+	{ActionRawJSON, "Explore the raw JSON of the resource", CategoryNavigation, ScopeNormal, []string{"i"}},
+	{ActionMetrics, "Metrics explorer: chart any metric of the resource", CategoryNavigation, ScopeNormal, []string{"M"}},
+	{ActionAlerts, "Fired alerts and alert rules of the selected resource or group", CategoryNavigation, ScopeNormal, []string{"!"}},
+	{ActionHealth, "Resource Health of the selected resource and Service Health incidents", CategoryNavigation, ScopeNormal, []string{"H"}},
+	{ActionDiagnostics, "Diagnostic settings audit of the selected group, resource or subscription", CategoryNavigation, ScopeNormal, []string{"P"}},
+	{ActionKQL, "Log Analytics KQL console with saved queries and export", CategoryNavigation, ScopeNormal, []string{"O"}},
+	{ActionScrollDown, "Scroll down in current panel", CategoryNavigation, ScopeNormal, []string{"ctrl+j", "ctrl+down"}},
+	{ActionScrollUp, "Scroll up in current panel", CategoryNavigation, ScopeNormal, []string{"ctrl+k", "ctrl+up"}},
+	{ActionOpenTab, "Open selected resource in a new tab", CategoryNavigation, ScopeNormal, []string{"o"}},
+	{ActionNextTab, "Next tab", CategoryNavigation, ScopeNormal, []string{"]"}},
+	{ActionPrevTab, "Previous tab", CategoryNavigation, ScopeNormal, []string{"["}},
+	{ActionCloseTab, "Close current tab", CategoryNavigation, ScopeNormal, []string{"ctrl+w"}},
+	{ActionToggleBookmark, "Bookmark selected node in Favorites (or remove it)", CategoryNavigation, ScopeNormal, []string{"m"}},
+	{ActionJumpBookmark, "Jump to a bookmark", CategoryNavigation, ScopeNormal, []string{"'"}},
+
+	{ActionGrowTree, "Widen the tree panel", CategoryLayout, ScopeNormal, []string{">"}},
+	{ActionShrinkTree, "Narrow the tree panel", CategoryLayout, ScopeNormal, []string{"<"}},
+	{ActionToggleBottom, "Show/hide the bottom panel (logs, output, AI chat)", CategoryLayout, ScopeNormal, []string{"`"}},
+	{ActionBottomView, "Switch the bottom panel between logs, output and AI chat", CategoryLayout, ScopeNormal, []string{"~"}},
+	{ActionGrowBottom, "Make the bottom panel taller", CategoryLayout, ScopeNormal, []string{"+"}},
+	{ActionShrinkBottom, "Make the bottom panel shorter", CategoryLayout, ScopeNormal, []string{"-"}},
+	{ActionZoom, "Zoom the focused panel to the full screen", CategoryLayout, ScopeNormal, []string{"z"}},
+	{ActionResetLayout, "Reset the layout", CategoryLayout, ScopeNormal, []string{"="}},
+
+	{ActionJobs, "Show background jobs in the bottom panel", CategoryJobs, ScopeNormal, []string{"J"}},
+	{ActionCancelJob, "Cancel the selected job, or the latest running one", CategoryJobs, ScopeNormal, []string{"X"}},
+
+	{ActionSearch, "Enter search mode", CategorySearch, ScopeNormal, []string{"/"}},
+	{ActionDeleteSavedSearch, "Remove selected smart folder", CategorySearch, ScopeNormal, []string{"delete"}},
+	{ActionSearchExit, "Exit search mode", CategorySearch, ScopeSearch, []string{"esc"}},
+	{ActionSearchNext, "Next search result", CategorySearch, ScopeSearch, []string{"down", "ctrl+j"}},
+	{ActionSearchPrev, "Previous search result", CategorySearch, ScopeSearch, []string{"up", "ctrl+k"}},
+	{ActionSearchFacets, "Narrow results by facet (type, location, rg, sub, tag)", CategorySearch, ScopeSearch, []string{"ctrl+f"}},
+	{ActionSearchSave, "Save query as smart folder", CategorySearch, ScopeSearch, []string{"ctrl+s"}},
+	{ActionSearchCopy, "Copy the search results", CategorySearch, ScopeSearch, []string{"ctrl+y"}},
+
+	{ActionStart, "Start resource (VMs, Containers)", CategoryResource, ScopeNormal, []string{"s"}},
+	{ActionStop, "Stop resource (VMs, Containers)", CategoryResource, ScopeNormal, []string{"S"}},
+	{ActionRestart, "Restart resource (VMs, Containers), or refresh", CategoryResource, ScopeNormal, []string{"r"}},
+	{ActionRefresh, "Refresh all data", CategoryResource, ScopeNormal, []string{"R"}},
+	{ActionAnalyze, "AI analysis (attach for containers without AI)", CategoryResource, ScopeNormal, []string{"a"}},
+
+	{ActionNetworkDashboard, "Network Dashboard", CategoryNetwork, ScopeNormal, []string{"N"}},
+	{ActionVNetDetails, "VNet Details (for VNets)", CategoryNetwork, ScopeNormal, []string{"V"}},
+	{ActionNSGDetails, "NSG Details (for NSGs)", CategoryNetwork, ScopeNormal, []string{"G"}},
+	{ActionNetworkTopology, "Network Topology", CategoryNetwork, ScopeNormal, []string{"Z"}},
+	{ActionNetworkAI, "AI Network Analysis", CategoryNetwork, ScopeNormal, []string{"A"}},
+	{ActionCreate, "Create VNet (secret for Key Vaults)", CategoryNetwork, ScopeNormal, []string{"C"}},
+	{ActionCreateNSG, "Create NSG", CategoryNetwork, ScopeNormal, []string{"ctrl+n"}},
+	{ActionCreateSubnet, "Create Subnet", CategoryNetwork, ScopeNormal, []string{"ctrl+s"}},
+	{ActionCreatePublicIP, "Create Public IP", CategoryNetwork, ScopeNormal, []string{"ctrl+p"}},
+	{ActionCreateLoadBalancer, "Create Load Balancer", CategoryNetwork, ScopeNormal, []string{"ctrl+l"}},
+
+	{ActionTerraformMenu, "Open Terraform Manager", CategoryTerraform, ScopeNormal, []string{"ctrl+t"}},
+	{ActionDevOpsMenu, "Open Azure DevOps Manager", CategoryDevOps, ScopeNormal, []string{"ctrl+o"}},
+
+	{ActionContainerLogs, "Get Container Logs", CategoryContainer, ScopeNormal, []string{"L"}},
+	{ActionContainerExec, "Exec into Container", CategoryContainer, ScopeNormal, []string{"E"}},
+	{ActionContainerScale, "Scale Container Resources", CategoryContainer, ScopeNormal, []string{"u"}},
+	{ActionContainerDetails, "Container Instance Details", CategoryContainer, ScopeNormal, []string{"I"}},
+
+	{ActionSSH, "SSH Connect (VMs)", CategorySSH, ScopeNormal, []string{"c"}},
+	{ActionBastion, "Bastion Connect (VMs)", CategorySSH, ScopeNormal, []string{"b"}},
+	{ActionAKSPods, "List Pods (AKS)", CategorySSH, ScopeNormal, []string{"p"}},
+	{ActionAKSDeployments, "List Deployments (AKS)", CategorySSH, ScopeNormal, []string{"y"}},
+	{ActionAKSNodes, "List Nodes (AKS)", CategorySSH, ScopeNormal, []string{"n"}},
+	{ActionAKSServices, "List Services (AKS)", CategorySSH, ScopeNormal, []string{"v"}},
+
+	{ActionListSecrets, "List Secrets", CategoryKeyVault, ScopeNormal, []string{"K"}},
+	{ActionDeleteSecret, "Delete Secret", CategoryKeyVault, ScopeNormal, []string{"ctrl+d"}},
+
+	{ActionListContainers, "List Containers", CategoryStorage, ScopeNormal, []string{"T"}},
+	{ActionCreateContainer, "Create Container", CategoryStorage, ScopeNormal, []string{"t"}},
+	{ActionListBlobs, "List Blobs (when viewing containers)", CategoryStorage, ScopeNormal, []string{"B"}},
+	{ActionUploadBlob, "Upload Blob (when viewing blobs)", CategoryStorage, ScopeNormal, []string{"U"}},
+	{ActionDeleteItem, "Delete Container or Blob", CategoryStorage, ScopeNormal, []string{"ctrl+x"}},
+
+	{ActionSubscriptionMenu, "Open Subscription Manager", CategorySubscription, ScopeNormal, []string{"ctrl+a"}},
+	{ActionQuotas, "Subscription quotas: usage versus limits per region", CategorySubscription, ScopeNormal, []string{"Q"}},
+
+	{ActionCommandPalette, "Command palette: search every action", CategoryInterface, ScopeNormal, []string{":"}},
+	{ActionCommands, "Show the az, PowerShell and REST commands behind actions and views", CategoryInterface, ScopeNormal, []string{"W"}},
+	{ActionCopy, "Copy resource ID, name, portal URL or a property", CategoryInterface, ScopeNormal, []string{"Y"}},
+	{ActionHelp, "Show/hide this help", CategoryInterface, ScopeNormal, []string{"?"}},
+	{ActionSettingsMenu, "Open Settings Manager", CategoryInterface, ScopeNormal, []string{"ctrl+,"}},
+	{ActionBack, "Navigate back / Close dialogs", CategoryInterface, ScopeNormal, []string{"esc"}},
+	{ActionQuit, "Quit application", CategoryInterface, ScopeNormal, []string{"q", "ctrl+c"}},
+}
+
+// presets replace the default keys of some actions
+var presets = map[string]map[string][]string{
+	"default": {},
+	"vim": {
+		ActionScrollDown:   {"ctrl+d", "ctrl+e", "ctrl+j"},
+		ActionScrollUp:     {"ctrl+u", "ctrl+y", "ctrl+k"},
+		ActionDeleteSecret: {"D"},
+		ActionSearchNext:   {"down", "ctrl+j", "ctrl+n"},
+		ActionSearchPrev:   {"up", "ctrl+k", "ctrl+p"},
+	},
+	"emacs": {
+		ActionMoveDown:       {"ctrl+n", "down"},
+		ActionMoveUp:         {"ctrl+p", "up"},
+		ActionPanelLeft:      {"ctrl+b", "left"},
+		ActionPanelRight:     {"ctrl+f", "right"},
+		ActionScrollDown:     {"ctrl+v", "ctrl+down"},
+		ActionScrollUp:       {"alt+v", "ctrl+up"},
+		ActionSearch:         {"ctrl+s", "/"},
+		ActionCommandPalette: {"alt+x", ":"},
+		ActionBack:           {"ctrl+g", "esc"},
+		ActionCreateNSG:      {"alt+n"},
+		ActionCreateSubnet:   {"alt+s"},
+		ActionCreatePublicIP: {"alt+p"},
+		ActionSearchExit:     {"ctrl+g", "esc"},
+		ActionSearchNext:     {"ctrl+n", "down"},
+		ActionSearchPrev:     {"ctrl+p", "up"},
+	},
+}
+
+// Presets returns the names of the built-in presets
+func Presets() []string {
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Actions returns the action registry with default keys
+func Actions() []Action {
+	return slices.Clone(registry)
+}
+
+// Conflict is a key claimed by more than one action. Kept is the action that
+// ended up with the key.
+type Conflict struct {
+	Scope   Scope
+	Key     string
+	Kept    string
+	Dropped string
+}
+
+func (c Conflict) String() string {
+	return fmt.Sprintf("key %q (%s) is bound to both %s and %s, keeping %s", Format(c.Key), c.Scope, c.Kept, c.Dropped, c.Kept)
+}
+
+// Keymap resolves keys to actions
+type Keymap struct {
+	actions  []Action // Registry order, with the active keys
+	bindings map[Scope]map[string]string
+}
+
+// Default returns the keymap with the built-in bindings
+func Default() *Keymap {
+	km, _, _ := Load("", nil)
+	return km
+}
+
+// Load builds a keymap from a preset and per-action overrides. Overrides
+// replace all keys of an action and take precedence over the preset; other
+// conflicting keys are resolved in registry order. Conflicts are reported
+// but the keymap is always usable; an error is only returned for an unknown
+// preset, action or key.
+func Load(preset string, overrides map[string][]string) (*Keymap, []Conflict, error) {
+	if preset == "" {
+		preset = "default"
+	}
+	presetKeys, ok := presets[preset]
+	if !ok {
+		return Default(), nil, fmt.Errorf("unknown keymap preset %q (available: %s)", preset, strings.Join(Presets(), ", "))
+	}
+
+	km := &Keymap{bindings: map[Scope]map[string]string{}}
+	index := map[string]int{}
+	for i, action := range registry {
+		index[action.ID] = i
+		if keys, ok := presetKeys[action.ID]; ok {
+			action.Keys = keys
+		}
+		km.actions = append(km.actions, action)
+	}
+
+	var errs []string
+	for id, keys := range overrides {
+		i, ok := index[id]
+		if !ok {
+			errs = append(errs, fmt.Sprintf("unknown action %q", id))
+			continue
+		}
+		km.actions[i].Keys = keys
+	}
+
+	// Overridden actions claim their keys first, so a user binding wins
+	// over a default one
+	order := make([]int, 0, len(km.actions))
+	for i, action := range km.actions {
+		if _, ok := overrides[action.ID]; ok {
+			order = append(order, i)
+		}
+	}
+	for i, action := range km.actions {
+		if _, ok := overrides[action.ID]; !ok {
+			order = append(order, i)
+		}
+	}
+
+	var conflicts []Conflict
+	for _, i := range order {
+		action := &km.actions[i]
+		scopeBindings := km.bindings[action.Scope]
+		if scopeBindings == nil {
+			scopeBindings = map[string]string{}
+			km.bindings[action.Scope] = scopeBindings
+		}
+
+		var kept []string
+		for _, key := range action.Keys {
+			key, err := Normalize(key)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %v", action.ID, err))
+				continue
+			}
+			if slices.Contains(kept, key) {
+				continue
+			}
+			if owner, taken := scopeBindings[key]; taken {
+				conflicts = append(conflicts, Conflict{Scope: action.Scope, Key: key, Kept: owner, Dropped: action.ID})
+				continue
+			}
+			// Printable keys are typed into the query while searching
+			if action.Scope == ScopeSearch && isPrintable(key) {
+				conflicts = append(conflicts, Conflict{Scope: action.Scope, Key: key, Kept: "text input", Dropped: action.ID})
+				continue
+			}
+			scopeBindings[key] = action.ID
+			kept = append(kept, key)
+		}
+		action.Keys = kept
+	}
+
+	if len(errs) > 0 {
+		slices.Sort(errs)
+		return km, conflicts, fmt.Errorf("invalid keymap: %s", strings.Join(errs, "; "))
+	}
+	return km, conflicts, nil
+}
+
+// Action returns the action bound to key in scope, or "" if there is none
+func (km *Keymap) Action(scope Scope, key string) string {
+	return km.bindings[scope][key]
+}
+
+// Keys returns the keys bound to an action
+func (km *Keymap) Keys(id string) []string {
+	for _, action := range km.actions {
+		if action.ID == id {
+			return action.Keys
+		}
+	}
+	return nil
+}
+
+// Description returns the help text of an action
+func (km *Keymap) Description(id string) string {
+	for _, action := range km.actions {
+		if action.ID == id {
+			return action.Description
+		}
+	}
+	return ""
+}
+
+// KeyHint formats the keys of an action for display, e.g. "j, ↓"
+func (km *Keymap) KeyHint(id string) string {
+	keys := km.Keys(id)
+	formatted := make([]string, len(keys))
+	for i, key := range keys {
+		formatted[i] = Format(key)
+	}
+	return strings.Join(formatted, ", ")
+}
+
+// HelpSection is a help popup category with its bound actions
+type HelpSection struct {
+	Category string
+	Rows     []HelpRow
+}
+
+// HelpRow is a single line of the help popup
+type HelpRow struct {
+	Action      string
+	Keys        string
+	Description string
+}
+
+// Help returns the active bindings grouped by category, in registry order.
+// Actions without any key are left out.
+func (km *Keymap) Help() []HelpSection {
+	var sections []HelpSection
+	for _, action := range km.actions {
+		if len(action.Keys) == 0 {
+			continue
+		}
+		if len(sections) == 0 || sections[len(sections)-1].Category != action.Category {
+			sections = append(sections, HelpSection{Category: action.Category})
+		}
+		section := &sections[len(sections)-1]
+		section.Rows = append(section.Rows, HelpRow{Action: action.ID, Keys: km.KeyHint(action.ID), Description: action.Description})
+	}
+	return sections
+}
+
+// keyNames maps alternative spellings to the names bubbletea reports
+var keyNames = map[string]string{
+	"escape": "esc",
+	"return": "enter",
+	"space":  " ",
+	"del":    "delete",
+}
+
+// Normalize converts a key as written in the config file to the string
+// bubbletea reports for it: "escape" becomes "esc", "space" becomes " " and
+// "shift+k" becomes "K"
+func Normalize(key string) (string, error) {
+	if key == " " {
+		return key, nil
+	}
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return "", fmt.Errorf("empty key")
+	}
+	if strings.Contains(key, " ") {
+		return "", fmt.Errorf("key sequences like %q are not supported", key)
+	}
+	if len(key) == 1 {
+		return key, nil
+	}
+
+	lower := strings.ToLower(key)
+	if name, ok := keyNames[lower]; ok {
+		return name, nil
+	}
+	if letter, ok := strings.CutPrefix(lower, "shift+"); ok && len(letter) == 1 && letter[0] >= 'a' && letter[0] <= 'z' {
+		return strings.ToUpper(letter), nil
+	}
+	return lower, nil
+}
+
+// keyLabels are the display names of special keys
+var keyLabels = map[string]string{
+	" ":      "Space",
+	"up":     "↑",
+	"down":   "↓",
+	"left":   "←",
+	"right":  "→",
+	"esc":    "Esc",
+	"enter":  "Enter",
+	"tab":    "Tab",
+	"delete": "Delete",
+}
+
+// plainKeyLabels replace the arrows of keyLabels in plain mode
+var plainKeyLabels = map[string]string{
+	"up":    "Up",
+	"down":  "Down",
+	"left":  "Left",
+	"right": "Right",
+}
+
+func keyLabel(key string) (string, bool) {
+	if label, ok := plainKeyLabels[key]; ok && theme.Plain() {
+		return label, true
+	}
+	label, ok := keyLabels[key]
+	return label, ok
+}
+
+// Format returns the display name of a normalized key, e.g. "Ctrl+W"
+func Format(key string) string {
+	if label, ok := keyLabel(key); ok {
+		return label
+	}
+	if len(key) == 1 {
+		return key
+	}
+
+	parts := strings.Split(key, "+")
+	for i, part := range parts {
+		if label, ok := keyLabel(part); ok {
+			parts[i] = label
+		} else if len(part) == 1 {
+			parts[i] = strings.ToUpper(part)
+		} else {
+			parts[i] = strings.ToUpper(part[:1]) + part[1:]
+		}
+	}
+	return strings.Join(parts, "+")
+}
+
+func isPrintable(key string) bool {
+	return len(key) == 1 && key >= " " && key <= "~"
+}
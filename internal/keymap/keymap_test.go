@@ -0,0 +1,134 @@
+package keymap
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPresetsHaveNoConflicts(t *testing.T) {
+	for _, preset := range Presets() {
+		km, conflicts, err := Load(preset, nil)
+		if err != nil {
+			t.Errorf("preset %s: %v", preset, err)
+		}
+		for _, conflict := range conflicts {
+			t.Errorf("preset %s: %s", preset, conflict)
+		}
+		for _, action := range Actions() {
+			if len(km.Keys(action.ID)) == 0 {
+				t.Errorf("preset %s: action %s has no key", preset, action.ID)
+			}
+		}
+	}
+}
+
+func TestLoadOverrides(t *testing.T) {
+	km, conflicts, err := Load("default", map[string][]string{
+		ActionStart:        {"x"},
+		ActionDeleteSecret: {"shift+k", "Escape"},
+	})
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if km.Action(ScopeNormal, "x") != ActionStart || km.Action(ScopeNormal, "s") != "" {
+		t.Error("Expected override to replace the default key")
+	}
+
+	// "shift+k" is normalized to "K" and "Escape" to "esc"; the override
+	// takes both away from the default bindings
+	if len(conflicts) != 2 {
+		t.Fatalf("Expected 2 conflicts, got %v", conflicts)
+	}
+	if conflicts[0].Key != "K" || conflicts[0].Kept != ActionDeleteSecret || conflicts[0].Dropped != ActionListSecrets {
+		t.Errorf("Unexpected conflict %v", conflicts[0])
+	}
+	if km.Action(ScopeNormal, "K") != ActionDeleteSecret || km.Action(ScopeNormal, "esc") != ActionDeleteSecret {
+		t.Error("Expected the override to win a conflicting key")
+	}
+	if keys := km.Keys(ActionListSecrets); len(keys) != 0 {
+		t.Errorf("Expected list secrets to lose its only key, got %v", keys)
+	}
+}
+
+func TestLoadOverrideConflicts(t *testing.T) {
+	// Two overrides on the same key: the earlier action keeps it
+	km, conflicts, err := Load("", map[string][]string{ActionStart: {"x"}, ActionStop: {"x"}})
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(conflicts) != 1 || km.Action(ScopeNormal, "x") != ActionStart {
+		t.Errorf("Expected the earlier action to keep the key, got %v", conflicts)
+	}
+}
+
+func TestLoadSearchScope(t *testing.T) {
+	km, conflicts, err := Load("default", map[string][]string{ActionSearchSave: {"w", "ctrl+w"}})
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(conflicts) != 1 || conflicts[0].Kept != "text input" {
+		t.Errorf("Expected a printable key to conflict with search input, got %v", conflicts)
+	}
+
+	// The same key may be bound in different scopes
+	if km.Action(ScopeSearch, "ctrl+w") != ActionSearchSave || km.Action(ScopeNormal, "ctrl+w") != ActionCloseTab {
+		t.Error("Expected scopes to be independent")
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	if _, _, err := Load("nano", nil); err == nil {
+		t.Error("Expected an error for an unknown preset")
+	}
+
+	km, _, err := Load("", map[string][]string{"launch_rockets": {"x"}})
+	if err == nil || !strings.Contains(err.Error(), "launch_rockets") {
+		t.Errorf("Expected an error naming the unknown action, got %v", err)
+	}
+	if km == nil || km.Action(ScopeNormal, "q") != ActionQuit {
+		t.Error("Expected a usable keymap despite the error")
+	}
+}
+
+func TestNormalizeAndFormat(t *testing.T) {
+	tests := []struct {
+		key, normalized, formatted string
+	}{
+		{"shift+t", "T", "T"},
+		{"ESCAPE", "esc", "Esc"},
+		{"space", " ", "Space"},
+		{"Ctrl+W", "ctrl+w", "Ctrl+W"},
+		{"ctrl+down", "ctrl+down", "Ctrl+↓"},
+		{"alt+v", "alt+v", "Alt+V"},
+		{"ctrl+,", "ctrl+,", "Ctrl+,"},
+	}
+	for _, test := range tests {
+		got, err := Normalize(test.key)
+		if err != nil || got != test.normalized {
+			t.Errorf("Normalize(%q) = %q, %v, want %q", test.key, got, err, test.normalized)
+		}
+		if formatted := Format(got); formatted != test.formatted {
+			t.Errorf("Format(%q) = %q, want %q", got, formatted, test.formatted)
+		}
+	}
+
+	if _, err := Normalize("ctrl+x ctrl+c"); err == nil {
+		t.Error("Expected key sequences to be rejected")
+	}
+}
+
+func TestHelp(t *testing.T) {
+	km, _, _ := Load("vim", nil)
+	sections := km.Help()
+	if len(sections) == 0 || sections[0].Category != CategoryNavigation {
+		t.Fatalf("Expected help to start with navigation, got %v", sections)
+	}
+	for _, section := range sections {
+		for _, row := range section.Rows {
+			if row.Action == ActionDeleteSecret && row.Keys != "D" {
+				t.Errorf("Expected help to show the preset key, got %q", row.Keys)
+			}
+		}
+	}
+}
@@ -3,6 +3,7 @@ package openai
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
@@ -247,3 +248,61 @@ func (ai *AIProvider) TroubleshootError(errorMessage, context string) (string, e
 		errorMessage, context)
 	return ai.Ask(prompt, "Azure Troubleshooting")
 }
+
+// translateTimeout bounds how long a search waits for a translated query
+var translateTimeout = 30 * time.Second
+
+// TranslateSearchQuery turns a natural-language question into the search
+// query syntax described by schema. Only the schema and the question are sent
+// to the model, never the resource inventory.
+func (ai *AIProvider) TranslateSearchQuery(question, schema string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), translateTimeout)
+	defer cancel()
+
+	resp, err := ai.Client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
+		Model: ai.getModel(),
+		Messages: []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleSystem, Content: "Translate the user's question into a single search query. Reply with the query only, no explanation.\n\n" + schema},
+			{Role: openai.ChatMessageRoleUser, Content: question},
+		},
+	})
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "", fmt.Errorf("no answer from the AI provider within %s", translateTimeout)
+	}
+	if err != nil {
+		return "", err
+	}
+	if len(resp.Choices) == 0 {
+		return "", fmt.Errorf("no query returned")
+	}
+
+	query := cleanTranslatedQuery(resp.Choices[0].Message.Content)
+	if query == "" {
+		return "", fmt.Errorf("no query returned")
+	}
+	return query, nil
+}
+
+// cleanTranslatedQuery strips code fences, quotes and labels that models tend
+// to wrap around a query and returns its first line
+func cleanTranslatedQuery(reply string) string {
+	for _, line := range strings.Split(reply, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "```") {
+			continue
+		}
+		for _, prefix := range []string{"Query:", "query:"} {
+			line = strings.TrimSpace(strings.TrimPrefix(line, prefix))
+		}
+		line = strings.Trim(line, "`")
+		// Only quotes around the whole query are the model's, quoted filter
+		// values such as rg:"rg-web" belong to it
+		for _, q := range []string{`"`, "'"} {
+			if len(line) >= 2 && strings.HasPrefix(line, q) && strings.HasSuffix(line, q) {
+				line = line[1 : len(line)-1]
+			}
+		}
+		return line
+	}
+	return ""
+}
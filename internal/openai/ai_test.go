@@ -1,8 +1,16 @@
 package openai
 
 import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/olafkfreund/azure-tui/internal/search"
+	openai "github.com/sashabaranov/go-openai"
 )
 
 // TestNewAIProviderAuto tests the AI provider auto-detection functionality
@@ -84,3 +92,97 @@ func TestGetGitHubCopilotToken(t *testing.T) {
 		}
 	})
 }
+
+// newFakeProvider returns an AIProvider backed by a local OpenAI-compatible
+// server that answers every chat completion with reply and records the
+// messages it was sent
+func newFakeProvider(t *testing.T, reply string, sent *[]openai.ChatCompletionMessage) *AIProvider {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req openai.ChatCompletionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("Failed to decode request: %v", err)
+		}
+		*sent = req.Messages
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(openai.ChatCompletionResponse{
+			Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: reply}}},
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	config := openai.DefaultConfig("test-key")
+	config.BaseURL = server.URL + "/v1"
+	return &AIProvider{Client: openai.NewClientWithConfig(config), ProviderType: "openai"}
+}
+
+// TestTranslateSearchQuery tests natural-language search translation
+func TestTranslateSearchQuery(t *testing.T) {
+	var sent []openai.ChatCompletionMessage
+	provider := newFakeProvider(t, "```\ntype:vm location:westeurope NOT tag:owner\n```", &sent)
+
+	question := "which VMs in west europe have no owner tag"
+	query, err := provider.TranslateSearchQuery(question, search.QuerySchema())
+	if err != nil {
+		t.Fatalf("TranslateSearchQuery failed: %v", err)
+	}
+	if query != "type:vm location:westeurope NOT tag:owner" {
+		t.Errorf("Expected code fences to be stripped, got %q", query)
+	}
+
+	// Only the schema and the question may be sent
+	if len(sent) != 2 || !strings.Contains(sent[0].Content, search.QuerySchema()) || sent[1].Content != question {
+		t.Errorf("Expected only the schema and question to be sent, got %v", sent)
+	}
+
+	// The generated query runs locally
+	engine := search.NewSearchEngine()
+	engine.SetResources([]search.Resource{
+		{ID: "1", Name: "vm-owned", Type: "Microsoft.Compute/virtualMachines", Location: "westeurope", Tags: map[string]string{"owner": "alice"}},
+		{ID: "2", Name: "vm-orphan", Type: "Microsoft.Compute/virtualMachines", Location: "westeurope"},
+		{ID: "3", Name: "vm-us", Type: "Microsoft.Compute/virtualMachines", Location: "eastus"},
+	})
+	results, err := engine.Search(query)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 1 || results[0].ResourceID != "2" {
+		t.Errorf("Expected only vm-orphan, got %v", results)
+	}
+}
+
+func TestTranslateSearchQueryTimeout(t *testing.T) {
+	defer func(timeout time.Duration) { translateTimeout = timeout }(translateTimeout)
+	translateTimeout = 50 * time.Millisecond
+
+	stalled := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-stalled // Never answers in time
+	}))
+	t.Cleanup(server.Close)
+	t.Cleanup(func() { close(stalled) })
+	config := openai.DefaultConfig("test-key")
+	config.BaseURL = server.URL + "/v1"
+	provider := &AIProvider{Client: openai.NewClientWithConfig(config), ProviderType: "openai"}
+
+	_, err := provider.TranslateSearchQuery("which VMs have no owner", search.QuerySchema())
+	if err == nil || !strings.Contains(err.Error(), "within 50ms") {
+		t.Errorf("TranslateSearchQuery() against a stalled provider = %v, want a timeout", err)
+	}
+}
+
+func TestCleanTranslatedQuery(t *testing.T) {
+	tests := map[string]string{
+		"type:vm":                       "type:vm",
+		"Query: `tag:none`":             "tag:none",
+		"\n\"rg:prod location:eastus\"": "rg:prod location:eastus",
+		"type:vm rg:\"rg-web\"":         "type:vm rg:\"rg-web\"",
+		"":                              "",
+	}
+	for reply, want := range tests {
+		if got := cleanTranslatedQuery(reply); got != want {
+			t.Errorf("cleanTranslatedQuery(%q) = %q, want %q", reply, got, want)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package search
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+)
+
+// Facet fields, in the order they are presented
+const (
+	FacetType          = "type"
+	FacetLocation      = "location"
+	FacetResourceGroup = "resource_group"
+	FacetSubscription  = "subscription"
+	FacetTag           = "tag"
+)
+
+// Facet is a single value of a facet field and how many results have it
+type Facet struct {
+	Field string
+	Value string
+	Count int
+}
+
+// FacetGroup holds the values of one facet field, most common first
+type FacetGroup struct {
+	Field  string
+	Facets []Facet
+}
+
+// ComputeFacets counts the distinct resources in results per type, location,
+// resource group, subscription and tag value. A resource matching on several
+// fields is only counted once.
+func ComputeFacets(results []SearchResult) []FacetGroup {
+	fields := []string{FacetType, FacetLocation, FacetResourceGroup, FacetSubscription, FacetTag}
+	counts := make(map[string]map[string]int, len(fields))
+	for _, field := range fields {
+		counts[field] = make(map[string]int)
+	}
+
+	seen := make(map[string]bool)
+	for _, result := range results {
+		if seen[result.ResourceID] {
+			continue
+		}
+		seen[result.ResourceID] = true
+
+		if result.ResourceType != "" {
+			counts[FacetType][result.ResourceType]++
+		}
+		if result.Location != "" {
+			counts[FacetLocation][result.Location]++
+		}
+		if result.ResourceGroup != "" {
+			counts[FacetResourceGroup][result.ResourceGroup]++
+		}
+		if subscription := SubscriptionFromID(result.ResourceID); subscription != "" {
+			counts[FacetSubscription][subscription]++
+		}
+		for key, value := range result.Tags {
+			counts[FacetTag][key+"="+value]++
+		}
+	}
+
+	groups := []FacetGroup{}
+	for _, field := range fields {
+		if len(counts[field]) == 0 {
+			continue
+		}
+		group := FacetGroup{Field: field}
+		for value, count := range counts[field] {
+			group.Facets = append(group.Facets, Facet{Field: field, Value: value, Count: count})
+		}
+		slices.SortFunc(group.Facets, func(a, b Facet) int {
+			if a.Count != b.Count {
+				return cmp.Compare(b.Count, a.Count)
+			}
+			return cmp.Compare(a.Value, b.Value)
+		})
+		groups = append(groups, group)
+	}
+
+	return groups
+}
+
+// FacetFilter returns the query filter that narrows results down to facet.
+// The value is quoted so that it only matches exactly, rg:"rg-web" leaving
+// out rg-web-2, and may contain spaces.
+func FacetFilter(facet Facet) string {
+	value := quote(strings.ReplaceAll(facet.Value, `"`, ""))
+
+	switch facet.Field {
+	case FacetType:
+		return "type:" + value
+	case FacetLocation:
+		return "location:" + value
+	case FacetResourceGroup:
+		return "rg:" + value
+	case FacetSubscription:
+		return "sub:" + value
+	case FacetTag:
+		return "tag:" + value
+	}
+	return ""
+}
+
+// SubscriptionFromID extracts the subscription ID from an Azure resource ID
+func SubscriptionFromID(resourceID string) string {
+	parts := strings.Split(resourceID, "/")
+	for i := 0; i+1 < len(parts); i++ {
+		if strings.EqualFold(parts[i], "subscriptions") {
+			return parts[i+1]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,175 @@
+package search
+
+import (
+	"math/bits"
+	"strings"
+
+	"github.com/sahilm/fuzzy"
+)
+
+// MatchSpan marks a highlighted range [Start, End) of byte offsets within SearchResult.MatchValue
+type MatchSpan struct {
+	Start int
+	End   int
+}
+
+// textMatch describes how a single search term matched a piece of text
+type textMatch struct {
+	Term    string
+	Spans   []MatchSpan // byte ranges of matched characters in the original text
+	Fuzzy   bool        // matched as a subsequence rather than a substring
+	Typos   int         // edit distance when matched with typo tolerance
+	Quality int         // character-level score from the fuzzy matcher
+}
+
+// Fuzzy matching tuning
+const (
+	minFuzzyTermLength = 4   // shorter terms match too much as subsequences
+	fuzzyPenalty       = 300 // substring matches always outrank subsequence matches
+	typoPenalty        = 450 // subsequence matches always outrank typo matches
+	perTypoPenalty     = 100 // each additional edit costs extra
+	fuzzySeparators    = "-_./ :"
+)
+
+// fuzzyMatchText matches term against text as a character subsequence (so
+// "pymnts-api" finds "payments-api") and, failing that, with a bounded
+// Damerau-Levenshtein distance against the separator-delimited tokens of text
+// (so "paymnets" finds "payments"). lowerText is text lowercased.
+func fuzzyMatchText(text, lowerText, term string) (textMatch, bool) {
+	if len(term) < minFuzzyTermLength || text == "" {
+		return textMatch{}, false
+	}
+
+	// The subsequence check is cheap; only run the scoring matcher when it can succeed
+	if isSubsequence(lowerText, term) {
+		if matches := fuzzy.Find(term, []string{text}); len(matches) > 0 {
+			return textMatch{
+				Term:    term,
+				Spans:   indexesToSpans(matches[0].MatchedIndexes),
+				Fuzzy:   true,
+				Quality: matches[0].Score,
+			}, true
+		}
+	}
+
+	maxTypos := maxTyposFor(term)
+	if maxTypos == 0 {
+		return textMatch{}, false
+	}
+
+	// The edit distance is at least the length difference and the number of
+	// term characters missing from the token, so skip tokens that can't be
+	// within the typo budget
+	termMask := charMask(term)
+	withinBudget := func(token string) bool {
+		return abs(len(token)-len(term)) <= maxTypos && bits.OnesCount64(termMask&^charMask(token)) <= maxTypos
+	}
+
+	best := textMatch{Typos: maxTypos + 1}
+	start := 0
+	for i := 0; i <= len(lowerText); i++ {
+		if i < len(lowerText) && !strings.ContainsRune(fuzzySeparators, rune(lowerText[i])) {
+			continue
+		}
+		if i > start && withinBudget(lowerText[start:i]) {
+			if d := editDistance(lowerText[start:i], term); d < best.Typos {
+				best = textMatch{Term: term, Spans: []MatchSpan{{Start: start, End: i}}, Fuzzy: true, Typos: d}
+			}
+		}
+		start = i + 1
+	}
+
+	// Also compare against the whole text for terms that span separators
+	if withinBudget(lowerText) {
+		if d := editDistance(lowerText, term); d < best.Typos {
+			best = textMatch{Term: term, Spans: []MatchSpan{{Start: 0, End: len(lowerText)}}, Fuzzy: true, Typos: d}
+		}
+	}
+
+	if best.Typos > maxTypos {
+		return textMatch{}, false
+	}
+	return best, true
+}
+
+// isSubsequence reports whether the bytes of term appear in text in order
+func isSubsequence(text, term string) bool {
+	j := 0
+	for i := 0; i < len(text) && j < len(term); i++ {
+		if text[i] == term[j] {
+			j++
+		}
+	}
+	return j == len(term)
+}
+
+// maxTyposFor returns how many edits are tolerated for a term of a given length
+func maxTyposFor(term string) int {
+	switch {
+	case len(term) >= 8:
+		return 2
+	case len(term) >= 4:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// editDistance computes the optimal string alignment distance (Levenshtein
+// plus adjacent transpositions) between a and b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prevPrev := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				curr[j] = min(curr[j], prevPrev[j-2]+1)
+			}
+		}
+		prevPrev, prev, curr = prev, curr, prevPrev
+	}
+
+	return prev[len(rb)]
+}
+
+// substringSpans returns the span of the first occurrence of term in text,
+// both lowercased
+func substringSpans(textLower, termLower string) []MatchSpan {
+	idx := strings.Index(textLower, termLower)
+	if idx < 0 || termLower == "" {
+		return nil
+	}
+	return []MatchSpan{{Start: idx, End: idx + len(termLower)}}
+}
+
+// indexesToSpans collapses sorted character offsets into contiguous spans
+func indexesToSpans(indexes []int) []MatchSpan {
+	var spans []MatchSpan
+	for _, idx := range indexes {
+		if n := len(spans); n > 0 && spans[n-1].End == idx {
+			spans[n-1].End = idx + 1
+			continue
+		}
+		spans = append(spans, MatchSpan{Start: idx, End: idx + 1})
+	}
+	return spans
+}
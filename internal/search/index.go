@@ -0,0 +1,719 @@
+package search
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"math/bits"
+	"slices"
+	"strings"
+)
+
+// searchIndex is an inverted index over the searchable fields of the
+// resources known to a SearchEngine. Tokens come from the resource name,
+// resource group, location, type segments and tag keys/values, and every
+// token is further indexed by its bigrams and trigrams so that substring
+// terms can be resolved without scanning the whole inventory. Filters are
+// resolved through the distinct values of each filterable field.
+type searchIndex struct {
+	docs     map[string]*indexedDoc                         // document key -> document
+	order    []*indexedDoc                                  // documents in SetResources order
+	postings map[string]map[*indexedDoc]struct{}            // token -> documents
+	grams    map[string]map[string]struct{}                 // bigram/trigram -> tokens
+	values   map[string]map[string]map[*indexedDoc]struct{} // filter field -> lowercased value -> documents
+	fuzzy    map[string]*fuzzyEntry                         // name/resource group values and tokens -> documents
+
+	// The fuzzy vocabulary by text length for typo matches, and the whole
+	// field values for subsequence matches
+	fuzzyByLength map[int]fuzzyBucket
+	fuzzyFullText fuzzyBucket
+
+	generation int // incremented on every update to find stale documents
+}
+
+// fuzzyBucket groups fuzzy vocabulary entries by character mask. The fuzzy
+// pre-screens only look at masks and lengths, so whole groups are accepted
+// or skipped at once.
+type fuzzyBucket map[uint64]map[string]*fuzzyEntry
+
+// fuzzyEntry is a name or resource group value (or one of its tokens) that
+// fuzzy terms are pre-screened against before running the full matcher
+type fuzzyEntry struct {
+	mask     uint64 // characters present in the text
+	fullText bool   // a whole field value, so subsequence matches apply
+	docs     map[*indexedDoc]struct{}
+}
+
+// indexedDoc is a resource together with its pre-computed search data
+type indexedDoc struct {
+	key          string
+	resource     Resource
+	position     int
+	generation   int
+	tokens       []string
+	nameLower    string
+	groupLower   string
+	locLower     string
+	typeLower    string
+	typeShort    string
+	subscription string
+	tags         []docTag // sorted by key
+	fuzzyKeys    []string // entries in the fuzzy vocabulary referencing this document
+}
+
+// docTag is a tag of an indexed resource
+type docTag struct {
+	key, value           string
+	keyLower, valueLower string
+	pair                 string // key=value, as shown in results
+}
+
+// Filter fields of the value index
+const (
+	fieldType          = "type"
+	fieldLocation      = "location"
+	fieldResourceGroup = "resource_group"
+	fieldSubscription  = "subscription"
+	fieldTagKey        = "tag_key"
+	fieldTag           = "tag"      // key and value separated by tagSeparator
+	fieldUntagged      = "untagged" // a single empty value for resources without tags
+	tagSeparator       = "\x00"
+)
+
+// fieldValue is a lowercased value of a filter field
+type fieldValue struct {
+	field string
+	value string
+}
+
+func newSearchIndex() *searchIndex {
+	return &searchIndex{
+		docs:     make(map[string]*indexedDoc),
+		postings: make(map[string]map[*indexedDoc]struct{}),
+		grams:    make(map[string]map[string]struct{}),
+		values:   make(map[string]map[string]map[*indexedDoc]struct{}),
+		fuzzy:    make(map[string]*fuzzyEntry),
+
+		fuzzyByLength: make(map[int]fuzzyBucket),
+		fuzzyFullText: make(fuzzyBucket),
+	}
+}
+
+// newIndexedDoc computes the search data of resource
+func newIndexedDoc(key string, resource Resource) *indexedDoc {
+	doc := &indexedDoc{
+		key:          key,
+		resource:     resource,
+		nameLower:    strings.ToLower(resource.Name),
+		groupLower:   strings.ToLower(resource.ResourceGroup),
+		locLower:     strings.ToLower(resource.Location),
+		typeLower:    strings.ToLower(resource.Type),
+		typeShort:    resourceTypeShortName(resource.Type),
+		subscription: strings.ToLower(SubscriptionFromID(resource.ID)),
+	}
+	for key, value := range resource.Tags {
+		doc.tags = append(doc.tags, docTag{
+			key:        key,
+			value:      value,
+			keyLower:   strings.ToLower(key),
+			valueLower: strings.ToLower(value),
+			pair:       key + "=" + value,
+		})
+	}
+	slices.SortFunc(doc.tags, func(a, b docTag) int { return cmp.Compare(a.key, b.key) })
+	return doc
+}
+
+// fieldValues returns the filter field values of doc
+func (doc *indexedDoc) fieldValues() []fieldValue {
+	values := []fieldValue{
+		{fieldType, doc.typeLower},
+		{fieldLocation, doc.locLower},
+		{fieldResourceGroup, doc.groupLower},
+		{fieldSubscription, doc.subscription},
+	}
+	if len(doc.tags) == 0 {
+		values = append(values, fieldValue{fieldUntagged, ""})
+	}
+	for _, tag := range doc.tags {
+		values = append(values,
+			fieldValue{fieldTagKey, tag.keyLower},
+			fieldValue{fieldTag, tag.keyLower + tagSeparator + tag.valueLower})
+	}
+	return values
+}
+
+// update brings the index in line with resources, re-indexing only the
+// resources that were added, removed or whose indexed fields changed
+func (idx *searchIndex) update(resources []Resource) {
+	idx.generation++
+	order := make([]*indexedDoc, 0, len(resources))
+
+	for i, resource := range resources {
+		key := docKey(resource, i)
+		doc, exists := idx.docs[key]
+		if exists && doc.generation == idx.generation {
+			continue // duplicate entries for the same resource
+		}
+
+		if exists && !sameIndexedFields(doc.resource, resource) {
+			idx.remove(key)
+			exists = false
+		}
+		if exists {
+			doc.resource = resource
+		} else {
+			doc = idx.add(key, resource)
+		}
+		doc.position = len(order)
+		doc.generation = idx.generation
+		order = append(order, doc)
+	}
+
+	for key, doc := range idx.docs {
+		if doc.generation != idx.generation {
+			idx.remove(key)
+		}
+	}
+
+	idx.order = order
+}
+
+// upsert adds or re-indexes resources without touching the rest of the
+// inventory; new resources go to the end of the inventory order. Resources
+// without an ID are skipped: only their position in the full inventory
+// identifies them, so they are indexed by update alone.
+func (idx *searchIndex) upsert(resources []Resource) {
+	for _, resource := range resources {
+		if resource.ID == "" {
+			continue
+		}
+		key := resource.ID
+		doc, exists := idx.docs[key]
+		if exists && sameIndexedFields(doc.resource, resource) {
+			doc.resource = resource
+			continue
+		}
+
+		position := len(idx.order)
+		if exists {
+			position = doc.position
+			idx.remove(key)
+		}
+		doc = idx.add(key, resource)
+		doc.position = position
+		doc.generation = idx.generation
+		if position == len(idx.order) {
+			idx.order = append(idx.order, doc)
+		} else {
+			idx.order[position] = doc
+		}
+	}
+}
+
+// removeKeys drops resources by key and compacts the inventory order
+func (idx *searchIndex) removeKeys(keys []string) {
+	removed := false
+	for _, key := range keys {
+		if _, exists := idx.docs[key]; exists {
+			idx.remove(key)
+			removed = true
+		}
+	}
+	if !removed {
+		return
+	}
+
+	order := idx.order[:0]
+	for _, doc := range idx.order {
+		if idx.docs[doc.key] == doc {
+			doc.position = len(order)
+			order = append(order, doc)
+		}
+	}
+	clear(idx.order[len(order):])
+	idx.order = order
+}
+
+// add indexes a single resource
+func (idx *searchIndex) add(key string, resource Resource) *indexedDoc {
+	doc := newIndexedDoc(key, resource)
+	for _, field := range []string{doc.nameLower, doc.groupLower} {
+		if field == "" {
+			continue
+		}
+		idx.addFuzzyEntry(doc, field, true)
+		for _, token := range tokenize(field) {
+			idx.addFuzzyEntry(doc, token, false)
+		}
+	}
+
+	unique := make(map[string]bool)
+	fields := []string{resource.Name, resource.ResourceGroup, resource.Location, resource.Type}
+	for _, tag := range doc.tags {
+		fields = append(fields, tag.key, tag.value)
+	}
+	for _, field := range fields {
+		for _, token := range tokenize(field) {
+			if unique[token] {
+				continue
+			}
+			unique[token] = true
+			doc.tokens = append(doc.tokens, token)
+
+			posting, exists := idx.postings[token]
+			if !exists {
+				posting = make(map[*indexedDoc]struct{})
+				idx.postings[token] = posting
+				for _, gram := range tokenGrams(token) {
+					if idx.grams[gram] == nil {
+						idx.grams[gram] = make(map[string]struct{})
+					}
+					idx.grams[gram][token] = struct{}{}
+				}
+			}
+			posting[doc] = struct{}{}
+		}
+	}
+
+	for _, fv := range doc.fieldValues() {
+		if idx.values[fv.field] == nil {
+			idx.values[fv.field] = make(map[string]map[*indexedDoc]struct{})
+		}
+		docs, exists := idx.values[fv.field][fv.value]
+		if !exists {
+			docs = make(map[*indexedDoc]struct{})
+			idx.values[fv.field][fv.value] = docs
+		}
+		docs[doc] = struct{}{}
+	}
+
+	idx.docs[key] = doc
+	return doc
+}
+
+// remove drops a resource and any tokens only it referenced
+func (idx *searchIndex) remove(key string) {
+	doc, exists := idx.docs[key]
+	if !exists {
+		return
+	}
+
+	for _, token := range doc.tokens {
+		posting := idx.postings[token]
+		delete(posting, doc)
+		if len(posting) > 0 {
+			continue
+		}
+		delete(idx.postings, token)
+		for _, gram := range tokenGrams(token) {
+			delete(idx.grams[gram], token)
+			if len(idx.grams[gram]) == 0 {
+				delete(idx.grams, gram)
+			}
+		}
+	}
+
+	for _, fv := range doc.fieldValues() {
+		docs := idx.values[fv.field][fv.value]
+		delete(docs, doc)
+		if len(docs) == 0 {
+			delete(idx.values[fv.field], fv.value)
+		}
+	}
+
+	for _, text := range doc.fuzzyKeys {
+		if entry, exists := idx.fuzzy[text]; exists {
+			delete(entry.docs, doc)
+			if len(entry.docs) == 0 {
+				delete(idx.fuzzy, text)
+				idx.fuzzyByLength[len(text)].remove(text, entry)
+				if len(idx.fuzzyByLength[len(text)]) == 0 {
+					delete(idx.fuzzyByLength, len(text))
+				}
+				if entry.fullText {
+					idx.fuzzyFullText.remove(text, entry)
+				}
+			}
+		}
+	}
+
+	delete(idx.docs, key)
+}
+
+// addFuzzyEntry records that doc has text as a name/resource group value or token
+func (idx *searchIndex) addFuzzyEntry(doc *indexedDoc, text string, fullText bool) {
+	entry, exists := idx.fuzzy[text]
+	if !exists {
+		entry = &fuzzyEntry{mask: charMask(text), docs: make(map[*indexedDoc]struct{})}
+		idx.fuzzy[text] = entry
+		if idx.fuzzyByLength[len(text)] == nil {
+			idx.fuzzyByLength[len(text)] = make(fuzzyBucket)
+		}
+		idx.fuzzyByLength[len(text)].add(text, entry)
+	}
+	if fullText && !entry.fullText {
+		entry.fullText = true
+		idx.fuzzyFullText.add(text, entry)
+	}
+	if _, referenced := entry.docs[doc]; !referenced {
+		entry.docs[doc] = struct{}{}
+		doc.fuzzyKeys = append(doc.fuzzyKeys, text)
+	}
+}
+
+// add files entry under its character mask
+func (b fuzzyBucket) add(text string, entry *fuzzyEntry) {
+	if b[entry.mask] == nil {
+		b[entry.mask] = make(map[string]*fuzzyEntry)
+	}
+	b[entry.mask][text] = entry
+}
+
+// remove drops entry, and its mask group once empty
+func (b fuzzyBucket) remove(text string, entry *fuzzyEntry) {
+	delete(b[entry.mask], text)
+	if len(b[entry.mask]) == 0 {
+		delete(b, entry.mask)
+	}
+}
+
+// fuzzyCandidates adds the documents that could match term as a subsequence
+// of their name or resource group, or within the typo budget of one of their
+// tokens. Both checks are necessary conditions of fuzzyMatchText:
+// subsequence matches need every character of the term, and each edit can
+// introduce at most one character and change the length by at most one. Only
+// the vocabulary buckets that can pass them are visited.
+func (idx *searchIndex) fuzzyCandidates(term string, docs docSet) {
+	termMask := charMask(term)
+	maxTypos := maxTyposFor(term)
+	for length := len(term) - maxTypos; length <= len(term)+maxTypos; length++ {
+		for mask, entries := range idx.fuzzyByLength[length] {
+			if bits.OnesCount64(termMask&^mask) > maxTypos {
+				continue
+			}
+			for _, entry := range entries {
+				docs.addAll(entry.docs)
+			}
+		}
+	}
+	for mask, entries := range idx.fuzzyFullText {
+		if termMask&^mask != 0 {
+			continue
+		}
+		for text, entry := range entries {
+			if len(text) >= len(term) {
+				docs.addAll(entry.docs)
+			}
+		}
+	}
+}
+
+// termDocs returns the documents that may match any of the query terms, or
+// nil when a term has nothing to look up, e.g. a lone separator. Substring
+// terms are resolved through the token index and wildcard terms through
+// their literal parts; terms long enough for fuzzy matching also pull in
+// documents from the fuzzy vocabulary.
+func (idx *searchIndex) termDocs(terms []string, wildcards bool) docSet {
+	matched := idx.newSet()
+	for _, term := range terms {
+		var pieces []string
+		if wildcards {
+			for _, part := range strings.FieldsFunc(term, isWildcard) {
+				pieces = append(pieces, tokenize(part)...)
+			}
+		} else {
+			pieces = tokenize(term)
+		}
+		pieces = lookupPieces(pieces)
+		if len(pieces) == 0 {
+			return nil
+		}
+
+		matched.union(idx.piecesDocs(pieces))
+
+		if !wildcards && len(term) >= minFuzzyTermLength {
+			idx.fuzzyCandidates(term, matched)
+		}
+	}
+	return matched
+}
+
+// valueDocs returns the documents whose value of a filter field satisfies matches
+func (idx *searchIndex) valueDocs(field string, matches func(value string) bool) docSet {
+	set := idx.newSet()
+	for value, docs := range idx.values[field] {
+		if matches(value) {
+			set.addAll(docs)
+		}
+	}
+	return set
+}
+
+// all returns every document in inventory order
+func (idx *searchIndex) all() []*indexedDoc {
+	return idx.order
+}
+
+// piecesDocs returns the documents having a token containing each of pieces.
+// The rarest piece is looked up first; once fewer documents are left than a
+// piece has postings, checking their tokens is cheaper than collecting them.
+func (idx *searchIndex) piecesDocs(pieces []string) docSet {
+	type lookup struct {
+		piece  string
+		tokens []string
+		size   int
+	}
+	lookups := make([]lookup, len(pieces))
+	for i, piece := range pieces {
+		lookups[i] = lookup{piece: piece, tokens: idx.tokensContaining(piece)}
+		for _, token := range lookups[i].tokens {
+			lookups[i].size += len(idx.postings[token])
+		}
+	}
+	slices.SortFunc(lookups, func(a, b lookup) int { return cmp.Compare(a.size, b.size) })
+
+	var docs docSet
+	for _, l := range lookups {
+		if docs != nil && l.size > 8*docs.count() {
+			idx.keep(docs, func(doc *indexedDoc) bool {
+				return slices.ContainsFunc(doc.tokens, func(token string) bool {
+					return strings.Contains(token, l.piece)
+				})
+			})
+			continue
+		}
+
+		pieceDocs := idx.newSet()
+		for _, token := range l.tokens {
+			pieceDocs.addAll(idx.postings[token])
+		}
+		if docs == nil {
+			docs = pieceDocs
+		} else {
+			docs.intersect(pieceDocs)
+		}
+	}
+	return docs
+}
+
+// tokensContaining returns the indexed tokens that contain piece as a substring
+func (idx *searchIndex) tokensContaining(piece string) []string {
+	var matches []string
+
+	switch {
+	case len(piece) < 2:
+		for token := range idx.postings {
+			if strings.Contains(token, piece) {
+				matches = append(matches, token)
+			}
+		}
+		return matches
+	case len(piece) == 2:
+		// Bigrams are indexed, so the lookup is exact
+		for token := range idx.grams[piece] {
+			matches = append(matches, token)
+		}
+		return matches
+	}
+
+	// Every token containing piece contains all of its trigrams, so the
+	// rarest trigram gives the smallest set to verify
+	var smallest map[string]struct{}
+	for _, gram := range trigrams(piece) {
+		tokens, exists := idx.grams[gram]
+		if !exists {
+			return nil
+		}
+		if smallest == nil || len(tokens) < len(smallest) {
+			smallest = tokens
+		}
+	}
+	for token := range smallest {
+		if strings.Contains(token, piece) {
+			matches = append(matches, token)
+		}
+	}
+	return matches
+}
+
+// lookupPieces drops one- and two-character pieces when the term has longer
+// ones: they match most of the vocabulary and add nothing to the intersection
+// that verification against the resource won't catch anyway
+func lookupPieces(pieces []string) []string {
+	long := make([]string, 0, len(pieces))
+	for _, piece := range pieces {
+		if len(piece) >= 3 {
+			long = append(long, piece)
+		}
+	}
+	if len(long) == 0 {
+		return pieces
+	}
+	return long
+}
+
+// tokenize lowercases text and splits it on separators
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return r < 128 && strings.ContainsRune(fuzzySeparators+"=,;:\\", r)
+	})
+}
+
+// trigrams returns the distinct three-byte substrings of token
+func trigrams(token string) []string {
+	return ngrams(token, 3)
+}
+
+// tokenGrams returns the distinct two- and three-byte substrings of token
+func tokenGrams(token string) []string {
+	return append(ngrams(token, 2), ngrams(token, 3)...)
+}
+
+// ngrams returns the distinct n-byte substrings of token
+func ngrams(token string, n int) []string {
+	if len(token) < n {
+		return nil
+	}
+	seen := make(map[string]bool, len(token)-n+1)
+	grams := make([]string, 0, len(token)-n+1)
+	for i := 0; i+n <= len(token); i++ {
+		gram := token[i : i+n]
+		if !seen[gram] {
+			seen[gram] = true
+			grams = append(grams, gram)
+		}
+	}
+	return grams
+}
+
+// isWildcard reports whether r is a wildcard character
+func isWildcard(r rune) bool {
+	return r == '*' || r == '?'
+}
+
+// docSet is a set of documents as a bitmap over their inventory positions
+type docSet []uint64
+
+// newSet returns an empty set sized for the inventory
+func (idx *searchIndex) newSet() docSet {
+	return make(docSet, (len(idx.order)+63)/64)
+}
+
+// allSet returns a set holding every document
+func (idx *searchIndex) allSet() docSet {
+	set := idx.newSet()
+	for i := range set {
+		set[i] = ^uint64(0)
+	}
+	if extra := len(idx.order) % 64; extra != 0 {
+		set[len(set)-1] = 1<<extra - 1
+	}
+	return set
+}
+
+// members returns the documents in set, in inventory order
+func (idx *searchIndex) members(set docSet) []*indexedDoc {
+	var docs []*indexedDoc
+	for i, word := range set {
+		for word != 0 {
+			docs = append(docs, idx.order[i*64+bits.TrailingZeros64(word)])
+			word &= word - 1
+		}
+	}
+	return docs
+}
+
+// keep drops the documents of set for which keep returns false
+func (idx *searchIndex) keep(set docSet, keep func(doc *indexedDoc) bool) {
+	for i, word := range set {
+		for word != 0 {
+			bit := bits.TrailingZeros64(word)
+			if !keep(idx.order[i*64+bit]) {
+				set[i] &^= 1 << bit
+			}
+			word &= word - 1
+		}
+	}
+}
+
+// count returns the number of documents in the set
+func (s docSet) count() int {
+	n := 0
+	for _, word := range s {
+		n += bits.OnesCount64(word)
+	}
+	return n
+}
+
+// addAll adds every document of a posting
+func (s docSet) addAll(docs map[*indexedDoc]struct{}) {
+	for doc := range docs {
+		s[doc.position/64] |= 1 << (doc.position % 64)
+	}
+}
+
+// union adds the documents of other
+func (s docSet) union(other docSet) {
+	for i := range s {
+		s[i] |= other[i]
+	}
+}
+
+// intersect keeps only the documents also in other
+func (s docSet) intersect(other docSet) {
+	for i := range s {
+		s[i] &= other[i]
+	}
+}
+
+// remove drops the documents in other
+func (s docSet) remove(other docSet) {
+	for i := range s {
+		s[i] &^= other[i]
+	}
+}
+
+// charMask sets one bit per distinct character in lowercased text. Letters
+// and digits get a bit each so that numbered names don't collide with words;
+// other bytes share the remaining bits.
+func charMask(text string) uint64 {
+	var mask uint64
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case 'a' <= c && c <= 'z':
+			mask |= 1 << (c - 'a')
+		case '0' <= c && c <= '9':
+			mask |= 1 << (26 + c - '0')
+		default:
+			mask |= 1 << (36 + c%28)
+		}
+	}
+	return mask
+}
+
+// resourceTypeShortName returns the lowercased last segment of a resource type
+func resourceTypeShortName(resourceType string) string {
+	typeParts := strings.Split(resourceType, "/")
+	if len(typeParts) > 1 {
+		return strings.ToLower(typeParts[len(typeParts)-1])
+	}
+	return ""
+}
+
+// docKey identifies a resource in the index, falling back to its position when it has no ID
+func docKey(resource Resource, position int) string {
+	if resource.ID != "" {
+		return resource.ID
+	}
+	return fmt.Sprintf("#%d", position)
+}
+
+// sameIndexedFields reports whether two versions of a resource index identically
+func sameIndexedFields(a, b Resource) bool {
+	return a.Name == b.Name &&
+		a.ResourceGroup == b.ResourceGroup &&
+		a.Location == b.Location &&
+		a.Type == b.Type &&
+		maps.Equal(a.Tags, b.Tags)
+}
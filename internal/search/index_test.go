@@ -0,0 +1,275 @@
+package search
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+)
+
+var benchmarkTypes = []string{
+	"Microsoft.Compute/virtualMachines",
+	"Microsoft.Storage/storageAccounts",
+	"Microsoft.Web/sites",
+	"Microsoft.Network/virtualNetworks",
+	"Microsoft.KeyVault/vaults",
+	"Microsoft.ContainerService/managedClusters",
+}
+
+var benchmarkApps = []string{"payments", "orders", "billing", "search", "identity", "catalog", "shipping", "reports"}
+
+var benchmarkLocations = []string{"westeurope", "northeurope", "eastus", "westus2", "uksouth"}
+
+// generateResources builds a deterministic inventory of n resources
+func generateResources(n int) []Resource {
+	resources := make([]Resource, n)
+	for i := range resources {
+		app := benchmarkApps[i%len(benchmarkApps)]
+		env := []string{"prod", "staging", "dev"}[i%3]
+		resources[i] = Resource{
+			ID:            fmt.Sprintf("/subscriptions/sub/resourceGroups/rg-%s-%d/providers/x/%d", app, i%200, i),
+			Name:          fmt.Sprintf("%s-api-%s-%d", app, env, i),
+			Type:          benchmarkTypes[i%len(benchmarkTypes)],
+			Location:      benchmarkLocations[i%len(benchmarkLocations)],
+			ResourceGroup: fmt.Sprintf("rg-%s-%d", app, i%200),
+			Tags:          map[string]string{"env": env, "team": fmt.Sprintf("team%d", i%17)},
+		}
+	}
+	return resources
+}
+
+// linearSearch is the unindexed reference implementation
+func linearSearch(se *SearchEngine, resources []Resource, query string) []SearchResult {
+	parsedQuery := se.parseQuery(query)
+	results := []SearchResult{}
+	for _, resource := range resources {
+		results = append(results, se.searchResource(resource, parsedQuery)...)
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	return results
+}
+
+func resultKeys(results []SearchResult) []string {
+	keys := make([]string, len(results))
+	for i, result := range results {
+		keys[i] = fmt.Sprintf("%s|%s|%s|%d", result.ResourceID, result.MatchType, result.MatchText, result.Score)
+	}
+	return keys
+}
+
+func TestSearchIndex_MatchesLinearScan(t *testing.T) {
+	resources := generateResources(2000)
+	engine := NewSearchEngine()
+	engine.SetResources(resources)
+
+	queries := []string{
+		"pay", "payments-api", "api-prod", "westeu", "virtualmachines", "compute",
+		"rg-orders-1", "team3", "pymnts", "paymnets", "prod 17", "type:vm payments",
+		"tag:env=prod", "shipp*", "zzz", "-", "e",
+		"type:vm", "location:westeurope tag:team", `rg:"rg-orders-1"`, "NOT type:vm", "tag:none", "sub:sub",
+		"type:storage -tag:env=dev", "*-api-dev-1?", "orders* tag:team=team3", "*",
+	}
+
+	for _, query := range queries {
+		got := resultKeys(mustSearch(t, engine, query))
+		want := resultKeys(linearSearch(engine, resources, query))
+		if len(got) != len(want) {
+			t.Errorf("query %q: indexed search returned %d results, linear scan %d", query, len(got), len(want))
+			continue
+		}
+		for i := range got {
+			if got[i] != want[i] {
+				t.Errorf("query %q: result %d differs: %s != %s", query, i, got[i], want[i])
+				break
+			}
+		}
+	}
+}
+
+func TestSearchIndex_NarrowsCandidates(t *testing.T) {
+	engine := NewSearchEngine()
+	engine.SetResources(generateResources(2000))
+
+	// Filters and wildcard terms are resolved through the index rather than
+	// by checking every resource
+	tests := map[string]int{
+		"type:vm":                      334,
+		`rg:"rg-orders-1"`:             10,
+		"tag:env=prod location:eastus": 133,
+		"NOT type:vm":                  1666,
+		"shipp*":                       250,
+		"*":                            2000,
+	}
+	for query, want := range tests {
+		if got := len(engine.candidates(engine.parseQuery(query))); got != want {
+			t.Errorf("candidates(%q) = %d resources, want %d", query, got, want)
+		}
+	}
+}
+
+func TestSearchIndex_IncrementalUpdates(t *testing.T) {
+	engine := NewSearchEngine()
+	resources := []Resource{
+		{ID: "1", Name: "payments-api", ResourceGroup: "rg-payments"},
+		{ID: "2", Name: "orders-api", ResourceGroup: "rg-orders"},
+	}
+	engine.SetResources(resources)
+
+	// Rename one resource and add another
+	updated := []Resource{
+		{ID: "1", Name: "checkout-api", ResourceGroup: "rg-payments"},
+		{ID: "2", Name: "orders-api", ResourceGroup: "rg-orders"},
+		{ID: "3", Name: "invoices-api", ResourceGroup: "rg-billing"},
+	}
+	engine.SetResources(updated)
+
+	if results := mustSearch(t, engine, "checkout"); len(results) != 1 || results[0].ResourceID != "1" {
+		t.Errorf("Expected renamed resource to be found by its new name, got %v", results)
+	}
+	for _, result := range mustSearch(t, engine, "payments") {
+		if result.MatchType == "name" {
+			t.Errorf("Expected old name to be removed from the index, got %v", result)
+		}
+	}
+	if results := mustSearch(t, engine, "invoices"); len(results) != 1 {
+		t.Errorf("Expected added resource to be indexed, got %v", results)
+	}
+
+	// Remove everything but one resource
+	engine.SetResources(updated[1:2])
+	if results := mustSearch(t, engine, "api"); len(results) != 1 || results[0].ResourceID != "2" {
+		t.Errorf("Expected removed resources to be dropped, got %v", results)
+	}
+	if _, exists := engine.index.postings["checkout"]; exists {
+		t.Error("Expected unused tokens to be dropped from the index")
+	}
+}
+
+func TestSearchIndex_UpsertAndRemove(t *testing.T) {
+	engine := NewSearchEngine()
+	engine.SetResources([]Resource{
+		{ID: "1", Name: "payments-api"},
+		{ID: "2", Name: "orders-api"},
+	})
+
+	engine.UpsertResources([]Resource{
+		{ID: "2", Name: "orders-api-v2"},
+		{ID: "3", Name: "invoices-api"},
+	})
+	results := mustSearch(t, engine, "api")
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results after upsert, got %v", results)
+	}
+	if results[1].ResourceName != "orders-api-v2" || results[2].ResourceID != "3" {
+		t.Errorf("Expected updated resource to keep its position and new ones to be appended, got %v", resultKeys(results))
+	}
+
+	engine.RemoveResources([]string{"1"})
+	results = mustSearch(t, engine, "api")
+	if len(results) != 2 || results[0].ResourceID != "2" {
+		t.Errorf("Expected removed resource to be dropped, got %v", resultKeys(results))
+	}
+	if got := mustSearch(t, engine, "payments"); len(got) != 0 {
+		t.Errorf("Expected no results for removed resource, got %v", got)
+	}
+
+	// Resources without an ID cannot be matched to an indexed one
+	engine.SetResources([]Resource{{Name: "legacy-api"}, {ID: "2", Name: "orders-api"}})
+	for range 2 {
+		engine.UpsertResources([]Resource{{Name: "legacy-api"}})
+	}
+	if results := mustSearch(t, engine, "legacy"); len(results) != 1 || len(engine.index.docs) != 2 || len(engine.index.order) != 2 {
+		t.Errorf("Expected upserting a resource without an ID to leave the index alone, got %v and %d docs", resultKeys(results), len(engine.index.docs))
+	}
+}
+
+func mustSearch(t *testing.T, engine *SearchEngine, query string) []SearchResult {
+	t.Helper()
+	results, err := engine.Search(query)
+	if err != nil {
+		t.Fatalf("Search(%q) failed: %v", query, err)
+	}
+	return results
+}
+
+// benchmarkKeystrokes simulates typing a query one character at a time,
+// either through the index or with the unindexed linear scan for comparison.
+// A single character matches nearly every resource (every type contains
+// "Microsoft"), so typing is measured from the second character on.
+func benchmarkKeystrokes(b *testing.B, n int, query string, linear bool) {
+	resources := generateResources(n)
+	engine := NewSearchEngine()
+	engine.SetResources(resources)
+	keystrokes := len(query) - 1
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for end := 2; end <= len(query); end++ {
+			if linear {
+				linearSearch(engine, resources, query[:end])
+			} else if _, err := engine.Search(query[:end]); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+	b.ReportMetric(float64(b.Elapsed().Microseconds())/float64(b.N*keystrokes), "µs/keystroke")
+}
+
+var benchmarkQueries = map[string]string{
+	"selective": "shipping-api-dev-4242",
+	"broad":     "westeu",
+}
+
+func BenchmarkSearchKeystrokes(b *testing.B) {
+	for _, n := range []int{10000, 50000, 100000} {
+		for _, kind := range []string{"selective", "broad"} {
+			b.Run(fmt.Sprintf("resources=%d/%s", n, kind), func(b *testing.B) {
+				benchmarkKeystrokes(b, n, benchmarkQueries[kind], false)
+			})
+		}
+	}
+}
+
+func BenchmarkLinearScanKeystrokes(b *testing.B) {
+	for _, n := range []int{10000, 50000, 100000} {
+		for _, kind := range []string{"selective", "broad"} {
+			b.Run(fmt.Sprintf("resources=%d/%s", n, kind), func(b *testing.B) {
+				benchmarkKeystrokes(b, n, benchmarkQueries[kind], true)
+			})
+		}
+	}
+}
+
+func BenchmarkSetResourcesIncremental(b *testing.B) {
+	for _, n := range []int{10000, 50000, 100000} {
+		b.Run(fmt.Sprintf("resources=%d", n), func(b *testing.B) {
+			resources := generateResources(n)
+			engine := NewSearchEngine()
+			engine.SetResources(resources)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				resources[i%n].Name = fmt.Sprintf("renamed-%d", i)
+				engine.SetResources(resources)
+			}
+		})
+	}
+}
+
+func BenchmarkUpsertResources(b *testing.B) {
+	for _, n := range []int{10000, 50000, 100000} {
+		b.Run(fmt.Sprintf("resources=%d", n), func(b *testing.B) {
+			resources := generateResources(n)
+			engine := NewSearchEngine()
+			engine.SetResources(resources)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				updated := resources[i%n]
+				updated.Name = fmt.Sprintf("renamed-%d", i)
+				engine.UpsertResources([]Resource{updated})
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package search
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// queryFilters lists the filter keys understood by parseAdvancedQuery and
+// whether they can be negated with NOT
+var queryFilters = map[string]bool{
+	"type":           true,
+	"tag":            true,
+	"location":       false,
+	"loc":            false,
+	"rg":             false,
+	"resourcegroup":  false,
+	"resource-group": false,
+	"sub":            false,
+	"subscription":   false,
+	"name":           false,
+}
+
+// QuerySchema describes the search query syntax. It is what gets sent to an
+// AI provider to translate natural language into a query, so it must not
+// contain any inventory data.
+func QuerySchema() string {
+	aliases := make([]string, 0, len(typeAliases))
+	for alias := range typeAliases {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+
+	var b strings.Builder
+	b.WriteString("Azure resource search query syntax. Parts are separated by spaces and every filter must match.\n")
+	b.WriteString("Free text terms match resource names, types, locations, resource groups and tags; * and ? are wildcards.\n")
+	b.WriteString("Filters (values are case-insensitive substrings; a quoted value such as rg:\"rg-web\" must match exactly and may contain spaces):\n")
+	fmt.Fprintf(&b, "  type:<type>         resource type: one of %s, or part of a full type such as Microsoft.Web/sites\n", strings.Join(aliases, ", "))
+	b.WriteString("  location:<region>   Azure region name, e.g. westeurope, eastus, uksouth\n")
+	b.WriteString("  rg:<name>           resource group name\n")
+	b.WriteString("  sub:<id>            subscription ID\n")
+	b.WriteString("  tag:<key>           has a tag with this key\n")
+	b.WriteString("  tag:<key>=<value>   has a tag with this key and value\n")
+	b.WriteString("  tag:none            has no tags at all\n")
+	b.WriteString("  NOT type:<type>     excludes a resource type\n")
+	b.WriteString("  NOT tag:<key>       excludes resources having this tag (also NOT tag:<key>=<value>)\n")
+	b.WriteString("Example: type:vm location:westeurope NOT tag:owner\n")
+	return b.String()
+}
+
+// ValidateQuery checks that query only uses known filters, so that a
+// generated query doesn't silently match something else than intended
+func ValidateQuery(query string) error {
+	if strings.TrimSpace(query) == "" {
+		return fmt.Errorf("empty query")
+	}
+
+	negate := false
+	for _, part := range queryFields(query) {
+		if strings.ToUpper(part) == "NOT" {
+			negate = true
+			continue
+		}
+		if strings.HasPrefix(part, "-") && strings.Contains(part, ":") {
+			negate = true
+			part = part[1:]
+		}
+
+		if key, value, found := strings.Cut(part, ":"); found {
+			negatable, known := queryFilters[strings.ToLower(key)]
+			if !known {
+				return fmt.Errorf("unknown filter %q", key)
+			}
+			if value == "" {
+				return fmt.Errorf("filter %q has no value", key)
+			}
+			if negate && !negatable {
+				return fmt.Errorf("filter %q can't be negated", key)
+			}
+		}
+		negate = false
+	}
+
+	return nil
+}
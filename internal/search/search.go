@@ -1,9 +1,11 @@
 package search
 
 import (
-	"fmt"
+	"cmp"
+	"slices"
 	"sort"
 	"strings"
+	"time"
 	"unicode"
 )
 
@@ -18,7 +20,8 @@ type SearchResult struct {
 	MatchType     string // "name", "location", "tag", "type", "resource_group"
 	MatchText     string
 	MatchValue    string
-	Score         int // Relevance score (higher = better match)
+	MatchSpans    []MatchSpan // Highlighted ranges within MatchValue
+	Score         int         // Relevance score (higher = better match)
 }
 
 // SearchFilters defines search filtering criteria
@@ -27,7 +30,10 @@ type SearchFilters struct {
 	ResourceType  string
 	Tags          map[string]string
 	ResourceGroup string
+	Subscription  string
 	ExcludeTypes  []string
+	ExcludeTags   map[string]string // Resources with these tags are left out (NOT tag:key)
+	Untagged      bool              // Only resources without any tags (tag:none)
 }
 
 // SearchQuery represents a parsed search query
@@ -41,7 +47,8 @@ type SearchQuery struct {
 
 // SearchEngine provides search functionality across Azure resources
 type SearchEngine struct {
-	resources []Resource
+	index       *searchIndex
+	accessTimes map[string]time.Time // Last time each resource was opened, used as a ranking tie-breaker
 }
 
 // Resource represents a searchable Azure resource
@@ -59,13 +66,36 @@ type Resource struct {
 // NewSearchEngine creates a new search engine instance
 func NewSearchEngine() *SearchEngine {
 	return &SearchEngine{
-		resources: make([]Resource, 0),
+		index:       newSearchIndex(),
+		accessTimes: make(map[string]time.Time),
 	}
 }
 
-// SetResources updates the searchable resource list
+// SetResources updates the searchable resource list. Only resources that were
+// added, removed or changed since the previous call are re-indexed.
 func (se *SearchEngine) SetResources(resources []Resource) {
-	se.resources = resources
+	se.index.update(resources)
+}
+
+// UpsertResources adds new resources and re-indexes changed ones, leaving the
+// rest of the inventory as is. Resources without an ID are ignored; use
+// SetResources for those.
+func (se *SearchEngine) UpsertResources(resources []Resource) {
+	se.index.upsert(resources)
+}
+
+// RemoveResources drops resources by ID
+func (se *SearchEngine) RemoveResources(resourceIDs []string) {
+	se.index.removeKeys(resourceIDs)
+}
+
+// RecordAccess notes that a resource was just opened so that recently used
+// resources rank first among equally scored results
+func (se *SearchEngine) RecordAccess(resourceID string) {
+	if se.accessTimes == nil {
+		se.accessTimes = make(map[string]time.Time)
+	}
+	se.accessTimes[resourceID] = time.Now()
 }
 
 // Search performs a comprehensive search across all resources
@@ -75,19 +105,129 @@ func (se *SearchEngine) Search(query string) ([]SearchResult, error) {
 	}
 
 	parsedQuery := se.parseQuery(query)
-	results := []SearchResult{}
+	candidates := se.candidates(parsedQuery)
+	results := make([]SearchResult, 0, len(candidates))
+
+	for _, doc := range candidates {
+		results = se.searchDoc(doc, parsedQuery, results)
+	}
+
+	// Sort by relevance score (descending), most recently accessed first on
+	// ties, otherwise keeping inventory order. Sorting positions avoids moving
+	// the result structs around on every swap.
+	order := make([]int, len(results))
+	lastAccess := make([]int64, len(results))
+	for i, result := range results {
+		order[i] = i
+		if accessed, exists := se.accessTimes[result.ResourceID]; exists {
+			lastAccess[i] = accessed.UnixNano()
+		}
+	}
+	slices.SortFunc(order, func(a, b int) int {
+		if results[a].Score != results[b].Score {
+			return cmp.Compare(results[b].Score, results[a].Score)
+		}
+		if lastAccess[a] != lastAccess[b] {
+			return cmp.Compare(lastAccess[b], lastAccess[a])
+		}
+		return cmp.Compare(a, b)
+	})
 
-	for _, resource := range se.resources {
-		matches := se.searchResource(resource, parsedQuery)
-		results = append(results, matches...)
+	sorted := make([]SearchResult, len(results))
+	for i, position := range order {
+		sorted[i] = results[position]
 	}
 
-	// Sort by relevance score (descending)
-	sort.Slice(results, func(i, j int) bool {
-		return results[i].Score > results[j].Score
-	})
+	return sorted, nil
+}
 
-	return results, nil
+// candidates returns the documents that may match query, in inventory order.
+// Terms are looked up in the token index and filters in the field value
+// index; only queries neither can narrow down check every resource.
+func (se *SearchEngine) candidates(query SearchQuery) []*indexedDoc {
+	var set docSet
+	if len(query.Terms) > 0 {
+		set = se.index.termDocs(query.Terms, query.Wildcards)
+	}
+	if filtered := se.filterDocs(query.Filters); filtered != nil {
+		if set == nil {
+			set = filtered
+		} else {
+			set.intersect(filtered)
+		}
+	}
+	if set == nil {
+		return se.index.all()
+	}
+	return se.index.members(set)
+}
+
+// filterDocs returns the documents matching filters, or nil when there are no
+// filters. Each filter is checked against the distinct values of its field,
+// which are far fewer than the resources having them.
+func (se *SearchEngine) filterDocs(filters SearchFilters) docSet {
+	idx := se.index
+	var set docSet
+	narrow := func(docs docSet) {
+		if set == nil {
+			set = docs
+		} else {
+			set.intersect(docs)
+		}
+	}
+	matching := func(filter string) func(string) bool {
+		return func(value string) bool { return se.matchesFilterValue(value, filter) }
+	}
+	matchingType := func(filter string) func(string) bool {
+		return func(value string) bool { return se.matchesResourceType(value, filter) }
+	}
+	matchingTag := func(filterKey, filterValue string) func(string) bool {
+		return func(pair string) bool {
+			key, value, _ := strings.Cut(pair, tagSeparator)
+			return se.matchesFilterValue(key, filterKey) && se.matchesFilterValue(value, filterValue)
+		}
+	}
+
+	if filters.ResourceType != "" {
+		narrow(idx.valueDocs(fieldType, matchingType(filters.ResourceType)))
+	}
+	if filters.Location != "" {
+		narrow(idx.valueDocs(fieldLocation, matching(filters.Location)))
+	}
+	if filters.ResourceGroup != "" {
+		narrow(idx.valueDocs(fieldResourceGroup, matching(filters.ResourceGroup)))
+	}
+	if filters.Subscription != "" {
+		narrow(idx.valueDocs(fieldSubscription, matching(filters.Subscription)))
+	}
+	if filters.Untagged {
+		narrow(idx.valueDocs(fieldUntagged, func(string) bool { return true }))
+	}
+	for key, value := range filters.Tags {
+		if value == "" {
+			narrow(idx.valueDocs(fieldTagKey, matching(key)))
+		} else {
+			narrow(idx.valueDocs(fieldTag, matchingTag(key, value)))
+		}
+	}
+
+	if len(filters.ExcludeTypes) == 0 && len(filters.ExcludeTags) == 0 {
+		return set
+	}
+	if set == nil {
+		set = idx.allSet()
+	}
+	for _, excludeType := range filters.ExcludeTypes {
+		set.remove(idx.valueDocs(fieldType, matchingType(excludeType)))
+	}
+	for key, value := range filters.ExcludeTags {
+		if value == "" {
+			set.remove(idx.valueDocs(fieldTagKey, matching(key)))
+		} else {
+			set.remove(idx.valueDocs(fieldTag, matchingTag(key, value)))
+		}
+	}
+	return set
 }
 
 // parseQuery parses the search query and extracts filters
@@ -95,7 +235,7 @@ func (se *SearchEngine) parseQuery(query string) SearchQuery {
 	sq := SearchQuery{
 		RawQuery: query,
 		Terms:    []string{},
-		Filters:  SearchFilters{Tags: make(map[string]string)},
+		Filters:  SearchFilters{Tags: make(map[string]string), ExcludeTags: make(map[string]string)},
 	}
 
 	// Check for advanced search syntax
@@ -116,11 +256,19 @@ func (se *SearchEngine) parseQuery(query string) SearchQuery {
 	return sq
 }
 
-// parseAdvancedQuery handles advanced search syntax
+// parseAdvancedQuery handles advanced search syntax. NOT (or a leading "-")
+// negates the type: or tag: filter that follows it. A quoted filter value,
+// as in rg:"rg-web", must match exactly rather than as a substring.
 func (se *SearchEngine) parseAdvancedQuery(query string, sq SearchQuery) SearchQuery {
-	parts := strings.Fields(query)
+	parts := queryFields(query)
+	negate := false
 
 	for _, part := range parts {
+		if strings.HasPrefix(part, "-") && strings.Contains(part, ":") {
+			negate = true
+			part = part[1:]
+		}
+
 		if strings.Contains(part, ":") {
 			// Handle key:value syntax
 			kv := strings.SplitN(part, ":", 2)
@@ -130,38 +278,127 @@ func (se *SearchEngine) parseAdvancedQuery(query string, sq SearchQuery) SearchQ
 
 				switch key {
 				case "type":
-					sq.Filters.ResourceType = value
+					if negate {
+						sq.Filters.ExcludeTypes = append(sq.Filters.ExcludeTypes, value)
+					} else {
+						sq.Filters.ResourceType = value
+					}
 				case "location", "loc":
 					sq.Filters.Location = value
 				case "rg", "resourcegroup", "resource-group":
 					sq.Filters.ResourceGroup = value
+				case "sub", "subscription":
+					sq.Filters.Subscription = value
 				case "tag":
-					// Handle tag:key=value or tag:key
-					if strings.Contains(value, "=") {
+					// Handle tag:key=value, tag:key or tag:none
+					tags := sq.Filters.Tags
+					if negate {
+						tags = sq.Filters.ExcludeTags
+					}
+					if value == "none" && !negate {
+						sq.Filters.Untagged = true
+					} else if exact, ok := exactValue(value); ok && strings.Contains(exact, "=") {
+						// tag:"key=value" matches both parts exactly
+						tagKey, tagValue, _ := strings.Cut(exact, "=")
+						tags[quote(tagKey)] = quote(tagValue)
+					} else if strings.Contains(value, "=") {
 						tagParts := strings.SplitN(value, "=", 2)
-						sq.Filters.Tags[tagParts[0]] = tagParts[1]
+						tags[tagParts[0]] = tagParts[1]
 					} else {
-						sq.Filters.Tags[value] = "" // Any value
+						tags[value] = "" // Any value
 					}
 				case "name":
 					sq.Terms = append(sq.Terms, value)
 				}
 			}
-		} else if strings.ToUpper(part) == "AND" || strings.ToUpper(part) == "OR" || strings.ToUpper(part) == "NOT" {
+			negate = false
+		} else if strings.ToUpper(part) == "NOT" {
+			negate = true
+		} else if strings.ToUpper(part) == "AND" || strings.ToUpper(part) == "OR" {
 			// Handle boolean operators (simplified for now)
 			continue
 		} else {
 			// Regular search term
+			if strings.Contains(part, "*") || strings.Contains(part, "?") {
+				sq.Wildcards = true
+			}
 			sq.Terms = append(sq.Terms, strings.ToLower(part))
+			negate = false
 		}
 	}
 
 	return sq
 }
 
+// queryFields splits query at whitespace outside double quotes. The quotes
+// are kept so that exact filter values can be told apart.
+func queryFields(query string) []string {
+	var fields []string
+	var field strings.Builder
+	quoted := false
+	for _, r := range query {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			field.WriteRune(r)
+		case unicode.IsSpace(r) && !quoted:
+			if field.Len() > 0 {
+				fields = append(fields, field.String())
+				field.Reset()
+			}
+		default:
+			field.WriteRune(r)
+		}
+	}
+	if field.Len() > 0 {
+		fields = append(fields, field.String())
+	}
+	return fields
+}
+
+// HasFilter reports whether query already contains filter, comparing whole
+// query parts case-insensitively
+func HasFilter(query, filter string) bool {
+	for _, part := range queryFields(query) {
+		if strings.EqualFold(part, filter) {
+			return true
+		}
+	}
+	return false
+}
+
+// quote wraps a filter value in double quotes, asking for an exact match
+func quote(value string) string {
+	return `"` + value + `"`
+}
+
+// exactValue returns a filter value without its quotes and whether it was
+// quoted
+func exactValue(value string) (string, bool) {
+	if len(value) >= 2 && strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`) {
+		return value[1 : len(value)-1], true
+	}
+	return value, false
+}
+
+// matchesFilterValue checks if text matches a filter value: a quoted value
+// must equal text, ignoring case, any other value only needs to be part of it
+func (se *SearchEngine) matchesFilterValue(text, value string) bool {
+	if exact, ok := exactValue(value); ok {
+		return strings.EqualFold(text, exact)
+	}
+	return se.matchesText(text, value, false)
+}
+
 // searchResource searches a single resource for matches
 func (se *SearchEngine) searchResource(resource Resource, query SearchQuery) []SearchResult {
-	results := []SearchResult{}
+	return se.searchDoc(newIndexedDoc("", resource), query, []SearchResult{})
+}
+
+// searchDoc appends the matches of an indexed resource to results, using its
+// pre-computed lowercase fields
+func (se *SearchEngine) searchDoc(doc *indexedDoc, query SearchQuery, results []SearchResult) []SearchResult {
+	resource := doc.resource
 
 	// Apply filters first
 	if !se.matchesFilters(resource, query.Filters) {
@@ -185,27 +422,10 @@ func (se *SearchEngine) searchResource(resource Resource, query SearchQuery) []S
 		return results
 	}
 
-	// Search in resource name
-	if matches := se.searchInText(resource.Name, query.Terms, query.Wildcards); len(matches) > 0 {
-		for _, match := range matches {
-			results = append(results, SearchResult{
-				ResourceID:    resource.ID,
-				ResourceName:  resource.Name,
-				ResourceType:  resource.Type,
-				Location:      resource.Location,
-				ResourceGroup: resource.ResourceGroup,
-				Tags:          resource.Tags,
-				MatchType:     "name",
-				MatchText:     match,
-				MatchValue:    resource.Name,
-				Score:         se.calculateScore("name", match, resource.Name),
-			})
-		}
-	}
-
-	// Search in location
-	if matches := se.searchInText(resource.Location, query.Terms, query.Wildcards); len(matches) > 0 {
-		for _, match := range matches {
+	// addMatches adds a result per term matching text. Spans are shifted by
+	// offset when matchValue has a prefix before text.
+	addMatches := func(matchType, text, textLower, matchValue string, offset int, allowFuzzy bool) {
+		for _, match := range se.searchInText(text, textLower, query.Terms, query.Wildcards, allowFuzzy) {
 			results = append(results, SearchResult{
 				ResourceID:    resource.ID,
 				ResourceName:  resource.Name,
@@ -213,87 +433,23 @@ func (se *SearchEngine) searchResource(resource Resource, query SearchQuery) []S
 				Location:      resource.Location,
 				ResourceGroup: resource.ResourceGroup,
 				Tags:          resource.Tags,
-				MatchType:     "location",
-				MatchText:     match,
-				MatchValue:    resource.Location,
-				Score:         se.calculateScore("location", match, resource.Location),
+				MatchType:     matchType,
+				MatchText:     match.Term,
+				MatchValue:    matchValue,
+				MatchSpans:    shiftSpans(match.Spans, offset),
+				Score:         se.scoreMatch(matchType, match, textLower),
 			})
 		}
 	}
 
-	// Search in resource type
-	if matches := se.searchInText(resource.Type, query.Terms, query.Wildcards); len(matches) > 0 {
-		for _, match := range matches {
-			results = append(results, SearchResult{
-				ResourceID:    resource.ID,
-				ResourceName:  resource.Name,
-				ResourceType:  resource.Type,
-				Location:      resource.Location,
-				ResourceGroup: resource.ResourceGroup,
-				Tags:          resource.Tags,
-				MatchType:     "type",
-				MatchText:     match,
-				MatchValue:    resource.Type,
-				Score:         se.calculateScore("type", match, resource.Type),
-			})
-		}
-	}
-
-	// Search in resource group
-	if matches := se.searchInText(resource.ResourceGroup, query.Terms, query.Wildcards); len(matches) > 0 {
-		for _, match := range matches {
-			results = append(results, SearchResult{
-				ResourceID:    resource.ID,
-				ResourceName:  resource.Name,
-				ResourceType:  resource.Type,
-				Location:      resource.Location,
-				ResourceGroup: resource.ResourceGroup,
-				Tags:          resource.Tags,
-				MatchType:     "resource_group",
-				MatchText:     match,
-				MatchValue:    resource.ResourceGroup,
-				Score:         se.calculateScore("resource_group", match, resource.ResourceGroup),
-			})
-		}
-	}
-
-	// Search in tags
-	for tagKey, tagValue := range resource.Tags {
-		// Search in tag keys
-		if matches := se.searchInText(tagKey, query.Terms, query.Wildcards); len(matches) > 0 {
-			for _, match := range matches {
-				results = append(results, SearchResult{
-					ResourceID:    resource.ID,
-					ResourceName:  resource.Name,
-					ResourceType:  resource.Type,
-					Location:      resource.Location,
-					ResourceGroup: resource.ResourceGroup,
-					Tags:          resource.Tags,
-					MatchType:     "tag",
-					MatchText:     match,
-					MatchValue:    fmt.Sprintf("%s=%s", tagKey, tagValue),
-					Score:         se.calculateScore("tag", match, tagKey),
-				})
-			}
-		}
-
-		// Search in tag values
-		if matches := se.searchInText(tagValue, query.Terms, query.Wildcards); len(matches) > 0 {
-			for _, match := range matches {
-				results = append(results, SearchResult{
-					ResourceID:    resource.ID,
-					ResourceName:  resource.Name,
-					ResourceType:  resource.Type,
-					Location:      resource.Location,
-					ResourceGroup: resource.ResourceGroup,
-					Tags:          resource.Tags,
-					MatchType:     "tag",
-					MatchText:     match,
-					MatchValue:    fmt.Sprintf("%s=%s", tagKey, tagValue),
-					Score:         se.calculateScore("tag", match, tagValue),
-				})
-			}
-		}
+	// Names and resource groups are typo tolerant
+	addMatches("name", resource.Name, doc.nameLower, resource.Name, 0, true)
+	addMatches("location", resource.Location, doc.locLower, resource.Location, 0, false)
+	addMatches("type", resource.Type, doc.typeLower, resource.Type, 0, false)
+	addMatches("resource_group", resource.ResourceGroup, doc.groupLower, resource.ResourceGroup, 0, true)
+	for _, tag := range doc.tags {
+		addMatches("tag", tag.key, tag.keyLower, tag.pair, 0, false)
+		addMatches("tag", tag.value, tag.valueLower, tag.pair, len(tag.key)+1, false)
 	}
 
 	return results
@@ -305,11 +461,19 @@ func (se *SearchEngine) matchesFilters(resource Resource, filters SearchFilters)
 		return false
 	}
 
-	if filters.Location != "" && !se.matchesText(resource.Location, filters.Location, false) {
+	if filters.Location != "" && !se.matchesFilterValue(resource.Location, filters.Location) {
 		return false
 	}
 
-	if filters.ResourceGroup != "" && !se.matchesText(resource.ResourceGroup, filters.ResourceGroup, false) {
+	if filters.ResourceGroup != "" && !se.matchesFilterValue(resource.ResourceGroup, filters.ResourceGroup) {
+		return false
+	}
+
+	if filters.Subscription != "" && !se.matchesFilterValue(SubscriptionFromID(resource.ID), filters.Subscription) {
+		return false
+	}
+
+	if filters.Untagged && len(resource.Tags) > 0 {
 		return false
 	}
 
@@ -317,8 +481,8 @@ func (se *SearchEngine) matchesFilters(resource Resource, filters SearchFilters)
 	for filterKey, filterValue := range filters.Tags {
 		found := false
 		for tagKey, tagValue := range resource.Tags {
-			if se.matchesText(tagKey, filterKey, false) {
-				if filterValue == "" || se.matchesText(tagValue, filterValue, false) {
+			if se.matchesFilterValue(tagKey, filterKey) {
+				if filterValue == "" || se.matchesFilterValue(tagValue, filterValue) {
 					found = true
 					break
 				}
@@ -331,22 +495,43 @@ func (se *SearchEngine) matchesFilters(resource Resource, filters SearchFilters)
 
 	// Check exclude types
 	for _, excludeType := range filters.ExcludeTypes {
-		if se.matchesText(resource.Type, excludeType, false) {
+		if se.matchesResourceType(resource.Type, excludeType) {
 			return false
 		}
 	}
 
+	// Check excluded tags
+	for excludeKey, excludeValue := range filters.ExcludeTags {
+		for tagKey, tagValue := range resource.Tags {
+			if se.matchesFilterValue(tagKey, excludeKey) && (excludeValue == "" || se.matchesFilterValue(tagValue, excludeValue)) {
+				return false
+			}
+		}
+	}
+
 	return true
 }
 
-// searchInText searches for terms in a text string
-func (se *SearchEngine) searchInText(text string, terms []string, wildcards bool) []string {
-	matches := []string{}
-	textLower := strings.ToLower(text)
+// searchInText searches for terms in a text string. When allowFuzzy is set,
+// terms that are not a substring of text may still match as a character
+// subsequence or with a small number of typos.
+func (se *SearchEngine) searchInText(text, textLower string, terms []string, wildcards, allowFuzzy bool) []textMatch {
+	var matches []textMatch
 
 	for _, term := range terms {
-		if se.matchesText(textLower, term, wildcards) {
-			matches = append(matches, term)
+		termLower := strings.ToLower(term)
+		if wildcards && se.matchesWildcard(textLower, termLower) {
+			matches = append(matches, textMatch{Term: term})
+			continue
+		}
+		if !wildcards && strings.Contains(textLower, termLower) {
+			matches = append(matches, textMatch{Term: term, Spans: substringSpans(textLower, termLower)})
+			continue
+		}
+		if allowFuzzy && !wildcards {
+			if match, ok := fuzzyMatchText(text, textLower, term); ok {
+				matches = append(matches, match)
+			}
 		}
 	}
 
@@ -365,8 +550,26 @@ func (se *SearchEngine) matchesText(text, term string, wildcards bool) bool {
 	return strings.Contains(textLower, termLower)
 }
 
+// typeAliases maps short type names used in queries to Azure resource types
+var typeAliases = map[string][]string{
+	"vm":       {"Microsoft.Compute/virtualMachines", "virtualmachine", "virtualmachines"},
+	"storage":  {"Microsoft.Storage/storageAccounts", "storageaccount", "storageaccounts"},
+	"aks":      {"Microsoft.ContainerService/managedClusters", "managedcluster", "managedclusters"},
+	"network":  {"Microsoft.Network/virtualNetworks", "virtualnetwork", "virtualnetworks"},
+	"keyvault": {"Microsoft.KeyVault/vaults", "vault", "vaults"},
+	"sql":      {"Microsoft.Sql/servers", "server", "servers"},
+	"acr":      {"Microsoft.ContainerRegistry/registries", "registry", "registries"},
+	"aci":      {"Microsoft.ContainerInstance/containerGroups", "containergroup", "containergroups"},
+	"webapp":   {"Microsoft.Web/sites", "site", "sites"},
+	"function": {"Microsoft.Web/sites", "functionapp", "functions"},
+}
+
 // matchesResourceType checks if a resource type matches a search term with type aliases
 func (se *SearchEngine) matchesResourceType(resourceType, searchTerm string) bool {
+	if exact, ok := exactValue(searchTerm); ok {
+		return strings.EqualFold(resourceType, exact)
+	}
+
 	resourceTypeLower := strings.ToLower(resourceType)
 	searchTermLower := strings.ToLower(searchTerm)
 
@@ -375,20 +578,6 @@ func (se *SearchEngine) matchesResourceType(resourceType, searchTerm string) boo
 		return true
 	}
 
-	// Handle common type aliases
-	typeAliases := map[string][]string{
-		"vm":       {"Microsoft.Compute/virtualMachines", "virtualmachine", "virtualmachines"},
-		"storage":  {"Microsoft.Storage/storageAccounts", "storageaccount", "storageaccounts"},
-		"aks":      {"Microsoft.ContainerService/managedClusters", "managedcluster", "managedclusters"},
-		"network":  {"Microsoft.Network/virtualNetworks", "virtualnetwork", "virtualnetworks"},
-		"keyvault": {"Microsoft.KeyVault/vaults", "vault", "vaults"},
-		"sql":      {"Microsoft.Sql/servers", "server", "servers"},
-		"acr":      {"Microsoft.ContainerRegistry/registries", "registry", "registries"},
-		"aci":      {"Microsoft.ContainerInstance/containerGroups", "containergroup", "containergroups"},
-		"webapp":   {"Microsoft.Web/sites", "site", "sites"},
-		"function": {"Microsoft.Web/sites", "functionapp", "functions"},
-	}
-
 	// Check if search term matches any aliases
 	if aliases, exists := typeAliases[searchTermLower]; exists {
 		for _, alias := range aliases {
@@ -477,6 +666,36 @@ func (se *SearchEngine) calculateScore(matchType, matchTerm, fullText string) in
 	return baseScore
 }
 
+// scoreMatch scores a term match against lowercased text, ranking substring
+// matches above subsequence matches and those above typo-tolerant matches
+func (se *SearchEngine) scoreMatch(matchType string, match textMatch, textLower string) int {
+	score := se.calculateScore(matchType, match.Term, textLower)
+	if !match.Fuzzy {
+		return score
+	}
+
+	if match.Typos > 0 {
+		score -= typoPenalty + perTypoPenalty*(match.Typos-1)
+	} else {
+		score -= fuzzyPenalty
+		score += min(match.Quality, fuzzyPenalty/2) // character-level quality: adjacency, word starts, gaps
+	}
+
+	if score < 1 {
+		score = 1
+	}
+	return score
+}
+
+// shiftSpans offsets spans by n bytes, e.g. to account for a "key=" prefix in MatchValue
+func shiftSpans(spans []MatchSpan, n int) []MatchSpan {
+	for i := range spans {
+		spans[i].Start += n
+		spans[i].End += n
+	}
+	return spans
+}
+
 // GetSuggestions provides search suggestions based on available resources
 func (se *SearchEngine) GetSuggestions(partial string) []string {
 	suggestions := make(map[string]bool)
@@ -486,30 +705,28 @@ func (se *SearchEngine) GetSuggestions(partial string) []string {
 		return []string{}
 	}
 
-	for _, resource := range se.resources {
+	for _, doc := range se.index.all() {
+		resource := doc.resource
+
 		// Suggest resource names
-		if strings.HasPrefix(strings.ToLower(resource.Name), partialLower) {
+		if strings.HasPrefix(doc.nameLower, partialLower) {
 			suggestions[resource.Name] = true
 		}
 
 		// Suggest locations
-		if strings.HasPrefix(strings.ToLower(resource.Location), partialLower) {
+		if strings.HasPrefix(doc.locLower, partialLower) {
 			suggestions[resource.Location] = true
 		}
 
 		// Suggest resource types (simplified)
-		typeParts := strings.Split(resource.Type, "/")
-		if len(typeParts) > 1 {
-			simpleType := strings.ToLower(typeParts[len(typeParts)-1])
-			if strings.HasPrefix(simpleType, partialLower) {
-				suggestions[simpleType] = true
-			}
+		if doc.typeShort != "" && strings.HasPrefix(doc.typeShort, partialLower) {
+			suggestions[doc.typeShort] = true
 		}
 
 		// Suggest tag keys
-		for tagKey := range resource.Tags {
-			if strings.HasPrefix(strings.ToLower(tagKey), partialLower) {
-				suggestions[tagKey] = true
+		for _, tag := range doc.tags {
+			if strings.HasPrefix(tag.keyLower, partialLower) {
+				suggestions[tag.key] = true
 			}
 		}
 	}
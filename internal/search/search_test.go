@@ -1,6 +1,8 @@
 package search
 
 import (
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -282,3 +284,275 @@ func TestSearchEngine_Scoring(t *testing.T) {
 		}
 	})
 }
+
+func TestSearchEngine_FuzzySearch(t *testing.T) {
+	engine := NewSearchEngine()
+
+	resources := []Resource{
+		{
+			ID:            "1",
+			Name:          "payments-api",
+			Type:          "Microsoft.Web/sites",
+			Location:      "westeurope",
+			ResourceGroup: "rg-payments",
+		},
+		{
+			ID:            "2",
+			Name:          "orders-api",
+			Type:          "Microsoft.Web/sites",
+			Location:      "westeurope",
+			ResourceGroup: "rg-orders",
+		},
+	}
+
+	engine.SetResources(resources)
+
+	t.Run("Subsequence match", func(t *testing.T) {
+		results, err := engine.Search("pymnts-api")
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+
+		if len(results) == 0 || results[0].ResourceName != "payments-api" {
+			t.Fatalf("Expected payments-api as first result, got %v", results)
+		}
+		if len(results[0].MatchSpans) == 0 {
+			t.Error("Expected match spans for fuzzy match")
+		}
+	})
+
+	t.Run("Typo match", func(t *testing.T) {
+		results, err := engine.Search("paymnets")
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+
+		if len(results) == 0 || results[0].ResourceName != "payments-api" {
+			t.Fatalf("Expected payments-api as first result, got %v", results)
+		}
+		for _, result := range results {
+			if result.ResourceName == "orders-api" {
+				t.Error("Typo search should not match orders-api")
+			}
+		}
+	})
+
+	t.Run("Substring matches outrank fuzzy matches", func(t *testing.T) {
+		engine.SetResources(append(resources, Resource{ID: "3", Name: "pay-mnts-api-old"}))
+
+		results, err := engine.Search("payments")
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+
+		if len(results) == 0 || results[0].ResourceName != "payments-api" {
+			t.Fatalf("Expected payments-api as first result, got %v", results)
+		}
+		engine.SetResources(resources)
+	})
+
+	t.Run("Match spans cover substring", func(t *testing.T) {
+		results, err := engine.Search("orders")
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+
+		if len(results) == 0 {
+			t.Fatal("Expected search results")
+		}
+		spans := results[0].MatchSpans
+		if len(spans) != 1 || spans[0].Start != 0 || spans[0].End != len("orders") {
+			t.Errorf("Expected a single span [0,6), got %v", spans)
+		}
+	})
+
+	t.Run("Recently accessed resources win ties", func(t *testing.T) {
+		engine.RecordAccess("2")
+
+		results, err := engine.Search("api")
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+
+		if len(results) < 2 || results[0].ResourceID != "2" {
+			t.Errorf("Expected recently accessed orders-api first, got %v", results)
+		}
+	})
+}
+
+func TestSearchEngine_UntaggedFilter(t *testing.T) {
+	engine := NewSearchEngine()
+	engine.SetResources([]Resource{
+		{ID: "1", Name: "sttagged", Type: "Microsoft.Storage/storageAccounts", Tags: map[string]string{"env": "prod"}},
+		{ID: "2", Name: "stuntagged", Type: "Microsoft.Storage/storageAccounts"},
+		{ID: "3", Name: "vm-untagged", Type: "Microsoft.Compute/virtualMachines"},
+	})
+
+	results, err := engine.Search("type:storage tag:none")
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+
+	if len(results) != 1 || results[0].ResourceID != "2" {
+		t.Errorf("Expected only the untagged storage account, got %v", results)
+	}
+}
+
+func TestSearchEngine_Facets(t *testing.T) {
+	engine := NewSearchEngine()
+	engine.SetResources([]Resource{
+		{ID: "/subscriptions/sub-a/resourceGroups/rg-web/providers/Microsoft.Web/sites/orders-api", Name: "orders-api", Type: "Microsoft.Web/sites", Location: "westeurope", ResourceGroup: "rg-web", Tags: map[string]string{"env": "prod"}},
+		{ID: "/subscriptions/sub-a/resourceGroups/rg-web/providers/Microsoft.Web/sites/billing-api", Name: "billing-api", Type: "Microsoft.Web/sites", Location: "westeurope", ResourceGroup: "rg-web", Tags: map[string]string{"env": "dev"}},
+		{ID: "/subscriptions/sub-b/resourceGroups/rg-vm/providers/Microsoft.Compute/virtualMachines/search-api-vm", Name: "search-api-vm", Type: "Microsoft.Compute/virtualMachines", Location: "northeurope", ResourceGroup: "rg-vm", Tags: map[string]string{"env": "prod"}},
+		{ID: "/subscriptions/sub-b/resourceGroups/rg-vm/providers/Microsoft.Compute/virtualMachines/jumpbox", Name: "jumpbox", Type: "Microsoft.Compute/virtualMachines", Location: "northeurope", ResourceGroup: "rg-vm"},
+	})
+
+	results, err := engine.Search("*-api*")
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+
+	groups := ComputeFacets(results)
+	counts := make(map[string]map[string]int)
+	for _, group := range groups {
+		counts[group.Field] = make(map[string]int)
+		for _, facet := range group.Facets {
+			counts[group.Field][facet.Value] = facet.Count
+		}
+	}
+
+	if counts[FacetType]["Microsoft.Web/sites"] != 2 || counts[FacetType]["Microsoft.Compute/virtualMachines"] != 1 {
+		t.Errorf("Unexpected type facets: %v", counts[FacetType])
+	}
+	if counts[FacetSubscription]["sub-a"] != 2 || counts[FacetSubscription]["sub-b"] != 1 {
+		t.Errorf("Unexpected subscription facets: %v", counts[FacetSubscription])
+	}
+	if counts[FacetTag]["env=prod"] != 2 || counts[FacetTag]["env=dev"] != 1 {
+		t.Errorf("Unexpected tag facets: %v", counts[FacetTag])
+	}
+	if groups[0].Field != FacetType || groups[0].Facets[0].Value != "Microsoft.Web/sites" {
+		t.Errorf("Expected facets ordered by field and count, got %v", groups[0])
+	}
+
+	// Selecting a facet narrows the query down
+	for _, facet := range []Facet{
+		{Field: FacetType, Value: "Microsoft.Web/sites"},
+		{Field: FacetSubscription, Value: "sub-a"},
+		{Field: FacetTag, Value: "env=prod"},
+	} {
+		query := "*-api* " + FacetFilter(facet)
+		narrowed, err := engine.Search(query)
+		if err != nil {
+			t.Fatalf("Search(%q) failed: %v", query, err)
+		}
+		want := counts[facet.Field][facet.Value]
+		if len(narrowed) != want {
+			t.Errorf("Search(%q) returned %d results, want %d", query, len(narrowed), want)
+		}
+	}
+}
+
+func TestSearchEngine_ExactFilters(t *testing.T) {
+	engine := NewSearchEngine()
+	engine.SetResources([]Resource{
+		{ID: "1", Name: "web", Type: "Microsoft.Web/sites", ResourceGroup: "rg-web", Tags: map[string]string{"env": "prod"}},
+		{ID: "2", Name: "web-2", Type: "Microsoft.Web/sites/slots", ResourceGroup: "rg-web-2", Tags: map[string]string{"env": "prod-eu"}},
+		{ID: "3", Name: "app", Type: "Microsoft.Web/sites", ResourceGroup: "RG Web Team", Tags: map[string]string{"environment": "prod"}},
+	})
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"rg:rg-web", []string{"1", "2"}},
+		{FacetFilter(Facet{Field: FacetResourceGroup, Value: "rg-web"}), []string{"1"}},
+		{FacetFilter(Facet{Field: FacetResourceGroup, Value: "RG Web Team"}), []string{"3"}},
+		{FacetFilter(Facet{Field: FacetType, Value: "microsoft.web/sites"}), []string{"1", "3"}},
+		{FacetFilter(Facet{Field: FacetTag, Value: "env=prod"}), []string{"1"}},
+		{`web NOT tag:"env=prod"`, []string{"2", "3"}},
+	}
+	for _, tt := range tests {
+		results, err := engine.Search(tt.query)
+		if err != nil {
+			t.Fatalf("Search(%q) failed: %v", tt.query, err)
+		}
+		seen := make(map[string]bool)
+		var got []string
+		for _, result := range results {
+			if !seen[result.ResourceID] {
+				seen[result.ResourceID] = true
+				got = append(got, result.ResourceID)
+			}
+		}
+		slices.Sort(got)
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+
+	query := `*-api* rg:"RG Web Team"`
+	if !HasFilter(query, FacetFilter(Facet{Field: FacetResourceGroup, Value: "rg web team"})) {
+		t.Errorf("HasFilter(%q) should find the same filter in another case", query)
+	}
+	if HasFilter(query, FacetFilter(Facet{Field: FacetResourceGroup, Value: "RG Web"})) {
+		t.Errorf("HasFilter(%q) should not match a shorter value", query)
+	}
+}
+
+func TestSubscriptionFromID(t *testing.T) {
+	if got := SubscriptionFromID("/subscriptions/1234/resourceGroups/rg/providers/x/y"); got != "1234" {
+		t.Errorf("Expected subscription 1234, got %q", got)
+	}
+	if got := SubscriptionFromID("not-an-id"); got != "" {
+		t.Errorf("Expected no subscription, got %q", got)
+	}
+}
+
+func TestSearchEngine_NegatedFilters(t *testing.T) {
+	engine := NewSearchEngine()
+	engine.SetResources([]Resource{
+		{ID: "1", Name: "vm-owned", Type: "Microsoft.Compute/virtualMachines", Location: "westeurope", Tags: map[string]string{"owner": "alice"}},
+		{ID: "2", Name: "vm-orphan", Type: "Microsoft.Compute/virtualMachines", Location: "westeurope", Tags: map[string]string{"env": "dev"}},
+		{ID: "3", Name: "vm-us", Type: "Microsoft.Compute/virtualMachines", Location: "eastus"},
+		{ID: "4", Name: "st-orphan", Type: "Microsoft.Storage/storageAccounts", Location: "westeurope"},
+	})
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"type:vm location:westeurope NOT tag:owner", []string{"2"}},
+		{"location:westeurope -tag:owner NOT type:vm", []string{"4"}},
+		{"type:vm NOT tag:env=dev", []string{"1", "3"}},
+	}
+
+	for _, tt := range tests {
+		results, err := engine.Search(tt.query)
+		if err != nil {
+			t.Fatalf("Search(%q) failed: %v", tt.query, err)
+		}
+		var got []string
+		for _, result := range results {
+			got = append(got, result.ResourceID)
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestValidateQuery(t *testing.T) {
+	valid := []string{"type:vm location:westeurope NOT tag:owner", "*-api* sub:1234", "payments", "-type:storage"}
+	for _, query := range valid {
+		if err := ValidateQuery(query); err != nil {
+			t.Errorf("ValidateQuery(%q) returned %v", query, err)
+		}
+	}
+
+	invalid := []string{"", "owner:none", "NOT location:eastus", "type:"}
+	for _, query := range invalid {
+		if err := ValidateQuery(query); err == nil {
+			t.Errorf("Expected ValidateQuery(%q) to fail", query)
+		}
+	}
+}
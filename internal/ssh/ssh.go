@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/olafkfreund/azure-tui/internal/azcli"
 	"github.com/olafkfreund/azure-tui/internal/azure/vm"
 	"golang.org/x/crypto/ssh"
 )
@@ -245,7 +246,7 @@ func (sm *SSHManager) StartInteractiveSession(ctx context.Context, host, usernam
 // ConnectViaBas tion connects to a VM through Azure Bastion
 func (sm *SSHManager) ConnectViaBastion(ctx context.Context, resourceGroupName, bastionName, vmName, username string) error {
 	// Use Azure CLI for Bastion connections
-	cmd := exec.CommandContext(ctx, "az", "network", "bastion", "ssh",
+	cmd := azcli.CommandContext(ctx, "network", "bastion", "ssh",
 		"--resource-group", resourceGroupName,
 		"--name", bastionName,
 		"--target-resource-id", vmName,
@@ -14,6 +14,7 @@ import (
 	"github.com/charmbracelet/lipgloss"
 	"github.com/olafkfreund/azure-tui/internal/azure/tfbicep"
 	"github.com/olafkfreund/azure-tui/internal/config"
+	"github.com/olafkfreund/azure-tui/internal/theme"
 )
 
 // Additional rendering methods for TerraformTUI
@@ -21,11 +22,11 @@ import (
 func (m *TerraformTUI) renderTemplatesView() string {
 	// Clean, frameless styling for consistency
 	style := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#FAFAFA")).
+		Foreground(theme.Current().Text).
 		Padding(1, 2)
 
 	if m.activeView == ViewTemplates {
-		style = style.Foreground(lipgloss.Color("#FF5F87"))
+		style = style.Foreground(theme.Current().Accent)
 	}
 
 	return style.Render(m.templates.View())
@@ -34,11 +35,11 @@ func (m *TerraformTUI) renderTemplatesView() string {
 func (m *TerraformTUI) renderWorkspacesView() string {
 	// Clean, frameless styling for consistency
 	style := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#FAFAFA")).
+		Foreground(theme.Current().Text).
 		Padding(1, 2)
 
 	if m.activeView == ViewWorkspaces {
-		style = style.Foreground(lipgloss.Color("#FF5F87"))
+		style = style.Foreground(theme.Current().Accent)
 	}
 
 	return style.Render(m.workspaces.View())
@@ -47,11 +48,11 @@ func (m *TerraformTUI) renderWorkspacesView() string {
 func (m *TerraformTUI) renderEditorView() string {
 	// Clean, frameless styling for consistency
 	style := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#FAFAFA")).
+		Foreground(theme.Current().Text).
 		Padding(1, 2)
 
 	if m.activeView == ViewEditor {
-		style = style.Foreground(lipgloss.Color("#FF5F87"))
+		style = style.Foreground(theme.Current().Accent)
 	}
 
 	title := fmt.Sprintf("Editor - %s", m.currentFile)
@@ -72,11 +73,11 @@ func (m *TerraformTUI) renderEditorView() string {
 func (m *TerraformTUI) renderOperationsView() string {
 	// Clean, frameless styling for consistency
 	style := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#FAFAFA")).
+		Foreground(theme.Current().Text).
 		Padding(1, 2)
 
 	if m.activeView == ViewOperations {
-		style = style.Foreground(lipgloss.Color("#FF5F87"))
+		style = style.Foreground(theme.Current().Accent)
 	}
 
 	var operations []string
@@ -95,10 +96,10 @@ func (m *TerraformTUI) renderOperationsView() string {
 		for i := start; i < len(m.operations); i++ {
 			op := m.operations[i]
 			status := "✓"
-			color := lipgloss.Color("#50FA7B")
+			color := theme.Current().Success
 			if !op.Success {
 				status = "✗"
-				color = lipgloss.Color("#FF5555")
+				color = theme.Current().Error
 			}
 
 			opLine := fmt.Sprintf("%s %s (%s)",
@@ -117,11 +118,11 @@ func (m *TerraformTUI) renderOperationsView() string {
 func (m *TerraformTUI) renderStateView() string {
 	// Clean, frameless styling for consistency
 	style := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#FAFAFA")).
+		Foreground(theme.Current().Text).
 		Padding(1, 2)
 
 	if m.activeView == ViewState {
-		style = style.Foreground(lipgloss.Color("#FF5F87"))
+		style = style.Foreground(theme.Current().Accent)
 	}
 
 	var stateInfo []string
@@ -159,11 +160,11 @@ func (m *TerraformTUI) renderStateView() string {
 func (m *TerraformTUI) renderStateViewerView() string {
 	// Clean, frameless styling for consistency
 	style := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#FAFAFA")).
+		Foreground(theme.Current().Text).
 		Padding(1, 2)
 
 	if m.activeView == ViewStateViewer {
-		style = style.Foreground(lipgloss.Color("#FF5F87"))
+		style = style.Foreground(theme.Current().Accent)
 	}
 
 	var content []string
@@ -194,7 +195,7 @@ func (m *TerraformTUI) renderStateViewerView() string {
 				prefix, statusIcon, resource.Type, resource.Name, resource.Status)
 
 			if i == m.selectedResource {
-				line = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF5F87")).Render(line)
+				line = lipgloss.NewStyle().Foreground(theme.Current().Accent).Render(line)
 			}
 
 			content = append(content, line)
@@ -217,11 +218,11 @@ func (m *TerraformTUI) renderStateViewerView() string {
 func (m *TerraformTUI) renderPlanViewerView() string {
 	// Clean, frameless styling for consistency
 	style := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#FAFAFA")).
+		Foreground(theme.Current().Text).
 		Padding(1, 2)
 
 	if m.activeView == ViewPlanViewer {
-		style = style.Foreground(lipgloss.Color("#FF5F87"))
+		style = style.Foreground(theme.Current().Accent)
 	}
 
 	var content []string
@@ -278,7 +279,7 @@ func (m *TerraformTUI) renderPlanViewerView() string {
 				prefix, icon, change.Resource, change.Action)
 
 			if i == m.selectedChange {
-				line = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF5F87")).Render(line)
+				line = lipgloss.NewStyle().Foreground(theme.Current().Accent).Render(line)
 			}
 
 			content = append(content, line)
@@ -302,11 +303,11 @@ func (m *TerraformTUI) renderPlanViewerView() string {
 func (m *TerraformTUI) renderEnvManagerView() string {
 	// Clean, frameless styling for consistency
 	style := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#FAFAFA")).
+		Foreground(theme.Current().Text).
 		Padding(1, 2)
 
 	if m.activeView == ViewEnvManager {
-		style = style.Foreground(lipgloss.Color("#FF5F87"))
+		style = style.Foreground(theme.Current().Accent)
 	}
 
 	var content []string
@@ -340,7 +341,7 @@ func (m *TerraformTUI) renderEnvManagerView() string {
 				prefix, statusIcon, workspace.Name, workspace.Environment, current)
 
 			if i == m.workspaceManager.selectedIndex {
-				line = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF5F87")).Render(line)
+				line = lipgloss.NewStyle().Foreground(theme.Current().Accent).Render(line)
 			}
 
 			content = append(content, line)
@@ -365,11 +366,11 @@ func (m *TerraformTUI) renderEnvManagerView() string {
 func (m *TerraformTUI) renderVarEditorView() string {
 	// Clean, frameless styling for consistency
 	style := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#FAFAFA")).
+		Foreground(theme.Current().Text).
 		Padding(1, 2)
 
 	if m.activeView == ViewVarEditor {
-		style = style.Foreground(lipgloss.Color("#FF5F87"))
+		style = style.Foreground(theme.Current().Accent)
 	}
 
 	var content []string
@@ -401,7 +402,7 @@ func (m *TerraformTUI) renderVarEditorView() string {
 
 				line := fmt.Sprintf("%s%s = %s", prefix, name, value)
 				if i == m.variableEditor.selectedIndex {
-					line = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF5F87")).Render(line)
+					line = lipgloss.NewStyle().Foreground(theme.Current().Accent).Render(line)
 				}
 
 				content = append(content, line)
@@ -785,10 +786,10 @@ func (m *TerraformTUI) renderEnhancedProgressIndicator(progress ParseProgressMsg
 
 	// Create gradient progress bar
 	progressBar := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#50FA7B")).
+		Foreground(theme.Current().Success).
 		Render(strings.Repeat("█", filledBlocks)) +
 		lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#44475A")).
+			Foreground(theme.Current().Surface).
 			Render(strings.Repeat("░", 20-filledBlocks))
 
 	// Stage indicator with icon
@@ -806,7 +807,7 @@ func (m *TerraformTUI) renderEnhancedProgressIndicator(progress ParseProgressMsg
 
 	progressStyle := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("#6272A4")).
+		BorderForeground(theme.Current().Muted).
 		Padding(1, 2).
 		Margin(1, 0)
 
@@ -851,24 +852,24 @@ func (m *TerraformTUI) renderEnhancedError(terraformErr TerraformError) string {
 
 	switch terraformErr.Severity {
 	case "critical":
-		borderColor = lipgloss.Color("#FF5555")
-		titleColor = lipgloss.Color("#FF5555")
-		iconColor = lipgloss.Color("#FF5555")
+		borderColor = theme.Current().Error
+		titleColor = theme.Current().Error
+		iconColor = theme.Current().Error
 		severityIcon = "🚨"
 	case "high":
-		borderColor = lipgloss.Color("#FFB86C")
-		titleColor = lipgloss.Color("#FFB86C")
-		iconColor = lipgloss.Color("#FFB86C")
+		borderColor = theme.Current().Highlight
+		titleColor = theme.Current().Highlight
+		iconColor = theme.Current().Highlight
 		severityIcon = "⚠️"
 	case "medium":
-		borderColor = lipgloss.Color("#F1FA8C")
-		titleColor = lipgloss.Color("#F1FA8C")
-		iconColor = lipgloss.Color("#F1FA8C")
+		borderColor = theme.Current().Warning
+		titleColor = theme.Current().Warning
+		iconColor = theme.Current().Warning
 		severityIcon = "⚡"
 	default:
-		borderColor = lipgloss.Color("#8BE9FD")
-		titleColor = lipgloss.Color("#8BE9FD")
-		iconColor = lipgloss.Color("#8BE9FD")
+		borderColor = theme.Current().Info
+		titleColor = theme.Current().Info
+		iconColor = theme.Current().Info
 		severityIcon = "ℹ️"
 	}
 
@@ -919,26 +920,26 @@ func (m *TerraformTUI) renderEnhancedError(terraformErr TerraformError) string {
 // Enhanced variable editor with syntax highlighting
 func (m *TerraformTUI) renderEnhancedVarEditor() string {
 	baseStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#F8F8F2")).
+		Foreground(theme.Current().Subtext).
 		Padding(1, 2)
 
 	if m.activeView == ViewVarEditor {
 		baseStyle = baseStyle.Border(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.Color("#FF79C6"))
+			BorderForeground(theme.Current().Accent)
 	}
 
 	var content []string
 
 	// Header with enhanced styling
 	headerStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#50FA7B")).
+		Foreground(theme.Current().Success).
 		Bold(true)
 	content = append(content, headerStyle.Render("🔧 Interactive Variable Editor"))
 	content = append(content, "")
 
 	if len(m.variableEditor.variables) == 0 {
 		noVarsStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#6272A4")).
+			Foreground(theme.Current().Muted).
 			Italic(true)
 		content = append(content, noVarsStyle.Render("No variables loaded"))
 		content = append(content, "Press 'v' to load variables first")
@@ -946,37 +947,37 @@ func (m *TerraformTUI) renderEnhancedVarEditor() string {
 		if m.variableEditor.editMode {
 			// Edit mode with enhanced styling
 			editHeaderStyle := lipgloss.NewStyle().
-				Foreground(lipgloss.Color("#FFB86C")).
+				Foreground(theme.Current().Highlight).
 				Bold(true)
 			content = append(content, editHeaderStyle.Render("✏️ Editing Variable"))
 			content = append(content, "")
 
 			varNameStyle := lipgloss.NewStyle().
-				Foreground(lipgloss.Color("#8BE9FD")).
+				Foreground(theme.Current().Info).
 				Bold(true)
 			content = append(content, fmt.Sprintf("Variable: %s",
 				varNameStyle.Render(m.variableEditor.editingVar)))
 
 			originalStyle := lipgloss.NewStyle().
-				Foreground(lipgloss.Color("#6272A4"))
+				Foreground(theme.Current().Muted)
 			content = append(content, fmt.Sprintf("Original: %s",
 				originalStyle.Render(m.variableEditor.originalValue)))
 
 			newValueStyle := lipgloss.NewStyle().
-				Foreground(lipgloss.Color("#50FA7B")).
-				Background(lipgloss.Color("#44475A")).
+				Foreground(theme.Current().Success).
+				Background(theme.Current().Surface).
 				Padding(0, 1)
 			content = append(content, fmt.Sprintf("New Value: %s",
 				newValueStyle.Render(m.variableEditor.editingValue)))
 
 			content = append(content, "")
 			helpStyle := lipgloss.NewStyle().
-				Foreground(lipgloss.Color("#6272A4"))
+				Foreground(theme.Current().Muted)
 			content = append(content, helpStyle.Render("Enter: save | Esc: cancel | Type to edit"))
 		} else {
 			// List mode with enhanced styling
 			content = append(content, fmt.Sprintf("Variables: %s",
-				lipgloss.NewStyle().Foreground(lipgloss.Color("#F1FA8C")).Render(fmt.Sprintf("%d", len(m.variableEditor.variables)))))
+				lipgloss.NewStyle().Foreground(theme.Current().Warning).Render(fmt.Sprintf("%d", len(m.variableEditor.variables)))))
 			content = append(content, "")
 
 			// Enhanced variable list
@@ -987,25 +988,25 @@ func (m *TerraformTUI) renderEnhancedVarEditor() string {
 				var nameStyle, valueStyle, equalsStyle lipgloss.Style
 				if isSelected {
 					nameStyle = lipgloss.NewStyle().
-						Foreground(lipgloss.Color("#50FA7B")).
+						Foreground(theme.Current().Success).
 						Bold(true)
 					equalsStyle = lipgloss.NewStyle().
-						Foreground(lipgloss.Color("#FF79C6"))
+						Foreground(theme.Current().Accent)
 					valueStyle = lipgloss.NewStyle().
-						Foreground(lipgloss.Color("#F1FA8C"))
+						Foreground(theme.Current().Warning)
 				} else {
 					nameStyle = lipgloss.NewStyle().
-						Foreground(lipgloss.Color("#8BE9FD"))
+						Foreground(theme.Current().Info)
 					equalsStyle = lipgloss.NewStyle().
-						Foreground(lipgloss.Color("#6272A4"))
+						Foreground(theme.Current().Muted)
 					valueStyle = lipgloss.NewStyle().
-						Foreground(lipgloss.Color("#F8F8F2"))
+						Foreground(theme.Current().Subtext)
 				}
 
 				prefix := "  "
 				if isSelected {
 					prefix = lipgloss.NewStyle().
-						Foreground(lipgloss.Color("#FF79C6")).
+						Foreground(theme.Current().Accent).
 						Render("▶ ")
 				}
 
@@ -1021,7 +1022,7 @@ func (m *TerraformTUI) renderEnhancedVarEditor() string {
 
 			content = append(content, "")
 			helpStyle := lipgloss.NewStyle().
-				Foreground(lipgloss.Color("#6272A4"))
+				Foreground(theme.Current().Muted)
 			content = append(content, helpStyle.Render("e: edit | ↑/↓: navigate | Esc: back"))
 		}
 	}
@@ -1032,26 +1033,26 @@ func (m *TerraformTUI) renderEnhancedVarEditor() string {
 // Enhanced plan viewer with better action icons and colors
 func (m *TerraformTUI) renderEnhancedPlanViewer() string {
 	baseStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#F8F8F2")).
+		Foreground(theme.Current().Subtext).
 		Padding(1, 2)
 
 	if m.activeView == ViewPlanViewer {
 		baseStyle = baseStyle.Border(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.Color("#FF79C6"))
+			BorderForeground(theme.Current().Accent)
 	}
 
 	var content []string
 
 	// Enhanced header
 	headerStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#50FA7B")).
+		Foreground(theme.Current().Success).
 		Bold(true)
 	content = append(content, headerStyle.Render("📊 Terraform Plan Analysis"))
 	content = append(content, "")
 
 	if len(m.planViewer.changes) == 0 {
 		noChangesStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#6272A4")).
+			Foreground(theme.Current().Muted).
 			Italic(true)
 		content = append(content, noChangesStyle.Render("No plan changes found"))
 		content = append(content, "Press 'p' to load plan changes")
@@ -1063,7 +1064,7 @@ func (m *TerraformTUI) renderEnhancedPlanViewer() string {
 		}
 
 		summaryStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#F1FA8C"))
+			Foreground(theme.Current().Warning)
 		content = append(content, summaryStyle.Render(fmt.Sprintf("Changes: %d | %s", len(m.planViewer.changes), filterText)))
 		content = append(content, "")
 
@@ -1075,17 +1076,17 @@ func (m *TerraformTUI) renderEnhancedPlanViewer() string {
 
 		var summary []string
 		actionStyles := map[string]lipgloss.Style{
-			"create":  lipgloss.NewStyle().Foreground(lipgloss.Color("#50FA7B")),
-			"update":  lipgloss.NewStyle().Foreground(lipgloss.Color("#F1FA8C")),
-			"delete":  lipgloss.NewStyle().Foreground(lipgloss.Color("#FF5555")),
-			"replace": lipgloss.NewStyle().Foreground(lipgloss.Color("#FFB86C")),
+			"create":  lipgloss.NewStyle().Foreground(theme.Current().Success),
+			"update":  lipgloss.NewStyle().Foreground(theme.Current().Warning),
+			"delete":  lipgloss.NewStyle().Foreground(theme.Current().Error),
+			"replace": lipgloss.NewStyle().Foreground(theme.Current().Highlight),
 		}
 
 		for action, count := range actionCounts {
 			icon := getEnhancedActionIcon(action)
 			style := actionStyles[action]
 			if style.GetForeground() == nil {
-				style = lipgloss.NewStyle().Foreground(lipgloss.Color("#6272A4"))
+				style = lipgloss.NewStyle().Foreground(theme.Current().Muted)
 			}
 			summary = append(summary, style.Render(fmt.Sprintf("%s %d %s", icon, count, action)))
 		}
@@ -1110,23 +1111,23 @@ func (m *TerraformTUI) renderEnhancedPlanViewer() string {
 			icon := getEnhancedActionIcon(change.Action)
 			actionStyle := actionStyles[change.Action]
 			if actionStyle.GetForeground() == nil {
-				actionStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#6272A4"))
+				actionStyle = lipgloss.NewStyle().Foreground(theme.Current().Muted)
 			}
 
 			var resourceStyle lipgloss.Style
 			if isSelected {
 				resourceStyle = lipgloss.NewStyle().
-					Foreground(lipgloss.Color("#FF79C6")).
+					Foreground(theme.Current().Accent).
 					Bold(true)
 			} else {
 				resourceStyle = lipgloss.NewStyle().
-					Foreground(lipgloss.Color("#8BE9FD"))
+					Foreground(theme.Current().Info)
 			}
 
 			prefix := "  "
 			if isSelected {
 				prefix = lipgloss.NewStyle().
-					Foreground(lipgloss.Color("#FF79C6")).
+					Foreground(theme.Current().Accent).
 					Render("▶ ")
 			}
 
@@ -1135,7 +1136,7 @@ func (m *TerraformTUI) renderEnhancedPlanViewer() string {
 				prefix,
 				actionStyle.Render(icon),
 				resourceStyle.Render(change.Resource),
-				lipgloss.NewStyle().Foreground(lipgloss.Color("#6272A4")).Render(fmt.Sprintf("(%s)", change.Action)),
+				lipgloss.NewStyle().Foreground(theme.Current().Muted).Render(fmt.Sprintf("(%s)", change.Action)),
 				impactIcon)
 
 			content = append(content, line)
@@ -1143,7 +1144,7 @@ func (m *TerraformTUI) renderEnhancedPlanViewer() string {
 			// Enhanced details for selected item
 			if m.showPlanDetails && isSelected {
 				detailStyle := lipgloss.NewStyle().
-					Foreground(lipgloss.Color("#6272A4")).
+					Foreground(theme.Current().Muted).
 					MarginLeft(4)
 
 				if change.Reason != "" {
@@ -1153,7 +1154,7 @@ func (m *TerraformTUI) renderEnhancedPlanViewer() string {
 
 				if change.Sensitive {
 					sensitiveStyle := lipgloss.NewStyle().
-						Foreground(lipgloss.Color("#FF5555")).
+						Foreground(theme.Current().Error).
 						Bold(true)
 					content = append(content, detailStyle.Render(sensitiveStyle.Render("⚠️ Contains sensitive data")))
 				}
@@ -1162,7 +1163,7 @@ func (m *TerraformTUI) renderEnhancedPlanViewer() string {
 
 		content = append(content, "")
 		helpStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#6272A4"))
+			Foreground(theme.Current().Muted)
 		content = append(content, helpStyle.Render("f: filter toggle | a: approval mode | t: target resource | d: toggle details"))
 	}
 
@@ -13,6 +13,7 @@ import (
 	"github.com/charmbracelet/lipgloss"
 	"github.com/olafkfreund/azure-tui/internal/azure/tfbicep"
 	"github.com/olafkfreund/azure-tui/internal/config"
+	"github.com/olafkfreund/azure-tui/internal/theme"
 )
 
 // Integration functions for adding Terraform support to the main TUI
@@ -215,7 +216,7 @@ func (qtc *QuickTemplateCreator) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 func (qtc *QuickTemplateCreator) View() string {
 	style := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("#874BFE")).
+		BorderForeground(theme.Current().Accent).
 		Padding(1, 2).
 		Margin(1, 0)
 
@@ -261,7 +262,7 @@ func (qtc *QuickTemplateCreator) View() string {
 			lipgloss.Left,
 			lipgloss.NewStyle().Bold(true).Render("🚀 Quick Template Creation"),
 			"",
-			lipgloss.NewStyle().Foreground(lipgloss.Color("#50FA7B")).Render("✓ "+qtc.status),
+			lipgloss.NewStyle().Foreground(theme.Current().Success).Render("✓ "+qtc.status),
 			"",
 			"q: Quit",
 		)
@@ -273,7 +274,7 @@ func (qtc *QuickTemplateCreator) View() string {
 			lipgloss.Left,
 			lipgloss.NewStyle().Bold(true).Render("🚀 Quick Template Creation"),
 			"",
-			lipgloss.NewStyle().Foreground(lipgloss.Color("#FF5555")).Render("✗ Error: "+qtc.error),
+			lipgloss.NewStyle().Foreground(theme.Current().Error).Render("✗ Error: "+qtc.error),
 			"",
 			"q: Quit",
 		)
@@ -482,7 +483,7 @@ func (qdm *QuickDeployManager) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 func (qdm *QuickDeployManager) View() string {
 	style := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("#FF6B6B")).
+		BorderForeground(theme.Current().Error).
 		Padding(1, 2).
 		Margin(1, 0)
 
@@ -539,7 +540,7 @@ func (qdm *QuickDeployManager) View() string {
 			lipgloss.Left,
 			lipgloss.NewStyle().Bold(true).Render("⚡ Quick Deploy"),
 			"",
-			lipgloss.NewStyle().Foreground(lipgloss.Color("#50FA7B")).Render("✓ "+qdm.status),
+			lipgloss.NewStyle().Foreground(theme.Current().Success).Render("✓ "+qdm.status),
 			"",
 			"q: Quit",
 		)
@@ -551,7 +552,7 @@ func (qdm *QuickDeployManager) View() string {
 			lipgloss.Left,
 			lipgloss.NewStyle().Bold(true).Render("⚡ Quick Deploy"),
 			"",
-			lipgloss.NewStyle().Foreground(lipgloss.Color("#FF5555")).Render("✗ Error: "+qdm.error),
+			lipgloss.NewStyle().Foreground(theme.Current().Error).Render("✗ Error: "+qdm.error),
 			"",
 			"q: Quit",
 		)
@@ -1114,7 +1115,7 @@ func (sv *StateViewerTUI) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 func (sv *StateViewerTUI) View() string {
 	style := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("#8BE9FD")).
+		BorderForeground(theme.Current().Info).
 		Padding(1, 2).
 		Margin(1, 0)
 
@@ -1162,7 +1163,7 @@ func (sv *StateViewerTUI) View() string {
 			lipgloss.Left,
 			lipgloss.NewStyle().Bold(true).Render("📊 Terraform State Viewer"),
 			"",
-			lipgloss.NewStyle().Foreground(lipgloss.Color("#FF5555")).Render("✗ Error: "+sv.error),
+			lipgloss.NewStyle().Foreground(theme.Current().Error).Render("✗ Error: "+sv.error),
 			"",
 			"q: Quit",
 		)
@@ -1,7 +1,9 @@
 package terraform
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -9,6 +11,7 @@ import (
 	"time"
 
 	"github.com/olafkfreund/azure-tui/internal/config"
+	"github.com/olafkfreund/azure-tui/internal/jobs"
 )
 
 // TerraformConfig holds configuration for Terraform operations
@@ -128,40 +131,51 @@ func LoadTerraformConfig() (*TerraformConfig, error) {
 
 // InitWorkspace initializes a Terraform workspace
 func InitWorkspace(workspaceDir string) error {
-	cmd := exec.Command("terraform", "init")
-	cmd.Dir = workspaceDir
-	output, err := cmd.CombinedOutput()
+	return InitWorkspaceContext(context.Background(), nil, workspaceDir)
+}
+
+// InitWorkspaceContext initializes a workspace, streaming the output to out
+func InitWorkspaceContext(ctx context.Context, out io.Writer, workspaceDir string) error {
+	output, err := jobs.RunCommand(ctx, out, workspaceDir, "terraform", "init")
 	if err != nil {
-		return fmt.Errorf("terraform init failed: %v\nOutput: %s", err, string(output))
+		return fmt.Errorf("terraform init failed: %v\nOutput: %s", err, output)
 	}
 	return nil
 }
 
 // PlanWorkspace runs terraform plan in a workspace
 func PlanWorkspace(workspaceDir string, varFile string) (string, error) {
+	return PlanWorkspaceContext(context.Background(), nil, workspaceDir, varFile)
+}
+
+// PlanWorkspaceContext runs terraform plan, streaming the output to out
+func PlanWorkspaceContext(ctx context.Context, out io.Writer, workspaceDir string, varFile string) (string, error) {
 	args := []string{"plan", "-detailed-exitcode"}
 	if varFile != "" {
 		args = append(args, "-var-file", varFile)
 	}
 
-	cmd := exec.Command("terraform", args...)
-	cmd.Dir = workspaceDir
-	output, err := cmd.CombinedOutput()
+	output, err := jobs.RunCommand(ctx, out, workspaceDir, "terraform", args...)
 
 	if err != nil {
 		if exitError, ok := err.(*exec.ExitError); ok {
 			// Exit code 2 means there are changes to apply
 			if exitError.ExitCode() == 2 {
-				return string(output), nil
+				return output, nil
 			}
 		}
-		return string(output), fmt.Errorf("terraform plan failed: %v", err)
+		return output, fmt.Errorf("terraform plan failed: %v", err)
 	}
-	return string(output), nil
+	return output, nil
 }
 
 // ApplyWorkspace applies Terraform changes in a workspace
 func ApplyWorkspace(workspaceDir string, varFile string, autoApprove bool) (string, error) {
+	return ApplyWorkspaceContext(context.Background(), nil, workspaceDir, varFile, autoApprove)
+}
+
+// ApplyWorkspaceContext applies Terraform changes, streaming the output to out
+func ApplyWorkspaceContext(ctx context.Context, out io.Writer, workspaceDir string, varFile string, autoApprove bool) (string, error) {
 	args := []string{"apply"}
 	if autoApprove {
 		args = append(args, "-auto-approve")
@@ -170,17 +184,20 @@ func ApplyWorkspace(workspaceDir string, varFile string, autoApprove bool) (stri
 		args = append(args, "-var-file", varFile)
 	}
 
-	cmd := exec.Command("terraform", args...)
-	cmd.Dir = workspaceDir
-	output, err := cmd.CombinedOutput()
+	output, err := jobs.RunCommand(ctx, out, workspaceDir, "terraform", args...)
 	if err != nil {
-		return string(output), fmt.Errorf("terraform apply failed: %v", err)
+		return output, fmt.Errorf("terraform apply failed: %v", err)
 	}
-	return string(output), nil
+	return output, nil
 }
 
 // DestroyWorkspace destroys Terraform-managed infrastructure
 func DestroyWorkspace(workspaceDir string, varFile string, autoApprove bool) (string, error) {
+	return DestroyWorkspaceContext(context.Background(), nil, workspaceDir, varFile, autoApprove)
+}
+
+// DestroyWorkspaceContext destroys Terraform-managed infrastructure, streaming the output to out
+func DestroyWorkspaceContext(ctx context.Context, out io.Writer, workspaceDir string, varFile string, autoApprove bool) (string, error) {
 	args := []string{"destroy"}
 	if autoApprove {
 		args = append(args, "-auto-approve")
@@ -189,13 +206,11 @@ func DestroyWorkspace(workspaceDir string, varFile string, autoApprove bool) (st
 		args = append(args, "-var-file", varFile)
 	}
 
-	cmd := exec.Command("terraform", args...)
-	cmd.Dir = workspaceDir
-	output, err := cmd.CombinedOutput()
+	output, err := jobs.RunCommand(ctx, out, workspaceDir, "terraform", args...)
 	if err != nil {
-		return string(output), fmt.Errorf("terraform destroy failed: %v", err)
+		return output, fmt.Errorf("terraform destroy failed: %v", err)
 	}
-	return string(output), nil
+	return output, nil
 }
 
 // ListTemplates lists available Terraform templates
@@ -10,6 +10,7 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/olafkfreund/azure-tui/internal/azure/tfbicep"
+	"github.com/olafkfreund/azure-tui/internal/theme"
 )
 
 // Enhanced State Management Types
@@ -841,13 +842,13 @@ func (m *TerraformTUI) prevView() {
 func (m *TerraformTUI) renderHeader() string {
 	title := lipgloss.NewStyle().
 		Bold(true).
-		Foreground(lipgloss.Color("#FAFAFA")).
-		Background(lipgloss.Color("#7D56F4")).
+		Foreground(theme.Current().Text).
+		Background(theme.Current().Accent).
 		Padding(0, 1).
 		Render("Azure TUI - Terraform Manager")
 
 	status := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#626262")).
+		Foreground(theme.Current().Muted).
 		Render(fmt.Sprintf("Status: %s | View: %s", m.status, m.activeView))
 
 	return lipgloss.JoinVertical(lipgloss.Left, title, status)
@@ -856,14 +857,14 @@ func (m *TerraformTUI) renderHeader() string {
 func (m *TerraformTUI) renderFooter() string {
 	if m.errorMsg != "" {
 		errorStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#FF5F87")).
+			Foreground(theme.Current().Accent).
 			Bold(true)
 		return errorStyle.Render(fmt.Sprintf("Error: %s", m.errorMsg))
 	}
 
 	helpText := "Tab: Switch views • Ctrl+P: Plan • Ctrl+A: Apply • Ctrl+D: Destroy • Q: Quit • ?: Help"
 	return lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#626262")).
+		Foreground(theme.Current().Muted).
 		Render(helpText)
 }
 
@@ -872,7 +873,7 @@ func (m *TerraformTUI) renderWithPopup() string {
 
 	// Clean, frameless popup style for consistency with main help popup
 	popup := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#FAFAFA")).
+		Foreground(theme.Current().Text).
 		Padding(1, 2).
 		Width(60).
 		Height(20).
@@ -0,0 +1,226 @@
+package theme
+
+import (
+	"os"
+	"strings"
+	"sync/atomic"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"github.com/rivo/uniseg"
+)
+
+// Plain mode renders without colors, emoji and box drawing characters for
+// dumb terminals, serial consoles and screen readers. Icons that carry
+// meaning become text labels, decorative ones are dropped.
+var (
+	plain        atomic.Bool
+	colorProfile termenv.Profile // Restored when plain mode is turned off
+)
+
+// Plain reports whether plain mode is on
+func Plain() bool {
+	return plain.Load()
+}
+
+// SetPlain turns plain mode on or off. Colors are switched off with it so
+// that nothing is signaled by color alone.
+func SetPlain(on bool) {
+	if on == plain.Load() {
+		return
+	}
+	plain.Store(on)
+	if on {
+		colorProfile = lipgloss.ColorProfile()
+		lipgloss.SetColorProfile(termenv.Ascii)
+	} else {
+		lipgloss.SetColorProfile(colorProfile)
+	}
+}
+
+// PlainFromEnv reports whether the environment asks for plain output:
+// TERM=dumb or NO_COLOR set to any value (https://no-color.org)
+func PlainFromEnv() bool {
+	return os.Getenv("TERM") == "dumb" || os.Getenv("NO_COLOR") != ""
+}
+
+// Icon returns icon, or label in plain mode
+func Icon(icon, label string) string {
+	if Plain() {
+		return label
+	}
+	return icon
+}
+
+// plainLabels replaces icons that carry meaning, mostly states
+var plainLabels = map[rune]string{
+	'✅': "[OK]",
+	'✓': "[OK]",
+	'✗': "[FAIL]",
+	'❌': "[ERROR]",
+	'⚠': "[WARN]",
+	'❓': "[?]",
+	'❔': "[?]",
+	'ℹ': "[INFO]",
+	'💡': "[TIP]",
+	'🚨': "[ALERT]",
+	'🟢': "[+]",
+	'🔴': "[-]",
+	'🟡': "[~]",
+	'🟠': "[!]",
+	'🔵': "[i]",
+	'⏳': "[WAIT]",
+	'🔄': "[SYNC]",
+	'⏹': "[STOP]",
+	'🔒': "[LOCKED]",
+	'🤖': "[AI]",
+}
+
+// plainSymbols replaces symbols that have an ASCII look-alike. Most keep
+// their width so columns stay where they are.
+var plainSymbols = map[rune]string{
+	'•': "*",
+	'…': "...",
+	'±': "+/-",
+	'×': "x",
+	'✕': "x",
+	'←': "<",
+	'→': ">",
+	'↑': "^",
+	'↓': "v",
+	'↔': "<>",
+	'⇄': "<>",
+	'▶': ">",
+	'►': ">",
+	'❯': ">",
+	'▼': "v",
+	'≡': "=",
+	'─': "-",
+	'━': "-",
+	'═': "=",
+	'│': "|",
+	'┃': "|",
+	'║': "|",
+	'▁': "_",
+	'▂': ".",
+	'▃': ",",
+	'▄': "-",
+	'▅': "=",
+	'▆': "+",
+	'▇': "*",
+	'█': "#",
+	'░': ".",
+	'▒': ":",
+	'▓': "%",
+}
+
+// Text converts s for plain mode: meaningful icons become labels, symbols
+// become ASCII and the remaining emoji are dropped along with the space
+// that follows them. Outside plain mode s is returned unchanged.
+func Text(s string) string {
+	if !Plain() {
+		return s
+	}
+
+	var b strings.Builder
+	b.Grow(len(s))
+	dropSpace := false
+	for _, r := range s {
+		if r == 0xfe0f || r == 0x200d {
+			// Emoji presentation selector and joiner
+			continue
+		}
+		if dropSpace {
+			dropSpace = false
+			if r == ' ' {
+				continue
+			}
+		}
+		switch {
+		case r < 0x80:
+			b.WriteRune(r)
+		case plainLabels[r] != "":
+			b.WriteString(plainLabels[r])
+		case plainSymbols[r] != "":
+			b.WriteString(plainSymbols[r])
+		case r >= 0x2500 && r <= 0x257f:
+			// Corners and junctions of box drawing
+			b.WriteByte('+')
+		case isIcon(r):
+			dropSpace = true
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// Screen converts what is left of a rendered screen for plain mode, mostly
+// borders, without changing the width of anything: the layout has already
+// been measured. Content should go through Text before it is laid out.
+func Screen(s string) string {
+	if !Plain() {
+		return s
+	}
+
+	var b strings.Builder
+	b.Grow(len(s))
+	state := -1
+	for s != "" {
+		var cluster string
+		var width int
+		cluster, s, width, state = uniseg.FirstGraphemeClusterInString(s, state)
+		r, _ := utf8.DecodeRuneInString(cluster)
+		if r < 0x80 || width == 0 {
+			b.WriteString(cluster)
+			continue
+		}
+
+		var text string
+		switch {
+		case plainLabels[r] != "":
+			text = plainLabels[r]
+		case plainSymbols[r] != "":
+			text = plainSymbols[r]
+		case r >= 0x2500 && r <= 0x257f:
+			text = "+"
+		case isIcon(r):
+			text = ""
+		default:
+			b.WriteString(cluster)
+			continue
+		}
+
+		// A longer replacement takes the padding that follows it, or is
+		// cut down to the cells of the icon
+		if extra := len(text) - width; extra > 0 {
+			if padding := strings.Repeat(" ", extra); strings.HasPrefix(s, padding) {
+				s = s[extra:]
+			} else {
+				text = strings.Trim(text, "[]")
+				text = text[:min(width, len(text))]
+			}
+		}
+		b.WriteString(text)
+		b.WriteString(strings.Repeat(" ", max(0, width-len(text))))
+	}
+	return b.String()
+}
+
+// isIcon reports whether r is a pictograph or dingbat rather than text
+func isIcon(r rune) bool {
+	switch {
+	case r >= 0x1f000 && r <= 0x1faff: // Emoji and pictographs
+		return true
+	case r >= 0x2300 && r <= 0x23ff: // Miscellaneous technical
+		return true
+	case r >= 0x2580 && r <= 0x27bf: // Blocks, shapes, symbols and dingbats
+		return true
+	case r >= 0x27c0 && r <= 0x2bff: // Arrows, shapes and symbols
+		return true
+	case r >= 0x2295 && r <= 0x22a1: // Circled and squared operators
+		return true
+	}
+	return false
+}
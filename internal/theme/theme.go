@@ -0,0 +1,223 @@
+package theme
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"github.com/charmbracelet/lipgloss"
+	"gopkg.in/yaml.v3"
+)
+
+// Theme holds the colors every view renders with. Views pick colors by role
+// rather than by value so that switching the theme restyles the whole UI.
+type Theme struct {
+	Name             string         `yaml:"name"`
+	Background       lipgloss.Color `yaml:"background"`        // Popup and panel background
+	Surface          lipgloss.Color `yaml:"surface"`           // Raised areas such as status bars and tabs
+	SurfaceHighlight lipgloss.Color `yaml:"surface_highlight"` // Selected rows and the active tab
+	Text             lipgloss.Color `yaml:"text"`              // Headings and selected text
+	Subtext          lipgloss.Color `yaml:"subtext"`           // Regular body text
+	Muted            lipgloss.Color `yaml:"muted"`             // Borders, hints and secondary details
+	Primary          lipgloss.Color `yaml:"primary"`           // Titles, focused borders and links
+	Success          lipgloss.Color `yaml:"success"`
+	Warning          lipgloss.Color `yaml:"warning"`
+	Error            lipgloss.Color `yaml:"error"`
+	Accent           lipgloss.Color `yaml:"accent"` // Resource types and secondary headings
+	Info             lipgloss.Color `yaml:"info"`   // Property keys and informational messages
+	Highlight        lipgloss.Color `yaml:"highlight"`
+}
+
+// Default is the theme used when the config does not choose one
+const Default = "dark"
+
+var builtins = map[string]Theme{
+	"dark": {
+		Name:             "dark",
+		Background:       "#282828",
+		Surface:          "#3c3836",
+		SurfaceHighlight: "#504945",
+		Text:             "#fbf1c7",
+		Subtext:          "#ebdbb2",
+		Muted:            "#a89984",
+		Primary:          "#83a598",
+		Success:          "#b8bb26",
+		Warning:          "#fabd2f",
+		Error:            "#fb4934",
+		Accent:           "#d3869b",
+		Info:             "#8ec07c",
+		Highlight:        "#fe8019",
+	},
+	"light": {
+		Name:             "light",
+		Background:       "#fbf1c7",
+		Surface:          "#ebdbb2",
+		SurfaceHighlight: "#d5c4a1",
+		Text:             "#282828",
+		Subtext:          "#3c3836",
+		Muted:            "#7c6f64",
+		Primary:          "#076678",
+		Success:          "#79740e",
+		Warning:          "#b57614",
+		Error:            "#9d0006",
+		Accent:           "#8f3f71",
+		Info:             "#427b58",
+		Highlight:        "#af3a03",
+	},
+	"solarized": {
+		Name:             "solarized",
+		Background:       "#002b36",
+		Surface:          "#073642",
+		SurfaceHighlight: "#586e75",
+		Text:             "#fdf6e3",
+		Subtext:          "#93a1a1",
+		Muted:            "#657b83",
+		Primary:          "#268bd2",
+		Success:          "#859900",
+		Warning:          "#b58900",
+		Error:            "#dc322f",
+		Accent:           "#d33682",
+		Info:             "#2aa198",
+		Highlight:        "#cb4b16",
+	},
+	"high-contrast": {
+		Name:             "high-contrast",
+		Background:       "#000000",
+		Surface:          "#1c1c1c",
+		SurfaceHighlight: "#3a3a3a",
+		Text:             "#ffffff",
+		Subtext:          "#ffffff",
+		Muted:            "#c0c0c0",
+		Primary:          "#00afff",
+		Success:          "#00ff00",
+		Warning:          "#ffff00",
+		Error:            "#ff0000",
+		Accent:           "#ff00ff",
+		Info:             "#00ffff",
+		Highlight:        "#ff8700",
+	},
+}
+
+// aliases maps older color_scheme values to built-in themes
+var aliases = map[string]string{
+	"":      Default,
+	"azure": Default,
+}
+
+var current atomic.Pointer[Theme]
+
+func init() {
+	t := builtins[Default]
+	current.Store(&t)
+}
+
+// Current returns the active theme
+func Current() *Theme {
+	return current.Load()
+}
+
+// Set makes t the active theme
+func Set(t Theme) {
+	current.Store(&t)
+}
+
+// Builtin returns the built-in theme with the given name
+func Builtin(name string) (Theme, bool) {
+	if alias, ok := aliases[name]; ok {
+		name = alias
+	}
+	t, ok := builtins[name]
+	return t, ok
+}
+
+// Names returns the built-in theme names followed by the user themes found
+// in the themes directory
+func Names() []string {
+	names := []string{"dark", "light", "solarized", "high-contrast"}
+
+	entries, err := os.ReadDir(Dir())
+	if err != nil {
+		return names
+	}
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		name := strings.TrimSuffix(entry.Name(), ext)
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Dir is where user themes are looked up by name
+func Dir() string {
+	return filepath.Join(os.Getenv("HOME"), ".config", "azure-tui", "themes")
+}
+
+// Load resolves a color_scheme value: a built-in theme name, the name of a
+// file in Dir() without its extension, or a path to a YAML theme file
+func Load(name string) (Theme, error) {
+	if t, ok := Builtin(name); ok {
+		return t, nil
+	}
+
+	path := name
+	if !strings.ContainsRune(name, filepath.Separator) && filepath.Ext(name) == "" {
+		path = filepath.Join(Dir(), name+".yaml")
+		if _, err := os.Stat(path); err != nil {
+			path = filepath.Join(Dir(), name+".yml")
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Theme{}, fmt.Errorf("unknown theme %q: %w", name, err)
+	}
+	t, err := Parse(data)
+	if err != nil {
+		return Theme{}, err
+	}
+	if t.Name == "" {
+		t.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	return t, nil
+}
+
+// themeFile is a user theme: a base theme with some colors replaced
+type themeFile struct {
+	Theme `yaml:",inline"`
+	Base  string `yaml:"base"`
+}
+
+// Parse reads a YAML theme. Colors that are not set are taken from the
+// theme named by "base", or from the default theme. The name is left empty
+// when the file does not set one.
+func Parse(data []byte) (Theme, error) {
+	var file themeFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return Theme{}, fmt.Errorf("failed to parse theme: %w", err)
+	}
+
+	base, ok := Builtin(file.Base)
+	if !ok {
+		return Theme{}, fmt.Errorf("unknown base theme %q", file.Base)
+	}
+
+	t := base
+	t.Name = ""
+	overlay := reflect.ValueOf(file.Theme)
+	target := reflect.ValueOf(&t).Elem()
+	for i := 0; i < overlay.NumField(); i++ {
+		if !overlay.Field(i).IsZero() {
+			target.Field(i).Set(overlay.Field(i))
+		}
+	}
+	return t, nil
+}
@@ -0,0 +1,160 @@
+package theme
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestBuiltinsSetEveryColor(t *testing.T) {
+	for _, name := range []string{"dark", "light", "solarized", "high-contrast"} {
+		th, ok := Builtin(name)
+		if !ok {
+			t.Fatalf("missing built-in theme %s", name)
+		}
+		v := reflect.ValueOf(th)
+		for i := 0; i < v.NumField(); i++ {
+			if v.Field(i).IsZero() {
+				t.Errorf("theme %s: %s is not set", name, v.Type().Field(i).Name)
+			}
+		}
+	}
+
+	for _, alias := range []string{"", "azure"} {
+		if th, ok := Builtin(alias); !ok || th.Name != Default {
+			t.Errorf("Expected %q to select the default theme", alias)
+		}
+	}
+}
+
+func TestParseOverlaysBase(t *testing.T) {
+	th, err := Parse([]byte("base: light\nprimary: \"#123456\"\n"))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	light, _ := Builtin("light")
+	if th.Primary != "#123456" {
+		t.Errorf("Expected primary to be overridden, got %s", th.Primary)
+	}
+	if th.Error != light.Error || th.Background != light.Background {
+		t.Error("Expected unset colors to come from the base theme")
+	}
+
+	if _, err := Parse([]byte("base: nope\n")); err == nil {
+		t.Error("Expected an error for an unknown base theme")
+	}
+}
+
+func TestLoadUserTheme(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	if err := os.MkdirAll(Dir(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(Dir(), "ocean.yaml"), []byte("base: solarized\nerror: \"#ff0000\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	th, err := Load("ocean")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if th.Name != "ocean" || th.Error != "#ff0000" || th.Primary != builtins["solarized"].Primary {
+		t.Errorf("Unexpected theme: %+v", th)
+	}
+
+	names := Names()
+	if names[len(names)-1] != "ocean" {
+		t.Errorf("Expected user theme to be listed, got %v", names)
+	}
+
+	if _, err := Load("missing"); err == nil {
+		t.Error("Expected an error for an unknown theme")
+	}
+}
+
+func TestSetCurrent(t *testing.T) {
+	defer Set(builtins[Default])
+
+	light, _ := Builtin("light")
+	Set(light)
+	if Current().Name != "light" {
+		t.Errorf("Expected light theme to be current, got %s", Current().Name)
+	}
+}
+
+func TestPlainText(t *testing.T) {
+	if got := Text("✅ Done"); got != "✅ Done" {
+		t.Errorf("Expected text to be unchanged outside plain mode, got %q", got)
+	}
+
+	SetPlain(true)
+	t.Cleanup(func() { SetPlain(false) })
+
+	tests := map[string]string{
+		"✅ Running":              "[OK] Running",
+		"🔴 Disabled":             "[-] Disabled",
+		"🗂️ rg1":                 "rg1",
+		"📊 Network Summary":      "Network Summary",
+		"╭──╮\n│ab│\n╰══╯":       "+--+\n|ab|\n+==+",
+		"• one … ↑ More above ↑": "* one ... ^ More above ^",
+		"Müller":                 "Müller",
+	}
+	for in, want := range tests {
+		if got := Text(in); got != want {
+			t.Errorf("Text(%q) = %q, want %q", in, got, want)
+		}
+	}
+	if got := Icon("🔒", "NSG:"); got != "NSG:" {
+		t.Errorf("Expected the label in plain mode, got %q", got)
+	}
+}
+
+func TestPlainScreen(t *testing.T) {
+	if got := Screen("│ ✅ │"); got != "│ ✅ │" {
+		t.Errorf("Expected the screen to be unchanged outside plain mode, got %q", got)
+	}
+
+	SetPlain(true)
+	t.Cleanup(func() { SetPlain(false) })
+
+	tests := map[string]string{
+		"╭──╮\n│ab│\n╰──╯": "+--+\n|ab|\n+--+",
+		"│long tex…  │":    "|long tex...|",
+		"│long tex…│":      "|long tex.|",
+		"│✅   │":           "|[OK] |",
+		"│✅ OK│":           "|OK OK|",
+		"│🗂️ rg1│":         "|   rg1|",
+		"│Müller│":         "|Müller|",
+	}
+	for in, want := range tests {
+		got := Screen(in)
+		if got != want {
+			t.Errorf("Screen(%q) = %q, want %q", in, got, want)
+		}
+		if lipgloss.Width(got) != lipgloss.Width(in) {
+			t.Errorf("Screen(%q) changed the width from %d to %d", in, lipgloss.Width(in), lipgloss.Width(got))
+		}
+	}
+}
+
+func TestPlainFromEnv(t *testing.T) {
+	t.Setenv("TERM", "xterm-256color")
+	t.Setenv("NO_COLOR", "")
+	if PlainFromEnv() {
+		t.Error("Expected plain mode to be off for a color terminal")
+	}
+	t.Setenv("NO_COLOR", "1")
+	if !PlainFromEnv() {
+		t.Error("Expected NO_COLOR to turn plain mode on")
+	}
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("TERM", "dumb")
+	if !PlainFromEnv() {
+		t.Error("Expected TERM=dumb to turn plain mode on")
+	}
+}
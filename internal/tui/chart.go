@@ -0,0 +1,272 @@
+package tui
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/olafkfreund/azure-tui/internal/theme"
+)
+
+// ChartPoint is a value at a time. Invalid points are gaps in the line.
+type ChartPoint struct {
+	Time  time.Time
+	Value float64
+	Valid bool
+}
+
+// ChartSeries is a line in a chart
+type ChartSeries struct {
+	Name   string
+	Points []ChartPoint
+	Color  lipgloss.TerminalColor
+}
+
+// LineChart draws series as lines over a time axis. Lines are drawn with
+// braille dots, two columns and four rows per cell, or with one ASCII
+// marker per series in plain mode.
+type LineChart struct {
+	Series     []ChartSeries
+	Start, End time.Time
+	Width      int                        // Including the axis labels
+	Height     int                        // Rows of the plot, without the time axis
+	FormatY    func(value float64) string // Axis labels, "%g" by default
+	TimeLayout string                     // Time axis labels, e.g. "15:04"
+}
+
+// plainMarkers tell series apart in plain mode
+var plainMarkers = []rune{'*', '+', 'o', 'x', '#', '%'}
+
+// brailleDots are the bits of the dots of a braille cell by column and row
+var brailleDots = [2][4]rune{{0x01, 0x02, 0x04, 0x40}, {0x08, 0x10, 0x20, 0x80}}
+
+// Render draws the chart: a value axis on the left, the plot and the time
+// axis below it
+func (c LineChart) Render() string {
+	formatY := c.FormatY
+	if formatY == nil {
+		formatY = func(v float64) string { return strconv.FormatFloat(v, 'g', 4, 64) }
+	}
+
+	low, high, ok := c.bounds()
+	if !ok {
+		return lipgloss.NewStyle().Foreground(theme.Current().Muted).Render("No data points in this time range")
+	}
+
+	rows := max(2, c.Height)
+	yLabels := []string{formatY(high), formatY((low + high) / 2), formatY(low)}
+	labelWidth := 0
+	for _, label := range yLabels {
+		labelWidth = max(labelWidth, ansi.StringWidth(label))
+	}
+	cols := max(10, c.Width-labelWidth-2)
+
+	plain := theme.Plain()
+	dotsX, dotsY := cols*2, rows*4
+	if plain {
+		dotsX, dotsY = cols, rows
+	}
+
+	// Cells hold braille bits, or the marker in plain mode, and the series
+	// drawn last in them
+	cells := make([][]rune, rows)
+	owner := make([][]int, rows)
+	for r := range cells {
+		cells[r] = make([]rune, cols)
+		owner[r] = make([]int, cols)
+		for col := range owner[r] {
+			owner[r][col] = -1
+		}
+	}
+	span := c.End.Sub(c.Start)
+	toX := func(t time.Time) int {
+		if span <= 0 {
+			return 0
+		}
+		x := int(math.Round(float64(t.Sub(c.Start)) / float64(span) * float64(dotsX-1)))
+		return max(0, min(dotsX-1, x))
+	}
+	toY := func(v float64) int {
+		y := int(math.Round((high - v) / (high - low) * float64(dotsY-1)))
+		return max(0, min(dotsY-1, y))
+	}
+	plot := func(series, x, y int) {
+		row, col := y, x
+		if !plain {
+			row, col = y/4, x/2
+		}
+		if plain {
+			cells[row][col] = plainMarkers[series%len(plainMarkers)]
+		} else {
+			cells[row][col] |= brailleDots[x%2][y%4]
+		}
+		owner[row][col] = series
+	}
+
+	for i, series := range c.Series {
+		prevX, prevY, prevValid := 0, 0, false
+		for _, point := range series.Points {
+			if !point.Valid {
+				prevValid = false
+				continue
+			}
+			x, y := toX(point.Time), toY(point.Value)
+			if prevValid {
+				drawLine(prevX, prevY, x, y, func(x, y int) { plot(i, x, y) })
+			} else {
+				plot(i, x, y)
+			}
+			prevX, prevY, prevValid = x, y, true
+		}
+	}
+
+	muted := lipgloss.NewStyle().Foreground(theme.Current().Muted)
+	var b strings.Builder
+	for r := 0; r < rows; r++ {
+		label := ""
+		switch r {
+		case 0:
+			label = yLabels[0]
+		case (rows - 1) / 2:
+			if rows > 2 {
+				label = yLabels[1]
+			}
+		case rows - 1:
+			label = yLabels[2]
+		}
+		axis := theme.Icon("│", "|")
+		if label != "" {
+			axis = theme.Icon("┤", "+")
+		}
+		b.WriteString(muted.Render(strings.Repeat(" ", labelWidth-ansi.StringWidth(label)) + label + " " + axis))
+
+		// Runs of cells of the same series share one style
+		var run strings.Builder
+		runOwner := -2
+		flush := func() {
+			if run.Len() == 0 {
+				return
+			}
+			if runOwner >= 0 && c.Series[runOwner].Color != nil {
+				b.WriteString(lipgloss.NewStyle().Foreground(c.Series[runOwner].Color).Render(run.String()))
+			} else {
+				b.WriteString(run.String())
+			}
+			run.Reset()
+		}
+		for col := 0; col < cols; col++ {
+			if owner[r][col] != runOwner {
+				flush()
+				runOwner = owner[r][col]
+			}
+			switch {
+			case cells[r][col] == 0:
+				run.WriteByte(' ')
+			case plain:
+				run.WriteRune(cells[r][col])
+			default:
+				run.WriteRune(0x2800 + cells[r][col])
+			}
+		}
+		flush()
+		b.WriteByte('\n')
+	}
+
+	// Time axis with the start, middle and end of the range
+	corner, line := theme.Icon("└", "+"), theme.Icon("─", "-")
+	b.WriteString(muted.Render(strings.Repeat(" ", labelWidth+1) + corner + strings.Repeat(line, cols)))
+	b.WriteByte('\n')
+	layout := c.TimeLayout
+	if layout == "" {
+		layout = "15:04"
+	}
+	startLabel := c.Start.Local().Format(layout)
+	middleLabel := c.Start.Add(span / 2).Local().Format(layout)
+	endLabel := c.End.Local().Format(layout)
+	axis := []rune(strings.Repeat(" ", cols+1))
+	place := func(label string, at int) {
+		at = max(0, min(len(axis)-len(label), at))
+		copy(axis[at:], []rune(label))
+	}
+	place(startLabel, 0)
+	place(middleLabel, (cols+1)/2-len(middleLabel)/2)
+	place(endLabel, cols+1-len(endLabel))
+	b.WriteString(muted.Render(strings.Repeat(" ", labelWidth+1) + strings.TrimRight(string(axis), " ")))
+	return b.String()
+}
+
+// bounds returns the value range of the chart. Charts of values that are
+// never negative start at zero.
+func (c LineChart) bounds() (low, high float64, ok bool) {
+	low, high = math.Inf(1), math.Inf(-1)
+	for _, series := range c.Series {
+		for _, point := range series.Points {
+			if point.Valid {
+				low, high = math.Min(low, point.Value), math.Max(high, point.Value)
+				ok = true
+			}
+		}
+	}
+	if !ok {
+		return 0, 0, false
+	}
+	if low >= 0 {
+		low = 0
+	}
+	if high <= low {
+		high = low + 1
+	}
+	return low, niceCeiling(high), true
+}
+
+// niceCeiling rounds v up to 1, 2, 2.5 or 5 times a power of ten
+func niceCeiling(v float64) float64 {
+	if v <= 0 {
+		return v
+	}
+	magnitude := math.Pow(10, math.Floor(math.Log10(v)))
+	for _, step := range []float64{1, 2, 2.5, 5, 10} {
+		if step*magnitude >= v {
+			return step * magnitude
+		}
+	}
+	return 10 * magnitude
+}
+
+// drawLine calls plot for the dots of the line from (x0, y0) to (x1, y1)
+func drawLine(x0, y0, x1, y1 int, plot func(x, y int)) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	err := dx + dy
+	for {
+		plot(x0, y0)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			err += dx
+			y0 += sy
+		}
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
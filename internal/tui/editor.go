@@ -0,0 +1,187 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/olafkfreund/azure-tui/internal/theme"
+)
+
+// Editor is a multi-line text editor with a cursor
+type Editor struct {
+	lines    [][]rune
+	row, col int
+	offset   int // First line shown
+}
+
+// NewEditor returns an editor holding text, with the cursor at its end
+func NewEditor(text string) *Editor {
+	e := &Editor{}
+	e.SetValue(text)
+	return e
+}
+
+// Value returns the text
+func (e *Editor) Value() string {
+	lines := make([]string, len(e.lines))
+	for i, line := range e.lines {
+		lines[i] = string(line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// SetValue replaces the text and moves the cursor to its end
+func (e *Editor) SetValue(text string) {
+	e.lines = nil
+	for _, line := range strings.Split(text, "\n") {
+		e.lines = append(e.lines, []rune(line))
+	}
+	e.row = len(e.lines) - 1
+	e.col = len(e.lines[e.row])
+	e.offset = 0
+}
+
+// Cursor returns the line and column of the cursor
+func (e *Editor) Cursor() (row, col int) {
+	return e.row, e.col
+}
+
+// Lines returns the number of lines
+func (e *Editor) Lines() int {
+	return len(e.lines)
+}
+
+// Insert types text at the cursor. Newlines split the line.
+func (e *Editor) Insert(text string) {
+	for _, r := range text {
+		if r == '\n' {
+			e.Newline()
+			continue
+		}
+		line := e.lines[e.row]
+		e.lines[e.row] = append(line[:e.col:e.col], append([]rune{r}, line[e.col:]...)...)
+		e.col++
+	}
+}
+
+// Newline splits the line at the cursor, keeping its indentation
+func (e *Editor) Newline() {
+	line := e.lines[e.row]
+	indent := 0
+	for indent < e.col && (line[indent] == ' ' || line[indent] == '\t') {
+		indent++
+	}
+	rest := append(append([]rune{}, line[:indent]...), line[e.col:]...)
+	e.lines[e.row] = line[:e.col:e.col]
+	e.lines = append(e.lines[:e.row+1], append([][]rune{rest}, e.lines[e.row+1:]...)...)
+	e.row++
+	e.col = indent
+}
+
+// Backspace deletes the character before the cursor, joining lines at the
+// start of one
+func (e *Editor) Backspace() {
+	switch {
+	case e.col > 0:
+		line := e.lines[e.row]
+		e.lines[e.row] = append(line[:e.col-1:e.col-1], line[e.col:]...)
+		e.col--
+	case e.row > 0:
+		previous := e.lines[e.row-1]
+		e.col = len(previous)
+		e.lines[e.row-1] = append(previous[:len(previous):len(previous)], e.lines[e.row]...)
+		e.lines = append(e.lines[:e.row], e.lines[e.row+1:]...)
+		e.row--
+	}
+}
+
+// Delete deletes the character under the cursor, joining lines at the end
+// of one
+func (e *Editor) Delete() {
+	line := e.lines[e.row]
+	switch {
+	case e.col < len(line):
+		e.lines[e.row] = append(line[:e.col:e.col], line[e.col+1:]...)
+	case e.row < len(e.lines)-1:
+		e.lines[e.row] = append(line[:len(line):len(line)], e.lines[e.row+1]...)
+		e.lines = append(e.lines[:e.row+1], e.lines[e.row+2:]...)
+	}
+}
+
+// Move moves the cursor by lines and columns. Moving past the start or end
+// of a line wraps to the previous or next one.
+func (e *Editor) Move(rows, cols int) {
+	if rows != 0 {
+		e.row = max(0, min(len(e.lines)-1, e.row+rows))
+		e.col = min(e.col, len(e.lines[e.row]))
+	}
+	for ; cols < 0; cols++ {
+		if e.col > 0 {
+			e.col--
+		} else if e.row > 0 {
+			e.row--
+			e.col = len(e.lines[e.row])
+		}
+	}
+	for ; cols > 0; cols-- {
+		if e.col < len(e.lines[e.row]) {
+			e.col++
+		} else if e.row < len(e.lines)-1 {
+			e.row++
+			e.col = 0
+		}
+	}
+}
+
+// Home and End move the cursor to the start and end of the line
+func (e *Editor) Home() { e.col = 0 }
+func (e *Editor) End()  { e.col = len(e.lines[e.row]) }
+
+// View draws height lines of the editor with line numbers, scrolled to
+// keep the cursor in view. The cursor is only drawn when focused.
+func (e *Editor) View(width, height int, focused bool) string {
+	height = max(1, height)
+	if e.row < e.offset {
+		e.offset = e.row
+	} else if e.row >= e.offset+height {
+		e.offset = e.row - height + 1
+	}
+
+	muted := lipgloss.NewStyle().Foreground(theme.Current().Muted)
+	cursorStyle := lipgloss.NewStyle().Reverse(true)
+	textWidth := max(1, width-5)
+
+	var b strings.Builder
+	for i := e.offset; i < e.offset+height; i++ {
+		if i > e.offset {
+			b.WriteByte('\n')
+		}
+		if i >= len(e.lines) {
+			b.WriteString(muted.Render("   ~"))
+			continue
+		}
+		b.WriteString(muted.Render(fmt.Sprintf("%3d ", i+1)))
+
+		line := e.lines[i]
+		// Long lines scroll sideways to keep the cursor in view
+		start := 0
+		if i == e.row && e.col >= textWidth {
+			start = e.col - textWidth + 1
+		}
+		visible := line[min(start, len(line)):]
+		if !focused || i != e.row {
+			b.WriteString(ansi.Truncate(string(visible), textWidth, "…"))
+			continue
+		}
+		at := e.col - start
+		before := string(visible[:min(at, len(visible))])
+		under, after := " ", ""
+		if at < len(visible) {
+			under, after = string(visible[at]), string(visible[at+1:])
+		}
+		b.WriteString(before + cursorStyle.Render(under) + ansi.Truncate(after, max(0, textWidth-at-1), "…"))
+	}
+	return b.String()
+}
@@ -0,0 +1,116 @@
+package tui
+
+// Panel identifies one of the panels of the layout
+type Panel int
+
+const (
+	PanelTree Panel = iota
+	PanelDetails
+	PanelBottom
+)
+
+// Rect is a region of the screen
+type Rect struct {
+	X, Y, Width, Height int
+}
+
+// Empty reports whether nothing is drawn in the region
+func (r Rect) Empty() bool {
+	return r.Width <= 0 || r.Height <= 0
+}
+
+// Contains reports whether the point is inside the region
+func (r Rect) Contains(x, y int) bool {
+	return x >= r.X && x < r.X+r.Width && y >= r.Y && y < r.Y+r.Height
+}
+
+// Sizes that keep the panels usable
+const (
+	MinTreeWidth        = 20
+	MinDetailsWidth     = 30
+	MinBottomHeight     = 4
+	MinPanelsHeight     = 10
+	DefaultTreeRatio    = 1.0 / 3
+	DefaultBottomHeight = 10
+)
+
+// Layout splits the screen between the resource tree, the details panel and
+// an optional bottom panel. Zooming gives the whole screen to the focused
+// panel.
+type Layout struct {
+	TreeRatio    float64 // Share of the width given to the tree
+	ShowBottom   bool
+	BottomHeight int
+	Zoomed       bool
+}
+
+func NewLayout() Layout {
+	return Layout{
+		TreeRatio:    DefaultTreeRatio,
+		BottomHeight: DefaultBottomHeight,
+	}
+}
+
+// TreeWidth returns the width of the tree on a screen of the given width
+func (l Layout) TreeWidth(width int) int {
+	ratio := l.TreeRatio
+	if ratio <= 0 || ratio >= 1 {
+		ratio = DefaultTreeRatio
+	}
+	// The details panel wins on narrow screens
+	return max(MinTreeWidth, min(int(float64(width)*ratio), width-MinDetailsWidth))
+}
+
+// SetTreeWidth changes the ratio so the tree is treeWidth columns wide
+func (l *Layout) SetTreeWidth(treeWidth, width int) {
+	if width <= 0 {
+		return
+	}
+	treeWidth = max(MinTreeWidth, min(treeWidth, width-MinDetailsWidth))
+	l.TreeRatio = float64(treeWidth) / float64(width)
+}
+
+// bottomHeight returns the height of the bottom panel on a screen of the
+// given height, 0 when it is hidden
+func (l Layout) bottomHeight(height int) int {
+	if !l.ShowBottom {
+		return 0
+	}
+	bottomHeight := l.BottomHeight
+	if bottomHeight <= 0 {
+		bottomHeight = DefaultBottomHeight
+	}
+	return max(MinBottomHeight, min(bottomHeight, height-MinPanelsHeight))
+}
+
+// ResizeBottom changes the height of the bottom panel by delta rows
+func (l *Layout) ResizeBottom(delta, height int) {
+	l.BottomHeight = max(MinBottomHeight, min(l.bottomHeight(height)+delta, height-MinPanelsHeight))
+}
+
+// Split returns the regions of the panels on a screen of width x height.
+// Panels that aren't shown get an empty region.
+func (l Layout) Split(width, height int, focus Panel) (tree, details, bottom Rect) {
+	full := Rect{Width: width, Height: height}
+	if l.Zoomed {
+		switch {
+		case focus == PanelTree:
+			return full, Rect{}, Rect{}
+		case focus == PanelDetails:
+			return Rect{}, full, Rect{}
+		case focus == PanelBottom && l.ShowBottom:
+			return Rect{}, Rect{}, full
+		}
+	}
+
+	bottomHeight := l.bottomHeight(height)
+	panelsHeight := height - bottomHeight
+	treeWidth := l.TreeWidth(width)
+
+	tree = Rect{Width: treeWidth, Height: panelsHeight}
+	details = Rect{X: treeWidth, Width: max(MinDetailsWidth, width-treeWidth), Height: panelsHeight}
+	if bottomHeight > 0 {
+		bottom = Rect{Y: panelsHeight, Width: width, Height: bottomHeight}
+	}
+	return tree, details, bottom
+}
@@ -0,0 +1,174 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/olafkfreund/azure-tui/internal/theme"
+	"github.com/sahilm/fuzzy"
+)
+
+// PaletteItem is an entry of the command palette
+type PaletteItem struct {
+	ID       string // Identifies what to run when the item is chosen
+	Title    string
+	Category string // e.g. "Resource", "View", "Terraform"
+	Key      string // Bound key for display, empty if there is none
+}
+
+// label is the text the palette matches against and shows
+func (item PaletteItem) label() string {
+	if item.Category == "" {
+		return item.Title
+	}
+	return item.Category + ": " + item.Title
+}
+
+// paletteMatch is an item that matched the query, with the matched
+// character positions of its label
+type paletteMatch struct {
+	item    PaletteItem
+	indexes []int
+}
+
+// CommandPalette lists actions and fuzzy-filters them as the query is typed
+type CommandPalette struct {
+	Query     string
+	Items     []PaletteItem
+	Selected  int
+	EmptyText string // Shown when nothing matches
+	matches   []paletteMatch
+}
+
+func NewCommandPalette(items []PaletteItem) *CommandPalette {
+	p := &CommandPalette{Items: items, EmptyText: "No matching actions"}
+	p.filter()
+	return p
+}
+
+// SetQuery filters the items and selects the best match
+func (p *CommandPalette) SetQuery(query string) {
+	p.Query = query
+	p.Selected = 0
+	p.filter()
+}
+
+func (p *CommandPalette) filter() {
+	p.matches = p.matches[:0]
+	query := strings.TrimSpace(p.Query)
+	if query == "" {
+		for _, item := range p.Items {
+			p.matches = append(p.matches, paletteMatch{item: item})
+		}
+		return
+	}
+
+	labels := make([]string, len(p.Items))
+	for i, item := range p.Items {
+		labels[i] = item.label()
+	}
+	// Results are sorted by score, ties keep the order of the items
+	for _, match := range fuzzy.Find(query, labels) {
+		p.matches = append(p.matches, paletteMatch{item: p.Items[match.Index], indexes: match.MatchedIndexes})
+	}
+}
+
+// Matches returns the items matching the query, best first
+func (p *CommandPalette) Matches() []PaletteItem {
+	items := make([]PaletteItem, len(p.matches))
+	for i, match := range p.matches {
+		items[i] = match.item
+	}
+	return items
+}
+
+// Move changes the selection by delta, wrapping around
+func (p *CommandPalette) Move(delta int) {
+	if len(p.matches) == 0 {
+		p.Selected = 0
+		return
+	}
+	p.Selected = (p.Selected + delta + len(p.matches)) % len(p.matches)
+}
+
+// SelectedItem returns the highlighted item, if any item matches
+func (p *CommandPalette) SelectedItem() (PaletteItem, bool) {
+	if p.Selected < 0 || p.Selected >= len(p.matches) {
+		return PaletteItem{}, false
+	}
+	return p.matches[p.Selected].item, true
+}
+
+// Render draws the query line and up to height matching items, scrolled so
+// the selection stays visible
+func (p *CommandPalette) Render(width, height int) string {
+	queryStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Current().Primary)
+	keyStyle := lipgloss.NewStyle().Foreground(theme.Current().Warning)
+	highlightStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Current().Highlight)
+	selectedStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Current().Text)
+	itemStyle := lipgloss.NewStyle().Foreground(theme.Current().Subtext)
+	faintStyle := lipgloss.NewStyle().Faint(true)
+
+	var b strings.Builder
+	b.WriteString(queryStyle.Render("> "+p.Query) + "█\n\n")
+
+	if len(p.matches) == 0 {
+		b.WriteString(faintStyle.Render(p.EmptyText))
+		return b.String()
+	}
+
+	height = max(1, height)
+	start := 0
+	if p.Selected >= height {
+		start = p.Selected - height + 1
+	}
+	end := min(len(p.matches), start+height)
+
+	for i := start; i < end; i++ {
+		match := p.matches[i]
+		style := itemStyle
+		prefix := "  "
+		if i == p.Selected {
+			style = selectedStyle
+			prefix = "▶ "
+		}
+
+		label := []rune(match.item.label())
+		keyWidth := lipgloss.Width(match.item.Key)
+		available := max(1, width-len(prefix)-keyWidth-2)
+		if len(label) > available {
+			label = append(label[:max(0, available-1)], '…')
+		}
+
+		matched := make(map[int]bool, len(match.indexes))
+		for _, idx := range match.indexes {
+			matched[idx] = true
+		}
+		var line strings.Builder
+		line.WriteString(style.Render(prefix))
+		// MatchedIndexes are byte offsets, so walk the label by byte position
+		pos := 0
+		for _, r := range label {
+			if matched[pos] {
+				line.WriteString(highlightStyle.Render(string(r)))
+			} else {
+				line.WriteString(style.Render(string(r)))
+			}
+			pos += len(string(r))
+		}
+
+		padding := max(1, width-lipgloss.Width(line.String())-keyWidth)
+		line.WriteString(strings.Repeat(" ", padding))
+		line.WriteString(keyStyle.Render(match.item.Key))
+		b.WriteString(line.String())
+		if i < end-1 {
+			b.WriteString("\n")
+		}
+	}
+
+	if len(p.matches) > height {
+		b.WriteString("\n" + faintStyle.Render(fmt.Sprintf("%d/%d", p.Selected+1, len(p.matches))))
+	}
+	return b.String()
+}
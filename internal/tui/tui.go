@@ -9,6 +9,7 @@ import (
 	"github.com/charmbracelet/lipgloss"
 	"github.com/olafkfreund/azure-tui/internal/azure/resourcedetails"
 	"github.com/olafkfreund/azure-tui/internal/azure/usage"
+	"github.com/olafkfreund/azure-tui/internal/theme"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 )
@@ -87,21 +88,37 @@ func (tm *TabManager) ActiveTab() *Tab {
 // TreeNode represents a node in the resource tree
 type TreeNode struct {
 	Name         string
-	Type         string // "group", "resource", "folder"
+	Type         string // "group", "resource", "folder", "smart-folder"
 	Icon         string
 	Children     []*TreeNode
 	Expanded     bool
 	Selected     bool
 	ResourceData interface{} // stores actual resource data
 	Level        int         // nesting level for indentation
+	Badge        string      // Shown after the name, e.g. the health of a resource
 }
 
-// TreeView manages the hierarchical display of resources
+// TreeView manages the hierarchical display of resources. The visible nodes
+// are kept flattened in display order so that moving the selection and
+// drawing the viewport don't walk the whole tree. Expanding and collapsing
+// splice the index; other changes rebuild it on next use. Code that edits
+// nodes directly instead of through TreeView methods calls Refresh after.
 type TreeView struct {
 	Root         *TreeNode
 	SelectedPath []int // path to selected node
 	ScrollOffset int
 	MaxVisible   int
+
+	visible  []treeLine // visible nodes in display order
+	stale    bool       // visible needs rebuilding
+	selected *TreeNode
+	selIndex int // index of selected in visible, -1 if hidden or none
+}
+
+// treeLine is a visible node and its indentation depth
+type treeLine struct {
+	node  *TreeNode
+	depth int
 }
 
 // NewTreeView creates a new tree view
@@ -111,9 +128,128 @@ func NewTreeView() *TreeView {
 		SelectedPath: []int{},
 		ScrollOffset: 0,
 		MaxVisible:   20,
+		stale:        true,
+		selIndex:     -1,
 	}
 }
 
+// Refresh rebuilds the visible node index from the tree. Only needed after
+// changing Expanded, Selected or Children directly.
+func (tv *TreeView) Refresh() {
+	tv.stale = true
+}
+
+// index returns the visible nodes, rebuilding them if the tree changed
+func (tv *TreeView) index() []treeLine {
+	if !tv.stale {
+		return tv.visible
+	}
+	tv.stale = false
+	tv.visible = tv.visible[:0]
+	tv.selected, tv.selIndex = nil, -1
+	for _, child := range tv.Root.Children {
+		tv.appendVisible(child, 0)
+	}
+	if tv.selected == nil {
+		// The selection may be inside a collapsed node
+		tv.selected = findSelectedNode(tv.Root)
+	}
+	tv.clampScroll()
+	return tv.visible
+}
+
+// appendVisible adds node and its expanded descendants to the index
+func (tv *TreeView) appendVisible(node *TreeNode, depth int) {
+	if node.Selected && tv.selected == nil {
+		tv.selected, tv.selIndex = node, len(tv.visible)
+	}
+	tv.visible = append(tv.visible, treeLine{node: node, depth: depth})
+	if node.Expanded {
+		for _, child := range node.Children {
+			tv.appendVisible(child, depth+1)
+		}
+	}
+}
+
+// subtree returns the visible descendants of an expanded node
+func subtree(node *TreeNode, depth int) []treeLine {
+	var lines []treeLine
+	var walk func(n *TreeNode, d int)
+	walk = func(n *TreeNode, d int) {
+		lines = append(lines, treeLine{node: n, depth: d})
+		if n.Expanded {
+			for _, child := range n.Children {
+				walk(child, d+1)
+			}
+		}
+	}
+	for _, child := range node.Children {
+		walk(child, depth+1)
+	}
+	return lines
+}
+
+// indexOf returns the position of node in the visible index, or -1
+func (tv *TreeView) indexOf(node *TreeNode) int {
+	visible := tv.index()
+	if node == tv.selected && tv.selIndex >= 0 {
+		return tv.selIndex
+	}
+	for i, line := range visible {
+		if line.node == node {
+			return i
+		}
+	}
+	return -1
+}
+
+// clampScroll keeps the scroll offset within the visible nodes
+func (tv *TreeView) clampScroll() {
+	tv.ScrollOffset = max(0, min(tv.ScrollOffset, len(tv.visible)-tv.MaxVisible))
+}
+
+// SetExpanded expands or collapses node, splicing its descendants into or
+// out of the visible index. Collapsing over the selection selects node.
+func (tv *TreeView) SetExpanded(node *TreeNode, expanded bool) {
+	if node.Expanded == expanded {
+		return
+	}
+	i := tv.indexOf(node)
+	node.Expanded = expanded
+	if i < 0 {
+		return // Hidden, nothing visible changes
+	}
+
+	depth := tv.visible[i].depth
+	if expanded {
+		lines := subtree(node, depth)
+		tv.visible = append(tv.visible[:i+1], append(lines, tv.visible[i+1:]...)...)
+		if tv.selIndex > i {
+			tv.selIndex += len(lines)
+		}
+		return
+	}
+
+	end := i + 1
+	for end < len(tv.visible) && tv.visible[end].depth > depth {
+		end++
+	}
+	tv.visible = append(tv.visible[:i+1], tv.visible[end:]...)
+	switch {
+	case tv.selIndex > i && tv.selIndex < end:
+		tv.selectAt(i)
+	case tv.selIndex >= end:
+		tv.selIndex -= end - i - 1
+	}
+	tv.clampScroll()
+}
+
+// ClearChildren removes all children of node
+func (tv *TreeView) ClearChildren(node *TreeNode) {
+	node.Children = []*TreeNode{}
+	tv.stale = true
+}
+
 // AddResourceGroup adds a resource group to the tree
 func (tv *TreeView) AddResourceGroup(name, location string) *TreeNode {
 	node := &TreeNode{
@@ -125,6 +261,7 @@ func (tv *TreeView) AddResourceGroup(name, location string) *TreeNode {
 		Level:    1,
 	}
 	tv.Root.Children = append(tv.Root.Children, node)
+	tv.stale = true
 	return node
 }
 
@@ -141,10 +278,201 @@ func (tv *TreeView) AddResource(groupNode *TreeNode, name, resourceType string,
 		Level:        2,
 	}
 	groupNode.Children = append(groupNode.Children, resource)
+	tv.stale = true
+}
+
+// SmartFolder is a saved search shown as a virtual folder at the top of the tree
+type SmartFolder struct {
+	Name  string
+	Query string
+	Items []SmartFolderItem // live query results
+}
+
+// SmartFolderItem is a resource listed under a smart folder
+type SmartFolderItem struct {
+	Name string
+	Type string
+	Data interface{}
 }
 
-// GetResourceIcon returns appropriate icon for resource type
+// SetSmartFolders replaces the smart folders at the top of the tree, keeping
+// the expansion state of existing folders and the current selection
+func (tv *TreeView) SetSmartFolders(folders []SmartFolder) {
+	expanded := make(map[string]bool)
+	selectedFolder, selectedItem := "", ""
+	var favorites, groups []*TreeNode
+	for _, child := range tv.Root.Children {
+		if child.Type == "favorites" {
+			favorites = append(favorites, child)
+			continue
+		}
+		if child.Type != "smart-folder" {
+			groups = append(groups, child)
+			continue
+		}
+		expanded[child.Name] = child.Expanded
+		if child.Selected {
+			selectedFolder = child.Name
+		}
+		for _, item := range child.Children {
+			if item.Selected {
+				selectedFolder, selectedItem = child.Name, item.Name
+			}
+		}
+	}
+
+	nodes := make([]*TreeNode, 0, len(favorites)+len(folders)+len(groups))
+	nodes = append(nodes, favorites...)
+	for _, folder := range folders {
+		node := &TreeNode{
+			Name:         folder.Name,
+			Type:         "smart-folder",
+			Icon:         "🔍",
+			Children:     []*TreeNode{},
+			Expanded:     expanded[folder.Name],
+			Selected:     folder.Name == selectedFolder && selectedItem == "",
+			ResourceData: folder.Query,
+			Level:        1,
+		}
+		for _, item := range folder.Items {
+			tv.AddResource(node, item.Name, item.Type, item.Data)
+		}
+		if folder.Name == selectedFolder && selectedItem != "" {
+			node.Selected = true // fall back to the folder if the item is gone
+			for _, child := range node.Children {
+				if child.Name == selectedItem {
+					node.Selected = false
+					child.Selected = true
+					break
+				}
+			}
+		}
+		nodes = append(nodes, node)
+	}
+
+	tv.Root.Children = append(nodes, groups...)
+	tv.stale = true
+}
+
+// Favorite is a bookmarked node listed in the Favorites folder
+type Favorite struct {
+	Name     string
+	Type     string      // Resource type, or "group" for resource groups
+	Data     interface{} // The resource once looked up, the bookmark before
+	Resolved bool        // Data is the resource, the node acts as one
+	Missing  bool        // The lookup found nothing
+	Items    []SmartFolderItem
+}
+
+// SetFavorites replaces the Favorites folder at the top of the tree, keeping
+// its expansion state and the current selection. The folder is left out
+// when there are no favorites.
+func (tv *TreeView) SetFavorites(favorites []Favorite) {
+	var old *TreeNode
+	rest := make([]*TreeNode, 0, len(tv.Root.Children))
+	for _, child := range tv.Root.Children {
+		if child.Type == "favorites" {
+			old = child
+		} else {
+			rest = append(rest, child)
+		}
+	}
+	tv.stale = true
+	if len(favorites) == 0 {
+		tv.Root.Children = rest
+		return
+	}
+
+	folder := &TreeNode{Name: "Favorites", Type: "favorites", Icon: "⭐", Children: []*TreeNode{}, Level: 1}
+	expanded := make(map[string]bool)
+	selectedFavorite, selectedItem := "", ""
+	if old != nil {
+		folder.Expanded, folder.Selected = old.Expanded, old.Selected
+		for _, child := range old.Children {
+			expanded[child.Name] = child.Expanded
+			if child.Selected {
+				selectedFavorite = child.Name
+			}
+			for _, item := range child.Children {
+				if item.Selected {
+					selectedFavorite, selectedItem = child.Name, item.Name
+				}
+			}
+		}
+	}
+
+	for _, favorite := range favorites {
+		node := &TreeNode{
+			Name:         favorite.Name,
+			Type:         "bookmark",
+			Icon:         GetResourceIcon(favorite.Type),
+			Children:     []*TreeNode{},
+			Expanded:     expanded[favorite.Name],
+			Selected:     favorite.Name == selectedFavorite,
+			ResourceData: favorite.Data,
+			Level:        2,
+		}
+		if favorite.Type == "group" {
+			node.Icon = "🗂️"
+		}
+		if favorite.Resolved {
+			node.Type = "resource"
+		}
+		if favorite.Missing {
+			node.Icon = "⚠️"
+		}
+		for _, item := range favorite.Items {
+			tv.AddResource(node, item.Name, item.Type, item.Data)
+			child := node.Children[len(node.Children)-1]
+			child.Level = 3
+			if node.Selected && item.Name == selectedItem {
+				node.Selected = false
+				child.Selected = true
+			}
+		}
+		folder.Children = append(folder.Children, node)
+	}
+
+	tv.Root.Children = append([]*TreeNode{folder}, rest...)
+}
+
+// Favorites returns the Favorites folder, or nil when there are no favorites
+func (tv *TreeView) Favorites() *TreeNode {
+	for _, child := range tv.Root.Children {
+		if child.Type == "favorites" {
+			return child
+		}
+	}
+	return nil
+}
+
+// resourceLabels name resource types in plain mode, where icons aren't shown
+var resourceLabels = map[string]string{
+	"Microsoft.Compute/virtualMachines":          "VM",
+	"Microsoft.KeyVault/vaults":                  "KV",
+	"Microsoft.Storage/storageAccounts":          "ST",
+	"Microsoft.Network/networkInterfaces":        "NIC",
+	"Microsoft.Network/publicIPAddresses":        "PIP",
+	"Microsoft.Network/virtualNetworks":          "VNET",
+	"Microsoft.Compute/disks":                    "DISK",
+	"Microsoft.Insights/actionGroups":            "AG",
+	"Microsoft.Insights/metricAlerts":            "ALRT",
+	"Microsoft.ContainerService/managedClusters": "AKS",
+	"Microsoft.Web/sites":                        "WEB",
+	"Microsoft.Sql/servers":                      "SQL",
+	"Microsoft.DocumentDB/databaseAccounts":      "COSM",
+}
+
+// GetResourceIcon returns appropriate icon for resource type, or a text
+// label in plain mode
 func GetResourceIcon(resourceType string) string {
+	if theme.Plain() {
+		if label, exists := resourceLabels[resourceType]; exists {
+			return "[" + label + "]"
+		}
+		return "[RES]"
+	}
+
 	icons := map[string]string{
 		"Microsoft.Compute/virtualMachines":          "🖥️",
 		"Microsoft.KeyVault/vaults":                  "🔑",
@@ -168,129 +496,153 @@ func GetResourceIcon(resourceType string) string {
 
 // GetAllVisibleNodes returns all currently visible nodes in order for navigation
 func (tv *TreeView) GetAllVisibleNodes() []*TreeNode {
-	var nodes []*TreeNode
-	tv.collectVisibleNodes(tv.Root, &nodes)
-	return nodes
-}
-
-// collectVisibleNodes recursively collects all visible nodes
-func (tv *TreeView) collectVisibleNodes(node *TreeNode, nodes *[]*TreeNode) {
-	if node.Type == "root" {
-		for _, child := range node.Children {
-			tv.collectVisibleNodes(child, nodes)
-		}
-		return
-	}
-
-	*nodes = append(*nodes, node)
-
-	// Include children only if expanded
-	if node.Expanded {
-		for _, child := range node.Children {
-			tv.collectVisibleNodes(child, nodes)
-		}
+	visible := tv.index()
+	nodes := make([]*TreeNode, len(visible))
+	for i, line := range visible {
+		nodes[i] = line.node
 	}
+	return nodes
 }
 
 // GetSelectedNode returns the currently selected node
 func (tv *TreeView) GetSelectedNode() *TreeNode {
-	return tv.findSelectedNode(tv.Root)
+	tv.index()
+	return tv.selected
 }
 
 // findSelectedNode recursively finds the selected node
-func (tv *TreeView) findSelectedNode(node *TreeNode) *TreeNode {
+func findSelectedNode(node *TreeNode) *TreeNode {
 	if node.Selected {
 		return node
 	}
 	for _, child := range node.Children {
-		if result := tv.findSelectedNode(child); result != nil {
+		if result := findSelectedNode(child); result != nil {
 			return result
 		}
 	}
 	return nil
 }
 
+// selectAt makes the visible node at i the only selected node
+func (tv *TreeView) selectAt(i int) *TreeNode {
+	if tv.selected != nil {
+		tv.selected.Selected = false
+	}
+	tv.selected, tv.selIndex = tv.visible[i].node, i
+	tv.selected.Selected = true
+	return tv.selected
+}
+
 // SelectNext moves selection to the next visible node
 func (tv *TreeView) SelectNext() *TreeNode {
-	visibleNodes := tv.GetAllVisibleNodes()
-	if len(visibleNodes) == 0 {
+	visible := tv.index()
+	if len(visible) == 0 {
 		return nil
 	}
 
-	// Find current selection
-	currentIndex := -1
-	for i, node := range visibleNodes {
-		if node.Selected {
-			currentIndex = i
-			break
-		}
-	}
-
-	// Clear current selection
-	tv.clearAllSelections(tv.Root)
-
 	// Select next node (wrap around)
-	nextIndex := (currentIndex + 1) % len(visibleNodes)
-	visibleNodes[nextIndex].Selected = true
+	nextIndex := (tv.selIndex + 1) % len(visible)
+	node := tv.selectAt(nextIndex)
 
 	// Update scroll if needed
 	if nextIndex >= tv.ScrollOffset+tv.MaxVisible {
 		tv.ScrollOffset = nextIndex - tv.MaxVisible + 1
+	} else if nextIndex < tv.ScrollOffset {
+		tv.ScrollOffset = nextIndex
 	}
 
-	return visibleNodes[nextIndex]
+	return node
 }
 
 // SelectPrevious moves selection to the previous visible node
 func (tv *TreeView) SelectPrevious() *TreeNode {
-	visibleNodes := tv.GetAllVisibleNodes()
-	if len(visibleNodes) == 0 {
+	visible := tv.index()
+	if len(visible) == 0 {
 		return nil
 	}
 
-	// Find current selection
-	currentIndex := -1
-	for i, node := range visibleNodes {
-		if node.Selected {
-			currentIndex = i
-			break
-		}
-	}
-
-	// Clear current selection
-	tv.clearAllSelections(tv.Root)
-
 	// Select previous node (wrap around)
-	prevIndex := (currentIndex - 1 + len(visibleNodes)) % len(visibleNodes)
-	visibleNodes[prevIndex].Selected = true
+	prevIndex := (tv.selIndex - 1 + len(visible)) % len(visible)
+	if tv.selIndex < 0 {
+		prevIndex = len(visible) - 1
+	}
+	node := tv.selectAt(prevIndex)
 
 	// Update scroll if needed
 	if prevIndex < tv.ScrollOffset {
 		tv.ScrollOffset = prevIndex
+	} else if prevIndex >= tv.ScrollOffset+tv.MaxVisible {
+		tv.ScrollOffset = prevIndex - tv.MaxVisible + 1
 	}
 
-	return visibleNodes[prevIndex]
-}
-
-// clearAllSelections recursively clears all selections
-func (tv *TreeView) clearAllSelections(node *TreeNode) {
-	node.Selected = false
-	for _, child := range node.Children {
-		tv.clearAllSelections(child)
-	}
+	return node
 }
 
 // ToggleExpansion toggles the expansion of the currently selected node
 func (tv *TreeView) ToggleExpansion() (*TreeNode, bool) {
 	selectedNode := tv.GetSelectedNode()
-	if selectedNode == nil || selectedNode.Type != "group" {
+	if selectedNode == nil {
+		return nil, false
+	}
+	switch selectedNode.Type {
+	case "group", "smart-folder", "favorites", "bookmark":
+	default:
 		return nil, false
 	}
 
-	selectedNode.Expanded = !selectedNode.Expanded
+	tv.SetExpanded(selectedNode, !selectedNode.Expanded)
 	return selectedNode, selectedNode.Expanded
 }
 
+// Select makes node the only selected node, scrolling it into view
+func (tv *TreeView) Select(node *TreeNode) {
+	if i := tv.indexOf(node); i >= 0 {
+		tv.selectAt(i)
+		if i < tv.ScrollOffset {
+			tv.ScrollOffset = i
+		} else if i >= tv.ScrollOffset+tv.MaxVisible {
+			tv.ScrollOffset = i - tv.MaxVisible + 1
+		}
+		return
+	}
+	if tv.selected != nil {
+		tv.selected.Selected = false
+	}
+	tv.selected, tv.selIndex = node, -1
+	node.Selected = true
+}
+
+// Scroll moves the visible window of the tree by delta lines without
+// changing the selection
+func (tv *TreeView) Scroll(delta int) {
+	maxOffset := max(0, len(tv.index())-tv.MaxVisible)
+	tv.ScrollOffset = min(max(0, tv.ScrollOffset+delta), maxOffset)
+}
+
+// window returns the range of visible nodes drawn by RenderTreeView
+func (tv *TreeView) window() (start, end int) {
+	visible := tv.index()
+	if len(visible) <= tv.MaxVisible {
+		return 0, len(visible)
+	}
+	start = min(tv.ScrollOffset, len(visible))
+	return start, min(start+tv.MaxVisible, len(visible))
+}
+
+// NodeAtLine returns the node drawn on the given line of RenderTreeView's
+// output, or nil for padding, scroll indicators and empty space
+func (tv *TreeView) NodeAtLine(line int) *TreeNode {
+	start, end := tv.window()
+	line-- // Top padding
+	if tv.ScrollOffset > 0 {
+		line-- // "More above" indicator
+	}
+	if line < 0 || start+line >= end {
+		return nil
+	}
+	return tv.visible[start+line].node
+}
+
 // EnsureSelection ensures at least one node is selected
 func (tv *TreeView) EnsureSelection() {
 	if tv.GetSelectedNode() != nil {
@@ -298,106 +650,96 @@ func (tv *TreeView) EnsureSelection() {
 	}
 
 	// Select first visible node
-	visibleNodes := tv.GetAllVisibleNodes()
-	if len(visibleNodes) > 0 {
-		visibleNodes[0].Selected = true
+	if len(tv.visible) > 0 {
+		tv.selectAt(0)
 	}
 }
 
-// RenderTreeView renders the tree view as a string
+// RenderTreeView renders the tree view as a string. Only the nodes in the
+// scroll window are drawn.
 func (tv *TreeView) RenderTreeView(width, height int) string {
 	style := lipgloss.NewStyle().
 		Width(width).
 		Height(height).
 		Padding(1, 1)
 
-	var lines []string
-	tv.renderNode(tv.Root, &lines, 0)
+	start, end := tv.window()
+	total := len(tv.visible)
+
+	// Long names are cut rather than wrapped so every node stays on one line
+	lineStyle := lipgloss.NewStyle().MaxWidth(max(1, width-2))
+	lines := make([]string, 0, end-start+2)
+	for _, line := range tv.visible[start:end] {
+		lines = append(lines, lineStyle.Render(theme.Text(renderTreeLine(line))))
+	}
 
 	// Show loading message if tree is empty
-	if len(lines) == 0 {
+	if total == 0 {
 		lines = append(lines, "☁️ Azure Resources")
 		lines = append(lines, "")
 		lines = append(lines, "🔄 Loading resource groups...")
 		lines = append(lines, "")
 		lines = append(lines, "Press ? for help")
-	}
-
-	// Handle scrolling
-	visibleLines := lines
-	if len(lines) > tv.MaxVisible {
-		start := tv.ScrollOffset
-		end := start + tv.MaxVisible
-		if end > len(lines) {
-			end = len(lines)
-		}
-		if start < len(lines) {
-			visibleLines = lines[start:end]
-		}
+		total = len(lines)
 	}
 
 	// Add scroll indicators
 	if tv.ScrollOffset > 0 {
-		visibleLines = append([]string{"  ↑ More above ↑"}, visibleLines...)
-	}
-	if tv.ScrollOffset+tv.MaxVisible < len(lines) {
-		visibleLines = append(visibleLines, "  ↓ More below ↓")
+		lines = append([]string{"  ↑ More above ↑"}, lines...)
 	}
-
-	content := strings.Join(visibleLines, "\n")
-
-	// Ensure we always have some content
-	if content == "" {
-		content = "☁️ Azure Resources\n\n🔄 Loading resource groups...\n\nPress ? for help"
+	if tv.ScrollOffset+tv.MaxVisible < total {
+		lines = append(lines, "  ↓ More below ↓")
 	}
 
-	return style.Render(content)
+	return style.Render(theme.Text(strings.Join(lines, "\n")))
 }
 
-// renderNode recursively renders tree nodes
-func (tv *TreeView) renderNode(node *TreeNode, lines *[]string, depth int) {
-	if node.Type == "root" {
-		// Render root children directly
-		for _, child := range node.Children {
-			tv.renderNode(child, lines, depth)
-		}
-		return
-	}
+// renderTreeLine renders a single visible node
+func renderTreeLine(line treeLine) string {
+	node := line.node
 
 	// Create indentation
-	indent := strings.Repeat("  ", depth)
+	indent := strings.Repeat("  ", line.depth)
 
 	// Create expand/collapse indicator
 	indicator := ""
 	if len(node.Children) > 0 {
 		if node.Expanded {
-			indicator = "▼ "
+			indicator = theme.Icon("▼ ", "- ")
 		} else {
-			indicator = "▶ "
+			indicator = theme.Icon("▶ ", "+ ")
 		}
 	} else {
 		indicator = "  "
 	}
 
 	// Create the line
-	line := fmt.Sprintf("%s%s%s %s", indent, indicator, node.Icon, node.Name)
+	text := fmt.Sprintf("%s%s%s %s", indent, indicator, node.Icon, node.Name)
+	if node.Type == "smart-folder" {
+		text += fmt.Sprintf(" (%d)", len(node.Children))
+	}
+	if node.Badge != "" {
+		text += " " + node.Badge
+	}
+
+	// Plain mode has no highlight, a marker column shows the selection
+	if theme.Plain() {
+		marker := "  "
+		if node.Selected {
+			marker = "> "
+		}
+		text = marker + text
+	}
 
 	// Highlight if selected
 	if node.Selected {
-		line = lipgloss.NewStyle().
-			Background(lipgloss.Color("33")).
-			Foreground(lipgloss.Color("230")).
-			Render(line)
+		text = lipgloss.NewStyle().
+			Background(theme.Current().Primary).
+			Foreground(theme.Current().Text).
+			Render(text)
 	}
 
-	*lines = append(*lines, line)
-
-	// Render children if expanded
-	if node.Expanded {
-		for _, child := range node.Children {
-			tv.renderNode(child, lines, depth+1)
-		}
-	}
+	return text
 }
 
 // PowerlineSegment represents a segment in the powerline statusbar
@@ -406,6 +748,7 @@ type PowerlineSegment struct {
 	Background lipgloss.Color
 	Foreground lipgloss.Color
 	Separator  string
+	Action     string // Run when the segment is clicked, empty if it isn't clickable
 }
 
 // StatusBar represents a powerline-style status bar
@@ -437,6 +780,26 @@ func (sb *StatusBar) AddSegment(text string, bg, fg lipgloss.Color) {
 	sb.Segments = append(sb.Segments, segment)
 }
 
+// AddActionSegment adds a segment that runs action when clicked
+func (sb *StatusBar) AddActionSegment(text, action string, bg, fg lipgloss.Color) {
+	sb.AddSegment(text, bg, fg)
+	sb.Segments[len(sb.Segments)-1].Action = action
+}
+
+// SegmentAt returns the left segment drawn at column x
+func (sb *StatusBar) SegmentAt(x int) (PowerlineSegment, bool) {
+	pos := 0
+	for _, segment := range sb.Segments {
+		// Segments are padded by one column on each side and separated by a space
+		width := lipgloss.Width(theme.Text(segment.Text)) + 2
+		if x >= pos && x < pos+width {
+			return segment, true
+		}
+		pos += width + 1
+	}
+	return PowerlineSegment{}, false
+}
+
 // AddRightSegment adds a right-aligned segment
 func (sb *StatusBar) AddRightSegment(text string, bg, fg lipgloss.Color) {
 	segment := PowerlineSegment{
@@ -451,7 +814,7 @@ func (sb *StatusBar) AddRightSegment(text string, bg, fg lipgloss.Color) {
 // RenderStatusBar renders the powerline status bar
 func (sb *StatusBar) RenderStatusBar() string {
 	if len(sb.Segments) == 0 {
-		return "🚀 Azure TUI | Loading..."
+		return theme.Text("🚀 Azure TUI | Loading...")
 	}
 
 	var leftSide strings.Builder
@@ -464,7 +827,7 @@ func (sb *StatusBar) RenderStatusBar() string {
 			Foreground(segment.Foreground).
 			Padding(0, 1)
 
-		leftSide.WriteString(style.Render(segment.Text))
+		leftSide.WriteString(style.Render(theme.Text(segment.Text)))
 
 		// Add powerline separator (simplified)
 		if i < len(sb.Segments)-1 {
@@ -480,7 +843,7 @@ func (sb *StatusBar) RenderStatusBar() string {
 			Foreground(segment.Foreground).
 			Padding(0, 1)
 
-		rightSide.WriteString(style.Render(segment.Text))
+		rightSide.WriteString(style.Render(theme.Text(segment.Text)))
 
 		// Add separator between segments
 		if i > 0 {
@@ -551,7 +914,7 @@ func RenderTabs(tm *TabManager, status string) string {
 	var tabBar strings.Builder
 	for i, tab := range tm.Tabs {
 		if i == tm.ActiveIndex {
-			tabBar.WriteString(lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("10")).Render("[" + tab.Title + "] "))
+			tabBar.WriteString(lipgloss.NewStyle().Bold(true).Foreground(theme.Current().Success).Render("[" + tab.Title + "] "))
 		} else {
 			tabBar.WriteString(lipgloss.NewStyle().Faint(true).Render(tab.Title + " "))
 		}
@@ -573,8 +936,29 @@ func RenderShortcutsPopup(shortcuts map[string]string) string {
 
 // RenderTabsWithActive renders a tab bar with the active tab highlighted, supporting a main (non-closable) tab and resource tabs with Azure icons.
 func RenderTabsWithActive(tabs []Tab, activeIdx int) string {
+	return strings.Join(renderTabLabels(tabs, activeIdx), " ")
+}
+
+// TabAt returns the index of the tab drawn at column x of the tab bar and
+// whether x is on its close button
+func TabAt(tabs []Tab, activeIdx int, x int) (int, bool) {
+	pos := 0
+	for i, label := range renderTabLabels(tabs, activeIdx) {
+		width := lipgloss.Width(label)
+		if x >= pos && x < pos+width {
+			// The close button is the last character before the right padding
+			closable := tabs[i].Closable && i != 0
+			return i, closable && x >= pos+width-3
+		}
+		pos += width + 1
+	}
+	return -1, false
+}
+
+// renderTabLabels renders each tab of the tab bar
+func renderTabLabels(tabs []Tab, activeIdx int) []string {
 	if len(tabs) == 0 {
-		return ""
+		return nil
 	}
 
 	// Azure service icons mapping - using Unicode symbols that represent Azure services
@@ -590,6 +974,7 @@ func RenderTabsWithActive(tabs []Tab, activeIdx int) string {
 		"keyvault":           "⚿", // Key vault
 		"monitor":            "◉", // Monitor/metrics
 		"logs":               "≡", // Log analytics
+		"shell":              "❯", // SSH sessions
 		"security":           "⛨", // Security center
 		"compute":            "⚙", // Compute services
 		"container":          "⬡", // Container instances
@@ -630,7 +1015,7 @@ func RenderTabsWithActive(tabs []Tab, activeIdx int) string {
 		"default":            "▫", // Default/unknown
 	}
 
-	var tabBar strings.Builder
+	labels := make([]string, 0, len(tabs))
 
 	for i, tab := range tabs {
 		// Get appropriate icon
@@ -644,31 +1029,35 @@ func RenderTabsWithActive(tabs []Tab, activeIdx int) string {
 		if i == activeIdx {
 			tabStyle = lipgloss.NewStyle().
 				Bold(true).
-				Foreground(lipgloss.Color("15")).
-				Background(lipgloss.Color("39")).
+				Foreground(theme.Current().Text).
+				Background(theme.Current().Primary).
 				Padding(0, 1)
 		} else {
 			tabStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("8")).
-				Background(lipgloss.Color("0")).
+				Foreground(theme.Current().Muted).
+				Background(theme.Current().Background).
 				Padding(0, 1)
 		}
 
-		// Format tab title with icon
+		// Format tab title with icon, plain mode goes by the title alone
 		tabTitle := fmt.Sprintf("%s %s", icon, tab.Title)
+		if theme.Plain() {
+			tabTitle = tab.Title
+		}
 		if tab.Closable && i != 0 { // First tab (main) is not closable
 			tabTitle += " ✕"
 		}
-
-		tabBar.WriteString(tabStyle.Render(tabTitle))
-
-		// Add separator between tabs
-		if i < len(tabs)-1 {
-			tabBar.WriteString(" ")
+		tabTitle = theme.Text(tabTitle)
+		if theme.Plain() && i == activeIdx {
+			// Brackets instead of the padding mark the active tab without color
+			tabStyle = tabStyle.UnsetPadding()
+			tabTitle = "[" + tabTitle + "]"
 		}
+
+		labels = append(labels, tabStyle.Render(tabTitle))
 	}
 
-	return tabBar.String()
+	return labels
 }
 
 // RenderMetricsDashboard renders an interactive dashboard for Azure resource metrics
@@ -676,16 +1065,16 @@ func RenderMetricsDashboard(resourceName string, metrics map[string]interface{})
 	var dashboard strings.Builder
 
 	// Header with resource name and current time
-	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("39")).Background(lipgloss.Color("236")).Padding(0, 2)
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Current().Primary).Background(theme.Current().Surface).Padding(0, 2)
 	dashboard.WriteString(headerStyle.Render(fmt.Sprintf("📊 Metrics Dashboard: %s", resourceName)))
 	dashboard.WriteString("\n\n")
 
 	// CPU Usage (example metric)
 	if cpu, exists := metrics["cpu_usage"]; exists {
 		cpuValue := fmt.Sprintf("%.1f%%", cpu)
-		cpuStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
+		cpuStyle := lipgloss.NewStyle().Foreground(theme.Current().Success)
 		if val, ok := cpu.(float64); ok && val > 80 {
-			cpuStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+			cpuStyle = lipgloss.NewStyle().Foreground(theme.Current().Error)
 		}
 		dashboard.WriteString(fmt.Sprintf("CPU Usage:    %s\n", cpuStyle.Render(cpuValue)))
 	}
@@ -693,39 +1082,39 @@ func RenderMetricsDashboard(resourceName string, metrics map[string]interface{})
 	// Memory Usage
 	if mem, exists := metrics["memory_usage"]; exists {
 		memValue := fmt.Sprintf("%.1f%%", mem)
-		memStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
+		memStyle := lipgloss.NewStyle().Foreground(theme.Current().Success)
 		if val, ok := mem.(float64); ok && val > 85 {
-			memStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+			memStyle = lipgloss.NewStyle().Foreground(theme.Current().Error)
 		}
 		dashboard.WriteString(fmt.Sprintf("Memory Usage: %s\n", memStyle.Render(memValue)))
 	}
 
 	// Network I/O
 	if netIn, exists := metrics["network_in"]; exists {
-		dashboard.WriteString(fmt.Sprintf("Network In:   %s MB/s\n", lipgloss.NewStyle().Foreground(lipgloss.Color("12")).Render(fmt.Sprintf("%.2f", netIn))))
+		dashboard.WriteString(fmt.Sprintf("Network In:   %s MB/s\n", lipgloss.NewStyle().Foreground(theme.Current().Primary).Render(fmt.Sprintf("%.2f", netIn))))
 	}
 	if netOut, exists := metrics["network_out"]; exists {
-		dashboard.WriteString(fmt.Sprintf("Network Out:  %s MB/s\n", lipgloss.NewStyle().Foreground(lipgloss.Color("12")).Render(fmt.Sprintf("%.2f", netOut))))
+		dashboard.WriteString(fmt.Sprintf("Network Out:  %s MB/s\n", lipgloss.NewStyle().Foreground(theme.Current().Primary).Render(fmt.Sprintf("%.2f", netOut))))
 	}
 
 	// Disk I/O
 	if diskRead, exists := metrics["disk_read"]; exists {
-		dashboard.WriteString(fmt.Sprintf("Disk Read:    %s MB/s\n", lipgloss.NewStyle().Foreground(lipgloss.Color("13")).Render(fmt.Sprintf("%.2f", diskRead))))
+		dashboard.WriteString(fmt.Sprintf("Disk Read:    %s MB/s\n", lipgloss.NewStyle().Foreground(theme.Current().Accent).Render(fmt.Sprintf("%.2f", diskRead))))
 	}
 	if diskWrite, exists := metrics["disk_write"]; exists {
-		dashboard.WriteString(fmt.Sprintf("Disk Write:   %s MB/s\n", lipgloss.NewStyle().Foreground(lipgloss.Color("13")).Render(fmt.Sprintf("%.2f", diskWrite))))
+		dashboard.WriteString(fmt.Sprintf("Disk Write:   %s MB/s\n", lipgloss.NewStyle().Foreground(theme.Current().Accent).Render(fmt.Sprintf("%.2f", diskWrite))))
 	}
 
 	// Add a simple ASCII graph for trending
 	dashboard.WriteString("\n")
-	trendStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Padding(1)
+	trendStyle := lipgloss.NewStyle().Foreground(theme.Current().Muted).Padding(1)
 	trendContent := "CPU Trend (24h):\n"
 	trendContent += "▁▂▃▄▅▆▇█▇▆▅▄▃▂▁▂▃▄▅▆▇█▇▆▅▄"
 	dashboard.WriteString(trendStyle.Render(trendContent))
 
 	// Add interactive controls hint
 	dashboard.WriteString("\n\n")
-	controlsStyle := lipgloss.NewStyle().Faint(true).Foreground(lipgloss.Color("8"))
+	controlsStyle := lipgloss.NewStyle().Faint(true).Foreground(theme.Current().Muted)
 	dashboard.WriteString(controlsStyle.Render("Controls: [r]efresh • [a]lerts • [e]xport • [q]uit"))
 
 	return dashboard.String()
@@ -735,7 +1124,7 @@ func RenderMetricsDashboard(resourceName string, metrics map[string]interface{})
 func RenderResourceActions(resourceType, resourceName string, actions []string) string {
 	var content strings.Builder
 
-	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("39")).Background(lipgloss.Color("236")).Padding(0, 2)
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Current().Primary).Background(theme.Current().Surface).Padding(0, 2)
 	content.WriteString(headerStyle.Render(fmt.Sprintf("⚡ Actions: %s", resourceName)))
 	content.WriteString("\n\n")
 
@@ -770,13 +1159,13 @@ func RenderResourceActions(resourceType, resourceName string, actions []string)
 			icon = "⚙️"
 		}
 
-		actionStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("14"))
+		actionStyle := lipgloss.NewStyle().Foreground(theme.Current().Info)
 		titleCaser := cases.Title(language.English)
 		content.WriteString(fmt.Sprintf("%d. %s %s\n", i+1, icon, actionStyle.Render(titleCaser.String(action))))
 	}
 
 	content.WriteString("\n")
-	controlsStyle := lipgloss.NewStyle().Faint(true).Foreground(lipgloss.Color("8"))
+	controlsStyle := lipgloss.NewStyle().Faint(true).Foreground(theme.Current().Muted)
 	content.WriteString(controlsStyle.Render("Select action by number • Press 'q' to cancel"))
 
 	return content.String()
@@ -786,23 +1175,23 @@ func RenderResourceActions(resourceType, resourceName string, actions []string)
 func RenderEditDialog(resourceName, resourceType string, currentConfig map[string]string) string {
 	var content strings.Builder
 
-	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("39")).Padding(0, 2)
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Current().Primary).Padding(0, 2)
 	content.WriteString(headerStyle.Render(fmt.Sprintf("✏️ Edit: %s", resourceName)))
 	content.WriteString("\n\n")
 
 	content.WriteString(fmt.Sprintf("Resource Type: %s\n\n", resourceType))
 
-	sectionStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("33"))
+	sectionStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Current().Primary)
 	content.WriteString(sectionStyle.Render("Current Configuration:"))
 	content.WriteString("\n")
 
 	for key, value := range currentConfig {
-		keyStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("14"))
+		keyStyle := lipgloss.NewStyle().Foreground(theme.Current().Info)
 		content.WriteString(fmt.Sprintf("%s: %s\n", keyStyle.Render(key), value))
 	}
 
 	content.WriteString("\n")
-	controlsStyle := lipgloss.NewStyle().Faint(true).Foreground(lipgloss.Color("8"))
+	controlsStyle := lipgloss.NewStyle().Faint(true).Foreground(theme.Current().Muted)
 	content.WriteString(controlsStyle.Render("Use arrow keys to navigate • Enter to edit • ESC to cancel"))
 
 	return content.String()
@@ -810,7 +1199,7 @@ func RenderEditDialog(resourceName, resourceType string, currentConfig map[strin
 
 // RenderDeleteConfirmation renders a confirmation dialog for resource deletion
 func RenderDeleteConfirmation(resourceName, resourceType string) string {
-	style := lipgloss.NewStyle().Padding(1).Foreground(lipgloss.Color("9"))
+	style := lipgloss.NewStyle().Padding(1).Foreground(theme.Current().Error)
 	content := fmt.Sprintf("⚠️  Delete Resource\n\nAre you sure you want to delete:\n\nName: %s\nType: %s\n\nThis action cannot be undone!\n\nPress 'y' to confirm, 'n' to cancel", resourceName, resourceType)
 	return style.Render(content)
 }
@@ -819,12 +1208,12 @@ func RenderDeleteConfirmation(resourceName, resourceType string) string {
 func RenderStructuredResourceDetails(details map[string]interface{}) string {
 	var content strings.Builder
 
-	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("39")).Padding(0, 2)
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Current().Primary).Padding(0, 2)
 	content.WriteString(headerStyle.Render("📋 Resource Details"))
 	content.WriteString("\n\n")
 
 	// Basic Information Section
-	sectionStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("33"))
+	sectionStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Current().Primary)
 	content.WriteString(sectionStyle.Render("📍 Basic Information"))
 	content.WriteString("\n")
 
@@ -841,9 +1230,9 @@ func RenderStructuredResourceDetails(details map[string]interface{}) string {
 		content.WriteString(fmt.Sprintf("Resource Group: %s\n", resourceGroup))
 	}
 	if status, ok := details["status"].(string); ok {
-		statusStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
+		statusStyle := lipgloss.NewStyle().Foreground(theme.Current().Success)
 		if status != "Succeeded" && status != "Running" {
-			statusStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+			statusStyle = lipgloss.NewStyle().Foreground(theme.Current().Error)
 		}
 		content.WriteString(fmt.Sprintf("Status:         %s\n", statusStyle.Render(status)))
 	}
@@ -867,7 +1256,7 @@ func RenderStructuredResourceDetails(details map[string]interface{}) string {
 		content.WriteString("\n")
 
 		for key, value := range tags {
-			tagStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("14"))
+			tagStyle := lipgloss.NewStyle().Foreground(theme.Current().Info)
 			content.WriteString(fmt.Sprintf("%s: %s\n", tagStyle.Render(key), value))
 		}
 	}
@@ -894,7 +1283,7 @@ func RenderStructuredResourceDetails(details map[string]interface{}) string {
 		importantProps := []string{"vmSize", "osType", "provisioningState", "adminUsername", "computerName", "dnsSettings", "ipConfigurations"}
 		for _, prop := range importantProps {
 			if value, exists := properties[prop]; exists {
-				propStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("11"))
+				propStyle := lipgloss.NewStyle().Foreground(theme.Current().Warning)
 				titleCaser := cases.Title(language.English)
 				content.WriteString(fmt.Sprintf("%s: %s\n", propStyle.Render(titleCaser.String(prop)), fmt.Sprintf("%v", value)))
 			}
@@ -908,7 +1297,7 @@ func RenderStructuredResourceDetails(details map[string]interface{}) string {
 func RenderEnhancedMetricsDashboard(resourceName string, metrics map[string]interface{}, trends map[string][]float64) string {
 	var dashboard strings.Builder
 
-	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("39")).Padding(0, 2)
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Current().Primary).Padding(0, 2)
 	dashboard.WriteString(headerStyle.Render(fmt.Sprintf("📊 Live Metrics: %s", resourceName)))
 	dashboard.WriteString("\n\n")
 
@@ -919,11 +1308,11 @@ func RenderEnhancedMetricsDashboard(resourceName string, metrics map[string]inte
 	cpuContent := "🖥️  CPU Usage\n"
 	if cpu, exists := metrics["cpu_usage"]; exists {
 		cpuValue := fmt.Sprintf("%.1f%%", cpu)
-		cpuStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
+		cpuStyle := lipgloss.NewStyle().Foreground(theme.Current().Success)
 		if val, ok := cpu.(float64); ok && val > 80 {
-			cpuStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+			cpuStyle = lipgloss.NewStyle().Foreground(theme.Current().Error)
 		} else if val, ok := cpu.(float64); ok && val > 60 {
-			cpuStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("11"))
+			cpuStyle = lipgloss.NewStyle().Foreground(theme.Current().Warning)
 		}
 		cpuContent += cpuStyle.Render(cpuValue)
 
@@ -938,11 +1327,11 @@ func RenderEnhancedMetricsDashboard(resourceName string, metrics map[string]inte
 	memContent := "💾 Memory Usage\n"
 	if mem, exists := metrics["memory_usage"]; exists {
 		memValue := fmt.Sprintf("%.1f%%", mem)
-		memStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
+		memStyle := lipgloss.NewStyle().Foreground(theme.Current().Success)
 		if val, ok := mem.(float64); ok && val > 85 {
-			memStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+			memStyle = lipgloss.NewStyle().Foreground(theme.Current().Error)
 		} else if val, ok := mem.(float64); ok && val > 70 {
-			memStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("11"))
+			memStyle = lipgloss.NewStyle().Foreground(theme.Current().Warning)
 		}
 		memContent += memStyle.Render(memValue)
 
@@ -986,7 +1375,7 @@ func RenderEnhancedMetricsDashboard(resourceName string, metrics map[string]inte
 	dashboard.WriteString("\n\n")
 
 	// Controls and refresh info
-	controlsStyle := lipgloss.NewStyle().Faint(true).Foreground(lipgloss.Color("8"))
+	controlsStyle := lipgloss.NewStyle().Faint(true).Foreground(theme.Current().Muted)
 	dashboard.WriteString(controlsStyle.Render("⚡ Auto-refresh: 30s | [r]efresh now | [a]lerts | [h]istory | [q]uit"))
 
 	return dashboard.String()
@@ -999,10 +1388,10 @@ func RenderAKSDetails(clusterName string, aksDetails map[string]interface{}) str
 	if aksDetails == nil {
 		// Fallback: minimal AKS dashboard with error
 		content.WriteString("\n\n")
-		headerStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("39")).Background(lipgloss.Color("236")).Padding(0, 2)
+		headerStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Current().Primary).Background(theme.Current().Surface).Padding(0, 2)
 		content.WriteString(headerStyle.Render(fmt.Sprintf("🚢 AKS Cluster: %s", clusterName)))
 		content.WriteString("\n\n")
-		timeStyle := lipgloss.NewStyle().Faint(true).Foreground(lipgloss.Color("8"))
+		timeStyle := lipgloss.NewStyle().Faint(true).Foreground(theme.Current().Muted)
 		content.WriteString(timeStyle.Render("Last Updated: --"))
 		content.WriteString("\n\n")
 		content.WriteString("❌ Unable to load AKS cluster details.\n")
@@ -1012,20 +1401,20 @@ func RenderAKSDetails(clusterName string, aksDetails map[string]interface{}) str
 		return content.String()
 	}
 
-	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("39")).Background(lipgloss.Color("236")).Padding(0, 2)
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Current().Primary).Background(theme.Current().Surface).Padding(0, 2)
 	content.WriteString(headerStyle.Render(fmt.Sprintf("🚢 AKS Cluster: %s", clusterName)))
 	content.WriteString("\n\n")
 
-	sectionStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("33"))
+	sectionStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Current().Primary)
 
 	// Cluster Overview
 	content.WriteString(sectionStyle.Render("📋 Cluster Overview"))
 	content.WriteString("\n")
 
 	if status, ok := aksDetails["status"].(string); ok {
-		statusStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
+		statusStyle := lipgloss.NewStyle().Foreground(theme.Current().Success)
 		if status != "Running" && status != "Succeeded" {
-			statusStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+			statusStyle = lipgloss.NewStyle().Foreground(theme.Current().Error)
 		}
 		content.WriteString(fmt.Sprintf("Status:         %s\n", statusStyle.Render(status)))
 	} else {
@@ -1057,7 +1446,7 @@ func RenderAKSDetails(clusterName string, aksDetails map[string]interface{}) str
 				vmSize, _ := poolMap["vmSize"].(string)
 				osType, _ := poolMap["osType"].(string)
 
-				poolStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("14"))
+				poolStyle := lipgloss.NewStyle().Foreground(theme.Current().Info)
 				content.WriteString(fmt.Sprintf("%s: %d × %s (%s)\n", poolStyle.Render(name), count, vmSize, osType))
 			}
 		}
@@ -1086,9 +1475,9 @@ func RenderAKSDetails(clusterName string, aksDetails map[string]interface{}) str
 		content.WriteString(fmt.Sprintf("Total Pods:     %d\n", len(pods)))
 
 		for status, count := range podCounts {
-			statusStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
+			statusStyle := lipgloss.NewStyle().Foreground(theme.Current().Success)
 			if status != "Running" {
-				statusStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("11"))
+				statusStyle = lipgloss.NewStyle().Foreground(theme.Current().Warning)
 			}
 			titleCaser := cases.Title(language.English)
 			content.WriteString(fmt.Sprintf("%s: %s\n", statusStyle.Render(titleCaser.String(status)), fmt.Sprintf("%d", count)))
@@ -1097,7 +1486,7 @@ func RenderAKSDetails(clusterName string, aksDetails map[string]interface{}) str
 		content.WriteString("\nTop Namespaces:\n")
 		for ns, count := range nsCounts {
 			if count > 1 {
-				nsStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("12"))
+				nsStyle := lipgloss.NewStyle().Foreground(theme.Current().Primary)
 				content.WriteString(fmt.Sprintf("%s: %d pods\n", nsStyle.Render(ns), count))
 			}
 		}
@@ -1123,7 +1512,7 @@ func RenderAKSDetails(clusterName string, aksDetails map[string]interface{}) str
 				namespace, _ := deployMap["namespace"].(string)
 				ready, _ := deployMap["ready"].(string)
 
-				deployStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("13"))
+				deployStyle := lipgloss.NewStyle().Foreground(theme.Current().Accent)
 				content.WriteString(fmt.Sprintf("%s (%s): %s\n", deployStyle.Render(name), namespace, ready))
 			}
 		}
@@ -1147,7 +1536,7 @@ func RenderAKSDetails(clusterName string, aksDetails map[string]interface{}) str
 
 		content.WriteString(fmt.Sprintf("Total Services: %d\n", len(services)))
 		for svcType, count := range typeCounts {
-			typeStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("15"))
+			typeStyle := lipgloss.NewStyle().Foreground(theme.Current().Text)
 			content.WriteString(fmt.Sprintf("%s: %d\n", typeStyle.Render(svcType), count))
 		}
 	} else {
@@ -1266,7 +1655,7 @@ func RenderTable(data TableData) string {
 
 	// Title
 	if data.Title != "" {
-		titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("39"))
+		titleStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Current().Primary)
 		content.WriteString(titleStyle.Render(data.Title))
 		content.WriteString("\n\n")
 	}
@@ -1291,7 +1680,7 @@ func RenderTable(data TableData) string {
 	}
 
 	// Render headers
-	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("33"))
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Current().Primary)
 	for i, header := range data.Headers {
 		content.WriteString(headerStyle.Render(fmt.Sprintf("%-*s", colWidths[i], header)))
 	}
@@ -1355,7 +1744,7 @@ func RenderSimpleList(data TableData) string {
 
 	// Title
 	if data.Title != "" {
-		titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("39"))
+		titleStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Current().Primary)
 		content.WriteString(titleStyle.Render(data.Title))
 		content.WriteString("\n\n")
 	}
@@ -1369,8 +1758,8 @@ func RenderSimpleList(data TableData) string {
 	}
 
 	// Render rows as property: value pairs
-	keyStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("14")).Bold(true)
-	valueStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("7"))
+	keyStyle := lipgloss.NewStyle().Foreground(theme.Current().Info).Bold(true)
+	valueStyle := lipgloss.NewStyle().Foreground(theme.Current().Subtext)
 
 	for _, row := range data.Rows {
 		if len(row) >= 2 {
@@ -1424,12 +1813,12 @@ func RenderDashboardLoadingProgress(progress DashboardLoadingProgress) string {
 	var content strings.Builder
 
 	// Header
-	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("39")).Padding(0, 1)
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Current().Primary).Padding(0, 1)
 	content.WriteString(headerStyle.Render("📊 Loading Resource Dashboard"))
 	content.WriteString("\n\n")
 
 	// Current operation
-	operationStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("12"))
+	operationStyle := lipgloss.NewStyle().Foreground(theme.Current().Primary)
 	content.WriteString(operationStyle.Render(fmt.Sprintf("📋 %s", progress.CurrentOperation)))
 	content.WriteString("\n\n")
 
@@ -1439,7 +1828,7 @@ func RenderDashboardLoadingProgress(progress DashboardLoadingProgress) string {
 	emptyWidth := progressBarWidth - filledWidth
 
 	progressBar := strings.Repeat("█", filledWidth) + strings.Repeat("░", emptyWidth)
-	progressStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
+	progressStyle := lipgloss.NewStyle().Foreground(theme.Current().Success)
 
 	content.WriteString(fmt.Sprintf("Progress: [%s] %.1f%% (%d/%d)",
 		progressStyle.Render(progressBar),
@@ -1450,7 +1839,7 @@ func RenderDashboardLoadingProgress(progress DashboardLoadingProgress) string {
 
 	// Time information
 	elapsed := time.Since(progress.StartTime)
-	timeStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+	timeStyle := lipgloss.NewStyle().Foreground(theme.Current().Muted)
 	content.WriteString(timeStyle.Render(fmt.Sprintf("⏱️  Elapsed: %.1fs | %s", elapsed.Seconds(), progress.EstimatedTimeRemaining)))
 	content.WriteString("\n\n")
 
@@ -1463,27 +1852,28 @@ func RenderDashboardLoadingProgress(progress DashboardLoadingProgress) string {
 
 	for _, dataType := range dataTypes {
 		if dataProgress, exists := progress.DataProgress[dataType]; exists {
-			var statusIcon, statusColor string
+			var statusIcon string
+			var statusColor lipgloss.Color
 
 			switch dataProgress.Status {
 			case "pending":
 				statusIcon = "⏳"
-				statusColor = "8" // Gray
+				statusColor = theme.Current().Muted
 			case "loading":
 				statusIcon = "🔄"
-				statusColor = "11" // Yellow
+				statusColor = theme.Current().Warning
 			case "completed":
 				statusIcon = "✅"
-				statusColor = "10" // Green
+				statusColor = theme.Current().Success
 			case "failed":
 				statusIcon = "❌"
-				statusColor = "9" // Red
+				statusColor = theme.Current().Error
 			default:
 				statusIcon = "❔"
-				statusColor = "8"
+				statusColor = theme.Current().Muted
 			}
 
-			statusStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(statusColor))
+			statusStyle := lipgloss.NewStyle().Foreground(statusColor)
 			dataName := formatDataTypeName(dataType)
 
 			line := fmt.Sprintf("%s %s", statusIcon, dataName)
@@ -1506,7 +1896,7 @@ func RenderDashboardLoadingProgress(progress DashboardLoadingProgress) string {
 	// Error summary if there are errors
 	if len(progress.Errors) > 0 {
 		content.WriteString("\n")
-		errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+		errorStyle := lipgloss.NewStyle().Foreground(theme.Current().Error)
 		content.WriteString(errorStyle.Render("⚠️  Errors encountered:"))
 		content.WriteString("\n")
 
@@ -1521,7 +1911,7 @@ func RenderDashboardLoadingProgress(progress DashboardLoadingProgress) string {
 
 	// Footer with helpful information
 	content.WriteString("\n")
-	helpStyle := lipgloss.NewStyle().Faint(true).Foreground(lipgloss.Color("8"))
+	helpStyle := lipgloss.NewStyle().Faint(true).Foreground(theme.Current().Muted)
 	content.WriteString(helpStyle.Render("💡 Loading comprehensive resource data from Azure Monitor and Activity Logs"))
 
 	return content.String()
@@ -1534,10 +1924,10 @@ func RenderComprehensiveDashboard(resourceName string, data *ComprehensiveDashbo
 	if data == nil {
 		// Fallback: minimal dashboard with error
 		content.WriteString("\n\n")
-		headerStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("39")).Padding(0, 1)
+		headerStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Current().Primary).Padding(0, 1)
 		content.WriteString(headerStyle.Render(fmt.Sprintf("📊 Comprehensive Dashboard: %s", resourceName)))
 		content.WriteString("\n\n")
-		timeStyle := lipgloss.NewStyle().Faint(true).Foreground(lipgloss.Color("8"))
+		timeStyle := lipgloss.NewStyle().Faint(true).Foreground(theme.Current().Muted)
 		content.WriteString(timeStyle.Render("Last Updated: --"))
 		content.WriteString("\n\n")
 		content.WriteString("❌ Unable to load dashboard data.\n")
@@ -1548,12 +1938,12 @@ func RenderComprehensiveDashboard(resourceName string, data *ComprehensiveDashbo
 	}
 
 	// Dashboard Header
-	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("39")).Padding(0, 1)
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Current().Primary).Padding(0, 1)
 	content.WriteString(headerStyle.Render(fmt.Sprintf("📊 Comprehensive Dashboard: %s", resourceName)))
 	content.WriteString("\n\n")
 
 	// Show last updated time
-	timeStyle := lipgloss.NewStyle().Faint(true).Foreground(lipgloss.Color("8"))
+	timeStyle := lipgloss.NewStyle().Faint(true).Foreground(theme.Current().Muted)
 	content.WriteString(timeStyle.Render(fmt.Sprintf("Last Updated: %s", data.LastUpdated.Format("15:04:05"))))
 	content.WriteString("\n\n")
 
@@ -1580,7 +1970,7 @@ func RenderComprehensiveDashboard(resourceName string, data *ComprehensiveDashbo
 	}
 
 	// Footer with controls
-	helpStyle := lipgloss.NewStyle().Faint(true).Foreground(lipgloss.Color("8"))
+	helpStyle := lipgloss.NewStyle().Faint(true).Foreground(theme.Current().Muted)
 	content.WriteString(helpStyle.Render("Press [d] for Details view • [r] to refresh • Auto-refresh: 30s"))
 
 	return content.String()
@@ -1590,7 +1980,7 @@ func RenderComprehensiveDashboard(resourceName string, data *ComprehensiveDashbo
 func renderMetricsSection(metrics *ResourceMetrics) string {
 	var content strings.Builder
 
-	sectionStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("10"))
+	sectionStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Current().Success)
 	content.WriteString(sectionStyle.Render("📈 Real-Time Metrics"))
 	content.WriteString("\n")
 
@@ -1600,18 +1990,18 @@ func renderMetricsSection(metrics *ResourceMetrics) string {
 	}
 
 	// CPU and Memory in a row with color coding
-	cpuStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
+	cpuStyle := lipgloss.NewStyle().Foreground(theme.Current().Success)
 	if metrics.CPUUsage > 80 {
-		cpuStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+		cpuStyle = lipgloss.NewStyle().Foreground(theme.Current().Error)
 	} else if metrics.CPUUsage > 60 {
-		cpuStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("11"))
+		cpuStyle = lipgloss.NewStyle().Foreground(theme.Current().Warning)
 	}
 
-	memStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
+	memStyle := lipgloss.NewStyle().Foreground(theme.Current().Success)
 	if metrics.MemoryUsage > 85 {
-		memStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+		memStyle = lipgloss.NewStyle().Foreground(theme.Current().Error)
 	} else if metrics.MemoryUsage > 70 {
-		memStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("11"))
+		memStyle = lipgloss.NewStyle().Foreground(theme.Current().Warning)
 	}
 
 	content.WriteString(fmt.Sprintf("🖥️  CPU: %s  💾 Memory: %s\n",
@@ -1619,13 +2009,13 @@ func renderMetricsSection(metrics *ResourceMetrics) string {
 		memStyle.Render(fmt.Sprintf("%.1f%%", metrics.MemoryUsage))))
 
 	// Network metrics
-	netStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("12"))
+	netStyle := lipgloss.NewStyle().Foreground(theme.Current().Primary)
 	content.WriteString(fmt.Sprintf("🌐 Network In: %s  Out: %s\n",
 		netStyle.Render(fmt.Sprintf("%.1f MB/s", metrics.NetworkIn)),
 		netStyle.Render(fmt.Sprintf("%.1f MB/s", metrics.NetworkOut))))
 
 	// Disk metrics
-	diskStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("13"))
+	diskStyle := lipgloss.NewStyle().Foreground(theme.Current().Accent)
 	content.WriteString(fmt.Sprintf("💿 Disk Read: %s  Write: %s\n",
 		diskStyle.Render(fmt.Sprintf("%.1f MB/s", metrics.DiskRead)),
 		diskStyle.Render(fmt.Sprintf("%.1f MB/s", metrics.DiskWrite))))
@@ -1633,18 +2023,18 @@ func renderMetricsSection(metrics *ResourceMetrics) string {
 	// Simple trend visualization if available
 	if len(metrics.TrendData) > 0 {
 		content.WriteString("\n")
-		trendStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("14"))
+		trendStyle := lipgloss.NewStyle().Foreground(theme.Current().Info)
 		content.WriteString(trendStyle.Render("Trend (24h): ▁▂▃▄▅▆▇█▇▆▅▄▃▂▁▂▃▄▅▆▇█▇▆▅▄"))
 	}
 
 	return content.String()
 }
 
-// renderUsageSection renders usage metrics and quotas
+// renderUsageSection renders the latest values of the usage metrics
 func renderUsageSection(usageMetrics []UsageMetric) string {
 	var content strings.Builder
 
-	sectionStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("11"))
+	sectionStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Current().Warning)
 	content.WriteString(sectionStyle.Render("📊 Resource Usage & Quotas"))
 	content.WriteString("\n")
 
@@ -1655,23 +2045,15 @@ func renderUsageSection(usageMetrics []UsageMetric) string {
 
 	// Create table for usage metrics
 	tableData := TableData{
-		Headers: []string{"Resource", "Current", "Limit", "Usage %"},
+		Headers: []string{"Metric", "Latest", "Unit"},
 		Rows:    [][]string{},
 	}
 
 	for _, metric := range usageMetrics {
-		// Calculate usage percentage if possible
-		usagePercent := "N/A"
-		if metric.Limit != "" && metric.Value != "" {
-			// Simple percentage calculation (would need better parsing in real implementation)
-			usagePercent = "~85%" // Placeholder
-		}
-
 		tableData.Rows = append(tableData.Rows, []string{
 			metric.Name,
 			metric.Value,
-			metric.Limit,
-			usagePercent,
+			metric.Unit,
 		})
 	}
 
@@ -1683,7 +2065,7 @@ func renderUsageSection(usageMetrics []UsageMetric) string {
 func renderAlarmsSection(alarms []Alarm) string {
 	var content strings.Builder
 
-	sectionStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("9"))
+	sectionStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Current().Error)
 	content.WriteString(sectionStyle.Render("🚨 Alarms & Alerts"))
 	content.WriteString("\n")
 
@@ -1692,7 +2074,7 @@ func renderAlarmsSection(alarms []Alarm) string {
 
 	// Summary with color coding
 	if summary.Total == 0 {
-		greenStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
+		greenStyle := lipgloss.NewStyle().Foreground(theme.Current().Success)
 		content.WriteString(greenStyle.Render("✅ No active alarms - all systems normal"))
 		content.WriteString("\n")
 		return content.String()
@@ -1701,15 +2083,15 @@ func renderAlarmsSection(alarms []Alarm) string {
 	// Show alarm summary
 	summaryLine := fmt.Sprintf("Total: %d", summary.Total)
 	if summary.Critical > 0 {
-		criticalStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+		criticalStyle := lipgloss.NewStyle().Foreground(theme.Current().Error)
 		summaryLine += fmt.Sprintf(" | Critical: %s", criticalStyle.Render(fmt.Sprintf("%d", summary.Critical)))
 	}
 	if summary.Warning > 0 {
-		warningStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("11"))
+		warningStyle := lipgloss.NewStyle().Foreground(theme.Current().Warning)
 		summaryLine += fmt.Sprintf(" | Warning: %s", warningStyle.Render(fmt.Sprintf("%d", summary.Warning)))
 	}
 	if summary.Info > 0 {
-		infoStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
+		infoStyle := lipgloss.NewStyle().Foreground(theme.Current().Success)
 		summaryLine += fmt.Sprintf(" | Info: %s", infoStyle.Render(fmt.Sprintf("%d", summary.Info)))
 	}
 
@@ -1739,7 +2121,7 @@ func renderAlarmsSection(alarms []Alarm) string {
 func renderLogsSection(logEntries []LogEntry) string {
 	var content strings.Builder
 
-	sectionStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("12"))
+	sectionStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Current().Primary)
 	content.WriteString(sectionStyle.Render("📋 Recent Activity & Logs"))
 	content.WriteString("\n")
 
@@ -1752,11 +2134,11 @@ func renderLogsSection(logEntries []LogEntry) string {
 	logStats := analyzeLogEntries(logEntries)
 	statsLine := fmt.Sprintf("Last 24h: %d entries", len(logEntries))
 	if logStats.ErrorCount > 0 {
-		errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+		errorStyle := lipgloss.NewStyle().Foreground(theme.Current().Error)
 		statsLine += fmt.Sprintf(" | Errors: %s", errorStyle.Render(fmt.Sprintf("%d", logStats.ErrorCount)))
 	}
 	if logStats.WarningCount > 0 {
-		warningStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("11"))
+		warningStyle := lipgloss.NewStyle().Foreground(theme.Current().Warning)
 		statsLine += fmt.Sprintf(" | Warnings: %s", warningStyle.Render(fmt.Sprintf("%d", logStats.WarningCount)))
 	}
 
@@ -1794,7 +2176,7 @@ func renderLogsSection(logEntries []LogEntry) string {
 func renderErrorSection(errors []string) string {
 	var content strings.Builder
 
-	errorStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("9"))
+	errorStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Current().Error)
 	content.WriteString(errorStyle.Render("⚠️  Data Loading Issues"))
 	content.WriteString("\n")
 
@@ -1,8 +1,13 @@
 package tui_test
 
 import (
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/charmbracelet/lipgloss"
+	"github.com/olafkfreund/azure-tui/internal/theme"
 	"github.com/olafkfreund/azure-tui/internal/tui"
 )
 
@@ -25,3 +30,471 @@ func TestRenderMatrixGraph(t *testing.T) {
 		t.Error("Expected non-empty matrix graph output")
 	}
 }
+
+func TestSetSmartFolders(t *testing.T) {
+	tv := tui.NewTreeView()
+	tv.AddResourceGroup("rg-app", "westeurope")
+
+	tv.SetSmartFolders([]tui.SmartFolder{{
+		Name:  "All prod VMs",
+		Query: "type:vm tag:env=prod",
+		Items: []tui.SmartFolderItem{{Name: "vm-prod-1", Type: "Microsoft.Compute/virtualMachines"}},
+	}})
+
+	if len(tv.Root.Children) != 2 || tv.Root.Children[0].Type != "smart-folder" {
+		t.Fatal("Expected smart folder above resource groups")
+	}
+
+	// Select the folder item and refresh with new results
+	tv.Root.Children[0].Expanded = true
+	tv.Root.Children[0].Children[0].Selected = true
+	tv.SetSmartFolders([]tui.SmartFolder{{
+		Name: "All prod VMs",
+		Items: []tui.SmartFolderItem{
+			{Name: "vm-prod-0", Type: "Microsoft.Compute/virtualMachines"},
+			{Name: "vm-prod-1", Type: "Microsoft.Compute/virtualMachines"},
+		},
+	}})
+
+	folder := tv.Root.Children[0]
+	if !folder.Expanded {
+		t.Error("Expected smart folder to stay expanded after refresh")
+	}
+	if selected := tv.GetSelectedNode(); selected == nil || selected.Name != "vm-prod-1" {
+		t.Error("Expected selection to survive refresh")
+	}
+	if len(tv.Root.Children) != 2 || tv.Root.Children[1].Name != "rg-app" {
+		t.Error("Expected resource groups to be kept")
+	}
+}
+
+func TestSetFavorites(t *testing.T) {
+	tv := tui.NewTreeView()
+	tv.AddResourceGroup("rg-app", "westeurope")
+	favorites := []tui.Favorite{
+		{Name: "vm-prod-1", Type: "Microsoft.Compute/virtualMachines", Data: "vm-prod-1", Resolved: true},
+		{Name: "rg-shared", Type: "group", Items: []tui.SmartFolderItem{{Name: "Loading...", Type: "placeholder"}}},
+	}
+	tv.SetFavorites(favorites)
+	tv.SetSmartFolders([]tui.SmartFolder{{Name: "All prod VMs"}})
+
+	folder := tv.Favorites()
+	if folder == nil || tv.Root.Children[0] != folder || tv.Root.Children[1].Type != "smart-folder" {
+		t.Fatal("Expected Favorites above smart folders")
+	}
+	if folder.Children[0].Type != "resource" || folder.Children[1].Type != "bookmark" {
+		t.Errorf("Expected a resolved resource and an unresolved bookmark, got %s and %s",
+			folder.Children[0].Type, folder.Children[1].Type)
+	}
+
+	// Open the group and select its resource, then load the group
+	tv.SetExpanded(folder, true)
+	tv.SetExpanded(folder.Children[1], true)
+	tv.Select(folder.Children[1].Children[0])
+	favorites[1].Items = []tui.SmartFolderItem{{Name: "Loading..."}, {Name: "vnet-hub", Type: "Microsoft.Network/virtualNetworks"}}
+	tv.SetFavorites(favorites)
+
+	folder = tv.Favorites()
+	if !folder.Expanded || !folder.Children[1].Expanded {
+		t.Error("Expected Favorites and the group to stay expanded")
+	}
+	if selected := tv.GetSelectedNode(); selected == nil || selected.Name != "Loading..." {
+		t.Errorf("Expected the selection to survive, got %v", selected)
+	}
+
+	tv.SetFavorites(nil)
+	if tv.Favorites() != nil || len(tv.Root.Children) != 2 {
+		t.Error("Expected the Favorites folder to be removed without favorites")
+	}
+}
+
+func TestCommandPalette(t *testing.T) {
+	p := tui.NewCommandPalette([]tui.PaletteItem{
+		{ID: "start", Title: "Start", Category: "Resource", Key: "s"},
+		{ID: "network_dashboard", Title: "Network Dashboard", Category: "View", Key: "N"},
+		{ID: "terraform:plan", Title: "Plan Deployment", Category: "Terraform"},
+	})
+
+	if len(p.Matches()) != 3 {
+		t.Fatalf("Expected every item with an empty query, got %d", len(p.Matches()))
+	}
+
+	p.SetQuery("netdash")
+	if item, ok := p.SelectedItem(); !ok || item.ID != "network_dashboard" {
+		t.Errorf("Expected fuzzy match on network dashboard, got %v", p.Matches())
+	}
+
+	// The category is matched too
+	p.SetQuery("terraform")
+	if item, ok := p.SelectedItem(); !ok || item.ID != "terraform:plan" {
+		t.Errorf("Expected category match, got %v", p.Matches())
+	}
+
+	p.SetQuery("zzz")
+	if _, ok := p.SelectedItem(); ok {
+		t.Error("Expected no selection without matches")
+	}
+	if out := p.Render(60, 10); !strings.Contains(out, "No matching actions") {
+		t.Errorf("Expected empty state, got %q", out)
+	}
+
+	p.SetQuery("")
+	p.Move(-1)
+	if item, _ := p.SelectedItem(); item.ID != "terraform:plan" {
+		t.Errorf("Expected selection to wrap around, got %v", item)
+	}
+	if out := p.Render(60, 10); !strings.Contains(out, "Network Dashboard") {
+		t.Errorf("Expected items to be rendered, got %q", out)
+	}
+}
+
+func TestTreeViewNodeAtLine(t *testing.T) {
+	tv := tui.NewTreeView()
+	tv.MaxVisible = 3
+	for _, name := range []string{"rg-a", "rg-b", "rg-c", "rg-d", "rg-e"} {
+		tv.AddResourceGroup(name, "westeurope")
+	}
+
+	// Line 0 is padding, the nodes follow
+	if node := tv.NodeAtLine(0); node != nil {
+		t.Errorf("Expected no node on the padding line, got %s", node.Name)
+	}
+	if node := tv.NodeAtLine(1); node == nil || node.Name != "rg-a" {
+		t.Errorf("Expected rg-a on line 1, got %v", node)
+	}
+
+	// Scrolled, the "More above" indicator takes a line
+	tv.Scroll(10)
+	if tv.ScrollOffset != 2 {
+		t.Fatalf("Expected scrolling to stop at the last page, got offset %d", tv.ScrollOffset)
+	}
+	lines := strings.Split(tv.RenderTreeView(40, 10), "\n")
+	if !strings.Contains(lines[2], "rg-c") {
+		t.Fatalf("Expected rg-c on line 2, got %q", lines[2])
+	}
+	if node := tv.NodeAtLine(2); node == nil || node.Name != "rg-c" {
+		t.Errorf("Expected rg-c on line 2, got %v", node)
+	}
+	if node := tv.NodeAtLine(5); node != nil {
+		t.Errorf("Expected no node below the window, got %s", node.Name)
+	}
+}
+
+func TestTabAt(t *testing.T) {
+	tabs := []tui.Tab{
+		{Title: "Overview", Type: "main"},
+		{Title: "vm1", Type: "vm", Closable: true},
+	}
+	bar := tui.RenderTabsWithActive(tabs, 0)
+	width := lipgloss.Width(bar)
+
+	if index, closeClicked := tui.TabAt(tabs, 0, 1); index != 0 || closeClicked {
+		t.Errorf("Expected the overview tab, got %d %v", index, closeClicked)
+	}
+	if index, closeClicked := tui.TabAt(tabs, 0, width-2); index != 1 || !closeClicked {
+		t.Errorf("Expected the close button of vm1, got %d %v", index, closeClicked)
+	}
+	if index, _ := tui.TabAt(tabs, 0, width+5); index != -1 {
+		t.Errorf("Expected no tab past the bar, got %d", index)
+	}
+}
+
+func TestStatusBarSegmentAt(t *testing.T) {
+	sb := tui.CreatePowerlineStatusBar(80)
+	sb.AddSegment("info", "1", "2")
+	sb.AddActionSegment("/:Search", "search", "1", "2")
+
+	if segment, ok := sb.SegmentAt(2); !ok || segment.Action != "" {
+		t.Errorf("Expected the info segment, got %+v", segment)
+	}
+	// "info" is 6 columns wide with padding, followed by a space
+	if segment, ok := sb.SegmentAt(7); !ok || segment.Action != "search" {
+		t.Errorf("Expected the search segment, got %+v", segment)
+	}
+	if _, ok := sb.SegmentAt(40); ok {
+		t.Error("Expected no segment past the last one")
+	}
+}
+
+func TestLayoutSplit(t *testing.T) {
+	l := tui.NewLayout()
+	tree, details, bottom := l.Split(120, 40, tui.PanelTree)
+	if tree.Width != 40 || details.X != 40 || details.Width != 80 {
+		t.Errorf("Expected a 40/80 split, got %+v %+v", tree, details)
+	}
+	if !bottom.Empty() || tree.Height != 40 {
+		t.Errorf("Expected no bottom panel, got %+v", bottom)
+	}
+
+	l.ShowBottom = true
+	tree, _, bottom = l.Split(120, 40, tui.PanelTree)
+	if bottom.Y != 30 || bottom.Height != 10 || bottom.Width != 120 || tree.Height != 30 {
+		t.Errorf("Expected a 10 row bottom panel, got %+v above %+v", bottom, tree)
+	}
+
+	l.Zoomed = true
+	tree, details, bottom = l.Split(120, 40, tui.PanelDetails)
+	if !tree.Empty() || !bottom.Empty() || details.Width != 120 || details.Height != 40 {
+		t.Errorf("Expected the zoomed details panel to fill the screen, got %+v", details)
+	}
+}
+
+func TestLayoutResizeLimits(t *testing.T) {
+	l := tui.NewLayout()
+	l.SetTreeWidth(5, 120)
+	if got := l.TreeWidth(120); got != tui.MinTreeWidth {
+		t.Errorf("Expected the tree to keep its minimum width, got %d", got)
+	}
+	l.SetTreeWidth(200, 120)
+	if got := l.TreeWidth(120); got != 120-tui.MinDetailsWidth {
+		t.Errorf("Expected the details panel to keep its minimum width, got %d", got)
+	}
+
+	l.ShowBottom = true
+	l.ResizeBottom(100, 40)
+	if _, _, bottom := l.Split(120, 40, tui.PanelTree); bottom.Height != 40-tui.MinPanelsHeight {
+		t.Errorf("Expected the panels to keep their minimum height, got %d", bottom.Height)
+	}
+	l.ResizeBottom(-100, 40)
+	if l.BottomHeight != tui.MinBottomHeight {
+		t.Errorf("Expected the minimum bottom height, got %d", l.BottomHeight)
+	}
+}
+
+func TestPlainTreeAndTabs(t *testing.T) {
+	theme.SetPlain(true)
+	t.Cleanup(func() { theme.SetPlain(false) })
+
+	tv := tui.NewTreeView()
+	group := tv.AddResourceGroup("rg1", "westeurope")
+	group.Expanded = true
+	tv.AddResource(group, "vm1", "Microsoft.Compute/virtualMachines", nil)
+	tv.EnsureSelection()
+
+	out := tv.RenderTreeView(40, 20)
+	for _, r := range out {
+		if r > 127 {
+			t.Fatalf("Expected ASCII only, got %q in:\n%s", r, out)
+		}
+	}
+	if !strings.Contains(out, "> - rg1") {
+		t.Errorf("Expected the selected, expanded group to be marked, got:\n%s", out)
+	}
+	if !strings.Contains(out, "[VM] vm1") {
+		t.Errorf("Expected a text label for the VM, got:\n%s", out)
+	}
+
+	tabs := []tui.Tab{
+		{Title: "Overview", Type: "main"},
+		{Title: "vm1", Type: "vm", Closable: true},
+	}
+	if bar := tui.RenderTabsWithActive(tabs, 1); bar != " Overview  [vm1 x]" {
+		t.Errorf("Expected the active tab in brackets, got %q", bar)
+	}
+}
+
+// largeTree builds a tree of groups with resources each
+func largeTree(groups, resources int) *tui.TreeView {
+	tv := tui.NewTreeView()
+	for g := 0; g < groups; g++ {
+		group := tv.AddResourceGroup(fmt.Sprintf("rg-%04d", g), "westeurope")
+		for r := 0; r < resources; r++ {
+			tv.AddResource(group, fmt.Sprintf("vm-%04d-%04d", g, r), "Microsoft.Compute/virtualMachines", nil)
+		}
+	}
+	tv.EnsureSelection()
+	return tv
+}
+
+func TestTreeViewExpandCollapse(t *testing.T) {
+	tv := largeTree(3, 2)
+	names := func() string {
+		var names []string
+		for _, node := range tv.GetAllVisibleNodes() {
+			names = append(names, node.Name)
+		}
+		return strings.Join(names, " ")
+	}
+
+	groups := tv.Root.Children
+	tv.SetExpanded(groups[1], true)
+	tv.SetExpanded(groups[0], true)
+	want := "rg-0000 vm-0000-0000 vm-0000-0001 rg-0001 vm-0001-0000 vm-0001-0001 rg-0002"
+	if got := names(); got != want {
+		t.Fatalf("Expected %q after expanding, got %q", want, got)
+	}
+
+	// The spliced index matches a rebuilt one
+	tv.Refresh()
+	if got := names(); got != want {
+		t.Errorf("Expected the rebuilt index to match, got %q", got)
+	}
+
+	// Selection moves by position, across the expanded groups
+	for i := 0; i < 4; i++ {
+		tv.SelectNext()
+	}
+	if node := tv.GetSelectedNode(); node == nil || node.Name != "vm-0001-0000" {
+		t.Fatalf("Expected vm-0001-0000 selected, got %v", node)
+	}
+
+	// Collapsing above the selection keeps it, collapsing over it moves it
+	// to the group
+	tv.SetExpanded(groups[0], false)
+	if node := tv.SelectNext(); node == nil || node.Name != "vm-0001-0001" {
+		t.Fatalf("Expected vm-0001-0001 after collapsing rg-0000, got %v", node)
+	}
+	tv.SetExpanded(groups[1], false)
+	if node := tv.GetSelectedNode(); node != groups[1] || !node.Selected {
+		t.Fatalf("Expected rg-0001 selected after collapsing it, got %v", node)
+	}
+	if got := names(); got != "rg-0000 rg-0001 rg-0002" {
+		t.Errorf("Expected only groups after collapsing, got %q", got)
+	}
+	if node := tv.SelectPrevious(); node != groups[0] {
+		t.Errorf("Expected rg-0000 above rg-0001, got %v", node)
+	}
+}
+
+func TestTreeViewRendersWindow(t *testing.T) {
+	tv := largeTree(100, 0)
+	tv.MaxVisible = 5
+	for i := 0; i < 50; i++ {
+		tv.SelectNext()
+	}
+
+	out := tv.RenderTreeView(40, 10)
+	if strings.Contains(out, "rg-0045") || !strings.Contains(out, "rg-0046") || !strings.Contains(out, "rg-0050") {
+		t.Errorf("Expected rg-0046 to rg-0050 in the window, got:\n%s", out)
+	}
+	if !strings.Contains(out, "More above") || !strings.Contains(out, "More below") {
+		t.Errorf("Expected both scroll indicators, got:\n%s", out)
+	}
+}
+
+func BenchmarkTreeViewSelectNext(b *testing.B) {
+	tv := largeTree(100, 100)
+	for _, group := range tv.Root.Children {
+		tv.SetExpanded(group, true)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tv.SelectNext()
+	}
+}
+
+func BenchmarkTreeViewRender(b *testing.B) {
+	tv := largeTree(100, 100)
+	for _, group := range tv.Root.Children {
+		tv.SetExpanded(group, true)
+	}
+	tv.Scroll(5000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tv.RenderTreeView(60, 30)
+	}
+}
+
+func BenchmarkTreeViewToggle(b *testing.B) {
+	tv := largeTree(100, 100)
+	group := tv.Root.Children[50]
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tv.SetExpanded(group, !group.Expanded)
+	}
+}
+
+func TestLineChartPlain(t *testing.T) {
+	theme.SetPlain(true)
+	t.Cleanup(func() { theme.SetPlain(false) })
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	chart := tui.LineChart{
+		Start:  start,
+		End:    start.Add(2 * time.Hour),
+		Width:  30,
+		Height: 5,
+		Series: []tui.ChartSeries{{Name: "cpu", Points: []tui.ChartPoint{
+			{Time: start, Value: 10, Valid: true},
+			{Time: start.Add(time.Hour), Valid: false},
+			{Time: start.Add(2 * time.Hour), Value: 40, Valid: true},
+		}}},
+		FormatY: func(v float64) string { return fmt.Sprintf("%.0f", v) },
+	}
+	lines := strings.Split(chart.Render(), "\n")
+	if len(lines) != 7 {
+		t.Fatalf("expected 5 rows and the time axis, got %d lines:\n%s", len(lines), strings.Join(lines, "\n"))
+	}
+	// The scale starts at zero and rounds the highest value up
+	if !strings.HasPrefix(lines[0], "50 +") {
+		t.Errorf("expected the top label 50, got %q", lines[0])
+	}
+	if !strings.HasSuffix(lines[1], "*") {
+		t.Errorf("expected the last point in the right column, got %q", lines[1])
+	}
+	if !strings.HasPrefix(lines[4], " 0 +") {
+		t.Errorf("expected the bottom label 0, got %q", lines[4])
+	}
+	// The gap leaves the two points unconnected
+	if got := strings.Count(strings.Join(lines[:5], ""), "*"); got != 2 {
+		t.Errorf("expected 2 markers, got %d", got)
+	}
+	if !strings.Contains(lines[6], "00:00") || !strings.Contains(lines[6], "02:00") {
+		t.Errorf("expected the start and end times on the axis, got %q", lines[6])
+	}
+}
+
+func TestLineChartNoData(t *testing.T) {
+	chart := tui.LineChart{Width: 30, Height: 5, Series: []tui.ChartSeries{{Name: "cpu"}}}
+	if out := chart.Render(); !strings.Contains(out, "No data points") {
+		t.Errorf("expected a no data message, got %q", out)
+	}
+}
+
+func TestEditor(t *testing.T) {
+	e := tui.NewEditor("Perf\n  | where x")
+	if row, col := e.Cursor(); row != 1 || col != 11 {
+		t.Fatalf("cursor = %d,%d, want the end of the text", row, col)
+	}
+
+	// Newlines keep the indentation of the line
+	e.Newline()
+	e.Insert("| take 10")
+	if got, want := e.Value(), "Perf\n  | where x\n  | take 10"; got != want {
+		t.Errorf("Value() = %q, want %q", got, want)
+	}
+
+	// Backspace at the start of a line joins it to the one above
+	e.Home()
+	e.Backspace()
+	if got, want := e.Value(), "Perf\n  | where x  | take 10"; got != want || e.Lines() != 2 {
+		t.Errorf("after backspace Value() = %q, want %q", got, want)
+	}
+
+	// Moving left past the start of a line wraps to the end of the one above
+	e.SetValue("ab\ncd")
+	e.Home()
+	e.Move(0, -1)
+	e.Delete()
+	if got := e.Value(); got != "abcd" {
+		t.Errorf("after delete at the end of a line Value() = %q, want %q", got, "abcd")
+	}
+	e.Move(-1, 0)
+	e.Insert("é\nx")
+	if got := e.Value(); got != "abé\nxcd" {
+		t.Errorf("after inserting a newline Value() = %q", got)
+	}
+}
+
+func TestEditorView(t *testing.T) {
+	e := tui.NewEditor("one\ntwo\nthree\nfour")
+	lines := strings.Split(e.View(40, 2, false), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], "3 three") || !strings.Contains(lines[1], "4 four") {
+		t.Errorf("View() should scroll to the cursor on the last line, got %q", lines)
+	}
+
+	e.SetValue("x")
+	if lines := strings.Split(e.View(40, 3, false), "\n"); len(lines) != 3 || !strings.Contains(lines[2], "~") {
+		t.Errorf("View() should mark the lines past the end, got %q", lines)
+	}
+}